@@ -0,0 +1,90 @@
+// bootstrap-admin 创建第一个管理员账号，或在所有管理员都无法登录时重置某个管理员的密码。
+//
+// 密码从环境变量 ADMIN_PASSWORD 读取，避免出现在命令行历史中：
+//
+//	ADMIN_PASSWORD=... go run ./cmd/bootstrap-admin -id root -name "System Admin"
+//	ADMIN_PASSWORD=... go run ./cmd/bootstrap-admin -id root -rotate
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/dialect"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+func main() {
+	configPath := flag.String("config", "../config.yaml", "path to config file")
+	id := flag.String("id", "", "admin login id")
+	name := flag.String("name", "", "admin display name")
+	rotate := flag.Bool("rotate", false, "reset the password of an existing admin and re-enable it; the admin must change it at next login")
+	flag.Parse()
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if *id == "" || password == "" {
+		log.Fatal("-id and the ADMIN_PASSWORD environment variable are required")
+	}
+
+	// 加载配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 连接数据库
+	sqlDB, d, err := dialect.Open(cfg.Database.Driver, cfg.Database.DSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	if err := sqlDB.Ping(); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+	db := repository.NewDB(sqlDB, d)
+
+	jwtManager, err := utils.NewJWTManager(cfg.JWT)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	// 重置密码时吊销该管理员已有的会话，命令行的操作同样写入审计日志
+	sessionService := service.NewSessionService(repository.NewSessionRepository(db), jwtManager, time.Duration(cfg.JWT.RefreshExpiration)*time.Second)
+	auditService := service.NewAuditService(repository.NewAuditRepository(db))
+	adminAccountService := service.NewAdminAccountService(repository.NewAdminRepository(db), sessionService, utils.NewPasswordPolicy(cfg.Password), auditService)
+
+	ctx := context.Background()
+	if *rotate {
+		if err := adminAccountService.RotatePassword(ctx, model.SystemActor, *id, password); err != nil {
+			log.Fatalf("Failed to rotate password: %v", err)
+		}
+		if err := adminAccountService.EnableAdmin(ctx, model.SystemActor, *id); err != nil {
+			log.Fatalf("Failed to enable admin: %v", err)
+		}
+		log.Printf("Password rotated for admin %s", *id)
+		return
+	}
+
+	if *name == "" {
+		*name = *id
+	}
+
+	admin, err := adminAccountService.Bootstrap(ctx, &model.AdminCreateRequest{
+		ID:       *id,
+		Name:     *name,
+		Password: password,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create admin: %v", err)
+	}
+
+	log.Printf("Admin %s created", admin.ID)
+}
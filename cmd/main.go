@@ -2,22 +2,27 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"path/filepath"
 	"syscall"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql" // 使用MySQL驱动
 	"github.com/yourusername/student-management-system/internal/api/handler"
 	"github.com/yourusername/student-management-system/internal/api/middleware"
+	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/repository"
 	"github.com/yourusername/student-management-system/internal/service"
 	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/dialect"
+	"github.com/yourusername/student-management-system/pkg/migrate"
+	"github.com/yourusername/student-management-system/pkg/notify"
+	"github.com/yourusername/student-management-system/pkg/oidc"
+	"github.com/yourusername/student-management-system/pkg/utils"
 )
 
 func main() {
@@ -27,29 +32,58 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// 连接数据库
-	db, err := sql.Open("mysql", cfg.Database.DSN)
+	// 连接数据库，驱动由 database.driver 决定
+	sqlDB, d, err := dialect.Open(cfg.Database.Driver, cfg.Database.DSN)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
+	defer sqlDB.Close()
 
 	// 设置数据库连接池参数
-	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	db.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
 
 	// 检查数据库连接
-	if err := db.Ping(); err != nil {
+	if err := sqlDB.Ping(); err != nil {
 		log.Fatalf("Failed to ping database: %v", err)
 	}
 
-	// 执行数据库初始化脚本
-	if err := executeSQLScript(db, "../scripts/init.sql"); err != nil {
-		log.Fatalf("Failed to execute init.sql: %v", err)
+	// 执行尚未执行的数据库迁移，多个实例同时启动时由迁移锁保证只执行一次
+	if cfg.Database.AutoMigrate {
+		migrations, err := migrate.Load(os.DirFS(filepath.Join(cfg.Database.MigrationsDir, d.Name())))
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if _, err := migrate.New(sqlDB, d, migrations).Up(0); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
+	// 初始化密码策略和通知发送
+	passwordPolicy := utils.NewPasswordPolicy(cfg.Password)
+	notifier, err := notify.New(cfg.Notifier)
+	if err != nil {
+		log.Fatalf("Failed to create notifier: %v", err)
 	}
 
-	// 初始化仓库层
+	// 初始化JWT签名密钥
+	jwtManager, err := utils.NewJWTManager(cfg.JWT)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	// 初始化单点登录身份提供方，未启用时为nil
+	var oidcProvider *oidc.Provider
+	if cfg.OIDC.Enabled {
+		oidcProvider, err = oidc.NewProvider(cfg.OIDC, nil)
+		if err != nil {
+			log.Fatalf("Failed to configure OIDC provider: %v", err)
+		}
+	}
+
+	// 初始化仓库层，仓库中的SQL由db按方言改写
+	db := repository.NewDB(sqlDB, d)
 	studentRepo := repository.NewStudentRepository(db)
 	instructorRepo := repository.NewInstructorRepository(db)
 	courseRepo := repository.NewCourseRepository(db)
@@ -61,98 +95,204 @@ func main() {
 	timeSlotRepo := repository.NewTimeSlotRepository(db)
 	teachesRepo := repository.NewTeachesRepository(db)
 	prereqRepo := repository.NewPrereqRepository(db)
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	termRepo := repository.NewTermRepository(db)
+	ticketRepo := repository.NewTimeTicketRepository(db)
+	overloadRepo := repository.NewOverloadRepository(db)
+	cartRepo := repository.NewCartRepository(db)
+	adminRepo := repository.NewAdminRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+	unitOfWork := repository.NewUnitOfWork(db)
 
 	// 初始化服务层
-	studentService := service.NewStudentService(studentRepo, takesRepo, prereqRepo, sectionRepo, advisorRepo)
-	instructorService := service.NewInstructorService(instructorRepo, teachesRepo, takesRepo, advisorRepo, sectionRepo, studentRepo)
+	sessionService := service.NewSessionService(sessionRepo, jwtManager, time.Duration(cfg.JWT.RefreshExpiration)*time.Second)
+	auditService := service.NewAuditService(auditRepo)
+	studentService := service.NewStudentService(studentRepo, takesRepo, prereqRepo, sectionRepo, advisorRepo, sessionService, passwordPolicy, auditService)
+	instructorService := service.NewInstructorService(instructorRepo, teachesRepo, takesRepo, advisorRepo, sectionRepo, studentRepo, sessionService, passwordPolicy, auditService)
 	courseService := service.NewCourseService(courseRepo, prereqRepo, departmentRepo)
 	sectionService := service.NewSectionService(sectionRepo, courseRepo, classroomRepo, timeSlotRepo)
-	enrollmentService := service.NewEnrollmentService(takesRepo, studentRepo, sectionRepo, courseRepo, prereqRepo, timeSlotRepo, teachesRepo)
-	adminService := service.NewAdminService(studentRepo, instructorRepo, courseRepo, sectionRepo, departmentRepo, classroomRepo, timeSlotRepo, teachesRepo, advisorRepo, prereqRepo)
+	enrollmentService := service.NewEnrollmentService(takesRepo, studentRepo, sectionRepo, courseRepo, prereqRepo, timeSlotRepo, teachesRepo, waitlistRepo, termRepo, overloadRepo, advisorRepo, cartRepo, unitOfWork, auditService)
+	passwordService := service.NewPasswordService(studentRepo, instructorRepo, adminRepo, passwordResetRepo, notifier, passwordPolicy, time.Duration(cfg.Password.ResetExpiration)*time.Second, sessionService, auditService)
+	adminAccountService := service.NewAdminAccountService(adminRepo, sessionService, passwordPolicy, auditService)
+	lockoutService := service.NewLockoutService(loginAttemptRepo, studentRepo, instructorRepo, adminRepo, cfg.Lockout, auditService)
+	permissionService := service.NewPermissionService(roleRepo, courseRepo, departmentRepo, unitOfWork, auditService)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, unitOfWork, cfg.TwoFactor, auditService)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, departmentRepo, unitOfWork, auditService)
+	oidcService := service.NewOIDCService(oidcProvider, oidcRepo, studentRepo, instructorRepo, cfg.OIDC, auditService)
+	adminService := service.NewAdminService(studentRepo, instructorRepo, courseRepo, sectionRepo, departmentRepo, classroomRepo, timeSlotRepo, teachesRepo, advisorRepo, prereqRepo, termRepo, ticketRepo, unitOfWork, sessionService, auditService)
 
 	// 初始化认证中间件
-	authMiddleware := middleware.NewAuthMiddleware()
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, sessionService, permissionService, apiKeyService)
 
 	// 初始化处理器
 	studentHandler := handler.NewStudentHandler(studentService)
-	instructorHandler := handler.NewInstructorHandler(instructorService)
+	instructorHandler := handler.NewInstructorHandler(instructorService, permissionService)
 	courseHandler := handler.NewCourseHandler(courseService)
 	sectionHandler := handler.NewSectionHandler(sectionService)
 	registrationHandler := handler.NewRegistrationHandler(enrollmentService) // 注意这里改为enrollmentService
-	adminHandler := handler.NewAdminHandler(adminService)
-	authHandler := handler.NewAuthHandler(studentService, instructorService)
+	adminHandler := handler.NewAdminHandler(adminService, permissionService)
+	adminAccountHandler := handler.NewAdminAccountHandler(adminAccountService)
+	lockoutHandler := handler.NewLockoutHandler(lockoutService)
+	roleHandler := handler.NewRoleHandler(permissionService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	authHandler := handler.NewAuthHandler(studentService, instructorService, adminAccountService, sessionService, passwordService, lockoutService, twoFactorService, oidcService)
 
 	// 创建路由
 	mux := http.NewServeMux()
 
-	// 添加 CORS 中间件
-	corsMux := middleware.CORSMiddleware(mux)
+	// 添加 CORS 中间件，每个请求按 server.timeout 设置截止时间
+	corsMux := middleware.CORSMiddleware(middleware.TimeoutMiddleware(time.Duration(cfg.Server.Timeout)*time.Second, mux))
 
 	// 认证路由
 	mux.HandleFunc("/api/login", authHandler.Login)
+	mux.HandleFunc("/api/login/2fa", authHandler.LoginTwoFactor)
+	mux.HandleFunc("/api/login/2fa/setup", authHandler.LoginTwoFactorSetup)
+	mux.HandleFunc("/api/token/refresh", authHandler.Refresh)
+	mux.HandleFunc("/api/logout", authMiddleware.AuthenticateAllowPasswordChange(authHandler.Logout))
+	mux.HandleFunc("/api/password/change", authMiddleware.AuthenticateAllowPasswordChange(authHandler.ChangePassword))
+	mux.HandleFunc("/api/password/reset/request", authHandler.RequestPasswordReset)
+	mux.HandleFunc("/api/password/reset", authHandler.ResetPassword)
+
+	// 单点登录路由
+	mux.HandleFunc("/api/oidc/login", authHandler.OIDCLogin)
+	mux.HandleFunc("/api/oidc/callback", authHandler.OIDCCallback)
+
+	// 两步验证路由
+	mux.HandleFunc("/api/2fa/status", authMiddleware.AuthenticateUser(twoFactorHandler.GetStatus))
+	mux.HandleFunc("/api/2fa/enroll", authMiddleware.AuthenticateUser(twoFactorHandler.Enroll))
+	mux.HandleFunc("/api/2fa/confirm", authMiddleware.AuthenticateUser(twoFactorHandler.Confirm))
+	mux.HandleFunc("/api/2fa/disable", authMiddleware.AuthenticateUser(twoFactorHandler.Disable))
+	mux.HandleFunc("/api/2fa/recovery-codes", authMiddleware.AuthenticateUser(twoFactorHandler.RegenerateRecoveryCodes))
 
 	// 学生路由
-	mux.HandleFunc("/api/students/profile", authMiddleware.Authenticate(authMiddleware.AuthorizeStudent(studentHandler.GetProfile)))
-	mux.HandleFunc("/api/students/profile/update", authMiddleware.Authenticate(authMiddleware.AuthorizeStudent(studentHandler.UpdateProfile)))
-	mux.HandleFunc("/api/students/advisor", authMiddleware.Authenticate(authMiddleware.AuthorizeStudent(studentHandler.GetAdvisor)))
-	mux.HandleFunc("/api/students/courses", authMiddleware.Authenticate(authMiddleware.AuthorizeStudent(studentHandler.GetCourses)))
-	mux.HandleFunc("/api/students/transcript", authMiddleware.Authenticate(authMiddleware.AuthorizeStudent(studentHandler.GetTranscript)))
+	mux.HandleFunc("/api/students/profile", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, studentHandler.GetProfile)))
+	mux.HandleFunc("/api/students/profile/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, studentHandler.UpdateProfile)))
+	mux.HandleFunc("/api/students/advisor", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, studentHandler.GetAdvisor)))
+	mux.HandleFunc("/api/students/courses", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, studentHandler.GetCourses)))
+	mux.HandleFunc("/api/students/transcript", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, studentHandler.GetTranscript)))
 
 	// 课程和选课路由
-	mux.HandleFunc("/api/courses", authMiddleware.Authenticate(courseHandler.GetCourses))
-	mux.HandleFunc("/api/sections", authMiddleware.Authenticate(sectionHandler.GetSections))
-	mux.HandleFunc("/api/registration/register", authMiddleware.Authenticate(authMiddleware.AuthorizeStudent(registrationHandler.RegisterCourse)))
-	mux.HandleFunc("/api/registration/drop", authMiddleware.Authenticate(authMiddleware.AuthorizeStudent(registrationHandler.DropCourse)))
+	mux.HandleFunc("/api/courses", authMiddleware.Authenticate(authMiddleware.Require(model.PermCoursesRead, courseHandler.GetCourses)))
+	mux.HandleFunc("/api/sections", authMiddleware.Authenticate(authMiddleware.Require(model.PermCoursesRead, sectionHandler.GetSections)))
+	mux.HandleFunc("/api/registration/register", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.RegisterCourse)))
+	mux.HandleFunc("/api/registration/eligibility", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.CheckEligibility)))
+	mux.HandleFunc("/api/registration/drop", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.DropCourse)))
+	mux.HandleFunc("/api/registration/waitlist", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.GetWaitlist)))
+	mux.HandleFunc("/api/registration/waitlist/join", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.JoinWaitlist)))
+	mux.HandleFunc("/api/registration/waitlist/leave", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.LeaveWaitlist)))
+	mux.HandleFunc("/api/registration/cart", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.GetCart)))
+	mux.HandleFunc("/api/registration/cart/add", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.AddToCart)))
+	mux.HandleFunc("/api/registration/cart/remove", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.RemoveFromCart)))
+	mux.HandleFunc("/api/registration/cart/validate", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.ValidateCart)))
+	mux.HandleFunc("/api/registration/cart/submit", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.SubmitCart)))
+	mux.HandleFunc("/api/registration/credits", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.GetCreditLoad)))
+	mux.HandleFunc("/api/registration/overloads", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.GetOverloadRequests)))
+	mux.HandleFunc("/api/registration/overloads/request", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentPortal, registrationHandler.RequestOverload)))
 
 	// 教师路由
-	mux.HandleFunc("/api/instructors/profile", authMiddleware.Authenticate(authMiddleware.AuthorizeInstructor(instructorHandler.GetProfile)))
-	mux.HandleFunc("/api/instructors/profile/update", authMiddleware.Authenticate(authMiddleware.AuthorizeInstructor(instructorHandler.UpdateProfile)))
-	mux.HandleFunc("/api/instructors/sections", authMiddleware.Authenticate(authMiddleware.AuthorizeInstructor(instructorHandler.GetSections)))
-	mux.HandleFunc("/api/instructors/sections/students", authMiddleware.Authenticate(authMiddleware.AuthorizeInstructor(instructorHandler.GetSectionStudents)))
-	mux.HandleFunc("/api/instructors/grade/update", authMiddleware.Authenticate(authMiddleware.AuthorizeInstructor(instructorHandler.UpdateGrade)))
-	mux.HandleFunc("/api/instructors/advisees", authMiddleware.Authenticate(authMiddleware.AuthorizeInstructor(instructorHandler.GetAdvisees)))
-	mux.HandleFunc("/api/instructors/advisees/info", authMiddleware.Authenticate(authMiddleware.AuthorizeInstructor(instructorHandler.GetAdviseeInfo)))
+	mux.HandleFunc("/api/instructors/profile", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorPortal, instructorHandler.GetProfile)))
+	mux.HandleFunc("/api/instructors/profile/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorPortal, instructorHandler.UpdateProfile)))
+	mux.HandleFunc("/api/instructors/sections", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorPortal, instructorHandler.GetSections)))
+	mux.HandleFunc("/api/instructors/sections/students", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorPortal, instructorHandler.GetSectionStudents)))
+	mux.HandleFunc("/api/instructors/sections/waitlist", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorPortal, registrationHandler.GetSectionWaitlist)))
+	mux.HandleFunc("/api/instructors/overloads", authMiddleware.Authenticate(authMiddleware.Require(model.PermOverloadsDecide, registrationHandler.GetPendingOverloadRequests)))
+	mux.HandleFunc("/api/instructors/overloads/decide", authMiddleware.Authenticate(authMiddleware.Require(model.PermOverloadsDecide, registrationHandler.DecideOverload)))
+	mux.HandleFunc("/api/instructors/overrides", authMiddleware.Authenticate(authMiddleware.Require(model.PermOverridesManage, registrationHandler.GetSectionOverrides)))
+	mux.HandleFunc("/api/instructors/overrides/grant", authMiddleware.Authenticate(authMiddleware.Require(model.PermOverridesManage, registrationHandler.GrantOverride)))
+	mux.HandleFunc("/api/instructors/overrides/revoke", authMiddleware.Authenticate(authMiddleware.Require(model.PermOverridesManage, registrationHandler.RevokeOverride)))
+	mux.HandleFunc("/api/instructors/grade/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermGradesWrite, instructorHandler.UpdateGrade)))
+	mux.HandleFunc("/api/instructors/advisees", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdviseesRead, instructorHandler.GetAdvisees)))
+	mux.HandleFunc("/api/instructors/advisees/info", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdviseesRead, instructorHandler.GetAdviseeInfo)))
 
 	// 管理员路由
-	mux.HandleFunc("/api/admin/students", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetStudents)))
-	mux.HandleFunc("/api/admin/students/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateStudent)))
-	mux.HandleFunc("/api/admin/students/update", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.UpdateStudent)))
-	mux.HandleFunc("/api/admin/students/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteStudent)))
-	mux.HandleFunc("/api/admin/instructors", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetInstructors)))
-	mux.HandleFunc("/api/admin/instructors/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateInstructor)))
-	mux.HandleFunc("/api/admin/instructors/update", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.UpdateInstructor)))
-	mux.HandleFunc("/api/admin/instructors/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteInstructor)))
-	mux.HandleFunc("/api/admin/departments", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetDepartments)))
-	mux.HandleFunc("/api/admin/departments/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateDepartment)))
-	mux.HandleFunc("/api/admin/departments/update", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.UpdateDepartment)))
-	mux.HandleFunc("/api/admin/departments/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteDepartment)))
-	mux.HandleFunc("/api/admin/courses", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetCourses)))
-	mux.HandleFunc("/api/admin/courses/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateCourse)))
-	mux.HandleFunc("/api/admin/courses/update", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.UpdateCourse)))
-	mux.HandleFunc("/api/admin/courses/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteCourse)))
-	mux.HandleFunc("/api/admin/prereqs", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetPrereqs)))
-	mux.HandleFunc("/api/admin/prereqs/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreatePrereq)))
-	mux.HandleFunc("/api/admin/prereqs/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeletePrereq)))
-	mux.HandleFunc("/api/admin/classrooms", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetClassrooms)))
-	mux.HandleFunc("/api/admin/classrooms/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateClassroom)))
-	mux.HandleFunc("/api/admin/classrooms/update", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.UpdateClassroom)))
-	mux.HandleFunc("/api/admin/classrooms/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteClassroom)))
-	mux.HandleFunc("/api/admin/sections", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetSections)))
-	mux.HandleFunc("/api/admin/sections/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateSection)))
-	mux.HandleFunc("/api/admin/sections/update", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.UpdateSection)))
-	mux.HandleFunc("/api/admin/sections/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteSection)))
-	mux.HandleFunc("/api/admin/teaches", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetTeaches)))
-	mux.HandleFunc("/api/admin/teaches/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateTeaches)))
-	mux.HandleFunc("/api/admin/teaches/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteTeaches)))
-	mux.HandleFunc("/api/admin/advisors", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetAdvisors)))
-	mux.HandleFunc("/api/admin/advisors/create", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.CreateAdvisor)))
-	mux.HandleFunc("/api/admin/advisors/delete", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.DeleteAdvisor)))
-	mux.HandleFunc("/api/admin/stats", authMiddleware.Authenticate(authMiddleware.AuthorizeAdmin(adminHandler.GetStats)))
-
-	// 创建HTTP服务器
+	mux.HandleFunc("/api/admin/students", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentsManage, adminHandler.GetStudents)))
+	mux.HandleFunc("/api/admin/students/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentsManage, adminHandler.CreateStudent)))
+	mux.HandleFunc("/api/admin/students/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentsManage, adminHandler.UpdateStudent)))
+	mux.HandleFunc("/api/admin/students/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermStudentsManage, adminHandler.DeleteStudent)))
+	mux.HandleFunc("/api/admin/instructors", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorsManage, adminHandler.GetInstructors)))
+	mux.HandleFunc("/api/admin/instructors/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorsManage, adminHandler.CreateInstructor)))
+	mux.HandleFunc("/api/admin/instructors/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorsManage, adminHandler.UpdateInstructor)))
+	mux.HandleFunc("/api/admin/instructors/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermInstructorsManage, adminHandler.DeleteInstructor)))
+	mux.HandleFunc("/api/admin/departments", authMiddleware.Authenticate(authMiddleware.Require(model.PermDepartmentsManage, adminHandler.GetDepartments)))
+	mux.HandleFunc("/api/admin/departments/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermDepartmentsManage, adminHandler.CreateDepartment)))
+	mux.HandleFunc("/api/admin/departments/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermDepartmentsManage, adminHandler.UpdateDepartment)))
+	mux.HandleFunc("/api/admin/departments/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermDepartmentsManage, adminHandler.DeleteDepartment)))
+	mux.HandleFunc("/api/admin/courses", authMiddleware.Authenticate(authMiddleware.Require(model.PermCoursesManage, adminHandler.GetCourses)))
+	mux.HandleFunc("/api/admin/courses/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermCoursesManage, adminHandler.CreateCourse)))
+	mux.HandleFunc("/api/admin/courses/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermCoursesManage, adminHandler.UpdateCourse)))
+	mux.HandleFunc("/api/admin/courses/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermCoursesManage, adminHandler.DeleteCourse)))
+	mux.HandleFunc("/api/admin/prereqs", authMiddleware.Authenticate(authMiddleware.Require(model.PermPrereqsManage, adminHandler.GetPrereqs)))
+	mux.HandleFunc("/api/admin/prereqs/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermPrereqsManage, adminHandler.CreatePrereq)))
+	mux.HandleFunc("/api/admin/prereqs/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermPrereqsManage, adminHandler.DeletePrereq)))
+	mux.HandleFunc("/api/admin/prereqs/rules", authMiddleware.Authenticate(authMiddleware.Require(model.PermPrereqsManage, adminHandler.GetPrereqRule)))
+	mux.HandleFunc("/api/admin/prereqs/rules/save", authMiddleware.Authenticate(authMiddleware.Require(model.PermPrereqsManage, adminHandler.SavePrereqRule)))
+	mux.HandleFunc("/api/admin/prereqs/rules/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermPrereqsManage, adminHandler.DeletePrereqRule)))
+	mux.HandleFunc("/api/admin/classrooms", authMiddleware.Authenticate(authMiddleware.Require(model.PermClassroomsManage, adminHandler.GetClassrooms)))
+	mux.HandleFunc("/api/admin/classrooms/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermClassroomsManage, adminHandler.CreateClassroom)))
+	mux.HandleFunc("/api/admin/classrooms/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermClassroomsManage, adminHandler.UpdateClassroom)))
+	mux.HandleFunc("/api/admin/classrooms/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermClassroomsManage, adminHandler.DeleteClassroom)))
+	mux.HandleFunc("/api/admin/sections", authMiddleware.Authenticate(authMiddleware.Require(model.PermSectionsManage, adminHandler.GetSections)))
+	mux.HandleFunc("/api/admin/sections/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermSectionsManage, adminHandler.CreateSection)))
+	mux.HandleFunc("/api/admin/sections/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermSectionsManage, adminHandler.UpdateSection)))
+	mux.HandleFunc("/api/admin/sections/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermSectionsManage, adminHandler.DeleteSection)))
+	mux.HandleFunc("/api/admin/teaches", authMiddleware.Authenticate(authMiddleware.Require(model.PermTeachesManage, adminHandler.GetTeaches)))
+	mux.HandleFunc("/api/admin/teaches/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermTeachesManage, adminHandler.CreateTeaches)))
+	mux.HandleFunc("/api/admin/teaches/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermTeachesManage, adminHandler.DeleteTeaches)))
+	mux.HandleFunc("/api/admin/advisors", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdvisorsManage, adminHandler.GetAdvisors)))
+	mux.HandleFunc("/api/admin/advisors/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdvisorsManage, adminHandler.CreateAdvisor)))
+	mux.HandleFunc("/api/admin/advisors/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdvisorsManage, adminHandler.DeleteAdvisor)))
+	mux.HandleFunc("/api/admin/terms", authMiddleware.Authenticate(authMiddleware.Require(model.PermTermsManage, adminHandler.GetTerms)))
+	mux.HandleFunc("/api/admin/terms/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermTermsManage, adminHandler.CreateTerm)))
+	mux.HandleFunc("/api/admin/terms/update", authMiddleware.Authenticate(authMiddleware.Require(model.PermTermsManage, adminHandler.UpdateTerm)))
+	mux.HandleFunc("/api/admin/terms/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermTermsManage, adminHandler.DeleteTerm)))
+	mux.HandleFunc("/api/admin/tickets", authMiddleware.Authenticate(authMiddleware.Require(model.PermTicketsManage, adminHandler.GetTimeTickets)))
+	mux.HandleFunc("/api/admin/tickets/preview", authMiddleware.Authenticate(authMiddleware.Require(model.PermTicketsManage, adminHandler.PreviewTimeTickets)))
+	mux.HandleFunc("/api/admin/tickets/publish", authMiddleware.Authenticate(authMiddleware.Require(model.PermTicketsManage, adminHandler.PublishTimeTickets)))
+	mux.HandleFunc("/api/admin/admins", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdminsManage, adminAccountHandler.GetAdmins)))
+	mux.HandleFunc("/api/admin/admins/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdminsManage, adminAccountHandler.CreateAdmin)))
+	mux.HandleFunc("/api/admin/admins/disable", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdminsManage, adminAccountHandler.DisableAdmin)))
+	mux.HandleFunc("/api/admin/admins/enable", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdminsManage, adminAccountHandler.EnableAdmin)))
+	mux.HandleFunc("/api/admin/admins/rotate", authMiddleware.Authenticate(authMiddleware.Require(model.PermAdminsManage, adminAccountHandler.RotatePassword)))
+	mux.HandleFunc("/api/admin/lockouts", authMiddleware.Authenticate(authMiddleware.Require(model.PermLockoutsManage, lockoutHandler.GetLockouts)))
+	mux.HandleFunc("/api/admin/lockouts/unlock", authMiddleware.Authenticate(authMiddleware.Require(model.PermLockoutsManage, lockoutHandler.Unlock)))
+	mux.HandleFunc("/api/admin/login-attempts", authMiddleware.Authenticate(authMiddleware.Require(model.PermLockoutsManage, lockoutHandler.GetLoginAttempts)))
+	mux.HandleFunc("/api/admin/roles", authMiddleware.Authenticate(authMiddleware.Require(model.PermRolesManage, roleHandler.GetRoles)))
+	mux.HandleFunc("/api/admin/roles/save", authMiddleware.Authenticate(authMiddleware.Require(model.PermRolesManage, roleHandler.SaveRole)))
+	mux.HandleFunc("/api/admin/roles/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermRolesManage, roleHandler.DeleteRole)))
+	mux.HandleFunc("/api/admin/roles/assignments", authMiddleware.Authenticate(authMiddleware.Require(model.PermRolesManage, roleHandler.GetAssignments)))
+	mux.HandleFunc("/api/admin/roles/assign", authMiddleware.Authenticate(authMiddleware.Require(model.PermRolesManage, roleHandler.AssignRole)))
+	mux.HandleFunc("/api/admin/roles/unassign", authMiddleware.Authenticate(authMiddleware.Require(model.PermRolesManage, roleHandler.UnassignRole)))
+	mux.HandleFunc("/api/admin/2fa/policies", authMiddleware.Authenticate(authMiddleware.Require(model.PermTwoFactorManage, twoFactorHandler.GetPolicies)))
+	mux.HandleFunc("/api/admin/2fa/policies/save", authMiddleware.Authenticate(authMiddleware.Require(model.PermTwoFactorManage, twoFactorHandler.SavePolicy)))
+	mux.HandleFunc("/api/admin/2fa/reset", authMiddleware.Authenticate(authMiddleware.Require(model.PermTwoFactorManage, twoFactorHandler.Reset)))
+	mux.HandleFunc("/api/admin/sessions/revoke", authMiddleware.Authenticate(authMiddleware.Require(model.PermSessionsRevoke, authHandler.RevokeSessions)))
+	mux.HandleFunc("/api/admin/audit", authMiddleware.Authenticate(authMiddleware.Require(model.PermAuditRead, auditHandler.GetEntries)))
+	mux.HandleFunc("/api/admin/audit/export", authMiddleware.Authenticate(authMiddleware.Require(model.PermAuditRead, auditHandler.Export)))
+	mux.HandleFunc("/api/admin/api-keys", authMiddleware.Authenticate(authMiddleware.Require(model.PermAPIKeysManage, apiKeyHandler.GetKeys)))
+	mux.HandleFunc("/api/admin/api-keys/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermAPIKeysManage, apiKeyHandler.CreateKey)))
+	mux.HandleFunc("/api/admin/api-keys/revoke", authMiddleware.Authenticate(authMiddleware.Require(model.PermAPIKeysManage, apiKeyHandler.RevokeKey)))
+	mux.HandleFunc("/api/admin/sso/identities", authMiddleware.Authenticate(authMiddleware.Require(model.PermSSOManage, oidcHandler.GetIdentities)))
+	mux.HandleFunc("/api/admin/sso/identities/create", authMiddleware.Authenticate(authMiddleware.Require(model.PermSSOManage, oidcHandler.LinkIdentity)))
+	mux.HandleFunc("/api/admin/sso/identities/delete", authMiddleware.Authenticate(authMiddleware.Require(model.PermSSOManage, oidcHandler.UnlinkIdentity)))
+	mux.HandleFunc("/api/admin/stats", authMiddleware.Authenticate(authMiddleware.Require(model.PermStatsRead, adminHandler.GetStats)))
+
+	// 创建HTTP服务器，所有请求的上下文都派生自baseCtx，关闭超时后取消它以中止仍在执行的查询
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler: corsMux,
+		Addr:        fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:     corsMux,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
 
 	// 启动服务器
@@ -173,44 +313,10 @@ func main() {
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
+		cancelRequests()
+		server.Close()
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
 	log.Println("Server exiting")
 }
-
-// executeSQLScript 读取并执行 SQL 脚本文件
-func executeSQLScript(db *sql.DB, filePath string) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read SQL script file %s: %w", filePath, err)
-	}
-
-	sqls := string(content)
-	// 分割 SQL 语句，这里简单地按分号分割，实际项目中可能需要更复杂的解析
-	statements := splitSQLStatements(sqls)
-
-	for _, stmt := range statements {
-		stmt = trimSpaceAndNewline(stmt)
-		if stmt == "" {
-			continue
-		}
-		_, err := db.Exec(stmt)
-		if err != nil {
-			return fmt.Errorf("failed to execute SQL statement: %s\nError: %w", stmt, err)
-		}
-	}
-	log.Printf("Successfully executed SQL script: %s", filePath)
-	return nil
-}
-
-// splitSQLStatements 辅助函数：分割 SQL 语句
-func splitSQLStatements(sqlContent string) []string {
-	// 简单的分割逻辑，不处理复杂的 SQL 语法，例如包含分号的字符串字面量
-	return strings.Split(sqlContent, ";")
-}
-
-// trimSpaceAndNewline 辅助函数：去除字符串首尾的空格和换行符
-func trimSpaceAndNewline(s string) string {
-	return strings.TrimSpace(strings.Trim(s, "\n\r\t "))
-}
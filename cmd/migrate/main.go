@@ -0,0 +1,155 @@
+// migrate 管理数据库结构迁移。
+//
+//	go run ./cmd/migrate up              执行全部尚未执行的迁移
+//	go run ./cmd/migrate up -to 3        只执行到版本3
+//	go run ./cmd/migrate down            回滚最近一次迁移
+//	go run ./cmd/migrate down -steps 2   回滚最近两次迁移
+//	go run ./cmd/migrate status          查看每个迁移是否已执行、执行后是否被修改
+//	go run ./cmd/migrate create add_x    在每种数据库的迁移目录中新建下一个版本的up和down迁移文件
+//	go run ./cmd/migrate seed            写入开发用的示例数据
+//
+// 迁移根目录默认取配置文件中的 database.migrationsDir，可以用 -dir 覆盖；
+// 实际使用根目录下与 database.driver 对应的子目录（mysql、postgres、sqlite）。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/dialect"
+	"github.com/yourusername/student-management-system/pkg/migrate"
+)
+
+func main() {
+	configPath := flag.String("config", "../config.yaml", "path to config file")
+	dir := flag.String("dir", "", "migrations root directory containing one subdirectory per database (default: database.migrationsDir from config)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: migrate [-config file] [-dir dir] up [-to version] | down [-steps n] | status | create <name> | seed [-file path]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command, args := flag.Arg(0), flag.Args()[1:]
+
+	// 加载配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *dir == "" {
+		*dir = cfg.Database.MigrationsDir
+	}
+
+	// 新建迁移文件不需要连接数据库，每种数据库各写一份，版本号保持一致
+	if command == "create" {
+		if len(args) != 1 {
+			log.Fatal("create requires a migration name")
+		}
+		for _, name := range dialect.Names() {
+			upPath, downPath, err := migrate.Create(filepath.Join(*dir, name), args[0])
+			if err != nil {
+				log.Fatalf("Failed to create %s migration: %v", name, err)
+			}
+			log.Printf("Created %s and %s", upPath, downPath)
+		}
+		return
+	}
+
+	// 连接数据库
+	db, d, err := dialect.Open(cfg.Database.Driver, cfg.Database.DSN)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+
+	if command == "seed" {
+		fs := flag.NewFlagSet("seed", flag.ExitOnError)
+		file := fs.String("file", filepath.Join("../scripts/seed", d.Name()+".sql"), "path to seed script")
+		fs.Parse(args)
+
+		script, err := os.ReadFile(*file)
+		if err != nil {
+			log.Fatalf("Failed to read seed script: %v", err)
+		}
+		if err := migrate.Exec(db, string(script)); err != nil {
+			log.Fatalf("Failed to seed database: %v", err)
+		}
+		log.Printf("Seed data from %s applied", *file)
+		return
+	}
+
+	migrations, err := migrate.Load(os.DirFS(filepath.Join(*dir, d.Name())))
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	migrator := migrate.New(db, d, migrations)
+
+	switch command {
+	case "up":
+		fs := flag.NewFlagSet("up", flag.ExitOnError)
+		to := fs.Int64("to", 0, "migrate up to and including this version (default: latest)")
+		fs.Parse(args)
+
+		applied, err := migrator.Up(*to)
+		if err != nil {
+			log.Fatalf("Failed to migrate: %v", err)
+		}
+		log.Printf("%d migration(s) applied", len(applied))
+
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		fs.Parse(args)
+
+		rolledBack, err := migrator.Down(*steps)
+		if err != nil {
+			log.Fatalf("Failed to roll back: %v", err)
+		}
+		log.Printf("%d migration(s) rolled back", len(rolledBack))
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+		printStatus(statuses)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// printStatus 以表格输出迁移状态
+func printStatus(statuses []*migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		note := ""
+		switch {
+		case s.Missing:
+			note = "file missing"
+		case s.Modified:
+			note = "modified after apply"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+	}
+	w.Flush()
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+type AdminAccountHandler struct {
+	adminAccountService service.AdminAccountService
+}
+
+func NewAdminAccountHandler(adminAccountService service.AdminAccountService) *AdminAccountHandler {
+	return &AdminAccountHandler{
+		adminAccountService: adminAccountService,
+	}
+}
+
+// GetAdmins 获取管理员账号列表
+func (h *AdminAccountHandler) GetAdmins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q, err := parseListQuery(r, model.AdminListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	admins, err := h.adminAccountService.ListAdmins(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get admins")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, admins)
+}
+
+// CreateAdmin 创建管理员账号
+func (h *AdminAccountHandler) CreateAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.AdminCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	admin, err := h.adminAccountService.CreateAdmin(r.Context(), requestActor(r), &req)
+	if err != nil {
+		writeAdminAccountError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, admin)
+}
+
+// DisableAdmin 停用管理员账号
+func (h *AdminAccountHandler) DisableAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Admin ID is required")
+		return
+	}
+
+	if err := h.adminAccountService.DisableAdmin(r.Context(), requestActor(r), id); err != nil {
+		writeAdminAccountError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Admin disabled successfully"})
+}
+
+// EnableAdmin 重新启用管理员账号
+func (h *AdminAccountHandler) EnableAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Admin ID is required")
+		return
+	}
+
+	if err := h.adminAccountService.EnableAdmin(r.Context(), requestActor(r), id); err != nil {
+		writeAdminAccountError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Admin enabled successfully"})
+}
+
+// RotatePassword 为管理员设置新密码
+func (h *AdminAccountHandler) RotatePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.AdminPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.ID == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Admin ID is required")
+		return
+	}
+
+	if err := h.adminAccountService.RotatePassword(r.Context(), requestActor(r), req.ID, req.NewPassword); err != nil {
+		writeAdminAccountError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Password rotated successfully"})
+}
+
+// writeAdminAccountError 将管理员账号服务的错误映射为HTTP状态码
+func writeAdminAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAdminNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrAdminExists), errors.Is(err, service.ErrLastActiveAdmin):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
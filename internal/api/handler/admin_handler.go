@@ -11,12 +11,14 @@ import (
 )
 
 type AdminHandler struct {
-	adminService service.AdminService
+	adminService      service.AdminService
+	permissionService service.PermissionService
 }
 
-func NewAdminHandler(adminService *service.DefaultAdminService) *AdminHandler {
+func NewAdminHandler(adminService *service.DefaultAdminService, permissionService service.PermissionService) *AdminHandler {
 	return &AdminHandler{
-		adminService: adminService,
+		adminService:      adminService,
+		permissionService: permissionService,
 	}
 }
 
@@ -27,9 +29,15 @@ func (h *AdminHandler) GetStudents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	students, err := h.adminService.GetAllStudents()
+	q, err := parseListQuery(r, model.StudentListFields)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get students")
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	students, err := h.adminService.ListStudents(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get students")
 		return
 	}
 
@@ -54,13 +62,17 @@ func (h *AdminHandler) CreateStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.CreateStudent(studentData.ID, studentData.Name, studentData.Dept)
+	password, err := h.adminService.CreateStudent(r.Context(), requestActor(r), studentData.ID, studentData.Name, studentData.Dept)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.WriteJSONResponse(w, http.StatusCreated, map[string]string{"message": "Student created successfully"})
+	// 临时密码只在这里返回一次，学生首次登录后必须修改
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]string{
+		"message":            "Student created successfully",
+		"temporary_password": password,
+	})
 }
 
 // UpdateStudent 更新学生信息
@@ -81,7 +93,7 @@ func (h *AdminHandler) UpdateStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.UpdateStudent(studentData.ID, studentData.Name, studentData.Dept)
+	err := h.adminService.UpdateStudent(r.Context(), requestActor(r), studentData.ID, studentData.Name, studentData.Dept)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -103,7 +115,7 @@ func (h *AdminHandler) DeleteStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.DeleteStudent(studentID)
+	err := h.adminService.DeleteStudent(r.Context(), requestActor(r), studentID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -119,13 +131,36 @@ func (h *AdminHandler) GetInstructors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	instructors, err := h.adminService.GetAllInstructors()
+	q, err := parseListQuery(r, model.InstructorListFields)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get instructors")
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.WriteJSONResponse(w, http.StatusOK, instructors)
+	instructors, err := h.adminService.ListInstructors(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get instructors")
+		return
+	}
+
+	// 只返回有salary.read权限的系部的薪水
+	salaryScope := requestPermissions(r).Scope(model.PermSalaryRead)
+	dtos := make([]*model.InstructorDTO, 0, len(instructors.Items))
+	for _, instructor := range instructors.Items {
+		dto := instructor.ToDTO()
+		if !salaryScope.Allows(instructor.Dept) {
+			dto.Salary = 0
+		}
+		dtos = append(dtos, dto)
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, &model.ListResult[model.InstructorDTO]{
+		Items:      dtos,
+		Total:      instructors.Total,
+		Page:       instructors.Page,
+		PageSize:   instructors.PageSize,
+		NextCursor: instructors.NextCursor,
+	})
 }
 
 // CreateInstructor 创建教师
@@ -147,13 +182,17 @@ func (h *AdminHandler) CreateInstructor(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.adminService.CreateInstructor(instructorData.ID, instructorData.Name, instructorData.Dept, instructorData.Salary)
+	password, err := h.adminService.CreateInstructor(r.Context(), requestActor(r), instructorData.ID, instructorData.Name, instructorData.Dept, instructorData.Salary)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.WriteJSONResponse(w, http.StatusCreated, map[string]string{"message": "Instructor created successfully"})
+	// 临时密码只在这里返回一次，教师首次登录后必须修改
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]string{
+		"message":            "Instructor created successfully",
+		"temporary_password": password,
+	})
 }
 
 // UpdateInstructor 更新教师信息
@@ -175,7 +214,9 @@ func (h *AdminHandler) UpdateInstructor(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.adminService.UpdateInstructor(instructorData.ID, instructorData.Name, instructorData.Dept, instructorData.Salary)
+	// 没有salary.read权限的系部，薪水保持不变
+	salaryScope := requestPermissions(r).Scope(model.PermSalaryRead)
+	err := h.adminService.UpdateInstructor(r.Context(), requestActor(r), instructorData.ID, instructorData.Name, instructorData.Dept, instructorData.Salary, salaryScope)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -197,7 +238,7 @@ func (h *AdminHandler) DeleteInstructor(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.adminService.DeleteInstructor(instructorID)
+	err := h.adminService.DeleteInstructor(r.Context(), requestActor(r), instructorID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -213,9 +254,15 @@ func (h *AdminHandler) GetDepartments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	departments, err := h.adminService.GetAllDepartments()
+	q, err := parseListQuery(r, model.DepartmentListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	departments, err := h.adminService.ListDepartments(r.Context(), q)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get departments")
+		writeListError(w, err, "Failed to get departments")
 		return
 	}
 
@@ -240,7 +287,7 @@ func (h *AdminHandler) CreateDepartment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.adminService.CreateDepartment(deptData.Name, deptData.Building, deptData.Budget)
+	err := h.adminService.CreateDepartment(r.Context(), requestActor(r), deptData.Name, deptData.Building, deptData.Budget)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -267,7 +314,7 @@ func (h *AdminHandler) UpdateDepartment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.adminService.UpdateDepartment(deptData.Name, deptData.Building, deptData.Budget)
+	err := h.adminService.UpdateDepartment(r.Context(), requestActor(r), deptData.Name, deptData.Building, deptData.Budget)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -289,7 +336,7 @@ func (h *AdminHandler) DeleteDepartment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.adminService.DeleteDepartment(deptName)
+	err := h.adminService.DeleteDepartment(r.Context(), requestActor(r), deptName)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -305,9 +352,15 @@ func (h *AdminHandler) GetCourses(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	courses, err := h.adminService.GetAllCourses()
+	q, err := parseListQuery(r, model.CourseListFields)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get courses")
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	courses, err := h.adminService.ListCourses(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get courses")
 		return
 	}
 
@@ -333,7 +386,11 @@ func (h *AdminHandler) CreateCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.CreateCourse(courseData.ID, courseData.Title, courseData.Dept, courseData.Credits)
+	if !checkScope(w, h.permissionService.CheckDepartment(r.Context(), requestScope(r), courseData.Dept)) {
+		return
+	}
+
+	err := h.adminService.CreateCourse(r.Context(), requestActor(r), courseData.ID, courseData.Title, courseData.Dept, courseData.Credits)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -361,7 +418,13 @@ func (h *AdminHandler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.UpdateCourse(courseData.ID, courseData.Title, courseData.Dept, courseData.Credits)
+	// 原课程和修改后的系部都必须在权限范围内
+	if !checkScope(w, h.permissionService.CheckCourse(r.Context(), requestScope(r), courseData.ID)) ||
+		!checkScope(w, h.permissionService.CheckDepartment(r.Context(), requestScope(r), courseData.Dept)) {
+		return
+	}
+
+	err := h.adminService.UpdateCourse(r.Context(), requestActor(r), courseData.ID, courseData.Title, courseData.Dept, courseData.Credits)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -383,7 +446,11 @@ func (h *AdminHandler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.DeleteCourse(courseID)
+	if !checkScope(w, h.permissionService.CheckCourse(r.Context(), requestScope(r), courseID)) {
+		return
+	}
+
+	err := h.adminService.DeleteCourse(r.Context(), requestActor(r), courseID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -392,22 +459,22 @@ func (h *AdminHandler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Course deleted successfully"})
 }
 
-// GetPrereqs 获取先修课程列表
+// GetPrereqs 获取先修课程列表，可以按course_id过滤
 func (h *AdminHandler) GetPrereqs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	courseID := r.URL.Query().Get("course_id")
-	if courseID == "" {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Course ID is required")
+	q, err := parseListQuery(r, model.PrereqListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	prereqs, err := h.adminService.GetPrereqs(courseID)
+	prereqs, err := h.adminService.ListPrereqs(r.Context(), q)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get prerequisites")
+		writeListError(w, err, "Failed to get prerequisites")
 		return
 	}
 
@@ -431,7 +498,11 @@ func (h *AdminHandler) CreatePrereq(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.CreatePrereq(prereqData.CourseID, prereqData.PrereqID)
+	if !checkScope(w, h.permissionService.CheckCourse(r.Context(), requestScope(r), prereqData.CourseID)) {
+		return
+	}
+
+	err := h.adminService.CreatePrereq(r.Context(), requestActor(r), prereqData.CourseID, prereqData.PrereqID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -455,7 +526,11 @@ func (h *AdminHandler) DeletePrereq(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.DeletePrereq(courseID, prereqID)
+	if !checkScope(w, h.permissionService.CheckCourse(r.Context(), requestScope(r), courseID)) {
+		return
+	}
+
+	err := h.adminService.DeletePrereq(r.Context(), requestActor(r), courseID, prereqID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -464,6 +539,80 @@ func (h *AdminHandler) DeletePrereq(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Prerequisite deleted successfully"})
 }
 
+// GetPrereqRule 获取课程的先修规则树
+func (h *AdminHandler) GetPrereqRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	courseID := r.URL.Query().Get("course_id")
+	if courseID == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Course ID is required")
+		return
+	}
+
+	rule, err := h.adminService.GetPrereqRule(r.Context(), courseID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get prerequisite rule")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, model.PrereqRuleRequest{CourseID: courseID, Rule: rule})
+}
+
+// SavePrereqRule 保存课程的先修规则树
+func (h *AdminHandler) SavePrereqRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.PrereqRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !checkScope(w, h.permissionService.CheckCourse(r.Context(), requestScope(r), req.CourseID)) {
+		return
+	}
+
+	err := h.adminService.SavePrereqRule(r.Context(), requestActor(r), &req)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Prerequisite rule saved successfully"})
+}
+
+// DeletePrereqRule 删除课程的先修规则树
+func (h *AdminHandler) DeletePrereqRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	courseID := r.URL.Query().Get("course_id")
+	if courseID == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Course ID is required")
+		return
+	}
+
+	if !checkScope(w, h.permissionService.CheckCourse(r.Context(), requestScope(r), courseID)) {
+		return
+	}
+
+	err := h.adminService.DeletePrereqRule(r.Context(), requestActor(r), courseID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Prerequisite rule deleted successfully"})
+}
+
 // GetClassrooms 获取教室列表
 func (h *AdminHandler) GetClassrooms(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -471,9 +620,15 @@ func (h *AdminHandler) GetClassrooms(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	classrooms, err := h.adminService.GetAllClassrooms()
+	q, err := parseListQuery(r, model.ClassroomListFields)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get classrooms")
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	classrooms, err := h.adminService.ListClassrooms(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get classrooms")
 		return
 	}
 
@@ -498,7 +653,7 @@ func (h *AdminHandler) CreateClassroom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.CreateClassroom(classroomData.Building, classroomData.Room, classroomData.Capacity)
+	err := h.adminService.CreateClassroom(r.Context(), requestActor(r), classroomData.Building, classroomData.Room, classroomData.Capacity)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -525,7 +680,7 @@ func (h *AdminHandler) UpdateClassroom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.UpdateClassroom(classroomData.Building, classroomData.Room, classroomData.Capacity)
+	err := h.adminService.UpdateClassroom(r.Context(), requestActor(r), classroomData.Building, classroomData.Room, classroomData.Capacity)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -549,7 +704,7 @@ func (h *AdminHandler) DeleteClassroom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.DeleteClassroom(building, room)
+	err := h.adminService.DeleteClassroom(r.Context(), requestActor(r), building, room)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -565,9 +720,15 @@ func (h *AdminHandler) GetSections(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sections, err := h.adminService.GetAllSections()
+	q, err := parseListQuery(r, model.SectionListFields)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get sections")
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sections, err := h.adminService.ListSections(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get sections")
 		return
 	}
 
@@ -606,7 +767,11 @@ func (h *AdminHandler) CreateSection(w http.ResponseWriter, r *http.Request) {
 		TimeSlotID: sectionData.TimeSlotID,
 	}
 
-	err := h.adminService.CreateSection(req)
+	if !checkScope(w, h.permissionService.CheckCourse(r.Context(), requestScope(r), req.CourseID)) {
+		return
+	}
+
+	err := h.adminService.CreateSection(r.Context(), requestActor(r), req)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -623,10 +788,7 @@ func (h *AdminHandler) UpdateSection(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var sectionData struct {
-		CourseID   string `json:"course_id"`
-		SecID      string `json:"sec_id"`
-		Semester   string `json:"semester"`
-		Year       int    `json:"year"`
+		model.SectionKey
 		Building   string `json:"building"`
 		Room       string `json:"room_number"`
 		TimeSlotID string `json:"time_slot_id"`
@@ -637,17 +799,23 @@ func (h *AdminHandler) UpdateSection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 使用完整主键作为标识符
+	if err := sectionData.SectionKey.Validate(); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	req := &model.SectionUpdateRequest{
-		Semester:   sectionData.Semester,
-		Year:       sectionData.Year,
 		Building:   sectionData.Building,
 		RoomNumber: sectionData.Room,
 		TimeSlotID: sectionData.TimeSlotID,
 	}
 
-	// 使用课程ID和章节ID作为标识符
-	sectionID := sectionData.SecID
-	err := h.adminService.UpdateSection(sectionID, req)
+	if !checkScope(w, h.permissionService.CheckSection(r.Context(), requestScope(r), sectionData.SectionKey)) {
+		return
+	}
+
+	err := h.adminService.UpdateSection(r.Context(), requestActor(r), sectionData.SectionKey, req)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -679,7 +847,12 @@ func (h *AdminHandler) DeleteSection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.adminService.DeleteSection(courseID, secID, semester, year)
+	key := model.SectionKey{CourseID: courseID, SecID: secID, Semester: semester, Year: year}
+	if !checkScope(w, h.permissionService.CheckSection(r.Context(), requestScope(r), key)) {
+		return
+	}
+
+	err = h.adminService.DeleteSection(r.Context(), requestActor(r), key)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -695,9 +868,15 @@ func (h *AdminHandler) GetTeaches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	teaches, err := h.adminService.GetAllTeaches()
+	q, err := parseListQuery(r, model.TeachesListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	teaches, err := h.adminService.ListTeaches(r.Context(), q)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get teaches")
+		writeListError(w, err, "Failed to get teaches")
 		return
 	}
 
@@ -713,10 +892,7 @@ func (h *AdminHandler) CreateTeaches(w http.ResponseWriter, r *http.Request) {
 
 	var teachesData struct {
 		InstructorID string `json:"instructor_id"`
-		CourseID     string `json:"course_id"`
-		SecID        string `json:"sec_id"`
-		Semester     string `json:"semester"`
-		Year         int    `json:"year"`
+		model.SectionKey
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&teachesData); err != nil {
@@ -724,7 +900,11 @@ func (h *AdminHandler) CreateTeaches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.CreateTeaches(teachesData.InstructorID, teachesData.CourseID, teachesData.SecID, teachesData.Semester, teachesData.Year)
+	if !checkScope(w, h.permissionService.CheckSection(r.Context(), requestScope(r), teachesData.SectionKey)) {
+		return
+	}
+
+	err := h.adminService.CreateTeaches(r.Context(), requestActor(r), teachesData.InstructorID, teachesData.SectionKey)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -757,7 +937,12 @@ func (h *AdminHandler) DeleteTeaches(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.adminService.DeleteTeaches(instructorID, courseID, secID, semester, year)
+	key := model.SectionKey{CourseID: courseID, SecID: secID, Semester: semester, Year: year}
+	if !checkScope(w, h.permissionService.CheckSection(r.Context(), requestScope(r), key)) {
+		return
+	}
+
+	err = h.adminService.DeleteTeaches(r.Context(), requestActor(r), instructorID, key)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -773,9 +958,15 @@ func (h *AdminHandler) GetAdvisors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	advisors, err := h.adminService.GetAllAdvisors()
+	q, err := parseListQuery(r, model.AdvisorListFields)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get advisors")
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	advisors, err := h.adminService.ListAdvisors(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get advisors")
 		return
 	}
 
@@ -799,7 +990,7 @@ func (h *AdminHandler) CreateAdvisor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.CreateAdvisor(advisorData.StudentID, advisorData.InstructorID)
+	err := h.adminService.CreateAdvisor(r.Context(), requestActor(r), advisorData.StudentID, advisorData.InstructorID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -823,7 +1014,7 @@ func (h *AdminHandler) DeleteAdvisor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.adminService.DeleteAdvisor(studentID, instructorID)
+	err := h.adminService.DeleteAdvisor(r.Context(), requestActor(r), studentID, instructorID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -832,6 +1023,168 @@ func (h *AdminHandler) DeleteAdvisor(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Advisor relationship deleted successfully"})
 }
 
+// GetTerms 获取校历列表
+func (h *AdminHandler) GetTerms(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q, err := parseListQuery(r, model.TermListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	terms, err := h.adminService.ListTerms(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get terms")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, terms)
+}
+
+// CreateTerm 创建校历
+func (h *AdminHandler) CreateTerm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var term model.Term
+	if err := json.NewDecoder(r.Body).Decode(&term); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err := h.adminService.CreateTerm(r.Context(), requestActor(r), &term)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, map[string]string{"message": "Term created successfully"})
+}
+
+// UpdateTerm 更新校历
+func (h *AdminHandler) UpdateTerm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var term model.Term
+	if err := json.NewDecoder(r.Body).Decode(&term); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err := h.adminService.UpdateTerm(r.Context(), requestActor(r), &term)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Term updated successfully"})
+}
+
+// DeleteTerm 删除校历
+func (h *AdminHandler) DeleteTerm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	semester := r.URL.Query().Get("semester")
+	yearStr := r.URL.Query().Get("year")
+
+	if semester == "" || yearStr == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Semester and Year are required")
+		return
+	}
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid year format")
+		return
+	}
+
+	err = h.adminService.DeleteTerm(r.Context(), requestActor(r), semester, year)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Term deleted successfully"})
+}
+
+// GetTimeTickets 获取已发布的选课时间票，可以按学期、学生和梯队过滤
+func (h *AdminHandler) GetTimeTickets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q, err := parseListQuery(r, model.TimeTicketListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tickets, err := h.adminService.ListTimeTickets(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get time tickets")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tickets)
+}
+
+// PreviewTimeTickets 预览按规则生成的选课时间票
+func (h *AdminHandler) PreviewTimeTickets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.TimeTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tickets, err := h.adminService.PreviewTimeTickets(r.Context(), &req)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tickets)
+}
+
+// PublishTimeTickets 按规则生成并发布选课时间票，替换该学期已发布的时间票
+func (h *AdminHandler) PublishTimeTickets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.TimeTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tickets, err := h.adminService.PublishTimeTickets(r.Context(), requestActor(r), &req)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tickets)
+}
+
 // GetStats 获取系统统计信息
 func (h *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -839,7 +1192,7 @@ func (h *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := h.adminService.GetSystemStats()
+	stats, err := h.adminService.GetSystemStats(r.Context())
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get system stats")
 		return
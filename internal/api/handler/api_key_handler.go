@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// GetKeys 分页获取API密钥，不返回密钥本身
+func (h *APIKeyHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q, err := parseListQuery(r, model.APIKeyListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get API keys")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, keys)
+}
+
+// CreateKey 创建API密钥，完整密钥只在响应中出现一次
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.APIKeyCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	created, err := h.apiKeyService.CreateKey(r.Context(), requestActor(r), requestPermissions(r), &req)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, created)
+}
+
+// RevokeKey 吊销API密钥
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "API key ID is required")
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(r.Context(), requestActor(r), id); err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "API key revoked successfully"})
+}
+
+// writeAPIKeyError 将API密钥服务的错误映射为HTTP状态码
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrPermissionNotHeld):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetEntries 按操作人、实体和时间范围分页查询审计日志
+func (h *AuditHandler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q, err := parseListQuery(r, model.AuditListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.auditService.ListEntries(r.Context(), filter, q)
+	if err != nil {
+		writeListError(w, err, "Failed to get audit log")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, entries)
+}
+
+// Export 按查询条件导出审计日志，format为csv或json，默认csv
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Format must be csv or json")
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.auditService.ExportEntries(r.Context(), filter)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to export audit log")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=audit-log.%s", format))
+	if format == "json" {
+		utils.WriteJSONResponse(w, http.StatusOK, entries)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "created_at", "actor_id", "actor_role", "action", "entity_type", "entity_key", "before", "after"})
+	for _, entry := range entries {
+		writer.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.CreatedAt.Format(time.RFC3339),
+			entry.ActorID,
+			entry.ActorRole,
+			entry.Action,
+			entry.EntityType,
+			entry.EntityKey,
+			string(entry.Before),
+			string(entry.After),
+		})
+	}
+	writer.Flush()
+}
+
+// parseAuditFilter 从查询参数解析审计日志的查询条件，from和to使用RFC3339格式
+func parseAuditFilter(r *http.Request) (model.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := model.AuditFilter{
+		ActorID:    query.Get("actor_id"),
+		ActorRole:  query.Get("actor_role"),
+		Action:     query.Get("action"),
+		EntityType: query.Get("entity_type"),
+		EntityKey:  query.Get("entity_key"),
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, errors.New("Invalid from time")
+		}
+		filter.From = &t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, errors.New("Invalid to time")
+		}
+		filter.To = &t
+	}
+
+	return filter, nil
+}
+
+// requestActor 返回发起请求的账号，作为审计日志的操作人
+func requestActor(r *http.Request) model.Actor {
+	userID, _ := r.Context().Value("userID").(string)
+	role, _ := r.Context().Value("role").(string)
+	return model.Actor{UserID: userID, Role: role}
+}
@@ -1,24 +1,48 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/service"
 	"github.com/yourusername/student-management-system/pkg/utils"
 )
 
+const (
+	// oidcStateCookie 保存单点登录state的Cookie名称
+	oidcStateCookie = "sms_oidc_state"
+	// oidcCookiePath state Cookie只发送给单点登录的接口
+	oidcCookiePath = "/api/oidc/"
+)
+
 type AuthHandler struct {
-	studentService    service.StudentService
-	instructorService service.InstructorService
+	studentService      service.StudentService
+	instructorService   service.InstructorService
+	adminAccountService service.AdminAccountService
+	sessionService      service.SessionService
+	passwordService     service.PasswordService
+	lockoutService      service.LockoutService
+	twoFactorService    service.TwoFactorService
+	oidcService         service.OIDCService
 }
 
-func NewAuthHandler(studentService service.StudentService, instructorService service.InstructorService) *AuthHandler {
+func NewAuthHandler(studentService service.StudentService, instructorService service.InstructorService, adminAccountService service.AdminAccountService, sessionService service.SessionService, passwordService service.PasswordService, lockoutService service.LockoutService, twoFactorService service.TwoFactorService, oidcService service.OIDCService) *AuthHandler {
 	return &AuthHandler{
-		studentService:    studentService,
-		instructorService: instructorService,
+		studentService:      studentService,
+		instructorService:   instructorService,
+		adminAccountService: adminAccountService,
+		sessionService:      sessionService,
+		passwordService:     passwordService,
+		lockoutService:      lockoutService,
+		twoFactorService:    twoFactorService,
+		oidcService:         oidcService,
 	}
 }
 
@@ -52,7 +76,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 			Password: registerData.Password,
 			Dept:     registerData.Department,
 		}
-		err = h.studentService.CreateStudent(req)
+		err = h.studentService.CreateStudent(r.Context(), req)
 	case "instructor":
 		// 创建教师注册请求
 		req := &model.InstructorCreateRequest{
@@ -61,7 +85,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 			Password: registerData.Password,
 			Dept:     registerData.Department,
 		}
-		err = h.instructorService.CreateInstructor(req)
+		err = h.instructorService.CreateInstructor(r.Context(), req)
 	default:
 		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid user type")
 		return
@@ -97,45 +121,455 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch loginData.Role {
+	case "student", "instructor", "admin":
+	default:
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid role")
+		return
+	}
+
+	attempt := &model.LoginAttempt{
+		UserID:    loginData.UserID,
+		Role:      loginData.Role,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	// 验证密码之前先检查账号是否被锁定或需要等待
+	if err := h.lockoutService.CheckLogin(r.Context(), attempt); err != nil {
+		writeLoginBlocked(w, err)
+		return
+	}
+
 	// 验证用户身份
 	var userID string
 	var err error
 
 	switch loginData.Role {
 	case "student":
-		userID, err = h.studentService.Authenticate(loginData.UserID, loginData.Password)
+		userID, err = h.studentService.Authenticate(r.Context(), loginData.UserID, loginData.Password)
 	case "instructor":
-		userID, err = h.instructorService.Authenticate(loginData.UserID, loginData.Password)
+		userID, err = h.instructorService.Authenticate(r.Context(), loginData.UserID, loginData.Password)
 	case "admin":
-		// 管理员验证逻辑（这里简化处理）
-		if loginData.UserID == "admin" && loginData.Password == "admin123" {
-			userID = "admin"
-		} else {
-			err = utils.NewError("Invalid admin credentials")
-		}
-	default:
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid role")
-		return
+		userID, err = h.adminAccountService.Authenticate(r.Context(), loginData.UserID, loginData.Password)
 	}
 
 	if err != nil {
 		log.Printf("Login failed for user %s (role: %s): %v", loginData.UserID, loginData.Role, err)
+		if err := h.lockoutService.RecordFailure(r.Context(), attempt); err != nil {
+			log.Printf("Failed to record login failure for user %s: %v", loginData.UserID, err)
+		}
 		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
-	// 生成JWT token
-	token, err := utils.GenerateJWT(userID, loginData.UserID, loginData.Role) // 使用 loginData.UserID 替代 userData.Username
+	// 需要两步验证时先返回挑战令牌，第二步通过后才算登录成功
+	challenge, err := h.twoFactorService.BeginLogin(r.Context(), userID, loginData.Role, loginData.UserID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to start two factor login")
+		return
+	}
+	if challenge != nil {
+		utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"two_factor_required": true,
+			"challenge_token":     challenge.Token,
+			"setup_required":      challenge.SetupRequired,
+			"expires_in":          challenge.ExpiresIn,
+		})
+		return
+	}
+
+	if err := h.lockoutService.RecordSuccess(r.Context(), attempt); err != nil {
+		log.Printf("Failed to record login success for user %s: %v", loginData.UserID, err)
+	}
+
+	response, err := h.startLoginSession(r.Context(), userID, loginData.UserID, loginData.Role) // 使用 loginData.UserID 替代 userData.Username
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
 
-	response := map[string]interface{}{
-		"token":   token,
-		"user_id": userID,
-		"role":    loginData.Role,
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// LoginTwoFactor 登录第二步：提交验证码或恢复码换取令牌
+// 错误的验证码与错误的密码一样计入账号锁定
+func (h *AuthHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	challenge, err := h.twoFactorService.FindChallenge(r.Context(), req.ChallengeToken)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	attempt := &model.LoginAttempt{
+		UserID:    challenge.Username,
+		Role:      challenge.Role,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	if err := h.lockoutService.CheckLogin(r.Context(), attempt); err != nil {
+		writeLoginBlocked(w, err)
+		return
+	}
+
+	result, err := h.twoFactorService.CompleteLogin(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTwoFactorCode) {
+			log.Printf("Two factor login failed for user %s (role: %s): %v", challenge.Username, challenge.Role, err)
+			if err := h.lockoutService.RecordFailure(r.Context(), attempt); err != nil {
+				log.Printf("Failed to record login failure for user %s: %v", challenge.Username, err)
+			}
+		}
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	if err := h.lockoutService.RecordSuccess(r.Context(), attempt); err != nil {
+		log.Printf("Failed to record login success for user %s: %v", challenge.Username, err)
+	}
+
+	response, err := h.startLoginSession(r.Context(), result.UserID, result.Username, result.Role)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	// 登录时完成绑定的账号，恢复码只在这里返回一次
+	if len(result.RecoveryCodes) > 0 {
+		response["recovery_codes"] = result.RecoveryCodes
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// LoginTwoFactorSetup 角色要求两步验证但账号尚未绑定时，凭挑战令牌获取绑定信息
+func (h *AuthHandler) LoginTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		ChallengeToken string `json:"challenge_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	enrollment, err := h.twoFactorService.EnrollWithChallenge(r.Context(), req.ChallengeToken)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, enrollment)
+}
+
+// OIDCLogin 跳转到身份提供方的登录页
+func (h *AuthHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	start, err := h.oidcService.BeginLogin(r.Context())
+	if err != nil {
+		if errors.Is(err, service.ErrSSODisabled) {
+			writeOIDCError(w, err)
+			return
+		}
+		log.Printf("Failed to start single sign-on: %v", err)
+		utils.WriteErrorResponse(w, http.StatusBadGateway, "Failed to reach identity provider")
+		return
+	}
+
+	// state绑定到发起登录的浏览器，SameSite=Lax使身份提供方跳转回来的顶层GET请求仍会带上Cookie
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    start.State,
+		Path:     oidcCookiePath,
+		Expires:  start.ExpiresAt,
+		HttpOnly: true,
+		Secure:   start.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, start.AuthURL, http.StatusFound)
+}
+
+// OIDCCallback 身份提供方登录后的回调，映射到本地账号后与密码登录一样检查锁定和两步验证，再签发本系统的令牌
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	if e := query.Get("error"); e != "" {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, "Identity provider returned error: "+e)
+		return
+	}
+
+	// state只能使用一次，无论成功与否都清除Cookie
+	var browserState string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: oidcCookiePath, MaxAge: -1, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+
+	result, err := h.oidcService.CompleteLogin(r.Context(), query.Get("state"), browserState, query.Get("code"))
+	if err != nil {
+		log.Printf("Single sign-on failed: %v", err)
+		writeOIDCError(w, err)
+		return
+	}
+
+	attempt := &model.LoginAttempt{
+		UserID:    result.UserID,
+		Role:      result.Role,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	// 被锁定的账号也不能通过单点登录绕过
+	if err := h.lockoutService.CheckLogin(r.Context(), attempt); err != nil {
+		writeLoginBlocked(w, err)
+		return
+	}
+
+	challenge, err := h.twoFactorService.BeginLogin(r.Context(), result.UserID, result.Role, result.UserID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to start two factor login")
+		return
+	}
+	if challenge != nil {
+		utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"two_factor_required": true,
+			"challenge_token":     challenge.Token,
+			"setup_required":      challenge.SetupRequired,
+			"expires_in":          challenge.ExpiresIn,
+		})
+		return
+	}
+
+	if err := h.lockoutService.RecordSuccess(r.Context(), attempt); err != nil {
+		log.Printf("Failed to record login success for user %s: %v", result.UserID, err)
+	}
+
+	response, err := h.startLoginSession(r.Context(), result.UserID, result.UserID, result.Role)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+		return
 	}
 
 	utils.WriteJSONResponse(w, http.StatusOK, response)
 }
+
+// startLoginSession 登录成功后创建会话，返回登录响应
+func (h *AuthHandler) startLoginSession(ctx context.Context, userID string, username string, role string) (map[string]interface{}, error) {
+	// 管理员创建或重置过密码的账号，登录后只能先修改密码
+	mustChange, err := h.passwordService.MustChangePassword(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建会话并生成令牌
+	tokens, err := h.sessionService.StartSession(ctx, userID, username, role, mustChange)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user_id":       userID,
+		"role":          role,
+
+		"must_change_password": tokens.PasswordChangeRequired,
+	}, nil
+}
+
+// Refresh 使用刷新令牌换取新令牌，旧的刷新令牌随即失效
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tokens)
+}
+
+// Logout 注销当前会话
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	sessionID := r.Context().Value("sessionID").(string)
+	if err := h.sessionService.Logout(r.Context(), sessionID); err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to logout")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
+}
+
+// RevokeSessions 管理员吊销某个用户的全部会话
+func (h *AuthHandler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.RevokeSessionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.UserID == "" || req.Role == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "User ID and role are required")
+		return
+	}
+
+	if err := h.sessionService.RevokeUserSessions(r.Context(), req.UserID, req.Role); err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Sessions revoked successfully"})
+}
+
+// ChangePassword 修改当前用户的密码，成功后其他会话全部失效并返回新的令牌
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID := r.Context().Value("userID").(string)
+	role := r.Context().Value("role").(string)
+
+	if err := h.passwordService.ChangePassword(r.Context(), userID, role, &req); err != nil {
+		if errors.Is(err, service.ErrInvalidOldPassword) {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.sessionService.StartSession(r.Context(), userID, userID, role, false)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, tokens)
+}
+
+// RequestPasswordReset 申请找回密码，无论账号是否存在都返回相同的结果
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.passwordService.RequestReset(r.Context(), &req); err != nil {
+		if errors.Is(err, service.ErrUnknownRole) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid role")
+			return
+		}
+		log.Printf("Password reset request failed for user %s (role: %s): %v", req.UserID, req.Role, err)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "If the account exists, a reset token has been sent"})
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(r.Context(), &req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Password reset successfully"})
+}
+
+// writeLoginBlocked 账号被锁定或需要等待时返回对应的响应
+func writeLoginBlocked(w http.ResponseWriter, err error) {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.Is(err, service.ErrAccountLocked):
+		utils.WriteErrorResponse(w, http.StatusLocked, "Account is locked")
+	case errors.As(err, &throttled):
+		w.Header().Set("Retry-After", strconv.Itoa(int((throttled.RetryAfter+time.Second-1)/time.Second)))
+		utils.WriteErrorResponse(w, http.StatusTooManyRequests, "Too many failed login attempts")
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to check login attempts")
+	}
+}
+
+// clientIP 获取请求的客户端IP
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
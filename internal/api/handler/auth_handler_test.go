@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+)
+
+// fakeOIDCService 记录回调时收到的state，其余方法调用时会panic
+type fakeOIDCService struct {
+	service.OIDCService
+	state        string
+	browserState string
+}
+
+func (s *fakeOIDCService) BeginLogin(ctx context.Context) (*model.OIDCLoginStart, error) {
+	return &model.OIDCLoginStart{AuthURL: "https://idp.example.edu/authorize", State: "id.secret", ExpiresAt: time.Now().Add(10 * time.Minute), Secure: true}, nil
+}
+
+func (s *fakeOIDCService) CompleteLogin(ctx context.Context, state string, browserState string, code string) (*model.OIDCLoginResult, error) {
+	s.state, s.browserState = state, browserState
+	return nil, service.ErrInvalidSSOState
+}
+
+func TestAuthHandler_OIDCStateCookie(t *testing.T) {
+	oidcService := &fakeOIDCService{}
+	h := NewAuthHandler(nil, nil, nil, nil, nil, nil, nil, oidcService)
+
+	w := httptest.NewRecorder()
+	h.OIDCLogin(w, httptest.NewRequest(http.MethodGet, "/api/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("Expected redirect, got %d", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected one state cookie, got %d", len(cookies))
+	}
+	cookie := cookies[0]
+	if cookie.Value != "id.secret" || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("Unexpected state cookie %+v", cookie)
+	}
+
+	// 回调把浏览器中的state交给服务校验，并清除Cookie
+	req := httptest.NewRequest(http.MethodGet, "/api/oidc/callback?state=id.secret&code=c", nil)
+	req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	w = httptest.NewRecorder()
+	h.OIDCCallback(w, req)
+	if oidcService.state != "id.secret" || oidcService.browserState != "id.secret" {
+		t.Errorf("Expected state and browser state to be passed, got %q and %q", oidcService.state, oidcService.browserState)
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+	if cleared := w.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("Expected state cookie to be cleared, got %+v", cleared)
+	}
+}
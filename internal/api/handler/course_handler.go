@@ -25,23 +25,15 @@ func (h *CourseHandler) GetCourses(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 获取查询参数
-	department := r.URL.Query().Get("department")
-	title := r.URL.Query().Get("title")
-
-	// 根据参数获取课程列表
-	var courses []*model.Course
-	var err error
-	
-	if department != "" || title != "" {
-		// 如果有查询参数，使用GetCoursesByDepartment或类似方法
-		courses, err = h.courseService.GetAllCourses()
-	} else {
-		courses, err = h.courseService.GetAllCourses()
+	q, err := parseListQuery(r, model.CourseListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	
+
+	courses, err := h.courseService.ListCourses(r.Context(), q)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get courses")
+		writeListError(w, err, "Failed to get courses")
 		return
 	}
 
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/service"
 	"github.com/yourusername/student-management-system/pkg/utils"
 	"net/http"
@@ -9,11 +10,13 @@ import (
 
 type InstructorHandler struct {
 	instructorService service.InstructorService
+	permissionService service.PermissionService
 }
 
-func NewInstructorHandler(instructorService service.InstructorService) *InstructorHandler {
+func NewInstructorHandler(instructorService service.InstructorService, permissionService service.PermissionService) *InstructorHandler {
 	return &InstructorHandler{
 		instructorService: instructorService,
+		permissionService: permissionService,
 	}
 }
 
@@ -26,7 +29,7 @@ func (h *InstructorHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 
 	instructorID := r.Context().Value("userID").(string)
 
-	instructor, err := h.instructorService.GetByID(instructorID)
+	instructor, err := h.instructorService.GetByID(r.Context(), instructorID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get instructor profile")
 		return
@@ -53,7 +56,7 @@ func (h *InstructorHandler) UpdateProfile(w http.ResponseWriter, r *http.Request
 
 	instructorID := r.Context().Value("userID").(string)
 
-	err := h.instructorService.UpdateProfile(instructorID, updateData.Name)
+	err := h.instructorService.UpdateProfile(r.Context(), instructorID, updateData.Name)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to update profile")
 		return
@@ -71,7 +74,7 @@ func (h *InstructorHandler) GetSections(w http.ResponseWriter, r *http.Request)
 
 	instructorID := r.Context().Value("userID").(string)
 
-	sections, err := h.instructorService.GetTeachingSections(instructorID)
+	sections, err := h.instructorService.GetTeachingSections(r.Context(), instructorID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get sections")
 		return
@@ -87,15 +90,19 @@ func (h *InstructorHandler) GetSectionStudents(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	sectionID := r.URL.Query().Get("section_id")
-	if sectionID == "" {
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Section ID is required")
+	ref, err := sectionRefFromQuery(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key, ok := resolveSection(r.Context(), w, h.instructorService, &ref)
+	if !ok {
 		return
 	}
 
 	instructorID := r.Context().Value("userID").(string)
 
-	students, err := h.instructorService.GetSectionStudents(instructorID, sectionID)
+	students, err := h.instructorService.GetSectionStudents(r.Context(), instructorID, key)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get section students")
 		return
@@ -111,20 +118,26 @@ func (h *InstructorHandler) UpdateGrade(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var gradeData struct {
-		StudentID string `json:"student_id"`
-		SectionID string `json:"section_id"`
-		Grade     string `json:"grade"`
-	}
-
+	var gradeData model.GradeUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&gradeData); err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	key, ok := resolveSection(r.Context(), w, h.instructorService, &gradeData.SectionRef)
+	if !ok {
+		return
+	}
+
 	instructorID := r.Context().Value("userID").(string)
 
-	err := h.instructorService.UpdateGrade(instructorID, gradeData.StudentID, gradeData.SectionID, gradeData.Grade)
+	// 按系部获得grades.write的账号（如助教、系主任）可以登记本系课程段的成绩，其他教师只能登记自己任教的课程段
+	var err error
+	if h.permissionService.CheckSection(r.Context(), requestScope(r), key) == nil {
+		err = h.instructorService.RecordGrade(r.Context(), requestActor(r), gradeData.StudentID, key, gradeData.Grade)
+	} else {
+		err = h.instructorService.UpdateGrade(r.Context(), instructorID, gradeData.StudentID, key, gradeData.Grade)
+	}
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to update grade")
 		return
@@ -142,7 +155,7 @@ func (h *InstructorHandler) GetAdvisees(w http.ResponseWriter, r *http.Request)
 
 	instructorID := r.Context().Value("userID").(string)
 
-	advisees, err := h.instructorService.GetAdvisees(instructorID)
+	advisees, err := h.instructorService.GetAdvisees(r.Context(), instructorID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get advisees")
 		return
@@ -166,7 +179,7 @@ func (h *InstructorHandler) GetAdviseeInfo(w http.ResponseWriter, r *http.Reques
 
 	instructorID := r.Context().Value("userID").(string)
 
-	info, err := h.instructorService.GetAdviseeInfo(instructorID, studentID)
+	info, err := h.instructorService.GetAdviseeInfo(r.Context(), instructorID, studentID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get advisee info")
 		return
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+// parseListQuery 从查询参数读取通用列表查询条件：page、page_size、cursor，
+// sort为逗号分隔的字段列表，字段前加-表示倒序；fields.Filter中的字段按同名参数过滤
+func parseListQuery(r *http.Request, fields model.ListFields) (*model.ListQuery, error) {
+	values := r.URL.Query()
+	q := &model.ListQuery{Cursor: values.Get("cursor")}
+
+	var err error
+	if q.Page, err = listQueryInt(values.Get("page"), "page"); err != nil {
+		return nil, err
+	}
+	if q.PageSize, err = listQueryInt(values.Get("page_size"), "page_size"); err != nil {
+		return nil, err
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			q.Sort = append(q.Sort, model.SortField{Field: strings.TrimPrefix(field, "-"), Desc: desc})
+		}
+	}
+
+	for _, field := range fields.Filter {
+		if value := values.Get(field); value != "" {
+			if q.Filters == nil {
+				q.Filters = make(map[string]string)
+			}
+			q.Filters[field] = value
+		}
+	}
+
+	return q, nil
+}
+
+func listQueryInt(value string, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer", model.ErrInvalidListQuery, name)
+	}
+	return n, nil
+}
+
+// writeListError 列表查询条件不合法时返回400和具体原因，其他错误返回500
+func writeListError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, model.ErrInvalidListQuery) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteErrorResponse(w, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+type LockoutHandler struct {
+	lockoutService service.LockoutService
+}
+
+func NewLockoutHandler(lockoutService service.LockoutService) *LockoutHandler {
+	return &LockoutHandler{
+		lockoutService: lockoutService,
+	}
+}
+
+// GetLockouts 获取账号锁定状态，未指定账号时分页返回被锁定的账号
+func (h *LockoutHandler) GetLockouts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	role := r.URL.Query().Get("role")
+
+	if userID == "" && role == "" {
+		q, err := parseListQuery(r, model.LockedAccountListFields)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		statuses, err := h.lockoutService.ListLockedAccounts(r.Context(), q)
+		if err != nil {
+			writeListError(w, err, "Failed to get locked accounts")
+			return
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, statuses)
+		return
+	}
+
+	if userID == "" || role == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "User ID and role are required")
+		return
+	}
+
+	status, err := h.lockoutService.GetLockStatus(r.Context(), userID, role)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get lock status")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, status)
+}
+
+// Unlock 解锁账号
+func (h *LockoutHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.UnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.UserID == "" || req.Role == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "User ID and role are required")
+		return
+	}
+
+	if err := h.lockoutService.Unlock(r.Context(), requestActor(r), req.UserID, req.Role); err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to unlock account")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Account unlocked successfully"})
+}
+
+// GetLoginAttempts 分页查询登录尝试记录
+func (h *LockoutHandler) GetLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q, err := parseListQuery(r, model.LoginAttemptListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	attempts, err := h.lockoutService.ListLoginAttempts(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get login attempts")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, attempts)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// GetIdentities 分页获取单点登录身份绑定
+func (h *OIDCHandler) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q, err := parseListQuery(r, model.OIDCIdentityListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get identities")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, identities)
+}
+
+// LinkIdentity 把身份提供方账号的subject或邮箱绑定到学生或教师账号
+func (h *OIDCHandler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.OIDCIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	identity, err := h.oidcService.LinkIdentity(r.Context(), requestActor(r), &req)
+	if err != nil {
+		writeOIDCError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, identity)
+}
+
+// UnlinkIdentity 解除身份绑定
+func (h *OIDCHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	role := r.URL.Query().Get("role")
+	userID := r.URL.Query().Get("user_id")
+	if role == "" || userID == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "User ID and role are required")
+		return
+	}
+
+	if err := h.oidcService.UnlinkIdentity(r.Context(), requestActor(r), role, userID); err != nil {
+		writeOIDCError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Identity unlinked successfully"})
+}
+
+// writeOIDCError 将单点登录服务的错误映射为HTTP状态码
+func writeOIDCError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSSODisabled), errors.Is(err, service.ErrSSOIdentityNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrSSOIdentityExists):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidSSOState), errors.Is(err, service.ErrSSORoleNotMapped),
+		errors.Is(err, service.ErrSSOAccountNotLinked):
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -2,8 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/service"
 	"github.com/yourusername/student-management-system/pkg/utils"
 )
@@ -26,7 +29,8 @@ func (h *RegistrationHandler) RegisterCourse(w http.ResponseWriter, r *http.Requ
 	}
 
 	var registrationData struct {
-		SectionID string `json:"section_id"`
+		model.SectionRef
+		PermissionCode string `json:"permission_code,omitempty"` // 教师签发的许可码，可选
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&registrationData); err != nil {
@@ -34,9 +38,24 @@ func (h *RegistrationHandler) RegisterCourse(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &registrationData.SectionRef)
+	if !ok {
+		return
+	}
+
 	studentID := r.Context().Value("userID").(string)
 
-	err := h.enrollmentService.RegisterForCourse(studentID, registrationData.SectionID)
+	var err error
+	if registrationData.PermissionCode != "" {
+		err = h.enrollmentService.RegisterWithPermissionCode(r.Context(), studentID, key, registrationData.PermissionCode)
+	} else {
+		err = h.enrollmentService.RegisterForCourse(r.Context(), studentID, key)
+	}
+	var regErr *service.RegistrationError
+	if errors.As(err, &regErr) {
+		writeRegistrationError(w, regErr)
+		return
+	}
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to register course")
 		return
@@ -45,6 +64,54 @@ func (h *RegistrationHandler) RegisterCourse(w http.ResponseWriter, r *http.Requ
 	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Course registered successfully"})
 }
 
+// CheckEligibility 试运行选课检查，返回学生能否选择该课程段及所有未通过的原因
+func (h *RegistrationHandler) CheckEligibility(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ref, err := sectionRefFromQuery(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &ref)
+	if !ok {
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	result, err := h.enrollmentService.CheckEligibility(r.Context(), studentID, key)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to check eligibility")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// writeRegistrationError 写入选课检查未通过的响应，data中包含结构化的检查结果
+// 只因课程段已满失败时返回409，课程段不存在返回404，其余返回403
+func writeRegistrationError(w http.ResponseWriter, regErr *service.RegistrationError) {
+	result := regErr.Result
+	status := http.StatusForbidden
+	message := "Registration requirements not met"
+	switch {
+	case result.HasReason(model.ReasonSectionNotFound):
+		status = http.StatusNotFound
+		message = "Section not found"
+	case len(result.Reasons) == 1 && result.HasReason(model.ReasonSectionFull):
+		status = http.StatusConflict
+		message = "Section is full, you can join the waitlist"
+	case len(result.Reasons) == 1:
+		message = result.Reasons[0].Message
+	}
+
+	utils.NewResponse(status, message, result).JSON(w)
+}
+
 // DropCourse 学生退课
 func (h *RegistrationHandler) DropCourse(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
@@ -52,19 +119,34 @@ func (h *RegistrationHandler) DropCourse(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var dropData struct {
-		SectionID string `json:"section_id"`
-	}
-
+	var dropData model.SectionRef
 	if err := json.NewDecoder(r.Body).Decode(&dropData); err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &dropData)
+	if !ok {
+		return
+	}
+
 	studentID := r.Context().Value("userID").(string)
 
-	err := h.enrollmentService.DropCourse(studentID, dropData.SectionID)
-	if err != nil {
+	err := h.enrollmentService.DropCourse(r.Context(), studentID, key)
+	switch {
+	case errors.Is(err, service.ErrWithdrawalDeadlinePassed):
+		utils.WriteErrorResponse(w, http.StatusForbidden, "Withdrawal deadline has passed")
+		return
+	case errors.Is(err, service.ErrSectionNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Section not found")
+		return
+	case errors.Is(err, service.ErrEnrollmentNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Enrollment not found")
+		return
+	case errors.Is(err, service.ErrAlreadyWithdrawn):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to drop course")
 		return
 	}
@@ -81,7 +163,7 @@ func (h *RegistrationHandler) GetRegisteredCourses(w http.ResponseWriter, r *htt
 
 	studentID := r.Context().Value("userID").(string)
 
-	courses, err := h.enrollmentService.GetRegisteredCourses(studentID)
+	courses, err := h.enrollmentService.GetRegisteredCourses(r.Context(), studentID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get registered courses")
 		return
@@ -89,3 +171,484 @@ func (h *RegistrationHandler) GetRegisteredCourses(w http.ResponseWriter, r *htt
 
 	utils.WriteJSONResponse(w, http.StatusOK, courses)
 }
+
+// JoinWaitlist 学生加入课程段候补队列
+func (h *RegistrationHandler) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var waitlistData model.WaitlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&waitlistData); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &waitlistData.SectionRef)
+	if !ok {
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	entry, err := h.enrollmentService.JoinWaitlist(r.Context(), studentID, key)
+	var regErr *service.RegistrationError
+	switch {
+	case errors.As(err, &regErr):
+		writeRegistrationError(w, regErr)
+		return
+	case errors.Is(err, service.ErrSectionNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Section not found")
+		return
+	case errors.Is(err, service.ErrAlreadyRegistered), errors.Is(err, service.ErrAlreadyWaitlisted),
+		errors.Is(err, service.ErrSectionNotFull):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to join waitlist")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, entry)
+}
+
+// LeaveWaitlist 学生退出课程段候补队列
+func (h *RegistrationHandler) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var waitlistData model.WaitlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&waitlistData); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &waitlistData.SectionRef)
+	if !ok {
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	err := h.enrollmentService.LeaveWaitlist(r.Context(), studentID, key)
+	if errors.Is(err, service.ErrNotWaitlisted) {
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Waitlist entry not found")
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to leave waitlist")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Left waitlist successfully"})
+}
+
+// GetCart 获取学生的选课购物车
+func (h *RegistrationHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	items, err := h.enrollmentService.GetCart(r.Context(), studentID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get cart")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, items)
+}
+
+// AddToCart 将课程段加入购物车
+func (h *RegistrationHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var cartData model.CartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&cartData); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &cartData.SectionRef)
+	if !ok {
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	item, err := h.enrollmentService.AddToCart(r.Context(), studentID, key)
+	if errors.Is(err, service.ErrSectionNotFound) {
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Section not found")
+		return
+	}
+	if errors.Is(err, service.ErrAlreadyInCart) || errors.Is(err, service.ErrAlreadyRegistered) {
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrRegistrationClosed) {
+		utils.WriteErrorResponse(w, http.StatusForbidden, "Registration is not open for this term")
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to add section to cart")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, item)
+}
+
+// RemoveFromCart 将课程段移出购物车
+func (h *RegistrationHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var cartData model.CartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&cartData); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &cartData.SectionRef)
+	if !ok {
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	err := h.enrollmentService.RemoveFromCart(r.Context(), studentID, key)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Cart item not found")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Section removed from cart"})
+}
+
+// ValidateCart 校验购物车中的所有课程段，不写入数据
+func (h *RegistrationHandler) ValidateCart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	result, err := h.enrollmentService.ValidateCart(r.Context(), studentID)
+	if errors.Is(err, service.ErrCartEmpty) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Cart is empty")
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to validate cart")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// SubmitCart 提交购物车选课，返回每个课程段的结果
+func (h *RegistrationHandler) SubmitCart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var submitData model.CartSubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&submitData); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	result, err := h.enrollmentService.SubmitCart(r.Context(), studentID, submitData.Atomic)
+	if errors.Is(err, service.ErrCartEmpty) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Cart is empty")
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to submit cart")
+		return
+	}
+
+	// 原子提交失败时没有任何课程段被选上
+	if submitData.Atomic && !result.Valid {
+		utils.NewResponse(http.StatusConflict, "Cart submission rolled back", result).JSON(w)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// GetWaitlist 获取学生的候补记录及排队位置
+func (h *RegistrationHandler) GetWaitlist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	entries, err := h.enrollmentService.GetWaitlistPositions(r.Context(), studentID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get waitlist")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, entries)
+}
+
+// GetSectionWaitlist 教师查看所授课程段的候补队列
+func (h *RegistrationHandler) GetSectionWaitlist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ref, err := sectionRefFromQuery(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &ref)
+	if !ok {
+		return
+	}
+
+	instructorID := r.Context().Value("userID").(string)
+
+	entries, err := h.enrollmentService.GetSectionWaitlist(r.Context(), instructorID, key)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get section waitlist")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, entries)
+}
+
+// GetCreditLoad 获取学生某学期的学分负载
+func (h *RegistrationHandler) GetCreditLoad(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	semester := r.URL.Query().Get("semester")
+	yearStr := r.URL.Query().Get("year")
+
+	if semester == "" || yearStr == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Semester and Year are required")
+		return
+	}
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid year format")
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	load, err := h.enrollmentService.GetCreditLoad(r.Context(), studentID, semester, year)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get credit load")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, load)
+}
+
+// RequestOverload 学生提交学分超载申请
+func (h *RegistrationHandler) RequestOverload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.OverloadCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	overload, err := h.enrollmentService.RequestOverload(r.Context(), studentID, &req)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, overload)
+}
+
+// GetOverloadRequests 获取学生的超载申请
+func (h *RegistrationHandler) GetOverloadRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	studentID := r.Context().Value("userID").(string)
+
+	reqs, err := h.enrollmentService.GetOverloadRequests(r.Context(), studentID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get overload requests")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, reqs)
+}
+
+// GetPendingOverloadRequests 导师查看名下学生等待审批的超载申请
+func (h *RegistrationHandler) GetPendingOverloadRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	instructorID := r.Context().Value("userID").(string)
+
+	reqs, err := h.enrollmentService.GetPendingOverloadRequests(r.Context(), instructorID)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get overload requests")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, reqs)
+}
+
+// DecideOverload 导师批准或拒绝超载申请
+func (h *RegistrationHandler) DecideOverload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.OverloadDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.ID == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Overload request ID is required")
+		return
+	}
+
+	instructorID := r.Context().Value("userID").(string)
+
+	overload, err := h.enrollmentService.DecideOverload(r.Context(), instructorID, &req)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, overload)
+}
+
+// GrantOverride 教师为学生签发选课特许或许可码
+func (h *RegistrationHandler) GrantOverride(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.OverrideGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	instructorID := r.Context().Value("userID").(string)
+
+	override, err := h.enrollmentService.GrantOverride(r.Context(), instructorID, &req)
+	if errors.Is(err, service.ErrNotSectionInstructor) {
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrSectionNotFound) {
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Section not found")
+		return
+	}
+	if errors.Is(err, service.ErrAmbiguousSection) {
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, override)
+}
+
+// GetSectionOverrides 教师查看所授课程段签发的选课特许
+func (h *RegistrationHandler) GetSectionOverrides(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ref, err := sectionRefFromQuery(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key, ok := resolveSection(r.Context(), w, h.enrollmentService, &ref)
+	if !ok {
+		return
+	}
+
+	instructorID := r.Context().Value("userID").(string)
+
+	overrides, err := h.enrollmentService.GetSectionOverrides(r.Context(), instructorID, key)
+	if errors.Is(err, service.ErrNotSectionInstructor) {
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get overrides")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, overrides)
+}
+
+// RevokeOverride 教师撤销尚未使用的选课特许
+func (h *RegistrationHandler) RevokeOverride(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Override ID is required")
+		return
+	}
+
+	instructorID := r.Context().Value("userID").(string)
+
+	err := h.enrollmentService.RevokeOverride(r.Context(), instructorID, id)
+	if errors.Is(err, service.ErrNotSectionInstructor) {
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Override revoked successfully"})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+)
+
+// fakeEnrollmentService 只实现退课用到的方法，其余方法调用时会panic
+type fakeEnrollmentService struct {
+	service.EnrollmentService
+	dropErr error
+}
+
+func (s *fakeEnrollmentService) ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error) {
+	return ref.SectionKey, nil
+}
+
+func (s *fakeEnrollmentService) DropCourse(ctx context.Context, studentID string, key model.SectionKey) error {
+	return s.dropErr
+}
+
+func TestRegistrationHandler_DropCourse(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"dropped", nil, http.StatusOK},
+		{"never enrolled", service.ErrEnrollmentNotFound, http.StatusNotFound},
+		{"dropped twice", service.ErrAlreadyWithdrawn, http.StatusConflict},
+		{"after withdrawal deadline", service.ErrWithdrawalDeadlinePassed, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		h := NewRegistrationHandler(&fakeEnrollmentService{dropErr: tt.err})
+		body := `{"course_id":"CS-101","sec_id":"1","semester":"Fall","year":2024}`
+		req := httptest.NewRequest(http.MethodDelete, "/api/registration", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), "userID", "00128"))
+		w := httptest.NewRecorder()
+
+		h.DropCourse(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+type RoleHandler struct {
+	permissionService service.PermissionService
+}
+
+func NewRoleHandler(permissionService service.PermissionService) *RoleHandler {
+	return &RoleHandler{
+		permissionService: permissionService,
+	}
+}
+
+// GetRoles 获取所有角色及其权限
+func (h *RoleHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	roles, err := h.permissionService.GetRoles(r.Context())
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get roles")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, roles)
+}
+
+// SaveRole 创建或更新角色
+func (h *RoleHandler) SaveRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var role model.Role
+	if err := json.NewDecoder(r.Body).Decode(&role); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.permissionService.SaveRole(r.Context(), requestActor(r), &role); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, role)
+}
+
+// DeleteRole 删除角色
+func (h *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Role name is required")
+		return
+	}
+
+	if err := h.permissionService.DeleteRole(r.Context(), requestActor(r), name); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Role deleted successfully"})
+}
+
+// GetAssignments 分页获取角色分配，可按账号、角色或院系过滤
+func (h *RoleHandler) GetAssignments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q, err := parseListQuery(r, model.RoleAssignmentListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	assignments, err := h.permissionService.ListAssignments(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "Failed to get role assignments")
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, assignments)
+}
+
+// AssignRole 为账号分配角色
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.RoleAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	assignment, err := h.permissionService.AssignRole(r.Context(), requestActor(r), &req)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusCreated, assignment)
+}
+
+// UnassignRole 撤销角色分配
+func (h *RoleHandler) UnassignRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid assignment ID")
+		return
+	}
+
+	if err := h.permissionService.UnassignRole(r.Context(), requestActor(r), id); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Role unassigned successfully"})
+}
+
+// writeRoleError 将角色服务的错误映射为HTTP状态码
+func writeRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound), errors.Is(err, service.ErrRoleAssignmentNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrRoleAlreadyAssigned):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// requestScope 获取路由所需权限的适用范围，由AuthMiddleware.Require写入上下文
+func requestScope(r *http.Request) *model.PermissionScope {
+	scope, _ := r.Context().Value("scope").(*model.PermissionScope)
+	return scope
+}
+
+// requestPermissions 获取当前账号的全部权限，由AuthMiddleware.Require写入上下文
+func requestPermissions(r *http.Request) model.PermissionSet {
+	permissions, _ := r.Context().Value("permissions").(model.PermissionSet)
+	return permissions
+}
+
+// checkScope 处理系部范围检查的结果，不在范围内时返回403
+func checkScope(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrOutOfScope):
+		utils.WriteErrorResponse(w, http.StatusForbidden, "Access denied for this department")
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to check permissions")
+	}
+	return false
+}
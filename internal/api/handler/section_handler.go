@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/service"
 	"github.com/yourusername/student-management-system/pkg/utils"
 )
@@ -25,25 +28,67 @@ func (h *SectionHandler) GetSections(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 获取查询参数
-	courseID := r.URL.Query().Get("course_id")
-	semester := r.URL.Query().Get("semester")
-	yearStr := r.URL.Query().Get("year")
-	instructorID := r.URL.Query().Get("instructor_id")
-
-	// 转换year为int
-	var year int
-	if yearStr != "" {
-		if y, err := strconv.Atoi(yearStr); err == nil {
-			year = y
-		}
+	q, err := parseListQuery(r, model.SectionListFields)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	sections, err := h.sectionService.GetSections(courseID, semester, year, instructorID)
+	sections, err := h.sectionService.ListSections(r.Context(), q)
 	if err != nil {
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get sections")
+		writeListError(w, err, "Failed to get sections")
 		return
 	}
 
 	utils.WriteJSONResponse(w, http.StatusOK, sections)
 }
+
+// sectionResolver 能将课程段引用解析为完整主键的服务
+type sectionResolver interface {
+	ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error)
+}
+
+// sectionRefFromQuery 从查询参数course_id、sec_id、semester、year中读取课程段引用，
+// 旧客户端可以只传section_id
+func sectionRefFromQuery(r *http.Request) (model.SectionRef, error) {
+	query := r.URL.Query()
+	ref := model.SectionRef{
+		SectionKey: model.SectionKey{
+			CourseID: query.Get("course_id"),
+			SecID:    query.Get("sec_id"),
+			Semester: query.Get("semester"),
+		},
+		SectionID: query.Get("section_id"),
+	}
+	if yearStr := query.Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			return ref, errors.New("invalid year format")
+		}
+		ref.Year = year
+	}
+	return ref, nil
+}
+
+// resolveSection 将课程段引用解析为完整主键，失败时写入错误响应并返回false
+// 课程段不存在返回404，旧客户端的sec_id对应多个课程段时返回409
+func resolveSection(ctx context.Context, w http.ResponseWriter, resolver sectionResolver, ref *model.SectionRef) (model.SectionKey, bool) {
+	if ref.IsEmpty() {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Section is required")
+		return model.SectionKey{}, false
+	}
+
+	key, err := resolver.ResolveSection(ctx, ref)
+	switch {
+	case errors.Is(err, service.ErrSectionNotFound):
+		utils.WriteErrorResponse(w, http.StatusNotFound, "Section not found")
+		return model.SectionKey{}, false
+	case errors.Is(err, service.ErrAmbiguousSection):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+		return model.SectionKey{}, false
+	case err != nil:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to resolve section")
+		return model.SectionKey{}, false
+	}
+	return key, true
+}
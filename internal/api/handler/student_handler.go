@@ -27,7 +27,7 @@ func (h *StudentHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	// 从JWT中获取学生ID
 	studentID := r.Context().Value("userID").(string)
 
-	student, err := h.studentService.GetByID(studentID)
+	student, err := h.studentService.GetByID(r.Context(), studentID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get student profile")
 		return
@@ -54,7 +54,7 @@ func (h *StudentHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 	studentID := r.Context().Value("userID").(string)
 
-	err := h.studentService.UpdateProfile(studentID, updateData.Name)
+	err := h.studentService.UpdateProfile(r.Context(), studentID, updateData.Name)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to update profile")
 		return
@@ -72,7 +72,7 @@ func (h *StudentHandler) GetAdvisor(w http.ResponseWriter, r *http.Request) {
 
 	studentID := r.Context().Value("userID").(string)
 
-	advisor, err := h.studentService.GetAdvisor(studentID)
+	advisor, err := h.studentService.GetAdvisor(r.Context(), studentID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get advisor")
 		return
@@ -90,7 +90,7 @@ func (h *StudentHandler) GetCourses(w http.ResponseWriter, r *http.Request) {
 
 	studentID := r.Context().Value("userID").(string)
 
-	courses, err := h.studentService.GetEnrolledCourses(studentID)
+	courses, err := h.studentService.GetEnrolledCourses(r.Context(), studentID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get courses")
 		return
@@ -108,7 +108,7 @@ func (h *StudentHandler) GetTranscript(w http.ResponseWriter, r *http.Request) {
 
 	studentID := r.Context().Value("userID").(string)
 
-	transcript, err := h.studentService.GetTranscript(studentID)
+	transcript, err := h.studentService.GetTranscript(r.Context(), studentID)
 	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get transcript")
 		return
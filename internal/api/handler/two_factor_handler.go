@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// GetStatus 获取当前账号的两步验证状态
+func (h *TwoFactorHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID := r.Context().Value("userID").(string)
+	role := r.Context().Value("role").(string)
+
+	status, err := h.twoFactorService.GetStatus(r.Context(), userID, role)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, status)
+}
+
+// Enroll 开始绑定验证器应用，返回密钥和otpauth URI
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID := r.Context().Value("userID").(string)
+	role := r.Context().Value("role").(string)
+
+	enrollment, err := h.twoFactorService.Enroll(r.Context(), userID, role)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, enrollment)
+}
+
+// Confirm 提交验证码确认绑定，返回恢复码
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID := r.Context().Value("userID").(string)
+	role := r.Context().Value("role").(string)
+
+	codes, err := h.twoFactorService.Confirm(r.Context(), userID, role, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
+// Disable 关闭当前账号的两步验证
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID := r.Context().Value("userID").(string)
+	role := r.Context().Value("role").(string)
+
+	if err := h.twoFactorService.Disable(r.Context(), userID, role, &req); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Two factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID := r.Context().Value("userID").(string)
+	role := r.Context().Value("role").(string)
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), userID, role, &req)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
+// GetPolicies 获取各角色的两步验证要求
+func (h *TwoFactorHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	policies, err := h.twoFactorService.GetPolicies(r.Context())
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, policies)
+}
+
+// SavePolicy 设置角色是否必须启用两步验证
+func (h *TwoFactorHandler) SavePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var policy model.TwoFactorPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.twoFactorService.SetPolicy(r.Context(), requestActor(r), &policy); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, policy)
+}
+
+// Reset 管理员清除账号的两步验证
+func (h *TwoFactorHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req model.TwoFactorResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.UserID == "" || req.Role == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "User ID and role are required")
+		return
+	}
+
+	if err := h.twoFactorService.Reset(r.Context(), requestActor(r), req.UserID, req.Role); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Two factor authentication reset successfully"})
+}
+
+// writeTwoFactorError 将两步验证服务的错误转换为HTTP响应
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, service.ErrInvalidChallenge):
+		utils.WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrTwoFactorNotEnrolled):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		utils.WriteErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		utils.WriteErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrUnknownRole):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid role")
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to process two factor request")
+	}
+}
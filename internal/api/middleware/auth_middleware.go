@@ -2,20 +2,65 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/service"
 	"github.com/yourusername/student-management-system/pkg/utils"
 )
 
-type AuthMiddleware struct{}
+// SessionChecker 检查访问令牌所属的会话是否已被吊销
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// PermissionResolver 计算账号拥有的权限
+type PermissionResolver interface {
+	Permissions(ctx context.Context, userID string, role string) (model.PermissionSet, error)
+}
+
+// APIKeyAuthenticator 校验外部系统使用的API密钥
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*model.APIKey, error)
+}
 
-func NewAuthMiddleware() *AuthMiddleware {
-	return &AuthMiddleware{}
+type AuthMiddleware struct {
+	jwtManager  *utils.JWTManager
+	sessions    SessionChecker
+	permissions PermissionResolver
+	apiKeys     APIKeyAuthenticator
 }
 
-// Authenticate 验证JWT token
+func NewAuthMiddleware(jwtManager *utils.JWTManager, sessions SessionChecker, permissions PermissionResolver, apiKeys APIKeyAuthenticator) *AuthMiddleware {
+	return &AuthMiddleware{
+		jwtManager:  jwtManager,
+		sessions:    sessions,
+		permissions: permissions,
+		apiKeys:     apiKeys,
+	}
+}
+
+// Authenticate 验证JWT token或API密钥，必须先修改密码的账号会被拒绝
+// API密钥使用 "Authorization: ApiKey <密钥>" 请求头
 func (m *AuthMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return m.authenticate(next, false, true)
+}
+
+// AuthenticateUser 只接受登录用户的JWT token，用于两步验证等针对账号本人的接口
+func (m *AuthMiddleware) AuthenticateUser(next http.HandlerFunc) http.HandlerFunc {
+	return m.authenticate(next, false, false)
+}
+
+// AuthenticateAllowPasswordChange 验证JWT token，允许必须先修改密码的账号访问
+// 只用于修改密码和注销接口
+func (m *AuthMiddleware) AuthenticateAllowPasswordChange(next http.HandlerFunc) http.HandlerFunc {
+	return m.authenticate(next, true, false)
+}
+
+// authenticate 验证JWT token或API密钥并将用户信息写入请求上下文
+func (m *AuthMiddleware) authenticate(next http.HandlerFunc, allowPasswordChange bool, allowAPIKey bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -23,8 +68,16 @@ func (m *AuthMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		// 检查Bearer token格式
+		// 检查Bearer token或ApiKey格式
 		tokenParts := strings.Split(authHeader, " ")
+		if len(tokenParts) == 2 && tokenParts[0] == "ApiKey" {
+			if !allowAPIKey {
+				utils.WriteErrorResponse(w, http.StatusUnauthorized, "API keys are not accepted for this endpoint")
+				return
+			}
+			m.authenticateAPIKey(w, r, tokenParts[1], next)
+			return
+		}
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
 			utils.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid authorization header format")
 			return
@@ -33,52 +86,83 @@ func (m *AuthMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
 		token := tokenParts[1]
 
 		// 验证JWT token
-		claims, err := utils.ValidateJWT(token)
+		claims, err := m.jwtManager.Validate(token)
 		if err != nil {
 			utils.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid token")
 			return
 		}
 
+		// 会话已注销或被吊销的令牌不再有效
+		active, err := m.sessions.IsSessionActive(r.Context(), claims.SessionID)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to check session")
+			return
+		}
+		if !active {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, "Session has been revoked")
+			return
+		}
+
+		if claims.PasswordChangeRequired && !allowPasswordChange {
+			utils.WriteErrorResponse(w, http.StatusForbidden, "Password change required")
+			return
+		}
+
 		// 将用户信息添加到请求上下文
 		ctx := context.WithValue(r.Context(), "userID", claims.UserID)
 		ctx = context.WithValue(ctx, "role", claims.Role)
+		ctx = context.WithValue(ctx, "sessionID", claims.SessionID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
 
-// AuthorizeStudent 授权学生访问
-func (m *AuthMiddleware) AuthorizeStudent(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		role := r.Context().Value("role").(string)
-		if role != "student" && role != "admin" {
-			utils.WriteErrorResponse(w, http.StatusForbidden, "Access denied")
+// authenticateAPIKey 验证API密钥，密钥ID作为userID写入请求上下文，权限直接取自密钥
+func (m *AuthMiddleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, token string, next http.HandlerFunc) {
+	key, err := m.apiKeys.Authenticate(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid API key")
 			return
 		}
-		next.ServeHTTP(w, r)
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to check API key")
+		return
 	}
+
+	ctx := context.WithValue(r.Context(), "userID", key.ID)
+	ctx = context.WithValue(ctx, "role", model.RoleAPIKey)
+	ctx = context.WithValue(ctx, "sessionID", "")
+	ctx = context.WithValue(ctx, "permissions", key.PermissionSet())
+
+	next.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// AuthorizeInstructor 授权教师访问
-func (m *AuthMiddleware) AuthorizeInstructor(next http.HandlerFunc) http.HandlerFunc {
+// Require 要求账号拥有指定权限
+// 权限集合和该权限的适用范围写入请求上下文，处理器据此检查系部范围
+// 使用API密钥时权限集合在认证时已经写入上下文
+func (m *AuthMiddleware) Require(permission model.Permission, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		role := r.Context().Value("role").(string)
-		if role != "instructor" && role != "admin" {
-			utils.WriteErrorResponse(w, http.StatusForbidden, "Access denied")
-			return
+		permissions, ok := r.Context().Value("permissions").(model.PermissionSet)
+		if !ok {
+			userID := r.Context().Value("userID").(string)
+			role := r.Context().Value("role").(string)
+
+			var err error
+			permissions, err = m.permissions.Permissions(r.Context(), userID, role)
+			if err != nil {
+				utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to check permissions")
+				return
+			}
 		}
-		next.ServeHTTP(w, r)
-	}
-}
 
-// AuthorizeAdmin 授权管理员访问
-func (m *AuthMiddleware) AuthorizeAdmin(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		role := r.Context().Value("role").(string)
-		if role != "admin" {
+		if !permissions.Has(permission) {
 			utils.WriteErrorResponse(w, http.StatusForbidden, "Access denied")
 			return
 		}
-		next.ServeHTTP(w, r)
+
+		ctx := context.WithValue(r.Context(), "permissions", permissions)
+		ctx = context.WithValue(ctx, "scope", permissions.Scope(permission))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// AdminStatus 表示管理员账号的状态
+type AdminStatus string
+
+const (
+	AdminStatusActive   AdminStatus = "active"   // 正常
+	AdminStatusDisabled AdminStatus = "disabled" // 已停用，不能登录
+)
+
+// Admin 表示管理员账号
+type Admin struct {
+	ID          string      `json:"id"`                      // 登录名
+	Name        string      `json:"name"`                    // 姓名
+	Password    string      `json:"-"`                       // bcrypt哈希
+	Status      AdminStatus `json:"status"`                  // 状态
+	CreatedAt   time.Time   `json:"created_at"`              // 创建时间
+	UpdatedAt   time.Time   `json:"updated_at"`              // 最近修改时间
+	LastLoginAt *time.Time  `json:"last_login_at,omitempty"` // 最近登录时间
+	// MustChangePassword 由其他管理员创建或重置密码后，下次登录必须修改密码
+	MustChangePassword bool `json:"must_change_password"`
+}
+
+// AdminCreateRequest 表示创建管理员账号的请求
+type AdminCreateRequest struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// Validate 检查创建请求，密码强度由服务层按密码策略检查
+func (r *AdminCreateRequest) Validate() error {
+	if r.ID == "" || r.Name == "" {
+		return errors.New("id and name are required")
+	}
+	return nil
+}
+
+// AdminPasswordRequest 表示轮换管理员密码的请求
+type AdminPasswordRequest struct {
+	ID          string `json:"id"`
+	NewPassword string `json:"new_password"`
+}
+
+// AdminListFields 管理员列表支持的排序和过滤字段
+var AdminListFields = ListFields{
+	Sort:    []string{"name", "status", "created_at", "last_login_at"},
+	Filter:  []string{"status"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "id"}},
+}
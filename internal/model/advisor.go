@@ -14,4 +14,12 @@ type Advisor struct {
 type AdvisorCreateRequest struct {
 	StudentID    string `json:"student_id"`
 	InstructorID string `json:"instructor_id"`
-}
\ No newline at end of file
+}
+
+// AdvisorListFields 导师关系列表支持的排序和过滤字段
+var AdvisorListFields = ListFields{
+	Sort:    []string{"instructor_id"},
+	Filter:  []string{"student_id", "instructor_id"},
+	Key:     []string{"student_id"},
+	Default: []SortField{{Field: "student_id"}},
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// RoleAPIKey 使用API密钥访问时写入请求上下文的角色，userID为密钥ID
+const RoleAPIKey = "api_key"
+
+// APIKey 表示供外部系统调用接口的API密钥，只保存密钥随机串的哈希
+type APIKey struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	KeyHash     string       `json:"-"`
+	Permissions []Permission `json:"permissions"`
+	// Dept 密钥权限适用的系部，为空表示不限系部
+	Dept       string     `json:"dept_name,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // 为空表示不过期
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive 判断密钥在给定时间是否仍然有效
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// PermissionSet 返回密钥拥有的权限，指定了系部时只作用于该系部
+func (k *APIKey) PermissionSet() PermissionSet {
+	set := make(PermissionSet)
+	for _, p := range k.Permissions {
+		set.Grant(p, k.Dept, false)
+	}
+	return set
+}
+
+// APIKeyCreateRequest 表示创建API密钥的请求
+type APIKeyCreateRequest struct {
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+	Dept        string       `json:"dept_name"`
+	ExpiresAt   *time.Time   `json:"expires_at"`
+}
+
+// Validate 检查创建API密钥的请求
+// 学生和教师自助权限只作用于本人数据，不能授予API密钥
+func (r *APIKeyCreateRequest) Validate(now time.Time) error {
+	if r.Name == "" {
+		return errors.New("api key name is required")
+	}
+	if len(r.Permissions) == 0 {
+		return errors.New("api key must grant at least one permission")
+	}
+	for _, p := range r.Permissions {
+		if !IsKnownPermission(p) {
+			return errors.New("unknown permission: " + string(p))
+		}
+		if p == PermStudentPortal || p == PermInstructorPortal {
+			return errors.New("permission cannot be granted to an api key: " + string(p))
+		}
+		if r.Dept != "" && !IsDeptScopedPermission(p) {
+			return errors.New("permission cannot be scoped to a department: " + string(p))
+		}
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
+
+// APIKeyCreated 表示新建的API密钥，Key只在创建时返回一次
+type APIKeyCreated struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyListFields API密钥列表支持的排序和过滤字段
+var APIKeyListFields = ListFields{
+	Sort:    []string{"name", "created_at", "created_by"},
+	Filter:  []string{"created_by", "dept_name"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "created_at"}},
+}
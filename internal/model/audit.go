@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Actor 表示执行操作的账号
+type Actor struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// SystemActor 表示命令行工具等不经过登录的操作
+var SystemActor = Actor{UserID: "system", Role: "system"}
+
+// 审计日志的操作类型
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// 审计日志记录的实体类型
+const (
+	AuditEntityStudent         = "student"
+	AuditEntityInstructor      = "instructor"
+	AuditEntityAdmin           = "admin"
+	AuditEntityCourse          = "course"
+	AuditEntitySection         = "section"
+	AuditEntityDepartment      = "department"
+	AuditEntityClassroom       = "classroom"
+	AuditEntityPrereq          = "prereq"
+	AuditEntityPrereqRule      = "prereq_rule"
+	AuditEntityTeaches         = "teaches"
+	AuditEntityAdvisor         = "advisor"
+	AuditEntityTerm            = "term"
+	AuditEntityTimeTickets     = "time_tickets"
+	AuditEntityGrade           = "grade"
+	AuditEntityEnrollment      = "enrollment"
+	AuditEntityWaitlist        = "waitlist"
+	AuditEntityCart            = "cart"
+	AuditEntityOverload        = "overload"
+	AuditEntityOverride        = "override"
+	AuditEntityRole            = "role"
+	AuditEntityRoleAssignment  = "role_assignment"
+	AuditEntityPassword        = "password"
+	AuditEntityTwoFactor       = "two_factor"
+	AuditEntityTwoFactorPolicy = "two_factor_policy"
+	AuditEntityLockout         = "lockout"
+	AuditEntityAPIKey          = "api_key"
+	AuditEntitySSOIdentity     = "sso_identity"
+)
+
+// AuditKey 把复合主键的各部分拼接为审计日志中的实体键
+func AuditKey(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// AuditEntry 表示一条审计日志，写入后不再修改或删除
+// Before和After是变更前后实体的JSON快照，新建时Before为空，删除时After为空
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	ActorID    string          `json:"actor_id"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityKey  string          `json:"entity_key"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter 表示查询审计日志的条件，时间范围包含From、不包含To
+type AuditFilter struct {
+	ActorID    string
+	ActorRole  string
+	Action     string
+	EntityType string
+	EntityKey  string
+	From       *time.Time
+	To         *time.Time
+	Limit      int // 为0表示不限制条数，只用于导出
+}
+
+// AuditListFields 审计日志列表支持的排序字段，过滤条件使用AuditFilter，默认最新的在前
+var AuditListFields = ListFields{
+	Sort:    []string{"created_at"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "id", Desc: true}},
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// CartItem 表示学生选课购物车中的一个课程段
+type CartItem struct {
+	StudentID string    `json:"student_id"` // 学生ID
+	CourseID  string    `json:"course_id"`  // 课程ID
+	SectionID string    `json:"section_id"` // 章节ID
+	Semester  string    `json:"semester"`   // 学期
+	Year      int       `json:"year"`       // 年份
+	AddedAt   time.Time `json:"added_at"`   // 加入时间
+
+	// 关联信息
+	Course *Course `json:"course,omitempty"` // 课程信息
+}
+
+// CartItemRequest 表示加入或移出购物车的请求
+type CartItemRequest struct {
+	SectionRef
+}
+
+// CartSubmitRequest 表示提交购物车的请求
+// Atomic 为 true 时任一课程段未通过检查则全部不选；为 false 时逐个选课并返回每一项的结果
+type CartSubmitRequest struct {
+	Atomic bool `json:"atomic"`
+}
+
+// CartItemResult 表示购物车中一个课程段的检查或选课结果
+type CartItemResult struct {
+	Section     SectionKey         `json:"section"`
+	Registered  bool               `json:"registered"` // 是否已经选课成功（仅提交时有效）
+	Eligibility *EligibilityResult `json:"eligibility"`
+}
+
+// CartResult 表示校验或提交购物车的结果
+type CartResult struct {
+	Valid  bool              `json:"valid"`  // 所有课程段都通过检查
+	Atomic bool              `json:"atomic"` // 是否按原子方式提交
+	Items  []*CartItemResult `json:"items"`
+}
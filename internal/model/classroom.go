@@ -19,3 +19,11 @@ type ClassroomCreateRequest struct {
 type ClassroomUpdateRequest struct {
 	Capacity int `json:"capacity"`
 }
+
+// ClassroomListFields 教室列表支持的排序和过滤字段
+var ClassroomListFields = ListFields{
+	Sort:    []string{"capacity"},
+	Filter:  []string{"building"},
+	Key:     []string{"building", "room_number"},
+	Default: []SortField{{Field: "building"}},
+}
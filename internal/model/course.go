@@ -39,3 +39,18 @@ type CourseUpdateRequest struct {
 	Dept    string  `json:"dept"`
 	Credits float64 `json:"credits"`
 }
+
+// CourseListFields 课程列表支持的排序和过滤字段
+var CourseListFields = ListFields{
+	Sort:    []string{"title", "dept", "credits"},
+	Filter:  []string{"dept"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "id"}},
+}
+
+// PrereqListFields 先修关系列表支持的排序和过滤字段
+var PrereqListFields = ListFields{
+	Filter:  []string{"course_id", "prereq_id"},
+	Key:     []string{"course_id", "prereq_id"},
+	Default: []SortField{{Field: "course_id"}},
+}
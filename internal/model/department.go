@@ -30,3 +30,11 @@ type DepartmentStats struct {
 	InstructorCount int        `json:"instructor_count"` // 教师数量
 	CourseCount     int        `json:"course_count"`     // 课程数量
 }
+
+// DepartmentListFields 院系列表支持的排序和过滤字段
+var DepartmentListFields = ListFields{
+	Sort:    []string{"building", "budget"},
+	Filter:  []string{"building"},
+	Key:     []string{"dept_name"},
+	Default: []SortField{{Field: "dept_name"}},
+}
@@ -0,0 +1,86 @@
+package model
+
+// EligibilityReasonCode 表示选课资格检查未通过的原因代码，前端据此展示具体的提示
+type EligibilityReasonCode string
+
+const (
+	ReasonSectionNotFound     EligibilityReasonCode = "SECTION_NOT_FOUND"     // 课程段不存在
+	ReasonRegistrationClosed  EligibilityReasonCode = "REGISTRATION_CLOSED"   // 不在选课时间窗口内
+	ReasonBeforeTimeTicket    EligibilityReasonCode = "BEFORE_TIME_TICKET"    // 选课时间票尚未开始
+	ReasonAlreadyRegistered   EligibilityReasonCode = "ALREADY_REGISTERED"    // 已经选过该课程段
+	ReasonPrereqsNotSatisfied EligibilityReasonCode = "PREREQS_NOT_SATISFIED" // 不满足先修规则
+	ReasonTimeConflict        EligibilityReasonCode = "TIME_CONFLICT"         // 与已选课程时间冲突
+	ReasonCreditLimitExceeded EligibilityReasonCode = "CREDIT_LIMIT_EXCEEDED" // 超过学期学分上限
+	ReasonSectionFull         EligibilityReasonCode = "SECTION_FULL"          // 课程段已满
+	ReasonInvalidPermission   EligibilityReasonCode = "INVALID_PERMISSION"    // 许可码无效、已使用或不属于该学生和课程段
+)
+
+// TimeConflict 表示与待选课程段时间冲突的已选课程段及冲突的时间段
+type TimeConflict struct {
+	Section    SectionKey `json:"section"`
+	TimeSlotID string     `json:"time_slot_id"`
+	Day        string     `json:"day"`
+	StartTime  string     `json:"start_time"` // HH:MM
+	EndTime    string     `json:"end_time"`   // HH:MM
+}
+
+// EligibilityReason 表示一条未通过的检查
+type EligibilityReason struct {
+	Code       EligibilityReasonCode `json:"code"`
+	Message    string                `json:"message"`
+	CourseIDs  []string              `json:"course_ids,omitempty"`  // 相关课程，如未满足的先修课程
+	Section    *SectionKey           `json:"section,omitempty"`     // 相关课程段，如冲突的已选课程段
+	Conflict   *TimeConflict         `json:"conflict,omitempty"`    // 时间冲突详情
+	OverrideID string                `json:"override_id,omitempty"` // 豁免该检查的教师特许
+}
+
+// EligibilityResult 表示学生能否选择某课程段的检查结果
+type EligibilityResult struct {
+	Eligible  bool                 `json:"eligible"`
+	StudentID string               `json:"student_id"`
+	Section   SectionKey           `json:"section"`
+	Reasons   []*EligibilityReason `json:"reasons"`
+	Waived    []*EligibilityReason `json:"waived,omitempty"` // 被教师特许豁免的检查
+}
+
+// NewEligibilityResult 创建检查结果，初始为可选
+func NewEligibilityResult(studentID string, key SectionKey) *EligibilityResult {
+	return &EligibilityResult{
+		Eligible:  true,
+		StudentID: studentID,
+		Section:   key,
+		Reasons:   []*EligibilityReason{},
+	}
+}
+
+// AddReason 记录一条未通过的检查
+func (r *EligibilityResult) AddReason(reason *EligibilityReason) {
+	r.Eligible = false
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// HasReason 判断结果中是否包含指定原因
+func (r *EligibilityResult) HasReason(code EligibilityReasonCode) bool {
+	for _, reason := range r.Reasons {
+		if reason.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Waive 将被特许豁免的原因从Reasons移到Waived，waivedBy返回豁免该检查的特许ID，返回空字符串表示不豁免
+// 没有剩余原因时结果变为可选
+func (r *EligibilityResult) Waive(waivedBy func(code EligibilityReasonCode) string) {
+	remaining := make([]*EligibilityReason, 0, len(r.Reasons))
+	for _, reason := range r.Reasons {
+		if overrideID := waivedBy(reason.Code); overrideID != "" {
+			reason.OverrideID = overrideID
+			r.Waived = append(r.Waived, reason)
+			continue
+		}
+		remaining = append(remaining, reason)
+	}
+	r.Reasons = remaining
+	r.Eligible = len(remaining) == 0
+}
@@ -1,5 +1,9 @@
 package model
 
+// GradeWithdrawn 表示退选（W），在退课截止后、退选截止前退课时记录，
+// 不计入容量、时间冲突、先修课程和GPA
+const GradeWithdrawn = "W"
+
 // IsValidGrade 检查成绩是否有效
 func IsValidGrade(grade string) bool {
 	validGrades := map[string]bool{
@@ -21,7 +25,55 @@ func IsValidGrade(grade string) bool {
 // IsPassingGrade 检查是否及格
 func IsPassingGrade(grade string) bool {
 	failingGrades := map[string]bool{
-		"F": true,
+		"F":            true,
+		GradeWithdrawn: true,
 	}
 	return !failingGrades[grade]
 }
+
+// gradeOrder 成绩从低到高的顺序，用于比较最低成绩要求
+var gradeOrder = []string{"F", "D", "D+", "C-", "C", "C+", "B-", "B", "B+", "A-", "A"}
+
+// GradeAtLeast 判断成绩grade是否不低于minGrade，无法比较的成绩（如W）返回false
+func GradeAtLeast(grade string, minGrade string) bool {
+	rank := func(g string) int {
+		for i, o := range gradeOrder {
+			if o == g {
+				return i
+			}
+		}
+		return -1
+	}
+	r := rank(grade)
+	return r >= 0 && r >= rank(minGrade)
+}
+
+// GradePoint 返回成绩对应的绩点，无效成绩和退选为0
+func GradePoint(grade string) float64 {
+	switch grade {
+	case "A":
+		return 4.0
+	case "A-":
+		return 3.7
+	case "B+":
+		return 3.3
+	case "B":
+		return 3.0
+	case "B-":
+		return 2.7
+	case "C+":
+		return 2.3
+	case "C":
+		return 2.0
+	case "C-":
+		return 1.7
+	case "D+":
+		return 1.3
+	case "D":
+		return 1.0
+	case "F":
+		return 0.0
+	default:
+		return 0.0
+	}
+}
@@ -8,6 +8,7 @@ type Instructor struct {
 	Salary   float64 `json:"salary"`   // 薪水
 	Password string  `json:"password,omitempty"` // 密码（哈希后）
 	Salt     string  `json:"salt,omitempty"`     // 密码盐值
+	MustChangePassword bool `json:"must_change_password"` // 下次登录必须修改密码
 }
 
 // InstructorDTO 表示教师数据传输对象（不包含敏感信息）
@@ -28,6 +29,24 @@ func (i *Instructor) ToDTO() *InstructorDTO {
 	}
 }
 
+// InstructorAuditSnapshot 表示审计日志中的教师快照
+// 审计日志只需要audit.read就能查看和导出，因此不记录薪水数值，只记录薪水是否被修改
+type InstructorAuditSnapshot struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Dept          string `json:"dept"`
+	SalaryChanged bool   `json:"salary_changed,omitempty"`
+}
+
+// AuditSnapshot 将Instructor转换为不含薪水的审计快照
+func (i *Instructor) AuditSnapshot() *InstructorAuditSnapshot {
+	return &InstructorAuditSnapshot{
+		ID:   i.ID,
+		Name: i.Name,
+		Dept: i.Dept,
+	}
+}
+
 // InstructorProfileUpdateRequest 表示教师个人信息更新请求
 type InstructorProfileUpdateRequest struct {
 	Name     string `json:"name"`
@@ -38,4 +57,12 @@ type InstructorProfileUpdateRequest struct {
 type InstructorLoginRequest struct {
 	ID       string `json:"id"`
 	Password string `json:"password"`
-}
\ No newline at end of file
+}
+
+// InstructorListFields 教师列表支持的排序和过滤字段
+var InstructorListFields = ListFields{
+	Sort:    []string{"name", "dept", "salary"},
+	Filter:  []string{"dept"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "id"}},
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// DefaultPageSize 列表查询未指定每页条数时的默认值
+	DefaultPageSize = 50
+	// MaxPageSize 列表查询每页条数的上限
+	MaxPageSize = 500
+)
+
+// ErrInvalidListQuery 列表查询条件不合法，例如排序或过滤的字段不被支持
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// SortField 排序字段，Desc为true时倒序
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListFields 描述一类资源的列表查询支持哪些字段
+// Key是能唯一确定一条记录的字段，总是追加在排序条件最后，保证翻页时顺序稳定；Default为未指定排序时使用的排序；
+// Numeric中的过滤字段取值必须是整数
+type ListFields struct {
+	Sort    []string
+	Filter  []string
+	Numeric []string
+	Key     []string
+	Default []SortField
+}
+
+// ListQuery 列表查询的通用条件：分页、排序和过滤
+// 分页可以使用页码Page，也可以使用上一页结果中的NextCursor，两者都有时以Cursor为准；Filters按字段精确匹配
+type ListQuery struct {
+	Page     int
+	PageSize int
+	Cursor   string
+	Sort     []SortField
+	Filters  map[string]string
+
+	offset int
+}
+
+// ListResult 列表查询的一页结果，Total为满足过滤条件的总条数，没有下一页时NextCursor为空
+type ListResult[T any] struct {
+	Items      []*T   `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Normalize 按fields检查查询条件并补全默认值：每页条数、默认排序和排序的主键字段，游标解析为偏移量
+// 仓储在执行查询前调用，对已经处理过的查询再次调用结果不变
+func (q *ListQuery) Normalize(fields ListFields) error {
+	switch {
+	case q.PageSize == 0:
+		q.PageSize = DefaultPageSize
+	case q.PageSize < 0 || q.PageSize > MaxPageSize:
+		return fmt.Errorf("%w: page_size must be between 1 and %d", ErrInvalidListQuery, MaxPageSize)
+	}
+
+	if q.Cursor != "" {
+		offset, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return err
+		}
+		q.offset = offset
+		q.Page = offset/q.PageSize + 1
+	} else {
+		if q.Page < 0 {
+			return fmt.Errorf("%w: page must be positive", ErrInvalidListQuery)
+		}
+		if q.Page == 0 {
+			q.Page = 1
+		}
+		q.offset = (q.Page - 1) * q.PageSize
+	}
+
+	if len(q.Sort) == 0 {
+		q.Sort = append(q.Sort, fields.Default...)
+	}
+	sorted := make(map[string]bool, len(q.Sort))
+	for _, sort := range q.Sort {
+		if !containsField(fields.Sort, sort.Field) && !containsField(fields.Key, sort.Field) {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidListQuery, sort.Field)
+		}
+		if sorted[sort.Field] {
+			return fmt.Errorf("%w: duplicate sort field %q", ErrInvalidListQuery, sort.Field)
+		}
+		sorted[sort.Field] = true
+	}
+	for _, key := range fields.Key {
+		if !sorted[key] {
+			q.Sort = append(q.Sort, SortField{Field: key})
+		}
+	}
+
+	for field, value := range q.Filters {
+		if !containsField(fields.Filter, field) {
+			return fmt.Errorf("%w: cannot filter by %q", ErrInvalidListQuery, field)
+		}
+		if containsField(fields.Numeric, field) {
+			if _, err := strconv.Atoi(value); err != nil {
+				return fmt.Errorf("%w: %s must be an integer", ErrInvalidListQuery, field)
+			}
+		}
+	}
+	return nil
+}
+
+// Offset 返回本页第一条记录的偏移量，需要先调用Normalize
+func (q *ListQuery) Offset() int {
+	return q.offset
+}
+
+// NewListResult 用查询条件、本页记录和总条数组装一页结果，后面还有记录时生成下一页的游标
+func NewListResult[T any](q *ListQuery, items []*T, total int64) *ListResult[T] {
+	if items == nil {
+		items = []*T{}
+	}
+	result := &ListResult[T]{
+		Items:    items,
+		Total:    total,
+		Page:     q.Page,
+		PageSize: q.PageSize,
+	}
+	if next := q.offset + len(items); len(items) > 0 && int64(next) < total {
+		result.NextCursor = encodeCursor(next)
+	}
+	return result
+}
+
+// encodeCursor 游标对客户端是不透明的字符串，目前保存下一页的偏移量
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidListQuery)
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidListQuery)
+	}
+	return offset, nil
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// 登录限流的范围
+const (
+	ThrottleScopeAccount = "account" // 按账号（角色+用户ID）统计
+	ThrottleScopeIP      = "ip"      // 按客户端IP统计
+)
+
+// 登录尝试的结果原因
+const (
+	LoginReasonSuccess            = "success"
+	LoginReasonInvalidCredentials = "invalid_credentials"
+	LoginReasonThrottled          = "throttled"
+	LoginReasonLocked             = "locked"
+)
+
+// LoginAttempt 表示一次登录尝试，用于安全审查
+type LoginAttempt struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginThrottle 表示某个账号或IP的连续失败计数
+type LoginThrottle struct {
+	Scope         string     `json:"scope"`
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedAt      *time.Time `json:"locked_at,omitempty"`
+}
+
+// AccountThrottleKey 返回账号维度的限流键，不同角色的同名ID互不影响
+func AccountThrottleKey(userID string, role string) string {
+	return role + "/" + userID
+}
+
+// LockStatus 表示账号的锁定状态
+type LockStatus struct {
+	UserID        string     `json:"user_id"`
+	Role          string     `json:"role"`
+	Locked        bool       `json:"locked"`
+	LockedAt      *time.Time `json:"locked_at,omitempty"`
+	Failures      int        `json:"failures"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+	RetryAfter    int64      `json:"retry_after"` // 距离允许下次尝试的秒数
+}
+
+// UnlockRequest 表示管理员解锁账号的请求
+type UnlockRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// LoginAttemptListFields 登录尝试列表支持的排序和过滤字段，默认最新的在前
+var LoginAttemptListFields = ListFields{
+	Sort:    []string{"created_at", "user_id", "ip"},
+	Filter:  []string{"user_id", "role", "ip", "reason"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "id", Desc: true}},
+}
+
+// LockedAccountListFields 被锁定账号列表支持的排序字段，默认最近锁定的在前
+var LockedAccountListFields = ListFields{
+	Sort:    []string{"locked_at", "last_failure_at", "failures"},
+	Key:     []string{"key"},
+	Default: []SortField{{Field: "locked_at", Desc: true}},
+}
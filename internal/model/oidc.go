@@ -0,0 +1,78 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// OIDCLoginState 表示跳转到身份提供方之前保存的登录状态，回调时凭state找回并只能使用一次
+// state参数的格式为 "<状态ID>.<随机串>"，只保存随机串的哈希；nonce和code_verifier需要参与校验，明文保存
+type OIDCLoginState struct {
+	ID           string
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+}
+
+// IsUsable 判断登录状态在给定时间是否仍可使用
+func (s *OIDCLoginState) IsUsable(now time.Time) bool {
+	return s.UsedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// OIDCIdentity 表示身份提供方账号与学生或教师账号的绑定
+// 管理员可以只填写邮箱预先绑定，该用户第一次单点登录时再记录subject
+type OIDCIdentity struct {
+	Role      string    `json:"role"`
+	UserID    string    `json:"user_id"`
+	Subject   string    `json:"subject,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCIdentityRequest 表示管理员绑定身份提供方账号的请求
+type OIDCIdentityRequest struct {
+	Role    string `json:"role"`
+	UserID  string `json:"user_id"`
+	Subject string `json:"subject"`
+	Email   string `json:"email"`
+}
+
+// Validate 验证绑定请求
+func (r *OIDCIdentityRequest) Validate() error {
+	if r.Role != RoleStudent && r.Role != RoleInstructor {
+		return errors.New("role must be student or instructor")
+	}
+	if r.UserID == "" {
+		return errors.New("user ID is required")
+	}
+	if r.Subject == "" && r.Email == "" {
+		return errors.New("subject or email is required")
+	}
+	return nil
+}
+
+// OIDCLoginStart 表示发起单点登录的结果
+// State需要写入发起登录的浏览器的Cookie，回调时必须与Cookie一致，防止把别人发起的登录回调转给受害者（登录CSRF）
+type OIDCLoginStart struct {
+	AuthURL   string    // 身份提供方登录页地址
+	State     string    // 与浏览器绑定的state
+	ExpiresAt time.Time // state过期时间
+	Secure    bool      // 回调地址是https时Cookie只通过https发送
+}
+
+// OIDCLoginResult 表示单点登录映射得到的本地账号
+type OIDCLoginResult struct {
+	UserID string
+	Role   string
+}
+
+// OIDCIdentityListFields 单点登录身份绑定列表支持的排序和过滤字段
+var OIDCIdentityListFields = ListFields{
+	Sort:    []string{"email", "created_at"},
+	Filter:  []string{"role", "user_id", "email"},
+	Key:     []string{"role", "user_id"},
+	Default: []SortField{{Field: "role"}},
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// OverloadStatus 表示超载申请状态
+type OverloadStatus string
+
+const (
+	OverloadStatusPending  OverloadStatus = "pending"  // 等待导师审批
+	OverloadStatusApproved OverloadStatus = "approved" // 已批准
+	OverloadStatusDenied   OverloadStatus = "denied"   // 已拒绝
+)
+
+// OverloadRequest 表示学生申请在某学期超过最高学分限制
+type OverloadRequest struct {
+	ID               string         `json:"id"`                   // 申请ID
+	StudentID        string         `json:"student_id"`           // 学生ID
+	Semester         string         `json:"semester"`             // 学期
+	Year             int            `json:"year"`                 // 年份
+	RequestedCredits float64        `json:"requested_credits"`    // 申请的最高学分
+	Reason           string         `json:"reason"`               // 申请理由
+	Status           OverloadStatus `json:"status"`               // 申请状态
+	AdvisorID        string         `json:"advisor_id,omitempty"` // 审批导师ID
+	DecisionNote     string         `json:"decision_note"`        // 审批意见
+	CreatedAt        time.Time      `json:"created_at"`           // 申请时间
+	DecidedAt        *time.Time     `json:"decided_at,omitempty"` // 审批时间
+}
+
+// OverloadCreateRequest 表示学生提交超载申请的请求
+type OverloadCreateRequest struct {
+	Semester         string  `json:"semester"`
+	Year             int     `json:"year"`
+	RequestedCredits float64 `json:"requested_credits"`
+	Reason           string  `json:"reason"`
+}
+
+// OverloadDecisionRequest 表示导师审批超载申请的请求
+type OverloadDecisionRequest struct {
+	ID      string `json:"id"`
+	Approve bool   `json:"approve"`
+	Note    string `json:"note"`
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OverrideStatus 表示选课特许的状态
+type OverrideStatus string
+
+const (
+	OverrideStatusActive  OverrideStatus = "active"  // 尚未使用
+	OverrideStatusUsed    OverrideStatus = "used"    // 已在选课时使用
+	OverrideStatusRevoked OverrideStatus = "revoked" // 已被教师撤销
+)
+
+// waivableReasons 可以由教师特许豁免的检查，学分上限走超载申请流程，不在此列
+var waivableReasons = map[EligibilityReasonCode]bool{
+	ReasonPrereqsNotSatisfied: true,
+	ReasonSectionFull:         true,
+	ReasonTimeConflict:        true,
+}
+
+// RegistrationOverride 表示教师为某学生在某课程段签发的一次性选课特许
+// Code 为空时学生选课时自动生效；不为空时学生需要在选课时提供该许可码
+type RegistrationOverride struct {
+	ID        string                  `json:"id"`                // 特许ID
+	StudentID string                  `json:"student_id"`        // 学生ID
+	CourseID  string                  `json:"course_id"`         // 课程ID
+	SectionID string                  `json:"section_id"`        // 章节ID
+	Semester  string                  `json:"semester"`          // 学期
+	Year      int                     `json:"year"`              // 年份
+	Waives    []EligibilityReasonCode `json:"waives"`            // 豁免的检查
+	Code      string                  `json:"code,omitempty"`    // 许可码
+	GrantedBy string                  `json:"granted_by"`        // 签发教师ID
+	Reason    string                  `json:"reason"`            // 签发理由
+	Status    OverrideStatus          `json:"status"`            // 状态
+	CreatedAt time.Time               `json:"created_at"`        // 签发时间
+	UsedAt    *time.Time              `json:"used_at,omitempty"` // 使用时间
+}
+
+// Waived 判断该特许是否豁免指定检查
+func (o *RegistrationOverride) Waived(code EligibilityReasonCode) bool {
+	for _, waived := range o.Waives {
+		if waived == code {
+			return true
+		}
+	}
+	return false
+}
+
+// OverrideGrantRequest 表示教师签发选课特许的请求
+type OverrideGrantRequest struct {
+	StudentID string `json:"student_id"`
+	SectionRef
+	Waives    []EligibilityReasonCode `json:"waives"`
+	Reason    string                  `json:"reason"`
+	IssueCode bool                    `json:"issue_code"` // 是否签发许可码，否则为直接生效的特许
+}
+
+// Validate 检查签发请求
+func (r *OverrideGrantRequest) Validate() error {
+	if r.StudentID == "" || r.SectionRef.IsEmpty() {
+		return errors.New("student_id and section are required")
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	if len(r.Waives) == 0 {
+		return errors.New("at least one waived check is required")
+	}
+	for _, code := range r.Waives {
+		if !waivableReasons[code] {
+			return fmt.Errorf("check %s cannot be waived", code)
+		}
+	}
+	return nil
+}
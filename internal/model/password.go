@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Credentials 表示某个角色账号的密码信息
+type Credentials struct {
+	UserID             string
+	Role               string
+	PasswordHash       string
+	MustChangePassword bool
+}
+
+// PasswordResetToken 表示一次找回密码请求，令牌只保存哈希且只能使用一次
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Role      string     `json:"role"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// IsUsable 判断重置令牌在给定时间是否仍可使用
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// PasswordResetRequest 表示申请找回密码的请求
+type PasswordResetRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// PasswordResetConfirmRequest 表示使用重置令牌设置新密码的请求
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
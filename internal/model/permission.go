@@ -0,0 +1,218 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Permission 表示一项具名权限，路由注册时声明所需的权限
+type Permission string
+
+const (
+	PermStudentPortal    Permission = "student.portal"    // 学生自助：个人信息、选课、成绩单
+	PermInstructorPortal Permission = "instructor.portal" // 教师自助：个人信息、任课课程段
+	PermCoursesRead      Permission = "courses.read"      // 浏览课程和课程段
+	PermGradesWrite      Permission = "grades.write"      // 登记成绩
+	PermOverloadsDecide  Permission = "overloads.decide"  // 审批超学分申请
+	PermOverridesManage  Permission = "overrides.manage"  // 授予或撤销先修课豁免
+	PermAdviseesRead     Permission = "advisees.read"     // 查看指导学生
+
+	PermStudentsManage    Permission = "students.manage"
+	PermInstructorsManage Permission = "instructors.manage"
+	PermSalaryRead        Permission = "salary.read"
+	PermDepartmentsManage Permission = "departments.manage"
+	PermCoursesManage     Permission = "courses.manage"
+	PermPrereqsManage     Permission = "prereqs.manage"
+	PermClassroomsManage  Permission = "classrooms.manage"
+	PermSectionsManage    Permission = "sections.manage"
+	PermTeachesManage     Permission = "teaches.manage"
+	PermAdvisorsManage    Permission = "advisors.manage"
+	PermTermsManage       Permission = "terms.manage"
+	PermTicketsManage     Permission = "tickets.manage"
+	PermStatsRead         Permission = "stats.read"
+	PermAdminsManage      Permission = "admins.manage"
+	PermSessionsRevoke    Permission = "sessions.revoke"
+	PermLockoutsManage    Permission = "lockouts.manage"
+	PermRolesManage       Permission = "roles.manage"
+	PermTwoFactorManage   Permission = "twofactor.manage"
+	PermAuditRead         Permission = "audit.read"
+	PermAPIKeysManage     Permission = "apikeys.manage"
+	PermSSOManage         Permission = "sso.manage"
+)
+
+// AllPermissions 所有已知权限
+var AllPermissions = []Permission{
+	PermStudentPortal, PermInstructorPortal, PermCoursesRead, PermGradesWrite,
+	PermOverloadsDecide, PermOverridesManage, PermAdviseesRead,
+	PermStudentsManage, PermInstructorsManage, PermSalaryRead, PermDepartmentsManage,
+	PermCoursesManage, PermPrereqsManage, PermClassroomsManage, PermSectionsManage,
+	PermTeachesManage, PermAdvisorsManage, PermTermsManage, PermTicketsManage,
+	PermStatsRead, PermAdminsManage, PermSessionsRevoke, PermLockoutsManage, PermRolesManage,
+	PermTwoFactorManage, PermAuditRead, PermAPIKeysManage, PermSSOManage,
+}
+
+// IsKnownPermission 判断权限名称是否有效
+func IsKnownPermission(p Permission) bool {
+	for _, known := range AllPermissions {
+		if known == p {
+			return true
+		}
+	}
+	return false
+}
+
+// DeptScopedPermissions 可以按系部授予的权限，处理器会检查数据所属的系部
+var DeptScopedPermissions = []Permission{
+	PermCoursesManage, PermSectionsManage, PermPrereqsManage, PermTeachesManage,
+	PermGradesWrite, PermSalaryRead,
+}
+
+// IsDeptScopedPermission 判断权限是否可以按系部授予
+func IsDeptScopedPermission(p Permission) bool {
+	for _, scoped := range DeptScopedPermissions {
+		if scoped == p {
+			return true
+		}
+	}
+	return false
+}
+
+// BasePermissions 登录角色自带的权限
+// 学生和教师的自带权限只作用于本人的数据，由服务层检查归属；管理员拥有全部权限
+var BasePermissions = map[string][]Permission{
+	RoleStudent: {PermStudentPortal, PermCoursesRead},
+	RoleInstructor: {
+		PermInstructorPortal, PermCoursesRead, PermGradesWrite,
+		PermOverloadsDecide, PermOverridesManage, PermAdviseesRead,
+	},
+	RoleAdmin: AllPermissions,
+}
+
+// PermissionScope 表示一项权限的适用范围
+// Global为true时不限系部；否则只能操作Depts中系部的数据，两者都为空表示只能操作本人的数据
+type PermissionScope struct {
+	Global bool     `json:"global"`
+	Depts  []string `json:"depts,omitempty"`
+}
+
+// Allows 判断权限是否覆盖指定系部
+func (s *PermissionScope) Allows(dept string) bool {
+	if s == nil {
+		return false
+	}
+	if s.Global {
+		return true
+	}
+	for _, d := range s.Depts {
+		if d == dept {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionSet 表示用户拥有的全部权限及其适用范围
+type PermissionSet map[Permission]*PermissionScope
+
+// Has 判断是否拥有某项权限（任意范围）
+func (s PermissionSet) Has(p Permission) bool {
+	_, ok := s[p]
+	return ok
+}
+
+// Scope 返回权限的适用范围，没有该权限时返回nil
+func (s PermissionSet) Scope(p Permission) *PermissionScope {
+	return s[p]
+}
+
+// Grant 将权限加入集合，dept为空表示全局授予，ownOnly表示只作用于本人数据
+func (s PermissionSet) Grant(p Permission, dept string, ownOnly bool) {
+	scope, ok := s[p]
+	if !ok {
+		scope = &PermissionScope{}
+		s[p] = scope
+	}
+	switch {
+	case ownOnly:
+	case dept == "":
+		scope.Global = true
+		scope.Depts = nil
+	case !scope.Global && !scope.Allows(dept):
+		scope.Depts = append(scope.Depts, dept)
+	}
+}
+
+// Role 表示一组权限的集合，例如教务员、系主任、助教
+type Role struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+	// DeptScoped 为true时分配角色必须指定系部，权限只作用于该系部
+	DeptScoped bool `json:"dept_scoped"`
+}
+
+// Validate 检查角色定义
+func (r *Role) Validate() error {
+	if r.Name == "" {
+		return errors.New("role name is required")
+	}
+	if r.Name == RoleStudent || r.Name == RoleInstructor || r.Name == RoleAdmin {
+		return errors.New("role name is reserved")
+	}
+	if len(r.Permissions) == 0 {
+		return errors.New("role must grant at least one permission")
+	}
+	for _, p := range r.Permissions {
+		if !IsKnownPermission(p) {
+			return errors.New("unknown permission: " + string(p))
+		}
+		if r.DeptScoped && !IsDeptScopedPermission(p) {
+			return errors.New("permission cannot be scoped to a department: " + string(p))
+		}
+	}
+	return nil
+}
+
+// RoleAssignment 表示把角色分配给某个账号
+type RoleAssignment struct {
+	ID       int64  `json:"id"`
+	UserID   string `json:"user_id"`
+	UserRole string `json:"user_role"` // 账号的登录角色：student、instructor、admin
+	RoleName string `json:"role_name"`
+	// Dept 角色适用的系部，为空表示不限系部
+	Dept      string    `json:"dept_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RoleAssignmentRequest 表示分配角色的请求
+type RoleAssignmentRequest struct {
+	UserID   string `json:"user_id"`
+	UserRole string `json:"user_role"`
+	RoleName string `json:"role_name"`
+	Dept     string `json:"dept_name"`
+}
+
+// Validate 检查分配角色的请求
+func (r *RoleAssignmentRequest) Validate() error {
+	if r.UserID == "" || r.RoleName == "" {
+		return errors.New("user_id and role_name are required")
+	}
+	if r.UserRole != RoleStudent && r.UserRole != RoleInstructor && r.UserRole != RoleAdmin {
+		return errors.New("invalid user_role")
+	}
+	return nil
+}
+
+// PermissionGrant 表示通过角色分配获得的一项权限
+type PermissionGrant struct {
+	Permission Permission
+	Dept       string // 为空表示不限系部
+}
+
+// RoleAssignmentListFields 角色分配列表支持的排序和过滤字段
+var RoleAssignmentListFields = ListFields{
+	Sort:    []string{"user_id", "role_name", "created_at"},
+	Filter:  []string{"user_id", "user_role", "role_name", "dept_name"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "id"}},
+}
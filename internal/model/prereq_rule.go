@@ -0,0 +1,229 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PrereqRuleType 表示先修规则节点类型
+type PrereqRuleType string
+
+const (
+	PrereqRuleAll        PrereqRuleType = "all"         // 所有子规则都满足
+	PrereqRuleAny        PrereqRuleType = "any"         // 任一子规则满足
+	PrereqRuleCourse     PrereqRuleType = "course"      // 已修完某门课程，可指定最低成绩
+	PrereqRuleCoreq      PrereqRuleType = "coreq"       // 已修完或同学期同时选修某门课程
+	PrereqRuleMinCredits PrereqRuleType = "min_credits" // 总学分不低于指定值
+	PrereqRuleStanding   PrereqRuleType = "standing"    // 年级不低于指定年级
+)
+
+// 年级及对应的最低总学分
+const (
+	StandingFreshman  = "freshman"
+	StandingSophomore = "sophomore"
+	StandingJunior    = "junior"
+	StandingSenior    = "senior"
+)
+
+var standingMinCredits = map[string]float64{
+	StandingFreshman:  0,
+	StandingSophomore: 30,
+	StandingJunior:    60,
+	StandingSenior:    90,
+}
+
+// PrereqRule 表示课程先修要求的规则树
+type PrereqRule struct {
+	Type       PrereqRuleType `json:"type"`
+	Rules      []*PrereqRule  `json:"rules,omitempty"`       // all / any 的子规则
+	CourseID   string         `json:"course_id,omitempty"`   // course / coreq 的课程ID
+	MinGrade   string         `json:"min_grade,omitempty"`   // course 的最低成绩，为空表示及格即可
+	MinCredits float64        `json:"min_credits,omitempty"` // min_credits 的最低总学分
+	Standing   string         `json:"standing,omitempty"`    // standing 的最低年级
+}
+
+// PrereqRuleRequest 表示保存课程先修规则的请求
+type PrereqRuleRequest struct {
+	CourseID string      `json:"course_id"`
+	Rule     *PrereqRule `json:"rule"`
+}
+
+// AcademicRecord 表示评估先修规则所需的学生学业记录
+type AcademicRecord struct {
+	TotCred   float64
+	Completed map[string]string // 已完成课程ID -> 最好成绩（不含未出成绩、不及格和退选）
+	Enrolled  map[string]bool   // 目标学期正在修读（未出成绩）的课程ID
+}
+
+// NewAcademicRecord 创建总学分为totCred、没有选课记录的学业记录
+func NewAcademicRecord(totCred float64) *AcademicRecord {
+	return &AcademicRecord{
+		TotCred:   totCred,
+		Completed: make(map[string]string),
+		Enrolled:  make(map[string]bool),
+	}
+}
+
+// AddTakes 把一条选课记录计入学业记录，semester和year为评估的目标学期
+func (r *AcademicRecord) AddTakes(courseID string, takesSemester string, takesYear int, grade string, semester string, year int) {
+	switch {
+	case grade == "":
+		// 未出成绩：只有目标学期的课程算作同时选修
+		if takesSemester == semester && takesYear == year {
+			r.Enrolled[courseID] = true
+		}
+	case IsValidGrade(grade) && IsPassingGrade(grade):
+		// 重修时保留最好成绩
+		if best, ok := r.Completed[courseID]; !ok || GradeAtLeast(grade, best) {
+			r.Completed[courseID] = grade
+		}
+	}
+}
+
+// PrereqCheckResult 表示先修规则的评估结果
+type PrereqCheckResult struct {
+	Satisfied    bool     `json:"satisfied"`
+	FailedClause string   `json:"failed_clause,omitempty"` // 未满足的规则描述
+	CourseIDs    []string `json:"course_ids,omitempty"`    // 未满足的规则中涉及的课程
+}
+
+// Validate 检查规则树结构是否完整
+func (r *PrereqRule) Validate() error {
+	if r == nil {
+		return errors.New("rule is required")
+	}
+
+	switch r.Type {
+	case PrereqRuleAll, PrereqRuleAny:
+		if len(r.Rules) == 0 {
+			return fmt.Errorf("%s rule requires at least one sub-rule", r.Type)
+		}
+		for _, sub := range r.Rules {
+			if err := sub.Validate(); err != nil {
+				return err
+			}
+		}
+	case PrereqRuleCourse, PrereqRuleCoreq:
+		if r.CourseID == "" {
+			return fmt.Errorf("%s rule requires course_id", r.Type)
+		}
+		if r.MinGrade != "" && (!IsValidGrade(r.MinGrade) || !IsPassingGrade(r.MinGrade)) {
+			return fmt.Errorf("invalid min_grade: %s", r.MinGrade)
+		}
+	case PrereqRuleMinCredits:
+		if r.MinCredits <= 0 {
+			return errors.New("min_credits rule requires a positive min_credits")
+		}
+	case PrereqRuleStanding:
+		if _, ok := standingMinCredits[r.Standing]; !ok {
+			return fmt.Errorf("invalid standing: %s", r.Standing)
+		}
+	default:
+		return fmt.Errorf("unknown rule type: %s", r.Type)
+	}
+
+	return nil
+}
+
+// CourseIDs 返回规则树中引用的所有课程ID
+func (r *PrereqRule) CourseIDs() []string {
+	var ids []string
+	if r.CourseID != "" {
+		ids = append(ids, r.CourseID)
+	}
+	for _, sub := range r.Rules {
+		ids = append(ids, sub.CourseIDs()...)
+	}
+	return ids
+}
+
+// Evaluate 根据学业记录评估规则树，返回是否满足以及第一个未满足的规则
+func (r *PrereqRule) Evaluate(record *AcademicRecord) (bool, *PrereqRule) {
+	switch r.Type {
+	case PrereqRuleAll:
+		for _, sub := range r.Rules {
+			if ok, failed := sub.Evaluate(record); !ok {
+				return false, failed
+			}
+		}
+		return true, nil
+	case PrereqRuleAny:
+		for _, sub := range r.Rules {
+			if ok, _ := sub.Evaluate(record); ok {
+				return true, nil
+			}
+		}
+		return false, r
+	case PrereqRuleCourse:
+		grade, ok := record.Completed[r.CourseID]
+		if ok && (r.MinGrade == "" || GradeAtLeast(grade, r.MinGrade)) {
+			return true, nil
+		}
+		return false, r
+	case PrereqRuleCoreq:
+		if _, ok := record.Completed[r.CourseID]; ok || record.Enrolled[r.CourseID] {
+			return true, nil
+		}
+		return false, r
+	case PrereqRuleMinCredits:
+		if record.TotCred >= r.MinCredits {
+			return true, nil
+		}
+		return false, r
+	case PrereqRuleStanding:
+		if record.TotCred >= standingMinCredits[r.Standing] {
+			return true, nil
+		}
+		return false, r
+	}
+	return false, r
+}
+
+// Check 评估规则树并生成结果
+func (r *PrereqRule) Check(record *AcademicRecord) *PrereqCheckResult {
+	ok, failed := r.Evaluate(record)
+	if ok {
+		return &PrereqCheckResult{Satisfied: true}
+	}
+	return &PrereqCheckResult{FailedClause: failed.String(), CourseIDs: failed.CourseIDs()}
+}
+
+// String 返回规则的可读描述
+func (r *PrereqRule) String() string {
+	switch r.Type {
+	case PrereqRuleAll, PrereqRuleAny:
+		parts := make([]string, 0, len(r.Rules))
+		for _, sub := range r.Rules {
+			parts = append(parts, sub.String())
+		}
+		if r.Type == PrereqRuleAll {
+			return "all of (" + strings.Join(parts, ", ") + ")"
+		}
+		return "one of (" + strings.Join(parts, ", ") + ")"
+	case PrereqRuleCourse:
+		if r.MinGrade != "" {
+			return fmt.Sprintf("%s with grade %s or better", r.CourseID, r.MinGrade)
+		}
+		return r.CourseID
+	case PrereqRuleCoreq:
+		return fmt.Sprintf("%s taken previously or concurrently", r.CourseID)
+	case PrereqRuleMinCredits:
+		return fmt.Sprintf("at least %g credits", r.MinCredits)
+	case PrereqRuleStanding:
+		return fmt.Sprintf("%s standing", r.Standing)
+	}
+	return string(r.Type)
+}
+
+// LegacyPrereqRule 把旧的prereq表（所有先修课程都需及格）转换为规则树，没有先修课程时返回nil
+func LegacyPrereqRule(prereqIDs []string) *PrereqRule {
+	if len(prereqIDs) == 0 {
+		return nil
+	}
+	rule := &PrereqRule{Type: PrereqRuleAll}
+	for _, id := range prereqIDs {
+		rule.Rules = append(rule.Rules, &PrereqRule{Type: PrereqRuleCourse, CourseID: id})
+	}
+	return rule
+}
@@ -0,0 +1,95 @@
+package model
+
+import "testing"
+
+func TestPrereqRule_Check(t *testing.T) {
+	// CS-190 的先修规则：(CS-101 成绩B以上 或 CS-102) 且 同时选修或已修 MA-101 且 至少大二
+	rule := &PrereqRule{
+		Type: PrereqRuleAll,
+		Rules: []*PrereqRule{
+			{Type: PrereqRuleAny, Rules: []*PrereqRule{
+				{Type: PrereqRuleCourse, CourseID: "CS-101", MinGrade: "B"},
+				{Type: PrereqRuleCourse, CourseID: "CS-102"},
+			}},
+			{Type: PrereqRuleCoreq, CourseID: "MA-101"},
+			{Type: PrereqRuleStanding, Standing: StandingSophomore},
+		},
+	}
+	if err := rule.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		record *AcademicRecord
+		failed string
+	}{
+		{
+			name: "satisfied with minimum grade and concurrent coreq",
+			record: &AcademicRecord{
+				TotCred:   45,
+				Completed: map[string]string{"CS-101": "A-"},
+				Enrolled:  map[string]bool{"MA-101": true},
+			},
+		},
+		{
+			name: "satisfied by alternative course",
+			record: &AcademicRecord{
+				TotCred:   30,
+				Completed: map[string]string{"CS-101": "C", "CS-102": "C", "MA-101": "B"},
+			},
+		},
+		{
+			name: "grade below minimum",
+			record: &AcademicRecord{
+				TotCred:   45,
+				Completed: map[string]string{"CS-101": "C+"},
+				Enrolled:  map[string]bool{"MA-101": true},
+			},
+			failed: "one of (CS-101 with grade B or better, CS-102)",
+		},
+		{
+			name: "missing coreq",
+			record: &AcademicRecord{
+				TotCred:   45,
+				Completed: map[string]string{"CS-102": "B"},
+			},
+			failed: "MA-101 taken previously or concurrently",
+		},
+		{
+			name: "insufficient standing",
+			record: &AcademicRecord{
+				TotCred:   12,
+				Completed: map[string]string{"CS-102": "B", "MA-101": "A"},
+			},
+			failed: "sophomore standing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := rule.Check(tt.record)
+			if result.Satisfied != (tt.failed == "") {
+				t.Fatalf("expected satisfied=%v, got %+v", tt.failed == "", result)
+			}
+			if result.FailedClause != tt.failed {
+				t.Errorf("expected failed clause %q, got %q", tt.failed, result.FailedClause)
+			}
+		})
+	}
+}
+
+func TestPrereqRule_Validate(t *testing.T) {
+	invalid := []*PrereqRule{
+		{Type: PrereqRuleAll},
+		{Type: PrereqRuleCourse},
+		{Type: PrereqRuleCourse, CourseID: "CS-101", MinGrade: "Z"},
+		{Type: PrereqRuleStanding, Standing: "graduate"},
+		{Type: "unknown"},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("expected validation error for %+v", rule)
+		}
+	}
+}
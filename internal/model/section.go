@@ -65,3 +65,12 @@ type SectionQueryParams struct {
 	Building     string `json:"building"`
 	RoomNumber   string `json:"room_number"`
 }
+
+// SectionListFields 课程段列表支持的排序和过滤字段，instructor_id过滤该教师授课的课程段，dept过滤课程所属院系
+var SectionListFields = ListFields{
+	Sort:    []string{"building"},
+	Filter:  []string{"course_id", "semester", "year", "building", "dept", "instructor_id"},
+	Numeric: []string{"year"},
+	Key:     []string{"course_id", "id", "semester", "year"},
+	Default: []SortField{{Field: "year", Desc: true}, {Field: "semester"}, {Field: "course_id"}},
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sectionKeySeparator 课程段主键字符串形式的分隔符
+const sectionKeySeparator = "/"
+
+// SectionKey 表示课程段的完整主键 (course_id, sec_id, semester, year)
+// 只有sec_id不能唯一确定课程段，不同课程、不同学期都可能有相同的sec_id
+type SectionKey struct {
+	CourseID string `json:"course_id"` // 课程ID
+	SecID    string `json:"sec_id"`    // 章节ID
+	Semester string `json:"semester"`  // 学期
+	Year     int    `json:"year"`      // 年份
+}
+
+// Key 返回课程段的完整主键
+func (s *Section) Key() SectionKey {
+	return SectionKey{CourseID: s.CourseID, SecID: s.ID, Semester: s.Semester, Year: s.Year}
+}
+
+// Key 返回选课记录对应课程段的完整主键
+func (t *Takes) Key() SectionKey {
+	return SectionKey{CourseID: t.CourseID, SecID: t.SectionID, Semester: t.Semester, Year: t.Year}
+}
+
+// Key 返回候补记录对应课程段的完整主键
+func (w *WaitlistEntry) Key() SectionKey {
+	return SectionKey{CourseID: w.CourseID, SecID: w.SectionID, Semester: w.Semester, Year: w.Year}
+}
+
+// Key 返回购物车项对应课程段的完整主键
+func (c *CartItem) Key() SectionKey {
+	return SectionKey{CourseID: c.CourseID, SecID: c.SectionID, Semester: c.Semester, Year: c.Year}
+}
+
+// Key 返回选课特许对应课程段的完整主键
+func (o *RegistrationOverride) Key() SectionKey {
+	return SectionKey{CourseID: o.CourseID, SecID: o.SectionID, Semester: o.Semester, Year: o.Year}
+}
+
+// Key 返回教学关系对应课程段的完整主键
+func (t *Teaches) Key() SectionKey {
+	return SectionKey{CourseID: t.CourseID, SecID: t.SectionID, Semester: t.Semester, Year: t.Year}
+}
+
+// IsComplete 判断主键的所有字段是否都已填写
+func (k SectionKey) IsComplete() bool {
+	return k.CourseID != "" && k.SecID != "" && k.Semester != "" && k.Year != 0
+}
+
+// Validate 检查主键是否完整
+func (k SectionKey) Validate() error {
+	if !k.IsComplete() {
+		return errors.New("course_id, sec_id, semester and year are required to identify a section")
+	}
+	return nil
+}
+
+// String 返回主键的字符串形式，如 CS-101/1/Fall/2024，可以用ParseSectionKey解析
+func (k SectionKey) String() string {
+	return strings.Join([]string{k.CourseID, k.SecID, k.Semester, strconv.Itoa(k.Year)}, sectionKeySeparator)
+}
+
+// ParseSectionKey 解析String生成的主键字符串
+func ParseSectionKey(s string) (SectionKey, error) {
+	parts := strings.Split(s, sectionKeySeparator)
+	if len(parts) != 4 {
+		return SectionKey{}, fmt.Errorf("invalid section key %q: expected course_id/sec_id/semester/year", s)
+	}
+	year, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return SectionKey{}, fmt.Errorf("invalid section key %q: invalid year", s)
+	}
+	key := SectionKey{CourseID: parts[0], SecID: parts[1], Semester: parts[2], Year: year}
+	if err := key.Validate(); err != nil {
+		return SectionKey{}, fmt.Errorf("invalid section key %q: %w", s, err)
+	}
+	return key, nil
+}
+
+// SectionRef 表示请求中对课程段的引用
+// 新客户端填写完整主键；旧客户端只提供section_id，可以是sec_id或主键字符串，需要由服务端解析
+type SectionRef struct {
+	SectionKey
+	SectionID string `json:"section_id,omitempty"` // 兼容旧客户端
+}
+
+// Key 返回引用中的完整主键，无法直接得到完整主键时第二个返回值为false，此时需要按sec_id查找
+func (r *SectionRef) Key() (SectionKey, bool) {
+	if r.SectionKey.IsComplete() {
+		return r.SectionKey, true
+	}
+	if r.SectionID == "" {
+		return r.SectionKey, false
+	}
+	if key, err := ParseSectionKey(r.SectionID); err == nil {
+		return key, true
+	}
+	// 旧客户端的sec_id，保留请求中已经填写的其他字段用于缩小查找范围
+	key := r.SectionKey
+	key.SecID = r.SectionID
+	return key, false
+}
+
+// IsEmpty 判断引用是否为空
+func (r *SectionRef) IsEmpty() bool {
+	return r.SectionID == "" && r.SectionKey.SecID == ""
+}
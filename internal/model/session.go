@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// 登录角色
+const (
+	RoleStudent    = "student"
+	RoleInstructor = "instructor"
+	RoleAdmin      = "admin"
+)
+
+// Session 表示一次登录会话，刷新令牌只保存哈希
+type Session struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	Role        string `json:"role"`
+	Username    string `json:"username"`
+	RefreshHash string `json:"-"`
+	// PasswordChangeRequired 登录时账号需要先修改密码，会话签发的令牌只能用于修改密码
+	PasswordChangeRequired bool       `json:"password_change_required"`
+	CreatedAt              time.Time  `json:"created_at"`
+	LastUsedAt             time.Time  `json:"last_used_at"`
+	ExpiresAt              time.Time  `json:"expires_at"`
+	RevokedAt              *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive 判断会话在给定时间是否仍然有效
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// TokenPair 表示登录或刷新后返回的令牌
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // 访问令牌剩余有效期（秒）
+	// PasswordChangeRequired 为true时客户端应引导用户修改密码
+	PasswordChangeRequired bool   `json:"must_change_password"`
+	SessionID              string `json:"-"`
+}
+
+// RefreshRequest 表示刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RevokeSessionsRequest 表示吊销某个用户全部会话的请求
+type RevokeSessionsRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
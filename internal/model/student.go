@@ -8,6 +8,7 @@ type Student struct {
 	TotCred  float64 `json:"tot_cred"` // 总学分
 	Password string  `json:"password,omitempty"` // 密码（哈希后）
 	Salt     string  `json:"salt,omitempty"`     // 密码盐值
+	MustChangePassword bool `json:"must_change_password"` // 下次登录必须修改密码
 }
 
 // StudentDTO 表示学生数据传输对象（不包含敏感信息）
@@ -38,4 +39,12 @@ type StudentProfileUpdateRequest struct {
 type StudentLoginRequest struct {
 	ID       string `json:"id"`
 	Password string `json:"password"`
-}
\ No newline at end of file
+}
+
+// StudentListFields 学生列表支持的排序和过滤字段
+var StudentListFields = ListFields{
+	Sort:    []string{"name", "dept", "tot_cred"},
+	Filter:  []string{"dept"},
+	Key:     []string{"id"},
+	Default: []SortField{{Field: "id"}},
+}
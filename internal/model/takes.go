@@ -25,8 +25,8 @@ type TakesCreateRequest struct {
 // GradeUpdateRequest 表示更新成绩的请求
 type GradeUpdateRequest struct {
 	StudentID string `json:"student_id"`
-	SectionID string `json:"section_id"`
-	Grade     string `json:"grade"`
+	SectionRef
+	Grade string `json:"grade"`
 }
 
 // Transcript 表示成绩单
@@ -19,4 +19,12 @@ type Teaches struct {
 type TeachesCreateRequest struct {
 	InstructorID string `json:"instructor_id"`
 	SectionID    string `json:"section_id"`
-}
\ No newline at end of file
+}
+
+// TeachesListFields 授课记录列表支持的排序和过滤字段
+var TeachesListFields = ListFields{
+	Filter:  []string{"instructor_id", "course_id", "semester", "year"},
+	Numeric: []string{"year"},
+	Key:     []string{"instructor_id", "course_id", "section_id", "semester", "year"},
+	Default: []SortField{{Field: "year", Desc: true}, {Field: "semester"}},
+}
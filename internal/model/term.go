@@ -0,0 +1,65 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Term 表示一个学期的校历，控制选课、退课和退选的时间窗口
+type Term struct {
+	Semester           string    `json:"semester"`            // 学期
+	Year               int       `json:"year"`                // 年份
+	RegistrationOpen   time.Time `json:"registration_open"`   // 选课开放时间
+	RegistrationClose  time.Time `json:"registration_close"`  // 选课截止时间
+	AddDropDeadline    time.Time `json:"add_drop_deadline"`   // 退课截止时间，此前退课直接删除选课记录
+	WithdrawalDeadline time.Time `json:"withdrawal_deadline"` // 退选截止时间，此前退课记为W
+	MinCredits         float64   `json:"min_credits"`         // 每学期最低学分，0表示不限制
+	MaxCredits         float64   `json:"max_credits"`         // 每学期最高学分，0表示不限制，超出需要导师批准超载申请
+}
+
+// Validate 检查校历各时间点的先后顺序
+func (t *Term) Validate() error {
+	if t.Semester == "" || t.Year == 0 {
+		return errors.New("semester and year are required")
+	}
+	if !t.RegistrationOpen.Before(t.RegistrationClose) {
+		return errors.New("registration_open must be before registration_close")
+	}
+	if t.AddDropDeadline.Before(t.RegistrationClose) {
+		return errors.New("add_drop_deadline must not be before registration_close")
+	}
+	if t.WithdrawalDeadline.Before(t.AddDropDeadline) {
+		return errors.New("withdrawal_deadline must not be before add_drop_deadline")
+	}
+	if t.MinCredits < 0 || t.MaxCredits < 0 {
+		return errors.New("min_credits and max_credits must not be negative")
+	}
+	if t.MaxCredits > 0 && t.MinCredits > t.MaxCredits {
+		return errors.New("min_credits must not be greater than max_credits")
+	}
+	return nil
+}
+
+// IsRegistrationOpen 判断给定时间是否在选课时间窗口内
+func (t *Term) IsRegistrationOpen(now time.Time) bool {
+	return !now.Before(t.RegistrationOpen) && !now.After(t.RegistrationClose)
+}
+
+// CreditLoad 表示学生某学期的学分负载
+type CreditLoad struct {
+	Semester     string  `json:"semester"`
+	Year         int     `json:"year"`
+	Credits      float64 `json:"credits"`       // 当前已选学分（不含退选）
+	MinCredits   float64 `json:"min_credits"`   // 最低学分，0表示不限制
+	MaxCredits   float64 `json:"max_credits"`   // 生效的最高学分（已批准的超载申请会提高该值），0表示不限制
+	BelowMinimum bool    `json:"below_minimum"` // 是否低于最低学分
+}
+
+// TermListFields 校历列表支持的排序和过滤字段
+var TermListFields = ListFields{
+	Sort:    []string{"registration_open"},
+	Filter:  []string{"semester", "year"},
+	Numeric: []string{"year"},
+	Key:     []string{"year", "semester"},
+	Default: []SortField{{Field: "year", Desc: true}, {Field: "registration_open", Desc: true}},
+}
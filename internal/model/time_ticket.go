@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// TimeTicket 表示学生在某学期的选课开始时间（时间票），早于该时间不能选课
+type TimeTicket struct {
+	StudentID string    `json:"student_id"` // 学生ID
+	Semester  string    `json:"semester"`   // 学期
+	Year      int       `json:"year"`       // 年份
+	Tier      int       `json:"tier"`       // 所在梯队，从1开始，数字越小越早
+	StartTime time.Time `json:"start_time"` // 选课开始时间
+
+	// 关联信息
+	Student *StudentDTO `json:"student,omitempty"` // 学生信息
+}
+
+// TicketRule 表示一个梯队规则：总学分不低于MinCredits的学生在基准时间之后OffsetMinutes分钟开始选课
+type TicketRule struct {
+	MinCredits    float64 `json:"min_credits"`    // 最低总学分
+	OffsetMinutes int     `json:"offset_minutes"` // 相对基准时间的偏移（分钟）
+}
+
+// DefaultTicketRules 默认按总学分分为四个梯队，每个梯队间隔一天
+var DefaultTicketRules = []TicketRule{
+	{MinCredits: 90, OffsetMinutes: 0},
+	{MinCredits: 60, OffsetMinutes: 24 * 60},
+	{MinCredits: 30, OffsetMinutes: 2 * 24 * 60},
+	{MinCredits: 0, OffsetMinutes: 3 * 24 * 60},
+}
+
+// TimeTicketRequest 表示生成时间票的请求
+type TimeTicketRequest struct {
+	Semester  string       `json:"semester"`
+	Year      int          `json:"year"`
+	StartTime *time.Time   `json:"start_time,omitempty"` // 基准时间，为空时使用校历中的选课开放时间
+	Rules     []TicketRule `json:"rules,omitempty"`      // 梯队规则，为空时使用DefaultTicketRules
+}
+
+// TimeTicketListFields 选课时间票列表支持的排序和过滤字段
+var TimeTicketListFields = ListFields{
+	Sort:    []string{"tier", "start_time"},
+	Filter:  []string{"semester", "year", "student_id", "tier"},
+	Numeric: []string{"year", "tier"},
+	Key:     []string{"year", "semester", "student_id"},
+	Default: []SortField{{Field: "start_time"}},
+}
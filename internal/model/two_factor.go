@@ -0,0 +1,87 @@
+package model
+
+import "time"
+
+// TwoFactor 表示账号的TOTP两步验证设置
+// 密钥需要参与计算验证码，只能明文保存；Enabled为false表示已生成密钥但尚未确认绑定
+type TwoFactor struct {
+	UserID       string     `json:"user_id"`
+	Role         string     `json:"role"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"` // 最近一次使用的时间步，不晚于它的验证码视为重放
+	CreatedAt    time.Time  `json:"created_at"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+}
+
+// TwoFactorStatus 表示账号两步验证的状态
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"` // 账号所属角色要求启用两步验证
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorEnrollment 表示绑定验证器应用所需的信息，只在开始绑定时返回一次
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorPolicy 表示某个登录角色是否必须启用两步验证
+type TwoFactorPolicy struct {
+	Role     string `json:"role"`
+	Required bool   `json:"required"`
+}
+
+// TwoFactorChallenge 表示密码验证通过后等待第二步验证的登录，令牌只保存哈希
+type TwoFactorChallenge struct {
+	ID        string
+	TokenHash string
+	UserID    string
+	Role      string
+	Username  string
+	Attempts  int
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// IsUsable 判断登录挑战在给定时间是否仍可使用
+func (c *TwoFactorChallenge) IsUsable(now time.Time, maxAttempts int) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt) && c.Attempts < maxAttempts
+}
+
+// TwoFactorLoginChallenge 表示登录第一步返回给客户端的挑战
+type TwoFactorLoginChallenge struct {
+	Token string `json:"challenge_token"`
+	// SetupRequired 为true表示角色要求两步验证但账号尚未绑定，需要先通过挑战令牌完成绑定
+	SetupRequired bool  `json:"setup_required"`
+	ExpiresIn     int64 `json:"expires_in"`
+}
+
+// TwoFactorLoginResult 表示第二步验证通过后的登录信息
+type TwoFactorLoginResult struct {
+	UserID   string
+	Role     string
+	Username string
+	// RecoveryCodes 登录时完成绑定会生成恢复码，只返回这一次
+	RecoveryCodes []string
+}
+
+// TwoFactorCodeRequest 表示提交验证码或恢复码的请求
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorLoginRequest 表示登录第二步的请求
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	TwoFactorCodeRequest
+}
+
+// TwoFactorResetRequest 表示管理员为丢失设备的账号关闭两步验证
+type TwoFactorResetRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
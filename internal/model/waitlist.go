@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// WaitlistEntry 表示课程段候补队列中的一条记录
+type WaitlistEntry struct {
+	ID        string           `json:"id"`         // 记录ID
+	StudentID string           `json:"student_id"` // 学生ID
+	CourseID  string           `json:"course_id"`  // 课程ID
+	SectionID string           `json:"section_id"` // 章节ID
+	Semester  string           `json:"semester"`   // 学期
+	Year      int              `json:"year"`       // 年份
+	QueueNo   int              `json:"-"`          // 入队序号，只用于排序
+	Position  int              `json:"position"`   // 当前排队位置（从1开始，仅等待中的记录有效）
+	Status    EnrollmentStatus `json:"status"`     // waiting / active(已转正) / dropped(主动退出) / rejected(转正时不满足条件)
+	CreatedAt time.Time        `json:"created_at"` // 加入时间
+	UpdatedAt time.Time        `json:"updated_at"` // 最后更新时间
+
+	// 关联信息
+	Student *Student `json:"student,omitempty"` // 学生信息
+	Course  *Course  `json:"course,omitempty"`  // 课程信息
+}
+
+// WaitlistRequest 表示加入或退出候补队列的请求
+type WaitlistRequest struct {
+	SectionRef
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// AdminRepository 定义管理员账号仓库接口
+type AdminRepository interface {
+	FindByID(ctx context.Context, id string) (*model.Admin, error)
+	FindAll(ctx context.Context) ([]*model.Admin, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Admin], error)
+	CountActive(ctx context.Context) (int, error)
+	Create(ctx context.Context, admin *model.Admin) error
+	UpdatePassword(ctx context.Context, id string, hashedPassword string, mustChange bool, updatedAt time.Time) error
+	UpdateStatus(ctx context.Context, id string, status model.AdminStatus, updatedAt time.Time) error
+	UpdateLastLogin(ctx context.Context, id string, loginAt time.Time) error
+}
+
+// SQLAdminRepository 实现AdminRepository接口
+type SQLAdminRepository struct {
+	db DBTX
+}
+
+// NewAdminRepository 创建管理员账号仓库实例
+func NewAdminRepository(db DBTX) AdminRepository {
+	return &SQLAdminRepository{db: db}
+}
+
+// adminColumns 查询管理员账号时使用的公共列
+const adminColumns = `id, name, password, status, must_change_password, created_at, updated_at, last_login_at`
+
+// scanAdmin 扫描一行管理员账号
+func scanAdmin(scanner rowScanner) (*model.Admin, error) {
+	var admin model.Admin
+	var status string
+	var lastLoginAt sql.NullTime
+
+	err := scanner.Scan(
+		&admin.ID,
+		&admin.Name,
+		&admin.Password,
+		&status,
+		&admin.MustChangePassword,
+		&admin.CreatedAt,
+		&admin.UpdatedAt,
+		&lastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	admin.Status = model.AdminStatus(status)
+	if lastLoginAt.Valid {
+		admin.LastLoginAt = &lastLoginAt.Time
+	}
+
+	return &admin, nil
+}
+
+// FindByID 根据登录名查找管理员
+func (r *SQLAdminRepository) FindByID(ctx context.Context, id string) (*model.Admin, error) {
+	query := `SELECT ` + adminColumns + ` FROM admin_account WHERE id = ?`
+
+	admin, err := scanAdmin(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying admin: %w", err)
+	}
+
+	return admin, nil
+}
+
+// FindAll 查找所有管理员
+func (r *SQLAdminRepository) FindAll(ctx context.Context) ([]*model.Admin, error) {
+	query := `SELECT ` + adminColumns + ` FROM admin_account ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying admins: %w", err)
+	}
+	defer rows.Close()
+
+	var admins []*model.Admin
+	for rows.Next() {
+		admin, err := scanAdmin(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning admin: %w", err)
+		}
+		admins = append(admins, admin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating admins: %w", err)
+	}
+
+	return admins, nil
+}
+
+// adminListTable 管理员列表的查询方式
+var adminListTable = listTable{
+	fields:  model.AdminListFields,
+	name:    "admins",
+	selects: adminColumns,
+	from:    `admin_account`,
+	columns: map[string]string{
+		"id":            "id",
+		"name":          "name",
+		"status":        "status",
+		"created_at":    "created_at",
+		"last_login_at": "last_login_at",
+	},
+}
+
+// List 按分页、排序和过滤条件查找管理员
+func (r *SQLAdminRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Admin], error) {
+	return list(ctx, r.db, adminListTable, q, scanAdmin)
+}
+
+// CountActive 统计未停用的管理员数量
+func (r *SQLAdminRepository) CountActive(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM admin_account WHERE status = 'active'`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting admins: %w", err)
+	}
+
+	return count, nil
+}
+
+// Create 创建管理员，密码必须已经哈希
+func (r *SQLAdminRepository) Create(ctx context.Context, admin *model.Admin) error {
+	query := `INSERT INTO admin_account (id, name, password, status, must_change_password, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query, admin.ID, admin.Name, admin.Password, string(admin.Status), admin.MustChangePassword, admin.CreatedAt, admin.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, ErrDuplicate) {
+			return fmt.Errorf("admin already exists: %w", ErrDuplicate)
+		}
+		return fmt.Errorf("error creating admin: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePassword 更新管理员的密码哈希
+func (r *SQLAdminRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string, mustChange bool, updatedAt time.Time) error {
+	query := `UPDATE admin_account SET password = ?, must_change_password = ?, updated_at = ? WHERE id = ?`
+	return r.exec(ctx, query, hashedPassword, mustChange, updatedAt, id)
+}
+
+// UpdateStatus 停用或启用管理员
+func (r *SQLAdminRepository) UpdateStatus(ctx context.Context, id string, status model.AdminStatus, updatedAt time.Time) error {
+	query := `UPDATE admin_account SET status = ?, updated_at = ? WHERE id = ?`
+	return r.exec(ctx, query, string(status), updatedAt, id)
+}
+
+// UpdateLastLogin 记录管理员最近登录时间
+func (r *SQLAdminRepository) UpdateLastLogin(ctx context.Context, id string, loginAt time.Time) error {
+	query := `UPDATE admin_account SET last_login_at = ? WHERE id = ?`
+	return r.exec(ctx, query, loginAt, id)
+}
+
+// exec 执行更新语句，没有匹配的行时返回ErrNotFound
+func (r *SQLAdminRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error updating admin: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -1,47 +1,48 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // AdvisorRepository 定义导师关系仓储接口
 type AdvisorRepository interface {
-	FindByID(studentID, instructorID string) (*model.Advisor, error)
-	FindAll() ([]*model.Advisor, error)
-	FindByStudentID(studentID string) ([]*model.Advisor, error)
-	FindByInstructorID(instructorID string) ([]*model.Advisor, error)
-	Create(advisor *model.Advisor) error
-	Update(studentID string, instructorID string) error
-	Delete(studentID, instructorID string) error
-	FindByStudentAndInstructor(studentID string, instructorID string) (*model.Advisor, error)
+	FindByID(ctx context.Context, studentID, instructorID string) (*model.Advisor, error)
+	FindAll(ctx context.Context) ([]*model.Advisor, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Advisor], error)
+	FindByStudentID(ctx context.Context, studentID string) ([]*model.Advisor, error)
+	FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Advisor, error)
+	Create(ctx context.Context, advisor *model.Advisor) error
+	Update(ctx context.Context, studentID string, instructorID string) error
+	Delete(ctx context.Context, studentID, instructorID string) error
+	FindByStudentAndInstructor(ctx context.Context, studentID string, instructorID string) (*model.Advisor, error)
 }
 
 // SQLAdvisorRepository 实现AdvisorRepository接口
 type SQLAdvisorRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewAdvisorRepository 创建导师关系仓储实例
-func NewAdvisorRepository(db *sql.DB) *SQLAdvisorRepository {
+func NewAdvisorRepository(db DBTX) *SQLAdvisorRepository {
 	return &SQLAdvisorRepository{db: db}
 }
 
 // FindByID 根据学生ID和导师ID查找导师关系
-func (r *SQLAdvisorRepository) FindByID(studentID, instructorID string) (*model.Advisor, error) {
+func (r *SQLAdvisorRepository) FindByID(ctx context.Context, studentID, instructorID string) (*model.Advisor, error) {
 	query := `
-		SELECT a.student_id, a.instructor_id, s.name as student_name, s.dept_name as student_dept,
+		SELECT a.s_id, a.i_id, s.name as student_name, s.dept_name as student_dept,
 		       i.name as instructor_name, i.dept_name as instructor_dept
 		FROM advisor a
-		JOIN student s ON a.student_id = s.id
-		JOIN instructor i ON a.instructor_id = i.id
-		WHERE a.student_id = ? AND a.instructor_id = ?
+		JOIN student s ON a.s_id = s.id
+		JOIN instructor i ON a.i_id = i.id
+		WHERE a.s_id = ? AND a.i_id = ?
 	`
-	row := r.db.QueryRow(query, studentID, instructorID)
+	row := r.db.QueryRowContext(ctx, query, studentID, instructorID)
 
 	var advisor model.Advisor
 	var student model.Student
@@ -58,7 +59,7 @@ func (r *SQLAdvisorRepository) FindByID(studentID, instructorID string) (*model.
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("advisor relationship not found")
+			return nil, fmt.Errorf("advisor relationship not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error querying advisor: %w", err)
 	}
@@ -70,16 +71,16 @@ func (r *SQLAdvisorRepository) FindByID(studentID, instructorID string) (*model.
 }
 
 // FindByStudentID 根据学生ID查找导师关系
-func (r *SQLAdvisorRepository) FindByStudentID(studentID string) ([]*model.Advisor, error) {
+func (r *SQLAdvisorRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.Advisor, error) {
 	query := `
-		SELECT a.student_id, a.instructor_id, s.name as student_name, s.dept_name as student_dept,
+		SELECT a.s_id, a.i_id, s.name as student_name, s.dept_name as student_dept,
 		       i.name as instructor_name, i.dept_name as instructor_dept
 		FROM advisor a
-		JOIN student s ON a.student_id = s.id
-		JOIN instructor i ON a.instructor_id = i.id
-		WHERE a.student_id = ?
+		JOIN student s ON a.s_id = s.id
+		JOIN instructor i ON a.i_id = i.id
+		WHERE a.s_id = ?
 	`
-	rows, err := r.db.Query(query, studentID)
+	rows, err := r.db.QueryContext(ctx, query, studentID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying advisors: %w", err)
 	}
@@ -118,15 +119,15 @@ func (r *SQLAdvisorRepository) FindByStudentID(studentID string) ([]*model.Advis
 }
 
 // FindByInstructorID 根据教师ID查找所有指导的学生
-func (r *SQLAdvisorRepository) FindByInstructorID(instructorID string) ([]*model.Advisor, error) {
+func (r *SQLAdvisorRepository) FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Advisor, error) {
 	query := `
-		SELECT a.student_id, a.instructor_id, s.name as student_name, s.dept_name as student_dept, s.tot_cred
+		SELECT a.s_id, a.i_id, s.name as student_name, s.dept_name as student_dept, s.tot_cred
 		FROM advisor a
-		JOIN student s ON a.student_id = s.id
-		WHERE a.instructor_id = ?
+		JOIN student s ON a.s_id = s.id
+		WHERE a.i_id = ?
 		ORDER BY s.name
 	`
-	rows, err := r.db.Query(query, instructorID)
+	rows, err := r.db.QueryContext(ctx, query, instructorID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying advisors: %w", err)
 	}
@@ -161,24 +162,24 @@ func (r *SQLAdvisorRepository) FindByInstructorID(instructorID string) ([]*model
 }
 
 // Create 创建导师关系
-func (r *SQLAdvisorRepository) Create(advisor *model.Advisor) error {
+func (r *SQLAdvisorRepository) Create(ctx context.Context, advisor *model.Advisor) error {
 	// 检查学生是否已有导师
-	checkQuery := `SELECT instructor_id FROM advisor WHERE student_id = ?`
+	checkQuery := `SELECT i_id FROM advisor WHERE s_id = ?`
 	var existingInstructorID string
-	err := r.db.QueryRow(checkQuery, advisor.StudentID).Scan(&existingInstructorID)
+	err := r.db.QueryRowContext(ctx, checkQuery, advisor.StudentID).Scan(&existingInstructorID)
 	if err == nil {
 		// 学生已有导师
-		return fmt.Errorf("student already has an advisor (instructor_id: %s)", existingInstructorID)
+		return fmt.Errorf("student already has an advisor (instructor_id: %s): %w", existingInstructorID, ErrDuplicate)
 	} else if !errors.Is(err, sql.ErrNoRows) {
 		// 查询出错
 		return fmt.Errorf("error checking existing advisor: %w", err)
 	}
 
 	// 创建新的导师关系
-	query := `INSERT INTO advisor (student_id, instructor_id) VALUES (?, ?)`
-	_, err = r.db.Exec(query, advisor.StudentID, advisor.InstructorID)
+	query := `INSERT INTO advisor (s_id, i_id) VALUES (?, ?)`
+	_, err = r.db.ExecContext(ctx, query, advisor.StudentID, advisor.InstructorID)
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, ErrDuplicate) {
 			return fmt.Errorf("advisor relationship already exists: %w", err)
 		}
 		return fmt.Errorf("error creating advisor relationship: %w", err)
@@ -188,22 +189,22 @@ func (r *SQLAdvisorRepository) Create(advisor *model.Advisor) error {
 }
 
 // Update 更新学生的导师
-func (r *SQLAdvisorRepository) Update(studentID string, instructorID string) error {
+func (r *SQLAdvisorRepository) Update(ctx context.Context, studentID string, instructorID string) error {
 	// 检查学生是否存在导师关系
-	checkQuery := `SELECT instructor_id FROM advisor WHERE student_id = ?`
+	checkQuery := `SELECT i_id FROM advisor WHERE s_id = ?`
 	var existingInstructorID string
-	err := r.db.QueryRow(checkQuery, studentID).Scan(&existingInstructorID)
+	err := r.db.QueryRowContext(ctx, checkQuery, studentID).Scan(&existingInstructorID)
 	if errors.Is(err, sql.ErrNoRows) {
 		// 学生没有导师，创建新关系
-		return r.Create(&model.Advisor{StudentID: studentID, InstructorID: instructorID})
+		return r.Create(ctx, &model.Advisor{StudentID: studentID, InstructorID: instructorID})
 	} else if err != nil {
 		// 查询出错
 		return fmt.Errorf("error checking existing advisor: %w", err)
 	}
 
 	// 更新导师关系
-	query := `UPDATE advisor SET instructor_id = ? WHERE student_id = ?`
-	result, err := r.db.Exec(query, instructorID, studentID)
+	query := `UPDATE advisor SET i_id = ? WHERE s_id = ?`
+	result, err := r.db.ExecContext(ctx, query, instructorID, studentID)
 	if err != nil {
 		return fmt.Errorf("error updating advisor: %w", err)
 	}
@@ -214,23 +215,23 @@ func (r *SQLAdvisorRepository) Update(studentID string, instructorID string) err
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("advisor relationship not found")
+		return fmt.Errorf("advisor relationship not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // FindByStudentAndInstructor 根据学生和导师ID查找导师关系
-func (r *SQLAdvisorRepository) FindByStudentAndInstructor(studentID string, instructorID string) (*model.Advisor, error) {
+func (r *SQLAdvisorRepository) FindByStudentAndInstructor(ctx context.Context, studentID string, instructorID string) (*model.Advisor, error) {
 	query := `
-		SELECT a.student_id, a.instructor_id, s.name as student_name, s.dept_name as student_dept,
+		SELECT a.s_id, a.i_id, s.name as student_name, s.dept_name as student_dept,
 		       i.name as instructor_name, i.dept_name as instructor_dept
 		FROM advisor a
-		JOIN student s ON a.student_id = s.id
-		JOIN instructor i ON a.instructor_id = i.id
-		WHERE a.student_id = ? AND a.instructor_id = ?
+		JOIN student s ON a.s_id = s.id
+		JOIN instructor i ON a.i_id = i.id
+		WHERE a.s_id = ? AND a.i_id = ?
 	`
-	row := r.db.QueryRow(query, studentID, instructorID)
+	row := r.db.QueryRowContext(ctx, query, studentID, instructorID)
 
 	var advisor model.Advisor
 	var student model.Student
@@ -246,7 +247,7 @@ func (r *SQLAdvisorRepository) FindByStudentAndInstructor(studentID string, inst
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("advisor relationship not found: %w", err)
+			return nil, fmt.Errorf("advisor relationship not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error scanning advisor: %w", err)
 	}
@@ -260,10 +261,10 @@ func (r *SQLAdvisorRepository) FindByStudentAndInstructor(studentID string, inst
 }
 
 // Delete 删除导师关系
-func (r *SQLAdvisorRepository) Delete(studentID, instructorID string) error {
+func (r *SQLAdvisorRepository) Delete(ctx context.Context, studentID, instructorID string) error {
 	query := `DELETE FROM advisor WHERE s_id = ? AND i_id = ?`
 
-	result, err := r.db.Exec(query, studentID, instructorID)
+	result, err := r.db.ExecContext(ctx, query, studentID, instructorID)
 	if err != nil {
 		return fmt.Errorf("error deleting advisor: %w", err)
 	}
@@ -274,7 +275,7 @@ func (r *SQLAdvisorRepository) Delete(studentID, instructorID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("advisor relationship not found")
+		return fmt.Errorf("advisor relationship not found: %w", ErrNotFound)
 	}
 
 	return nil
@@ -283,10 +284,10 @@ func (r *SQLAdvisorRepository) Delete(studentID, instructorID string) error {
 // 注意：AdvisorRepository接口已在文件顶部定义，这里不需要重复定义
 
 // 在 SQLAdvisorRepository 中实现 FindAll 方法
-func (r *SQLAdvisorRepository) FindAll() ([]*model.Advisor, error) {
+func (r *SQLAdvisorRepository) FindAll(ctx context.Context) ([]*model.Advisor, error) {
 	query := `SELECT s_id, i_id FROM advisor`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying advisors: %w", err)
 	}
@@ -311,3 +312,21 @@ func (r *SQLAdvisorRepository) FindAll() ([]*model.Advisor, error) {
 
 	return advisors, nil
 }
+
+// advisorListTable 导师关系列表的查询方式
+var advisorListTable = listTable{
+	fields:  model.AdvisorListFields,
+	name:    "advisors",
+	selects: `s_id, i_id`,
+	from:    `advisor`,
+	columns: map[string]string{"student_id": "s_id", "instructor_id": "i_id"},
+}
+
+// List 按分页、排序和过滤条件查找导师关系
+func (r *SQLAdvisorRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Advisor], error) {
+	return list(ctx, r.db, advisorListTable, q, func(row rowScanner) (*model.Advisor, error) {
+		var advisor model.Advisor
+		err := row.Scan(&advisor.StudentID, &advisor.InstructorID)
+		return &advisor, err
+	})
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// APIKeyRepository 定义API密钥仓库接口
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByID(ctx context.Context, id string) (*model.APIKey, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.APIKey], error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// SQLAPIKeyRepository 实现APIKeyRepository接口
+type SQLAPIKeyRepository struct {
+	db DBTX
+}
+
+// NewAPIKeyRepository 创建API密钥仓库实例
+func NewAPIKeyRepository(db DBTX) APIKeyRepository {
+	return &SQLAPIKeyRepository{db: db}
+}
+
+// Create 保存API密钥及其权限，需要在工作单元中调用以保证原子性
+func (r *SQLAPIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	query := `
+		INSERT INTO api_key (id, name, key_hash, dept_name, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.Name, key.KeyHash, key.Dept, key.CreatedBy, key.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creating api key: %w", err)
+	}
+
+	for _, permission := range key.Permissions {
+		query := `INSERT INTO api_key_permission (key_id, permission) VALUES (?, ?)`
+		if _, err := r.db.ExecContext(ctx, query, key.ID, string(permission)); err != nil {
+			return fmt.Errorf("error inserting api key permission: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID 根据ID查找API密钥及其权限
+func (r *SQLAPIKeyRepository) FindByID(ctx context.Context, id string) (*model.APIKey, error) {
+	keys, err := r.find(ctx, `WHERE k.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, ErrNotFound
+	}
+	return keys[0], nil
+}
+
+// apiKeyListTable API密钥列表的查询方式，权限在查出一页密钥后另外加载
+var apiKeyListTable = listTable{
+	fields:  model.APIKeyListFields,
+	name:    "api keys",
+	selects: `id, name, key_hash, dept_name, created_by, created_at, expires_at, last_used_at, revoked_at`,
+	from:    `api_key`,
+	columns: map[string]string{
+		"id":         "id",
+		"name":       "name",
+		"created_at": "created_at",
+		"created_by": "created_by",
+		"dept_name":  "dept_name",
+	},
+}
+
+// List 按分页、排序和过滤条件查找API密钥及其权限，包括已吊销和已过期的
+func (r *SQLAPIKeyRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.APIKey], error) {
+	result, err := list(ctx, r.db, apiKeyListTable, q, func(row rowScanner) (*model.APIKey, error) {
+		var key model.APIKey
+		var expiresAt, lastUsedAt, revokedAt sql.NullTime
+		err := row.Scan(&key.ID, &key.Name, &key.KeyHash, &key.Dept, &key.CreatedBy, &key.CreatedAt, &expiresAt, &lastUsedAt, &revokedAt)
+		if err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			key.ExpiresAt = &expiresAt.Time
+		}
+		if lastUsedAt.Valid {
+			key.LastUsedAt = &lastUsedAt.Time
+		}
+		if revokedAt.Valid {
+			key.RevokedAt = &revokedAt.Time
+		}
+		return &key, nil
+	})
+	if err != nil || len(result.Items) == 0 {
+		return result, err
+	}
+
+	keys := make(map[string]*model.APIKey, len(result.Items))
+	args := make([]interface{}, 0, len(result.Items))
+	for _, key := range result.Items {
+		keys[key.ID] = key
+		args = append(args, key.ID)
+	}
+	query := `SELECT key_id, permission FROM api_key_permission WHERE key_id IN (` +
+		strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ") + `) ORDER BY key_id, permission`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying api key permissions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var keyID, permission string
+		if err := rows.Scan(&keyID, &permission); err != nil {
+			return nil, fmt.Errorf("error scanning api key permission: %w", err)
+		}
+		keys[keyID].Permissions = append(keys[keyID].Permissions, model.Permission(permission))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api key permissions: %w", err)
+	}
+
+	return result, nil
+}
+
+// find 按条件查询API密钥，每个密钥的多个权限合并到同一条记录
+func (r *SQLAPIKeyRepository) find(ctx context.Context, where string, args ...interface{}) ([]*model.APIKey, error) {
+	query := `
+		SELECT k.id, k.name, k.key_hash, k.dept_name, k.created_by, k.created_at, k.expires_at, k.last_used_at, k.revoked_at, p.permission
+		FROM api_key k
+		LEFT JOIN api_key_permission p ON p.key_id = k.id
+		` + where + `
+		ORDER BY k.created_at, k.id, p.permission
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	var current *model.APIKey
+	for rows.Next() {
+		var key model.APIKey
+		var expiresAt, lastUsedAt, revokedAt sql.NullTime
+		var permission sql.NullString
+		err := rows.Scan(
+			&key.ID,
+			&key.Name,
+			&key.KeyHash,
+			&key.Dept,
+			&key.CreatedBy,
+			&key.CreatedAt,
+			&expiresAt,
+			&lastUsedAt,
+			&revokedAt,
+			&permission,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning api key: %w", err)
+		}
+		if current == nil || current.ID != key.ID {
+			if expiresAt.Valid {
+				key.ExpiresAt = &expiresAt.Time
+			}
+			if lastUsedAt.Valid {
+				key.LastUsedAt = &lastUsedAt.Time
+			}
+			if revokedAt.Valid {
+				key.RevokedAt = &revokedAt.Time
+			}
+			current = &key
+			keys = append(keys, current)
+		}
+		if permission.Valid {
+			current.Permissions = append(current.Permissions, model.Permission(permission.String))
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke 吊销API密钥，密钥不存在或已吊销时返回ErrNotFound
+func (r *SQLAPIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE api_key SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, revokedAt, id)
+	if err != nil {
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed 更新API密钥的最近使用时间
+func (r *SQLAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE api_key SET last_used_at = ? WHERE id = ?`, usedAt, id); err != nil {
+		return fmt.Errorf("error updating api key last used time: %w", err)
+	}
+	return nil
+}
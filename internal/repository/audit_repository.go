@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// AuditRepository 定义审计日志仓库接口
+// 审计日志只能追加和查询，不提供修改和删除
+type AuditRepository interface {
+	Create(ctx context.Context, entry *model.AuditEntry) error
+	Find(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+	List(ctx context.Context, filter model.AuditFilter, q *model.ListQuery) (*model.ListResult[model.AuditEntry], error)
+}
+
+// SQLAuditRepository 实现AuditRepository接口
+type SQLAuditRepository struct {
+	db DBTX
+}
+
+// NewAuditRepository 创建审计日志仓库实例
+func NewAuditRepository(db DBTX) AuditRepository {
+	return &SQLAuditRepository{db: db}
+}
+
+// Create 追加一条审计日志
+func (r *SQLAuditRepository) Create(ctx context.Context, entry *model.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor_id, actor_role, action, entity_type, entity_key, before_value, after_value, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := insertReturningID(ctx, r.db, query, entry.ActorID, entry.ActorRole, entry.Action, entry.EntityType, entry.EntityKey,
+		nullableJSON(entry.Before), nullableJSON(entry.After), entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating audit entry: %w", err)
+	}
+	entry.ID = id
+
+	return nil
+}
+
+// auditColumns 审计日志查询的列，顺序与scanAuditEntry一致
+const auditColumns = `id, actor_id, actor_role, action, entity_type, entity_key, before_value, after_value, created_at`
+
+// Find 按条件查询审计日志，按时间倒序
+func (r *SQLAuditRepository) Find(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	where, args := auditConditions(filter)
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE ` + where + ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// List 按条件分页查询审计日志，filter.Limit不起作用
+func (r *SQLAuditRepository) List(ctx context.Context, filter model.AuditFilter, q *model.ListQuery) (*model.ListResult[model.AuditEntry], error) {
+	where, args := auditConditions(filter)
+	table := listTable{
+		fields:  model.AuditListFields,
+		name:    "audit log",
+		selects: auditColumns,
+		from:    `audit_log`,
+		where:   where,
+		args:    args,
+		columns: map[string]string{"id": "id", "created_at": "created_at"},
+	}
+	return list(ctx, r.db, table, q, scanAuditEntry)
+}
+
+// auditConditions 把查询条件转换为WHERE子句
+func auditConditions(filter model.AuditFilter) (string, []interface{}) {
+	where := `1 = 1`
+	var args []interface{}
+
+	if filter.ActorID != "" {
+		where += ` AND actor_id = ?`
+		args = append(args, filter.ActorID)
+	}
+	if filter.ActorRole != "" {
+		where += ` AND actor_role = ?`
+		args = append(args, filter.ActorRole)
+	}
+	if filter.Action != "" {
+		where += ` AND action = ?`
+		args = append(args, filter.Action)
+	}
+	if filter.EntityType != "" {
+		where += ` AND entity_type = ?`
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityKey != "" {
+		where += ` AND entity_key = ?`
+		args = append(args, filter.EntityKey)
+	}
+	if filter.From != nil {
+		where += ` AND created_at >= ?`
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		where += ` AND created_at < ?`
+		args = append(args, *filter.To)
+	}
+
+	return where, args
+}
+
+// scanAuditEntry 扫描一行审计日志
+func scanAuditEntry(scanner rowScanner) (*model.AuditEntry, error) {
+	var entry model.AuditEntry
+	var before, after sql.NullString
+	err := scanner.Scan(
+		&entry.ID,
+		&entry.ActorID,
+		&entry.ActorRole,
+		&entry.Action,
+		&entry.EntityType,
+		&entry.EntityKey,
+		&before,
+		&after,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if before.Valid {
+		entry.Before = []byte(before.String)
+	}
+	if after.Valid {
+		entry.After = []byte(after.String)
+	}
+	return &entry, nil
+}
+
+// nullableJSON 空的JSON快照保存为NULL
+func nullableJSON(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
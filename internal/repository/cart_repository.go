@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// CartRepository 定义选课购物车仓库接口
+type CartRepository interface {
+	FindByStudentID(ctx context.Context, studentID string) ([]*model.CartItem, error)
+	FindByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.CartItem, error)
+	Create(ctx context.Context, item *model.CartItem) error
+	Delete(ctx context.Context, studentID string, key model.SectionKey) error
+}
+
+// SQLCartRepository 实现CartRepository接口
+type SQLCartRepository struct {
+	db DBTX
+}
+
+// NewCartRepository 创建购物车仓库实例
+func NewCartRepository(db DBTX) CartRepository {
+	return &SQLCartRepository{db: db}
+}
+
+// FindByStudentID 查找学生购物车中的所有课程段，按课程段排序，提交时按此顺序加锁
+func (r *SQLCartRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.CartItem, error) {
+	query := `
+		SELECT ci.student_id, ci.course_id, ci.sec_id, ci.semester, ci.year, ci.added_at,
+		       c.title, c.dept_name, c.credits
+		FROM cart_item ci
+		JOIN course c ON ci.course_id = c.course_id
+		WHERE ci.student_id = ?
+		ORDER BY ci.sec_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cart: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*model.CartItem
+	for rows.Next() {
+		var item model.CartItem
+		var course model.Course
+
+		err := rows.Scan(
+			&item.StudentID,
+			&item.CourseID,
+			&item.SectionID,
+			&item.Semester,
+			&item.Year,
+			&item.AddedAt,
+			&course.Title,
+			&course.Dept,
+			&course.Credits,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning cart item: %w", err)
+		}
+
+		course.ID = item.CourseID
+		item.Course = &course
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cart: %w", err)
+	}
+
+	return items, nil
+}
+
+// FindByStudentAndSection 查找学生购物车中的指定课程段
+func (r *SQLCartRepository) FindByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.CartItem, error) {
+	query := `
+		SELECT student_id, course_id, sec_id, semester, year, added_at
+		FROM cart_item
+		WHERE student_id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ?
+	`
+
+	var item model.CartItem
+	err := r.db.QueryRowContext(ctx, query, studentID, key.CourseID, key.SecID, key.Semester, key.Year).Scan(
+		&item.StudentID,
+		&item.CourseID,
+		&item.SectionID,
+		&item.Semester,
+		&item.Year,
+		&item.AddedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying cart item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// Create 将课程段加入购物车
+func (r *SQLCartRepository) Create(ctx context.Context, item *model.CartItem) error {
+	query := `
+		INSERT INTO cart_item (student_id, course_id, sec_id, semester, year, added_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, item.StudentID, item.CourseID, item.SectionID, item.Semester, item.Year, item.AddedAt)
+	if err != nil {
+		return fmt.Errorf("error creating cart item: %w", err)
+	}
+
+	return nil
+}
+
+// Delete 将课程段移出购物车
+func (r *SQLCartRepository) Delete(ctx context.Context, studentID string, key model.SectionKey) error {
+	query := `DELETE FROM cart_item WHERE student_id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
+
+	result, err := r.db.ExecContext(ctx, query, studentID, key.CourseID, key.SecID, key.Semester, key.Year)
+	if err != nil {
+		return fmt.Errorf("error deleting cart item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
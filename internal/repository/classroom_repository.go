@@ -1,41 +1,42 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // ClassroomRepository 定义教室仓储接口
 type ClassroomRepository interface {
-	FindByID(building, roomNumber string) (*model.Classroom, error)
-	FindAll() ([]*model.Classroom, error)
-	FindByBuildingAndRoom(building, roomNumber string) (*model.Classroom, error)
-	FindByBuilding(building string) ([]*model.Classroom, error)
-	Create(classroom *model.Classroom) error
-	Update(classroom *model.Classroom) error
-	Delete(building, roomNumber string) error
-	FindAvailable(capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error)
+	FindByID(ctx context.Context, building, roomNumber string) (*model.Classroom, error)
+	FindAll(ctx context.Context) ([]*model.Classroom, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Classroom], error)
+	FindByBuildingAndRoom(ctx context.Context, building, roomNumber string) (*model.Classroom, error)
+	FindByBuilding(ctx context.Context, building string) ([]*model.Classroom, error)
+	Create(ctx context.Context, classroom *model.Classroom) error
+	Update(ctx context.Context, classroom *model.Classroom) error
+	Delete(ctx context.Context, building, roomNumber string) error
+	FindAvailable(ctx context.Context, capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error)
 }
 
 // SQLClassroomRepository 实现ClassroomRepository接口
 type SQLClassroomRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewClassroomRepository 创建教室仓储实例
-func NewClassroomRepository(db *sql.DB) ClassroomRepository {
+func NewClassroomRepository(db DBTX) ClassroomRepository {
 	return &SQLClassroomRepository{db: db}
 }
 
 // FindByBuilding 根据教学楼查找教室
-func (r *SQLClassroomRepository) FindByBuilding(building string) ([]*model.Classroom, error) {
+func (r *SQLClassroomRepository) FindByBuilding(ctx context.Context, building string) ([]*model.Classroom, error) {
 	query := `SELECT building, room_number, capacity FROM classroom WHERE building = ?`
 
-	rows, err := r.db.Query(query, building)
+	rows, err := r.db.QueryContext(ctx, query, building)
 	if err != nil {
 		return nil, fmt.Errorf("error querying classrooms by building: %w", err)
 	}
@@ -63,15 +64,15 @@ func (r *SQLClassroomRepository) FindByBuilding(building string) ([]*model.Class
 }
 
 // FindByID 根据教学楼和教室号查找教室
-func (r *SQLClassroomRepository) FindByID(building, roomNumber string) (*model.Classroom, error) {
+func (r *SQLClassroomRepository) FindByID(ctx context.Context, building, roomNumber string) (*model.Classroom, error) {
 	query := `SELECT building, room_number, capacity FROM classroom WHERE building = ? AND room_number = ?`
-	row := r.db.QueryRow(query, building, roomNumber)
+	row := r.db.QueryRowContext(ctx, query, building, roomNumber)
 
 	var classroom model.Classroom
 	err := row.Scan(&classroom.Building, &classroom.RoomNumber, &classroom.Capacity)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("classroom not found: %w", err)
+			return nil, fmt.Errorf("classroom not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error scanning classroom: %w", err)
 	}
@@ -80,9 +81,9 @@ func (r *SQLClassroomRepository) FindByID(building, roomNumber string) (*model.C
 }
 
 // FindAll 查找所有教室
-func (r *SQLClassroomRepository) FindAll() ([]*model.Classroom, error) {
+func (r *SQLClassroomRepository) FindAll(ctx context.Context) ([]*model.Classroom, error) {
 	query := `SELECT building, room_number, capacity FROM classroom`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying classrooms: %w", err)
 	}
@@ -105,6 +106,24 @@ func (r *SQLClassroomRepository) FindAll() ([]*model.Classroom, error) {
 	return classrooms, nil
 }
 
+// classroomListTable 教室列表的查询方式
+var classroomListTable = listTable{
+	fields:  model.ClassroomListFields,
+	name:    "classrooms",
+	selects: `building, room_number, capacity`,
+	from:    `classroom`,
+	columns: map[string]string{"building": "building", "room_number": "room_number", "capacity": "capacity"},
+}
+
+// List 按分页、排序和过滤条件查找教室
+func (r *SQLClassroomRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Classroom], error) {
+	return list(ctx, r.db, classroomListTable, q, func(row rowScanner) (*model.Classroom, error) {
+		var classroom model.Classroom
+		err := row.Scan(&classroom.Building, &classroom.RoomNumber, &classroom.Capacity)
+		return &classroom, err
+	})
+}
+
 // FindByBuilding 根据教学楼查找教室
 //func (r *SQLClassroomRepository) FindByBuilding(building string) ([]*model.Classroom, error) {
 //	query := `SELECT building, room_number, capacity FROM classroom WHERE building = ? ORDER BY room_number`
@@ -132,11 +151,11 @@ func (r *SQLClassroomRepository) FindAll() ([]*model.Classroom, error) {
 //}
 
 // Create 创建教室
-func (r *SQLClassroomRepository) Create(classroom *model.Classroom) error {
+func (r *SQLClassroomRepository) Create(ctx context.Context, classroom *model.Classroom) error {
 	query := `INSERT INTO classroom (building, room_number, capacity) VALUES (?, ?, ?)`
-	_, err := r.db.Exec(query, classroom.Building, classroom.RoomNumber, classroom.Capacity)
+	_, err := r.db.ExecContext(ctx, query, classroom.Building, classroom.RoomNumber, classroom.Capacity)
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, ErrDuplicate) {
 			return fmt.Errorf("classroom already exists: %w", err)
 		}
 		return fmt.Errorf("error creating classroom: %w", err)
@@ -146,9 +165,9 @@ func (r *SQLClassroomRepository) Create(classroom *model.Classroom) error {
 }
 
 // Update 更新教室
-func (r *SQLClassroomRepository) Update(classroom *model.Classroom) error {
+func (r *SQLClassroomRepository) Update(ctx context.Context, classroom *model.Classroom) error {
 	query := `UPDATE classroom SET capacity = ? WHERE building = ? AND room_number = ?`
-	result, err := r.db.Exec(query, classroom.Capacity, classroom.Building, classroom.RoomNumber)
+	result, err := r.db.ExecContext(ctx, query, classroom.Capacity, classroom.Building, classroom.RoomNumber)
 	if err != nil {
 		return fmt.Errorf("error updating classroom: %w", err)
 	}
@@ -159,21 +178,21 @@ func (r *SQLClassroomRepository) Update(classroom *model.Classroom) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("classroom not found")
+		return fmt.Errorf("classroom not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // Delete 删除教室
-func (r *SQLClassroomRepository) Delete(building, roomNumber string) error {
+func (r *SQLClassroomRepository) Delete(ctx context.Context, building, roomNumber string) error {
 	// 检查教室是否被使用
 	checkQuery := `
 		SELECT COUNT(*) FROM section 
 		WHERE building = ? AND room_number = ?
 	`
 	var count int
-	err := r.db.QueryRow(checkQuery, building, roomNumber).Scan(&count)
+	err := r.db.QueryRowContext(ctx, checkQuery, building, roomNumber).Scan(&count)
 	if err != nil {
 		return fmt.Errorf("error checking classroom usage: %w", err)
 	}
@@ -184,7 +203,7 @@ func (r *SQLClassroomRepository) Delete(building, roomNumber string) error {
 
 	// 删除教室
 	query := `DELETE FROM classroom WHERE building = ? AND room_number = ?`
-	result, err := r.db.Exec(query, building, roomNumber)
+	result, err := r.db.ExecContext(ctx, query, building, roomNumber)
 	if err != nil {
 		return fmt.Errorf("error deleting classroom: %w", err)
 	}
@@ -195,14 +214,14 @@ func (r *SQLClassroomRepository) Delete(building, roomNumber string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("classroom not found")
+		return fmt.Errorf("classroom not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // FindAvailable 查找可用教室
-func (r *SQLClassroomRepository) FindAvailable(capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error) {
+func (r *SQLClassroomRepository) FindAvailable(ctx context.Context, capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error) {
 	// 查询在指定时间段没有被占用且容量满足要求的教室
 	query := `
 		SELECT c.building, c.room_number, c.capacity
@@ -218,7 +237,7 @@ func (r *SQLClassroomRepository) FindAvailable(capacity int, semester string, ye
 		)
 		ORDER BY c.capacity
 	`
-	rows, err := r.db.Query(query, capacity, semester, year, timeSlotID)
+	rows, err := r.db.QueryContext(ctx, query, capacity, semester, year, timeSlotID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying available classrooms: %w", err)
 	}
@@ -242,11 +261,11 @@ func (r *SQLClassroomRepository) FindAvailable(capacity int, semester string, ye
 }
 
 // FindByBuildingAndRoom 根据建筑和房间号查找教室
-func (r *SQLClassroomRepository) FindByBuildingAndRoom(building string, roomNumber string) (*model.Classroom, error) {
+func (r *SQLClassroomRepository) FindByBuildingAndRoom(ctx context.Context, building string, roomNumber string) (*model.Classroom, error) {
 	query := `SELECT building, room_number, capacity FROM classroom WHERE building = ? AND room_number = ?`
 
 	var classroom model.Classroom
-	err := r.db.QueryRow(query, building, roomNumber).Scan(
+	err := r.db.QueryRowContext(ctx, query, building, roomNumber).Scan(
 		&classroom.Building,
 		&classroom.RoomNumber,
 		&classroom.Capacity,
@@ -254,7 +273,7 @@ func (r *SQLClassroomRepository) FindByBuildingAndRoom(building string, roomNumb
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("classroom not found")
+			return nil, fmt.Errorf("classroom not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error querying classroom: %w", err)
 	}
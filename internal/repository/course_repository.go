@@ -1,45 +1,46 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // CourseRepository 定义课程仓储接口
 type CourseRepository interface {
-	FindByID(id string) (*model.Course, error)
-	FindAll() ([]*model.Course, error)
-	FindByDept(dept string) ([]*model.Course, error)
-	Create(course *model.Course) error
-	Update(course *model.Course) error
-	Delete(id string) error
-	FindWithPrereqs(id string) (*model.CourseWithPrereqs, error)
+	FindByID(ctx context.Context, id string) (*model.Course, error)
+	FindAll(ctx context.Context) ([]*model.Course, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Course], error)
+	FindByDept(ctx context.Context, dept string) ([]*model.Course, error)
+	Create(ctx context.Context, course *model.Course) error
+	Update(ctx context.Context, course *model.Course) error
+	Delete(ctx context.Context, id string) error
+	FindWithPrereqs(ctx context.Context, id string) (*model.CourseWithPrereqs, error)
 }
 
 // SQLCourseRepository 实现CourseRepository接口
 type SQLCourseRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewCourseRepository 创建课程仓储实例
-func NewCourseRepository(db *sql.DB) CourseRepository {
+func NewCourseRepository(db DBTX) CourseRepository {
 	return &SQLCourseRepository{db: db}
 }
 
 // FindByID 根据ID查找课程
-func (r *SQLCourseRepository) FindByID(id string) (*model.Course, error) {
+func (r *SQLCourseRepository) FindByID(ctx context.Context, id string) (*model.Course, error) {
 	query := `SELECT course_id, title, dept_name, credits FROM course WHERE course_id = ?`
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	var course model.Course
 	err := row.Scan(&course.ID, &course.Title, &course.Dept, &course.Credits)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("course not found: %w", err)
+			return nil, fmt.Errorf("course not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error scanning course: %w", err)
 	}
@@ -48,9 +49,9 @@ func (r *SQLCourseRepository) FindByID(id string) (*model.Course, error) {
 }
 
 // FindAll 查找所有课程
-func (r *SQLCourseRepository) FindAll() ([]*model.Course, error) {
+func (r *SQLCourseRepository) FindAll(ctx context.Context) ([]*model.Course, error) {
 	query := `SELECT course_id, title, dept_name, credits FROM course`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying courses: %w", err)
 	}
@@ -73,10 +74,28 @@ func (r *SQLCourseRepository) FindAll() ([]*model.Course, error) {
 	return courses, nil
 }
 
+// courseListTable 课程列表的查询方式
+var courseListTable = listTable{
+	fields:  model.CourseListFields,
+	name:    "courses",
+	selects: `course_id, title, dept_name, credits`,
+	from:    `course`,
+	columns: map[string]string{"id": "course_id", "title": "title", "dept": "dept_name", "credits": "credits"},
+}
+
+// List 按分页、排序和过滤条件查找课程
+func (r *SQLCourseRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Course], error) {
+	return list(ctx, r.db, courseListTable, q, func(row rowScanner) (*model.Course, error) {
+		var course model.Course
+		err := row.Scan(&course.ID, &course.Title, &course.Dept, &course.Credits)
+		return &course, err
+	})
+}
+
 // FindByDept 根据院系查找课程
-func (r *SQLCourseRepository) FindByDept(dept string) ([]*model.Course, error) {
+func (r *SQLCourseRepository) FindByDept(ctx context.Context, dept string) ([]*model.Course, error) {
 	query := `SELECT course_id, title, dept_name, credits FROM course WHERE dept_name = ?`
-	rows, err := r.db.Query(query, dept)
+	rows, err := r.db.QueryContext(ctx, query, dept)
 	if err != nil {
 		return nil, fmt.Errorf("error querying courses by dept: %w", err)
 	}
@@ -100,11 +119,11 @@ func (r *SQLCourseRepository) FindByDept(dept string) ([]*model.Course, error) {
 }
 
 // Create 创建课程
-func (r *SQLCourseRepository) Create(course *model.Course) error {
+func (r *SQLCourseRepository) Create(ctx context.Context, course *model.Course) error {
 	query := `INSERT INTO course (course_id, title, dept_name, credits) VALUES (?, ?, ?, ?)`
-	_, err := r.db.Exec(query, course.ID, course.Title, course.Dept, course.Credits)
+	_, err := r.db.ExecContext(ctx, query, course.ID, course.Title, course.Dept, course.Credits)
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, ErrDuplicate) {
 			return fmt.Errorf("course already exists: %w", err)
 		}
 		return fmt.Errorf("error creating course: %w", err)
@@ -114,9 +133,9 @@ func (r *SQLCourseRepository) Create(course *model.Course) error {
 }
 
 // Update 更新课程
-func (r *SQLCourseRepository) Update(course *model.Course) error {
+func (r *SQLCourseRepository) Update(ctx context.Context, course *model.Course) error {
 	query := `UPDATE course SET title = ?, dept_name = ?, credits = ? WHERE course_id = ?`
-	result, err := r.db.Exec(query, course.Title, course.Dept, course.Credits, course.ID)
+	result, err := r.db.ExecContext(ctx, query, course.Title, course.Dept, course.Credits, course.ID)
 	if err != nil {
 		return fmt.Errorf("error updating course: %w", err)
 	}
@@ -127,16 +146,16 @@ func (r *SQLCourseRepository) Update(course *model.Course) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("course not found")
+		return fmt.Errorf("course not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // Delete 删除课程
-func (r *SQLCourseRepository) Delete(id string) error {
+func (r *SQLCourseRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM course WHERE course_id = ?`
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("error deleting course: %w", err)
 	}
@@ -147,16 +166,16 @@ func (r *SQLCourseRepository) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("course not found")
+		return fmt.Errorf("course not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // FindWithPrereqs 查找课程及其先修课程
-func (r *SQLCourseRepository) FindWithPrereqs(id string) (*model.CourseWithPrereqs, error) {
+func (r *SQLCourseRepository) FindWithPrereqs(ctx context.Context, id string) (*model.CourseWithPrereqs, error) {
 	// 查找课程
-	course, err := r.FindByID(id)
+	course, err := r.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +187,7 @@ func (r *SQLCourseRepository) FindWithPrereqs(id string) (*model.CourseWithPrere
 		JOIN course c ON p.prereq_id = c.course_id 
 		WHERE p.course_id = ?
 	`
-	rows, err := r.db.Query(query, id)
+	rows, err := r.db.QueryContext(ctx, query, id)
 	if err != nil {
 		return nil, fmt.Errorf("error querying prereqs: %w", err)
 	}
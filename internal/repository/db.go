@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/student-management-system/pkg/dialect"
+)
+
+// sqlExecutor *sql.DB 和 *sql.Tx 共有的查询方法
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// executor 按方言改写SQL和参数后执行，并把约束错误转换为仓储层通用错误
+type executor struct {
+	exec    sqlExecutor
+	dialect dialect.Dialect
+}
+
+// ExecContext 执行不返回结果集的语句，ctx取消或超时后中止执行
+func (e executor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := e.exec.ExecContext(ctx, e.dialect.Rebind(query), e.dialect.ConvertArgs(args)...)
+	if err != nil {
+		return nil, translateError(e.dialect, query, err)
+	}
+	return result, nil
+}
+
+// QueryContext 执行返回多行的查询
+func (e executor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := e.exec.QueryContext(ctx, e.dialect.Rebind(query), e.dialect.ConvertArgs(args)...)
+	if err != nil {
+		return nil, translateError(e.dialect, query, err)
+	}
+	return rows, nil
+}
+
+// QueryRowContext 执行返回单行的查询，错误在Scan时返回
+func (e executor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return e.exec.QueryRowContext(ctx, e.dialect.Rebind(query), e.dialect.ConvertArgs(args)...)
+}
+
+// Dialect 返回数据库方言
+func (e executor) Dialect() dialect.Dialect {
+	return e.dialect
+}
+
+// DB 包装数据库连接池，仓库通过它访问数据库，SQL统一使用 ? 占位符
+type DB struct {
+	executor
+	db *sql.DB
+}
+
+// NewDB 创建按方言执行SQL的数据库访问对象
+func NewDB(db *sql.DB, d dialect.Dialect) *DB {
+	return &DB{executor: executor{exec: db, dialect: d}, db: db}
+}
+
+// BeginTx 开启事务，事务中执行的SQL同样按方言改写，ctx在提交或回滚前被取消时事务自动回滚
+func (db *DB) BeginTx(ctx context.Context) (*Tx, error) {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{executor: executor{exec: tx, dialect: db.dialect}, tx: tx}, nil
+}
+
+// Tx 包装数据库事务
+type Tx struct {
+	executor
+	tx *sql.Tx
+}
+
+// Commit 提交事务
+func (tx *Tx) Commit() error {
+	return tx.tx.Commit()
+}
+
+// Rollback 回滚事务
+func (tx *Tx) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+// constraintError 保留驱动返回的原始错误，同时可以用 errors.Is 判断为 ErrDuplicate 或 ErrNotFound
+type constraintError struct {
+	kind error
+	err  error
+}
+
+func (e *constraintError) Error() string {
+	return e.err.Error()
+}
+
+func (e *constraintError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// translateError 把唯一约束冲突转换为ErrDuplicate，把写入时引用的行不存在转换为ErrNotFound
+// 删除被引用的行同样违反外键约束，但不表示资源不存在，保留原始错误
+func translateError(d dialect.Dialect, query string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case d.IsUniqueViolation(err):
+		return &constraintError{kind: ErrDuplicate, err: err}
+	case d.IsForeignKeyViolation(err) && writesRows(query):
+		return &constraintError{kind: ErrNotFound, err: err}
+	default:
+		return err
+	}
+}
+
+// writesRows 判断语句是否为INSERT或UPDATE
+func writesRows(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	verb := strings.ToUpper(fields[0])
+	return verb == "INSERT" || verb == "UPDATE"
+}
+
+// insertReturningID 执行INSERT并返回数据库生成的自增主键，自增列必须名为id
+func insertReturningID(ctx context.Context, db DBTX, query string, args ...interface{}) (int64, error) {
+	if db.Dialect().SupportsLastInsertID() {
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("error getting last insert id: %w", err)
+		}
+		return id, nil
+	}
+
+	var id int64
+	if err := db.QueryRowContext(ctx, strings.TrimSpace(query)+` RETURNING id`, args...).Scan(&id); err != nil {
+		return 0, translateError(db.Dialect(), query, err)
+	}
+	return id, nil
+}
@@ -1,48 +1,49 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // DepartmentRepository 定义院系仓储接口
 type DepartmentRepository interface {
-	FindByID(id string) (*model.Department, error)
-	FindAll() ([]*model.Department, error)
-	FindByDepartment(deptName string) (*model.Department, error)
-	Create(department *model.Department) error
-	Update(department *model.Department) error
-	Delete(deptName string) error
-	GetStudentCount(deptName string) (int, error)
-	GetInstructorCount(deptName string) (int, error)
-	GetCourseCount(deptName string) (int, error)
-	GetDepartmentStats() ([]*model.DepartmentStats, error)
+	FindByID(ctx context.Context, id string) (*model.Department, error)
+	FindAll(ctx context.Context) ([]*model.Department, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Department], error)
+	FindByDepartment(ctx context.Context, deptName string) (*model.Department, error)
+	Create(ctx context.Context, department *model.Department) error
+	Update(ctx context.Context, department *model.Department) error
+	Delete(ctx context.Context, deptName string) error
+	GetStudentCount(ctx context.Context, deptName string) (int, error)
+	GetInstructorCount(ctx context.Context, deptName string) (int, error)
+	GetCourseCount(ctx context.Context, deptName string) (int, error)
+	GetDepartmentStats(ctx context.Context) ([]*model.DepartmentStats, error)
 }
 
 // SQLDepartmentRepository 实现DepartmentRepository接口
 type SQLDepartmentRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewDepartmentRepository 创建院系仓储实例
-func NewDepartmentRepository(db *sql.DB) DepartmentRepository {
+func NewDepartmentRepository(db DBTX) DepartmentRepository {
 	return &SQLDepartmentRepository{db: db}
 }
 
 // FindByID 根据院系名称查找院系
-func (r *SQLDepartmentRepository) FindByID(deptName string) (*model.Department, error) {
+func (r *SQLDepartmentRepository) FindByID(ctx context.Context, deptName string) (*model.Department, error) {
 	query := `SELECT dept_name, building, budget FROM department WHERE dept_name = ?`
-	row := r.db.QueryRow(query, deptName)
+	row := r.db.QueryRowContext(ctx, query, deptName)
 
 	var department model.Department
 	err := row.Scan(&department.DeptName, &department.Building, &department.Budget)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("department not found: %w", err)
+			return nil, fmt.Errorf("department not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error scanning department: %w", err)
 	}
@@ -51,9 +52,9 @@ func (r *SQLDepartmentRepository) FindByID(deptName string) (*model.Department,
 }
 
 // FindAll 查找所有院系
-func (r *SQLDepartmentRepository) FindAll() ([]*model.Department, error) {
+func (r *SQLDepartmentRepository) FindAll(ctx context.Context) ([]*model.Department, error) {
 	query := `SELECT dept_name, building, budget FROM department ORDER BY dept_name`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying departments: %w", err)
 	}
@@ -76,12 +77,30 @@ func (r *SQLDepartmentRepository) FindAll() ([]*model.Department, error) {
 	return departments, nil
 }
 
+// departmentListTable 院系列表的查询方式
+var departmentListTable = listTable{
+	fields:  model.DepartmentListFields,
+	name:    "departments",
+	selects: `dept_name, building, budget`,
+	from:    `department`,
+	columns: map[string]string{"dept_name": "dept_name", "building": "building", "budget": "budget"},
+}
+
+// List 按分页、排序和过滤条件查找院系
+func (r *SQLDepartmentRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Department], error) {
+	return list(ctx, r.db, departmentListTable, q, func(row rowScanner) (*model.Department, error) {
+		var department model.Department
+		err := row.Scan(&department.DeptName, &department.Building, &department.Budget)
+		return &department, err
+	})
+}
+
 // Create 创建院系
-func (r *SQLDepartmentRepository) Create(department *model.Department) error {
+func (r *SQLDepartmentRepository) Create(ctx context.Context, department *model.Department) error {
 	query := `INSERT INTO department (dept_name, building, budget) VALUES (?, ?, ?)`
-	_, err := r.db.Exec(query, department.DeptName, department.Building, department.Budget)
+	_, err := r.db.ExecContext(ctx, query, department.DeptName, department.Building, department.Budget)
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, ErrDuplicate) {
 			return fmt.Errorf("department already exists: %w", err)
 		}
 		return fmt.Errorf("error creating department: %w", err)
@@ -91,9 +110,9 @@ func (r *SQLDepartmentRepository) Create(department *model.Department) error {
 }
 
 // Update 更新院系
-func (r *SQLDepartmentRepository) Update(department *model.Department) error {
+func (r *SQLDepartmentRepository) Update(ctx context.Context, department *model.Department) error {
 	query := `UPDATE department SET building = ?, budget = ? WHERE dept_name = ?`
-	result, err := r.db.Exec(query, department.Building, department.Budget, department.DeptName)
+	result, err := r.db.ExecContext(ctx, query, department.Building, department.Budget, department.DeptName)
 	if err != nil {
 		return fmt.Errorf("error updating department: %w", err)
 	}
@@ -104,18 +123,18 @@ func (r *SQLDepartmentRepository) Update(department *model.Department) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("department not found")
+		return fmt.Errorf("department not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // Delete 删除院系
-func (r *SQLDepartmentRepository) Delete(deptName string) error {
+func (r *SQLDepartmentRepository) Delete(ctx context.Context, deptName string) error {
 	// 检查院系是否有关联的学生
 	studentQuery := `SELECT COUNT(*) FROM student WHERE dept_name = ?`
 	var studentCount int
-	err := r.db.QueryRow(studentQuery, deptName).Scan(&studentCount)
+	err := r.db.QueryRowContext(ctx, studentQuery, deptName).Scan(&studentCount)
 	if err != nil {
 		return fmt.Errorf("error checking student count: %w", err)
 	}
@@ -127,7 +146,7 @@ func (r *SQLDepartmentRepository) Delete(deptName string) error {
 	// 检查院系是否有关联的教师
 	instructorQuery := `SELECT COUNT(*) FROM instructor WHERE dept_name = ?`
 	var instructorCount int
-	err = r.db.QueryRow(instructorQuery, deptName).Scan(&instructorCount)
+	err = r.db.QueryRowContext(ctx, instructorQuery, deptName).Scan(&instructorCount)
 	if err != nil {
 		return fmt.Errorf("error checking instructor count: %w", err)
 	}
@@ -139,7 +158,7 @@ func (r *SQLDepartmentRepository) Delete(deptName string) error {
 	// 检查院系是否有关联的课程
 	courseQuery := `SELECT COUNT(*) FROM course WHERE dept_name = ?`
 	var courseCount int
-	err = r.db.QueryRow(courseQuery, deptName).Scan(&courseCount)
+	err = r.db.QueryRowContext(ctx, courseQuery, deptName).Scan(&courseCount)
 	if err != nil {
 		return fmt.Errorf("error checking course count: %w", err)
 	}
@@ -150,7 +169,7 @@ func (r *SQLDepartmentRepository) Delete(deptName string) error {
 
 	// 删除院系
 	query := `DELETE FROM department WHERE dept_name = ?`
-	result, err := r.db.Exec(query, deptName)
+	result, err := r.db.ExecContext(ctx, query, deptName)
 	if err != nil {
 		return fmt.Errorf("error deleting department: %w", err)
 	}
@@ -161,17 +180,17 @@ func (r *SQLDepartmentRepository) Delete(deptName string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("department not found")
+		return fmt.Errorf("department not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // GetStudentCount 获取院系学生数量
-func (r *SQLDepartmentRepository) GetStudentCount(deptName string) (int, error) {
+func (r *SQLDepartmentRepository) GetStudentCount(ctx context.Context, deptName string) (int, error) {
 	query := `SELECT COUNT(*) FROM student WHERE dept_name = ?`
 	var count int
-	err := r.db.QueryRow(query, deptName).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, deptName).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error getting student count: %w", err)
 	}
@@ -180,10 +199,10 @@ func (r *SQLDepartmentRepository) GetStudentCount(deptName string) (int, error)
 }
 
 // GetInstructorCount 获取院系教师数量
-func (r *SQLDepartmentRepository) GetInstructorCount(deptName string) (int, error) {
+func (r *SQLDepartmentRepository) GetInstructorCount(ctx context.Context, deptName string) (int, error) {
 	query := `SELECT COUNT(*) FROM instructor WHERE dept_name = ?`
 	var count int
-	err := r.db.QueryRow(query, deptName).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, deptName).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error getting instructor count: %w", err)
 	}
@@ -192,10 +211,10 @@ func (r *SQLDepartmentRepository) GetInstructorCount(deptName string) (int, erro
 }
 
 // GetCourseCount 获取院系课程数量
-func (r *SQLDepartmentRepository) GetCourseCount(deptName string) (int, error) {
+func (r *SQLDepartmentRepository) GetCourseCount(ctx context.Context, deptName string) (int, error) {
 	query := `SELECT COUNT(*) FROM course WHERE dept_name = ?`
 	var count int
-	err := r.db.QueryRow(query, deptName).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, deptName).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error getting course count: %w", err)
 	}
@@ -204,7 +223,7 @@ func (r *SQLDepartmentRepository) GetCourseCount(deptName string) (int, error) {
 }
 
 // GetDepartmentStats 获取所有院系统计信息
-func (r *SQLDepartmentRepository) GetDepartmentStats() ([]*model.DepartmentStats, error) {
+func (r *SQLDepartmentRepository) GetDepartmentStats(ctx context.Context) ([]*model.DepartmentStats, error) {
 	query := `
 		SELECT 
 			d.dept_name, 
@@ -216,7 +235,7 @@ func (r *SQLDepartmentRepository) GetDepartmentStats() ([]*model.DepartmentStats
 		FROM department d
 		ORDER BY d.dept_name
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying department stats: %w", err)
 	}
@@ -253,8 +272,8 @@ func (r *SQLDepartmentRepository) GetDepartmentStats() ([]*model.DepartmentStats
 // 以下是额外的方法实现
 
 // 在 SQLDepartmentRepository 中实现缺少的方法
-func (r *SQLDepartmentRepository) FindByDepartment(deptName string) (*model.Department, error) {
-	return r.FindByID(deptName)
+func (r *SQLDepartmentRepository) FindByDepartment(ctx context.Context, deptName string) (*model.Department, error) {
+	return r.FindByID(ctx, deptName)
 }
 
 // FindByDepartment方法已通过FindByID实现
\ No newline at end of file
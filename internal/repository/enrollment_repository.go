@@ -1,63 +1,64 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
+
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // EnrollmentRepository 定义选课仓库接口
 type EnrollmentRepository interface {
-	FindByID(id string) (*model.Enrollment, error)
-	FindByStudentID(studentID string) ([]*model.Enrollment, error)
-	FindBySectionID(sectionID string) ([]*model.Enrollment, error)
-	FindByParams(params *model.EnrollmentQueryParams) ([]*model.Enrollment, error)
-	Create(enrollment *model.Enrollment) error
-	Update(enrollment *model.Enrollment) error
-	Delete(id string) error
+	FindByID(ctx context.Context, id string) (*model.Enrollment, error)
+	FindByStudentID(ctx context.Context, studentID string) ([]*model.Enrollment, error)
+	FindBySectionID(ctx context.Context, sectionID string) ([]*model.Enrollment, error)
+	FindByParams(ctx context.Context, params *model.EnrollmentQueryParams) ([]*model.Enrollment, error)
+	Create(ctx context.Context, enrollment *model.Enrollment) error
+	Update(ctx context.Context, enrollment *model.Enrollment) error
+	Delete(ctx context.Context, id string) error
 }
 
 // SQLEnrollmentRepository 实现EnrollmentRepository接口
 type SQLEnrollmentRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewEnrollmentRepository 创建选课仓库实例
-func NewEnrollmentRepository(db *sql.DB) EnrollmentRepository {
+func NewEnrollmentRepository(db DBTX) EnrollmentRepository {
 	return &SQLEnrollmentRepository{db: db}
 }
 
 // 实现所有接口方法...
-func (r *SQLEnrollmentRepository) FindByID(id string) (*model.Enrollment, error) {
+func (r *SQLEnrollmentRepository) FindByID(ctx context.Context, id string) (*model.Enrollment, error) {
 	// 实现代码
 	return nil, nil
 }
 
-func (r *SQLEnrollmentRepository) FindByStudentID(studentID string) ([]*model.Enrollment, error) {
+func (r *SQLEnrollmentRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.Enrollment, error) {
 	// 实现代码
 	return nil, nil
 }
 
-func (r *SQLEnrollmentRepository) FindBySectionID(sectionID string) ([]*model.Enrollment, error) {
+func (r *SQLEnrollmentRepository) FindBySectionID(ctx context.Context, sectionID string) ([]*model.Enrollment, error) {
 	// 实现代码
 	return nil, nil
 }
 
-func (r *SQLEnrollmentRepository) FindByParams(params *model.EnrollmentQueryParams) ([]*model.Enrollment, error) {
+func (r *SQLEnrollmentRepository) FindByParams(ctx context.Context, params *model.EnrollmentQueryParams) ([]*model.Enrollment, error) {
 	// 实现代码
 	return nil, nil
 }
 
-func (r *SQLEnrollmentRepository) Create(enrollment *model.Enrollment) error {
+func (r *SQLEnrollmentRepository) Create(ctx context.Context, enrollment *model.Enrollment) error {
 	// 实现代码
 	return nil
 }
 
-func (r *SQLEnrollmentRepository) Update(enrollment *model.Enrollment) error {
+func (r *SQLEnrollmentRepository) Update(ctx context.Context, enrollment *model.Enrollment) error {
 	// 实现代码
 	return nil
 }
 
-func (r *SQLEnrollmentRepository) Delete(id string) error {
+func (r *SQLEnrollmentRepository) Delete(ctx context.Context, id string) error {
 	// 实现代码
 	return nil
 }
@@ -1,34 +1,34 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // SQLInstructorRepository 实现InstructorRepository接口
 type SQLInstructorRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewInstructorRepository 创建教师仓储实例
-func NewInstructorRepository(db *sql.DB) InstructorRepository {
+func NewInstructorRepository(db DBTX) InstructorRepository {
 	return &SQLInstructorRepository{db: db}
 }
 
 // GetByID 根据ID查找教师
-func (r *SQLInstructorRepository) GetByID(id string) (*model.Instructor, error) {
-	query := `SELECT id, name, dept_name, salary, password, salt FROM instructor WHERE id = ?`
-	row := r.db.QueryRow(query, id)
+func (r *SQLInstructorRepository) GetByID(ctx context.Context, id string) (*model.Instructor, error) {
+	query := `SELECT id, name, dept_name, salary, password, salt, must_change_password FROM instructor WHERE id = ?`
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	var instructor model.Instructor
-	err := row.Scan(&instructor.ID, &instructor.Name, &instructor.Dept, &instructor.Salary, &instructor.Password, &instructor.Salt)
+	err := row.Scan(&instructor.ID, &instructor.Name, &instructor.Dept, &instructor.Salary, &instructor.Password, &instructor.Salt, &instructor.MustChangePassword)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("instructor not found: %w", err)
+			return nil, fmt.Errorf("instructor not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error scanning instructor: %w", err)
 	}
@@ -36,46 +36,30 @@ func (r *SQLInstructorRepository) GetByID(id string) (*model.Instructor, error)
 	return &instructor, nil
 }
 
-// List 查找所有教师
-func (r *SQLInstructorRepository) List(page, pageSize int) ([]*model.Instructor, int64, error) {
-	var total int64
-	countQuery := `SELECT COUNT(*) FROM instructor`
-	err := r.db.QueryRow(countQuery).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("error counting instructors: %w", err)
-	}
-
-	offset := (page - 1) * pageSize
-	query := `SELECT id, name, dept_name, salary FROM instructor LIMIT ? OFFSET ?`
-	rows, err := r.db.Query(query, pageSize, offset)
-	if err != nil {
-		return nil, 0, fmt.Errorf("error querying instructors: %w", err)
-	}
-	defer rows.Close()
+// instructorListTable 教师列表的查询方式
+var instructorListTable = listTable{
+	fields:  model.InstructorListFields,
+	name:    "instructors",
+	selects: `id, name, dept_name, salary`,
+	from:    `instructor`,
+	columns: map[string]string{"id": "id", "name": "name", "dept": "dept_name", "salary": "salary"},
+}
 
-	var instructors []*model.Instructor
-	for rows.Next() {
+// List 按分页、排序和过滤条件查找教师
+func (r *SQLInstructorRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Instructor], error) {
+	return list(ctx, r.db, instructorListTable, q, func(row rowScanner) (*model.Instructor, error) {
 		var instructor model.Instructor
-		err := rows.Scan(&instructor.ID, &instructor.Name, &instructor.Dept, &instructor.Salary)
-		if err != nil {
-			return nil, 0, fmt.Errorf("error scanning instructor: %w", err)
-		}
-		instructors = append(instructors, &instructor)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating instructors: %w", err)
-	}
-
-	return instructors, total, nil
+		err := row.Scan(&instructor.ID, &instructor.Name, &instructor.Dept, &instructor.Salary)
+		return &instructor, err
+	})
 }
 
 // Create 创建教师
-func (r *SQLInstructorRepository) Create(instructor *model.Instructor) error {
-	query := `INSERT INTO instructor (id, name, dept_name, salary, password, salt) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err := r.db.Exec(query, instructor.ID, instructor.Name, instructor.Dept, instructor.Salary, instructor.Password, instructor.Salt)
+func (r *SQLInstructorRepository) Create(ctx context.Context, instructor *model.Instructor) error {
+	query := `INSERT INTO instructor (id, name, dept_name, salary, password, salt, must_change_password) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, instructor.ID, instructor.Name, instructor.Dept, instructor.Salary, instructor.Password, instructor.Salt, instructor.MustChangePassword)
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, ErrDuplicate) {
 			return fmt.Errorf("instructor already exists: %w", err)
 		}
 		return fmt.Errorf("error creating instructor: %w", err)
@@ -85,9 +69,9 @@ func (r *SQLInstructorRepository) Create(instructor *model.Instructor) error {
 }
 
 // Update 更新教师
-func (r *SQLInstructorRepository) Update(instructor *model.Instructor) error {
+func (r *SQLInstructorRepository) Update(ctx context.Context, instructor *model.Instructor) error {
 	query := `UPDATE instructor SET name = ?, dept_name = ?, salary = ? WHERE id = ?`
-	result, err := r.db.Exec(query, instructor.Name, instructor.Dept, instructor.Salary, instructor.ID)
+	result, err := r.db.ExecContext(ctx, query, instructor.Name, instructor.Dept, instructor.Salary, instructor.ID)
 	if err != nil {
 		return fmt.Errorf("error updating instructor: %w", err)
 	}
@@ -98,16 +82,16 @@ func (r *SQLInstructorRepository) Update(instructor *model.Instructor) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("instructor not found")
+		return fmt.Errorf("instructor not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // Delete 删除教师
-func (r *SQLInstructorRepository) Delete(id string) error {
+func (r *SQLInstructorRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM instructor WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("error deleting instructor: %w", err)
 	}
@@ -118,17 +102,17 @@ func (r *SQLInstructorRepository) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("instructor not found")
+		return fmt.Errorf("instructor not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // ExistsByID 检查指定ID的教师是否存在
-func (r *SQLInstructorRepository) ExistsByID(id string) (bool, error) {
+func (r *SQLInstructorRepository) ExistsByID(ctx context.Context, id string) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM instructor WHERE id = ?)`
-	err := r.db.QueryRow(query, id).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("error checking instructor existence: %w", err)
 	}
@@ -136,10 +120,10 @@ func (r *SQLInstructorRepository) ExistsByID(id string) (bool, error) {
 }
 
 // Search 搜索教师
-func (r *SQLInstructorRepository) Search(query string) ([]*model.Instructor, error) {
+func (r *SQLInstructorRepository) Search(ctx context.Context, query string) ([]*model.Instructor, error) {
 	sqlQuery := `SELECT id, name, dept_name, salary FROM instructor WHERE id LIKE ? OR name LIKE ? OR dept_name LIKE ?`
 	pattern := "%" + query + "%"
-	rows, err := r.db.Query(sqlQuery, pattern, pattern, pattern)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, pattern, pattern, pattern)
 	if err != nil {
 		return nil, fmt.Errorf("error searching instructors: %w", err)
 	}
@@ -163,9 +147,9 @@ func (r *SQLInstructorRepository) Search(query string) ([]*model.Instructor, err
 }
 
 // UpdatePassword 更新教师密码
-func (r *SQLInstructorRepository) UpdatePassword(id, hashedPassword, salt string) error {
-	query := `UPDATE instructor SET password = ?, salt = ? WHERE id = ?`
-	result, err := r.db.Exec(query, hashedPassword, salt, id)
+func (r *SQLInstructorRepository) UpdatePassword(ctx context.Context, id, hashedPassword, salt string, mustChange bool) error {
+	query := `UPDATE instructor SET password = ?, salt = ?, must_change_password = ? WHERE id = ?`
+	result, err := r.db.ExecContext(ctx, query, hashedPassword, salt, mustChange, id)
 	if err != nil {
 		return fmt.Errorf("error updating instructor password: %w", err)
 	}
@@ -176,7 +160,7 @@ func (r *SQLInstructorRepository) UpdatePassword(id, hashedPassword, salt string
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("instructor not found")
+		return fmt.Errorf("instructor not found: %w", ErrNotFound)
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// listTable 描述如何用SQL响应一类资源的通用列表查询
+type listTable struct {
+	fields  model.ListFields
+	name    string            // 用于错误信息的资源名称
+	selects string            // SELECT的列，顺序与scan一致
+	from    string            // FROM之后的表和连接
+	where   string            // 固定的查询条件，可以为空
+	args    []interface{}     // where中占位符的取值
+	columns map[string]string // 排序和过滤字段对应的列
+	filters map[string]string // 不能直接按列比较的过滤字段，条件中用?引用取值
+}
+
+// list 按查询条件统计总数并查询一页记录，过滤字段取等值，排序最后总是带上主键保证翻页稳定
+func list[T any](ctx context.Context, db DBTX, t listTable, q *model.ListQuery, scan func(rowScanner) (*T, error)) (*model.ListResult[T], error) {
+	if err := q.Normalize(t.fields); err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	args := append([]interface{}{}, t.args...)
+	if t.where != "" {
+		conditions = append(conditions, t.where)
+	}
+	fields := make([]string, 0, len(q.Filters))
+	for field := range q.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		condition, ok := t.filters[field]
+		if !ok {
+			condition = t.columns[field] + ` = ?`
+		}
+		conditions = append(conditions, condition)
+		args = append(args, filterArg(t.fields, field, q.Filters[field]))
+	}
+
+	from := ` FROM ` + t.from
+	if len(conditions) > 0 {
+		from += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	var total int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("error counting %s: %w", t.name, err)
+	}
+
+	orderBy := make([]string, 0, len(q.Sort))
+	for _, s := range q.Sort {
+		column := t.columns[s.Field]
+		if s.Desc {
+			column += ` DESC`
+		}
+		orderBy = append(orderBy, column)
+	}
+	query := `SELECT ` + t.selects + from + ` ORDER BY ` + strings.Join(orderBy, `, `) +
+		db.Dialect().LimitOffset(q.PageSize, q.Offset())
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	var items []*T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning %s: %w", t.name, err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s: %w", t.name, err)
+	}
+
+	return model.NewListResult(q, items, total), nil
+}
+
+// filterArg 整数字段的过滤值按整数传给数据库，Normalize已经检查过格式
+func filterArg(fields model.ListFields, field string, value string) interface{} {
+	for _, numeric := range fields.Numeric {
+		if numeric == field {
+			n, _ := strconv.Atoi(value)
+			return n
+		}
+	}
+	return value
+}
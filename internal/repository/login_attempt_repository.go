@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// LoginAttemptRepository 定义登录尝试记录和失败计数仓库接口
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *model.LoginAttempt) error
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginAttempt], error)
+
+	FindThrottle(ctx context.Context, scope string, key string) (*model.LoginThrottle, error)
+	ListLockedAccounts(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginThrottle], error)
+	IncrementFailures(ctx context.Context, scope string, key string, at time.Time) (*model.LoginThrottle, error)
+	Lock(ctx context.Context, scope string, key string, at time.Time) error
+	ResetThrottle(ctx context.Context, scope string, key string) error
+}
+
+// SQLLoginAttemptRepository 实现LoginAttemptRepository接口
+type SQLLoginAttemptRepository struct {
+	db DBTX
+}
+
+// NewLoginAttemptRepository 创建登录尝试仓库实例
+func NewLoginAttemptRepository(db DBTX) LoginAttemptRepository {
+	return &SQLLoginAttemptRepository{db: db}
+}
+
+// Create 记录一次登录尝试
+func (r *SQLLoginAttemptRepository) Create(ctx context.Context, attempt *model.LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempt (user_id, role, ip, user_agent, success, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := insertReturningID(ctx, r.db, query, attempt.UserID, attempt.Role, attempt.IP, attempt.UserAgent,
+		attempt.Success, attempt.Reason, attempt.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error recording login attempt: %w", err)
+	}
+	attempt.ID = id
+
+	return nil
+}
+
+// loginAttemptListTable 登录尝试列表的查询方式
+var loginAttemptListTable = listTable{
+	fields:  model.LoginAttemptListFields,
+	name:    "login attempts",
+	selects: `id, user_id, role, ip, user_agent, success, reason, created_at`,
+	from:    `login_attempt`,
+	columns: map[string]string{
+		"id":         "id",
+		"user_id":    "user_id",
+		"role":       "role",
+		"ip":         "ip",
+		"reason":     "reason",
+		"created_at": "created_at",
+	},
+}
+
+// List 按分页、排序和过滤条件查询登录尝试
+func (r *SQLLoginAttemptRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginAttempt], error) {
+	return list(ctx, r.db, loginAttemptListTable, q, func(row rowScanner) (*model.LoginAttempt, error) {
+		var attempt model.LoginAttempt
+		err := row.Scan(
+			&attempt.ID,
+			&attempt.UserID,
+			&attempt.Role,
+			&attempt.IP,
+			&attempt.UserAgent,
+			&attempt.Success,
+			&attempt.Reason,
+			&attempt.CreatedAt,
+		)
+		return &attempt, err
+	})
+}
+
+// scanThrottle 扫描一行失败计数
+func scanThrottle(scanner rowScanner) (*model.LoginThrottle, error) {
+	var throttle model.LoginThrottle
+	var lockedAt sql.NullTime
+
+	err := scanner.Scan(&throttle.Scope, &throttle.Key, &throttle.Failures, &throttle.LastFailureAt, &lockedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if lockedAt.Valid {
+		throttle.LockedAt = &lockedAt.Time
+	}
+
+	return &throttle, nil
+}
+
+// FindThrottle 查找失败计数，没有记录时返回ErrNotFound
+func (r *SQLLoginAttemptRepository) FindThrottle(ctx context.Context, scope string, key string) (*model.LoginThrottle, error) {
+	query := `SELECT scope, throttle_key, failures, last_failure_at, locked_at FROM login_throttle WHERE scope = ? AND throttle_key = ?`
+
+	throttle, err := scanThrottle(r.db.QueryRowContext(ctx, query, scope, key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying login throttle: %w", err)
+	}
+
+	return throttle, nil
+}
+
+// lockedAccountListTable 被锁定账号列表的查询方式，只包括账号维度的计数
+var lockedAccountListTable = listTable{
+	fields:  model.LockedAccountListFields,
+	name:    "locked accounts",
+	selects: `scope, throttle_key, failures, last_failure_at, locked_at`,
+	from:    `login_throttle`,
+	where:   `scope = '` + model.ThrottleScopeAccount + `' AND locked_at IS NOT NULL`,
+	columns: map[string]string{
+		"key":             "throttle_key",
+		"failures":        "failures",
+		"last_failure_at": "last_failure_at",
+		"locked_at":       "locked_at",
+	},
+}
+
+// ListLockedAccounts 按分页和排序条件查找已锁定的账号
+func (r *SQLLoginAttemptRepository) ListLockedAccounts(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginThrottle], error) {
+	return list(ctx, r.db, lockedAccountListTable, q, scanThrottle)
+}
+
+// IncrementFailures 原子地增加失败次数并返回最新的计数
+func (r *SQLLoginAttemptRepository) IncrementFailures(ctx context.Context, scope string, key string, at time.Time) (*model.LoginThrottle, error) {
+	query := `
+		INSERT INTO login_throttle (scope, throttle_key, failures, last_failure_at)
+		VALUES (?, ?, 1, ?)
+	` + r.db.Dialect().Upsert([]string{"scope", "throttle_key"},
+		"failures = login_throttle.failures + 1", "last_failure_at = excluded.last_failure_at")
+
+	if _, err := r.db.ExecContext(ctx, query, scope, key, at); err != nil {
+		return nil, fmt.Errorf("error incrementing login failures: %w", err)
+	}
+
+	return r.FindThrottle(ctx, scope, key)
+}
+
+// Lock 锁定账号
+func (r *SQLLoginAttemptRepository) Lock(ctx context.Context, scope string, key string, at time.Time) error {
+	query := `UPDATE login_throttle SET locked_at = ? WHERE scope = ? AND throttle_key = ? AND locked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, at, scope, key); err != nil {
+		return fmt.Errorf("error locking account: %w", err)
+	}
+
+	return nil
+}
+
+// ResetThrottle 清除失败计数和锁定
+func (r *SQLLoginAttemptRepository) ResetThrottle(ctx context.Context, scope string, key string) error {
+	query := `DELETE FROM login_throttle WHERE scope = ? AND throttle_key = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("error resetting login throttle: %w", err)
+	}
+
+	return nil
+}
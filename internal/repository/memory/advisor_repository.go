@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// AdvisorRepository 导师关系仓储的内存实现，每个学生最多有一位导师
+type AdvisorRepository struct {
+	store *Store
+}
+
+// NewAdvisorRepository 创建导师关系仓储实例
+func NewAdvisorRepository(store *Store) repository.AdvisorRepository {
+	return &AdvisorRepository{store: store}
+}
+
+// FindByID 根据学生ID和导师ID查找导师关系，附带学生和导师信息
+func (r *AdvisorRepository) FindByID(ctx context.Context, studentID, instructorID string) (*model.Advisor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if current, ok := r.store.advisors[studentID]; !ok || current != instructorID {
+		return nil, fmt.Errorf("advisor relationship not found: %w", repository.ErrNotFound)
+	}
+	return r.store.advisorWithDetails(studentID), nil
+}
+
+// FindByStudentAndInstructor 根据学生ID和导师ID查找导师关系
+func (r *AdvisorRepository) FindByStudentAndInstructor(ctx context.Context, studentID string, instructorID string) (*model.Advisor, error) {
+	return r.FindByID(ctx, studentID, instructorID)
+}
+
+// FindAll 查找所有导师关系
+func (r *AdvisorRepository) FindAll(ctx context.Context) ([]*model.Advisor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var advisors []*model.Advisor
+	for _, studentID := range sortedKeys(r.store.advisors, lessString) {
+		advisors = append(advisors, &model.Advisor{StudentID: studentID, InstructorID: r.store.advisors[studentID]})
+	}
+	return advisors, nil
+}
+
+// advisorListSpec 导师关系列表的字段取值
+var advisorListSpec = listSpec[model.Advisor]{
+	fields: model.AdvisorListFields,
+	values: map[string]func(*model.Advisor) interface{}{
+		"student_id":    func(a *model.Advisor) interface{} { return a.StudentID },
+		"instructor_id": func(a *model.Advisor) interface{} { return a.InstructorID },
+	},
+}
+
+// List 按分页、排序和过滤条件查找导师关系
+func (r *AdvisorRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Advisor], error) {
+	advisors, _ := r.FindAll(ctx)
+	return list(advisors, advisorListSpec, q)
+}
+
+// FindByStudentID 根据学生ID查找导师关系
+func (r *AdvisorRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.Advisor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.advisors[studentID]; !ok {
+		return nil, nil
+	}
+	return []*model.Advisor{r.store.advisorWithDetails(studentID)}, nil
+}
+
+// FindByInstructorID 根据导师ID查找其指导的学生
+func (r *AdvisorRepository) FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Advisor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var advisors []*model.Advisor
+	for _, studentID := range sortedKeys(r.store.advisors, lessString) {
+		if r.store.advisors[studentID] == instructorID {
+			advisors = append(advisors, r.store.advisorWithDetails(studentID))
+		}
+	}
+	return advisors, nil
+}
+
+// Create 创建导师关系，学生已有导师时返回ErrDuplicate
+func (r *AdvisorRepository) Create(ctx context.Context, advisor *model.Advisor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.setAdvisor(advisor.StudentID, advisor.InstructorID, false)
+}
+
+// Update 更新学生的导师，学生还没有导师时直接建立关系
+func (r *AdvisorRepository) Update(ctx context.Context, studentID string, instructorID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.setAdvisor(studentID, instructorID, true)
+}
+
+// Delete 删除导师关系
+func (r *AdvisorRepository) Delete(ctx context.Context, studentID, instructorID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if current, ok := r.store.advisors[studentID]; !ok || current != instructorID {
+		return fmt.Errorf("advisor relationship not found: %w", repository.ErrNotFound)
+	}
+
+	delete(r.store.advisors, studentID)
+	return nil
+}
+
+// setAdvisor 检查外键后保存导师关系，replace为false时学生已有导师返回ErrDuplicate，调用方需持有锁
+func (s *Store) setAdvisor(studentID, instructorID string, replace bool) error {
+	if existing, ok := s.advisors[studentID]; ok && !replace {
+		return fmt.Errorf("student already has an advisor (instructor_id: %s): %w", existing, repository.ErrDuplicate)
+	}
+	if _, ok := s.students[studentID]; !ok {
+		return fmt.Errorf("student %s: %w", studentID, repository.ErrNotFound)
+	}
+	if _, ok := s.instructors[instructorID]; !ok {
+		return fmt.Errorf("instructor %s: %w", instructorID, repository.ErrNotFound)
+	}
+
+	s.advisors[studentID] = instructorID
+	return nil
+}
+
+// advisorWithDetails 返回附带学生和导师信息的导师关系，调用方需持有锁
+func (s *Store) advisorWithDetails(studentID string) *model.Advisor {
+	instructorID := s.advisors[studentID]
+	return &model.Advisor{
+		StudentID:    studentID,
+		InstructorID: instructorID,
+		Student:      publicStudent(s.students[studentID]),
+		Instructor:   publicInstructor(s.instructors[instructorID]),
+	}
+}
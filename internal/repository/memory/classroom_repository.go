@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// ClassroomRepository 教室仓储的内存实现
+type ClassroomRepository struct {
+	store *Store
+}
+
+// NewClassroomRepository 创建教室仓储实例
+func NewClassroomRepository(store *Store) repository.ClassroomRepository {
+	return &ClassroomRepository{store: store}
+}
+
+// FindByID 根据教学楼和教室号查找教室
+func (r *ClassroomRepository) FindByID(ctx context.Context, building, roomNumber string) (*model.Classroom, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	classroom, ok := r.store.classrooms[classroomKey{building, roomNumber}]
+	if !ok {
+		return nil, fmt.Errorf("classroom not found: %w", repository.ErrNotFound)
+	}
+	c := *classroom
+	return &c, nil
+}
+
+// FindByBuildingAndRoom 根据建筑和房间号查找教室
+func (r *ClassroomRepository) FindByBuildingAndRoom(ctx context.Context, building string, roomNumber string) (*model.Classroom, error) {
+	return r.FindByID(ctx, building, roomNumber)
+}
+
+// FindAll 查找所有教室
+func (r *ClassroomRepository) FindAll(ctx context.Context) ([]*model.Classroom, error) {
+	return r.find(func(*model.Classroom) bool { return true }), nil
+}
+
+// classroomListSpec 教室列表的字段取值
+var classroomListSpec = listSpec[model.Classroom]{
+	fields: model.ClassroomListFields,
+	values: map[string]func(*model.Classroom) interface{}{
+		"building":    func(c *model.Classroom) interface{} { return c.Building },
+		"room_number": func(c *model.Classroom) interface{} { return c.RoomNumber },
+		"capacity":    func(c *model.Classroom) interface{} { return c.Capacity },
+	},
+}
+
+// List 按分页、排序和过滤条件查找教室
+func (r *ClassroomRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Classroom], error) {
+	return list(r.find(func(*model.Classroom) bool { return true }), classroomListSpec, q)
+}
+
+// FindByBuilding 根据教学楼查找教室
+func (r *ClassroomRepository) FindByBuilding(ctx context.Context, building string) ([]*model.Classroom, error) {
+	return r.find(func(classroom *model.Classroom) bool { return classroom.Building == building }), nil
+}
+
+// FindAvailable 查找容量满足要求且在指定学期和时间段没有被课程段占用的教室，按容量从小到大排序
+func (r *ClassroomRepository) FindAvailable(ctx context.Context, capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error) {
+	r.store.mu.RLock()
+	occupied := make(map[classroomKey]bool)
+	for key, section := range r.store.sections {
+		if key.Semester == semester && key.Year == year && section.TimeSlotID == timeSlotID {
+			occupied[classroomKey{section.Building, section.RoomNumber}] = true
+		}
+	}
+	r.store.mu.RUnlock()
+
+	classrooms := r.find(func(classroom *model.Classroom) bool {
+		return classroom.Capacity >= capacity && !occupied[classroomKey{classroom.Building, classroom.RoomNumber}]
+	})
+	sort.SliceStable(classrooms, func(i, j int) bool { return classrooms[i].Capacity < classrooms[j].Capacity })
+	return classrooms, nil
+}
+
+func (r *ClassroomRepository) find(match func(*model.Classroom) bool) []*model.Classroom {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var classrooms []*model.Classroom
+	for _, key := range sortedKeys(r.store.classrooms, lessClassroomKey) {
+		if classroom := r.store.classrooms[key]; match(classroom) {
+			c := *classroom
+			classrooms = append(classrooms, &c)
+		}
+	}
+	return classrooms
+}
+
+// Create 创建教室
+func (r *ClassroomRepository) Create(ctx context.Context, classroom *model.Classroom) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := classroomKey{classroom.Building, classroom.RoomNumber}
+	if _, ok := r.store.classrooms[key]; ok {
+		return fmt.Errorf("classroom already exists: %w", repository.ErrDuplicate)
+	}
+
+	c := *classroom
+	r.store.classrooms[key] = &c
+	return nil
+}
+
+// Update 更新教室容量
+func (r *ClassroomRepository) Update(ctx context.Context, classroom *model.Classroom) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.classrooms[classroomKey{classroom.Building, classroom.RoomNumber}]
+	if !ok {
+		return fmt.Errorf("classroom not found: %w", repository.ErrNotFound)
+	}
+
+	existing.Capacity = classroom.Capacity
+	return nil
+}
+
+// Delete 删除教室，被课程段使用时拒绝删除
+func (r *ClassroomRepository) Delete(ctx context.Context, building, roomNumber string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	count := 0
+	for _, section := range r.store.sections {
+		if section.Building == building && section.RoomNumber == roomNumber {
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("cannot delete classroom: it is being used by %d sections", count)
+	}
+
+	key := classroomKey{building, roomNumber}
+	if _, ok := r.store.classrooms[key]; !ok {
+		return fmt.Errorf("classroom not found: %w", repository.ErrNotFound)
+	}
+
+	delete(r.store.classrooms, key)
+	return nil
+}
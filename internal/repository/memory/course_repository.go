@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// CourseRepository 课程仓储的内存实现
+type CourseRepository struct {
+	store *Store
+}
+
+// NewCourseRepository 创建课程仓储实例
+func NewCourseRepository(store *Store) repository.CourseRepository {
+	return &CourseRepository{store: store}
+}
+
+// FindByID 根据ID查找课程
+func (r *CourseRepository) FindByID(ctx context.Context, id string) (*model.Course, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	course, ok := r.store.courses[id]
+	if !ok {
+		return nil, fmt.Errorf("course not found: %w", repository.ErrNotFound)
+	}
+	return copyCourse(course), nil
+}
+
+// FindAll 按ID顺序查找所有课程
+func (r *CourseRepository) FindAll(ctx context.Context) ([]*model.Course, error) {
+	return r.find(func(*model.Course) bool { return true }), nil
+}
+
+// courseListSpec 课程列表的字段取值
+var courseListSpec = listSpec[model.Course]{
+	fields: model.CourseListFields,
+	values: map[string]func(*model.Course) interface{}{
+		"id":      func(c *model.Course) interface{} { return c.ID },
+		"title":   func(c *model.Course) interface{} { return c.Title },
+		"dept":    func(c *model.Course) interface{} { return c.Dept },
+		"credits": func(c *model.Course) interface{} { return c.Credits },
+	},
+}
+
+// List 按分页、排序和过滤条件查找课程
+func (r *CourseRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Course], error) {
+	return list(r.find(func(*model.Course) bool { return true }), courseListSpec, q)
+}
+
+// FindByDept 根据院系查找课程
+func (r *CourseRepository) FindByDept(ctx context.Context, dept string) ([]*model.Course, error) {
+	return r.find(func(course *model.Course) bool { return course.Dept == dept }), nil
+}
+
+func (r *CourseRepository) find(match func(*model.Course) bool) []*model.Course {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var courses []*model.Course
+	for _, id := range sortedKeys(r.store.courses, lessString) {
+		if course := r.store.courses[id]; match(course) {
+			courses = append(courses, copyCourse(course))
+		}
+	}
+	return courses
+}
+
+// Create 创建课程
+func (r *CourseRepository) Create(ctx context.Context, course *model.Course) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.courses[course.ID]; ok {
+		return fmt.Errorf("course already exists: %w", repository.ErrDuplicate)
+	}
+	if err := r.store.requireDept(course.Dept); err != nil {
+		return fmt.Errorf("error creating course: %w", err)
+	}
+
+	r.store.courses[course.ID] = copyCourse(course)
+	return nil
+}
+
+// Update 更新课程
+func (r *CourseRepository) Update(ctx context.Context, course *model.Course) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.courses[course.ID]
+	if !ok {
+		return fmt.Errorf("course not found: %w", repository.ErrNotFound)
+	}
+	if err := r.store.requireDept(course.Dept); err != nil {
+		return fmt.Errorf("error updating course: %w", err)
+	}
+
+	existing.Title = course.Title
+	existing.Dept = course.Dept
+	existing.Credits = course.Credits
+	return nil
+}
+
+// Delete 删除课程，仍有课程段或先修关系时拒绝删除
+func (r *CourseRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.courses[id]; !ok {
+		return fmt.Errorf("course not found: %w", repository.ErrNotFound)
+	}
+	for key := range r.store.sections {
+		if key.CourseID == id {
+			return fmt.Errorf("cannot delete course: it has sections")
+		}
+	}
+	for key := range r.store.prereqs {
+		if key.CourseID == id || key.PrereqID == id {
+			return fmt.Errorf("cannot delete course: it is used by prerequisites")
+		}
+	}
+	if _, ok := r.store.prereqRules[id]; ok {
+		return fmt.Errorf("cannot delete course: it has a prerequisite rule")
+	}
+
+	delete(r.store.courses, id)
+	return nil
+}
+
+// FindWithPrereqs 查找课程及其先修课程
+func (r *CourseRepository) FindWithPrereqs(ctx context.Context, id string) (*model.CourseWithPrereqs, error) {
+	course, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var prereqs []model.Course
+	for _, key := range sortedKeys(r.store.prereqs, lessPrereqKey) {
+		if key.CourseID == id {
+			prereqs = append(prereqs, *copyCourse(r.store.courses[key.PrereqID]))
+		}
+	}
+
+	return &model.CourseWithPrereqs{
+		Course:  *course,
+		Prereqs: prereqs,
+	}, nil
+}
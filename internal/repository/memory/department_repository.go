@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// DepartmentRepository 院系仓储的内存实现
+type DepartmentRepository struct {
+	store *Store
+}
+
+// NewDepartmentRepository 创建院系仓储实例
+func NewDepartmentRepository(store *Store) repository.DepartmentRepository {
+	return &DepartmentRepository{store: store}
+}
+
+// FindByID 根据院系名称查找院系
+func (r *DepartmentRepository) FindByID(ctx context.Context, deptName string) (*model.Department, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	department, ok := r.store.departments[deptName]
+	if !ok {
+		return nil, fmt.Errorf("department not found: %w", repository.ErrNotFound)
+	}
+	c := *department
+	return &c, nil
+}
+
+// FindByDepartment 根据院系名称查找院系
+func (r *DepartmentRepository) FindByDepartment(ctx context.Context, deptName string) (*model.Department, error) {
+	return r.FindByID(ctx, deptName)
+}
+
+// FindAll 按名称顺序查找所有院系
+func (r *DepartmentRepository) FindAll(ctx context.Context) ([]*model.Department, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var departments []*model.Department
+	for _, name := range sortedKeys(r.store.departments, lessString) {
+		c := *r.store.departments[name]
+		departments = append(departments, &c)
+	}
+	return departments, nil
+}
+
+// departmentListSpec 院系列表的字段取值
+var departmentListSpec = listSpec[model.Department]{
+	fields: model.DepartmentListFields,
+	values: map[string]func(*model.Department) interface{}{
+		"dept_name": func(d *model.Department) interface{} { return d.DeptName },
+		"building":  func(d *model.Department) interface{} { return d.Building },
+		"budget":    func(d *model.Department) interface{} { return d.Budget },
+	},
+}
+
+// List 按分页、排序和过滤条件查找院系
+func (r *DepartmentRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Department], error) {
+	departments, _ := r.FindAll(ctx)
+	return list(departments, departmentListSpec, q)
+}
+
+// Create 创建院系
+func (r *DepartmentRepository) Create(ctx context.Context, department *model.Department) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.departments[department.DeptName]; ok {
+		return fmt.Errorf("department already exists: %w", repository.ErrDuplicate)
+	}
+
+	c := *department
+	r.store.departments[department.DeptName] = &c
+	return nil
+}
+
+// Update 更新院系
+func (r *DepartmentRepository) Update(ctx context.Context, department *model.Department) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.departments[department.DeptName]; !ok {
+		return fmt.Errorf("department not found: %w", repository.ErrNotFound)
+	}
+
+	c := *department
+	r.store.departments[department.DeptName] = &c
+	return nil
+}
+
+// Delete 删除院系，仍有学生、教师或课程时拒绝删除
+func (r *DepartmentRepository) Delete(ctx context.Context, deptName string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stats := r.store.departmentStats(deptName)
+	if stats.StudentCount > 0 {
+		return fmt.Errorf("cannot delete department: it has %d associated students", stats.StudentCount)
+	}
+	if stats.InstructorCount > 0 {
+		return fmt.Errorf("cannot delete department: it has %d associated instructors", stats.InstructorCount)
+	}
+	if stats.CourseCount > 0 {
+		return fmt.Errorf("cannot delete department: it has %d associated courses", stats.CourseCount)
+	}
+
+	if _, ok := r.store.departments[deptName]; !ok {
+		return fmt.Errorf("department not found: %w", repository.ErrNotFound)
+	}
+
+	delete(r.store.departments, deptName)
+	return nil
+}
+
+// GetStudentCount 获取院系学生数量
+func (r *DepartmentRepository) GetStudentCount(ctx context.Context, deptName string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.departmentStats(deptName).StudentCount, nil
+}
+
+// GetInstructorCount 获取院系教师数量
+func (r *DepartmentRepository) GetInstructorCount(ctx context.Context, deptName string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.departmentStats(deptName).InstructorCount, nil
+}
+
+// GetCourseCount 获取院系课程数量
+func (r *DepartmentRepository) GetCourseCount(ctx context.Context, deptName string) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.departmentStats(deptName).CourseCount, nil
+}
+
+// GetDepartmentStats 按名称顺序获取所有院系统计信息
+func (r *DepartmentRepository) GetDepartmentStats(ctx context.Context) ([]*model.DepartmentStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var stats []*model.DepartmentStats
+	for _, name := range sortedKeys(r.store.departments, lessString) {
+		stats = append(stats, r.store.departmentStats(name))
+	}
+	return stats, nil
+}
+
+// departmentStats 统计院系的学生、教师和课程数量，调用方需持有锁
+func (s *Store) departmentStats(deptName string) *model.DepartmentStats {
+	stats := &model.DepartmentStats{}
+	if department, ok := s.departments[deptName]; ok {
+		stats.Department = *department
+	}
+	for _, student := range s.students {
+		if student.Dept == deptName {
+			stats.StudentCount++
+		}
+	}
+	for _, instructor := range s.instructors {
+		if instructor.Dept == deptName {
+			stats.InstructorCount++
+		}
+	}
+	for _, course := range s.courses {
+		if course.Dept == deptName {
+			stats.CourseCount++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// InstructorRepository 教师仓储的内存实现
+type InstructorRepository struct {
+	store *Store
+}
+
+// NewInstructorRepository 创建教师仓储实例
+func NewInstructorRepository(store *Store) repository.InstructorRepository {
+	return &InstructorRepository{store: store}
+}
+
+// GetByID 根据ID查找教师
+func (r *InstructorRepository) GetByID(ctx context.Context, id string) (*model.Instructor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	instructor, ok := r.store.instructors[id]
+	if !ok {
+		return nil, fmt.Errorf("instructor not found: %w", repository.ErrNotFound)
+	}
+	return copyInstructor(instructor), nil
+}
+
+// instructorListSpec 教师列表的字段取值
+var instructorListSpec = listSpec[model.Instructor]{
+	fields: model.InstructorListFields,
+	values: map[string]func(*model.Instructor) interface{}{
+		"id":     func(i *model.Instructor) interface{} { return i.ID },
+		"name":   func(i *model.Instructor) interface{} { return i.Name },
+		"dept":   func(i *model.Instructor) interface{} { return i.Dept },
+		"salary": func(i *model.Instructor) interface{} { return i.Salary },
+	},
+}
+
+// List 按分页、排序和过滤条件查找教师，不返回密码
+func (r *InstructorRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Instructor], error) {
+	r.store.mu.RLock()
+	instructors := make([]*model.Instructor, 0, len(r.store.instructors))
+	for _, instructor := range r.store.instructors {
+		instructors = append(instructors, publicInstructor(instructor))
+	}
+	r.store.mu.RUnlock()
+
+	return list(instructors, instructorListSpec, q)
+}
+
+// Create 创建教师
+func (r *InstructorRepository) Create(ctx context.Context, instructor *model.Instructor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.instructors[instructor.ID]; ok {
+		return fmt.Errorf("instructor already exists: %w", repository.ErrDuplicate)
+	}
+	if err := r.store.requireDept(instructor.Dept); err != nil {
+		return fmt.Errorf("error creating instructor: %w", err)
+	}
+
+	r.store.instructors[instructor.ID] = copyInstructor(instructor)
+	return nil
+}
+
+// Update 更新教师的姓名、院系和薪水
+func (r *InstructorRepository) Update(ctx context.Context, instructor *model.Instructor) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.instructors[instructor.ID]
+	if !ok {
+		return fmt.Errorf("instructor not found: %w", repository.ErrNotFound)
+	}
+	if err := r.store.requireDept(instructor.Dept); err != nil {
+		return fmt.Errorf("error updating instructor: %w", err)
+	}
+
+	existing.Name = instructor.Name
+	existing.Dept = instructor.Dept
+	existing.Salary = instructor.Salary
+	return nil
+}
+
+// Delete 删除教师，仍有授课记录或指导学生时拒绝删除
+func (r *InstructorRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.instructors[id]; !ok {
+		return fmt.Errorf("instructor not found: %w", repository.ErrNotFound)
+	}
+	for key := range r.store.teaches {
+		if key.PersonID == id {
+			return fmt.Errorf("cannot delete instructor: it has teaches records")
+		}
+	}
+	for _, instructorID := range r.store.advisors {
+		if instructorID == id {
+			return fmt.Errorf("cannot delete instructor: it advises students")
+		}
+	}
+
+	delete(r.store.instructors, id)
+	return nil
+}
+
+// ExistsByID 检查指定ID的教师是否存在
+func (r *InstructorRepository) ExistsByID(ctx context.Context, id string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.instructors[id]
+	return ok, nil
+}
+
+// Search 按ID、姓名或院系模糊搜索教师
+func (r *InstructorRepository) Search(ctx context.Context, query string) ([]*model.Instructor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var instructors []*model.Instructor
+	for _, id := range sortedKeys(r.store.instructors, lessString) {
+		instructor := r.store.instructors[id]
+		if containsFold(instructor.ID, query) || containsFold(instructor.Name, query) || containsFold(instructor.Dept, query) {
+			instructors = append(instructors, publicInstructor(instructor))
+		}
+	}
+	return instructors, nil
+}
+
+// UpdatePassword 更新教师密码
+func (r *InstructorRepository) UpdatePassword(ctx context.Context, id, hashedPassword, salt string, mustChange bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	instructor, ok := r.store.instructors[id]
+	if !ok {
+		return fmt.Errorf("instructor not found: %w", repository.ErrNotFound)
+	}
+
+	instructor.Password = hashedPassword
+	instructor.Salt = salt
+	instructor.MustChangePassword = mustChange
+	return nil
+}
+
+// publicInstructor 返回不含密码信息的副本，与SQL实现的列表查询一致
+func publicInstructor(instructor *model.Instructor) *model.Instructor {
+	return &model.Instructor{ID: instructor.ID, Name: instructor.Name, Dept: instructor.Dept, Salary: instructor.Salary}
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// listSpec 描述如何在内存中响应一类资源的通用列表查询
+type listSpec[T any] struct {
+	fields model.ListFields
+	values map[string]func(*T) interface{}  // 排序和过滤字段的取值
+	match  map[string]func(*T, string) bool // 不能直接比较取值的过滤字段
+}
+
+// list 按查询条件过滤、排序并取出一页，与SQL实现一样过滤取等值、排序最后带上主键
+func list[T any](items []*T, spec listSpec[T], q *model.ListQuery) (*model.ListResult[T], error) {
+	if err := q.Normalize(spec.fields); err != nil {
+		return nil, err
+	}
+
+	var matched []*T
+	for _, item := range items {
+		if spec.matches(item, q.Filters) {
+			matched = append(matched, item)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, s := range q.Sort {
+			value := spec.values[s.Field]
+			if c := compareValues(value(matched[i]), value(matched[j])); c != 0 {
+				return (c < 0) != s.Desc
+			}
+		}
+		return false
+	})
+
+	page := matched
+	if q.Offset() >= len(page) {
+		page = nil
+	} else {
+		page = page[q.Offset():]
+		if len(page) > q.PageSize {
+			page = page[:q.PageSize]
+		}
+	}
+	return model.NewListResult(q, page, int64(len(matched))), nil
+}
+
+func (spec listSpec[T]) matches(item *T, filters map[string]string) bool {
+	for field, want := range filters {
+		if match, ok := spec.match[field]; ok {
+			if !match(item, want) {
+				return false
+			}
+			continue
+		}
+		if fmt.Sprint(spec.values[field](item)) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// compareValues 比较两个同类型的字段值，nil排在最前
+func compareValues(a, b interface{}) int {
+	if t, ok := a.(*time.Time); ok {
+		a = derefTime(t)
+	}
+	if t, ok := b.(*time.Time); ok {
+		b = derefTime(t)
+	}
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return compareOrdered(a, b.(string))
+	case int:
+		return compareOrdered(a, b.(int))
+	case int64:
+		return compareOrdered(a, b.(int64))
+	case float64:
+		return compareOrdered(a, b.(float64))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	panic(fmt.Sprintf("memory: cannot compare %T", a))
+}
+
+func derefTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func compareOrdered[T string | int | int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/yourusername/student-management-system/internal/repository/memory"
+	"github.com/yourusername/student-management-system/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) *repotest.Stores {
+		store := memory.NewStore()
+		return &repotest.Stores{
+			Students:    memory.NewStudentRepository(store),
+			Instructors: memory.NewInstructorRepository(store),
+			Departments: memory.NewDepartmentRepository(store),
+			Courses:     memory.NewCourseRepository(store),
+			Classrooms:  memory.NewClassroomRepository(store),
+			TimeSlots:   memory.NewTimeSlotRepository(store),
+			Sections:    memory.NewSectionRepository(store),
+			Takes:       memory.NewTakesRepository(store),
+			Teaches:     memory.NewTeachesRepository(store),
+			Advisors:    memory.NewAdvisorRepository(store),
+			Prereqs:     memory.NewPrereqRepository(store),
+		}
+	})
+}
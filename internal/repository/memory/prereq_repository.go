@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// PrereqRepository 先修课程仓储的内存实现
+type PrereqRepository struct {
+	store *Store
+}
+
+// NewPrereqRepository 创建先修课程仓储实例
+func NewPrereqRepository(store *Store) repository.PrereqRepository {
+	return &PrereqRepository{store: store}
+}
+
+// FindByID 根据课程ID和先修课程ID查找先修关系
+func (r *PrereqRepository) FindByID(ctx context.Context, courseID, prereqID string) (*model.Prereq, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if !r.store.prereqs[prereqKey{courseID, prereqID}] {
+		return nil, fmt.Errorf("prerequisite relationship not found: %w", repository.ErrNotFound)
+	}
+	return &model.Prereq{CourseID: courseID, PrereqID: prereqID}, nil
+}
+
+// FindAll 查找所有先修关系
+func (r *PrereqRepository) FindAll(ctx context.Context) ([]*model.Prereq, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var prereqs []*model.Prereq
+	for _, key := range sortedKeys(r.store.prereqs, lessPrereqKey) {
+		prereqs = append(prereqs, &model.Prereq{CourseID: key.CourseID, PrereqID: key.PrereqID})
+	}
+	return prereqs, nil
+}
+
+// prereqListSpec 先修关系列表的字段取值
+var prereqListSpec = listSpec[model.Prereq]{
+	fields: model.PrereqListFields,
+	values: map[string]func(*model.Prereq) interface{}{
+		"course_id": func(p *model.Prereq) interface{} { return p.CourseID },
+		"prereq_id": func(p *model.Prereq) interface{} { return p.PrereqID },
+	},
+}
+
+// List 按分页、排序和过滤条件查找先修关系，附带先修课程信息
+func (r *PrereqRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Prereq], error) {
+	r.store.mu.RLock()
+	prereqs := make([]*model.Prereq, 0, len(r.store.prereqs))
+	for key := range r.store.prereqs {
+		prereqs = append(prereqs, &model.Prereq{
+			CourseID:   key.CourseID,
+			PrereqID:   key.PrereqID,
+			PrereqInfo: copyCourse(r.store.courses[key.PrereqID]),
+		})
+	}
+	r.store.mu.RUnlock()
+
+	return list(prereqs, prereqListSpec, q)
+}
+
+// FindByCourseID 根据课程ID查找先修关系，附带先修课程信息
+func (r *PrereqRepository) FindByCourseID(ctx context.Context, courseID string) ([]*model.Prereq, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var prereqs []*model.Prereq
+	for _, key := range sortedKeys(r.store.prereqs, lessPrereqKey) {
+		if key.CourseID == courseID {
+			prereqs = append(prereqs, &model.Prereq{
+				CourseID:   key.CourseID,
+				PrereqID:   key.PrereqID,
+				PrereqInfo: copyCourse(r.store.courses[key.PrereqID]),
+			})
+		}
+	}
+	return prereqs, nil
+}
+
+// GetPrereqIDs 获取课程的先修课程ID
+func (r *PrereqRepository) GetPrereqIDs(ctx context.Context, courseID string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.prereqIDs(courseID), nil
+}
+
+// HasPrerequisite 检查是否存在先修关系
+func (r *PrereqRepository) HasPrerequisite(ctx context.Context, courseID string, prereqID string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.prereqs[prereqKey{courseID, prereqID}], nil
+}
+
+// Create 创建先修关系
+func (r *PrereqRepository) Create(ctx context.Context, prereq *model.Prereq) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := prereqKey{prereq.CourseID, prereq.PrereqID}
+	if r.store.prereqs[key] {
+		return fmt.Errorf("prereq relationship already exists: %w", repository.ErrDuplicate)
+	}
+	for _, id := range []string{prereq.CourseID, prereq.PrereqID} {
+		if _, ok := r.store.courses[id]; !ok {
+			return fmt.Errorf("error creating prereq: course %s: %w", id, repository.ErrNotFound)
+		}
+	}
+
+	r.store.prereqs[key] = true
+	return nil
+}
+
+// Delete 删除先修关系
+func (r *PrereqRepository) Delete(ctx context.Context, courseID, prereqID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := prereqKey{courseID, prereqID}
+	if !r.store.prereqs[key] {
+		return fmt.Errorf("prereq relationship not found: %w", repository.ErrNotFound)
+	}
+
+	delete(r.store.prereqs, key)
+	return nil
+}
+
+// GetRule 获取课程保存的先修规则树
+func (r *PrereqRepository) GetRule(ctx context.Context, courseID string) (*model.PrereqRule, error) {
+	r.store.mu.RLock()
+	data, ok := r.store.prereqRules[courseID]
+	r.store.mu.RUnlock()
+
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	var rule model.PrereqRule
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return nil, fmt.Errorf("error decoding prereq rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// GetEffectiveRule 获取课程生效的先修规则：优先使用规则树，没有规则树时由先修关系生成，都没有时返回nil
+func (r *PrereqRepository) GetEffectiveRule(ctx context.Context, courseID string) (*model.PrereqRule, error) {
+	rule, err := r.GetRule(ctx, courseID)
+	if err == nil {
+		return rule, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	prereqIDs, err := r.GetPrereqIDs(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return model.LegacyPrereqRule(prereqIDs), nil
+}
+
+// SaveRule 保存课程的先修规则树，已存在时覆盖
+func (r *PrereqRepository) SaveRule(ctx context.Context, courseID string, rule *model.PrereqRule) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("error encoding prereq rule: %w", err)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.courses[courseID]; !ok {
+		return fmt.Errorf("error saving prereq rule: course %s: %w", courseID, repository.ErrNotFound)
+	}
+
+	r.store.prereqRules[courseID] = data
+	return nil
+}
+
+// DeleteRule 删除课程的先修规则树，之后回退到先修关系
+func (r *PrereqRepository) DeleteRule(ctx context.Context, courseID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.prereqRules[courseID]; !ok {
+		return repository.ErrNotFound
+	}
+
+	delete(r.store.prereqRules, courseID)
+	return nil
+}
+
+// CheckPrereqsSatisfied 检查学生是否满足课程的先修规则，不考虑同学期同时选修的课程
+func (r *PrereqRepository) CheckPrereqsSatisfied(ctx context.Context, studentID, courseID string) (bool, error) {
+	result, err := r.EvaluatePrereqs(ctx, studentID, courseID, "", 0)
+	if err != nil {
+		return false, err
+	}
+	return result.Satisfied, nil
+}
+
+// EvaluatePrereqs 评估学生选修某课程时的先修规则，semester和year用于判断同时选修（coreq）的课程
+func (r *PrereqRepository) EvaluatePrereqs(ctx context.Context, studentID string, courseID string, semester string, year int) (*model.PrereqCheckResult, error) {
+	rule, err := r.GetEffectiveRule(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 没有先修要求，则满足条件
+	if rule == nil {
+		return &model.PrereqCheckResult{Satisfied: true}, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	student, ok := r.store.students[studentID]
+	if !ok {
+		return nil, fmt.Errorf("student not found: %w", repository.ErrNotFound)
+	}
+	record := model.NewAcademicRecord(student.TotCred)
+	for member, takes := range r.store.takes {
+		if member.PersonID == studentID {
+			record.AddTakes(takes.CourseID, takes.Semester, takes.Year, takes.Grade, semester, year)
+		}
+	}
+
+	return rule.Check(record), nil
+}
+
+// prereqIDs 返回课程的先修课程ID，调用方需持有锁
+func (s *Store) prereqIDs(courseID string) []string {
+	var ids []string
+	for _, key := range sortedKeys(s.prereqs, lessPrereqKey) {
+		if key.CourseID == courseID {
+			ids = append(ids, key.PrereqID)
+		}
+	}
+	return ids
+}
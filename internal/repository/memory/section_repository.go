@@ -0,0 +1,259 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// SectionRepository 课程段仓储的内存实现
+type SectionRepository struct {
+	store *Store
+}
+
+// NewSectionRepository 创建课程段仓储实例
+func NewSectionRepository(store *Store) repository.SectionRepository {
+	return &SectionRepository{store: store}
+}
+
+// FindByID 根据完整主键查找课程段
+func (r *SectionRepository) FindByID(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	section, ok := r.store.sections[key]
+	if !ok {
+		return nil, fmt.Errorf("section not found: %w", repository.ErrNotFound)
+	}
+	return copySection(section), nil
+}
+
+// FindByIDForUpdate 根据完整主键查找课程段，内存实现不需要行锁
+func (r *SectionRepository) FindByIDForUpdate(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	return r.FindByID(ctx, key)
+}
+
+// FindBySecID 根据sec_id查找所有课程段，按年份倒序、学期和课程ID排序
+func (r *SectionRepository) FindBySecID(ctx context.Context, secID string) ([]*model.Section, error) {
+	sections := r.find(func(section *model.Section) bool { return section.ID == secID })
+	sort.SliceStable(sections, func(i, j int) bool {
+		a, b := sections[i], sections[j]
+		if a.Year != b.Year {
+			return a.Year > b.Year
+		}
+		if a.Semester != b.Semester {
+			return a.Semester < b.Semester
+		}
+		return a.CourseID < b.CourseID
+	})
+	return sections, nil
+}
+
+// FindAll 查找所有课程段
+func (r *SectionRepository) FindAll(ctx context.Context) ([]*model.Section, error) {
+	return r.find(func(*model.Section) bool { return true }), nil
+}
+
+// List 按分页、排序和过滤条件查找课程段，dept按课程所属院系过滤，instructor_id按授课教师过滤
+func (r *SectionRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Section], error) {
+	r.store.mu.RLock()
+	courseDept := make(map[string]string, len(r.store.courses))
+	for id, course := range r.store.courses {
+		courseDept[id] = course.Dept
+	}
+	taught := make(map[memberKey]bool, len(r.store.teaches))
+	for member := range r.store.teaches {
+		taught[member] = true
+	}
+	r.store.mu.RUnlock()
+
+	spec := listSpec[model.Section]{
+		fields: model.SectionListFields,
+		values: map[string]func(*model.Section) interface{}{
+			"course_id": func(s *model.Section) interface{} { return s.CourseID },
+			"id":        func(s *model.Section) interface{} { return s.ID },
+			"semester":  func(s *model.Section) interface{} { return s.Semester },
+			"year":      func(s *model.Section) interface{} { return s.Year },
+			"building":  func(s *model.Section) interface{} { return s.Building },
+			"dept":      func(s *model.Section) interface{} { return courseDept[s.CourseID] },
+		},
+		match: map[string]func(*model.Section, string) bool{
+			"instructor_id": func(s *model.Section, id string) bool { return taught[memberKey{id, s.Key()}] },
+		},
+	}
+	return list(r.find(func(*model.Section) bool { return true }), spec, q)
+}
+
+// FindByCourseID 根据课程ID查找课程段
+func (r *SectionRepository) FindByCourseID(ctx context.Context, courseID string) ([]*model.Section, error) {
+	return r.find(func(section *model.Section) bool { return section.CourseID == courseID }), nil
+}
+
+// FindByParams 根据课程、学期、年份和开课院系查找课程段
+func (r *SectionRepository) FindByParams(ctx context.Context, params *model.SectionQueryParams) ([]*model.Section, error) {
+	r.store.mu.RLock()
+	courseDept := make(map[string]string, len(r.store.courses))
+	for id, course := range r.store.courses {
+		courseDept[id] = course.Dept
+	}
+	r.store.mu.RUnlock()
+
+	return r.find(func(section *model.Section) bool {
+		return (params.CourseID == "" || section.CourseID == params.CourseID) &&
+			(params.Semester == "" || section.Semester == params.Semester) &&
+			(params.Year == 0 || section.Year == params.Year) &&
+			(params.Dept == "" || courseDept[section.CourseID] == params.Dept)
+	}), nil
+}
+
+func (r *SectionRepository) find(match func(*model.Section) bool) []*model.Section {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var sections []*model.Section
+	for _, key := range sortedKeys(r.store.sections, lessSectionKey) {
+		if section := r.store.sections[key]; match(section) {
+			sections = append(sections, copySection(section))
+		}
+	}
+	return sections
+}
+
+// Create 创建课程段
+func (r *SectionRepository) Create(ctx context.Context, section *model.Section) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.sections[section.Key()]; ok {
+		return fmt.Errorf("section already exists: %w", repository.ErrDuplicate)
+	}
+	if err := r.store.checkSectionRefs(section); err != nil {
+		return fmt.Errorf("error creating section: %w", err)
+	}
+
+	r.store.sections[section.Key()] = copySection(section)
+	return nil
+}
+
+// Update 更新课程段的教室和时间段
+func (r *SectionRepository) Update(ctx context.Context, section *model.Section) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.sections[section.Key()]
+	if !ok {
+		return fmt.Errorf("section not found: %w", repository.ErrNotFound)
+	}
+	if err := r.store.checkSectionRefs(section); err != nil {
+		return fmt.Errorf("error updating section: %w", err)
+	}
+
+	existing.Building = section.Building
+	existing.RoomNumber = section.RoomNumber
+	existing.TimeSlotID = section.TimeSlotID
+	return nil
+}
+
+// Delete 删除课程段，仍有选课或授课记录时拒绝删除
+func (r *SectionRepository) Delete(ctx context.Context, key model.SectionKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.sections[key]; !ok {
+		return fmt.Errorf("section not found: %w", repository.ErrNotFound)
+	}
+	for member := range r.store.takes {
+		if member.Section == key {
+			return fmt.Errorf("cannot delete section: it has takes records")
+		}
+	}
+	for member := range r.store.teaches {
+		if member.Section == key {
+			return fmt.Errorf("cannot delete section: it has teaches records")
+		}
+	}
+
+	delete(r.store.sections, key)
+	return nil
+}
+
+// GetEnrollmentCount 统计课程段的选课人数，退选（W）的记录不计入
+func (r *SectionRepository) GetEnrollmentCount(ctx context.Context, key model.SectionKey) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.enrollmentCount(key), nil
+}
+
+// FindWithDetails 查找课程段及其课程、教室、时间段和授课教师
+func (r *SectionRepository) FindWithDetails(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stored, ok := r.store.sections[key]
+	if !ok {
+		return nil, fmt.Errorf("section not found: %w", repository.ErrNotFound)
+	}
+	section := copySection(stored)
+
+	section.Course = copyCourse(r.store.courses[section.CourseID])
+	if classroom, ok := r.store.classrooms[classroomKey{section.Building, section.RoomNumber}]; ok {
+		c := *classroom
+		section.Classroom = &c
+	}
+	if timeSlot, ok := r.store.timeSlots[section.TimeSlotID]; ok {
+		section.TimeSlot = copyTimeSlot(timeSlot)
+	}
+
+	var instructors []model.Instructor
+	for _, member := range sortedKeys(r.store.teaches, lessMemberKey) {
+		if member.Section == key {
+			instructors = append(instructors, *publicInstructor(r.store.instructors[member.PersonID]))
+		}
+	}
+	section.Instructors = instructors
+
+	return section, nil
+}
+
+// GetSectionClassroom 获取课程段的教室信息
+func (r *SectionRepository) GetSectionClassroom(ctx context.Context, key model.SectionKey) (*model.Classroom, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if section, ok := r.store.sections[key]; ok {
+		if classroom, ok := r.store.classrooms[classroomKey{section.Building, section.RoomNumber}]; ok {
+			c := *classroom
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("classroom not found for section: %w", repository.ErrNotFound)
+}
+
+// checkSectionRefs 检查课程段引用的课程、教室和时间段是否存在，调用方需持有锁
+func (s *Store) checkSectionRefs(section *model.Section) error {
+	if _, ok := s.courses[section.CourseID]; !ok {
+		return fmt.Errorf("course %s: %w", section.CourseID, repository.ErrNotFound)
+	}
+	if _, ok := s.classrooms[classroomKey{section.Building, section.RoomNumber}]; !ok {
+		return fmt.Errorf("classroom %s %s: %w", section.Building, section.RoomNumber, repository.ErrNotFound)
+	}
+	if _, ok := s.timeSlots[section.TimeSlotID]; !ok {
+		return fmt.Errorf("time slot %s: %w", section.TimeSlotID, repository.ErrNotFound)
+	}
+	return nil
+}
+
+// enrollmentCount 统计课程段未退选的选课记录数，调用方需持有锁
+func (s *Store) enrollmentCount(key model.SectionKey) int {
+	count := 0
+	for member, takes := range s.takes {
+		if member.Section == key && takes.Grade != model.GradeWithdrawn {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,159 @@
+// Package memory 提供仓储接口的内存实现，用于不依赖数据库的服务和处理器测试。
+// 同一个Store创建的仓储共享数据，并像数据库的主键和外键约束一样返回
+// repository.ErrDuplicate和repository.ErrNotFound。
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// classroomKey 教室主键
+type classroomKey struct {
+	Building   string
+	RoomNumber string
+}
+
+// memberKey 选课和授课记录的主键：学生或教师ID加课程段
+type memberKey struct {
+	PersonID string
+	Section  model.SectionKey
+}
+
+// prereqKey 先修关系主键
+type prereqKey struct {
+	CourseID string
+	PrereqID string
+}
+
+// Store 保存全部内存表
+type Store struct {
+	mu sync.RWMutex
+
+	departments map[string]*model.Department
+	students    map[string]*model.Student
+	instructors map[string]*model.Instructor
+	courses     map[string]*model.Course
+	classrooms  map[classroomKey]*model.Classroom
+	timeSlots   map[string]*model.TimeSlot
+	sections    map[model.SectionKey]*model.Section
+	takes       map[memberKey]*model.Takes
+	teaches     map[memberKey]*model.Teaches
+	advisors    map[string]string // 学生ID -> 导师ID
+	prereqs     map[prereqKey]bool
+	prereqRules map[string][]byte // 课程ID -> JSON编码的规则树
+}
+
+// NewStore 创建空的内存存储
+func NewStore() *Store {
+	return &Store{
+		departments: make(map[string]*model.Department),
+		students:    make(map[string]*model.Student),
+		instructors: make(map[string]*model.Instructor),
+		courses:     make(map[string]*model.Course),
+		classrooms:  make(map[classroomKey]*model.Classroom),
+		timeSlots:   make(map[string]*model.TimeSlot),
+		sections:    make(map[model.SectionKey]*model.Section),
+		takes:       make(map[memberKey]*model.Takes),
+		teaches:     make(map[memberKey]*model.Teaches),
+		advisors:    make(map[string]string),
+		prereqs:     make(map[prereqKey]bool),
+		prereqRules: make(map[string][]byte),
+	}
+}
+
+// requireDept 检查院系外键
+func (s *Store) requireDept(deptName string) error {
+	if _, ok := s.departments[deptName]; !ok {
+		return fmt.Errorf("department %s: %w", deptName, repository.ErrNotFound)
+	}
+	return nil
+}
+
+// requireSection 检查课程段外键
+func (s *Store) requireSection(key model.SectionKey) error {
+	if _, ok := s.sections[key]; !ok {
+		return fmt.Errorf("section %s: %w", key, repository.ErrNotFound)
+	}
+	return nil
+}
+
+// sortedKeys 返回按less排序的map键，保证查询结果的顺序稳定
+func sortedKeys[K comparable, V any](m map[K]V, less func(a, b K) bool) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+func lessString(a, b string) bool { return a < b }
+
+func lessSectionKey(a, b model.SectionKey) bool {
+	if a.CourseID != b.CourseID {
+		return a.CourseID < b.CourseID
+	}
+	if a.SecID != b.SecID {
+		return a.SecID < b.SecID
+	}
+	if a.Year != b.Year {
+		return a.Year < b.Year
+	}
+	return a.Semester < b.Semester
+}
+
+func lessMemberKey(a, b memberKey) bool {
+	if a.PersonID != b.PersonID {
+		return a.PersonID < b.PersonID
+	}
+	return lessSectionKey(a.Section, b.Section)
+}
+
+func lessClassroomKey(a, b classroomKey) bool {
+	if a.Building != b.Building {
+		return a.Building < b.Building
+	}
+	return a.RoomNumber < b.RoomNumber
+}
+
+func lessPrereqKey(a, b prereqKey) bool {
+	if a.CourseID != b.CourseID {
+		return a.CourseID < b.CourseID
+	}
+	return a.PrereqID < b.PrereqID
+}
+
+// 以下函数返回记录的副本，避免调用方修改存储中的数据
+
+func copyStudent(student *model.Student) *model.Student {
+	c := *student
+	return &c
+}
+
+func copyInstructor(instructor *model.Instructor) *model.Instructor {
+	c := *instructor
+	return &c
+}
+
+func copyCourse(course *model.Course) *model.Course {
+	c := *course
+	c.Prereqs = nil
+	return &c
+}
+
+func copyTimeSlot(timeSlot *model.TimeSlot) *model.TimeSlot {
+	c := *timeSlot
+	c.Days = append([]int{}, timeSlot.Days...)
+	return &c
+}
+
+func copySection(section *model.Section) *model.Section {
+	c := *section
+	c.Course, c.TimeSlot, c.Classroom, c.Instructors = nil, nil, nil, nil
+	return &c
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// StudentRepository 学生仓储的内存实现
+type StudentRepository struct {
+	store *Store
+}
+
+// NewStudentRepository 创建学生仓储实例
+func NewStudentRepository(store *Store) repository.StudentRepository {
+	return &StudentRepository{store: store}
+}
+
+// GetByID 根据ID查找学生
+func (r *StudentRepository) GetByID(ctx context.Context, id string) (*model.Student, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	student, ok := r.store.students[id]
+	if !ok {
+		return nil, fmt.Errorf("student not found: %w", repository.ErrNotFound)
+	}
+	return copyStudent(student), nil
+}
+
+// studentListSpec 学生列表的字段取值
+var studentListSpec = listSpec[model.Student]{
+	fields: model.StudentListFields,
+	values: map[string]func(*model.Student) interface{}{
+		"id":       func(s *model.Student) interface{} { return s.ID },
+		"name":     func(s *model.Student) interface{} { return s.Name },
+		"dept":     func(s *model.Student) interface{} { return s.Dept },
+		"tot_cred": func(s *model.Student) interface{} { return s.TotCred },
+	},
+}
+
+// List 按分页、排序和过滤条件查找学生，不返回密码
+func (r *StudentRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Student], error) {
+	r.store.mu.RLock()
+	students := make([]*model.Student, 0, len(r.store.students))
+	for _, student := range r.store.students {
+		students = append(students, publicStudent(student))
+	}
+	r.store.mu.RUnlock()
+
+	return list(students, studentListSpec, q)
+}
+
+// Create 创建学生
+func (r *StudentRepository) Create(ctx context.Context, student *model.Student) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.students[student.ID]; ok {
+		return fmt.Errorf("student already exists: %w", repository.ErrDuplicate)
+	}
+	if err := r.store.requireDept(student.Dept); err != nil {
+		return fmt.Errorf("error creating student: %w", err)
+	}
+
+	r.store.students[student.ID] = copyStudent(student)
+	return nil
+}
+
+// Update 更新学生的姓名、院系和学分
+func (r *StudentRepository) Update(ctx context.Context, student *model.Student) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.students[student.ID]
+	if !ok {
+		return fmt.Errorf("student not found: %w", repository.ErrNotFound)
+	}
+	if err := r.store.requireDept(student.Dept); err != nil {
+		return fmt.Errorf("error updating student: %w", err)
+	}
+
+	existing.Name = student.Name
+	existing.Dept = student.Dept
+	existing.TotCred = student.TotCred
+	return nil
+}
+
+// Delete 删除学生，仍有选课记录或导师关系时拒绝删除
+func (r *StudentRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.students[id]; !ok {
+		return fmt.Errorf("student not found: %w", repository.ErrNotFound)
+	}
+	for key := range r.store.takes {
+		if key.PersonID == id {
+			return fmt.Errorf("cannot delete student: it has takes records")
+		}
+	}
+	if _, ok := r.store.advisors[id]; ok {
+		return fmt.Errorf("cannot delete student: it has an advisor")
+	}
+
+	delete(r.store.students, id)
+	return nil
+}
+
+// ExistsByID 检查指定ID的学生是否存在
+func (r *StudentRepository) ExistsByID(ctx context.Context, id string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.students[id]
+	return ok, nil
+}
+
+// Search 按ID、姓名或院系模糊搜索学生
+func (r *StudentRepository) Search(ctx context.Context, query string) ([]*model.Student, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var students []*model.Student
+	for _, id := range sortedKeys(r.store.students, lessString) {
+		student := r.store.students[id]
+		if containsFold(student.ID, query) || containsFold(student.Name, query) || containsFold(student.Dept, query) {
+			students = append(students, publicStudent(student))
+		}
+	}
+	return students, nil
+}
+
+// UpdatePassword 更新学生密码
+func (r *StudentRepository) UpdatePassword(ctx context.Context, id, hashedPassword, salt string, mustChange bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	student, ok := r.store.students[id]
+	if !ok {
+		return fmt.Errorf("student not found: %w", repository.ErrNotFound)
+	}
+
+	student.Password = hashedPassword
+	student.Salt = salt
+	student.MustChangePassword = mustChange
+	return nil
+}
+
+// publicStudent 返回不含密码信息的副本，与SQL实现的列表查询一致
+func publicStudent(student *model.Student) *model.Student {
+	return &model.Student{ID: student.ID, Name: student.Name, Dept: student.Dept, TotCred: student.TotCred}
+}
+
+// containsFold 不区分大小写地判断s是否包含substr
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// TakesRepository 选课记录仓储的内存实现
+type TakesRepository struct {
+	store *Store
+}
+
+// NewTakesRepository 创建选课记录仓储实例
+func NewTakesRepository(store *Store) repository.TakesRepository {
+	return &TakesRepository{store: store}
+}
+
+// FindByStudentID 根据学生ID查找选课记录，附带课程和课程段信息
+func (r *TakesRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.Takes, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var takesList []*model.Takes
+	for _, member := range sortedKeys(r.store.takes, lessMemberKey) {
+		if member.PersonID == studentID {
+			takes := r.store.takesWithSection(member)
+			takesList = append(takesList, takes)
+		}
+	}
+	return takesList, nil
+}
+
+// FindByStudentAndSection 根据学生ID和课程段主键查找选课记录
+func (r *TakesRepository) FindByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.Takes, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	takes, ok := r.store.takes[memberKey{studentID, key}]
+	if !ok {
+		return nil, fmt.Errorf("takes record not found: %w", repository.ErrNotFound)
+	}
+	c := *takes
+	return &c, nil
+}
+
+// FindBySection 根据课程段主键查找所有选课记录，附带学生信息
+func (r *TakesRepository) FindBySection(ctx context.Context, key model.SectionKey) ([]*model.Takes, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var takesList []*model.Takes
+	for _, member := range sortedKeys(r.store.takes, lessMemberKey) {
+		if member.Section == key {
+			takes := *r.store.takes[member]
+			takes.Student = publicStudent(r.store.students[member.PersonID])
+			takesList = append(takesList, &takes)
+		}
+	}
+	return takesList, nil
+}
+
+// FindBySectionID 根据课程段主键查找选课记录
+func (r *TakesRepository) FindBySectionID(ctx context.Context, key model.SectionKey) ([]*model.Takes, error) {
+	return r.FindBySection(ctx, key)
+}
+
+// Create 创建选课记录
+func (r *TakesRepository) Create(ctx context.Context, takes *model.Takes) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member := memberKey{takes.StudentID, takes.Key()}
+	if _, ok := r.store.takes[member]; ok {
+		return fmt.Errorf("error creating takes: %w", repository.ErrDuplicate)
+	}
+	if _, ok := r.store.students[takes.StudentID]; !ok {
+		return fmt.Errorf("error creating takes: student %s: %w", takes.StudentID, repository.ErrNotFound)
+	}
+	if err := r.store.requireSection(member.Section); err != nil {
+		return fmt.Errorf("error creating takes: %w", err)
+	}
+
+	r.store.takes[member] = &model.Takes{
+		StudentID: takes.StudentID,
+		CourseID:  takes.CourseID,
+		SectionID: takes.SectionID,
+		Semester:  takes.Semester,
+		Year:      takes.Year,
+		Grade:     takes.Grade,
+	}
+	return nil
+}
+
+// Delete 删除选课记录
+func (r *TakesRepository) Delete(ctx context.Context, studentID string, key model.SectionKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member := memberKey{studentID, key}
+	if _, ok := r.store.takes[member]; !ok {
+		return fmt.Errorf("takes record not found: %w", repository.ErrNotFound)
+	}
+
+	delete(r.store.takes, member)
+	return nil
+}
+
+// UpdateGrade 更新成绩
+func (r *TakesRepository) UpdateGrade(ctx context.Context, studentID string, key model.SectionKey, grade string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	takes, ok := r.store.takes[memberKey{studentID, key}]
+	if !ok {
+		return fmt.Errorf("takes record not found: %w", repository.ErrNotFound)
+	}
+
+	takes.Grade = grade
+	return nil
+}
+
+// GetStudentTranscript 获取学生成绩单，按年份和学期倒序
+func (r *TakesRepository) GetStudentTranscript(ctx context.Context, studentID string) (*model.Transcript, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	student, ok := r.store.students[studentID]
+	if !ok {
+		return nil, fmt.Errorf("error getting student info: %w", repository.ErrNotFound)
+	}
+
+	var transcript model.Transcript
+	transcript.Student = *student.ToDTO()
+	var totalCredits float64
+	var totalGradePoints float64
+
+	for _, member := range sortedKeys(r.store.takes, lessMemberKey) {
+		if member.PersonID != studentID {
+			continue
+		}
+		takes := r.store.takes[member]
+		course := r.store.courses[takes.CourseID]
+
+		courseGrade := model.CourseGrade{
+			CourseID:   takes.CourseID,
+			Title:      course.Title,
+			Semester:   takes.Semester,
+			Year:       takes.Year,
+			Credits:    course.Credits,
+			Grade:      takes.Grade,
+			GradePoint: model.GradePoint(takes.Grade),
+		}
+		transcript.Courses = append(transcript.Courses, courseGrade)
+
+		// 退选（W）不计入学分和GPA
+		if takes.Grade != "" && takes.Grade != "F" && takes.Grade != model.GradeWithdrawn {
+			totalCredits += courseGrade.Credits
+			totalGradePoints += courseGrade.Credits * courseGrade.GradePoint
+		}
+	}
+
+	sort.SliceStable(transcript.Courses, func(i, j int) bool {
+		a, b := transcript.Courses[i], transcript.Courses[j]
+		if a.Year != b.Year {
+			return a.Year > b.Year
+		}
+		return a.Semester > b.Semester
+	})
+
+	transcript.TotalCred = totalCredits
+	if totalCredits > 0 {
+		transcript.GPA = totalGradePoints / totalCredits
+	}
+
+	return &transcript, nil
+}
+
+// GetCurrentCourses 获取学生某学期的课程，附带课程、课程段和时间段信息
+func (r *TakesRepository) GetCurrentCourses(ctx context.Context, studentID string, semester string, year int) ([]*model.Takes, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var takesList []*model.Takes
+	for _, member := range sortedKeys(r.store.takes, lessMemberKey) {
+		if member.PersonID != studentID || member.Section.Semester != semester || member.Section.Year != year {
+			continue
+		}
+		takes := r.store.takesWithSection(member)
+		takes.Section.Course = takes.Course
+		if timeSlot, ok := r.store.timeSlots[takes.Section.TimeSlotID]; ok {
+			takes.Section.TimeSlot = copyTimeSlot(timeSlot)
+		}
+		takesList = append(takesList, takes)
+	}
+	return takesList, nil
+}
+
+// CheckTimeConflict 检查时间冲突
+func (r *TakesRepository) CheckTimeConflict(ctx context.Context, studentID string, key model.SectionKey) (bool, error) {
+	conflicts, err := r.FindTimeConflicts(ctx, studentID, key)
+	if err != nil {
+		return false, err
+	}
+	return len(conflicts) > 0, nil
+}
+
+// FindTimeConflicts 查找学生同学期已选课程中与该课程段时间重叠的课程段及冲突的时间段
+func (r *TakesRepository) FindTimeConflicts(ctx context.Context, studentID string, key model.SectionKey) ([]*model.TimeConflict, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	section, ok := r.store.sections[key]
+	if !ok {
+		return nil, nil
+	}
+	slot, ok := r.store.timeSlots[section.TimeSlotID]
+	if !ok {
+		return nil, nil
+	}
+	slotStart := slot.StartHr*60 + slot.StartMin
+	slotEnd := slot.EndHr*60 + slot.EndMin
+
+	var conflicts []*model.TimeConflict
+	for _, member := range sortedKeys(r.store.takes, lessMemberKey) {
+		other := member.Section
+		if member.PersonID != studentID || other.Semester != key.Semester || other.Year != key.Year ||
+			(other.CourseID == key.CourseID && other.SecID == key.SecID) {
+			continue
+		}
+		// 已退选（W）的课程不参与冲突检查
+		if r.store.takes[member].Grade == model.GradeWithdrawn {
+			continue
+		}
+
+		current, ok := r.store.timeSlots[r.store.sections[other].TimeSlotID]
+		if !ok || current.Days[0] != slot.Days[0] {
+			continue
+		}
+		currentStart := current.StartHr*60 + current.StartMin
+		currentEnd := current.EndHr*60 + current.EndMin
+
+		if slotStart < currentEnd && slotEnd > currentStart {
+			conflicts = append(conflicts, &model.TimeConflict{
+				Section:    other,
+				TimeSlotID: current.ID,
+				Day:        strconv.Itoa(current.Days[0]),
+				StartTime:  fmt.Sprintf("%02d:%02d", current.StartHr, current.StartMin),
+				EndTime:    fmt.Sprintf("%02d:%02d", current.EndHr, current.EndMin),
+			})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// GetTermCredits 统计学生某学期已选课程的总学分，退选（W）的课程不计入
+func (r *TakesRepository) GetTermCredits(ctx context.Context, studentID string, semester string, year int) (float64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var credits float64
+	for member, takes := range r.store.takes {
+		if member.PersonID == studentID && member.Section.Semester == semester && member.Section.Year == year &&
+			takes.Grade != model.GradeWithdrawn {
+			credits += r.store.courses[takes.CourseID].Credits
+		}
+	}
+	return credits, nil
+}
+
+// takesWithSection 返回附带课程和课程段信息的选课记录副本，调用方需持有锁
+func (s *Store) takesWithSection(member memberKey) *model.Takes {
+	takes := *s.takes[member]
+	takes.Course = copyCourse(s.courses[takes.CourseID])
+	takes.Section = copySection(s.sections[member.Section])
+	return &takes
+}
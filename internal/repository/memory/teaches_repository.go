@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// TeachesRepository 授课记录仓储的内存实现
+type TeachesRepository struct {
+	store *Store
+}
+
+// NewTeachesRepository 创建授课记录仓储实例
+func NewTeachesRepository(store *Store) repository.TeachesRepository {
+	return &TeachesRepository{store: store}
+}
+
+// FindAll 查找所有授课记录，附带课程和课程段信息
+func (r *TeachesRepository) FindAll(ctx context.Context) ([]*model.Teaches, error) {
+	return r.find(func(memberKey) bool { return true }), nil
+}
+
+// teachesListSpec 授课记录列表的字段取值
+var teachesListSpec = listSpec[model.Teaches]{
+	fields: model.TeachesListFields,
+	values: map[string]func(*model.Teaches) interface{}{
+		"instructor_id": func(t *model.Teaches) interface{} { return t.InstructorID },
+		"course_id":     func(t *model.Teaches) interface{} { return t.CourseID },
+		"section_id":    func(t *model.Teaches) interface{} { return t.SectionID },
+		"semester":      func(t *model.Teaches) interface{} { return t.Semester },
+		"year":          func(t *model.Teaches) interface{} { return t.Year },
+	},
+}
+
+// List 按分页、排序和过滤条件查找授课记录，附带课程和课程段信息
+func (r *TeachesRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Teaches], error) {
+	return list(r.find(func(memberKey) bool { return true }), teachesListSpec, q)
+}
+
+// FindByInstructorID 根据教师ID查找授课记录，附带课程和课程段信息
+func (r *TeachesRepository) FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Teaches, error) {
+	return r.find(func(member memberKey) bool { return member.PersonID == instructorID }), nil
+}
+
+// GetCurrentTeaching 获取教师某学期的授课记录，课程段附带时间段和选课人数
+func (r *TeachesRepository) GetCurrentTeaching(ctx context.Context, instructorID string, semester string, year int) ([]*model.Teaches, error) {
+	teachesList := r.find(func(member memberKey) bool {
+		return member.PersonID == instructorID && member.Section.Semester == semester && member.Section.Year == year
+	})
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, teaches := range teachesList {
+		teaches.Section.Course = teaches.Course
+		teaches.Section.Enrollment = r.store.enrollmentCount(teaches.Key())
+		if timeSlot, ok := r.store.timeSlots[teaches.Section.TimeSlotID]; ok {
+			teaches.Section.TimeSlot = copyTimeSlot(timeSlot)
+		}
+	}
+	return teachesList, nil
+}
+
+func (r *TeachesRepository) find(match func(memberKey) bool) []*model.Teaches {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var teachesList []*model.Teaches
+	for _, member := range sortedKeys(r.store.teaches, lessMemberKey) {
+		if !match(member) {
+			continue
+		}
+		teaches := *r.store.teaches[member]
+		teaches.Course = copyCourse(r.store.courses[teaches.CourseID])
+		teaches.Section = copySection(r.store.sections[member.Section])
+		teachesList = append(teachesList, &teaches)
+	}
+	return teachesList
+}
+
+// FindBySectionID 根据课程段主键查找授课记录，附带教师信息
+func (r *TeachesRepository) FindBySectionID(ctx context.Context, key model.SectionKey) ([]*model.Teaches, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var teachesList []*model.Teaches
+	for _, member := range sortedKeys(r.store.teaches, lessMemberKey) {
+		if member.Section == key {
+			teaches := *r.store.teaches[member]
+			teaches.Instructor = publicInstructor(r.store.instructors[member.PersonID])
+			teachesList = append(teachesList, &teaches)
+		}
+	}
+	return teachesList, nil
+}
+
+// FindByInstructorAndSection 根据教师ID和课程段主键查找授课记录
+func (r *TeachesRepository) FindByInstructorAndSection(ctx context.Context, instructorID string, key model.SectionKey) (*model.Teaches, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	teaches, ok := r.store.teaches[memberKey{instructorID, key}]
+	if !ok {
+		return nil, fmt.Errorf("teaches record not found: %w", repository.ErrNotFound)
+	}
+	c := *teaches
+	return &c, nil
+}
+
+// Create 创建授课记录
+func (r *TeachesRepository) Create(ctx context.Context, teaches *model.Teaches) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member := memberKey{teaches.InstructorID, teaches.Key()}
+	if _, ok := r.store.teaches[member]; ok {
+		return fmt.Errorf("error creating teaches: %w", repository.ErrDuplicate)
+	}
+	if _, ok := r.store.instructors[teaches.InstructorID]; !ok {
+		return fmt.Errorf("error creating teaches: instructor %s: %w", teaches.InstructorID, repository.ErrNotFound)
+	}
+	if err := r.store.requireSection(member.Section); err != nil {
+		return fmt.Errorf("error creating teaches: %w", err)
+	}
+
+	// 与数据库一样，记录ID就是教师ID
+	r.store.teaches[member] = &model.Teaches{
+		ID:           teaches.InstructorID,
+		InstructorID: teaches.InstructorID,
+		CourseID:     teaches.CourseID,
+		SectionID:    teaches.SectionID,
+		Semester:     teaches.Semester,
+		Year:         teaches.Year,
+	}
+	return nil
+}
+
+// Delete 删除授课记录
+func (r *TeachesRepository) Delete(ctx context.Context, instructorID string, key model.SectionKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	member := memberKey{instructorID, key}
+	if _, ok := r.store.teaches[member]; !ok {
+		return fmt.Errorf("teaches record not found: %w", repository.ErrNotFound)
+	}
+
+	delete(r.store.teaches, member)
+	return nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// TimeSlotRepository 时间段仓储的内存实现
+type TimeSlotRepository struct {
+	store *Store
+}
+
+// NewTimeSlotRepository 创建时间段仓储实例
+func NewTimeSlotRepository(store *Store) repository.TimeSlotRepository {
+	return &TimeSlotRepository{store: store}
+}
+
+// FindByID 根据ID查找时间段
+func (r *TimeSlotRepository) FindByID(ctx context.Context, id string) (*model.TimeSlot, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	timeSlot, ok := r.store.timeSlots[id]
+	if !ok {
+		return nil, fmt.Errorf("time slot not found: %w", repository.ErrNotFound)
+	}
+	return copyTimeSlot(timeSlot), nil
+}
+
+// FindAll 查找所有时间段
+func (r *TimeSlotRepository) FindAll(ctx context.Context) ([]*model.TimeSlot, error) {
+	return r.find(func(*model.TimeSlot) bool { return true }), nil
+}
+
+// FindByDayOfWeek 根据星期几查找时间段
+func (r *TimeSlotRepository) FindByDayOfWeek(ctx context.Context, dayOfWeek int) ([]*model.TimeSlot, error) {
+	return r.find(func(timeSlot *model.TimeSlot) bool { return timeSlot.Days[0] == dayOfWeek }), nil
+}
+
+// FindByTimeRange 查找完全落在时间范围内的时间段，startTime和endTime为 HH:MM 格式
+func (r *TimeSlotRepository) FindByTimeRange(ctx context.Context, startTime, endTime string) ([]*model.TimeSlot, error) {
+	start, err := minuteOfDay(startTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time %q: %w", startTime, err)
+	}
+	end, err := minuteOfDay(endTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time %q: %w", endTime, err)
+	}
+
+	return r.find(func(timeSlot *model.TimeSlot) bool {
+		return timeSlot.StartHr*60+timeSlot.StartMin >= start && timeSlot.EndHr*60+timeSlot.EndMin <= end
+	}), nil
+}
+
+func (r *TimeSlotRepository) find(match func(*model.TimeSlot) bool) []*model.TimeSlot {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var timeSlots []*model.TimeSlot
+	for _, id := range sortedKeys(r.store.timeSlots, lessString) {
+		if timeSlot := r.store.timeSlots[id]; match(timeSlot) {
+			timeSlots = append(timeSlots, copyTimeSlot(timeSlot))
+		}
+	}
+	return timeSlots
+}
+
+// Create 创建时间段，与数据库一样只保存Days的第一天
+func (r *TimeSlotRepository) Create(ctx context.Context, timeSlot *model.TimeSlot) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.timeSlots[timeSlot.ID]; ok {
+		return fmt.Errorf("error creating time slot: %w", repository.ErrDuplicate)
+	}
+
+	r.store.timeSlots[timeSlot.ID] = storedTimeSlot(timeSlot)
+	return nil
+}
+
+// Update 更新时间段
+func (r *TimeSlotRepository) Update(ctx context.Context, timeSlot *model.TimeSlot) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.timeSlots[timeSlot.ID]; !ok {
+		return fmt.Errorf("time slot not found: %w", repository.ErrNotFound)
+	}
+
+	r.store.timeSlots[timeSlot.ID] = storedTimeSlot(timeSlot)
+	return nil
+}
+
+// Delete 删除时间段，被课程段使用时拒绝删除
+func (r *TimeSlotRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.timeSlots[id]; !ok {
+		return fmt.Errorf("time slot not found: %w", repository.ErrNotFound)
+	}
+	for _, section := range r.store.sections {
+		if section.TimeSlotID == id {
+			return fmt.Errorf("cannot delete time slot: it is used by sections")
+		}
+	}
+
+	delete(r.store.timeSlots, id)
+	return nil
+}
+
+// storedTimeSlot 返回按数据库列保存的时间段：一个星期几和起止时分
+func storedTimeSlot(timeSlot *model.TimeSlot) *model.TimeSlot {
+	day := 0
+	if len(timeSlot.Days) > 0 {
+		day = timeSlot.Days[0]
+	}
+	return &model.TimeSlot{
+		ID:       timeSlot.ID,
+		Days:     []int{day},
+		StartHr:  timeSlot.StartHr,
+		StartMin: timeSlot.StartMin,
+		EndHr:    timeSlot.EndHr,
+		EndMin:   timeSlot.EndMin,
+	}
+}
+
+// minuteOfDay 把 HH:MM 转换为当天的分钟数
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
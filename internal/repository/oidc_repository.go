@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// OIDCRepository 定义单点登录仓库接口
+type OIDCRepository interface {
+	CreateState(ctx context.Context, state *model.OIDCLoginState) error
+	FindState(ctx context.Context, id string) (*model.OIDCLoginState, error)
+	MarkStateUsed(ctx context.Context, id string, at time.Time) error
+
+	ListIdentities(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.OIDCIdentity], error)
+	FindIdentity(ctx context.Context, role string, userID string) (*model.OIDCIdentity, error)
+	FindIdentityBySubject(ctx context.Context, role string, subject string) (*model.OIDCIdentity, error)
+	FindIdentityByEmail(ctx context.Context, role string, email string) (*model.OIDCIdentity, error)
+	CreateIdentity(ctx context.Context, identity *model.OIDCIdentity) error
+	BindSubject(ctx context.Context, role string, userID string, subject string) error
+	DeleteIdentity(ctx context.Context, role string, userID string) error
+}
+
+// SQLOIDCRepository 实现OIDCRepository接口
+type SQLOIDCRepository struct {
+	db DBTX
+}
+
+// NewOIDCRepository 创建单点登录仓库实例
+func NewOIDCRepository(db DBTX) OIDCRepository {
+	return &SQLOIDCRepository{db: db}
+}
+
+// CreateState 保存登录状态
+func (r *SQLOIDCRepository) CreateState(ctx context.Context, state *model.OIDCLoginState) error {
+	query := `
+		INSERT INTO oidc_login_state (id, state_hash, nonce, code_verifier, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, state.ID, state.StateHash, state.Nonce, state.CodeVerifier, state.CreatedAt, state.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creating oidc login state: %w", err)
+	}
+
+	return nil
+}
+
+// FindState 根据ID查找登录状态
+func (r *SQLOIDCRepository) FindState(ctx context.Context, id string) (*model.OIDCLoginState, error) {
+	var state model.OIDCLoginState
+	var usedAt sql.NullTime
+	query := `SELECT id, state_hash, nonce, code_verifier, created_at, expires_at, used_at FROM oidc_login_state WHERE id = ?`
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&state.ID,
+		&state.StateHash,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.CreatedAt,
+		&state.ExpiresAt,
+		&usedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying oidc login state: %w", err)
+	}
+
+	if usedAt.Valid {
+		state.UsedAt = &usedAt.Time
+	}
+
+	return &state, nil
+}
+
+// MarkStateUsed 将登录状态标记为已使用，已使用时返回ErrNotFound
+func (r *SQLOIDCRepository) MarkStateUsed(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE oidc_login_state SET used_at = ? WHERE id = ? AND used_at IS NULL`
+
+	return r.execOne(ctx, query, "error marking oidc login state used", at, id)
+}
+
+// oidcIdentityListTable 身份绑定列表的查询方式
+var oidcIdentityListTable = listTable{
+	fields:  model.OIDCIdentityListFields,
+	name:    "oidc identities",
+	selects: `role, user_id, subject, email, created_at`,
+	from:    `oidc_identity`,
+	columns: map[string]string{
+		"role":       "role",
+		"user_id":    "user_id",
+		"email":      "email",
+		"created_at": "created_at",
+	},
+}
+
+// ListIdentities 按分页、排序和过滤条件查找身份绑定
+func (r *SQLOIDCRepository) ListIdentities(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.OIDCIdentity], error) {
+	return list(ctx, r.db, oidcIdentityListTable, q, scanIdentity)
+}
+
+// FindIdentity 查找本地账号的身份绑定
+func (r *SQLOIDCRepository) FindIdentity(ctx context.Context, role string, userID string) (*model.OIDCIdentity, error) {
+	return r.findIdentity(ctx, `WHERE role = ? AND user_id = ?`, role, userID)
+}
+
+// FindIdentityBySubject 根据身份提供方的subject查找身份绑定
+func (r *SQLOIDCRepository) FindIdentityBySubject(ctx context.Context, role string, subject string) (*model.OIDCIdentity, error) {
+	return r.findIdentity(ctx, `WHERE role = ? AND subject = ?`, role, subject)
+}
+
+// FindIdentityByEmail 根据邮箱查找身份绑定
+func (r *SQLOIDCRepository) FindIdentityByEmail(ctx context.Context, role string, email string) (*model.OIDCIdentity, error) {
+	return r.findIdentity(ctx, `WHERE role = ? AND email = ?`, role, email)
+}
+
+// CreateIdentity 保存身份绑定，账号已绑定或subject、邮箱已被占用时返回ErrDuplicate
+func (r *SQLOIDCRepository) CreateIdentity(ctx context.Context, identity *model.OIDCIdentity) error {
+	query := `
+		INSERT INTO oidc_identity (role, user_id, subject, email, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, identity.Role, identity.UserID, nullableString(identity.Subject),
+		nullableString(identity.Email), identity.CreatedAt)
+	if err != nil {
+		if errors.Is(err, ErrDuplicate) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("error creating oidc identity: %w", err)
+	}
+
+	return nil
+}
+
+// BindSubject 为只按邮箱绑定的账号记录subject，已有subject时返回ErrNotFound
+func (r *SQLOIDCRepository) BindSubject(ctx context.Context, role string, userID string, subject string) error {
+	query := `UPDATE oidc_identity SET subject = ? WHERE role = ? AND user_id = ? AND subject IS NULL`
+
+	return r.execOne(ctx, query, "error binding oidc subject", subject, role, userID)
+}
+
+// DeleteIdentity 删除身份绑定
+func (r *SQLOIDCRepository) DeleteIdentity(ctx context.Context, role string, userID string) error {
+	query := `DELETE FROM oidc_identity WHERE role = ? AND user_id = ?`
+
+	return r.execOne(ctx, query, "error deleting oidc identity", role, userID)
+}
+
+// findIdentity 按条件查找一条身份绑定
+func (r *SQLOIDCRepository) findIdentity(ctx context.Context, where string, args ...interface{}) (*model.OIDCIdentity, error) {
+	identities, err := r.findIdentities(ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, ErrNotFound
+	}
+	return identities[0], nil
+}
+
+// findIdentities 按条件查询身份绑定
+func (r *SQLOIDCRepository) findIdentities(ctx context.Context, where string, args ...interface{}) ([]*model.OIDCIdentity, error) {
+	query := `SELECT role, user_id, subject, email, created_at FROM oidc_identity ` + where
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying oidc identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []*model.OIDCIdentity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning oidc identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating oidc identities: %w", err)
+	}
+
+	return identities, nil
+}
+
+// scanIdentity 扫描一行身份绑定，没有subject或邮箱时为空字符串
+func scanIdentity(scanner rowScanner) (*model.OIDCIdentity, error) {
+	var identity model.OIDCIdentity
+	var subject, email sql.NullString
+	if err := scanner.Scan(&identity.Role, &identity.UserID, &subject, &email, &identity.CreatedAt); err != nil {
+		return nil, err
+	}
+	identity.Subject = subject.String
+	identity.Email = email.String
+	return &identity, nil
+}
+
+// execOne 执行只应影响一行的更新，没有影响任何行时返回ErrNotFound
+func (r *SQLOIDCRepository) execOne(ctx context.Context, query string, errMsg string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMsg, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// nullableString 空字符串写入NULL，使唯一索引允许多个空值
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
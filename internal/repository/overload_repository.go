@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// OverloadRepository 定义学分超载申请仓库接口
+type OverloadRepository interface {
+	FindByID(ctx context.Context, id string) (*model.OverloadRequest, error)
+	FindByStudentID(ctx context.Context, studentID string) ([]*model.OverloadRequest, error)
+	FindByStudentAndTerm(ctx context.Context, studentID string, semester string, year int) ([]*model.OverloadRequest, error)
+	Create(ctx context.Context, req *model.OverloadRequest) error
+	UpdateDecision(ctx context.Context, req *model.OverloadRequest) error
+}
+
+// SQLOverloadRepository 实现OverloadRepository接口
+type SQLOverloadRepository struct {
+	db DBTX
+}
+
+// NewOverloadRepository 创建学分超载申请仓库实例
+func NewOverloadRepository(db DBTX) OverloadRepository {
+	return &SQLOverloadRepository{db: db}
+}
+
+// overloadColumns 查询超载申请时使用的公共列
+const overloadColumns = `id, student_id, semester, year, requested_credits, reason, status, advisor_id, decision_note, created_at, decided_at`
+
+// scanOverloadRequest 扫描一行超载申请
+func scanOverloadRequest(scanner rowScanner) (*model.OverloadRequest, error) {
+	var req model.OverloadRequest
+	var status string
+	var reason, advisorID, note sql.NullString
+	var decidedAt sql.NullTime
+
+	err := scanner.Scan(
+		&req.ID,
+		&req.StudentID,
+		&req.Semester,
+		&req.Year,
+		&req.RequestedCredits,
+		&reason,
+		&status,
+		&advisorID,
+		&note,
+		&req.CreatedAt,
+		&decidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Status = model.OverloadStatus(status)
+	req.Reason = reason.String
+	req.AdvisorID = advisorID.String
+	req.DecisionNote = note.String
+	if decidedAt.Valid {
+		req.DecidedAt = &decidedAt.Time
+	}
+
+	return &req, nil
+}
+
+// FindByID 根据ID查找超载申请
+func (r *SQLOverloadRepository) FindByID(ctx context.Context, id string) (*model.OverloadRequest, error) {
+	query := `SELECT ` + overloadColumns + ` FROM overload_request WHERE id = ?`
+
+	req, err := scanOverloadRequest(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying overload request: %w", err)
+	}
+
+	return req, nil
+}
+
+// FindByStudentID 查找学生的所有超载申请
+func (r *SQLOverloadRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.OverloadRequest, error) {
+	query := `SELECT ` + overloadColumns + ` FROM overload_request WHERE student_id = ? ORDER BY created_at DESC`
+	return r.findMany(ctx, query, studentID)
+}
+
+// FindByStudentAndTerm 查找学生在指定学期的所有超载申请
+func (r *SQLOverloadRepository) FindByStudentAndTerm(ctx context.Context, studentID string, semester string, year int) ([]*model.OverloadRequest, error) {
+	query := `SELECT ` + overloadColumns + ` FROM overload_request WHERE student_id = ? AND semester = ? AND year = ? ORDER BY created_at DESC`
+	return r.findMany(ctx, query, studentID, semester, year)
+}
+
+// findMany 执行查询并扫描多行超载申请
+func (r *SQLOverloadRepository) findMany(ctx context.Context, query string, args ...interface{}) ([]*model.OverloadRequest, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying overload requests: %w", err)
+	}
+	defer rows.Close()
+
+	var reqs []*model.OverloadRequest
+	for rows.Next() {
+		req, err := scanOverloadRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning overload request: %w", err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating overload requests: %w", err)
+	}
+
+	return reqs, nil
+}
+
+// Create 创建超载申请
+func (r *SQLOverloadRepository) Create(ctx context.Context, req *model.OverloadRequest) error {
+	query := `INSERT INTO overload_request (id, student_id, semester, year, requested_credits, reason, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		req.ID,
+		req.StudentID,
+		req.Semester,
+		req.Year,
+		req.RequestedCredits,
+		req.Reason,
+		string(req.Status),
+		req.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating overload request: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDecision 保存导师的审批结果，只有等待中的申请可以被审批
+func (r *SQLOverloadRepository) UpdateDecision(ctx context.Context, req *model.OverloadRequest) error {
+	query := `UPDATE overload_request SET status = ?, advisor_id = ?, decision_note = ?, decided_at = ? WHERE id = ? AND status = 'pending'`
+
+	result, err := r.db.ExecContext(ctx, query,
+		string(req.Status),
+		req.AdvisorID,
+		req.DecisionNote,
+		req.DecidedAt,
+		req.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating overload request: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending overload request not found: %w", ErrNotFound)
+	}
+
+	return nil
+}
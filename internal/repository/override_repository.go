@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// OverrideRepository 定义选课特许仓库接口
+type OverrideRepository interface {
+	FindByID(ctx context.Context, id string) (*model.RegistrationOverride, error)
+	FindByCode(ctx context.Context, code string) (*model.RegistrationOverride, error)
+	FindBySection(ctx context.Context, key model.SectionKey) ([]*model.RegistrationOverride, error)
+	FindActiveByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) ([]*model.RegistrationOverride, error)
+	Create(ctx context.Context, override *model.RegistrationOverride) error
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) error
+	Revoke(ctx context.Context, id string) error
+}
+
+// SQLOverrideRepository 实现OverrideRepository接口
+type SQLOverrideRepository struct {
+	db DBTX
+}
+
+// NewOverrideRepository 创建选课特许仓库实例
+func NewOverrideRepository(db DBTX) OverrideRepository {
+	return &SQLOverrideRepository{db: db}
+}
+
+// overrideColumns 查询选课特许时使用的公共列
+const overrideColumns = `id, student_id, course_id, sec_id, semester, year, waives, code, granted_by, reason, status, created_at, used_at`
+
+// scanOverride 扫描一行选课特许，waives 以逗号分隔存储
+func scanOverride(scanner rowScanner) (*model.RegistrationOverride, error) {
+	var override model.RegistrationOverride
+	var waives, status string
+	var code sql.NullString
+	var usedAt sql.NullTime
+
+	err := scanner.Scan(
+		&override.ID,
+		&override.StudentID,
+		&override.CourseID,
+		&override.SectionID,
+		&override.Semester,
+		&override.Year,
+		&waives,
+		&code,
+		&override.GrantedBy,
+		&override.Reason,
+		&status,
+		&override.CreatedAt,
+		&usedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, waived := range strings.Split(waives, ",") {
+		if waived != "" {
+			override.Waives = append(override.Waives, model.EligibilityReasonCode(waived))
+		}
+	}
+	override.Code = code.String
+	override.Status = model.OverrideStatus(status)
+	if usedAt.Valid {
+		override.UsedAt = &usedAt.Time
+	}
+
+	return &override, nil
+}
+
+// FindByID 根据ID查找选课特许
+func (r *SQLOverrideRepository) FindByID(ctx context.Context, id string) (*model.RegistrationOverride, error) {
+	query := `SELECT ` + overrideColumns + ` FROM registration_override WHERE id = ?`
+	return r.findOne(ctx, query, id)
+}
+
+// FindByCode 根据许可码查找选课特许
+func (r *SQLOverrideRepository) FindByCode(ctx context.Context, code string) (*model.RegistrationOverride, error) {
+	query := `SELECT ` + overrideColumns + ` FROM registration_override WHERE code = ?`
+	return r.findOne(ctx, query, code)
+}
+
+// FindBySection 查找课程段的所有选课特许
+func (r *SQLOverrideRepository) FindBySection(ctx context.Context, key model.SectionKey) ([]*model.RegistrationOverride, error) {
+	query := `SELECT ` + overrideColumns + ` FROM registration_override WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ? ORDER BY created_at DESC`
+	return r.findMany(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year)
+}
+
+// FindActiveByStudentAndSection 查找学生在课程段上尚未使用且不需要许可码的特许
+func (r *SQLOverrideRepository) FindActiveByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) ([]*model.RegistrationOverride, error) {
+	query := `SELECT ` + overrideColumns + ` FROM registration_override WHERE student_id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ? AND status = 'active' AND code IS NULL ORDER BY created_at`
+	return r.findMany(ctx, query, studentID, key.CourseID, key.SecID, key.Semester, key.Year)
+}
+
+// findOne 执行查询并扫描一行选课特许
+func (r *SQLOverrideRepository) findOne(ctx context.Context, query string, args ...interface{}) (*model.RegistrationOverride, error) {
+	override, err := scanOverride(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying registration override: %w", err)
+	}
+
+	return override, nil
+}
+
+// findMany 执行查询并扫描多行选课特许
+func (r *SQLOverrideRepository) findMany(ctx context.Context, query string, args ...interface{}) ([]*model.RegistrationOverride, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying registration overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*model.RegistrationOverride
+	for rows.Next() {
+		override, err := scanOverride(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning registration override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registration overrides: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// Create 创建选课特许
+func (r *SQLOverrideRepository) Create(ctx context.Context, override *model.RegistrationOverride) error {
+	query := `INSERT INTO registration_override (id, student_id, course_id, sec_id, semester, year, waives, code, granted_by, reason, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	waives := make([]string, 0, len(override.Waives))
+	for _, waived := range override.Waives {
+		waives = append(waives, string(waived))
+	}
+
+	var code sql.NullString
+	if override.Code != "" {
+		code = sql.NullString{String: override.Code, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		override.ID,
+		override.StudentID,
+		override.CourseID,
+		override.SectionID,
+		override.Semester,
+		override.Year,
+		strings.Join(waives, ","),
+		code,
+		override.GrantedBy,
+		override.Reason,
+		string(override.Status),
+		override.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating registration override: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUsed 将选课特许标记为已使用，只有未使用的特许可以被使用，保证一次性
+func (r *SQLOverrideRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	query := `UPDATE registration_override SET status = 'used', used_at = ? WHERE id = ? AND status = 'active'`
+	return r.updateActive(ctx, query, usedAt, id)
+}
+
+// Revoke 撤销尚未使用的选课特许
+func (r *SQLOverrideRepository) Revoke(ctx context.Context, id string) error {
+	query := `UPDATE registration_override SET status = 'revoked' WHERE id = ? AND status = 'active'`
+	return r.updateActive(ctx, query, id)
+}
+
+// updateActive 更新一条未使用的特许，没有匹配的行时返回ErrNotFound
+func (r *SQLOverrideRepository) updateActive(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error updating registration override: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
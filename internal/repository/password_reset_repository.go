@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// PasswordResetRepository 定义找回密码令牌仓库接口
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *model.PasswordResetToken) error
+	FindByID(ctx context.Context, id string) (*model.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) error
+	InvalidateByUser(ctx context.Context, userID string, role string, at time.Time) error
+}
+
+// SQLPasswordResetRepository 实现PasswordResetRepository接口
+type SQLPasswordResetRepository struct {
+	db DBTX
+}
+
+// NewPasswordResetRepository 创建找回密码令牌仓库实例
+func NewPasswordResetRepository(db DBTX) PasswordResetRepository {
+	return &SQLPasswordResetRepository{db: db}
+}
+
+// Create 保存重置令牌
+func (r *SQLPasswordResetRepository) Create(ctx context.Context, token *model.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_token (id, user_id, role, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, token.ID, token.UserID, token.Role, token.TokenHash, token.CreatedAt, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creating password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID 根据ID查找重置令牌
+func (r *SQLPasswordResetRepository) FindByID(ctx context.Context, id string) (*model.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, role, token_hash, created_at, expires_at, used_at
+		FROM password_reset_token
+		WHERE id = ?
+	`
+
+	var token model.PasswordResetToken
+	var usedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Role,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&usedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying password reset token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+// MarkUsed 将令牌标记为已使用，令牌已被使用时返回ErrNotFound
+func (r *SQLPasswordResetRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	query := `UPDATE password_reset_token SET used_at = ? WHERE id = ? AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return fmt.Errorf("error marking password reset token used: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// InvalidateByUser 作废某个用户所有未使用的重置令牌
+func (r *SQLPasswordResetRepository) InvalidateByUser(ctx context.Context, userID string, role string, at time.Time) error {
+	query := `UPDATE password_reset_token SET used_at = ? WHERE user_id = ? AND role = ? AND used_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, at, userID, role); err != nil {
+		return fmt.Errorf("error invalidating password reset tokens: %w", err)
+	}
+
+	return nil
+}
@@ -1,44 +1,52 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // PrereqRepository 定义先修课程仓储接口
 type PrereqRepository interface {
-	FindByID(courseID, prereqID string) (*model.Prereq, error)
-	FindAll() ([]*model.Prereq, error)
-	FindByCourseID(courseID string) ([]*model.Prereq, error)
-	Create(prereq *model.Prereq) error
-	Delete(courseID, prereqID string) error
-	GetPrereqIDs(courseID string) ([]string, error)
-	CheckPrereqsSatisfied(studentID string, courseID string) (bool, error)
-	HasPrerequisite(courseID string, prereqID string) (bool, error)
+	FindByID(ctx context.Context, courseID, prereqID string) (*model.Prereq, error)
+	FindAll(ctx context.Context) ([]*model.Prereq, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Prereq], error)
+	FindByCourseID(ctx context.Context, courseID string) ([]*model.Prereq, error)
+	Create(ctx context.Context, prereq *model.Prereq) error
+	Delete(ctx context.Context, courseID, prereqID string) error
+	GetPrereqIDs(ctx context.Context, courseID string) ([]string, error)
+	CheckPrereqsSatisfied(ctx context.Context, studentID string, courseID string) (bool, error)
+	HasPrerequisite(ctx context.Context, courseID string, prereqID string) (bool, error)
+	GetRule(ctx context.Context, courseID string) (*model.PrereqRule, error)
+	GetEffectiveRule(ctx context.Context, courseID string) (*model.PrereqRule, error)
+	SaveRule(ctx context.Context, courseID string, rule *model.PrereqRule) error
+	DeleteRule(ctx context.Context, courseID string) error
+	EvaluatePrereqs(ctx context.Context, studentID string, courseID string, semester string, year int) (*model.PrereqCheckResult, error)
 }
 
 // SQLPrereqRepository 实现PrereqRepository接口
 type SQLPrereqRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPrereqRepository 创建先修课程仓储实例
-func NewPrereqRepository(db *sql.DB) *SQLPrereqRepository {
+func NewPrereqRepository(db DBTX) *SQLPrereqRepository {
 	return &SQLPrereqRepository{db: db}
 }
 
 // FindByCourseID 根据课程ID查找先修课程
-func (r *SQLPrereqRepository) FindByCourseID(courseID string) ([]*model.Prereq, error) {
+func (r *SQLPrereqRepository) FindByCourseID(ctx context.Context, courseID string) ([]*model.Prereq, error) {
 	query := `
 		SELECT p.course_id, p.prereq_id, c.title, c.dept_name, c.credits 
 		FROM prereq p 
 		JOIN course c ON p.prereq_id = c.course_id 
 		WHERE p.course_id = ?
 	`
-	rows, err := r.db.Query(query, courseID)
+	rows, err := r.db.QueryContext(ctx, query, courseID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying prereqs: %w", err)
 	}
@@ -65,11 +73,11 @@ func (r *SQLPrereqRepository) FindByCourseID(courseID string) ([]*model.Prereq,
 }
 
 // Create 创建先修课程关系
-func (r *SQLPrereqRepository) Create(prereq *model.Prereq) error {
+func (r *SQLPrereqRepository) Create(ctx context.Context, prereq *model.Prereq) error {
 	query := `INSERT INTO prereq (course_id, prereq_id) VALUES (?, ?)`
-	_, err := r.db.Exec(query, prereq.CourseID, prereq.PrereqID)
+	_, err := r.db.ExecContext(ctx, query, prereq.CourseID, prereq.PrereqID)
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, ErrDuplicate) {
 			return fmt.Errorf("prereq relationship already exists: %w", err)
 		}
 		return fmt.Errorf("error creating prereq: %w", err)
@@ -79,9 +87,9 @@ func (r *SQLPrereqRepository) Create(prereq *model.Prereq) error {
 }
 
 // Delete 删除先修课程关系
-func (r *SQLPrereqRepository) Delete(courseID, prereqID string) error {
+func (r *SQLPrereqRepository) Delete(ctx context.Context, courseID, prereqID string) error {
 	query := `DELETE FROM prereq WHERE course_id = ? AND prereq_id = ?`
-	result, err := r.db.Exec(query, courseID, prereqID)
+	result, err := r.db.ExecContext(ctx, query, courseID, prereqID)
 	if err != nil {
 		return fmt.Errorf("error deleting prereq: %w", err)
 	}
@@ -92,73 +100,158 @@ func (r *SQLPrereqRepository) Delete(courseID, prereqID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("prereq relationship not found")
+		return fmt.Errorf("prereq relationship not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
-// CheckPrereqsSatisfied 检查学生是否满足课程的所有先修课程要求
-func (r *SQLPrereqRepository) CheckPrereqsSatisfied(studentID, courseID string) (bool, error) {
-	// 查询课程的所有先修课程
-	query := `
-		SELECT prereq_id 
-		FROM prereq 
-		WHERE course_id = ?
-	`
-	rows, err := r.db.Query(query, courseID)
+// CheckPrereqsSatisfied 检查学生是否满足课程的先修规则，不考虑同学期同时选修的课程
+func (r *SQLPrereqRepository) CheckPrereqsSatisfied(ctx context.Context, studentID, courseID string) (bool, error) {
+	result, err := r.EvaluatePrereqs(ctx, studentID, courseID, "", 0)
 	if err != nil {
-		return false, fmt.Errorf("error querying prereqs: %w", err)
+		return false, err
 	}
-	defer rows.Close()
+	return result.Satisfied, nil
+}
 
-	var prereqIDs []string
-	for rows.Next() {
-		var prereqID string
-		err := rows.Scan(&prereqID)
-		if err != nil {
-			return false, fmt.Errorf("error scanning prereq: %w", err)
+// EvaluatePrereqs 评估学生选修某课程时的先修规则，semester和year用于判断同时选修（coreq）的课程
+func (r *SQLPrereqRepository) EvaluatePrereqs(ctx context.Context, studentID string, courseID string, semester string, year int) (*model.PrereqCheckResult, error) {
+	rule, err := r.GetEffectiveRule(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 没有先修要求，则满足条件
+	if rule == nil {
+		return &model.PrereqCheckResult{Satisfied: true}, nil
+	}
+
+	record, err := r.loadAcademicRecord(ctx, studentID, semester, year)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule.Check(record), nil
+}
+
+// GetRule 获取课程保存的先修规则树
+func (r *SQLPrereqRepository) GetRule(ctx context.Context, courseID string) (*model.PrereqRule, error) {
+	query := `SELECT rule FROM prereq_rule WHERE course_id = ?`
+
+	var data string
+	err := r.db.QueryRowContext(ctx, query, courseID).Scan(&data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
 		}
-		prereqIDs = append(prereqIDs, prereqID)
+		return nil, fmt.Errorf("error querying prereq rule: %w", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return false, fmt.Errorf("error iterating prereqs: %w", err)
+	var rule model.PrereqRule
+	if err := json.Unmarshal([]byte(data), &rule); err != nil {
+		return nil, fmt.Errorf("error decoding prereq rule: %w", err)
 	}
 
-	// 如果没有先修课程，则满足条件
-	if len(prereqIDs) == 0 {
-		return true, nil
+	return &rule, nil
+}
+
+// GetEffectiveRule 获取课程生效的先修规则：优先使用规则树，没有规则树时由prereq表生成，都没有时返回nil
+func (r *SQLPrereqRepository) GetEffectiveRule(ctx context.Context, courseID string) (*model.PrereqRule, error) {
+	rule, err := r.GetRule(ctx, courseID)
+	if err == nil {
+		return rule, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
 	}
 
-	// 检查学生是否已经通过所有先修课程
-	for _, prereqID := range prereqIDs {
-		// 查询学生是否通过了该先修课程
-		query := `
-			SELECT COUNT(*) 
-			FROM takes 
-			WHERE student_id = ? AND course_id = ? AND grade IS NOT NULL AND grade <> 'F'
-		`
-		var count int
-		err := r.db.QueryRow(query, studentID, prereqID).Scan(&count)
-		if err != nil {
-			return false, fmt.Errorf("error checking prereq completion: %w", err)
+	prereqIDs, err := r.GetPrereqIDs(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.LegacyPrereqRule(prereqIDs), nil
+}
+
+// SaveRule 保存课程的先修规则树，已存在时覆盖
+func (r *SQLPrereqRepository) SaveRule(ctx context.Context, courseID string, rule *model.PrereqRule) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("error encoding prereq rule: %w", err)
+	}
+
+	query := `INSERT INTO prereq_rule (course_id, rule, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)` +
+		r.db.Dialect().Upsert([]string{"course_id"}, "rule = excluded.rule", "updated_at = CURRENT_TIMESTAMP")
+	if _, err := r.db.ExecContext(ctx, query, courseID, string(data)); err != nil {
+		return fmt.Errorf("error saving prereq rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRule 删除课程的先修规则树，之后回退到prereq表
+func (r *SQLPrereqRepository) DeleteRule(ctx context.Context, courseID string) error {
+	query := `DELETE FROM prereq_rule WHERE course_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, courseID)
+	if err != nil {
+		return fmt.Errorf("error deleting prereq rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// loadAcademicRecord 读取学生的总学分和选课记录，用于评估先修规则
+func (r *SQLPrereqRepository) loadAcademicRecord(ctx context.Context, studentID string, semester string, year int) (*model.AcademicRecord, error) {
+	var totCred float64
+	err := r.db.QueryRowContext(ctx, `SELECT tot_cred FROM student WHERE id = ?`, studentID).Scan(&totCred)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("student not found: %w", ErrNotFound)
 		}
+		return nil, fmt.Errorf("error querying student credits: %w", err)
+	}
+	record := model.NewAcademicRecord(totCred)
 
-		// 如果学生没有通过该先修课程，则不满足条件
-		if count == 0 {
-			return false, nil
+	query := `SELECT course_id, semester, year, grade FROM takes WHERE id = ?`
+	rows, err := r.db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying takes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var courseID, takesSemester string
+		var takesYear int
+		var grade sql.NullString
+		if err := rows.Scan(&courseID, &takesSemester, &takesYear, &grade); err != nil {
+			return nil, fmt.Errorf("error scanning takes: %w", err)
 		}
+
+		record.AddTakes(courseID, takesSemester, takesYear, grade.String, semester, year)
 	}
 
-	// 学生已通过所有先修课程
-	return true, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating takes: %w", err)
+	}
+
+	return record, nil
 }
 
-func (r *SQLPrereqRepository) GetPrereqIDs(courseID string) ([]string, error) {
+func (r *SQLPrereqRepository) GetPrereqIDs(ctx context.Context, courseID string) ([]string, error) {
 	query := `SELECT prereq_id FROM prereq WHERE course_id = ?`
 
-	rows, err := r.db.Query(query, courseID)
+	rows, err := r.db.QueryContext(ctx, query, courseID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying prereqs: %w", err)
 	}
@@ -181,10 +274,10 @@ func (r *SQLPrereqRepository) GetPrereqIDs(courseID string) ([]string, error) {
 	return prereqIDs, nil
 }
 
-func (r *SQLPrereqRepository) FindAll() ([]*model.Prereq, error) {
+func (r *SQLPrereqRepository) FindAll(ctx context.Context) ([]*model.Prereq, error) {
 	query := `SELECT course_id, prereq_id FROM prereq`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying prereqs: %w", err)
 	}
@@ -210,19 +303,42 @@ func (r *SQLPrereqRepository) FindAll() ([]*model.Prereq, error) {
 	return prereqs, nil
 }
 
+// prereqListTable 先修关系列表的查询方式，附带先修课程信息
+var prereqListTable = listTable{
+	fields:  model.PrereqListFields,
+	name:    "prereqs",
+	selects: `p.course_id, p.prereq_id, c.title, c.dept_name, c.credits`,
+	from:    `prereq p JOIN course c ON p.prereq_id = c.course_id`,
+	columns: map[string]string{"course_id": "p.course_id", "prereq_id": "p.prereq_id"},
+}
+
+// List 按分页、排序和过滤条件查找先修关系
+func (r *SQLPrereqRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Prereq], error) {
+	return list(ctx, r.db, prereqListTable, q, func(row rowScanner) (*model.Prereq, error) {
+		var prereq model.Prereq
+		var info model.Course
+		if err := row.Scan(&prereq.CourseID, &prereq.PrereqID, &info.Title, &info.Dept, &info.Credits); err != nil {
+			return nil, err
+		}
+		info.ID = prereq.PrereqID
+		prereq.PrereqInfo = &info
+		return &prereq, nil
+	})
+}
+
 // FindByID 根据课程ID和前置课程ID查找前置课程关系
-func (r *SQLPrereqRepository) FindByID(courseID string, prereqID string) (*model.Prereq, error) {
+func (r *SQLPrereqRepository) FindByID(ctx context.Context, courseID string, prereqID string) (*model.Prereq, error) {
 	query := `SELECT course_id, prereq_id FROM prereq WHERE course_id = ? AND prereq_id = ?`
 
 	var prereq model.Prereq
-	err := r.db.QueryRow(query, courseID, prereqID).Scan(
+	err := r.db.QueryRowContext(ctx, query, courseID, prereqID).Scan(
 		&prereq.CourseID,
 		&prereq.PrereqID,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("prerequisite relationship not found")
+			return nil, fmt.Errorf("prerequisite relationship not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error querying prerequisite: %w", err)
 	}
@@ -231,11 +347,11 @@ func (r *SQLPrereqRepository) FindByID(courseID string, prereqID string) (*model
 }
 
 // HasPrerequisite 检查是否存在先修课程关系
-func (r *SQLPrereqRepository) HasPrerequisite(courseID string, prereqID string) (bool, error) {
+func (r *SQLPrereqRepository) HasPrerequisite(ctx context.Context, courseID string, prereqID string) (bool, error) {
 	query := `SELECT 1 FROM prereq WHERE course_id = ? AND prereq_id = ?`
 
 	var exists int
-	err := r.db.QueryRow(query, courseID, prereqID).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, courseID, prereqID).Scan(&exists)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -1,7 +1,7 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 
 	"github.com/yourusername/student-management-system/internal/model"
@@ -9,23 +9,23 @@ import (
 
 // PrerequisiteRepository 定义先修课程仓库接口
 type PrerequisiteRepository interface {
-	FindByCourseID(courseID string) ([]*model.Prereq, error)
-	Create(prereq *model.Prereq) error
-	Delete(courseID, prereqID string) error
+	FindByCourseID(ctx context.Context, courseID string) ([]*model.Prereq, error)
+	Create(ctx context.Context, prereq *model.Prereq) error
+	Delete(ctx context.Context, courseID, prereqID string) error
 }
 
 // SQLPrerequisiteRepository 实现PrerequisiteRepository接口
 type SQLPrerequisiteRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewPrerequisiteRepository 创建先修课程仓库实例
-func NewPrerequisiteRepository(db *sql.DB) PrerequisiteRepository {
+func NewPrerequisiteRepository(db DBTX) PrerequisiteRepository {
 	return &SQLPrerequisiteRepository{db: db}
 }
 
 // FindByCourseID 根据课程ID查找先修课程
-func (r *SQLPrerequisiteRepository) FindByCourseID(courseID string) ([]*model.Prereq, error) {
+func (r *SQLPrerequisiteRepository) FindByCourseID(ctx context.Context, courseID string) ([]*model.Prereq, error) {
 	query := `
 		SELECT p.course_id, p.prereq_id, c.title, c.dept_name, c.credits
 		FROM prereq p
@@ -33,7 +33,7 @@ func (r *SQLPrerequisiteRepository) FindByCourseID(courseID string) ([]*model.Pr
 		WHERE p.course_id = ?
 	`
 
-	rows, err := r.db.Query(query, courseID)
+	rows, err := r.db.QueryContext(ctx, query, courseID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying prerequisites: %w", err)
 	}
@@ -69,10 +69,10 @@ func (r *SQLPrerequisiteRepository) FindByCourseID(courseID string) ([]*model.Pr
 }
 
 // Create 创建先修课程关系
-func (r *SQLPrerequisiteRepository) Create(prereq *model.Prereq) error {
+func (r *SQLPrerequisiteRepository) Create(ctx context.Context, prereq *model.Prereq) error {
 	query := `INSERT INTO prereq (course_id, prereq_id) VALUES (?, ?)`
 
-	_, err := r.db.Exec(query, prereq.CourseID, prereq.PrereqID)
+	_, err := r.db.ExecContext(ctx, query, prereq.CourseID, prereq.PrereqID)
 	if err != nil {
 		return fmt.Errorf("error creating prerequisite: %w", err)
 	}
@@ -81,10 +81,10 @@ func (r *SQLPrerequisiteRepository) Create(prereq *model.Prereq) error {
 }
 
 // Delete 删除先修课程关系
-func (r *SQLPrerequisiteRepository) Delete(courseID, prereqID string) error {
+func (r *SQLPrerequisiteRepository) Delete(ctx context.Context, courseID, prereqID string) error {
 	query := `DELETE FROM prereq WHERE course_id = ? AND prereq_id = ?`
 
-	_, err := r.db.Exec(query, courseID, prereqID)
+	_, err := r.db.ExecContext(ctx, query, courseID, prereqID)
 	if err != nil {
 		return fmt.Errorf("error deleting prerequisite: %w", err)
 	}
@@ -1,21 +1,30 @@
 package repository
 
-import "github.com/yourusername/student-management-system/internal/model"
+import (
+	"context"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// rowScanner 抽象 *sql.Row 和 *sql.Rows 共有的 Scan 方法
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
 // BaseRepository 定义基础仓储接口
 type BaseRepository[T any] interface {
-	GetByID(id string) (*T, error)
-	Create(entity *T) error
-	Update(entity *T) error
-	Delete(id string) error
-	List(page, pageSize int) ([]*T, int64, error)
-	ExistsByID(id string) (bool, error)
-	Search(query string) ([]*T, error)
+	GetByID(ctx context.Context, id string) (*T, error)
+	Create(ctx context.Context, entity *T) error
+	Update(ctx context.Context, entity *T) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[T], error)
+	ExistsByID(ctx context.Context, id string) (bool, error)
+	Search(ctx context.Context, query string) ([]*T, error)
 }
 
 // AuthRepository 定义认证相关的仓储接口
 type AuthRepository interface {
-	UpdatePassword(id, hashedPassword, salt string) error
+	UpdatePassword(ctx context.Context, id, hashedPassword, salt string, mustChange bool) error
 }
 
 // StudentRepository 定义学生仓储接口
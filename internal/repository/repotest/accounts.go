@@ -0,0 +1,442 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+func testAdmins(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.Admins == nil {
+		t.Skip("admin store not implemented")
+	}
+	r := s.Admins
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	root := &model.Admin{ID: "root", Name: "Root", Password: "hash", Status: model.AdminStatusActive, CreatedAt: at, UpdatedAt: at, MustChangePassword: true}
+	ops := &model.Admin{ID: "ops", Name: "Ops", Password: "hash", Status: model.AdminStatusActive, CreatedAt: at, UpdatedAt: at}
+	must(t, r.Create(ctx, root))
+	must(t, r.Create(ctx, ops))
+	expectErr(t, r.Create(ctx, root), repository.ErrDuplicate, "Create() duplicate")
+
+	got, err := r.FindByID(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Name != root.Name || got.Password != root.Password || got.Status != model.AdminStatusActive ||
+		!got.MustChangePassword || got.LastLoginAt != nil || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindByID() = %+v, expected %+v", got, root)
+	}
+
+	must(t, r.UpdatePassword(ctx, root.ID, "new-hash", false, at.Add(time.Hour)))
+	must(t, r.UpdateLastLogin(ctx, root.ID, at.Add(2*time.Hour)))
+	got, err = r.FindByID(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Password != "new-hash" || got.MustChangePassword || !got.UpdatedAt.Equal(at.Add(time.Hour)) ||
+		got.LastLoginAt == nil || !got.LastLoginAt.Equal(at.Add(2*time.Hour)) {
+		t.Errorf("FindByID() after updates = %+v", got)
+	}
+
+	must(t, r.UpdateStatus(ctx, ops.ID, model.AdminStatusDisabled, at.Add(time.Hour)))
+	if count, err := r.CountActive(ctx); err != nil || count != 1 {
+		t.Errorf("CountActive() = %d, %v, expected 1", count, err)
+	}
+
+	all, err := r.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID != ops.ID {
+		t.Errorf("FindAll() = %+v, expected ordered by id", all)
+	}
+
+	list, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"status": string(model.AdminStatusDisabled)}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 1 || len(list.Items) != 1 || list.Items[0].ID != ops.ID {
+		t.Errorf("List() = %+v, expected the disabled admin", list)
+	}
+
+	_, err = r.FindByID(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindByID() missing")
+	expectErr(t, r.UpdatePassword(ctx, "missing", "hash", false, at), repository.ErrNotFound, "UpdatePassword() missing")
+	expectErr(t, r.UpdateStatus(ctx, "missing", model.AdminStatusActive, at), repository.ErrNotFound, "UpdateStatus() missing")
+}
+
+func testSessions(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.Sessions == nil {
+		t.Skip("session store not implemented")
+	}
+	r := s.Sessions
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	newSession := func(id string) *model.Session {
+		return &model.Session{ID: id, UserID: "00128", Role: "student", Username: "00128", RefreshHash: "hash-" + id,
+			CreatedAt: at, LastUsedAt: at, ExpiresAt: at.Add(24 * time.Hour)}
+	}
+	first, second := newSession("s1"), newSession("s2")
+	first.PasswordChangeRequired = true
+	must(t, r.Create(ctx, first))
+	must(t, r.Create(ctx, second))
+	expectErr(t, r.Create(ctx, first), repository.ErrDuplicate, "Create() duplicate")
+
+	got, err := r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.UserID != first.UserID || got.RefreshHash != first.RefreshHash || !got.PasswordChangeRequired ||
+		!got.ExpiresAt.Equal(first.ExpiresAt) || got.RevokedAt != nil {
+		t.Errorf("FindByID() = %+v, expected %+v", got, first)
+	}
+
+	// 只有旧哈希仍然匹配时才能轮换，重放旧的刷新令牌会失败
+	usedAt := at.Add(time.Hour)
+	must(t, r.RotateRefreshHash(ctx, first.ID, "hash-s1", "hash-s1b", usedAt, usedAt.Add(24*time.Hour)))
+	expectErr(t, r.RotateRefreshHash(ctx, first.ID, "hash-s1", "hash-s1c", usedAt, usedAt), repository.ErrNotFound, "RotateRefreshHash() stale hash")
+	if rotated, err := r.WasRotated(ctx, first.ID, "hash-s1"); err != nil || !rotated {
+		t.Errorf("WasRotated(old hash) = %v, %v, expected true", rotated, err)
+	}
+	if rotated, err := r.WasRotated(ctx, first.ID, "hash-s1b"); err != nil || rotated {
+		t.Errorf("WasRotated(current hash) = %v, %v, expected false", rotated, err)
+	}
+	got, err = r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.RefreshHash != "hash-s1b" || !got.LastUsedAt.Equal(usedAt) || !got.ExpiresAt.Equal(usedAt.Add(24*time.Hour)) {
+		t.Errorf("FindByID() after rotation = %+v", got)
+	}
+
+	must(t, r.Revoke(ctx, first.ID, usedAt))
+	expectErr(t, r.RotateRefreshHash(ctx, first.ID, "hash-s1b", "hash-s1c", usedAt, usedAt), repository.ErrNotFound, "RotateRefreshHash() revoked")
+	got, err = r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.RevokedAt == nil || !got.RevokedAt.Equal(usedAt) {
+		t.Errorf("FindByID() after revoke = %+v", got)
+	}
+
+	if n, err := r.RevokeByUser(ctx, "00128", "student", usedAt); err != nil || n != 1 {
+		t.Errorf("RevokeByUser() = %d, %v, expected 1 remaining session revoked", n, err)
+	}
+	_, err = r.FindByID(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindByID() missing")
+}
+
+func testPasswordResets(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.PasswordResets == nil {
+		t.Skip("password reset store not implemented")
+	}
+	r := s.PasswordResets
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	newToken := func(id string) *model.PasswordResetToken {
+		return &model.PasswordResetToken{ID: id, UserID: "00128", Role: "student", TokenHash: "hash-" + id, CreatedAt: at, ExpiresAt: at.Add(time.Hour)}
+	}
+	first, second := newToken("p1"), newToken("p2")
+	must(t, r.Create(ctx, first))
+	must(t, r.Create(ctx, second))
+	expectErr(t, r.Create(ctx, first), repository.ErrDuplicate, "Create() duplicate")
+
+	got, err := r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.UserID != first.UserID || got.TokenHash != first.TokenHash || !got.ExpiresAt.Equal(first.ExpiresAt) || got.UsedAt != nil {
+		t.Errorf("FindByID() = %+v, expected %+v", got, first)
+	}
+
+	// 令牌只能使用一次
+	usedAt := at.Add(time.Minute)
+	must(t, r.MarkUsed(ctx, first.ID, usedAt))
+	expectErr(t, r.MarkUsed(ctx, first.ID, usedAt), repository.ErrNotFound, "MarkUsed() twice")
+
+	must(t, r.InvalidateByUser(ctx, "00128", "student", usedAt.Add(time.Minute)))
+	got, err = r.FindByID(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.UsedAt == nil || !got.UsedAt.Equal(usedAt.Add(time.Minute)) {
+		t.Errorf("FindByID() after InvalidateByUser = %+v", got)
+	}
+	got, err = r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.UsedAt == nil || !got.UsedAt.Equal(usedAt) {
+		t.Errorf("Expected InvalidateByUser to keep the original used_at, got %+v", got)
+	}
+
+	_, err = r.FindByID(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindByID() missing")
+}
+
+func testTwoFactor(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.TwoFactor == nil {
+		t.Skip("two factor store not implemented")
+	}
+	r := s.TwoFactor
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	_, err := r.Find(ctx, "10101", "instructor")
+	expectErr(t, err, repository.ErrNotFound, "Find() before setup")
+
+	// 重新开始绑定会覆盖尚未确认的密钥
+	must(t, r.SavePending(ctx, &model.TwoFactor{UserID: "10101", Role: "instructor", Secret: "old", CreatedAt: at}))
+	must(t, r.SavePending(ctx, &model.TwoFactor{UserID: "10101", Role: "instructor", Secret: "new", CreatedAt: at.Add(time.Minute)}))
+	got, err := r.Find(ctx, "10101", "instructor")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if got.Secret != "new" || got.Enabled || got.EnabledAt != nil {
+		t.Errorf("Find() after SavePending = %+v", got)
+	}
+
+	must(t, r.Enable(ctx, "10101", "instructor", 100, at.Add(time.Hour)))
+	expectErr(t, r.Enable(ctx, "10101", "instructor", 101, at), repository.ErrNotFound, "Enable() twice")
+	got, err = r.Find(ctx, "10101", "instructor")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if !got.Enabled || got.LastUsedStep != 100 || got.EnabledAt == nil || !got.EnabledAt.Equal(at.Add(time.Hour)) {
+		t.Errorf("Find() after Enable = %+v", got)
+	}
+
+	// 不晚于上次使用的时间步视为重放
+	expectErr(t, r.UseStep(ctx, "10101", "instructor", 100), repository.ErrNotFound, "UseStep() replay")
+	must(t, r.UseStep(ctx, "10101", "instructor", 101))
+
+	must(t, r.ReplaceRecoveryCodes(ctx, "10101", "instructor", []string{"c1", "c2", "c3"}))
+	must(t, r.ReplaceRecoveryCodes(ctx, "10101", "instructor", []string{"c4", "c5"}))
+	expectErr(t, r.UseRecoveryCode(ctx, "10101", "instructor", "c1", at), repository.ErrNotFound, "UseRecoveryCode() replaced code")
+	must(t, r.UseRecoveryCode(ctx, "10101", "instructor", "c4", at))
+	expectErr(t, r.UseRecoveryCode(ctx, "10101", "instructor", "c4", at), repository.ErrNotFound, "UseRecoveryCode() twice")
+	if count, err := r.CountRecoveryCodes(ctx, "10101", "instructor"); err != nil || count != 1 {
+		t.Errorf("CountRecoveryCodes() = %d, %v, expected 1", count, err)
+	}
+
+	challenge := &model.TwoFactorChallenge{ID: "c1", TokenHash: "hash", UserID: "10101", Role: "instructor", Username: "10101",
+		CreatedAt: at, ExpiresAt: at.Add(5 * time.Minute)}
+	must(t, r.CreateChallenge(ctx, challenge))
+	must(t, r.IncrementChallengeAttempts(ctx, challenge.ID))
+	must(t, r.IncrementChallengeAttempts(ctx, challenge.ID))
+	must(t, r.MarkChallengeUsed(ctx, challenge.ID, at.Add(time.Minute)))
+	expectErr(t, r.MarkChallengeUsed(ctx, challenge.ID, at.Add(time.Minute)), repository.ErrNotFound, "MarkChallengeUsed() twice")
+	gotChallenge, err := r.FindChallenge(ctx, challenge.ID)
+	if err != nil {
+		t.Fatalf("FindChallenge() error = %v", err)
+	}
+	if gotChallenge.Attempts != 2 || gotChallenge.Username != challenge.Username || !gotChallenge.ExpiresAt.Equal(challenge.ExpiresAt) ||
+		gotChallenge.UsedAt == nil || !gotChallenge.UsedAt.Equal(at.Add(time.Minute)) {
+		t.Errorf("FindChallenge() = %+v", gotChallenge)
+	}
+	_, err = r.FindChallenge(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindChallenge() missing")
+
+	must(t, r.SavePolicy(ctx, &model.TwoFactorPolicy{Role: "admin", Required: true}))
+	must(t, r.SavePolicy(ctx, &model.TwoFactorPolicy{Role: "admin", Required: false}))
+	must(t, r.SavePolicy(ctx, &model.TwoFactorPolicy{Role: "instructor", Required: true}))
+	policies, err := r.FindPolicies(ctx)
+	if err != nil {
+		t.Fatalf("FindPolicies() error = %v", err)
+	}
+	if len(policies) != 2 || policies[0].Role != "admin" || policies[0].Required || !policies[1].Required {
+		t.Errorf("FindPolicies() = %+v", policies)
+	}
+
+	must(t, r.Delete(ctx, "10101", "instructor"))
+	_, err = r.Find(ctx, "10101", "instructor")
+	expectErr(t, err, repository.ErrNotFound, "Find() after delete")
+}
+
+func testAudit(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.Audit == nil {
+		t.Skip("audit store not implemented")
+	}
+	r := s.Audit
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	create := &model.AuditEntry{ActorID: "root", ActorRole: "admin", Action: model.AuditActionCreate, EntityType: model.AuditEntityInstructor,
+		EntityKey: "10101", After: json.RawMessage(`{"salary":65000}`), CreatedAt: at}
+	update := &model.AuditEntry{ActorID: "root", ActorRole: "admin", Action: model.AuditActionUpdate, EntityType: model.AuditEntityInstructor,
+		EntityKey: "10101", Before: json.RawMessage(`{"salary":65000}`), After: json.RawMessage(`{"salary":70000}`), CreatedAt: at.Add(time.Hour)}
+	other := &model.AuditEntry{ActorID: "10101", ActorRole: "instructor", Action: model.AuditActionUpdate, EntityType: model.AuditEntityEnrollment,
+		EntityKey: "00128", CreatedAt: at.Add(2 * time.Hour)}
+	for _, entry := range []*model.AuditEntry{create, update, other} {
+		must(t, r.Create(ctx, entry))
+	}
+	if create.ID == 0 || update.ID <= create.ID || other.ID <= update.ID {
+		t.Errorf("Expected increasing IDs, got %d, %d and %d", create.ID, update.ID, other.ID)
+	}
+
+	entries, err := r.Find(ctx, model.AuditFilter{EntityType: model.AuditEntityInstructor, EntityKey: "10101"})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != update.ID || !entries[0].CreatedAt.Equal(update.CreatedAt) {
+		t.Fatalf("Find() = %+v, expected newest first", entries)
+	}
+	// JSON列可能重新格式化内容，按值比较
+	var before, after map[string]float64
+	if err := json.Unmarshal(entries[0].Before, &before); err != nil {
+		t.Fatalf("Unmarshal(before) error = %v", err)
+	}
+	if err := json.Unmarshal(entries[0].After, &after); err != nil {
+		t.Fatalf("Unmarshal(after) error = %v", err)
+	}
+	if before["salary"] != 65000 || after["salary"] != 70000 {
+		t.Errorf("Expected salary 65000 -> 70000, got %v -> %v", before, after)
+	}
+	if entries[1].Before != nil {
+		t.Errorf("Expected empty before value for create, got %s", entries[1].Before)
+	}
+
+	from, to := at.Add(30*time.Minute), at.Add(2*time.Hour)
+	entries, err = r.Find(ctx, model.AuditFilter{From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != update.ID {
+		t.Errorf("Find() with time range = %+v, expected only the update", entries)
+	}
+	if entries, err := r.Find(ctx, model.AuditFilter{Limit: 1}); err != nil || len(entries) != 1 || entries[0].ID != other.ID {
+		t.Errorf("Find() with limit = %+v, %v, expected only the newest entry", entries, err)
+	}
+
+	list, err := r.List(ctx, model.AuditFilter{ActorID: "root"}, &model.ListQuery{PageSize: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 2 || len(list.Items) != 1 {
+		t.Errorf("List() = %+v, expected one of two entries", list)
+	}
+}
+
+func testAPIKeys(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.APIKeys == nil {
+		t.Skip("api key store not implemented")
+	}
+	r := s.APIKeys
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	expiresAt := at.Add(30 * 24 * time.Hour)
+	global := &model.APIKey{ID: "k1", Name: "Reporting", KeyHash: "hash-k1", Permissions: []model.Permission{model.PermStudentsManage, model.PermCoursesRead},
+		CreatedBy: "root", CreatedAt: at}
+	scoped := &model.APIKey{ID: "k2", Name: "Dept sync", KeyHash: "hash-k2", Permissions: []model.Permission{model.PermCoursesRead},
+		Dept: f.Dept.DeptName, CreatedBy: "root", CreatedAt: at.Add(time.Hour), ExpiresAt: &expiresAt}
+	must(t, r.Create(ctx, global))
+	must(t, r.Create(ctx, scoped))
+	expectErr(t, r.Create(ctx, global), repository.ErrDuplicate, "Create() duplicate")
+
+	got, err := r.FindByID(ctx, global.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Name != global.Name || got.KeyHash != global.KeyHash || got.Dept != "" || len(got.Permissions) != 2 ||
+		got.ExpiresAt != nil || got.LastUsedAt != nil || got.RevokedAt != nil || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindByID() = %+v, expected %+v", got, global)
+	}
+
+	list, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"dept_name": f.Dept.DeptName}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 1 || len(list.Items) != 1 || list.Items[0].ID != scoped.ID || len(list.Items[0].Permissions) != 1 ||
+		list.Items[0].ExpiresAt == nil || !list.Items[0].ExpiresAt.Equal(expiresAt) {
+		t.Errorf("List() = %+v, expected the department key", list)
+	}
+
+	must(t, r.TouchLastUsed(ctx, global.ID, at.Add(time.Minute)))
+	must(t, r.Revoke(ctx, global.ID, at.Add(time.Hour)))
+	expectErr(t, r.Revoke(ctx, global.ID, at.Add(time.Hour)), repository.ErrNotFound, "Revoke() twice")
+	got, err = r.FindByID(ctx, global.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(at.Add(time.Minute)) || got.RevokedAt == nil || !got.RevokedAt.Equal(at.Add(time.Hour)) {
+		t.Errorf("FindByID() after use and revoke = %+v", got)
+	}
+
+	_, err = r.FindByID(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindByID() missing")
+}
+
+func testOIDC(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.OIDC == nil {
+		t.Skip("oidc store not implemented")
+	}
+	r := s.OIDC
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	state := &model.OIDCLoginState{ID: "st1", StateHash: "hash", Nonce: "nonce", CodeVerifier: "verifier", CreatedAt: at, ExpiresAt: at.Add(10 * time.Minute)}
+	must(t, r.CreateState(ctx, state))
+	must(t, r.MarkStateUsed(ctx, state.ID, at.Add(time.Minute)))
+	expectErr(t, r.MarkStateUsed(ctx, state.ID, at.Add(time.Minute)), repository.ErrNotFound, "MarkStateUsed() twice")
+	gotState, err := r.FindState(ctx, state.ID)
+	if err != nil {
+		t.Fatalf("FindState() error = %v", err)
+	}
+	if gotState.Nonce != state.Nonce || gotState.CodeVerifier != state.CodeVerifier || !gotState.ExpiresAt.Equal(state.ExpiresAt) ||
+		gotState.UsedAt == nil || !gotState.UsedAt.Equal(at.Add(time.Minute)) {
+		t.Errorf("FindState() = %+v, expected %+v", gotState, state)
+	}
+	_, err = r.FindState(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindState() missing")
+
+	// 只按邮箱预先绑定的账号在第一次登录时记录subject
+	byEmail := &model.OIDCIdentity{Role: "student", UserID: "00128", Email: "zhang@example.edu", CreatedAt: at}
+	bySubject := &model.OIDCIdentity{Role: "instructor", UserID: "10101", Subject: "sub-10101", CreatedAt: at}
+	must(t, r.CreateIdentity(ctx, byEmail))
+	must(t, r.CreateIdentity(ctx, bySubject))
+	expectErr(t, r.CreateIdentity(ctx, &model.OIDCIdentity{Role: "student", UserID: "12345", Email: byEmail.Email, CreatedAt: at}),
+		repository.ErrDuplicate, "CreateIdentity() duplicate email")
+
+	got, err := r.FindIdentityByEmail(ctx, "student", byEmail.Email)
+	if err != nil {
+		t.Fatalf("FindIdentityByEmail() error = %v", err)
+	}
+	if got.UserID != byEmail.UserID || got.Subject != "" || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindIdentityByEmail() = %+v, expected %+v", got, byEmail)
+	}
+	_, err = r.FindIdentityByEmail(ctx, "instructor", byEmail.Email)
+	expectErr(t, err, repository.ErrNotFound, "FindIdentityByEmail() other role")
+
+	must(t, r.BindSubject(ctx, "student", "00128", "sub-00128"))
+	expectErr(t, r.BindSubject(ctx, "student", "00128", "sub-other"), repository.ErrNotFound, "BindSubject() twice")
+	got, err = r.FindIdentityBySubject(ctx, "student", "sub-00128")
+	if err != nil {
+		t.Fatalf("FindIdentityBySubject() error = %v", err)
+	}
+	if got.UserID != "00128" || got.Email != byEmail.Email {
+		t.Errorf("FindIdentityBySubject() = %+v", got)
+	}
+
+	list, err := r.ListIdentities(ctx, &model.ListQuery{Filters: map[string]string{"role": "instructor"}})
+	if err != nil {
+		t.Fatalf("ListIdentities() error = %v", err)
+	}
+	if list.Total != 1 || len(list.Items) != 1 || list.Items[0].Subject != bySubject.Subject {
+		t.Errorf("ListIdentities() = %+v, expected the instructor identity", list)
+	}
+
+	must(t, r.DeleteIdentity(ctx, "instructor", "10101"))
+	_, err = r.FindIdentity(ctx, "instructor", "10101")
+	expectErr(t, err, repository.ErrNotFound, "FindIdentity() after delete")
+	expectErr(t, r.DeleteIdentity(ctx, "instructor", "10101"), repository.ErrNotFound, "DeleteIdentity() missing")
+}
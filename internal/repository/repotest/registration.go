@@ -0,0 +1,381 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// secondStudent 写入与fixture同系的第二个学生
+func secondStudent(t *testing.T, s *Stores, f *fixture) *model.Student {
+	t.Helper()
+	student := &model.Student{ID: "12345", Name: "Shankar", Dept: f.Dept.DeptName, TotCred: 32, Password: "hash", Salt: "salt"}
+	must(t, s.Students.Create(context.Background(), student))
+	return student
+}
+
+func testWaitlist(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Waitlist == nil {
+		t.Skip("waitlist store not implemented")
+	}
+	r := s.Waitlist
+	other := secondStudent(t, s, f)
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	newEntry := func(id, studentID string) *model.WaitlistEntry {
+		return &model.WaitlistEntry{ID: id, StudentID: studentID, CourseID: f.Key.CourseID, SectionID: f.Key.SecID,
+			Semester: f.Key.Semester, Year: f.Key.Year, Status: model.EnrollmentStatusWaiting, CreatedAt: at, UpdatedAt: at}
+	}
+	first, second := newEntry("w1", f.Student.ID), newEntry("w2", other.ID)
+	must(t, r.Create(ctx, first))
+	must(t, r.Create(ctx, second))
+	if first.QueueNo != 1 || second.QueueNo != 2 {
+		t.Errorf("Expected queue numbers 1 and 2, got %d and %d", first.QueueNo, second.QueueNo)
+	}
+
+	missing := newEntry("w3", f.Student.ID)
+	missing.SectionID = "9"
+	expectErr(t, r.Create(ctx, missing), repository.ErrNotFound, "Create() unknown section")
+
+	bySection, err := r.FindBySection(ctx, f.Key)
+	if err != nil {
+		t.Fatalf("FindBySection() error = %v", err)
+	}
+	if len(bySection) != 2 || bySection[0].ID != first.ID || bySection[0].Position != 1 || bySection[1].Position != 2 {
+		t.Errorf("FindBySection() = %+v, expected w1 then w2", bySection)
+	}
+
+	got, err := r.FindWaitingByStudentAndSection(ctx, other.ID, f.Key)
+	if err != nil {
+		t.Fatalf("FindWaitingByStudentAndSection() error = %v", err)
+	}
+	if got.ID != second.ID || got.Position != 2 || got.Status != model.EnrollmentStatusWaiting || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindWaitingByStudentAndSection() = %+v, expected %+v at position 2", got, second)
+	}
+
+	// 前面的学生转正后，后面的学生位置前移
+	must(t, r.UpdateStatus(ctx, first.ID, model.EnrollmentStatusActive))
+	if position, err := r.GetPosition(ctx, second.ID); err != nil || position != 1 {
+		t.Errorf("GetPosition() = %d, %v, expected 1", position, err)
+	}
+	if position, err := r.GetPosition(ctx, first.ID); err != nil || position != 0 {
+		t.Errorf("GetPosition() of promoted entry = %d, %v, expected 0", position, err)
+	}
+	_, err = r.FindWaitingByStudentAndSection(ctx, f.Student.ID, f.Key)
+	expectErr(t, err, repository.ErrNotFound, "FindWaitingByStudentAndSection() after promotion")
+
+	promoted, err := r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if promoted.Status != model.EnrollmentStatusActive {
+		t.Errorf("FindByID() status = %q, expected %q", promoted.Status, model.EnrollmentStatusActive)
+	}
+
+	byStudent, err := r.FindByStudentID(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("FindByStudentID() error = %v", err)
+	}
+	if len(byStudent) != 1 || byStudent[0].ID != second.ID || byStudent[0].Position != 1 {
+		t.Errorf("FindByStudentID() = %+v, expected w2 at position 1", byStudent)
+	}
+
+	// 重新入队的序号排在所有已有记录之后
+	again := newEntry("w4", f.Student.ID)
+	must(t, r.Create(ctx, again))
+	if again.QueueNo != 3 {
+		t.Errorf("Expected queue number 3, got %d", again.QueueNo)
+	}
+
+	_, err = r.FindByID(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindByID() missing")
+	expectErr(t, r.UpdateStatus(ctx, "missing", model.EnrollmentStatusDropped), repository.ErrNotFound, "UpdateStatus() missing")
+}
+
+func testTerms(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.Terms == nil {
+		t.Skip("term store not implemented")
+	}
+	r := s.Terms
+
+	day := func(d int) time.Time { return time.Date(2024, time.September, d, 8, 0, 0, 0, time.UTC) }
+	fall := &model.Term{Semester: "Fall", Year: 2024, RegistrationOpen: day(1), RegistrationClose: day(10),
+		AddDropDeadline: day(15), WithdrawalDeadline: day(25), MinCredits: 12, MaxCredits: 18}
+	spring := &model.Term{Semester: "Spring", Year: 2025, RegistrationOpen: day(20), RegistrationClose: day(28),
+		AddDropDeadline: day(29), WithdrawalDeadline: day(30)}
+	must(t, r.Create(ctx, fall))
+	must(t, r.Create(ctx, spring))
+	expectErr(t, r.Create(ctx, fall), repository.ErrDuplicate, "Create() duplicate")
+
+	got, err := r.FindByTerm(ctx, "Fall", 2024)
+	if err != nil {
+		t.Fatalf("FindByTerm() error = %v", err)
+	}
+	if !got.RegistrationOpen.Equal(fall.RegistrationOpen) || !got.WithdrawalDeadline.Equal(fall.WithdrawalDeadline) ||
+		got.MinCredits != fall.MinCredits || got.MaxCredits != fall.MaxCredits {
+		t.Errorf("FindByTerm() = %+v, expected %+v", got, fall)
+	}
+
+	all, err := r.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].Year != 2025 {
+		t.Errorf("FindAll() = %+v, expected newest term first", all)
+	}
+
+	list, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"year": "2024"}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 1 || len(list.Items) != 1 || list.Items[0].Semester != "Fall" {
+		t.Errorf("List() = %+v, expected Fall 2024", list)
+	}
+
+	fall.MaxCredits = 21
+	fall.AddDropDeadline = day(16)
+	must(t, r.Update(ctx, fall))
+	got, err = r.FindByTerm(ctx, "Fall", 2024)
+	if err != nil {
+		t.Fatalf("FindByTerm() error = %v", err)
+	}
+	if got.MaxCredits != 21 || !got.AddDropDeadline.Equal(day(16)) {
+		t.Errorf("FindByTerm() after update = %+v", got)
+	}
+
+	must(t, r.Delete(ctx, "Fall", 2024))
+	_, err = r.FindByTerm(ctx, "Fall", 2024)
+	expectErr(t, err, repository.ErrNotFound, "FindByTerm() after delete")
+	expectErr(t, r.Delete(ctx, "Fall", 2024), repository.ErrNotFound, "Delete() missing")
+	expectErr(t, r.Update(ctx, fall), repository.ErrNotFound, "Update() missing")
+}
+
+func testTimeTickets(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Tickets == nil {
+		t.Skip("time ticket store not implemented")
+	}
+	r := s.Tickets
+	other := secondStudent(t, s, f)
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	senior := &model.TimeTicket{StudentID: f.Student.ID, Semester: "Fall", Year: 2024, Tier: 1, StartTime: at}
+	junior := &model.TimeTicket{StudentID: other.ID, Semester: "Fall", Year: 2024, Tier: 2, StartTime: at.Add(time.Hour)}
+	must(t, r.Create(ctx, senior))
+	must(t, r.Create(ctx, junior))
+	if err := r.Create(ctx, senior); err == nil {
+		t.Error("Create() duplicate: expected an error")
+	}
+
+	got, err := r.FindByStudentAndTerm(ctx, other.ID, "Fall", 2024)
+	if err != nil {
+		t.Fatalf("FindByStudentAndTerm() error = %v", err)
+	}
+	if got.Tier != 2 || !got.StartTime.Equal(junior.StartTime) {
+		t.Errorf("FindByStudentAndTerm() = %+v, expected %+v", got, junior)
+	}
+	_, err = r.FindByStudentAndTerm(ctx, other.ID, "Spring", 2025)
+	expectErr(t, err, repository.ErrNotFound, "FindByStudentAndTerm() other term")
+
+	byTerm, err := r.FindByTerm(ctx, "Fall", 2024)
+	if err != nil {
+		t.Fatalf("FindByTerm() error = %v", err)
+	}
+	if len(byTerm) != 2 || byTerm[0].StudentID != f.Student.ID || byTerm[0].Student == nil || byTerm[0].Student.Name != f.Student.Name {
+		t.Errorf("FindByTerm() = %+v, expected tier 1 first with student info", byTerm)
+	}
+
+	list, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"tier": "2"}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 1 || len(list.Items) != 1 || list.Items[0].StudentID != other.ID {
+		t.Errorf("List() = %+v, expected the tier 2 ticket", list)
+	}
+
+	must(t, r.DeleteByTerm(ctx, "Fall", 2024))
+	if byTerm, err := r.FindByTerm(ctx, "Fall", 2024); err != nil || len(byTerm) != 0 {
+		t.Errorf("FindByTerm() after DeleteByTerm = %+v, %v", byTerm, err)
+	}
+}
+
+func testOverloads(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Overloads == nil {
+		t.Skip("overload store not implemented")
+	}
+	r := s.Overloads
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	fall := &model.OverloadRequest{ID: "o1", StudentID: f.Student.ID, Semester: "Fall", Year: 2024, RequestedCredits: 21,
+		Reason: "Graduating", Status: model.OverloadStatusPending, CreatedAt: at}
+	spring := &model.OverloadRequest{ID: "o2", StudentID: f.Student.ID, Semester: "Spring", Year: 2025, RequestedCredits: 20,
+		Reason: "Double major", Status: model.OverloadStatusPending, CreatedAt: at.Add(time.Hour)}
+	must(t, r.Create(ctx, fall))
+	must(t, r.Create(ctx, spring))
+	expectErr(t, r.Create(ctx, fall), repository.ErrDuplicate, "Create() duplicate")
+
+	got, err := r.FindByID(ctx, fall.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.RequestedCredits != 21 || got.Status != model.OverloadStatusPending || got.DecidedAt != nil || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindByID() = %+v, expected %+v", got, fall)
+	}
+
+	byStudent, err := r.FindByStudentID(ctx, f.Student.ID)
+	if err != nil {
+		t.Fatalf("FindByStudentID() error = %v", err)
+	}
+	if len(byStudent) != 2 || byStudent[0].ID != spring.ID {
+		t.Errorf("FindByStudentID() = %+v, expected newest first", byStudent)
+	}
+	byTerm, err := r.FindByStudentAndTerm(ctx, f.Student.ID, "Fall", 2024)
+	if err != nil {
+		t.Fatalf("FindByStudentAndTerm() error = %v", err)
+	}
+	if len(byTerm) != 1 || byTerm[0].ID != fall.ID {
+		t.Errorf("FindByStudentAndTerm() = %+v, expected %s", byTerm, fall.ID)
+	}
+
+	decidedAt := at.Add(24 * time.Hour)
+	fall.Status = model.OverloadStatusApproved
+	fall.AdvisorID = f.Instructor.ID
+	fall.DecisionNote = "OK"
+	fall.DecidedAt = &decidedAt
+	must(t, r.UpdateDecision(ctx, fall))
+	got, err = r.FindByID(ctx, fall.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Status != model.OverloadStatusApproved || got.AdvisorID != f.Instructor.ID || got.DecidedAt == nil || !got.DecidedAt.Equal(decidedAt) {
+		t.Errorf("FindByID() after decision = %+v", got)
+	}
+
+	// 只有等待中的申请可以审批
+	fall.Status = model.OverloadStatusDenied
+	expectErr(t, r.UpdateDecision(ctx, fall), repository.ErrNotFound, "UpdateDecision() already decided")
+	_, err = r.FindByID(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindByID() missing")
+}
+
+func testCarts(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Carts == nil {
+		t.Skip("cart store not implemented")
+	}
+	r := s.Carts
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	item := &model.CartItem{StudentID: f.Student.ID, CourseID: f.Key.CourseID, SectionID: f.Key.SecID,
+		Semester: f.Key.Semester, Year: f.Key.Year, AddedAt: at}
+	must(t, r.Create(ctx, item))
+	expectErr(t, r.Create(ctx, item), repository.ErrDuplicate, "Create() duplicate")
+	unknown := *item
+	unknown.SectionID = "9"
+	expectErr(t, r.Create(ctx, &unknown), repository.ErrNotFound, "Create() unknown section")
+
+	got, err := r.FindByStudentAndSection(ctx, f.Student.ID, f.Key)
+	if err != nil {
+		t.Fatalf("FindByStudentAndSection() error = %v", err)
+	}
+	if got.Key() != f.Key || !got.AddedAt.Equal(at) {
+		t.Errorf("FindByStudentAndSection() = %+v, expected %+v", got, item)
+	}
+
+	items, err := r.FindByStudentID(ctx, f.Student.ID)
+	if err != nil {
+		t.Fatalf("FindByStudentID() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Course == nil || items[0].Course.Title != f.Course.Title || items[0].Course.Credits != f.Course.Credits {
+		t.Errorf("FindByStudentID() = %+v, expected one item with course info", items)
+	}
+
+	must(t, r.Delete(ctx, f.Student.ID, f.Key))
+	_, err = r.FindByStudentAndSection(ctx, f.Student.ID, f.Key)
+	expectErr(t, err, repository.ErrNotFound, "FindByStudentAndSection() after delete")
+	expectErr(t, r.Delete(ctx, f.Student.ID, f.Key), repository.ErrNotFound, "Delete() missing")
+}
+
+func testOverrides(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Overrides == nil {
+		t.Skip("override store not implemented")
+	}
+	r := s.Overrides
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	newOverride := func(id, code string) *model.RegistrationOverride {
+		return &model.RegistrationOverride{ID: id, StudentID: f.Student.ID, CourseID: f.Key.CourseID, SectionID: f.Key.SecID,
+			Semester: f.Key.Semester, Year: f.Key.Year, Waives: []model.EligibilityReasonCode{model.ReasonSectionFull, model.ReasonPrereqsNotSatisfied},
+			Code: code, GrantedBy: f.Instructor.ID, Reason: "Senior", Status: model.OverrideStatusActive, CreatedAt: at}
+	}
+	direct, coded := newOverride("r1", ""), newOverride("r2", "PERMIT42")
+	must(t, r.Create(ctx, direct))
+	must(t, r.Create(ctx, coded))
+	expectErr(t, r.Create(ctx, newOverride("r3", "PERMIT42")), repository.ErrDuplicate, "Create() duplicate code")
+
+	got, err := r.FindByID(ctx, direct.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.StudentID != f.Student.ID || got.Code != "" || len(got.Waives) != 2 || !got.Waived(model.ReasonPrereqsNotSatisfied) ||
+		got.Status != model.OverrideStatusActive || got.UsedAt != nil || !got.CreatedAt.Equal(at) {
+		t.Errorf("FindByID() = %+v, expected %+v", got, direct)
+	}
+
+	byCode, err := r.FindByCode(ctx, "PERMIT42")
+	if err != nil {
+		t.Fatalf("FindByCode() error = %v", err)
+	}
+	if byCode.ID != coded.ID || byCode.Code != coded.Code {
+		t.Errorf("FindByCode() = %+v, expected %+v", byCode, coded)
+	}
+
+	// 许可码只在选课时出示才生效，不会作为直接生效的特许返回
+	active, err := r.FindActiveByStudentAndSection(ctx, f.Student.ID, f.Key)
+	if err != nil {
+		t.Fatalf("FindActiveByStudentAndSection() error = %v", err)
+	}
+	if len(active) != 1 || active[0].ID != direct.ID {
+		t.Errorf("FindActiveByStudentAndSection() = %+v, expected %s", active, direct.ID)
+	}
+
+	usedAt := at.Add(time.Hour)
+	must(t, r.MarkUsed(ctx, direct.ID, usedAt))
+	expectErr(t, r.MarkUsed(ctx, direct.ID, usedAt), repository.ErrNotFound, "MarkUsed() twice")
+	got, err = r.FindByID(ctx, direct.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Status != model.OverrideStatusUsed || got.UsedAt == nil || !got.UsedAt.Equal(usedAt) {
+		t.Errorf("FindByID() after MarkUsed = %+v", got)
+	}
+	if active, err := r.FindActiveByStudentAndSection(ctx, f.Student.ID, f.Key); err != nil || len(active) != 0 {
+		t.Errorf("FindActiveByStudentAndSection() after MarkUsed = %+v, %v", active, err)
+	}
+
+	must(t, r.Revoke(ctx, coded.ID))
+	expectErr(t, r.Revoke(ctx, coded.ID), repository.ErrNotFound, "Revoke() twice")
+
+	bySection, err := r.FindBySection(ctx, f.Key)
+	if err != nil {
+		t.Fatalf("FindBySection() error = %v", err)
+	}
+	if len(bySection) != 2 {
+		t.Errorf("FindBySection() = %+v, expected both overrides", bySection)
+	}
+
+	_, err = r.FindByCode(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound, "FindByCode() missing")
+}
@@ -0,0 +1,902 @@
+// Package repotest 提供仓储接口的一致性测试套件，同一组用例可以分别运行在
+// 各数据库方言的SQL实现和其他实现上，保证它们的行为一致。
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// Stores 被测的一组仓储，未实现的仓储留空，依赖它的用例会被跳过
+type Stores struct {
+	Students       repository.StudentRepository
+	Instructors    repository.InstructorRepository
+	Departments    repository.DepartmentRepository
+	Courses        repository.CourseRepository
+	Classrooms     repository.ClassroomRepository
+	TimeSlots      repository.TimeSlotRepository
+	Sections       repository.SectionRepository
+	Takes          repository.TakesRepository
+	Teaches        repository.TeachesRepository
+	Advisors       repository.AdvisorRepository
+	Prereqs        repository.PrereqRepository
+	LoginAttempts  repository.LoginAttemptRepository
+	Roles          repository.RoleRepository
+	Waitlist       repository.WaitlistRepository
+	Terms          repository.TermRepository
+	Tickets        repository.TimeTicketRepository
+	Overloads      repository.OverloadRepository
+	Carts          repository.CartRepository
+	Overrides      repository.OverrideRepository
+	Admins         repository.AdminRepository
+	Sessions       repository.SessionRepository
+	PasswordResets repository.PasswordResetRepository
+	TwoFactor      repository.TwoFactorRepository
+	Audit          repository.AuditRepository
+	APIKeys        repository.APIKeyRepository
+	OIDC           repository.OIDCRepository
+}
+
+// Factory 为每个用例创建一组共享同一份空数据的仓储
+type Factory func(t *testing.T) *Stores
+
+// Run 对newStores创建的仓储运行全部一致性用例
+func Run(t *testing.T, newStores Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s *Stores)
+	}{
+		{"Students", testStudents},
+		{"Instructors", testInstructors},
+		{"Departments", testDepartments},
+		{"Courses", testCourses},
+		{"Classrooms", testClassrooms},
+		{"TimeSlots", testTimeSlots},
+		{"Sections", testSections},
+		{"Takes", testTakes},
+		{"Teaches", testTeaches},
+		{"Advisors", testAdvisors},
+		{"Prereqs", testPrereqs},
+		{"LoginAttempts", testLoginAttempts},
+		{"Roles", testRoles},
+		{"Waitlist", testWaitlist},
+		{"Terms", testTerms},
+		{"TimeTickets", testTimeTickets},
+		{"Overloads", testOverloads},
+		{"Carts", testCarts},
+		{"Overrides", testOverrides},
+		{"Admins", testAdmins},
+		{"Sessions", testSessions},
+		{"PasswordResets", testPasswordResets},
+		{"TwoFactor", testTwoFactor},
+		{"Audit", testAudit},
+		{"APIKeys", testAPIKeys},
+		{"OIDC", testOIDC},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStores(t))
+		})
+	}
+}
+
+// fixture 是seed写入的基础数据
+type fixture struct {
+	Dept       *model.Department
+	Instructor *model.Instructor
+	Student    *model.Student
+	Course     *model.Course
+	Course2    *model.Course
+	Classroom  *model.Classroom
+	Classroom2 *model.Classroom
+	TimeSlot   *model.TimeSlot
+	Section    *model.Section
+	Key        model.SectionKey
+}
+
+// seedStoresPresent 判断seed需要的仓储是否都已实现
+func seedStoresPresent(s *Stores) bool {
+	return s.Departments != nil && s.Instructors != nil && s.Students != nil && s.Courses != nil &&
+		s.Classrooms != nil && s.TimeSlots != nil && s.Sections != nil
+}
+
+// seed 写入院系、教师、学生、课程、教室、时间段和一个课程段
+func seed(t *testing.T, s *Stores) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	if !seedStoresPresent(s) {
+		t.Skip("seed requires department, instructor, student, course, classroom, time slot and section stores")
+	}
+
+	f := &fixture{
+		Dept:       &model.Department{DeptName: "Comp. Sci.", Building: "Taylor", Budget: 100000},
+		Instructor: &model.Instructor{ID: "10101", Name: "Srinivasan", Dept: "Comp. Sci.", Salary: 65000, Password: "hash", Salt: "salt"},
+		Student:    &model.Student{ID: "00128", Name: "Zhang", Dept: "Comp. Sci.", TotCred: 102, Password: "hash", Salt: "salt"},
+		Course:     &model.Course{ID: "CS-101", Title: "Intro. to Computer Science", Dept: "Comp. Sci.", Credits: 4},
+		Course2:    &model.Course{ID: "CS-190", Title: "Game Design", Dept: "Comp. Sci.", Credits: 4},
+		Classroom:  &model.Classroom{Building: "Packard", RoomNumber: "101", Capacity: 500},
+		Classroom2: &model.Classroom{Building: "Taylor", RoomNumber: "3128", Capacity: 70},
+		TimeSlot:   &model.TimeSlot{ID: "A", Days: []int{1}, StartHr: 8, StartMin: 0, EndHr: 8, EndMin: 50},
+		Section: &model.Section{ID: "1", CourseID: "CS-101", Semester: "Fall", Year: 2024,
+			Building: "Packard", RoomNumber: "101", TimeSlotID: "A"},
+	}
+	f.Key = model.SectionKey{CourseID: "CS-101", SecID: "1", Semester: "Fall", Year: 2024}
+
+	must(t, s.Departments.Create(ctx, f.Dept))
+	must(t, s.Instructors.Create(ctx, f.Instructor))
+	must(t, s.Students.Create(ctx, f.Student))
+	must(t, s.Courses.Create(ctx, f.Course))
+	must(t, s.Courses.Create(ctx, f.Course2))
+	must(t, s.Classrooms.Create(ctx, f.Classroom))
+	must(t, s.Classrooms.Create(ctx, f.Classroom2))
+	must(t, s.TimeSlots.Create(ctx, f.TimeSlot))
+	must(t, s.Sections.Create(ctx, f.Section))
+
+	return f
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func expectErr(t *testing.T, err, target error, op string) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("%s: expected %v, got %v", op, target, err)
+	}
+}
+
+func testStudents(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	r := s.Students
+
+	got, err := r.GetByID(ctx, f.Student.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Name != f.Student.Name || got.Dept != f.Student.Dept || got.TotCred != f.Student.TotCred ||
+		got.Password != f.Student.Password || got.Salt != f.Student.Salt || got.MustChangePassword {
+		t.Errorf("GetByID() = %+v, expected %+v", got, f.Student)
+	}
+
+	expectErr(t, r.Create(ctx, &model.Student{ID: f.Student.ID, Name: "Other", Dept: f.Dept.DeptName}), repository.ErrDuplicate, "Create() duplicate")
+	expectErr(t, r.Create(ctx, &model.Student{ID: "99999", Name: "Nobody", Dept: "Nowhere"}), repository.ErrNotFound, "Create() unknown dept")
+
+	must(t, r.Create(ctx, &model.Student{ID: "12345", Name: "Shankar", Dept: f.Dept.DeptName, TotCred: 32}))
+	must(t, r.Create(ctx, &model.Student{ID: "19991", Name: "Brandt", Dept: f.Dept.DeptName, TotCred: 80}))
+
+	page, err := r.List(ctx, &model.ListQuery{Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if page.Total != 3 || len(page.Items) != 1 || page.Items[0].ID != "19991" || page.NextCursor != "" {
+		t.Errorf("List(page 2 of 2) = %+v, expected [19991] of 3 and no next page", page)
+	}
+
+	// 按学分倒序翻页，游标接着上一页继续
+	first, err := r.List(ctx, &model.ListQuery{PageSize: 2, Sort: []model.SortField{{Field: "tot_cred", Desc: true}}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if first.Total != 3 || len(first.Items) != 2 || first.Items[0].ID != f.Student.ID || first.Items[1].ID != "19991" ||
+		first.Items[0].Password != "" || first.NextCursor == "" {
+		t.Errorf("List(-tot_cred) = %+v, expected [%s 19991] with a next page", first, f.Student.ID)
+	}
+	next, err := r.List(ctx, &model.ListQuery{PageSize: 2, Cursor: first.NextCursor, Sort: []model.SortField{{Field: "tot_cred", Desc: true}}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if next.Page != 2 || len(next.Items) != 1 || next.Items[0].ID != "12345" || next.NextCursor != "" {
+		t.Errorf("List(cursor) = %+v, expected [12345] as the last page", next)
+	}
+
+	filtered, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"dept": "Physics"}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if filtered.Total != 0 || len(filtered.Items) != 0 {
+		t.Errorf("List(dept=Physics) = %+v, expected none", filtered)
+	}
+	_, err = r.List(ctx, &model.ListQuery{Sort: []model.SortField{{Field: "password"}}})
+	expectErr(t, err, model.ErrInvalidListQuery, "List() unknown sort field")
+	_, err = r.List(ctx, &model.ListQuery{Filters: map[string]string{"name": "Zhang"}})
+	expectErr(t, err, model.ErrInvalidListQuery, "List() unknown filter field")
+
+	found, err := r.Search(ctx, "Shan")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(found) != 1 || found[0].ID != "12345" {
+		t.Errorf("Search(Shan) returned %d students, expected 12345", len(found))
+	}
+
+	if exists, err := r.ExistsByID(ctx, "12345"); err != nil || !exists {
+		t.Errorf("ExistsByID(12345) = %v, %v, expected true", exists, err)
+	}
+	if exists, err := r.ExistsByID(ctx, "00000"); err != nil || exists {
+		t.Errorf("ExistsByID(00000) = %v, %v, expected false", exists, err)
+	}
+
+	must(t, r.Update(ctx, &model.Student{ID: "12345", Name: "Shankar", Dept: f.Dept.DeptName, TotCred: 36}))
+	must(t, r.UpdatePassword(ctx, "12345", "newhash", "newsalt", true))
+	got, err = r.GetByID(ctx, "12345")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.TotCred != 36 || got.Password != "newhash" || got.Salt != "newsalt" || !got.MustChangePassword {
+		t.Errorf("GetByID() after update = %+v", got)
+	}
+
+	expectErr(t, r.Update(ctx, &model.Student{ID: "00000", Name: "Ghost", Dept: f.Dept.DeptName}), repository.ErrNotFound, "Update() missing")
+	expectErr(t, r.UpdatePassword(ctx, "00000", "h", "s", false), repository.ErrNotFound, "UpdatePassword() missing")
+
+	must(t, r.Delete(ctx, "12345"))
+	_, err = r.GetByID(ctx, "12345")
+	expectErr(t, err, repository.ErrNotFound, "GetByID() after delete")
+	expectErr(t, r.Delete(ctx, "12345"), repository.ErrNotFound, "Delete() missing")
+}
+
+func testInstructors(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	r := s.Instructors
+
+	got, err := r.GetByID(ctx, f.Instructor.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Name != f.Instructor.Name || got.Salary != f.Instructor.Salary || got.Password != f.Instructor.Password {
+		t.Errorf("GetByID() = %+v, expected %+v", got, f.Instructor)
+	}
+
+	expectErr(t, r.Create(ctx, &model.Instructor{ID: f.Instructor.ID, Name: "Other", Dept: f.Dept.DeptName}), repository.ErrDuplicate, "Create() duplicate")
+
+	must(t, r.Create(ctx, &model.Instructor{ID: "22222", Name: "Einstein", Dept: f.Dept.DeptName, Salary: 95000}))
+	page, err := r.List(ctx, &model.ListQuery{Page: 1, PageSize: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 1 || page.Items[0].ID != f.Instructor.ID || page.NextCursor == "" {
+		t.Errorf("List(page 1 of 1) = %+v, expected [%s] of 2", page, f.Instructor.ID)
+	}
+
+	must(t, r.Update(ctx, &model.Instructor{ID: "22222", Name: "Einstein", Dept: f.Dept.DeptName, Salary: 96000}))
+	if got, err := r.GetByID(ctx, "22222"); err != nil || got.Salary != 96000 {
+		t.Errorf("GetByID() after update = %+v, %v", got, err)
+	}
+
+	must(t, r.Delete(ctx, "22222"))
+	_, err = r.GetByID(ctx, "22222")
+	expectErr(t, err, repository.ErrNotFound, "GetByID() after delete")
+}
+
+func testDepartments(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	r := s.Departments
+
+	got, err := r.FindByID(ctx, f.Dept.DeptName)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if *got != *f.Dept {
+		t.Errorf("FindByID() = %+v, expected %+v", got, f.Dept)
+	}
+
+	expectErr(t, r.Create(ctx, &model.Department{DeptName: f.Dept.DeptName}), repository.ErrDuplicate, "Create() duplicate")
+
+	physics := &model.Department{DeptName: "Physics", Building: "Watson", Budget: 70000}
+	must(t, r.Create(ctx, physics))
+	physics.Budget = 75000
+	must(t, r.Update(ctx, physics))
+
+	all, err := r.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].DeptName != "Comp. Sci." || all[1].DeptName != "Physics" || all[1].Budget != 75000 {
+		t.Errorf("FindAll() returned unexpected departments: %+v", all)
+	}
+
+	byBudget, err := r.List(ctx, &model.ListQuery{Sort: []model.SortField{{Field: "budget"}}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if byBudget.Total != 2 || len(byBudget.Items) != 2 || byBudget.Items[0].DeptName != "Physics" {
+		t.Errorf("List(budget) = %+v, expected Physics first", byBudget)
+	}
+
+	stats, err := r.GetDepartmentStats(ctx)
+	if err != nil {
+		t.Fatalf("GetDepartmentStats() error = %v", err)
+	}
+	if len(stats) != 2 || stats[0].StudentCount != 1 || stats[0].InstructorCount != 1 || stats[0].CourseCount != 2 ||
+		stats[1].StudentCount != 0 {
+		t.Errorf("GetDepartmentStats() returned unexpected counts")
+	}
+
+	if count, err := r.GetCourseCount(ctx, f.Dept.DeptName); err != nil || count != 2 {
+		t.Errorf("GetCourseCount() = %d, %v, expected 2", count, err)
+	}
+
+	if err := r.Delete(ctx, f.Dept.DeptName); err == nil {
+		t.Error("Expected error deleting a department with students")
+	}
+	must(t, r.Delete(ctx, "Physics"))
+	expectErr(t, r.Delete(ctx, "Physics"), repository.ErrNotFound, "Delete() missing")
+	expectErr(t, r.Update(ctx, physics), repository.ErrNotFound, "Update() missing")
+}
+
+func testCourses(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	r := s.Courses
+
+	got, err := r.FindByID(ctx, f.Course.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Title != f.Course.Title || got.Dept != f.Course.Dept || got.Credits != f.Course.Credits {
+		t.Errorf("FindByID() = %+v, expected %+v", got, f.Course)
+	}
+
+	expectErr(t, r.Create(ctx, &model.Course{ID: f.Course.ID, Title: "Dup", Dept: f.Dept.DeptName}), repository.ErrDuplicate, "Create() duplicate")
+	expectErr(t, r.Create(ctx, &model.Course{ID: "XX-100", Title: "Orphan", Dept: "Nowhere"}), repository.ErrNotFound, "Create() unknown dept")
+
+	byDept, err := r.FindByDept(ctx, f.Dept.DeptName)
+	if err != nil {
+		t.Fatalf("FindByDept() error = %v", err)
+	}
+	if len(byDept) != 2 {
+		t.Errorf("FindByDept() returned %d courses, expected 2", len(byDept))
+	}
+
+	f.Course2.Credits = 3
+	must(t, r.Update(ctx, f.Course2))
+	if got, err := r.FindByID(ctx, f.Course2.ID); err != nil || got.Credits != 3 {
+		t.Errorf("FindByID() after update = %+v, %v", got, err)
+	}
+
+	if s.Prereqs != nil {
+		must(t, s.Prereqs.Create(ctx, &model.Prereq{CourseID: f.Course2.ID, PrereqID: f.Course.ID}))
+		withPrereqs, err := r.FindWithPrereqs(ctx, f.Course2.ID)
+		if err != nil {
+			t.Fatalf("FindWithPrereqs() error = %v", err)
+		}
+		if len(withPrereqs.Prereqs) != 1 || withPrereqs.Prereqs[0].ID != f.Course.ID || withPrereqs.Prereqs[0].Title != f.Course.Title {
+			t.Errorf("FindWithPrereqs() = %+v, expected prereq %s", withPrereqs.Prereqs, f.Course.ID)
+		}
+		must(t, s.Prereqs.Delete(ctx, f.Course2.ID, f.Course.ID))
+	}
+
+	must(t, r.Delete(ctx, f.Course2.ID))
+	_, err = r.FindByID(ctx, f.Course2.ID)
+	expectErr(t, err, repository.ErrNotFound, "FindByID() after delete")
+	_, err = r.FindWithPrereqs(ctx, f.Course2.ID)
+	expectErr(t, err, repository.ErrNotFound, "FindWithPrereqs() missing")
+}
+
+func testClassrooms(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	r := s.Classrooms
+
+	got, err := r.FindByID(ctx, f.Classroom.Building, f.Classroom.RoomNumber)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if *got != *f.Classroom {
+		t.Errorf("FindByID() = %+v, expected %+v", got, f.Classroom)
+	}
+
+	expectErr(t, r.Create(ctx, f.Classroom), repository.ErrDuplicate, "Create() duplicate")
+
+	// Packard 101 已被课程段占用
+	available, err := r.FindAvailable(ctx, 50, f.Key.Semester, f.Key.Year, f.TimeSlot.ID)
+	if err != nil {
+		t.Fatalf("FindAvailable() error = %v", err)
+	}
+	if len(available) != 1 || available[0].Building != f.Classroom2.Building {
+		t.Errorf("FindAvailable() = %+v, expected only %s %s", available, f.Classroom2.Building, f.Classroom2.RoomNumber)
+	}
+
+	available, err = r.FindAvailable(ctx, 100, "Spring", f.Key.Year, f.TimeSlot.ID)
+	if err != nil {
+		t.Fatalf("FindAvailable() error = %v", err)
+	}
+	if len(available) != 1 || available[0].Building != f.Classroom.Building {
+		t.Errorf("FindAvailable() in another term = %+v, expected only %s %s", available, f.Classroom.Building, f.Classroom.RoomNumber)
+	}
+
+	if err := r.Delete(ctx, f.Classroom.Building, f.Classroom.RoomNumber); err == nil {
+		t.Error("Expected error deleting a classroom used by a section")
+	}
+
+	f.Classroom2.Capacity = 80
+	must(t, r.Update(ctx, f.Classroom2))
+	byBuilding, err := r.FindByBuilding(ctx, f.Classroom2.Building)
+	if err != nil {
+		t.Fatalf("FindByBuilding() error = %v", err)
+	}
+	if len(byBuilding) != 1 || byBuilding[0].Capacity != 80 {
+		t.Errorf("FindByBuilding() = %+v, expected capacity 80", byBuilding)
+	}
+
+	must(t, r.Delete(ctx, f.Classroom2.Building, f.Classroom2.RoomNumber))
+	_, err = r.FindByBuildingAndRoom(ctx, f.Classroom2.Building, f.Classroom2.RoomNumber)
+	expectErr(t, err, repository.ErrNotFound, "FindByBuildingAndRoom() after delete")
+	expectErr(t, r.Update(ctx, f.Classroom2), repository.ErrNotFound, "Update() missing")
+}
+
+func testTimeSlots(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.TimeSlots == nil {
+		t.Skip("time slot store not implemented")
+	}
+	r := s.TimeSlots
+
+	morning := &model.TimeSlot{ID: "A", Days: []int{1}, StartHr: 8, StartMin: 0, EndHr: 8, EndMin: 50}
+	afternoon := &model.TimeSlot{ID: "B", Days: []int{3}, StartHr: 13, StartMin: 30, EndHr: 14, EndMin: 45}
+	must(t, r.Create(ctx, morning))
+	must(t, r.Create(ctx, afternoon))
+
+	got, err := r.FindByID(ctx, "B")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if len(got.Days) != 1 || got.Days[0] != 3 || got.StartHr != 13 || got.StartMin != 30 || got.EndHr != 14 || got.EndMin != 45 {
+		t.Errorf("FindByID() = %+v, expected %+v", got, afternoon)
+	}
+
+	byDay, err := r.FindByDayOfWeek(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByDayOfWeek() error = %v", err)
+	}
+	if len(byDay) != 1 || byDay[0].ID != "A" {
+		t.Errorf("FindByDayOfWeek(1) = %+v, expected [A]", byDay)
+	}
+
+	inRange, err := r.FindByTimeRange(ctx, "12:00", "15:00")
+	if err != nil {
+		t.Fatalf("FindByTimeRange() error = %v", err)
+	}
+	if len(inRange) != 1 || inRange[0].ID != "B" {
+		t.Errorf("FindByTimeRange(12:00, 15:00) = %+v, expected [B]", inRange)
+	}
+	if _, err := r.FindByTimeRange(ctx, "noon", "15:00"); err == nil {
+		t.Error("Expected error for malformed time")
+	}
+
+	afternoon.Days = []int{4}
+	must(t, r.Update(ctx, afternoon))
+	if got, err := r.FindByID(ctx, "B"); err != nil || len(got.Days) != 1 || got.Days[0] != 4 {
+		t.Errorf("FindByID() after update = %+v, %v", got, err)
+	}
+
+	must(t, r.Delete(ctx, "B"))
+	_, err = r.FindByID(ctx, "B")
+	expectErr(t, err, repository.ErrNotFound, "FindByID() after delete")
+	expectErr(t, r.Delete(ctx, "B"), repository.ErrNotFound, "Delete() missing")
+	expectErr(t, r.Update(ctx, afternoon), repository.ErrNotFound, "Update() missing")
+}
+
+func testSections(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	r := s.Sections
+
+	got, err := r.FindByID(ctx, f.Key)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.ID != f.Section.ID || got.CourseID != f.Section.CourseID || got.Building != f.Section.Building ||
+		got.RoomNumber != f.Section.RoomNumber || got.TimeSlotID != f.Section.TimeSlotID || got.Year != f.Section.Year {
+		t.Errorf("FindByID() = %+v, expected %+v", got, f.Section)
+	}
+	if _, err := r.FindByIDForUpdate(ctx, f.Key); err != nil {
+		t.Errorf("FindByIDForUpdate() error = %v", err)
+	}
+
+	expectErr(t, r.Create(ctx, f.Section), repository.ErrDuplicate, "Create() duplicate")
+	orphan := *f.Section
+	orphan.CourseID = "XX-100"
+	expectErr(t, r.Create(ctx, &orphan), repository.ErrNotFound, "Create() unknown course")
+
+	spring := *f.Section
+	spring.Semester = "Spring"
+	spring.Year = 2025
+	must(t, r.Create(ctx, &spring))
+
+	byCourse, err := r.FindByCourseID(ctx, f.Course.ID)
+	if err != nil {
+		t.Fatalf("FindByCourseID() error = %v", err)
+	}
+	if len(byCourse) != 2 {
+		t.Errorf("FindByCourseID() returned %d sections, expected 2", len(byCourse))
+	}
+
+	listed, err := r.List(ctx, &model.ListQuery{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if listed.Total != 2 || len(listed.Items) != 2 || listed.Items[0].Year != 2025 {
+		t.Errorf("List() = %+v, expected the newest section first", listed)
+	}
+	fall, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"year": "2024", "dept": f.Dept.DeptName}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if fall.Total != 1 || len(fall.Items) != 1 || fall.Items[0].Semester != f.Key.Semester {
+		t.Errorf("List(year=2024) = %+v, expected the %s section", fall, f.Key.Semester)
+	}
+	_, err = r.List(ctx, &model.ListQuery{Filters: map[string]string{"year": "next"}})
+	expectErr(t, err, model.ErrInvalidListQuery, "List() non-numeric year")
+
+	spring.Building = f.Classroom2.Building
+	spring.RoomNumber = f.Classroom2.RoomNumber
+	must(t, r.Update(ctx, &spring))
+	springKey := model.SectionKey{CourseID: spring.CourseID, SecID: spring.ID, Semester: spring.Semester, Year: spring.Year}
+	classroom, err := r.GetSectionClassroom(ctx, springKey)
+	if err != nil {
+		t.Fatalf("GetSectionClassroom() error = %v", err)
+	}
+	if classroom.Building != f.Classroom2.Building || classroom.Capacity != f.Classroom2.Capacity {
+		t.Errorf("GetSectionClassroom() = %+v, expected %+v", classroom, f.Classroom2)
+	}
+
+	if s.Takes != nil {
+		must(t, s.Takes.Create(ctx, &model.Takes{StudentID: f.Student.ID, CourseID: f.Key.CourseID, SectionID: f.Key.SecID,
+			Semester: f.Key.Semester, Year: f.Key.Year}))
+		if count, err := r.GetEnrollmentCount(ctx, f.Key); err != nil || count != 1 {
+			t.Errorf("GetEnrollmentCount() = %d, %v, expected 1", count, err)
+		}
+	}
+
+	must(t, r.Delete(ctx, springKey))
+	_, err = r.FindByID(ctx, springKey)
+	expectErr(t, err, repository.ErrNotFound, "FindByID() after delete")
+	expectErr(t, r.Update(ctx, &spring), repository.ErrNotFound, "Update() missing")
+}
+
+func testTakes(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Takes == nil {
+		t.Skip("takes store not implemented")
+	}
+	r := s.Takes
+
+	takes := &model.Takes{StudentID: f.Student.ID, CourseID: f.Key.CourseID, SectionID: f.Key.SecID,
+		Semester: f.Key.Semester, Year: f.Key.Year}
+	must(t, r.Create(ctx, takes))
+	expectErr(t, r.Create(ctx, takes), repository.ErrDuplicate, "Create() duplicate")
+	expectErr(t, r.Create(ctx, &model.Takes{StudentID: "99999", CourseID: f.Key.CourseID, SectionID: f.Key.SecID,
+		Semester: f.Key.Semester, Year: f.Key.Year}), repository.ErrNotFound, "Create() unknown student")
+
+	got, err := r.FindByStudentAndSection(ctx, f.Student.ID, f.Key)
+	if err != nil {
+		t.Fatalf("FindByStudentAndSection() error = %v", err)
+	}
+	if got.StudentID != f.Student.ID || got.CourseID != f.Key.CourseID || got.Year != f.Key.Year || got.Grade != "" {
+		t.Errorf("FindByStudentAndSection() = %+v, expected %+v", got, takes)
+	}
+
+	if credits, err := r.GetTermCredits(ctx, f.Student.ID, f.Key.Semester, f.Key.Year); err != nil || credits != f.Course.Credits {
+		t.Errorf("GetTermCredits() = %v, %v, expected %v", credits, err, f.Course.Credits)
+	}
+
+	bySection, err := r.FindBySection(ctx, f.Key)
+	if err != nil {
+		t.Fatalf("FindBySection() error = %v", err)
+	}
+	if len(bySection) != 1 || bySection[0].StudentID != f.Student.ID {
+		t.Errorf("FindBySection() = %+v, expected student %s", bySection, f.Student.ID)
+	}
+
+	must(t, r.UpdateGrade(ctx, f.Student.ID, f.Key, "A"))
+	transcript, err := r.GetStudentTranscript(ctx, f.Student.ID)
+	if err != nil {
+		t.Fatalf("GetStudentTranscript() error = %v", err)
+	}
+	if len(transcript.Courses) != 1 || transcript.Courses[0].Grade != "A" || transcript.Courses[0].Title != f.Course.Title ||
+		transcript.TotalCred != f.Course.Credits || transcript.GPA != 4 {
+		t.Errorf("GetStudentTranscript() = %+v", transcript)
+	}
+
+	byStudent, err := r.FindByStudentID(ctx, f.Student.ID)
+	if err != nil {
+		t.Fatalf("FindByStudentID() error = %v", err)
+	}
+	if len(byStudent) != 1 || byStudent[0].Grade != "A" {
+		t.Errorf("FindByStudentID() = %+v, expected one graded record", byStudent)
+	}
+
+	// 退选不计入学期学分
+	must(t, r.UpdateGrade(ctx, f.Student.ID, f.Key, model.GradeWithdrawn))
+	if credits, err := r.GetTermCredits(ctx, f.Student.ID, f.Key.Semester, f.Key.Year); err != nil || credits != 0 {
+		t.Errorf("GetTermCredits() after withdrawal = %v, %v, expected 0", credits, err)
+	}
+
+	must(t, r.Delete(ctx, f.Student.ID, f.Key))
+	_, err = r.FindByStudentAndSection(ctx, f.Student.ID, f.Key)
+	expectErr(t, err, repository.ErrNotFound, "FindByStudentAndSection() after delete")
+	expectErr(t, r.Delete(ctx, f.Student.ID, f.Key), repository.ErrNotFound, "Delete() missing")
+	expectErr(t, r.UpdateGrade(ctx, f.Student.ID, f.Key, "B"), repository.ErrNotFound, "UpdateGrade() missing")
+}
+
+func testTeaches(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Teaches == nil {
+		t.Skip("teaches store not implemented")
+	}
+	r := s.Teaches
+
+	teaches := &model.Teaches{InstructorID: f.Instructor.ID, CourseID: f.Key.CourseID, SectionID: f.Key.SecID,
+		Semester: f.Key.Semester, Year: f.Key.Year}
+	must(t, r.Create(ctx, teaches))
+	expectErr(t, r.Create(ctx, teaches), repository.ErrDuplicate, "Create() duplicate")
+
+	got, err := r.FindByInstructorAndSection(ctx, f.Instructor.ID, f.Key)
+	if err != nil {
+		t.Fatalf("FindByInstructorAndSection() error = %v", err)
+	}
+	if got.InstructorID != f.Instructor.ID || got.SectionID != f.Key.SecID || got.Year != f.Key.Year {
+		t.Errorf("FindByInstructorAndSection() = %+v, expected %+v", got, teaches)
+	}
+
+	current, err := r.GetCurrentTeaching(ctx, f.Instructor.ID, f.Key.Semester, f.Key.Year)
+	if err != nil {
+		t.Fatalf("GetCurrentTeaching() error = %v", err)
+	}
+	if len(current) != 1 || current[0].CourseID != f.Key.CourseID {
+		t.Errorf("GetCurrentTeaching() = %+v, expected %s", current, f.Key.CourseID)
+	}
+
+	bySection, err := r.FindBySectionID(ctx, f.Key)
+	if err != nil {
+		t.Fatalf("FindBySectionID() error = %v", err)
+	}
+	if len(bySection) != 1 || bySection[0].InstructorID != f.Instructor.ID {
+		t.Errorf("FindBySectionID() = %+v, expected instructor %s", bySection, f.Instructor.ID)
+	}
+
+	listed, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"instructor_id": f.Instructor.ID}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if listed.Total != 1 || len(listed.Items) != 1 || listed.Items[0].Course == nil || listed.Items[0].Course.Title != f.Course.Title ||
+		listed.Items[0].Section == nil || listed.Items[0].Section.Building != f.Section.Building {
+		t.Errorf("List(instructor_id) = %+v, expected the section with course details", listed)
+	}
+
+	taught, err := s.Sections.List(ctx, &model.ListQuery{Filters: map[string]string{"instructor_id": f.Instructor.ID}})
+	if err != nil {
+		t.Fatalf("Sections.List() error = %v", err)
+	}
+	if taught.Total != 1 || len(taught.Items) != 1 || taught.Items[0].CourseID != f.Key.CourseID {
+		t.Errorf("Sections.List(instructor_id) = %+v, expected the taught section", taught)
+	}
+
+	must(t, r.Delete(ctx, f.Instructor.ID, f.Key))
+	_, err = r.FindByInstructorAndSection(ctx, f.Instructor.ID, f.Key)
+	expectErr(t, err, repository.ErrNotFound, "FindByInstructorAndSection() after delete")
+	expectErr(t, r.Delete(ctx, f.Instructor.ID, f.Key), repository.ErrNotFound, "Delete() missing")
+}
+
+func testAdvisors(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Advisors == nil {
+		t.Skip("advisor store not implemented")
+	}
+	r := s.Advisors
+
+	must(t, r.Create(ctx, &model.Advisor{StudentID: f.Student.ID, InstructorID: f.Instructor.ID}))
+	expectErr(t, r.Create(ctx, &model.Advisor{StudentID: f.Student.ID, InstructorID: f.Instructor.ID}), repository.ErrDuplicate, "Create() second advisor")
+
+	got, err := r.FindByID(ctx, f.Student.ID, f.Instructor.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.StudentID != f.Student.ID || got.InstructorID != f.Instructor.ID {
+		t.Errorf("FindByID() = %+v", got)
+	}
+
+	other := &model.Instructor{ID: "22222", Name: "Einstein", Dept: f.Dept.DeptName, Salary: 95000}
+	must(t, s.Instructors.Create(ctx, other))
+	must(t, r.Update(ctx, f.Student.ID, other.ID))
+
+	byStudent, err := r.FindByStudentID(ctx, f.Student.ID)
+	if err != nil {
+		t.Fatalf("FindByStudentID() error = %v", err)
+	}
+	if len(byStudent) != 1 || byStudent[0].InstructorID != other.ID {
+		t.Errorf("FindByStudentID() after update = %+v, expected instructor %s", byStudent, other.ID)
+	}
+
+	byInstructor, err := r.FindByInstructorID(ctx, f.Instructor.ID)
+	if err != nil {
+		t.Fatalf("FindByInstructorID() error = %v", err)
+	}
+	if len(byInstructor) != 0 {
+		t.Errorf("FindByInstructorID() = %+v, expected none", byInstructor)
+	}
+
+	must(t, r.Delete(ctx, f.Student.ID, other.ID))
+	_, err = r.FindByStudentAndInstructor(ctx, f.Student.ID, other.ID)
+	expectErr(t, err, repository.ErrNotFound, "FindByStudentAndInstructor() after delete")
+	expectErr(t, r.Delete(ctx, f.Student.ID, other.ID), repository.ErrNotFound, "Delete() missing")
+
+	// 没有导师的学生更新时直接建立关系
+	must(t, r.Update(ctx, f.Student.ID, f.Instructor.ID))
+	if _, err := r.FindByID(ctx, f.Student.ID, f.Instructor.ID); err != nil {
+		t.Errorf("FindByID() after Update() without advisor error = %v", err)
+	}
+}
+
+func testPrereqs(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	f := seed(t, s)
+	if s.Prereqs == nil {
+		t.Skip("prereq store not implemented")
+	}
+	r := s.Prereqs
+
+	prereq := &model.Prereq{CourseID: f.Course2.ID, PrereqID: f.Course.ID}
+	must(t, r.Create(ctx, prereq))
+	expectErr(t, r.Create(ctx, prereq), repository.ErrDuplicate, "Create() duplicate")
+
+	if has, err := r.HasPrerequisite(ctx, f.Course2.ID, f.Course.ID); err != nil || !has {
+		t.Errorf("HasPrerequisite() = %v, %v, expected true", has, err)
+	}
+	ids, err := r.GetPrereqIDs(ctx, f.Course2.ID)
+	if err != nil {
+		t.Fatalf("GetPrereqIDs() error = %v", err)
+	}
+	if len(ids) != 1 || ids[0] != f.Course.ID {
+		t.Errorf("GetPrereqIDs() = %v, expected [%s]", ids, f.Course.ID)
+	}
+
+	listed, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"course_id": f.Course2.ID}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if listed.Total != 1 || len(listed.Items) != 1 || listed.Items[0].PrereqInfo == nil || listed.Items[0].PrereqInfo.Title != f.Course.Title {
+		t.Errorf("List(course_id) = %+v, expected %s with course details", listed, f.Course.ID)
+	}
+
+	_, err = r.GetRule(ctx, f.Course2.ID)
+	expectErr(t, err, repository.ErrNotFound, "GetRule() before save")
+
+	// 再次保存覆盖原有规则
+	must(t, r.SaveRule(ctx, f.Course2.ID, &model.PrereqRule{Type: model.PrereqRuleCourse, CourseID: f.Course.ID}))
+	must(t, r.SaveRule(ctx, f.Course2.ID, &model.PrereqRule{Type: model.PrereqRuleCourse, CourseID: f.Course.ID, MinGrade: "B"}))
+	rule, err := r.GetRule(ctx, f.Course2.ID)
+	if err != nil {
+		t.Fatalf("GetRule() error = %v", err)
+	}
+	if rule.Type != model.PrereqRuleCourse || rule.CourseID != f.Course.ID || rule.MinGrade != "B" {
+		t.Errorf("GetRule() = %+v, expected the second rule", rule)
+	}
+
+	must(t, r.DeleteRule(ctx, f.Course2.ID))
+	expectErr(t, r.DeleteRule(ctx, f.Course2.ID), repository.ErrNotFound, "DeleteRule() missing")
+
+	must(t, r.Delete(ctx, f.Course2.ID, f.Course.ID))
+	_, err = r.FindByID(ctx, f.Course2.ID, f.Course.ID)
+	expectErr(t, err, repository.ErrNotFound, "FindByID() after delete")
+	expectErr(t, r.Delete(ctx, f.Course2.ID, f.Course.ID), repository.ErrNotFound, "Delete() missing")
+}
+
+func testLoginAttempts(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.LoginAttempts == nil {
+		t.Skip("login attempt store not implemented")
+	}
+	r := s.LoginAttempts
+
+	// MySQL的DATETIME不保存小数秒，用整秒时间比较
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+
+	first := &model.LoginAttempt{UserID: "00128", Role: "student", IP: "10.0.0.1", Reason: "bad_password", CreatedAt: at}
+	second := &model.LoginAttempt{UserID: "00128", Role: "student", IP: "10.0.0.1", Success: true, CreatedAt: at.Add(time.Minute)}
+	must(t, r.Create(ctx, first))
+	must(t, r.Create(ctx, second))
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Errorf("Expected increasing IDs, got %d and %d", first.ID, second.ID)
+	}
+
+	attempts, err := r.List(ctx, &model.ListQuery{Filters: map[string]string{"user_id": "00128"}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if attempts.Total != 2 || len(attempts.Items) != 2 || attempts.Items[0].ID != second.ID ||
+		!attempts.Items[0].CreatedAt.Equal(second.CreatedAt) || !attempts.Items[0].Success {
+		t.Errorf("List() = %+v, expected newest first", attempts)
+	}
+
+	_, err = r.FindThrottle(ctx, "account", "student:00128")
+	expectErr(t, err, repository.ErrNotFound, "FindThrottle() before failures")
+
+	if _, err := r.IncrementFailures(ctx, "account", "student:00128", at); err != nil {
+		t.Fatalf("IncrementFailures() error = %v", err)
+	}
+	throttle, err := r.IncrementFailures(ctx, "account", "student:00128", at.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("IncrementFailures() error = %v", err)
+	}
+	if throttle.Failures != 2 || !throttle.LastFailureAt.Equal(at.Add(time.Minute)) || throttle.LockedAt != nil {
+		t.Errorf("IncrementFailures() = %+v, expected 2 failures at %v", throttle, at.Add(time.Minute))
+	}
+
+	must(t, r.Lock(ctx, "account", "student:00128", at.Add(2*time.Minute)))
+	locked, err := r.ListLockedAccounts(ctx, &model.ListQuery{})
+	if err != nil {
+		t.Fatalf("ListLockedAccounts() error = %v", err)
+	}
+	if locked.Total != 1 || len(locked.Items) != 1 || locked.Items[0].LockedAt == nil || !locked.Items[0].LockedAt.Equal(at.Add(2*time.Minute)) {
+		t.Errorf("ListLockedAccounts() = %+v", locked)
+	}
+
+	must(t, r.ResetThrottle(ctx, "account", "student:00128"))
+	_, err = r.FindThrottle(ctx, "account", "student:00128")
+	expectErr(t, err, repository.ErrNotFound, "FindThrottle() after reset")
+}
+
+func testRoles(t *testing.T, s *Stores) {
+	ctx := context.Background()
+	if s.Roles == nil {
+		t.Skip("role store not implemented")
+	}
+	r := s.Roles
+
+	role := &model.Role{Name: "grader", Description: "Grades courses"}
+	must(t, r.SaveRole(ctx, role))
+	role.Description = "Grades department courses"
+	role.DeptScoped = true
+	must(t, r.SaveRole(ctx, role))
+	must(t, r.ReplacePermissions(ctx, role.Name, []model.Permission{model.PermGradesWrite, model.PermCoursesRead}))
+
+	got, err := r.FindRole(ctx, role.Name)
+	if err != nil {
+		t.Fatalf("FindRole() error = %v", err)
+	}
+	if got.Description != role.Description || !got.DeptScoped || len(got.Permissions) != 2 ||
+		got.Permissions[0] != model.PermCoursesRead || got.Permissions[1] != model.PermGradesWrite {
+		t.Errorf("FindRole() = %+v, expected %+v", got, role)
+	}
+
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	assignment := &model.RoleAssignment{UserID: "10101", UserRole: "instructor", RoleName: role.Name, Dept: "Comp. Sci.", CreatedAt: at}
+	must(t, r.CreateAssignment(ctx, assignment))
+	if assignment.ID == 0 {
+		t.Error("Expected CreateAssignment to set the ID")
+	}
+	duplicate := *assignment
+	expectErr(t, r.CreateAssignment(ctx, &duplicate), repository.ErrDuplicate, "CreateAssignment() duplicate")
+
+	assignments, err := r.ListAssignments(ctx, &model.ListQuery{Filters: map[string]string{"user_id": "10101", "user_role": "instructor"}})
+	if err != nil {
+		t.Fatalf("ListAssignments() error = %v", err)
+	}
+	if assignments.Total != 1 || len(assignments.Items) != 1 || assignments.Items[0].ID != assignment.ID ||
+		!assignments.Items[0].CreatedAt.Equal(at) {
+		t.Errorf("ListAssignments() = %+v, expected %+v", assignments, assignment)
+	}
+
+	must(t, r.DeleteAssignment(ctx, assignment.ID))
+	_, err = r.FindAssignment(ctx, assignment.ID)
+	expectErr(t, err, repository.ErrNotFound, "FindAssignment() after delete")
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// RoleRepository 定义角色和角色分配仓库接口
+type RoleRepository interface {
+	FindAllRoles(ctx context.Context) ([]*model.Role, error)
+	FindRole(ctx context.Context, name string) (*model.Role, error)
+	SaveRole(ctx context.Context, role *model.Role) error
+	ReplacePermissions(ctx context.Context, roleName string, permissions []model.Permission) error
+	DeleteRole(ctx context.Context, name string) error
+
+	ListAssignments(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.RoleAssignment], error)
+	FindAssignment(ctx context.Context, id int64) (*model.RoleAssignment, error)
+	CreateAssignment(ctx context.Context, assignment *model.RoleAssignment) error
+	DeleteAssignment(ctx context.Context, id int64) error
+	FindGrants(ctx context.Context, userID string, userRole string) ([]model.PermissionGrant, error)
+}
+
+// SQLRoleRepository 实现RoleRepository接口
+type SQLRoleRepository struct {
+	db DBTX
+}
+
+// NewRoleRepository 创建角色仓库实例
+func NewRoleRepository(db DBTX) RoleRepository {
+	return &SQLRoleRepository{db: db}
+}
+
+// FindAllRoles 查找所有角色及其权限
+func (r *SQLRoleRepository) FindAllRoles(ctx context.Context) ([]*model.Role, error) {
+	query := `
+		SELECT r.name, r.description, r.dept_scoped, p.permission
+		FROM auth_role r
+		LEFT JOIN auth_role_permission p ON p.role_name = r.name
+		ORDER BY r.name, p.permission
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*model.Role
+	var current *model.Role
+	for rows.Next() {
+		var role model.Role
+		var permission sql.NullString
+		if err := rows.Scan(&role.Name, &role.Description, &role.DeptScoped, &permission); err != nil {
+			return nil, fmt.Errorf("error scanning role: %w", err)
+		}
+		if current == nil || current.Name != role.Name {
+			current = &role
+			roles = append(roles, current)
+		}
+		if permission.Valid {
+			current.Permissions = append(current.Permissions, model.Permission(permission.String))
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// FindRole 根据名称查找角色
+func (r *SQLRoleRepository) FindRole(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	query := `SELECT name, description, dept_scoped FROM auth_role WHERE name = ?`
+
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&role.Name, &role.Description, &role.DeptScoped)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying role: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT permission FROM auth_role_permission WHERE role_name = ? ORDER BY permission`, name)
+	if err != nil {
+		return nil, fmt.Errorf("error querying role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("error scanning role permission: %w", err)
+		}
+		role.Permissions = append(role.Permissions, model.Permission(permission))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role permissions: %w", err)
+	}
+
+	return &role, nil
+}
+
+// SaveRole 创建或更新角色的基本信息，权限由ReplacePermissions保存
+func (r *SQLRoleRepository) SaveRole(ctx context.Context, role *model.Role) error {
+	query := `
+		INSERT INTO auth_role (name, description, dept_scoped)
+		VALUES (?, ?, ?)
+	` + r.db.Dialect().Upsert([]string{"name"}, "description = excluded.description", "dept_scoped = excluded.dept_scoped")
+
+	if _, err := r.db.ExecContext(ctx, query, role.Name, role.Description, role.DeptScoped); err != nil {
+		return fmt.Errorf("error saving role: %w", err)
+	}
+
+	return nil
+}
+
+// ReplacePermissions 替换角色的全部权限
+func (r *SQLRoleRepository) ReplacePermissions(ctx context.Context, roleName string, permissions []model.Permission) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM auth_role_permission WHERE role_name = ?`, roleName); err != nil {
+		return fmt.Errorf("error deleting role permissions: %w", err)
+	}
+
+	for _, permission := range permissions {
+		query := `INSERT INTO auth_role_permission (role_name, permission) VALUES (?, ?)`
+		if _, err := r.db.ExecContext(ctx, query, roleName, string(permission)); err != nil {
+			return fmt.Errorf("error inserting role permission: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteRole 删除角色，权限和分配记录随外键级联删除
+func (r *SQLRoleRepository) DeleteRole(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM auth_role WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("error deleting role: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// roleAssignmentListTable 角色分配列表的查询方式
+var roleAssignmentListTable = listTable{
+	fields:  model.RoleAssignmentListFields,
+	name:    "role assignments",
+	selects: `id, user_id, user_role, role_name, dept_name, created_at`,
+	from:    `auth_role_assignment`,
+	columns: map[string]string{
+		"id":         "id",
+		"user_id":    "user_id",
+		"user_role":  "user_role",
+		"role_name":  "role_name",
+		"dept_name":  "dept_name",
+		"created_at": "created_at",
+	},
+}
+
+// ListAssignments 按分页、排序和过滤条件查找角色分配
+func (r *SQLRoleRepository) ListAssignments(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.RoleAssignment], error) {
+	return list(ctx, r.db, roleAssignmentListTable, q, func(row rowScanner) (*model.RoleAssignment, error) {
+		var assignment model.RoleAssignment
+		err := row.Scan(
+			&assignment.ID,
+			&assignment.UserID,
+			&assignment.UserRole,
+			&assignment.RoleName,
+			&assignment.Dept,
+			&assignment.CreatedAt,
+		)
+		return &assignment, err
+	})
+}
+
+// FindAssignment 根据ID查找角色分配
+func (r *SQLRoleRepository) FindAssignment(ctx context.Context, id int64) (*model.RoleAssignment, error) {
+	query := `SELECT id, user_id, user_role, role_name, dept_name, created_at FROM auth_role_assignment WHERE id = ?`
+
+	var assignment model.RoleAssignment
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&assignment.ID,
+		&assignment.UserID,
+		&assignment.UserRole,
+		&assignment.RoleName,
+		&assignment.Dept,
+		&assignment.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding role assignment: %w", err)
+	}
+
+	return &assignment, nil
+}
+
+// CreateAssignment 分配角色
+func (r *SQLRoleRepository) CreateAssignment(ctx context.Context, assignment *model.RoleAssignment) error {
+	query := `
+		INSERT INTO auth_role_assignment (user_id, user_role, role_name, dept_name, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	id, err := insertReturningID(ctx, r.db, query, assignment.UserID, assignment.UserRole, assignment.RoleName,
+		assignment.Dept, assignment.CreatedAt)
+	if err != nil {
+		if errors.Is(err, ErrDuplicate) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("error creating role assignment: %w", err)
+	}
+	assignment.ID = id
+
+	return nil
+}
+
+// DeleteAssignment 撤销角色分配
+func (r *SQLRoleRepository) DeleteAssignment(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM auth_role_assignment WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting role assignment: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// FindGrants 查找账号通过角色分配获得的全部权限
+func (r *SQLRoleRepository) FindGrants(ctx context.Context, userID string, userRole string) ([]model.PermissionGrant, error) {
+	query := `
+		SELECT p.permission, a.dept_name
+		FROM auth_role_assignment a
+		JOIN auth_role_permission p ON p.role_name = a.role_name
+		WHERE a.user_id = ? AND a.user_role = ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, userRole)
+	if err != nil {
+		return nil, fmt.Errorf("error querying permission grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []model.PermissionGrant
+	for rows.Next() {
+		var grant model.PermissionGrant
+		var permission string
+		if err := rows.Scan(&permission, &grant.Dept); err != nil {
+			return nil, fmt.Errorf("error scanning permission grant: %w", err)
+		}
+		grant.Permission = model.Permission(permission)
+		grants = append(grants, grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating permission grants: %w", err)
+	}
+
+	return grants, nil
+}
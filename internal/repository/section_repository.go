@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -10,34 +11,37 @@ import (
 
 // SectionRepository 定义课程章节仓库接口
 type SectionRepository interface {
-	FindByID(id string) (*model.Section, error)
-	FindAll() ([]*model.Section, error)
-	FindByCourseID(courseID string) ([]*model.Section, error)
-	FindByParams(params *model.SectionQueryParams) ([]*model.Section, error)
-	Create(section *model.Section) error
-	Update(section *model.Section) error
-	Delete(id string) error
-	GetEnrollmentCount(sectionID string) (int, error)
-	FindWithDetails(id string) (*model.Section, error)
-	GetSectionClassroom(sectionID string) (*model.Classroom, error)
+	FindByID(ctx context.Context, key model.SectionKey) (*model.Section, error)
+	FindByIDForUpdate(ctx context.Context, key model.SectionKey) (*model.Section, error)
+	FindBySecID(ctx context.Context, secID string) ([]*model.Section, error)
+	FindAll(ctx context.Context) ([]*model.Section, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Section], error)
+	FindByCourseID(ctx context.Context, courseID string) ([]*model.Section, error)
+	FindByParams(ctx context.Context, params *model.SectionQueryParams) ([]*model.Section, error)
+	Create(ctx context.Context, section *model.Section) error
+	Update(ctx context.Context, section *model.Section) error
+	Delete(ctx context.Context, key model.SectionKey) error
+	GetEnrollmentCount(ctx context.Context, key model.SectionKey) (int, error)
+	FindWithDetails(ctx context.Context, key model.SectionKey) (*model.Section, error)
+	GetSectionClassroom(ctx context.Context, key model.SectionKey) (*model.Classroom, error)
 }
 
 // SQLSectionRepository 实现SectionRepository接口
 type SQLSectionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewSectionRepository 创建课程章节仓库实例
-func NewSectionRepository(db *sql.DB) SectionRepository {
+func NewSectionRepository(db DBTX) SectionRepository {
 	return &SQLSectionRepository{db: db}
 }
 
-// FindByID 根据ID查找课程章节
-func (r *SQLSectionRepository) FindByID(id string) (*model.Section, error) {
-	query := `SELECT course_id, sec_id, semester, year, building, room_number, time_slot_id FROM section WHERE sec_id = ?`
+// FindByID 根据完整主键查找课程章节
+func (r *SQLSectionRepository) FindByID(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	query := `SELECT course_id, sec_id, semester, year, building, room_number, time_slot_id FROM section WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
 	var section model.Section
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year).Scan(
 		&section.CourseID,
 		&section.ID,
 		&section.Semester,
@@ -49,7 +53,7 @@ func (r *SQLSectionRepository) FindByID(id string) (*model.Section, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("section not found")
+			return nil, fmt.Errorf("section not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error querying section: %w", err)
 	}
@@ -57,24 +61,85 @@ func (r *SQLSectionRepository) FindByID(id string) (*model.Section, error) {
 	return &section, nil // 返回指针类型
 }
 
+// FindByIDForUpdate 根据完整主键查找课程章节并对该行加排他锁，必须在事务中调用，
+// 锁在事务提交或回滚时释放，用于串行化同一课程段的选课操作
+func (r *SQLSectionRepository) FindByIDForUpdate(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	query := `SELECT course_id, sec_id, semester, year, building, room_number, time_slot_id FROM section WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ?` + r.db.Dialect().ForUpdate()
+
+	var section model.Section
+	err := r.db.QueryRowContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year).Scan(
+		&section.CourseID,
+		&section.ID,
+		&section.Semester,
+		&section.Year,
+		&section.Building,
+		&section.RoomNumber,
+		&section.TimeSlotID,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("section not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("error locking section: %w", err)
+	}
+
+	return &section, nil
+}
+
+// FindBySecID 查找所有sec_id相同的课程章节，用于解析旧客户端只提供sec_id的请求
+func (r *SQLSectionRepository) FindBySecID(ctx context.Context, secID string) ([]*model.Section, error) {
+	query := `SELECT course_id, sec_id, semester, year, building, room_number, time_slot_id FROM section WHERE sec_id = ? ORDER BY year DESC, semester, course_id`
+
+	rows, err := r.db.QueryContext(ctx, query, secID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sections: %w", err)
+	}
+	defer rows.Close()
+
+	var sections []*model.Section
+	for rows.Next() {
+		var section model.Section
+		err := rows.Scan(
+			&section.CourseID,
+			&section.ID,
+			&section.Semester,
+			&section.Year,
+			&section.Building,
+			&section.RoomNumber,
+			&section.TimeSlotID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning section: %w", err)
+		}
+		sections = append(sections, &section)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sections: %w", err)
+	}
+
+	return sections, nil
+}
+
 // GetSectionClassroom 获取课程章节的教室信息
-func (r *SQLSectionRepository) GetSectionClassroom(sectionID string) (*model.Classroom, error) {
+func (r *SQLSectionRepository) GetSectionClassroom(ctx context.Context, key model.SectionKey) (*model.Classroom, error) {
 	query := `
 		SELECT c.building, c.room_number, c.capacity 
 		FROM section s 
 		JOIN classroom c ON s.building = c.building AND s.room_number = c.room_number 
-		WHERE s.sec_id = ?
+		WHERE s.course_id = ? AND s.sec_id = ? AND s.semester = ? AND s.year = ?
 	`
 
 	var classroom model.Classroom
-	err := r.db.QueryRow(query, sectionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year).Scan(
 		&classroom.Building,
 		&classroom.RoomNumber,
 		&classroom.Capacity,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("classroom not found for section: %w", err)
+			return nil, fmt.Errorf("classroom not found for section: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error getting classroom info: %w", err)
 	}
@@ -83,10 +148,10 @@ func (r *SQLSectionRepository) GetSectionClassroom(sectionID string) (*model.Cla
 }
 
 // FindAll 查找所有课程章节
-func (r *SQLSectionRepository) FindAll() ([]*model.Section, error) {
+func (r *SQLSectionRepository) FindAll(ctx context.Context) ([]*model.Section, error) {
 	query := `SELECT course_id, sec_id, semester, year, building, room_number, time_slot_id FROM section`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying sections: %w", err)
 	}
@@ -117,11 +182,47 @@ func (r *SQLSectionRepository) FindAll() ([]*model.Section, error) {
 	return sections, nil
 }
 
+// sectionListTable 课程段列表的查询方式
+var sectionListTable = listTable{
+	fields:  model.SectionListFields,
+	name:    "sections",
+	selects: `s.course_id, s.sec_id, s.semester, s.year, s.building, s.room_number, s.time_slot_id`,
+	from:    `section s JOIN course c ON s.course_id = c.course_id`,
+	columns: map[string]string{
+		"course_id": "s.course_id",
+		"id":        "s.sec_id",
+		"semester":  "s.semester",
+		"year":      "s.year",
+		"building":  "s.building",
+		"dept":      "c.dept_name",
+	},
+	filters: map[string]string{
+		"instructor_id": `EXISTS (SELECT 1 FROM teaches t WHERE t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year AND t.id = ?)`,
+	},
+}
+
+// List 按分页、排序和过滤条件查找课程段
+func (r *SQLSectionRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Section], error) {
+	return list(ctx, r.db, sectionListTable, q, func(row rowScanner) (*model.Section, error) {
+		var section model.Section
+		err := row.Scan(
+			&section.CourseID,
+			&section.ID,
+			&section.Semester,
+			&section.Year,
+			&section.Building,
+			&section.RoomNumber,
+			&section.TimeSlotID,
+		)
+		return &section, err
+	})
+}
+
 // FindByCourseID 根据课程ID查找课程章节
-func (r *SQLSectionRepository) FindByCourseID(courseID string) ([]*model.Section, error) {
+func (r *SQLSectionRepository) FindByCourseID(ctx context.Context, courseID string) ([]*model.Section, error) {
 	query := `SELECT course_id, sec_id, semester, year, building, room_number, time_slot_id FROM section WHERE course_id = ?`
 
-	rows, err := r.db.Query(query, courseID)
+	rows, err := r.db.QueryContext(ctx, query, courseID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying sections: %w", err)
 	}
@@ -153,7 +254,7 @@ func (r *SQLSectionRepository) FindByCourseID(courseID string) ([]*model.Section
 }
 
 // FindByParams 根据参数查找课程章节
-func (r *SQLSectionRepository) FindByParams(params *model.SectionQueryParams) ([]*model.Section, error) {
+func (r *SQLSectionRepository) FindByParams(ctx context.Context, params *model.SectionQueryParams) ([]*model.Section, error) {
 	query := `SELECT s.course_id, s.sec_id, s.semester, s.year, s.building, s.room_number, s.time_slot_id 
 			  FROM section s 
 			  JOIN course c ON s.course_id = c.course_id 
@@ -181,7 +282,7 @@ func (r *SQLSectionRepository) FindByParams(params *model.SectionQueryParams) ([
 		args = append(args, params.Dept)
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying sections: %w", err)
 	}
@@ -213,10 +314,10 @@ func (r *SQLSectionRepository) FindByParams(params *model.SectionQueryParams) ([
 }
 
 // Create 创建课程章节
-func (r *SQLSectionRepository) Create(section *model.Section) error {
+func (r *SQLSectionRepository) Create(ctx context.Context, section *model.Section) error {
 	query := `INSERT INTO section (course_id, sec_id, semester, year, building, room_number, time_slot_id) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		section.CourseID,
 		section.ID,
 		section.Semester,
@@ -233,18 +334,18 @@ func (r *SQLSectionRepository) Create(section *model.Section) error {
 	return nil
 }
 
-// Update 更新课程章节
-func (r *SQLSectionRepository) Update(section *model.Section) error {
-	query := `UPDATE section SET semester = ?, year = ?, building = ?, room_number = ?, time_slot_id = ? WHERE course_id = ? AND sec_id = ?`
+// Update 更新课程章节的教室和时间段，课程段由完整主键确定
+func (r *SQLSectionRepository) Update(ctx context.Context, section *model.Section) error {
+	query := `UPDATE section SET building = ?, room_number = ?, time_slot_id = ? WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
-	result, err := r.db.Exec(query,
-		section.Semester,
-		section.Year,
+	result, err := r.db.ExecContext(ctx, query,
 		section.Building,
 		section.RoomNumber,
 		section.TimeSlotID,
 		section.CourseID,
 		section.ID,
+		section.Semester,
+		section.Year,
 	)
 
 	if err != nil {
@@ -257,17 +358,17 @@ func (r *SQLSectionRepository) Update(section *model.Section) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("section not found")
+		return fmt.Errorf("section not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // Delete 删除课程章节
-func (r *SQLSectionRepository) Delete(id string) error {
-	query := `DELETE FROM section WHERE sec_id = ?`
+func (r *SQLSectionRepository) Delete(ctx context.Context, key model.SectionKey) error {
+	query := `DELETE FROM section WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year)
 	if err != nil {
 		return fmt.Errorf("error deleting section: %w", err)
 	}
@@ -278,18 +379,18 @@ func (r *SQLSectionRepository) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("section not found")
+		return fmt.Errorf("section not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
-// GetEnrollmentCount 获取课程章节的选课人数
-func (r *SQLSectionRepository) GetEnrollmentCount(sectionID string) (int, error) {
-	query := `SELECT COUNT(*) FROM takes WHERE sec_id = ?`
+// GetEnrollmentCount 获取课程章节的选课人数，退选（W）的记录不占用名额
+func (r *SQLSectionRepository) GetEnrollmentCount(ctx context.Context, key model.SectionKey) (int, error) {
+	query := `SELECT COUNT(*) FROM takes WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ? AND (grade IS NULL OR grade <> 'W')`
 
 	var count int
-	err := r.db.QueryRow(query, sectionID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error getting enrollment count: %w", err)
 	}
@@ -298,9 +399,9 @@ func (r *SQLSectionRepository) GetEnrollmentCount(sectionID string) (int, error)
 }
 
 // FindWithDetails 查找课程章节详细信息
-func (r *SQLSectionRepository) FindWithDetails(id string) (*model.Section, error) {
+func (r *SQLSectionRepository) FindWithDetails(ctx context.Context, key model.SectionKey) (*model.Section, error) {
 	// 首先查找基本信息
-	section, err := r.FindByID(id)
+	section, err := r.FindByID(ctx, key)
 	if err != nil {
 		return nil, err
 	}
@@ -308,7 +409,7 @@ func (r *SQLSectionRepository) FindWithDetails(id string) (*model.Section, error
 	// 查找课程信息
 	courseQuery := `SELECT course_id, title, dept_name, credits FROM course WHERE course_id = ?`
 	var course model.Course
-	err = r.db.QueryRow(courseQuery, section.CourseID).Scan(
+	err = r.db.QueryRowContext(ctx, courseQuery, section.CourseID).Scan(
 		&course.ID,
 		&course.Title,
 		&course.Dept,
@@ -322,7 +423,7 @@ func (r *SQLSectionRepository) FindWithDetails(id string) (*model.Section, error
 	// 查找教室信息
 	classroomQuery := `SELECT building, room_number, capacity FROM classroom WHERE building = ? AND room_number = ?`
 	var classroom model.Classroom
-	err = r.db.QueryRow(classroomQuery, section.Building, section.RoomNumber).Scan(
+	err = r.db.QueryRowContext(ctx, classroomQuery, section.Building, section.RoomNumber).Scan(
 		&classroom.Building,
 		&classroom.RoomNumber,
 		&classroom.Capacity,
@@ -338,7 +439,7 @@ func (r *SQLSectionRepository) FindWithDetails(id string) (*model.Section, error
 	timeSlotQuery := `SELECT time_slot_id, day, start_hr, start_min, end_hr, end_min FROM time_slot WHERE time_slot_id = ?`
 	var timeSlot model.TimeSlot
 	var dayStr string
-	err = r.db.QueryRow(timeSlotQuery, section.TimeSlotID).Scan(
+	err = r.db.QueryRowContext(ctx, timeSlotQuery, section.TimeSlotID).Scan(
 		&timeSlot.ID,
 		&dayStr,
 		&timeSlot.StartHr,
@@ -366,9 +467,9 @@ func (r *SQLSectionRepository) FindWithDetails(id string) (*model.Section, error
 		SELECT i.id, i.name, i.dept_name, i.salary 
 		FROM teaches t 
 		JOIN instructor i ON t.id = i.id 
-		WHERE t.sec_id = ? AND t.semester = ? AND t.year = ?
+		WHERE t.course_id = ? AND t.sec_id = ? AND t.semester = ? AND t.year = ?
 	`
-	instructorRows, err := r.db.Query(instructorQuery, section.ID, section.Semester, section.Year)
+	instructorRows, err := r.db.QueryContext(ctx, instructorQuery, section.CourseID, section.ID, section.Semester, section.Year)
 	if err != nil {
 		return nil, fmt.Errorf("error querying instructors: %w", err)
 	}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// SessionRepository 定义登录会话仓库接口
+type SessionRepository interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindByID(ctx context.Context, id string) (*model.Session, error)
+	RotateRefreshHash(ctx context.Context, id string, oldHash string, newHash string, usedAt time.Time, expiresAt time.Time) error
+	WasRotated(ctx context.Context, id string, hash string) (bool, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	RevokeByUser(ctx context.Context, userID string, role string, revokedAt time.Time) (int64, error)
+}
+
+// SQLSessionRepository 实现SessionRepository接口
+type SQLSessionRepository struct {
+	db DBTX
+}
+
+// NewSessionRepository 创建登录会话仓库实例
+func NewSessionRepository(db DBTX) SessionRepository {
+	return &SQLSessionRepository{db: db}
+}
+
+// Create 创建会话
+func (r *SQLSessionRepository) Create(ctx context.Context, session *model.Session) error {
+	query := `
+		INSERT INTO auth_session (id, user_id, role, username, refresh_hash, password_change_required, created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, session.ID, session.UserID, session.Role, session.Username,
+		session.RefreshHash, session.PasswordChangeRequired, session.CreatedAt, session.LastUsedAt, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creating session: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID 根据ID查找会话
+func (r *SQLSessionRepository) FindByID(ctx context.Context, id string) (*model.Session, error) {
+	query := `
+		SELECT id, user_id, role, username, refresh_hash, password_change_required, created_at, last_used_at, expires_at, revoked_at
+		FROM auth_session
+		WHERE id = ?
+	`
+
+	var session model.Session
+	var revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Role,
+		&session.Username,
+		&session.RefreshHash,
+		&session.PasswordChangeRequired,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&revokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying session: %w", err)
+	}
+
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return &session, nil
+}
+
+// RotateRefreshHash 用新的刷新令牌哈希替换旧哈希，并记录被换下的旧哈希
+// 只有旧哈希仍然匹配且会话未吊销时才更新，并发刷新中后到的请求会得到ErrNotFound
+func (r *SQLSessionRepository) RotateRefreshHash(ctx context.Context, id string, oldHash string, newHash string, usedAt time.Time, expiresAt time.Time) error {
+	query := `
+		UPDATE auth_session SET refresh_hash = ?, last_used_at = ?, expires_at = ?
+		WHERE id = ? AND refresh_hash = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, newHash, usedAt, expiresAt, id, oldHash)
+	if err != nil {
+		return fmt.Errorf("error rotating refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	// 记录被换下的旧哈希，之后再出现时可以识别为重放
+	query = `INSERT INTO auth_session_rotated (session_id, refresh_hash, rotated_at) VALUES (?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, query, id, oldHash, usedAt); err != nil {
+		return fmt.Errorf("error recording rotated refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// WasRotated 判断哈希是否为该会话已经轮换掉的刷新令牌
+func (r *SQLSessionRepository) WasRotated(ctx context.Context, id string, hash string) (bool, error) {
+	query := `SELECT COUNT(*) FROM auth_session_rotated WHERE session_id = ? AND refresh_hash = ?`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, id, hash).Scan(&count); err != nil {
+		return false, fmt.Errorf("error querying rotated refresh token: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Revoke 吊销单个会话
+func (r *SQLSessionRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	query := `UPDATE auth_session SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, revokedAt, id); err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeByUser 吊销某个用户的全部会话，返回吊销的数量
+func (r *SQLSessionRepository) RevokeByUser(ctx context.Context, userID string, role string, revokedAt time.Time) (int64, error) {
+	query := `UPDATE auth_session SET revoked_at = ? WHERE user_id = ? AND role = ? AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, revokedAt, userID, role)
+	if err != nil {
+		return 0, fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,145 @@
+package repository_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/internal/repository/repotest"
+	"github.com/yourusername/student-management-system/pkg/dialect"
+	"github.com/yourusername/student-management-system/pkg/migrate"
+)
+
+// TestSQLConformance 在各数据库方言上运行仓储一致性测试。SQLite总是运行；
+// MySQL和PostgreSQL需要通过TEST_MYSQL_DSN（需带parseTime=true）和TEST_POSTGRES_DSN
+// 指定一个可以随意清空的测试库。
+func TestSQLConformance(t *testing.T) {
+	t.Run(dialect.SQLite, func(t *testing.T) {
+		// 只迁移一次，每个用例复制一份模板库
+		template := filepath.Join(t.TempDir(), "template.db")
+		db, d, err := dialect.Open(dialect.SQLite, sqliteDSN(template))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		if _, err := migrate.New(db, d, loadMigrations(t, d)).Up(0); err != nil {
+			t.Fatalf("Up() error = %v", err)
+		}
+		db.Close()
+
+		repotest.Run(t, func(t *testing.T) *repotest.Stores {
+			path := filepath.Join(t.TempDir(), "conformance.db")
+			copyFile(t, template, path)
+
+			db, d, err := dialect.Open(dialect.SQLite, sqliteDSN(path))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+			return sqlStores(repository.NewDB(db, d))
+		})
+	})
+
+	servers := []struct {
+		driver string
+		env    string
+	}{
+		{dialect.MySQL, "TEST_MYSQL_DSN"},
+		{dialect.Postgres, "TEST_POSTGRES_DSN"},
+	}
+	for _, server := range servers {
+		server := server
+		t.Run(server.driver, func(t *testing.T) {
+			dsn := os.Getenv(server.env)
+			if dsn == "" {
+				t.Skipf("%s not set", server.env)
+			}
+
+			db, d, err := dialect.Open(server.driver, dsn)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer db.Close()
+
+			migrations := loadMigrations(t, d)
+			migrator := migrate.New(db, d, migrations)
+
+			repotest.Run(t, func(t *testing.T) *repotest.Stores {
+				// 回滚全部迁移后重新执行，每个用例从空库开始
+				if _, err := migrator.Down(len(migrations)); err != nil {
+					t.Fatalf("Down() error = %v", err)
+				}
+				if _, err := migrator.Up(0); err != nil {
+					t.Fatalf("Up() error = %v", err)
+				}
+				return sqlStores(repository.NewDB(db, d))
+			})
+		})
+	}
+}
+
+func sqlStores(db *repository.DB) *repotest.Stores {
+	return &repotest.Stores{
+		Students:       repository.NewStudentRepository(db),
+		Instructors:    repository.NewInstructorRepository(db),
+		Departments:    repository.NewDepartmentRepository(db),
+		Courses:        repository.NewCourseRepository(db),
+		Classrooms:     repository.NewClassroomRepository(db),
+		TimeSlots:      repository.NewTimeSlotRepository(db),
+		Sections:       repository.NewSectionRepository(db),
+		Takes:          repository.NewTakesRepository(db),
+		Teaches:        repository.NewTeachesRepository(db),
+		Advisors:       repository.NewAdvisorRepository(db),
+		Prereqs:        repository.NewPrereqRepository(db),
+		LoginAttempts:  repository.NewLoginAttemptRepository(db),
+		Roles:          repository.NewRoleRepository(db),
+		Waitlist:       repository.NewWaitlistRepository(db),
+		Terms:          repository.NewTermRepository(db),
+		Tickets:        repository.NewTimeTicketRepository(db),
+		Overloads:      repository.NewOverloadRepository(db),
+		Carts:          repository.NewCartRepository(db),
+		Overrides:      repository.NewOverrideRepository(db),
+		Admins:         repository.NewAdminRepository(db),
+		Sessions:       repository.NewSessionRepository(db),
+		PasswordResets: repository.NewPasswordResetRepository(db),
+		TwoFactor:      repository.NewTwoFactorRepository(db),
+		Audit:          repository.NewAuditRepository(db),
+		APIKeys:        repository.NewAPIKeyRepository(db),
+		OIDC:           repository.NewOIDCRepository(db),
+	}
+}
+
+func sqliteDSN(path string) string {
+	return "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+}
+
+func loadMigrations(t *testing.T, d dialect.Dialect) []*migrate.Migration {
+	t.Helper()
+	migrations, err := migrate.Load(os.DirFS(filepath.Join("../../scripts/migrations", d.Name())))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return migrations
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,34 +1,34 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // SQLStudentRepository 实现StudentRepository接口
 type SQLStudentRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewStudentRepository 创建学生仓储实例
-func NewStudentRepository(db *sql.DB) StudentRepository {
+func NewStudentRepository(db DBTX) StudentRepository {
 	return &SQLStudentRepository{db: db}
 }
 
 // GetByID 根据ID查找学生
-func (r *SQLStudentRepository) GetByID(id string) (*model.Student, error) {
-	query := `SELECT id, name, dept_name, tot_cred, password, salt FROM student WHERE id = ?`
-	row := r.db.QueryRow(query, id)
+func (r *SQLStudentRepository) GetByID(ctx context.Context, id string) (*model.Student, error) {
+	query := `SELECT id, name, dept_name, tot_cred, password, salt, must_change_password FROM student WHERE id = ?`
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	var student model.Student
-	err := row.Scan(&student.ID, &student.Name, &student.Dept, &student.TotCred, &student.Password, &student.Salt)
+	err := row.Scan(&student.ID, &student.Name, &student.Dept, &student.TotCred, &student.Password, &student.Salt, &student.MustChangePassword)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("student not found: %w", err)
+			return nil, fmt.Errorf("student not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error scanning student: %w", err)
 	}
@@ -36,46 +36,30 @@ func (r *SQLStudentRepository) GetByID(id string) (*model.Student, error) {
 	return &student, nil
 }
 
-// List 查找所有学生
-func (r *SQLStudentRepository) List(page, pageSize int) ([]*model.Student, int64, error) {
-	var total int64
-	countQuery := `SELECT COUNT(*) FROM student`
-	err := r.db.QueryRow(countQuery).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("error counting students: %w", err)
-	}
-
-	offset := (page - 1) * pageSize
-	query := `SELECT id, name, dept_name, tot_cred FROM student LIMIT ? OFFSET ?`
-	rows, err := r.db.Query(query, pageSize, offset)
-	if err != nil {
-		return nil, 0, fmt.Errorf("error querying students: %w", err)
-	}
-	defer rows.Close()
+// studentListTable 学生列表的查询方式
+var studentListTable = listTable{
+	fields:  model.StudentListFields,
+	name:    "students",
+	selects: `id, name, dept_name, tot_cred`,
+	from:    `student`,
+	columns: map[string]string{"id": "id", "name": "name", "dept": "dept_name", "tot_cred": "tot_cred"},
+}
 
-	var students []*model.Student
-	for rows.Next() {
+// List 按分页、排序和过滤条件查找学生
+func (r *SQLStudentRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Student], error) {
+	return list(ctx, r.db, studentListTable, q, func(row rowScanner) (*model.Student, error) {
 		var student model.Student
-		err := rows.Scan(&student.ID, &student.Name, &student.Dept, &student.TotCred)
-		if err != nil {
-			return nil, 0, fmt.Errorf("error scanning student: %w", err)
-		}
-		students = append(students, &student)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating students: %w", err)
-	}
-
-	return students, total, nil
+		err := row.Scan(&student.ID, &student.Name, &student.Dept, &student.TotCred)
+		return &student, err
+	})
 }
 
 // Create 创建学生
-func (r *SQLStudentRepository) Create(student *model.Student) error {
-	query := `INSERT INTO student (id, name, dept_name, tot_cred, password, salt) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err := r.db.Exec(query, student.ID, student.Name, student.Dept, student.TotCred, student.Password, student.Salt)
+func (r *SQLStudentRepository) Create(ctx context.Context, student *model.Student) error {
+	query := `INSERT INTO student (id, name, dept_name, tot_cred, password, salt, must_change_password) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, student.ID, student.Name, student.Dept, student.TotCred, student.Password, student.Salt, student.MustChangePassword)
 	if err != nil {
-		if strings.Contains(err.Error(), "Duplicate entry") {
+		if errors.Is(err, ErrDuplicate) {
 			return fmt.Errorf("student already exists: %w", err)
 		}
 		return fmt.Errorf("error creating student: %w", err)
@@ -85,9 +69,9 @@ func (r *SQLStudentRepository) Create(student *model.Student) error {
 }
 
 // Update 更新学生
-func (r *SQLStudentRepository) Update(student *model.Student) error {
+func (r *SQLStudentRepository) Update(ctx context.Context, student *model.Student) error {
 	query := `UPDATE student SET name = ?, dept_name = ?, tot_cred = ? WHERE id = ?`
-	result, err := r.db.Exec(query, student.Name, student.Dept, student.TotCred, student.ID)
+	result, err := r.db.ExecContext(ctx, query, student.Name, student.Dept, student.TotCred, student.ID)
 	if err != nil {
 		return fmt.Errorf("error updating student: %w", err)
 	}
@@ -98,16 +82,16 @@ func (r *SQLStudentRepository) Update(student *model.Student) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("student not found")
+		return fmt.Errorf("student not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // Delete 删除学生
-func (r *SQLStudentRepository) Delete(id string) error {
+func (r *SQLStudentRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM student WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("error deleting student: %w", err)
 	}
@@ -118,17 +102,17 @@ func (r *SQLStudentRepository) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("student not found")
+		return fmt.Errorf("student not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // ExistsByID 检查指定ID的学生是否存在
-func (r *SQLStudentRepository) ExistsByID(id string) (bool, error) {
+func (r *SQLStudentRepository) ExistsByID(ctx context.Context, id string) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM student WHERE id = ?)`
-	err := r.db.QueryRow(query, id).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("error checking student existence: %w", err)
 	}
@@ -136,10 +120,10 @@ func (r *SQLStudentRepository) ExistsByID(id string) (bool, error) {
 }
 
 // Search 搜索学生
-func (r *SQLStudentRepository) Search(query string) ([]*model.Student, error) {
+func (r *SQLStudentRepository) Search(ctx context.Context, query string) ([]*model.Student, error) {
 	sqlQuery := `SELECT id, name, dept_name, tot_cred FROM student WHERE id LIKE ? OR name LIKE ? OR dept_name LIKE ?`
 	pattern := "%" + query + "%"
-	rows, err := r.db.Query(sqlQuery, pattern, pattern, pattern)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, pattern, pattern, pattern)
 	if err != nil {
 		return nil, fmt.Errorf("error searching students: %w", err)
 	}
@@ -163,9 +147,9 @@ func (r *SQLStudentRepository) Search(query string) ([]*model.Student, error) {
 }
 
 // UpdatePassword 更新学生密码
-func (r *SQLStudentRepository) UpdatePassword(id, hashedPassword, salt string) error {
-	query := `UPDATE student SET password = ?, salt = ? WHERE id = ?`
-	result, err := r.db.Exec(query, hashedPassword, salt, id)
+func (r *SQLStudentRepository) UpdatePassword(ctx context.Context, id, hashedPassword, salt string, mustChange bool) error {
+	query := `UPDATE student SET password = ?, salt = ?, must_change_password = ? WHERE id = ?`
+	result, err := r.db.ExecContext(ctx, query, hashedPassword, salt, mustChange, id)
 	if err != nil {
 		return fmt.Errorf("error updating student password: %w", err)
 	}
@@ -176,7 +160,7 @@ func (r *SQLStudentRepository) UpdatePassword(id, hashedPassword, salt string) e
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("student not found")
+		return fmt.Errorf("student not found: %w", ErrNotFound)
 	}
 
 	return nil
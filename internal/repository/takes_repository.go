@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -9,41 +10,43 @@ import (
 
 // TakesRepository 定义学生选课仓库接口
 type TakesRepository interface {
-	FindByStudentID(studentID string) ([]*model.Takes, error)
-	FindByStudentAndSection(studentID, sectionID string) (*model.Takes, error)
-	FindBySection(sectionID string) ([]*model.Takes, error)
-	FindBySectionID(sectionID string) ([]*model.Takes, error)
-	Create(takes *model.Takes) error
-	Delete(studentID, sectionID string) error
-	UpdateGrade(studentID, sectionID, grade string) error
-	GetStudentTranscript(studentID string) (*model.Transcript, error)
-	GetCurrentCourses(studentID string, semester string, year int) ([]*model.Takes, error)
-	CheckTimeConflict(studentID, sectionID string) (bool, error)
+	FindByStudentID(ctx context.Context, studentID string) ([]*model.Takes, error)
+	FindByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.Takes, error)
+	FindBySection(ctx context.Context, key model.SectionKey) ([]*model.Takes, error)
+	FindBySectionID(ctx context.Context, key model.SectionKey) ([]*model.Takes, error)
+	Create(ctx context.Context, takes *model.Takes) error
+	Delete(ctx context.Context, studentID string, key model.SectionKey) error
+	UpdateGrade(ctx context.Context, studentID string, key model.SectionKey, grade string) error
+	GetStudentTranscript(ctx context.Context, studentID string) (*model.Transcript, error)
+	GetCurrentCourses(ctx context.Context, studentID string, semester string, year int) ([]*model.Takes, error)
+	CheckTimeConflict(ctx context.Context, studentID string, key model.SectionKey) (bool, error)
+	FindTimeConflicts(ctx context.Context, studentID string, key model.SectionKey) ([]*model.TimeConflict, error)
+	GetTermCredits(ctx context.Context, studentID string, semester string, year int) (float64, error)
 }
 
 // SQLTakesRepository 实现TakesRepository接口
 type SQLTakesRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewTakesRepository 创建学生选课仓库实例
-func NewTakesRepository(db *sql.DB) TakesRepository {
+func NewTakesRepository(db DBTX) TakesRepository {
 	return &SQLTakesRepository{db: db}
 }
 
 // FindByStudentID 根据学生ID查找选课记录
-func (r *SQLTakesRepository) FindByStudentID(studentID string) ([]*model.Takes, error) {
+func (r *SQLTakesRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.Takes, error) {
 	query := `
-		SELECT t.student_id, t.course_id, t.sec_id, t.semester, t.year, t.grade,
+		SELECT t.id, t.course_id, t.sec_id, t.semester, t.year, t.grade,
 		       c.title, c.dept_name, c.credits,
 		       s.building, s.room_number, s.time_slot_id
 		FROM takes t
 		JOIN course c ON t.course_id = c.course_id
-		JOIN section s ON t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
-		WHERE t.student_id = ?
+		JOIN section s ON t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
+		WHERE t.id = ?
 	`
 
-	rows, err := r.db.Query(query, studentID)
+	rows, err := r.db.QueryContext(ctx, query, studentID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying takes: %w", err)
 	}
@@ -92,11 +95,11 @@ func (r *SQLTakesRepository) FindByStudentID(studentID string) ([]*model.Takes,
 }
 
 // FindByStudentAndSection 根据学生ID和课程段ID查找选课记录
-func (r *SQLTakesRepository) FindByStudentAndSection(studentID, sectionID string) (*model.Takes, error) {
-	query := `SELECT student_id, course_id, sec_id, semester, year, grade FROM takes WHERE student_id = ? AND sec_id = ?`
+func (r *SQLTakesRepository) FindByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.Takes, error) {
+	query := `SELECT id, course_id, sec_id, semester, year, grade FROM takes WHERE id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
 	var takes model.Takes
-	err := r.db.QueryRow(query, studentID, sectionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, studentID, key.CourseID, key.SecID, key.Semester, key.Year).Scan(
 		&takes.StudentID,
 		&takes.CourseID,
 		&takes.SectionID,
@@ -107,7 +110,7 @@ func (r *SQLTakesRepository) FindByStudentAndSection(studentID, sectionID string
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("takes record not found")
+			return nil, fmt.Errorf("takes record not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error querying takes: %w", err)
 	}
@@ -115,17 +118,17 @@ func (r *SQLTakesRepository) FindByStudentAndSection(studentID, sectionID string
 	return &takes, nil
 }
 
-// FindBySection 根据课程段ID查找所有选课记录
-func (r *SQLTakesRepository) FindBySection(sectionID string) ([]*model.Takes, error) {
+// FindBySection 根据课程段主键查找所有选课记录
+func (r *SQLTakesRepository) FindBySection(ctx context.Context, key model.SectionKey) ([]*model.Takes, error) {
 	query := `
-		SELECT t.student_id, t.course_id, t.sec_id, t.semester, t.year, t.grade,
+		SELECT t.id, t.course_id, t.sec_id, t.semester, t.year, t.grade,
 		       s.name, s.dept_name, s.tot_cred
 		FROM takes t
-		JOIN student s ON t.student_id = s.id
-		WHERE t.sec_id = ?
+		JOIN student s ON t.id = s.id
+		WHERE t.course_id = ? AND t.sec_id = ? AND t.semester = ? AND t.year = ?
 	`
 
-	rows, err := r.db.Query(query, sectionID)
+	rows, err := r.db.QueryContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year)
 	if err != nil {
 		return nil, fmt.Errorf("error querying takes: %w", err)
 	}
@@ -164,10 +167,10 @@ func (r *SQLTakesRepository) FindBySection(sectionID string) ([]*model.Takes, er
 }
 
 // Create 创建选课记录
-func (r *SQLTakesRepository) Create(takes *model.Takes) error {
-	query := `INSERT INTO takes (student_id, course_id, sec_id, semester, year, grade) VALUES (?, ?, ?, ?, ?, ?)`
+func (r *SQLTakesRepository) Create(ctx context.Context, takes *model.Takes) error {
+	query := `INSERT INTO takes (id, course_id, sec_id, semester, year, grade) VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		takes.StudentID,
 		takes.CourseID,
 		takes.SectionID,
@@ -184,10 +187,10 @@ func (r *SQLTakesRepository) Create(takes *model.Takes) error {
 }
 
 // Delete 删除选课记录
-func (r *SQLTakesRepository) Delete(studentID, sectionID string) error {
-	query := `DELETE FROM takes WHERE student_id = ? AND sec_id = ?`
+func (r *SQLTakesRepository) Delete(ctx context.Context, studentID string, key model.SectionKey) error {
+	query := `DELETE FROM takes WHERE id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
-	result, err := r.db.Exec(query, studentID, sectionID)
+	result, err := r.db.ExecContext(ctx, query, studentID, key.CourseID, key.SecID, key.Semester, key.Year)
 	if err != nil {
 		return fmt.Errorf("error deleting takes: %w", err)
 	}
@@ -198,17 +201,17 @@ func (r *SQLTakesRepository) Delete(studentID, sectionID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("takes record not found")
+		return fmt.Errorf("takes record not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // UpdateGrade 更新成绩
-func (r *SQLTakesRepository) UpdateGrade(studentID, sectionID, grade string) error {
-	query := `UPDATE takes SET grade = ? WHERE student_id = ? AND sec_id = ?`
+func (r *SQLTakesRepository) UpdateGrade(ctx context.Context, studentID string, key model.SectionKey, grade string) error {
+	query := `UPDATE takes SET grade = ? WHERE id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
-	result, err := r.db.Exec(query, grade, studentID, sectionID)
+	result, err := r.db.ExecContext(ctx, query, grade, studentID, key.CourseID, key.SecID, key.Semester, key.Year)
 	if err != nil {
 		return fmt.Errorf("error updating grade: %w", err)
 	}
@@ -219,18 +222,18 @@ func (r *SQLTakesRepository) UpdateGrade(studentID, sectionID, grade string) err
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("takes record not found")
+		return fmt.Errorf("takes record not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // GetStudentTranscript 获取学生成绩单
-func (r *SQLTakesRepository) GetStudentTranscript(studentID string) (*model.Transcript, error) {
+func (r *SQLTakesRepository) GetStudentTranscript(ctx context.Context, studentID string) (*model.Transcript, error) {
 	// 首先获取学生信息
 	studentQuery := `SELECT id, name, dept_name, tot_cred FROM student WHERE id = ?`
 	var student model.Student
-	err := r.db.QueryRow(studentQuery, studentID).Scan(
+	err := r.db.QueryRowContext(ctx, studentQuery, studentID).Scan(
 		&student.ID,
 		&student.Name,
 		&student.Dept,
@@ -246,11 +249,11 @@ func (r *SQLTakesRepository) GetStudentTranscript(studentID string) (*model.Tran
 		       c.title, c.dept_name, c.credits
 		FROM takes t
 		JOIN course c ON t.course_id = c.course_id
-		WHERE t.student_id = ?
+		WHERE t.id = ?
 		ORDER BY t.year DESC, t.semester DESC
 	`
 
-	rows, err := r.db.Query(query, studentID)
+	rows, err := r.db.QueryContext(ctx, query, studentID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying transcript: %w", err)
 	}
@@ -263,17 +266,17 @@ func (r *SQLTakesRepository) GetStudentTranscript(studentID string) (*model.Tran
 
 	for rows.Next() {
 		var courseGrade model.CourseGrade
-		var semester, year string
+		var secID, deptName string
 		var grade sql.NullString
 
 		err := rows.Scan(
 			&courseGrade.CourseID,
-			&semester,
-			&year,
-			&grade,
-			&courseGrade.Title,
+			&secID,
 			&courseGrade.Semester,
 			&courseGrade.Year,
+			&grade,
+			&courseGrade.Title,
+			&deptName,
 			&courseGrade.Credits,
 		)
 		if err != nil {
@@ -287,11 +290,12 @@ func (r *SQLTakesRepository) GetStudentTranscript(studentID string) (*model.Tran
 		courseGrade.Grade = gradeStr
 
 		// 计算绩点
-		courseGrade.GradePoint = calculateGradePoint(gradeStr)
+		courseGrade.GradePoint = model.GradePoint(gradeStr)
 
 		transcript.Courses = append(transcript.Courses, courseGrade)
 
-		if gradeStr != "" && gradeStr != "F" {
+		// 退选（W）不计入学分和GPA
+		if gradeStr != "" && gradeStr != "F" && gradeStr != model.GradeWithdrawn {
 			totalCredits += courseGrade.Credits
 			totalGradePoints += courseGrade.Credits * courseGrade.GradePoint
 		}
@@ -309,21 +313,38 @@ func (r *SQLTakesRepository) GetStudentTranscript(studentID string) (*model.Tran
 	return &transcript, nil
 }
 
+// GetTermCredits 统计学生某学期已选课程的总学分，退选（W）的课程不计入
+func (r *SQLTakesRepository) GetTermCredits(ctx context.Context, studentID string, semester string, year int) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(c.credits), 0)
+		FROM takes t
+		JOIN course c ON t.course_id = c.course_id
+		WHERE t.id = ? AND t.semester = ? AND t.year = ? AND (t.grade IS NULL OR t.grade <> 'W')
+	`
+
+	var credits float64
+	if err := r.db.QueryRowContext(ctx, query, studentID, semester, year).Scan(&credits); err != nil {
+		return 0, fmt.Errorf("error getting term credits: %w", err)
+	}
+
+	return credits, nil
+}
+
 // GetCurrentCourses 获取学生当前学期的课程
-func (r *SQLTakesRepository) GetCurrentCourses(studentID string, semester string, year int) ([]*model.Takes, error) {
+func (r *SQLTakesRepository) GetCurrentCourses(ctx context.Context, studentID string, semester string, year int) ([]*model.Takes, error) {
 	query := `
-		SELECT t.student_id, t.course_id, t.sec_id, t.semester, t.year, t.grade,
+		SELECT t.id, t.course_id, t.sec_id, t.semester, t.year, t.grade,
 		       c.title, c.dept_name, c.credits,
 		       s.building, s.room_number, s.time_slot_id,
 		       ts.day, ts.start_hr, ts.start_min, ts.end_hr, ts.end_min
 		FROM takes t
 		JOIN course c ON t.course_id = c.course_id
-		JOIN section s ON t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
+		JOIN section s ON t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
 		JOIN time_slot ts ON s.time_slot_id = ts.time_slot_id
-		WHERE t.student_id = ? AND t.semester = ? AND t.year = ?
+		WHERE t.id = ? AND t.semester = ? AND t.year = ?
 	`
 
-	rows, err := r.db.Query(query, studentID, semester, year)
+	rows, err := r.db.QueryContext(ctx, query, studentID, semester, year)
 	if err != nil {
 		return nil, fmt.Errorf("error querying current courses: %w", err)
 	}
@@ -398,23 +419,32 @@ func (r *SQLTakesRepository) GetCurrentCourses(studentID string, semester string
 	return takesList, nil
 }
 
-// FindBySectionID 根据课程段ID查找选课记录
-func (r *SQLTakesRepository) FindBySectionID(sectionID string) ([]*model.Takes, error) {
-	return r.FindBySection(sectionID)
+// FindBySectionID 根据课程段主键查找选课记录
+func (r *SQLTakesRepository) FindBySectionID(ctx context.Context, key model.SectionKey) ([]*model.Takes, error) {
+	return r.FindBySection(ctx, key)
 }
 
 // CheckTimeConflict 检查时间冲突
-func (r *SQLTakesRepository) CheckTimeConflict(studentID, sectionID string) (bool, error) {
+func (r *SQLTakesRepository) CheckTimeConflict(ctx context.Context, studentID string, key model.SectionKey) (bool, error) {
+	conflicts, err := r.FindTimeConflicts(ctx, studentID, key)
+	if err != nil {
+		return false, err
+	}
+	return len(conflicts) > 0, nil
+}
+
+// FindTimeConflicts 查找学生同学期已选课程中与该课程段时间重叠的课程段及冲突的时间段
+func (r *SQLTakesRepository) FindTimeConflicts(ctx context.Context, studentID string, key model.SectionKey) ([]*model.TimeConflict, error) {
 	// 获取要选的课程的时间段
 	timeSlotQuery := `
-		SELECT ts.time_slot_id, ts.day, ts.start_hr, ts.start_min, ts.end_hr, ts.end_min
+		SELECT ts.day, ts.start_hr, ts.start_min, ts.end_hr, ts.end_min
 		FROM section s
 		JOIN time_slot ts ON s.time_slot_id = ts.time_slot_id
-		WHERE s.sec_id = ?
+		WHERE s.course_id = ? AND s.sec_id = ? AND s.semester = ? AND s.year = ?
 	`
-	timeSlotRows, err := r.db.Query(timeSlotQuery, sectionID)
+	timeSlotRows, err := r.db.QueryContext(ctx, timeSlotQuery, key.CourseID, key.SecID, key.Semester, key.Year)
 	if err != nil {
-		return false, fmt.Errorf("error querying time slot: %w", err)
+		return nil, fmt.Errorf("error querying time slot: %w", err)
 	}
 	defer timeSlotRows.Close()
 
@@ -428,66 +458,75 @@ func (r *SQLTakesRepository) CheckTimeConflict(studentID, sectionID string) (boo
 
 	var newTimeSlots []TimeSlotInfo
 	for timeSlotRows.Next() {
-		var timeSlotID, day string
-		var startHr, startMin, endHr, endMin int
-		err := timeSlotRows.Scan(&timeSlotID, &day, &startHr, &startMin, &endHr, &endMin)
+		var slot TimeSlotInfo
+		err := timeSlotRows.Scan(&slot.Day, &slot.StartHr, &slot.StartMin, &slot.EndHr, &slot.EndMin)
 		if err != nil {
-			return false, fmt.Errorf("error scanning time slot: %w", err)
+			return nil, fmt.Errorf("error scanning time slot: %w", err)
 		}
-		newTimeSlots = append(newTimeSlots, TimeSlotInfo{Day: day, StartHr: startHr, StartMin: startMin, EndHr: endHr, EndMin: endMin})
+		newTimeSlots = append(newTimeSlots, slot)
 	}
 
 	if err := timeSlotRows.Err(); err != nil {
-		return false, fmt.Errorf("error iterating time slots: %w", err)
+		return nil, fmt.Errorf("error iterating time slots: %w", err)
+	}
+
+	if len(newTimeSlots) == 0 {
+		return nil, nil
 	}
 
-	// 获取学生当前学期已选课程的时间段
+	// 获取学生同学期已选课程的时间段，已退选（W）的课程不参与冲突检查
 	currentCoursesQuery := `
-		SELECT ts.day, ts.start_hr, ts.start_min, ts.end_hr, ts.end_min
+		SELECT t.course_id, t.sec_id, ts.time_slot_id, ts.day, ts.start_hr, ts.start_min, ts.end_hr, ts.end_min
 		FROM takes t
-		JOIN section s ON t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
+		JOIN section s ON t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
 		JOIN time_slot ts ON s.time_slot_id = ts.time_slot_id
-		WHERE t.student_id = ? AND t.sec_id <> ?
+		WHERE t.id = ? AND t.semester = ? AND t.year = ?
+		  AND NOT (t.course_id = ? AND t.sec_id = ?)
+		  AND (t.grade IS NULL OR t.grade <> 'W')
 	`
-	currentCoursesRows, err := r.db.Query(currentCoursesQuery, studentID, sectionID)
+	currentCoursesRows, err := r.db.QueryContext(ctx, currentCoursesQuery, studentID, key.Semester, key.Year, key.CourseID, key.SecID)
 	if err != nil {
-		return false, fmt.Errorf("error querying current courses: %w", err)
+		return nil, fmt.Errorf("error querying current courses: %w", err)
 	}
 	defer currentCoursesRows.Close()
 
-	var currentTimeSlots []TimeSlotInfo
+	var conflicts []*model.TimeConflict
 	for currentCoursesRows.Next() {
-		var day string
-		var startHr, startMin, endHr, endMin int
-		err := currentCoursesRows.Scan(&day, &startHr, &startMin, &endHr, &endMin)
+		var courseID, secID, timeSlotID string
+		var current TimeSlotInfo
+		err := currentCoursesRows.Scan(&courseID, &secID, &timeSlotID, &current.Day, &current.StartHr, &current.StartMin, &current.EndHr, &current.EndMin)
 		if err != nil {
-			return false, fmt.Errorf("error scanning current course: %w", err)
+			return nil, fmt.Errorf("error scanning current course: %w", err)
 		}
-		currentTimeSlots = append(currentTimeSlots, TimeSlotInfo{Day: day, StartHr: startHr, StartMin: startMin, EndHr: endHr, EndMin: endMin})
-	}
 
-	if err := currentCoursesRows.Err(); err != nil {
-		return false, fmt.Errorf("error iterating current courses: %w", err)
-	}
-
-	// 检查时间冲突
-	for _, newSlot := range newTimeSlots {
-		for _, currentSlot := range currentTimeSlots {
-			if newSlot.Day == currentSlot.Day {
-				// 检查时间重叠
-				newStart := newSlot.StartHr*60 + newSlot.StartMin
-				newEnd := newSlot.EndHr*60 + newSlot.EndMin
-				currentStart := currentSlot.StartHr*60 + currentSlot.StartMin
-				currentEnd := currentSlot.EndHr*60 + currentSlot.EndMin
-
-				if (newStart < currentEnd) && (newEnd > currentStart) {
-					return true, nil // 发现时间冲突
-				}
+		// 检查时间重叠
+		for _, newSlot := range newTimeSlots {
+			if newSlot.Day != current.Day {
+				continue
+			}
+			newStart := newSlot.StartHr*60 + newSlot.StartMin
+			newEnd := newSlot.EndHr*60 + newSlot.EndMin
+			currentStart := current.StartHr*60 + current.StartMin
+			currentEnd := current.EndHr*60 + current.EndMin
+
+			if (newStart < currentEnd) && (newEnd > currentStart) {
+				conflicts = append(conflicts, &model.TimeConflict{
+					Section:    model.SectionKey{CourseID: courseID, SecID: secID, Semester: key.Semester, Year: key.Year},
+					TimeSlotID: timeSlotID,
+					Day:        current.Day,
+					StartTime:  fmt.Sprintf("%02d:%02d", current.StartHr, current.StartMin),
+					EndTime:    fmt.Sprintf("%02d:%02d", current.EndHr, current.EndMin),
+				})
+				break
 			}
 		}
 	}
 
-	return false, nil // 没有时间冲突
+	if err := currentCoursesRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating current courses: %w", err)
+	}
+
+	return conflicts, nil
 }
 
 // convertDayToInt 将星期转换为数字
@@ -511,33 +550,3 @@ func convertDayToInt(day string) int {
 		return 0
 	}
 }
-
-// calculateGradePoint 计算绩点
-func calculateGradePoint(grade string) float64 {
-	switch grade {
-	case "A":
-		return 4.0
-	case "A-":
-		return 3.7
-	case "B+":
-		return 3.3
-	case "B":
-		return 3.0
-	case "B-":
-		return 2.7
-	case "C+":
-		return 2.3
-	case "C":
-		return 2.0
-	case "C-":
-		return 1.7
-	case "D+":
-		return 1.3
-	case "D":
-		return 1.0
-	case "F":
-		return 0.0
-	default:
-		return 0.0
-	}
-}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -9,37 +10,38 @@ import (
 
 // TeachesRepository 定义教学关系仓库接口
 type TeachesRepository interface {
-	FindByInstructorID(instructorID string) ([]*model.Teaches, error)
-	FindBySectionID(sectionID string) ([]*model.Teaches, error)
-	FindByInstructorAndSection(instructorID, sectionID string) (*model.Teaches, error)
-	Create(teaches *model.Teaches) error
-	Delete(instructorID, courseID, sectionID, semester string, year int) error
-	GetCurrentTeaching(instructorID string, semester string, year int) ([]*model.Teaches, error)
-	FindAll() ([]*model.Teaches, error)
+	FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Teaches, error)
+	FindBySectionID(ctx context.Context, key model.SectionKey) ([]*model.Teaches, error)
+	FindByInstructorAndSection(ctx context.Context, instructorID string, key model.SectionKey) (*model.Teaches, error)
+	Create(ctx context.Context, teaches *model.Teaches) error
+	Delete(ctx context.Context, instructorID string, key model.SectionKey) error
+	GetCurrentTeaching(ctx context.Context, instructorID string, semester string, year int) ([]*model.Teaches, error)
+	FindAll(ctx context.Context) ([]*model.Teaches, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Teaches], error)
 }
 
-// SQLTeachesRepository 实现TeachesRepository接口
+// SQLTeachesRepository 实现TeachesRepository接口，teaches表的ID列即教师ID
 type SQLTeachesRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewTeachesRepository 创建教学关系仓库实例
-func NewTeachesRepository(db *sql.DB) TeachesRepository {
+func NewTeachesRepository(db DBTX) TeachesRepository {
 	return &SQLTeachesRepository{db: db}
 }
 
 // FindAll 查找所有教学关系
-func (r *SQLTeachesRepository) FindAll() ([]*model.Teaches, error) {
+func (r *SQLTeachesRepository) FindAll(ctx context.Context) ([]*model.Teaches, error) {
 	query := `
-		SELECT t.id, t.instructor_id, t.course_id, t.sec_id, t.semester, t.year,
+		SELECT t.id, t.id AS instructor_id, t.course_id, t.sec_id, t.semester, t.year,
 		       c.title, c.dept_name, c.credits,
 		       s.building, s.room_number, s.time_slot_id
 		FROM teaches t
 		JOIN course c ON t.course_id = c.course_id
-		JOIN section s ON t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
+		JOIN section s ON t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying teaches: %w", err)
 	}
@@ -47,38 +49,11 @@ func (r *SQLTeachesRepository) FindAll() ([]*model.Teaches, error) {
 
 	var teachesList []*model.Teaches
 	for rows.Next() {
-		var teaches model.Teaches
-		var course model.Course
-		var section model.Section
-
-		err := rows.Scan(
-			&teaches.ID,
-			&teaches.InstructorID,
-			&teaches.CourseID,
-			&teaches.SectionID,
-			&teaches.Semester,
-			&teaches.Year,
-			&course.Title,
-			&course.Dept,
-			&course.Credits,
-			&section.Building,
-			&section.RoomNumber,
-			&section.TimeSlotID,
-		)
+		teaches, err := scanTeachesWithSection(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning teaches row: %w", err)
 		}
-
-		course.ID = teaches.CourseID
-		teaches.Course = &course
-
-		section.ID = teaches.SectionID
-		section.CourseID = teaches.CourseID
-		section.Semester = teaches.Semester
-		section.Year = teaches.Year
-		teaches.Section = &section
-
-		teachesList = append(teachesList, &teaches)
+		teachesList = append(teachesList, teaches)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -88,19 +63,79 @@ func (r *SQLTeachesRepository) FindAll() ([]*model.Teaches, error) {
 	return teachesList, nil
 }
 
+// teachesListTable 授课记录列表的查询方式
+var teachesListTable = listTable{
+	fields: model.TeachesListFields,
+	name:   "teaches",
+	selects: `t.id, t.id AS instructor_id, t.course_id, t.sec_id, t.semester, t.year,
+		c.title, c.dept_name, c.credits,
+		s.building, s.room_number, s.time_slot_id`,
+	from: `teaches t
+		JOIN course c ON t.course_id = c.course_id
+		JOIN section s ON t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year`,
+	columns: map[string]string{
+		"instructor_id": "t.id",
+		"course_id":     "t.course_id",
+		"section_id":    "t.sec_id",
+		"semester":      "t.semester",
+		"year":          "t.year",
+	},
+}
+
+// List 按分页、排序和过滤条件查找授课记录，附带课程和课程段信息
+func (r *SQLTeachesRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Teaches], error) {
+	return list(ctx, r.db, teachesListTable, q, scanTeachesWithSection)
+}
+
+// scanTeachesWithSection 扫描附带课程和课程段信息的授课记录
+func scanTeachesWithSection(row rowScanner) (*model.Teaches, error) {
+	var teaches model.Teaches
+	var course model.Course
+	var section model.Section
+
+	err := row.Scan(
+		&teaches.ID,
+		&teaches.InstructorID,
+		&teaches.CourseID,
+		&teaches.SectionID,
+		&teaches.Semester,
+		&teaches.Year,
+		&course.Title,
+		&course.Dept,
+		&course.Credits,
+		&section.Building,
+		&section.RoomNumber,
+		&section.TimeSlotID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	course.ID = teaches.CourseID
+	teaches.Course = &course
+
+	section.ID = teaches.SectionID
+	section.CourseID = teaches.CourseID
+	section.Semester = teaches.Semester
+	section.Year = teaches.Year
+	teaches.Section = &section
+
+	return &teaches, nil
+}
+
 // FindByInstructorID 根据教师ID查找教学关系
-func (r *SQLTeachesRepository) FindByInstructorID(instructorID string) ([]*model.Teaches, error) {
+func (r *SQLTeachesRepository) FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Teaches, error) {
 	query := `
-		SELECT t.id, t.instructor_id, t.course_id, t.sec_id, t.semester, t.year,
+		SELECT t.id, t.id AS instructor_id, t.course_id, t.sec_id, t.semester, t.year,
 		       c.title, c.dept_name, c.credits,
 		       s.building, s.room_number, s.time_slot_id
 		FROM teaches t
 		JOIN course c ON t.course_id = c.course_id
-		JOIN section s ON t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
-		WHERE t.instructor_id = ?
+		JOIN section s ON t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
+		WHERE t.id = ?
 	`
 
-	rows, err := r.db.Query(query, instructorID)
+	rows, err := r.db.QueryContext(ctx, query, instructorID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying teaches: %w", err)
 	}
@@ -148,17 +183,17 @@ func (r *SQLTeachesRepository) FindByInstructorID(instructorID string) ([]*model
 	return teachesList, nil
 }
 
-// FindBySectionID 根据课程段ID查找教学关系
-func (r *SQLTeachesRepository) FindBySectionID(sectionID string) ([]*model.Teaches, error) {
+// FindBySectionID 根据课程段主键查找教学关系
+func (r *SQLTeachesRepository) FindBySectionID(ctx context.Context, key model.SectionKey) ([]*model.Teaches, error) {
 	query := `
-		SELECT t.id, t.instructor_id, t.course_id, t.sec_id, t.semester, t.year,
+		SELECT t.id, t.id AS instructor_id, t.course_id, t.sec_id, t.semester, t.year,
 		       i.name, i.dept_name, i.salary
 		FROM teaches t
-		JOIN instructor i ON t.instructor_id = i.id
-		WHERE t.sec_id = ?
+		JOIN instructor i ON t.id = i.id
+		WHERE t.course_id = ? AND t.sec_id = ? AND t.semester = ? AND t.year = ?
 	`
 
-	rows, err := r.db.Query(query, sectionID)
+	rows, err := r.db.QueryContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year)
 	if err != nil {
 		return nil, fmt.Errorf("error querying teaches: %w", err)
 	}
@@ -196,12 +231,12 @@ func (r *SQLTeachesRepository) FindBySectionID(sectionID string) ([]*model.Teach
 	return teachesList, nil
 }
 
-// FindByInstructorAndSection 根据教师ID和课程段ID查找教学关系
-func (r *SQLTeachesRepository) FindByInstructorAndSection(instructorID, sectionID string) (*model.Teaches, error) {
-	query := `SELECT id, instructor_id, course_id, sec_id, semester, year FROM teaches WHERE instructor_id = ? AND sec_id = ?`
+// FindByInstructorAndSection 根据教师ID和课程段主键查找教学关系
+func (r *SQLTeachesRepository) FindByInstructorAndSection(ctx context.Context, instructorID string, key model.SectionKey) (*model.Teaches, error) {
+	query := `SELECT id, id AS instructor_id, course_id, sec_id, semester, year FROM teaches WHERE id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
 	var teaches model.Teaches
-	err := r.db.QueryRow(query, instructorID, sectionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, instructorID, key.CourseID, key.SecID, key.Semester, key.Year).Scan(
 		&teaches.ID,
 		&teaches.InstructorID,
 		&teaches.CourseID,
@@ -212,7 +247,7 @@ func (r *SQLTeachesRepository) FindByInstructorAndSection(instructorID, sectionI
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("teaches record not found")
+			return nil, fmt.Errorf("teaches record not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error querying teaches: %w", err)
 	}
@@ -221,10 +256,10 @@ func (r *SQLTeachesRepository) FindByInstructorAndSection(instructorID, sectionI
 }
 
 // Create 创建教学关系
-func (r *SQLTeachesRepository) Create(teaches *model.Teaches) error {
-	query := `INSERT INTO teaches (instructor_id, course_id, sec_id, semester, year) VALUES (?, ?, ?, ?, ?)`
+func (r *SQLTeachesRepository) Create(ctx context.Context, teaches *model.Teaches) error {
+	query := `INSERT INTO teaches (id, course_id, sec_id, semester, year) VALUES (?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		teaches.InstructorID,
 		teaches.CourseID,
 		teaches.SectionID,
@@ -240,10 +275,10 @@ func (r *SQLTeachesRepository) Create(teaches *model.Teaches) error {
 }
 
 // Delete 删除教学安排
-func (r *SQLTeachesRepository) Delete(instructorID, courseID, sectionID, semester string, year int) error {
+func (r *SQLTeachesRepository) Delete(ctx context.Context, instructorID string, key model.SectionKey) error {
 	query := `DELETE FROM teaches WHERE id = ? AND course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
 
-	result, err := r.db.Exec(query, instructorID, courseID, sectionID, semester, year)
+	result, err := r.db.ExecContext(ctx, query, instructorID, key.CourseID, key.SecID, key.Semester, key.Year)
 	if err != nil {
 		return fmt.Errorf("error deleting teaches: %w", err)
 	}
@@ -254,27 +289,27 @@ func (r *SQLTeachesRepository) Delete(instructorID, courseID, sectionID, semeste
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("teaches record not found")
+		return fmt.Errorf("teaches record not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // GetCurrentTeaching 获取教师当前学期的教学任务
-func (r *SQLTeachesRepository) GetCurrentTeaching(instructorID string, semester string, year int) ([]*model.Teaches, error) {
+func (r *SQLTeachesRepository) GetCurrentTeaching(ctx context.Context, instructorID string, semester string, year int) ([]*model.Teaches, error) {
 	query := `
-		SELECT t.id, t.instructor_id, t.course_id, t.sec_id, t.semester, t.year,
+		SELECT t.id, t.id AS instructor_id, t.course_id, t.sec_id, t.semester, t.year,
 		       c.title, c.dept_name, c.credits,
 		       s.building, s.room_number, s.time_slot_id,
 		       ts.day, ts.start_hr, ts.start_min, ts.end_hr, ts.end_min,
-		       (SELECT COUNT(*) FROM takes tk WHERE tk.sec_id = t.sec_id AND tk.semester = t.semester AND tk.year = t.year) as enrollment
+		       (SELECT COUNT(*) FROM takes tk WHERE tk.course_id = t.course_id AND tk.sec_id = t.sec_id AND tk.semester = t.semester AND tk.year = t.year) as enrollment
 		FROM teaches t
 		JOIN course c ON t.course_id = c.course_id
-		JOIN section s ON t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
+		JOIN section s ON t.course_id = s.course_id AND t.sec_id = s.sec_id AND t.semester = s.semester AND t.year = s.year
 		JOIN time_slot ts ON s.time_slot_id = ts.time_slot_id
-		WHERE t.instructor_id = ? AND t.semester = ? AND t.year = ?
+		WHERE t.id = ? AND t.semester = ? AND t.year = ?
 	`
-	rows, err := r.db.Query(query, instructorID, semester, year)
+	rows, err := r.db.QueryContext(ctx, query, instructorID, semester, year)
 	if err != nil {
 		return nil, fmt.Errorf("error querying current teaching: %w", err)
 	}
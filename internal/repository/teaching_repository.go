@@ -1,45 +1,46 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
+
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // TeachingRepository 定义教学仓库接口
 type TeachingRepository interface {
-	FindByInstructorID(instructorID string) ([]*model.Teaches, error)
-	FindBySectionID(sectionID string) ([]*model.Teaches, error)
-	Create(teaching *model.Teaches) error
-	Delete(instructorID, sectionID string) error
+	FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Teaches, error)
+	FindBySectionID(ctx context.Context, sectionID string) ([]*model.Teaches, error)
+	Create(ctx context.Context, teaching *model.Teaches) error
+	Delete(ctx context.Context, instructorID, sectionID string) error
 }
 
 // SQLTeachingRepository 实现TeachingRepository接口
 type SQLTeachingRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewTeachingRepository 创建教学仓库实例
-func NewTeachingRepository(db *sql.DB) TeachingRepository {
+func NewTeachingRepository(db DBTX) TeachingRepository {
 	return &SQLTeachingRepository{db: db}
 }
 
 // 实现所有接口方法...
-func (r *SQLTeachingRepository) FindByInstructorID(instructorID string) ([]*model.Teaches, error) {
+func (r *SQLTeachingRepository) FindByInstructorID(ctx context.Context, instructorID string) ([]*model.Teaches, error) {
 	// 实现代码
 	return nil, nil
 }
 
-func (r *SQLTeachingRepository) FindBySectionID(sectionID string) ([]*model.Teaches, error) {
+func (r *SQLTeachingRepository) FindBySectionID(ctx context.Context, sectionID string) ([]*model.Teaches, error) {
 	// 实现代码
 	return nil, nil
 }
 
-func (r *SQLTeachingRepository) Create(teaching *model.Teaches) error {
+func (r *SQLTeachingRepository) Create(ctx context.Context, teaching *model.Teaches) error {
 	// 实现代码
 	return nil
 }
 
-func (r *SQLTeachingRepository) Delete(instructorID, sectionID string) error {
+func (r *SQLTeachingRepository) Delete(ctx context.Context, instructorID, sectionID string) error {
 	// 实现代码
 	return nil
 }
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// TermRepository 定义校历仓库接口
+type TermRepository interface {
+	FindByTerm(ctx context.Context, semester string, year int) (*model.Term, error)
+	FindAll(ctx context.Context) ([]*model.Term, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Term], error)
+	Create(ctx context.Context, term *model.Term) error
+	Update(ctx context.Context, term *model.Term) error
+	Delete(ctx context.Context, semester string, year int) error
+}
+
+// SQLTermRepository 实现TermRepository接口
+type SQLTermRepository struct {
+	db DBTX
+}
+
+// NewTermRepository 创建校历仓库实例
+func NewTermRepository(db DBTX) TermRepository {
+	return &SQLTermRepository{db: db}
+}
+
+// FindByTerm 根据学期和年份查找校历
+func (r *SQLTermRepository) FindByTerm(ctx context.Context, semester string, year int) (*model.Term, error) {
+	query := `SELECT semester, year, registration_open, registration_close, add_drop_deadline, withdrawal_deadline, min_credits, max_credits FROM term_calendar WHERE semester = ? AND year = ?`
+
+	var term model.Term
+	err := r.db.QueryRowContext(ctx, query, semester, year).Scan(
+		&term.Semester,
+		&term.Year,
+		&term.RegistrationOpen,
+		&term.RegistrationClose,
+		&term.AddDropDeadline,
+		&term.WithdrawalDeadline,
+		&term.MinCredits,
+		&term.MaxCredits,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying term: %w", err)
+	}
+
+	return &term, nil
+}
+
+// FindAll 查找所有校历
+func (r *SQLTermRepository) FindAll(ctx context.Context) ([]*model.Term, error) {
+	query := `SELECT semester, year, registration_open, registration_close, add_drop_deadline, withdrawal_deadline, min_credits, max_credits FROM term_calendar ORDER BY year DESC, registration_open DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying terms: %w", err)
+	}
+	defer rows.Close()
+
+	var terms []*model.Term
+	for rows.Next() {
+		var term model.Term
+		err := rows.Scan(
+			&term.Semester,
+			&term.Year,
+			&term.RegistrationOpen,
+			&term.RegistrationClose,
+			&term.AddDropDeadline,
+			&term.WithdrawalDeadline,
+			&term.MinCredits,
+			&term.MaxCredits,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning term: %w", err)
+		}
+		terms = append(terms, &term)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating terms: %w", err)
+	}
+
+	return terms, nil
+}
+
+// termListTable 校历列表的查询方式
+var termListTable = listTable{
+	fields:  model.TermListFields,
+	name:    "terms",
+	selects: `semester, year, registration_open, registration_close, add_drop_deadline, withdrawal_deadline, min_credits, max_credits`,
+	from:    `term_calendar`,
+	columns: map[string]string{"semester": "semester", "year": "year", "registration_open": "registration_open"},
+}
+
+// List 按分页、排序和过滤条件查找校历
+func (r *SQLTermRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Term], error) {
+	return list(ctx, r.db, termListTable, q, func(row rowScanner) (*model.Term, error) {
+		var term model.Term
+		err := row.Scan(
+			&term.Semester,
+			&term.Year,
+			&term.RegistrationOpen,
+			&term.RegistrationClose,
+			&term.AddDropDeadline,
+			&term.WithdrawalDeadline,
+			&term.MinCredits,
+			&term.MaxCredits,
+		)
+		return &term, err
+	})
+}
+
+// Create 创建校历
+func (r *SQLTermRepository) Create(ctx context.Context, term *model.Term) error {
+	query := `INSERT INTO term_calendar (semester, year, registration_open, registration_close, add_drop_deadline, withdrawal_deadline, min_credits, max_credits) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		term.Semester,
+		term.Year,
+		term.RegistrationOpen,
+		term.RegistrationClose,
+		term.AddDropDeadline,
+		term.WithdrawalDeadline,
+		term.MinCredits,
+		term.MaxCredits,
+	)
+
+	if err != nil {
+		if errors.Is(err, ErrDuplicate) {
+			return fmt.Errorf("term already exists: %w", ErrDuplicate)
+		}
+		return fmt.Errorf("error creating term: %w", err)
+	}
+
+	return nil
+}
+
+// Update 更新校历
+func (r *SQLTermRepository) Update(ctx context.Context, term *model.Term) error {
+	query := `UPDATE term_calendar SET registration_open = ?, registration_close = ?, add_drop_deadline = ?, withdrawal_deadline = ?, min_credits = ?, max_credits = ? WHERE semester = ? AND year = ?`
+
+	result, err := r.db.ExecContext(ctx, query,
+		term.RegistrationOpen,
+		term.RegistrationClose,
+		term.AddDropDeadline,
+		term.WithdrawalDeadline,
+		term.MinCredits,
+		term.MaxCredits,
+		term.Semester,
+		term.Year,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating term: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete 删除校历
+func (r *SQLTermRepository) Delete(ctx context.Context, semester string, year int) error {
+	query := `DELETE FROM term_calendar WHERE semester = ? AND year = ?`
+
+	result, err := r.db.ExecContext(ctx, query, semester, year)
+	if err != nil {
+		return fmt.Errorf("error deleting term: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
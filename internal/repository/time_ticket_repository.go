@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// TimeTicketRepository 定义时间票仓库接口
+type TimeTicketRepository interface {
+	FindByStudentAndTerm(ctx context.Context, studentID string, semester string, year int) (*model.TimeTicket, error)
+	FindByTerm(ctx context.Context, semester string, year int) ([]*model.TimeTicket, error)
+	List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.TimeTicket], error)
+	DeleteByTerm(ctx context.Context, semester string, year int) error
+	Create(ctx context.Context, ticket *model.TimeTicket) error
+}
+
+// SQLTimeTicketRepository 实现TimeTicketRepository接口
+type SQLTimeTicketRepository struct {
+	db DBTX
+}
+
+// NewTimeTicketRepository 创建时间票仓库实例
+func NewTimeTicketRepository(db DBTX) TimeTicketRepository {
+	return &SQLTimeTicketRepository{db: db}
+}
+
+// FindByStudentAndTerm 查找学生在指定学期的时间票
+func (r *SQLTimeTicketRepository) FindByStudentAndTerm(ctx context.Context, studentID string, semester string, year int) (*model.TimeTicket, error) {
+	query := `SELECT student_id, semester, year, tier, start_time FROM time_ticket WHERE student_id = ? AND semester = ? AND year = ?`
+
+	var ticket model.TimeTicket
+	err := r.db.QueryRowContext(ctx, query, studentID, semester, year).Scan(
+		&ticket.StudentID,
+		&ticket.Semester,
+		&ticket.Year,
+		&ticket.Tier,
+		&ticket.StartTime,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying time ticket: %w", err)
+	}
+
+	return &ticket, nil
+}
+
+// FindByTerm 查找指定学期已发布的所有时间票
+func (r *SQLTimeTicketRepository) FindByTerm(ctx context.Context, semester string, year int) ([]*model.TimeTicket, error) {
+	query := `
+		SELECT t.student_id, t.semester, t.year, t.tier, t.start_time,
+		       s.name, s.dept_name, s.tot_cred
+		FROM time_ticket t
+		JOIN student s ON t.student_id = s.id
+		WHERE t.semester = ? AND t.year = ?
+		ORDER BY t.start_time, t.student_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, semester, year)
+	if err != nil {
+		return nil, fmt.Errorf("error querying time tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []*model.TimeTicket
+	for rows.Next() {
+		ticket, err := scanTimeTicketWithStudent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning time ticket: %w", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating time tickets: %w", err)
+	}
+
+	return tickets, nil
+}
+
+// timeTicketListTable 时间票列表的查询方式，附带学生信息
+var timeTicketListTable = listTable{
+	fields: model.TimeTicketListFields,
+	name:   "time tickets",
+	selects: `t.student_id, t.semester, t.year, t.tier, t.start_time,
+		s.name, s.dept_name, s.tot_cred`,
+	from: `time_ticket t JOIN student s ON t.student_id = s.id`,
+	columns: map[string]string{
+		"student_id": "t.student_id",
+		"semester":   "t.semester",
+		"year":       "t.year",
+		"tier":       "t.tier",
+		"start_time": "t.start_time",
+	},
+}
+
+// List 按分页、排序和过滤条件查找已发布的时间票
+func (r *SQLTimeTicketRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.TimeTicket], error) {
+	return list(ctx, r.db, timeTicketListTable, q, scanTimeTicketWithStudent)
+}
+
+// scanTimeTicketWithStudent 扫描附带学生信息的时间票
+func scanTimeTicketWithStudent(row rowScanner) (*model.TimeTicket, error) {
+	var ticket model.TimeTicket
+	var student model.StudentDTO
+	err := row.Scan(
+		&ticket.StudentID,
+		&ticket.Semester,
+		&ticket.Year,
+		&ticket.Tier,
+		&ticket.StartTime,
+		&student.Name,
+		&student.Dept,
+		&student.TotCred,
+	)
+	if err != nil {
+		return nil, err
+	}
+	student.ID = ticket.StudentID
+	ticket.Student = &student
+	return &ticket, nil
+}
+
+// DeleteByTerm 删除指定学期的所有时间票
+func (r *SQLTimeTicketRepository) DeleteByTerm(ctx context.Context, semester string, year int) error {
+	query := `DELETE FROM time_ticket WHERE semester = ? AND year = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, semester, year); err != nil {
+		return fmt.Errorf("error deleting time tickets: %w", err)
+	}
+
+	return nil
+}
+
+// Create 创建时间票
+func (r *SQLTimeTicketRepository) Create(ctx context.Context, ticket *model.TimeTicket) error {
+	query := `INSERT INTO time_ticket (student_id, semester, year, tier, start_time) VALUES (?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		ticket.StudentID,
+		ticket.Semester,
+		ticket.Year,
+		ticket.Tier,
+		ticket.StartTime,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating time ticket: %w", err)
+	}
+
+	return nil
+}
@@ -1,183 +1,111 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/yourusername/student-management-system/internal/model"
 )
 
 // TimeSlotRepository 定义时间段仓库接口
 type TimeSlotRepository interface {
-	FindByID(id string) (*model.TimeSlot, error)
-	FindAll() ([]*model.TimeSlot, error)
-	FindByDayOfWeek(dayOfWeek int) ([]*model.TimeSlot, error)
-	FindByTimeRange(startTime, endTime string) ([]*model.TimeSlot, error)
-	Create(timeSlot *model.TimeSlot) error
-	Update(timeSlot *model.TimeSlot) error
-	Delete(id string) error
+	FindByID(ctx context.Context, id string) (*model.TimeSlot, error)
+	FindAll(ctx context.Context) ([]*model.TimeSlot, error)
+	FindByDayOfWeek(ctx context.Context, dayOfWeek int) ([]*model.TimeSlot, error)
+	FindByTimeRange(ctx context.Context, startTime, endTime string) ([]*model.TimeSlot, error)
+	Create(ctx context.Context, timeSlot *model.TimeSlot) error
+	Update(ctx context.Context, timeSlot *model.TimeSlot) error
+	Delete(ctx context.Context, id string) error
 }
 
 // SQLTimeSlotRepository 实现TimeSlotRepository接口
 type SQLTimeSlotRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 // NewTimeSlotRepository 创建时间段仓库实例
-func NewTimeSlotRepository(db *sql.DB) TimeSlotRepository {
+func NewTimeSlotRepository(db DBTX) TimeSlotRepository {
 	return &SQLTimeSlotRepository{db: db}
 }
 
 // FindByID 根据ID查找时间段
-func (r *SQLTimeSlotRepository) FindByID(id string) (*model.TimeSlot, error) {
-	query := `SELECT id, start_time, end_time, days FROM time_slot WHERE id = ?`
-
-	var timeSlot model.TimeSlot
-	var daysStr string
-
-	err := r.db.QueryRow(query, id).Scan(
-		&timeSlot.ID,
-		&timeSlot.StartTime,
-		&timeSlot.EndTime,
-		&daysStr,
-	)
+func (r *SQLTimeSlotRepository) FindByID(ctx context.Context, id string) (*model.TimeSlot, error) {
+	query := `SELECT time_slot_id, day, start_hr, start_min, end_hr, end_min FROM time_slot WHERE time_slot_id = ?`
 
+	timeSlot, err := scanTimeSlot(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("time slot not found")
+			return nil, fmt.Errorf("time slot not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("error querying time slot: %w", err)
 	}
 
-	// 解析星期几数据
-	// 这里需要实现从字符串转换为 []int 的逻辑
-	timeSlot.Days = parseDaysString(daysStr)
-
-	return &timeSlot, nil
+	return timeSlot, nil
 }
 
 // FindAll 查找所有时间段
-func (r *SQLTimeSlotRepository) FindAll() ([]*model.TimeSlot, error) {
+func (r *SQLTimeSlotRepository) FindAll(ctx context.Context) ([]*model.TimeSlot, error) {
 	query := `SELECT time_slot_id, day, start_hr, start_min, end_hr, end_min FROM time_slot`
 
-	rows, err := r.db.Query(query)
+	timeSlots, err := r.queryTimeSlots(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying time slots: %w", err)
 	}
-	defer rows.Close()
-
-	var timeSlots []*model.TimeSlot
-	for rows.Next() {
-		var timeSlot model.TimeSlot
-		var dayStr string
-		err := rows.Scan(
-			&timeSlot.ID,
-			&dayStr,
-			&timeSlot.StartHr,
-			&timeSlot.StartMin,
-			&timeSlot.EndHr,
-			&timeSlot.EndMin,
-		)
-		// 将dayStr转换为Days数组
-		timeSlot.Days = []int{}
-		if dayStr != "" {
-			dayInt, err := strconv.Atoi(dayStr)
-			if err == nil {
-				timeSlot.Days = append(timeSlot.Days, dayInt)
-			}
-		}
-		if err != nil {
-			return nil, fmt.Errorf("error scanning time slot: %w", err)
-		}
-		timeSlots = append(timeSlots, &timeSlot)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating time slots: %w", err)
-	}
 
 	return timeSlots, nil
 }
 
 // FindByDayOfWeek 根据星期几查找时间段
-func (r *SQLTimeSlotRepository) FindByDayOfWeek(dayOfWeek int) ([]*model.TimeSlot, error) {
-	query := `SELECT time_slot_id, day, start_hr, start_min, end_hr, end_min FROM time_slot WHERE ? IN (day)`
+func (r *SQLTimeSlotRepository) FindByDayOfWeek(ctx context.Context, dayOfWeek int) ([]*model.TimeSlot, error) {
+	query := `SELECT time_slot_id, day, start_hr, start_min, end_hr, end_min FROM time_slot WHERE day = ?`
 
-	rows, err := r.db.Query(query, dayOfWeek)
+	timeSlots, err := r.queryTimeSlots(ctx, query, strconv.Itoa(dayOfWeek))
 	if err != nil {
 		return nil, fmt.Errorf("error querying time slots by day of week: %w", err)
 	}
-	defer rows.Close()
 
-	var timeSlots []*model.TimeSlot
-	for rows.Next() {
-		var timeSlot model.TimeSlot
-		var dayStr string
-		err := rows.Scan(
-			&timeSlot.ID,
-			&dayStr,
-			&timeSlot.StartHr,
-			&timeSlot.StartMin,
-			&timeSlot.EndHr,
-			&timeSlot.EndMin,
-		)
-		// 将dayStr转换为Days数组
-		timeSlot.Days = []int{}
-		if dayStr != "" {
-			dayInt, err := strconv.Atoi(dayStr)
-			if err == nil {
-				timeSlot.Days = append(timeSlot.Days, dayInt)
-			}
-		}
-		if err != nil {
-			return nil, fmt.Errorf("error scanning time slot: %w", err)
-		}
-		timeSlots = append(timeSlots, &timeSlot)
+	return timeSlots, nil
+}
+
+// FindByTimeRange 根据时间范围查找时间段，startTime和endTime为 HH:MM 格式
+func (r *SQLTimeSlotRepository) FindByTimeRange(ctx context.Context, startTime, endTime string) ([]*model.TimeSlot, error) {
+	start, err := minuteOfDay(startTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time %q: %w", startTime, err)
+	}
+	end, err := minuteOfDay(endTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time %q: %w", endTime, err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating time slots: %w", err)
+	query := `SELECT time_slot_id, day, start_hr, start_min, end_hr, end_min FROM time_slot WHERE (start_hr * 60 + start_min) >= ? AND (end_hr * 60 + end_min) <= ?`
+
+	timeSlots, err := r.queryTimeSlots(ctx, query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error querying time slots by time range: %w", err)
 	}
 
 	return timeSlots, nil
 }
 
-// FindByTimeRange 根据时间范围查找时间段
-func (r *SQLTimeSlotRepository) FindByTimeRange(startTime, endTime string) ([]*model.TimeSlot, error) {
-	query := `SELECT time_slot_id, day, start_hr, start_min, end_hr, end_min FROM time_slot WHERE (start_hr * 60 + start_min) >= (CAST(? AS UNSIGNED) * 60 + CAST(? AS UNSIGNED)) AND (end_hr * 60 + end_min) <= (CAST(? AS UNSIGNED) * 60 + CAST(? AS UNSIGNED))`
-
-	rows, err := r.db.Query(query, startTime[:2], startTime[3:], endTime[:2], endTime[3:])
+// queryTimeSlots 执行查询并扫描所有时间段
+func (r *SQLTimeSlotRepository) queryTimeSlots(ctx context.Context, query string, args ...interface{}) ([]*model.TimeSlot, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error querying time slots by time range: %w", err)
+		return nil, err
 	}
 	defer rows.Close()
 
 	var timeSlots []*model.TimeSlot
 	for rows.Next() {
-		var timeSlot model.TimeSlot
-		var dayStr string
-		err := rows.Scan(
-			&timeSlot.ID,
-			&dayStr,
-			&timeSlot.StartHr,
-			&timeSlot.StartMin,
-			&timeSlot.EndHr,
-			&timeSlot.EndMin,
-		)
-		// 将dayStr转换为Days数组
-		timeSlot.Days = []int{}
-		if dayStr != "" {
-			dayInt, err := strconv.Atoi(dayStr)
-			if err == nil {
-				timeSlot.Days = append(timeSlot.Days, dayInt)
-			}
-		}
+		timeSlot, err := scanTimeSlot(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning time slot: %w", err)
 		}
-		timeSlots = append(timeSlots, &timeSlot)
+		timeSlots = append(timeSlots, timeSlot)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -187,8 +115,41 @@ func (r *SQLTimeSlotRepository) FindByTimeRange(startTime, endTime string) ([]*m
 	return timeSlots, nil
 }
 
+// scanTimeSlot 扫描一行时间段，day为数字时转换为Days
+func scanTimeSlot(row rowScanner) (*model.TimeSlot, error) {
+	var timeSlot model.TimeSlot
+	var dayStr string
+	err := row.Scan(
+		&timeSlot.ID,
+		&dayStr,
+		&timeSlot.StartHr,
+		&timeSlot.StartMin,
+		&timeSlot.EndHr,
+		&timeSlot.EndMin,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	timeSlot.Days = []int{}
+	if dayInt, err := strconv.Atoi(dayStr); err == nil {
+		timeSlot.Days = append(timeSlot.Days, dayInt)
+	}
+
+	return &timeSlot, nil
+}
+
+// minuteOfDay 把 HH:MM 转换为当天的分钟数
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
 // Create 创建时间段
-func (r *SQLTimeSlotRepository) Create(timeSlot *model.TimeSlot) error {
+func (r *SQLTimeSlotRepository) Create(ctx context.Context, timeSlot *model.TimeSlot) error {
 	query := `INSERT INTO time_slot (time_slot_id, day, start_hr, start_min, end_hr, end_min) VALUES (?, ?, ?, ?, ?, ?)`
 
 	// 使用Days数组的第一个元素作为day字段
@@ -197,9 +158,9 @@ func (r *SQLTimeSlotRepository) Create(timeSlot *model.TimeSlot) error {
 		day = timeSlot.Days[0]
 	}
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		timeSlot.ID,
-		day,
+		strconv.Itoa(day),
 		timeSlot.StartHr,
 		timeSlot.StartMin,
 		timeSlot.EndHr,
@@ -214,7 +175,7 @@ func (r *SQLTimeSlotRepository) Create(timeSlot *model.TimeSlot) error {
 }
 
 // Update 更新时间段
-func (r *SQLTimeSlotRepository) Update(timeSlot *model.TimeSlot) error {
+func (r *SQLTimeSlotRepository) Update(ctx context.Context, timeSlot *model.TimeSlot) error {
 	query := `UPDATE time_slot SET day = ?, start_hr = ?, start_min = ?, end_hr = ?, end_min = ? WHERE time_slot_id = ?`
 
 	// 使用Days数组的第一个元素作为day字段
@@ -223,8 +184,8 @@ func (r *SQLTimeSlotRepository) Update(timeSlot *model.TimeSlot) error {
 		day = timeSlot.Days[0]
 	}
 
-	result, err := r.db.Exec(query,
-		day,
+	result, err := r.db.ExecContext(ctx, query,
+		strconv.Itoa(day),
 		timeSlot.StartHr,
 		timeSlot.StartMin,
 		timeSlot.EndHr,
@@ -242,17 +203,17 @@ func (r *SQLTimeSlotRepository) Update(timeSlot *model.TimeSlot) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("time slot not found")
+		return fmt.Errorf("time slot not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
 
 // Delete 删除时间段
-func (r *SQLTimeSlotRepository) Delete(id string) error {
+func (r *SQLTimeSlotRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM time_slot WHERE time_slot_id = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("error deleting time slot: %w", err)
 	}
@@ -263,25 +224,8 @@ func (r *SQLTimeSlotRepository) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("time slot not found")
+		return fmt.Errorf("time slot not found: %w", ErrNotFound)
 	}
 
 	return nil
 }
-
-// parseDaysString 解析星期几字符串，转换为整数切片
-func parseDaysString(daysStr string) []int {
-	if daysStr == "" {
-		return nil
-	}
-
-	days := strings.Split(daysStr, ",")
-	var result []int
-	for _, day := range days {
-		day = strings.TrimSpace(day)
-		if dayInt, err := strconv.Atoi(day); err == nil {
-			result = append(result, dayInt)
-		}
-	}
-	return result
-}
\ No newline at end of file
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// TwoFactorRepository 定义两步验证仓库接口
+type TwoFactorRepository interface {
+	Find(ctx context.Context, userID string, role string) (*model.TwoFactor, error)
+	SavePending(ctx context.Context, tf *model.TwoFactor) error
+	Enable(ctx context.Context, userID string, role string, step int64, at time.Time) error
+	UseStep(ctx context.Context, userID string, role string, step int64) error
+	Delete(ctx context.Context, userID string, role string) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID string, role string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID string, role string, codeHash string, at time.Time) error
+	CountRecoveryCodes(ctx context.Context, userID string, role string) (int, error)
+
+	CreateChallenge(ctx context.Context, challenge *model.TwoFactorChallenge) error
+	FindChallenge(ctx context.Context, id string) (*model.TwoFactorChallenge, error)
+	IncrementChallengeAttempts(ctx context.Context, id string) error
+	MarkChallengeUsed(ctx context.Context, id string, at time.Time) error
+
+	FindPolicies(ctx context.Context) ([]*model.TwoFactorPolicy, error)
+	SavePolicy(ctx context.Context, policy *model.TwoFactorPolicy) error
+}
+
+// SQLTwoFactorRepository 实现TwoFactorRepository接口
+type SQLTwoFactorRepository struct {
+	db DBTX
+}
+
+// NewTwoFactorRepository 创建两步验证仓库实例
+func NewTwoFactorRepository(db DBTX) TwoFactorRepository {
+	return &SQLTwoFactorRepository{db: db}
+}
+
+// Find 查找账号的两步验证设置
+func (r *SQLTwoFactorRepository) Find(ctx context.Context, userID string, role string) (*model.TwoFactor, error) {
+	var tf model.TwoFactor
+	var enabledAt sql.NullTime
+	query := `SELECT user_id, role, secret, enabled, last_used_step, created_at, enabled_at FROM two_factor WHERE user_id = ? AND role = ?`
+
+	err := r.db.QueryRowContext(ctx, query, userID, role).Scan(
+		&tf.UserID,
+		&tf.Role,
+		&tf.Secret,
+		&tf.Enabled,
+		&tf.LastUsedStep,
+		&tf.CreatedAt,
+		&enabledAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying two factor: %w", err)
+	}
+
+	if enabledAt.Valid {
+		tf.EnabledAt = &enabledAt.Time
+	}
+
+	return &tf, nil
+}
+
+// SavePending 保存尚未确认的密钥，覆盖之前未完成的绑定
+func (r *SQLTwoFactorRepository) SavePending(ctx context.Context, tf *model.TwoFactor) error {
+	query := `
+		INSERT INTO two_factor (user_id, role, secret, enabled, last_used_step, created_at)
+		VALUES (?, ?, ?, FALSE, 0, ?)
+	` + r.db.Dialect().Upsert([]string{"user_id", "role"}, "secret = excluded.secret", "enabled = FALSE", "last_used_step = 0",
+		"created_at = excluded.created_at", "enabled_at = NULL")
+
+	if _, err := r.db.ExecContext(ctx, query, tf.UserID, tf.Role, tf.Secret, tf.CreatedAt); err != nil {
+		return fmt.Errorf("error saving two factor: %w", err)
+	}
+
+	return nil
+}
+
+// Enable 确认绑定，已启用时返回ErrNotFound
+func (r *SQLTwoFactorRepository) Enable(ctx context.Context, userID string, role string, step int64, at time.Time) error {
+	query := `UPDATE two_factor SET enabled = TRUE, enabled_at = ?, last_used_step = ? WHERE user_id = ? AND role = ? AND enabled = FALSE`
+
+	return r.execOne(ctx, query, "error enabling two factor", at, step, userID, role)
+}
+
+// UseStep 记录已使用的时间步，时间步不晚于上次使用的时间步时返回ErrNotFound
+func (r *SQLTwoFactorRepository) UseStep(ctx context.Context, userID string, role string, step int64) error {
+	query := `UPDATE two_factor SET last_used_step = ? WHERE user_id = ? AND role = ? AND enabled = TRUE AND last_used_step < ?`
+
+	return r.execOne(ctx, query, "error recording two factor step", step, userID, role, step)
+}
+
+// Delete 删除账号的两步验证设置
+func (r *SQLTwoFactorRepository) Delete(ctx context.Context, userID string, role string) error {
+	query := `DELETE FROM two_factor WHERE user_id = ? AND role = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, role); err != nil {
+		return fmt.Errorf("error deleting two factor: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes 替换账号的全部恢复码
+func (r *SQLTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, role string, codeHashes []string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM two_factor_recovery_code WHERE user_id = ? AND role = ?`, userID, role); err != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		query := `INSERT INTO two_factor_recovery_code (user_id, role, code_hash) VALUES (?, ?, ?)`
+		if _, err := r.db.ExecContext(ctx, query, userID, role, codeHash); err != nil {
+			return fmt.Errorf("error inserting recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UseRecoveryCode 使用一个恢复码，恢复码不存在或已使用时返回ErrNotFound
+func (r *SQLTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID string, role string, codeHash string, at time.Time) error {
+	query := `UPDATE two_factor_recovery_code SET used_at = ? WHERE user_id = ? AND role = ? AND code_hash = ? AND used_at IS NULL`
+
+	return r.execOne(ctx, query, "error using recovery code", at, userID, role, codeHash)
+}
+
+// CountRecoveryCodes 统计未使用的恢复码数量
+func (r *SQLTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID string, role string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM two_factor_recovery_code WHERE user_id = ? AND role = ? AND used_at IS NULL`
+
+	if err := r.db.QueryRowContext(ctx, query, userID, role).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateChallenge 保存登录挑战
+func (r *SQLTwoFactorRepository) CreateChallenge(ctx context.Context, challenge *model.TwoFactorChallenge) error {
+	query := `
+		INSERT INTO two_factor_challenge (id, token_hash, user_id, role, username, attempts, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, challenge.ID, challenge.TokenHash, challenge.UserID, challenge.Role,
+		challenge.Username, challenge.CreatedAt, challenge.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creating two factor challenge: %w", err)
+	}
+
+	return nil
+}
+
+// FindChallenge 根据ID查找登录挑战
+func (r *SQLTwoFactorRepository) FindChallenge(ctx context.Context, id string) (*model.TwoFactorChallenge, error) {
+	var challenge model.TwoFactorChallenge
+	var usedAt sql.NullTime
+	query := `SELECT id, token_hash, user_id, role, username, attempts, created_at, expires_at, used_at FROM two_factor_challenge WHERE id = ?`
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&challenge.ID,
+		&challenge.TokenHash,
+		&challenge.UserID,
+		&challenge.Role,
+		&challenge.Username,
+		&challenge.Attempts,
+		&challenge.CreatedAt,
+		&challenge.ExpiresAt,
+		&usedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error querying two factor challenge: %w", err)
+	}
+
+	if usedAt.Valid {
+		challenge.UsedAt = &usedAt.Time
+	}
+
+	return &challenge, nil
+}
+
+// IncrementChallengeAttempts 记录一次错误的验证码
+func (r *SQLTwoFactorRepository) IncrementChallengeAttempts(ctx context.Context, id string) error {
+	query := `UPDATE two_factor_challenge SET attempts = attempts + 1 WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error updating two factor challenge: %w", err)
+	}
+
+	return nil
+}
+
+// MarkChallengeUsed 将登录挑战标记为已使用，已使用时返回ErrNotFound
+func (r *SQLTwoFactorRepository) MarkChallengeUsed(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE two_factor_challenge SET used_at = ? WHERE id = ? AND used_at IS NULL`
+
+	return r.execOne(ctx, query, "error marking two factor challenge used", at, id)
+}
+
+// FindPolicies 查找所有角色的两步验证要求
+func (r *SQLTwoFactorRepository) FindPolicies(ctx context.Context) ([]*model.TwoFactorPolicy, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT role, required FROM two_factor_policy ORDER BY role`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying two factor policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*model.TwoFactorPolicy
+	for rows.Next() {
+		var policy model.TwoFactorPolicy
+		if err := rows.Scan(&policy.Role, &policy.Required); err != nil {
+			return nil, fmt.Errorf("error scanning two factor policy: %w", err)
+		}
+		policies = append(policies, &policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating two factor policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// SavePolicy 保存角色的两步验证要求
+func (r *SQLTwoFactorRepository) SavePolicy(ctx context.Context, policy *model.TwoFactorPolicy) error {
+	query := `
+		INSERT INTO two_factor_policy (role, required)
+		VALUES (?, ?)
+	` + r.db.Dialect().Upsert([]string{"role"}, "required = excluded.required")
+
+	if _, err := r.db.ExecContext(ctx, query, policy.Role, policy.Required); err != nil {
+		return fmt.Errorf("error saving two factor policy: %w", err)
+	}
+
+	return nil
+}
+
+// execOne 执行只应影响一行的更新，没有影响任何行时返回ErrNotFound
+func (r *SQLTwoFactorRepository) execOne(ctx context.Context, query string, errMsg string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMsg, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
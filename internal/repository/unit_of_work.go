@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/pkg/dialect"
+)
+
+// DBTX 抽象 DB 和 Tx 共有的查询方法，使同一个仓库实现既可以直接访问数据库，也可以在事务中使用
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Dialect() dialect.Dialect
+}
+
+// Repositories 一个工作单元内可用的仓库集合，所有仓库共享同一个事务
+type Repositories struct {
+	Students  StudentRepository
+	Sections  SectionRepository
+	Courses   CourseRepository
+	Takes     TakesRepository
+	Prereqs   PrereqRepository
+	Waitlist  WaitlistRepository
+	Terms     TermRepository
+	Tickets   TimeTicketRepository
+	Overloads OverloadRepository
+	Carts     CartRepository
+	Overrides OverrideRepository
+	Roles     RoleRepository
+	TwoFactor TwoFactorRepository
+	APIKeys   APIKeyRepository
+	Audit     AuditRepository
+}
+
+// UnitOfWork 定义工作单元接口
+// Do 在一个事务中执行fn，fn返回nil时提交，返回错误或发生panic时回滚，ctx取消时事务同样回滚
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
+// SQLUnitOfWork 基于数据库事务实现UnitOfWork接口
+type SQLUnitOfWork struct {
+	db *DB
+}
+
+// NewUnitOfWork 创建工作单元实例
+func NewUnitOfWork(db *DB) UnitOfWork {
+	return &SQLUnitOfWork{db: db}
+}
+
+// Do 开启事务并执行fn
+func (u *SQLUnitOfWork) Do(ctx context.Context, fn func(repos *Repositories) error) (err error) {
+	tx, err := u.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	repos := &Repositories{
+		Students:  NewStudentRepository(tx),
+		Sections:  NewSectionRepository(tx),
+		Courses:   NewCourseRepository(tx),
+		Takes:     NewTakesRepository(tx),
+		Prereqs:   NewPrereqRepository(tx),
+		Waitlist:  NewWaitlistRepository(tx),
+		Terms:     NewTermRepository(tx),
+		Tickets:   NewTimeTicketRepository(tx),
+		Overloads: NewOverloadRepository(tx),
+		Carts:     NewCartRepository(tx),
+		Overrides: NewOverrideRepository(tx),
+		Roles:     NewRoleRepository(tx),
+		TwoFactor: NewTwoFactorRepository(tx),
+		APIKeys:   NewAPIKeyRepository(tx),
+		Audit:     NewAuditRepository(tx),
+	}
+
+	if err := fn(repos); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("error rolling back transaction: %v (original error: %w)", rbErr, err)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// WaitlistRepository 定义候补队列仓库接口
+type WaitlistRepository interface {
+	FindByID(ctx context.Context, id string) (*model.WaitlistEntry, error)
+	FindByStudentID(ctx context.Context, studentID string) ([]*model.WaitlistEntry, error)
+	FindBySection(ctx context.Context, key model.SectionKey) ([]*model.WaitlistEntry, error)
+	FindWaitingByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.WaitlistEntry, error)
+	Create(ctx context.Context, entry *model.WaitlistEntry) error
+	UpdateStatus(ctx context.Context, id string, status model.EnrollmentStatus) error
+	GetPosition(ctx context.Context, id string) (int, error)
+}
+
+// SQLWaitlistRepository 实现WaitlistRepository接口
+type SQLWaitlistRepository struct {
+	db DBTX
+}
+
+// NewWaitlistRepository 创建候补队列仓库实例
+func NewWaitlistRepository(db DBTX) WaitlistRepository {
+	return &SQLWaitlistRepository{db: db}
+}
+
+// waitlistColumns 查询候补记录时使用的公共列，position 只对等待中的记录计算
+const waitlistColumns = `
+		w.id, w.student_id, w.course_id, w.sec_id, w.semester, w.year, w.queue_no, w.status, w.created_at, w.updated_at,
+		CASE WHEN w.status = 'waiting' THEN (
+			SELECT COUNT(*) FROM waitlist w2
+			WHERE w2.course_id = w.course_id AND w2.sec_id = w.sec_id AND w2.semester = w.semester AND w2.year = w.year
+			  AND w2.status = 'waiting' AND w2.queue_no < w.queue_no
+		) + 1 ELSE 0 END AS position`
+
+// scanWaitlistEntry 扫描一行候补记录
+func scanWaitlistEntry(scanner rowScanner, entry *model.WaitlistEntry) error {
+	var status string
+	err := scanner.Scan(
+		&entry.ID,
+		&entry.StudentID,
+		&entry.CourseID,
+		&entry.SectionID,
+		&entry.Semester,
+		&entry.Year,
+		&entry.QueueNo,
+		&status,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+		&entry.Position,
+	)
+	entry.Status = model.EnrollmentStatus(status)
+	return err
+}
+
+// FindByID 根据ID查找候补记录
+func (r *SQLWaitlistRepository) FindByID(ctx context.Context, id string) (*model.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist w WHERE w.id = ?`
+
+	var entry model.WaitlistEntry
+	if err := scanWaitlistEntry(r.db.QueryRowContext(ctx, query, id), &entry); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("waitlist entry not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("error querying waitlist entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// FindByStudentID 查找学生所有等待中的候补记录
+func (r *SQLWaitlistRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `, c.title, c.dept_name, c.credits
+		FROM waitlist w
+		JOIN course c ON w.course_id = c.course_id
+		WHERE w.student_id = ? AND w.status = 'waiting'
+		ORDER BY w.year DESC, w.semester, w.course_id, w.sec_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.WaitlistEntry
+	for rows.Next() {
+		var entry model.WaitlistEntry
+		var course model.Course
+		var status string
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.StudentID,
+			&entry.CourseID,
+			&entry.SectionID,
+			&entry.Semester,
+			&entry.Year,
+			&entry.QueueNo,
+			&status,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.Position,
+			&course.Title,
+			&course.Dept,
+			&course.Credits,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning waitlist entry: %w", err)
+		}
+
+		entry.Status = model.EnrollmentStatus(status)
+		course.ID = entry.CourseID
+		entry.Course = &course
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating waitlist: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FindBySection 按排队顺序查找课程段所有等待中的候补记录
+func (r *SQLWaitlistRepository) FindBySection(ctx context.Context, key model.SectionKey) ([]*model.WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `, s.name, s.dept_name, s.tot_cred
+		FROM waitlist w
+		JOIN student s ON w.student_id = s.id
+		WHERE w.course_id = ? AND w.sec_id = ? AND w.semester = ? AND w.year = ? AND w.status = 'waiting'
+		ORDER BY w.queue_no, w.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, key.CourseID, key.SecID, key.Semester, key.Year)
+	if err != nil {
+		return nil, fmt.Errorf("error querying waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.WaitlistEntry
+	for rows.Next() {
+		var entry model.WaitlistEntry
+		var student model.Student
+		var status string
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.StudentID,
+			&entry.CourseID,
+			&entry.SectionID,
+			&entry.Semester,
+			&entry.Year,
+			&entry.QueueNo,
+			&status,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.Position,
+			&student.Name,
+			&student.Dept,
+			&student.TotCred,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning waitlist entry: %w", err)
+		}
+
+		entry.Status = model.EnrollmentStatus(status)
+		student.ID = entry.StudentID
+		entry.Student = &student
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating waitlist: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FindWaitingByStudentAndSection 查找学生在指定课程段中等待中的候补记录
+func (r *SQLWaitlistRepository) FindWaitingByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist w WHERE w.student_id = ? AND w.course_id = ? AND w.sec_id = ? AND w.semester = ? AND w.year = ? AND w.status = 'waiting'`
+
+	var entry model.WaitlistEntry
+	if err := scanWaitlistEntry(r.db.QueryRowContext(ctx, query, studentID, key.CourseID, key.SecID, key.Semester, key.Year), &entry); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("waitlist entry not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("error querying waitlist entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// Create 将学生加入候补队列，队列序号取该课程段当前最大序号加一
+// 分配序号前锁定课程段行，必须在事务中调用，同一课程段的并发加入在这里排队；
+// (课程段, queue_no) 上的唯一约束保证即使没有加锁也不会分配出重复的序号
+func (r *SQLWaitlistRepository) Create(ctx context.Context, entry *model.WaitlistEntry) error {
+	lockQuery := `SELECT 1 FROM section WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ?` + r.db.Dialect().ForUpdate()
+	var locked int
+	err := r.db.QueryRowContext(ctx, lockQuery, entry.CourseID, entry.SectionID, entry.Semester, entry.Year).Scan(&locked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("section not found: %w", ErrNotFound)
+		}
+		return fmt.Errorf("error locking section: %w", err)
+	}
+
+	var maxQueueNo sql.NullInt64
+	maxQuery := `SELECT MAX(queue_no) FROM waitlist WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ?`
+	err = r.db.QueryRowContext(ctx, maxQuery, entry.CourseID, entry.SectionID, entry.Semester, entry.Year).Scan(&maxQueueNo)
+	if err != nil {
+		return fmt.Errorf("error getting waitlist queue number: %w", err)
+	}
+	entry.QueueNo = int(maxQueueNo.Int64) + 1
+
+	query := `INSERT INTO waitlist (id, student_id, course_id, sec_id, semester, year, queue_no, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		entry.ID,
+		entry.StudentID,
+		entry.CourseID,
+		entry.SectionID,
+		entry.Semester,
+		entry.Year,
+		entry.QueueNo,
+		string(entry.Status),
+		entry.CreatedAt,
+		entry.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, ErrDuplicate) {
+			return fmt.Errorf("waitlist queue number already taken: %w", err)
+		}
+		return fmt.Errorf("error creating waitlist entry: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus 更新候补记录状态
+func (r *SQLWaitlistRepository) UpdateStatus(ctx context.Context, id string, status model.EnrollmentStatus) error {
+	query := `UPDATE waitlist SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, string(status), id)
+	if err != nil {
+		return fmt.Errorf("error updating waitlist entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("waitlist entry not found: %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// GetPosition 获取候补记录当前的排队位置
+func (r *SQLWaitlistRepository) GetPosition(ctx context.Context, id string) (int, error) {
+	entry, err := r.FindByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return entry.Position, nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+var (
+	// ErrAdminNotFound 管理员账号不存在
+	ErrAdminNotFound = errors.New("admin not found")
+
+	// ErrAdminExists 管理员账号已存在
+	ErrAdminExists = errors.New("admin already exists")
+
+	// ErrAdminDisabled 管理员账号已停用
+	ErrAdminDisabled = errors.New("admin account is disabled")
+
+	// ErrLastActiveAdmin 不能停用最后一个可用的管理员
+	ErrLastActiveAdmin = errors.New("cannot disable the last active admin")
+
+	// ErrAlreadyBootstrapped 已经存在管理员，不能再次初始化
+	ErrAlreadyBootstrapped = errors.New("admin accounts already exist")
+)
+
+// AdminAccountService 定义管理员账号服务接口
+type AdminAccountService interface {
+	Authenticate(ctx context.Context, id string, password string) (string, error)
+	ListAdmins(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Admin], error)
+	CreateAdmin(ctx context.Context, actor model.Actor, req *model.AdminCreateRequest) (*model.Admin, error)
+	DisableAdmin(ctx context.Context, actor model.Actor, id string) error
+	EnableAdmin(ctx context.Context, actor model.Actor, id string) error
+	RotatePassword(ctx context.Context, actor model.Actor, id string, newPassword string) error
+	Bootstrap(ctx context.Context, req *model.AdminCreateRequest) (*model.Admin, error)
+}
+
+// DefaultAdminAccountService 实现AdminAccountService接口
+type DefaultAdminAccountService struct {
+	adminRepo repository.AdminRepository
+	sessions  SessionRevoker
+	policy    utils.PasswordPolicy
+	audit     AuditRecorder
+	now       func() time.Time // 当前时间，测试中可替换
+}
+
+// NewAdminAccountService 创建管理员账号服务实例
+func NewAdminAccountService(adminRepo repository.AdminRepository, sessions SessionRevoker, policy utils.PasswordPolicy, audit AuditRecorder) AdminAccountService {
+	return &DefaultAdminAccountService{
+		adminRepo: adminRepo,
+		sessions:  sessions,
+		policy:    policy,
+		audit:     auditRecorderOrNop(audit),
+		now:       time.Now,
+	}
+}
+
+// Authenticate 验证管理员身份，已停用的账号不能登录
+func (s *DefaultAdminAccountService) Authenticate(ctx context.Context, id string, password string) (string, error) {
+	admin, err := s.adminRepo.FindByID(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("admin not found: %w", err)
+	}
+
+	if admin.Status != model.AdminStatusActive {
+		return "", ErrAdminDisabled
+	}
+
+	if !utils.CheckPassword(password, admin.Password) {
+		return "", errors.New("invalid password")
+	}
+
+	// 登录时间只用于展示，记录失败不影响登录
+	_ = s.adminRepo.UpdateLastLogin(ctx, admin.ID, s.now())
+
+	return admin.ID, nil
+}
+
+// ListAdmins 分页查询管理员账号
+func (s *DefaultAdminAccountService) ListAdmins(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Admin], error) {
+	return s.adminRepo.List(ctx, q)
+}
+
+// CreateAdmin 创建管理员账号，新管理员首次登录后必须修改密码
+func (s *DefaultAdminAccountService) CreateAdmin(ctx context.Context, actor model.Actor, req *model.AdminCreateRequest) (*model.Admin, error) {
+	return s.createAdmin(ctx, actor, req, true)
+}
+
+// createAdmin 创建管理员账号
+func (s *DefaultAdminAccountService) createAdmin(ctx context.Context, actor model.Actor, req *model.AdminCreateRequest, mustChangePassword bool) (*model.Admin, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	now := s.now()
+	admin := &model.Admin{
+		ID:                 req.ID,
+		Name:               req.Name,
+		Password:           hashedPassword,
+		Status:             model.AdminStatusActive,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+		MustChangePassword: mustChangePassword,
+	}
+
+	if err := s.adminRepo.Create(ctx, admin); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrAdminExists
+		}
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityAdmin, admin.ID, nil, admin); err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// DisableAdmin 停用管理员账号，不能停用自己或最后一个可用的管理员
+func (s *DefaultAdminAccountService) DisableAdmin(ctx context.Context, actor model.Actor, id string) error {
+	if actor.UserID == id {
+		return errors.New("cannot disable your own account")
+	}
+
+	admin, err := s.findAdmin(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if admin.Status == model.AdminStatusDisabled {
+		return nil
+	}
+
+	active, err := s.adminRepo.CountActive(ctx)
+	if err != nil {
+		return err
+	}
+	if active <= 1 {
+		return ErrLastActiveAdmin
+	}
+
+	now := s.now()
+	if err := s.adminRepo.UpdateStatus(ctx, id, model.AdminStatusDisabled, now); err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeUserSessions(ctx, id, model.RoleAdmin); err != nil {
+		return err
+	}
+	return s.recordStatus(ctx, actor, admin, model.AdminStatusDisabled, now)
+}
+
+// EnableAdmin 重新启用管理员账号
+func (s *DefaultAdminAccountService) EnableAdmin(ctx context.Context, actor model.Actor, id string) error {
+	admin, err := s.findAdmin(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if err := s.adminRepo.UpdateStatus(ctx, id, model.AdminStatusActive, now); err != nil {
+		return err
+	}
+	return s.recordStatus(ctx, actor, admin, model.AdminStatusActive, now)
+}
+
+// recordStatus 记录管理员账号状态变更的审计日志
+func (s *DefaultAdminAccountService) recordStatus(ctx context.Context, actor model.Actor, admin *model.Admin, status model.AdminStatus, now time.Time) error {
+	after := *admin
+	after.Status = status
+	after.UpdatedAt = now
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityAdmin, admin.ID, admin, &after)
+}
+
+// RotatePassword 为管理员设置临时新密码，该管理员下次登录后必须修改密码
+// 审计日志只记录重置了哪个账号的密码，不记录密码本身
+func (s *DefaultAdminAccountService) RotatePassword(ctx context.Context, actor model.Actor, id string, newPassword string) error {
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	if _, err := s.findAdmin(ctx, id); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	if err := s.adminRepo.UpdatePassword(ctx, id, hashedPassword, true, s.now()); err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeUserSessions(ctx, id, model.RoleAdmin); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityPassword, model.AuditKey(model.RoleAdmin, id), nil, nil)
+}
+
+// Bootstrap 创建第一个管理员账号，已有管理员时返回ErrAlreadyBootstrapped
+// 初始密码由运维人员自己设置，不要求首次登录修改
+func (s *DefaultAdminAccountService) Bootstrap(ctx context.Context, req *model.AdminCreateRequest) (*model.Admin, error) {
+	admins, err := s.adminRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(admins) > 0 {
+		return nil, ErrAlreadyBootstrapped
+	}
+
+	return s.createAdmin(ctx, model.SystemActor, req, false)
+}
+
+// findAdmin 查找管理员，不存在时返回ErrAdminNotFound
+func (s *DefaultAdminAccountService) findAdmin(ctx context.Context, id string) (*model.Admin, error) {
+	admin, err := s.adminRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, err
+	}
+	return admin, nil
+}
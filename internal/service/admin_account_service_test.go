@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+// fakeAdminRepository 基于内存的管理员仓库
+type fakeAdminRepository struct {
+	admins map[string]*model.Admin
+}
+
+func newFakeAdminRepository() *fakeAdminRepository {
+	return &fakeAdminRepository{admins: make(map[string]*model.Admin)}
+}
+
+func (r *fakeAdminRepository) FindByID(ctx context.Context, id string) (*model.Admin, error) {
+	admin, ok := r.admins[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return admin, nil
+}
+
+func (r *fakeAdminRepository) FindAll(ctx context.Context) ([]*model.Admin, error) {
+	var admins []*model.Admin
+	for _, admin := range r.admins {
+		admins = append(admins, admin)
+	}
+	return admins, nil
+}
+
+func (r *fakeAdminRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Admin], error) {
+	if err := q.Normalize(model.AdminListFields); err != nil {
+		return nil, err
+	}
+	admins, _ := r.FindAll(ctx)
+	return model.NewListResult(q, admins, int64(len(admins))), nil
+}
+
+func (r *fakeAdminRepository) CountActive(ctx context.Context) (int, error) {
+	count := 0
+	for _, admin := range r.admins {
+		if admin.Status == model.AdminStatusActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeAdminRepository) Create(ctx context.Context, admin *model.Admin) error {
+	if _, ok := r.admins[admin.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	r.admins[admin.ID] = admin
+	return nil
+}
+
+func (r *fakeAdminRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string, mustChange bool, updatedAt time.Time) error {
+	admin, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	admin.Password = hashedPassword
+	admin.MustChangePassword = mustChange
+	admin.UpdatedAt = updatedAt
+	return nil
+}
+
+func (r *fakeAdminRepository) UpdateStatus(ctx context.Context, id string, status model.AdminStatus, updatedAt time.Time) error {
+	admin, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	admin.Status = status
+	admin.UpdatedAt = updatedAt
+	return nil
+}
+
+func (r *fakeAdminRepository) UpdateLastLogin(ctx context.Context, id string, loginAt time.Time) error {
+	admin, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	admin.LastLoginAt = &loginAt
+	return nil
+}
+
+func TestAdminAccountService(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeAdminRepository()
+	revoker := &fakeSessionRevoker{}
+	svc := NewAdminAccountService(repo, revoker, utils.NewPasswordPolicy(config.PasswordConfig{}), nil)
+
+	// 初始化第一个管理员，之后不能再次初始化
+	if _, err := svc.Bootstrap(ctx, &model.AdminCreateRequest{ID: "root", Name: "Root", Password: "short"}); err == nil {
+		t.Fatal("Expected error for short password")
+	}
+	if _, err := svc.Bootstrap(ctx, &model.AdminCreateRequest{ID: "root", Name: "Root", Password: "rootpass1"}); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	if _, err := svc.Bootstrap(ctx, &model.AdminCreateRequest{ID: "other", Name: "Other", Password: "otherpass1"}); !errors.Is(err, ErrAlreadyBootstrapped) {
+		t.Fatalf("Expected ErrAlreadyBootstrapped, got %v", err)
+	}
+	if repo.admins["root"].Password == "rootpass1" {
+		t.Fatal("Expected password to be stored hashed")
+	}
+	if repo.admins["root"].MustChangePassword {
+		t.Error("Expected bootstrap admin not to be forced to change password")
+	}
+
+	if id, err := svc.Authenticate(ctx, "root", "rootpass1"); err != nil || id != "root" {
+		t.Fatalf("Authenticate() = %q, %v", id, err)
+	}
+	if repo.admins["root"].LastLoginAt == nil {
+		t.Error("Expected last login time to be recorded")
+	}
+	if _, err := svc.Authenticate(ctx, "root", "wrongpass"); err == nil {
+		t.Error("Expected error for wrong password")
+	}
+
+	// 不能停用自己，也不能停用最后一个可用的管理员
+	if err := svc.DisableAdmin(ctx, model.Actor{UserID: "root", Role: model.RoleAdmin}, "root"); err == nil {
+		t.Error("Expected error when disabling own account")
+	}
+	if err := svc.DisableAdmin(ctx, model.Actor{UserID: "someone", Role: model.RoleAdmin}, "root"); !errors.Is(err, ErrLastActiveAdmin) {
+		t.Errorf("Expected ErrLastActiveAdmin, got %v", err)
+	}
+
+	if _, err := svc.CreateAdmin(ctx, model.Actor{UserID: "root", Role: model.RoleAdmin}, &model.AdminCreateRequest{ID: "ops", Name: "Ops", Password: "opspass12"}); err != nil {
+		t.Fatalf("CreateAdmin() error = %v", err)
+	}
+	if !repo.admins["ops"].MustChangePassword {
+		t.Error("Expected admin created by another admin to be forced to change password")
+	}
+	if _, err := svc.CreateAdmin(ctx, model.Actor{UserID: "root", Role: model.RoleAdmin}, &model.AdminCreateRequest{ID: "ops", Name: "Ops", Password: "opspass12"}); !errors.Is(err, ErrAdminExists) {
+		t.Errorf("Expected ErrAdminExists, got %v", err)
+	}
+
+	if err := svc.DisableAdmin(ctx, model.Actor{UserID: "root", Role: model.RoleAdmin}, "ops"); err != nil {
+		t.Fatalf("DisableAdmin() error = %v", err)
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != "admin/ops" {
+		t.Errorf("Expected sessions of ops to be revoked, got %v", revoker.revoked)
+	}
+	if _, err := svc.Authenticate(ctx, "ops", "opspass12"); !errors.Is(err, ErrAdminDisabled) {
+		t.Errorf("Expected ErrAdminDisabled, got %v", err)
+	}
+
+	// 轮换密码后旧密码失效
+	if err := svc.EnableAdmin(ctx, model.Actor{UserID: "root", Role: model.RoleAdmin}, "ops"); err != nil {
+		t.Fatalf("EnableAdmin() error = %v", err)
+	}
+	if err := svc.RotatePassword(ctx, model.Actor{UserID: "root", Role: model.RoleAdmin}, "ops", "newopspass"); err != nil {
+		t.Fatalf("RotatePassword() error = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, "ops", "opspass12"); err == nil {
+		t.Error("Expected old password to be rejected after rotation")
+	}
+	if _, err := svc.Authenticate(ctx, "ops", "newopspass"); err != nil {
+		t.Errorf("Authenticate() with new password error = %v", err)
+	}
+	if err := svc.RotatePassword(ctx, model.Actor{UserID: "root", Role: model.RoleAdmin}, "missing", "newopspass"); !errors.Is(err, ErrAdminNotFound) {
+		t.Errorf("Expected ErrAdminNotFound, got %v", err)
+	}
+}
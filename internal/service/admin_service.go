@@ -1,70 +1,88 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/utils"
 )
 
 // AdminService 定义管理员服务接口
 type AdminService interface {
 	// 学生管理
-	GetAllStudents() ([]*model.Student, error)
-	CreateStudent(id string, name string, dept string) error
-	UpdateStudent(id string, name string, dept string) error
-	DeleteStudent(id string) error
+	ListStudents(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Student], error)
+	CreateStudent(ctx context.Context, actor model.Actor, id string, name string, dept string) (string, error)
+	UpdateStudent(ctx context.Context, actor model.Actor, id string, name string, dept string) error
+	DeleteStudent(ctx context.Context, actor model.Actor, id string) error
 
 	// 教师管理
-	GetAllInstructors() ([]*model.Instructor, error)
-	CreateInstructor(id string, name string, dept string, salary float64) error
-	UpdateInstructor(id string, name string, dept string, salary float64) error
-	DeleteInstructor(id string) error
+	ListInstructors(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Instructor], error)
+	CreateInstructor(ctx context.Context, actor model.Actor, id string, name string, dept string, salary float64) (string, error)
+	UpdateInstructor(ctx context.Context, actor model.Actor, id string, name string, dept string, salary float64, salaryScope *model.PermissionScope) error
+	DeleteInstructor(ctx context.Context, actor model.Actor, id string) error
 
 	// 课程管理
-	GetAllCourses() ([]*model.Course, error)
-	CreateCourse(id string, title string, dept string, credits int) error
-	UpdateCourse(id string, title string, dept string, credits int) error
-	DeleteCourse(id string) error
+	ListCourses(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Course], error)
+	CreateCourse(ctx context.Context, actor model.Actor, id string, title string, dept string, credits int) error
+	UpdateCourse(ctx context.Context, actor model.Actor, id string, title string, dept string, credits int) error
+	DeleteCourse(ctx context.Context, actor model.Actor, id string) error
 
 	// 章节管理
-	GetAllSections() ([]*model.Section, error)
-	CreateSection(req *model.SectionCreateRequest) error
-	UpdateSection(id string, req *model.SectionUpdateRequest) error
-	DeleteSection(id string, secID string, semester string, year int) error
+	ListSections(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Section], error)
+	CreateSection(ctx context.Context, actor model.Actor, req *model.SectionCreateRequest) error
+	UpdateSection(ctx context.Context, actor model.Actor, key model.SectionKey, req *model.SectionUpdateRequest) error
+	DeleteSection(ctx context.Context, actor model.Actor, key model.SectionKey) error
 
 	// 系部管理
-	GetAllDepartments() ([]*model.Department, error)
-	CreateDepartment(deptName string, building string, budget float64) error
-	UpdateDepartment(deptName string, building string, budget float64) error
-	DeleteDepartment(deptName string) error
+	ListDepartments(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Department], error)
+	CreateDepartment(ctx context.Context, actor model.Actor, deptName string, building string, budget float64) error
+	UpdateDepartment(ctx context.Context, actor model.Actor, deptName string, building string, budget float64) error
+	DeleteDepartment(ctx context.Context, actor model.Actor, deptName string) error
 
 	// 教室管理
-	GetAllClassrooms() ([]*model.Classroom, error)
-	CreateClassroom(building string, roomNumber string, capacity int) error
-	UpdateClassroom(building string, roomNumber string, capacity int) error
-	DeleteClassroom(building string, roomNumber string) error
+	ListClassrooms(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Classroom], error)
+	CreateClassroom(ctx context.Context, actor model.Actor, building string, roomNumber string, capacity int) error
+	UpdateClassroom(ctx context.Context, actor model.Actor, building string, roomNumber string, capacity int) error
+	DeleteClassroom(ctx context.Context, actor model.Actor, building string, roomNumber string) error
 
 	// 先修课程管理
-	GetAllPrereqs() ([]*model.Prereq, error)
-	CreatePrereq(courseID string, prereqID string) error
-	DeletePrereq(courseID string, prereqID string) error
+	ListPrereqs(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Prereq], error)
+	CreatePrereq(ctx context.Context, actor model.Actor, courseID string, prereqID string) error
+	DeletePrereq(ctx context.Context, actor model.Actor, courseID string, prereqID string) error
+	GetPrereqRule(ctx context.Context, courseID string) (*model.PrereqRule, error)
+	SavePrereqRule(ctx context.Context, actor model.Actor, req *model.PrereqRuleRequest) error
+	DeletePrereqRule(ctx context.Context, actor model.Actor, courseID string) error
 
 	// 教学安排管理
-	GetAllTeaches() ([]*model.Teaches, error)
-	CreateTeaches(instructorID string, courseID string, sectionID string, semester string, year int) error
-	DeleteTeaches(instructorID string, courseID string, sectionID string, semester string, year int) error
+	ListTeaches(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Teaches], error)
+	CreateTeaches(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error
+	DeleteTeaches(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error
 
 	// 导师关系管理
-	GetAllAdvisors() ([]*model.Advisor, error)
-	CreateAdvisor(studentID string, instructorID string) error
-	DeleteAdvisor(studentID string, instructorID string) error
+	ListAdvisors(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Advisor], error)
+	CreateAdvisor(ctx context.Context, actor model.Actor, studentID string, instructorID string) error
+	DeleteAdvisor(ctx context.Context, actor model.Actor, studentID string, instructorID string) error
+
+	// 校历管理
+	ListTerms(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Term], error)
+	CreateTerm(ctx context.Context, actor model.Actor, term *model.Term) error
+	UpdateTerm(ctx context.Context, actor model.Actor, term *model.Term) error
+	DeleteTerm(ctx context.Context, actor model.Actor, semester string, year int) error
+
+	// 选课时间票管理
+	ListTimeTickets(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.TimeTicket], error)
+	PreviewTimeTickets(ctx context.Context, req *model.TimeTicketRequest) ([]*model.TimeTicket, error)
+	PublishTimeTickets(ctx context.Context, actor model.Actor, req *model.TimeTicketRequest) ([]*model.TimeTicket, error)
 
 	// 统计信息
-	GetStats() (*model.AdminStats, error)
-	GetSystemStats() (*model.SystemStats, error)
-	GetPrereqs(id string) ([]*model.Prereq, error)
+	GetStats(ctx context.Context) (*model.AdminStats, error)
+	GetSystemStats(ctx context.Context) (*model.SystemStats, error)
 }
 
 // DefaultAdminService 实现AdminService接口
@@ -79,14 +97,26 @@ type DefaultAdminService struct {
 	teachesRepo    repository.TeachesRepository
 	advisorRepo    repository.AdvisorRepository
 	prereqRepo     repository.PrereqRepository
+	termRepo       repository.TermRepository
+	ticketRepo     repository.TimeTicketRepository
+	uow            repository.UnitOfWork
+	sessions       SessionRevoker
+	audit          AuditRecorder
 }
 
-func (s *DefaultAdminService) DeleteSection(id string, secID string, semester string, year int) error {
-	//TODO implement me
-	panic("implement me")
+// DeleteSection 删除章节
+func (s *DefaultAdminService) DeleteSection(ctx context.Context, actor model.Actor, key model.SectionKey) error {
+	section, err := s.sectionRepo.FindByID(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := s.sectionRepo.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntitySection, key.String(), section, nil)
 }
 
-func (s *DefaultAdminService) GetSystemStats() (*model.SystemStats, error) {
+func (s *DefaultAdminService) GetSystemStats(ctx context.Context) (*model.SystemStats, error) {
 	// TODO: 实现系统统计信息的收集
 	stats := &model.SystemStats{
 		ActiveUsers:     0, // 需要实现实际的统计逻辑
@@ -100,12 +130,8 @@ func (s *DefaultAdminService) GetSystemStats() (*model.SystemStats, error) {
 	return stats, nil
 }
 
-func (s *DefaultAdminService) GetPrereqs(id string) ([]*model.Prereq, error) {
-	return s.prereqRepo.FindByCourseID(id)
-}
-
 // NewAdminService 创建新的AdminService实例
-func NewAdminService(studentRepo repository.StudentRepository, instructorRepo repository.InstructorRepository, courseRepo repository.CourseRepository, sectionRepo repository.SectionRepository, departmentRepo repository.DepartmentRepository, classroomRepo repository.ClassroomRepository, timeSlotRepo repository.TimeSlotRepository, teachesRepo repository.TeachesRepository, advisorRepo repository.AdvisorRepository, prereqRepo repository.PrereqRepository) *DefaultAdminService {
+func NewAdminService(studentRepo repository.StudentRepository, instructorRepo repository.InstructorRepository, courseRepo repository.CourseRepository, sectionRepo repository.SectionRepository, departmentRepo repository.DepartmentRepository, classroomRepo repository.ClassroomRepository, timeSlotRepo repository.TimeSlotRepository, teachesRepo repository.TeachesRepository, advisorRepo repository.AdvisorRepository, prereqRepo repository.PrereqRepository, termRepo repository.TermRepository, ticketRepo repository.TimeTicketRepository, uow repository.UnitOfWork, sessions SessionRevoker, audit AuditRecorder) *DefaultAdminService {
 	return &DefaultAdminService{
 		studentRepo:    studentRepo,
 		instructorRepo: instructorRepo,
@@ -117,32 +143,46 @@ func NewAdminService(studentRepo repository.StudentRepository, instructorRepo re
 		teachesRepo:    teachesRepo,
 		advisorRepo:    advisorRepo,
 		prereqRepo:     prereqRepo,
+		termRepo:       termRepo,
+		ticketRepo:     ticketRepo,
+		uow:            uow,
+		sessions:       sessions,
+		audit:          auditRecorderOrNop(audit),
 	}
 }
 
-// GetAllStudents 获取所有学生
-func (s *DefaultAdminService) GetAllStudents() ([]*model.Student, error) {
-	students, _, err := s.studentRepo.List(1, 1000) // 设置一个较大的页大小来获取所有学生
+// ListStudents 分页查询学生
+func (s *DefaultAdminService) ListStudents(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Student], error) {
+	return s.studentRepo.List(ctx, q)
+}
+
+// CreateStudent 创建学生，返回一次性的临时密码，学生首次登录后必须修改
+func (s *DefaultAdminService) CreateStudent(ctx context.Context, actor model.Actor, id string, name string, dept string) (string, error) {
+	password, hashedPassword, err := newTemporaryPassword()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return students, nil
-}
 
-// CreateStudent 创建学生
-func (s *DefaultAdminService) CreateStudent(id string, name string, dept string) error {
 	student := &model.Student{
-		ID:      id,
-		Name:    name,
-		Dept:    dept,
-		TotCred: 0.0, // 使用float64类型
+		ID:                 id,
+		Name:               name,
+		Dept:               dept,
+		TotCred:            0.0, // 使用float64类型
+		Password:           hashedPassword,
+		MustChangePassword: true,
+	}
+	if err := s.studentRepo.Create(ctx, student); err != nil {
+		return "", err
+	}
+	if err := s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityStudent, id, nil, student.ToDTO()); err != nil {
+		return "", err
 	}
-	return s.studentRepo.Create(student)
+	return password, nil
 }
 
 // UpdateStudent 更新学生信息
-func (s *DefaultAdminService) UpdateStudent(id string, name string, dept string) error {
-	student, err := s.studentRepo.GetByID(id)
+func (s *DefaultAdminService) UpdateStudent(ctx context.Context, actor model.Actor, id string, name string, dept string) error {
+	student, err := s.studentRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("student not found: %w", err)
 	}
@@ -150,39 +190,79 @@ func (s *DefaultAdminService) UpdateStudent(id string, name string, dept string)
 		return errors.New("student not found")
 	}
 
+	before := student.ToDTO()
 	student.Name = name
 	student.Dept = dept
-	return s.studentRepo.Update(student)
+	if err := s.studentRepo.Update(ctx, student); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityStudent, id, before, student.ToDTO())
 }
 
 // DeleteStudent 删除学生
-func (s *DefaultAdminService) DeleteStudent(id string) error {
-	return s.studentRepo.Delete(id)
+func (s *DefaultAdminService) DeleteStudent(ctx context.Context, actor model.Actor, id string) error {
+	student, err := s.studentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.studentRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityStudent, id, student.ToDTO(), nil); err != nil {
+		return err
+	}
+	return s.sessions.RevokeUserSessions(ctx, id, model.RoleStudent)
+}
+
+// ListInstructors 分页查询教师
+func (s *DefaultAdminService) ListInstructors(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Instructor], error) {
+	return s.instructorRepo.List(ctx, q)
 }
 
-// GetAllInstructors 获取所有教师
-func (s *DefaultAdminService) GetAllInstructors() ([]*model.Instructor, error) {
-	instructors, _, err := s.instructorRepo.List(1, 1000) // 设置一个较大的页大小来获取所有教师
+// CreateInstructor 创建教师，返回一次性的临时密码，教师首次登录后必须修改
+func (s *DefaultAdminService) CreateInstructor(ctx context.Context, actor model.Actor, id string, name string, dept string, salary float64) (string, error) {
+	password, hashedPassword, err := newTemporaryPassword()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return instructors, nil
-}
 
-// CreateInstructor 创建教师
-func (s *DefaultAdminService) CreateInstructor(id string, name string, dept string, salary float64) error {
 	instructor := &model.Instructor{
-		ID:     id,
-		Name:   name,
-		Dept:   dept,
-		Salary: salary,
+		ID:                 id,
+		Name:               name,
+		Dept:               dept,
+		Salary:             salary,
+		Password:           hashedPassword,
+		MustChangePassword: true,
 	}
-	return s.instructorRepo.Create(instructor)
+	if err := s.instructorRepo.Create(ctx, instructor); err != nil {
+		return "", err
+	}
+	if err := s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityInstructor, id, nil, instructor.AuditSnapshot()); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+// newTemporaryPassword 生成临时密码及其哈希
+func newTemporaryPassword() (string, string, error) {
+	password, err := utils.GenerateTemporaryPassword(16)
+	if err != nil {
+		return "", "", fmt.Errorf("error generating temporary password: %w", err)
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return "", "", fmt.Errorf("error hashing password: %w", err)
+	}
+
+	return password, hashedPassword, nil
 }
 
 // UpdateInstructor 更新教师信息
-func (s *DefaultAdminService) UpdateInstructor(id string, name string, dept string, salary float64) error {
-	instructor, err := s.instructorRepo.GetByID(id)
+// salaryScope 是调用者salary.read权限的范围，只有原系部和新系部都在范围内时才更新薪水，
+// 否则保持原值，避免看不到薪水的调用者把列表中置零的薪水写回
+func (s *DefaultAdminService) UpdateInstructor(ctx context.Context, actor model.Actor, id string, name string, dept string, salary float64, salaryScope *model.PermissionScope) error {
+	instructor, err := s.instructorRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("instructor not found: %w", err)
 	}
@@ -190,24 +270,43 @@ func (s *DefaultAdminService) UpdateInstructor(id string, name string, dept stri
 		return errors.New("instructor not found")
 	}
 
+	before := instructor.AuditSnapshot()
+	oldSalary := instructor.Salary
+	if salaryScope.Allows(instructor.Dept) && salaryScope.Allows(dept) {
+		instructor.Salary = salary
+	}
 	instructor.Name = name
 	instructor.Dept = dept
-	instructor.Salary = salary
-	return s.instructorRepo.Update(instructor)
+	if err := s.instructorRepo.Update(ctx, instructor); err != nil {
+		return err
+	}
+	after := instructor.AuditSnapshot()
+	after.SalaryChanged = instructor.Salary != oldSalary
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityInstructor, id, before, after)
 }
 
 // DeleteInstructor 删除教师
-func (s *DefaultAdminService) DeleteInstructor(id string) error {
-	return s.instructorRepo.Delete(id)
+func (s *DefaultAdminService) DeleteInstructor(ctx context.Context, actor model.Actor, id string) error {
+	instructor, err := s.instructorRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.instructorRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityInstructor, id, instructor.AuditSnapshot(), nil); err != nil {
+		return err
+	}
+	return s.sessions.RevokeUserSessions(ctx, id, model.RoleInstructor)
 }
 
-// GetAllCourses 获取所有课程
-func (s *DefaultAdminService) GetAllCourses() ([]*model.Course, error) {
-	return s.courseRepo.FindAll()
+// ListCourses 分页查询课程
+func (s *DefaultAdminService) ListCourses(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Course], error) {
+	return s.courseRepo.List(ctx, q)
 }
 
 // CreateCourse 创建课程
-func (s *DefaultAdminService) CreateCourse(id string, title string, dept string, credits int) error {
+func (s *DefaultAdminService) CreateCourse(ctx context.Context, actor model.Actor, id string, title string, dept string, credits int) error {
 	course := &model.Course{
 		ID:      id,
 		Title:   title,
@@ -215,12 +314,15 @@ func (s *DefaultAdminService) CreateCourse(id string, title string, dept string,
 		Credits: float64(credits), // 转换为float64类型
 		Name:    title,            // 添加Name字段
 	}
-	return s.courseRepo.Create(course)
+	if err := s.courseRepo.Create(ctx, course); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityCourse, id, nil, course)
 }
 
 // UpdateCourse 更新课程
-func (s *DefaultAdminService) UpdateCourse(id string, title string, dept string, credits int) error {
-	course, err := s.courseRepo.FindByID(id)
+func (s *DefaultAdminService) UpdateCourse(ctx context.Context, actor model.Actor, id string, title string, dept string, credits int) error {
+	course, err := s.courseRepo.FindByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("course not found: %w", err)
 	}
@@ -228,25 +330,36 @@ func (s *DefaultAdminService) UpdateCourse(id string, title string, dept string,
 		return errors.New("course not found")
 	}
 
+	before := *course
 	course.Title = title
 	course.Dept = dept
 	course.Credits = float64(credits) // 转换为float64类型
 	course.Name = title               // 更新Name字段
-	return s.courseRepo.Update(course)
+	if err := s.courseRepo.Update(ctx, course); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityCourse, id, &before, course)
 }
 
 // DeleteCourse 删除课程
-func (s *DefaultAdminService) DeleteCourse(id string) error {
-	return s.courseRepo.Delete(id)
+func (s *DefaultAdminService) DeleteCourse(ctx context.Context, actor model.Actor, id string) error {
+	course, err := s.courseRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.courseRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityCourse, id, course, nil)
 }
 
-// GetAllSections 获取所有章节
-func (s *DefaultAdminService) GetAllSections() ([]*model.Section, error) {
-	return s.sectionRepo.FindAll()
+// ListSections 分页查询章节
+func (s *DefaultAdminService) ListSections(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Section], error) {
+	return s.sectionRepo.List(ctx, q)
 }
 
 // CreateSection 创建章节
-func (s *DefaultAdminService) CreateSection(req *model.SectionCreateRequest) error {
+func (s *DefaultAdminService) CreateSection(ctx context.Context, actor model.Actor, req *model.SectionCreateRequest) error {
 	section := &model.Section{
 		ID:         req.ID,
 		CourseID:   req.CourseID,
@@ -257,12 +370,16 @@ func (s *DefaultAdminService) CreateSection(req *model.SectionCreateRequest) err
 		TimeSlotID: req.TimeSlotID,
 		Enrollment: 0,
 	}
-	return s.sectionRepo.Create(section)
+	if err := s.sectionRepo.Create(ctx, section); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntitySection, section.Key().String(), nil, section)
 }
 
 // UpdateSection 更新章节
-func (s *DefaultAdminService) UpdateSection(id string, req *model.SectionUpdateRequest) error {
-	section, err := s.sectionRepo.FindByID(id)
+// 学期和年份属于主键，只能修改教室和时间段
+func (s *DefaultAdminService) UpdateSection(ctx context.Context, actor model.Actor, key model.SectionKey, req *model.SectionUpdateRequest) error {
+	section, err := s.sectionRepo.FindByID(ctx, key)
 	if err != nil {
 		return fmt.Errorf("section not found: %w", err)
 	}
@@ -270,12 +387,7 @@ func (s *DefaultAdminService) UpdateSection(id string, req *model.SectionUpdateR
 		return errors.New("section not found")
 	}
 
-	if req.Semester != "" {
-		section.Semester = req.Semester
-	}
-	if req.Year != 0 {
-		section.Year = req.Year
-	}
+	before := *section
 	if req.Building != "" {
 		section.Building = req.Building
 	}
@@ -286,27 +398,33 @@ func (s *DefaultAdminService) UpdateSection(id string, req *model.SectionUpdateR
 		section.TimeSlotID = req.TimeSlotID
 	}
 
-	return s.sectionRepo.Update(section)
+	if err := s.sectionRepo.Update(ctx, section); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntitySection, key.String(), &before, section)
 }
 
-// GetAllDepartments 获取所有系部
-func (s *DefaultAdminService) GetAllDepartments() ([]*model.Department, error) {
-	return s.departmentRepo.FindAll()
+// ListDepartments 分页查询系部
+func (s *DefaultAdminService) ListDepartments(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Department], error) {
+	return s.departmentRepo.List(ctx, q)
 }
 
 // CreateDepartment 创建系部
-func (s *DefaultAdminService) CreateDepartment(deptName string, building string, budget float64) error {
+func (s *DefaultAdminService) CreateDepartment(ctx context.Context, actor model.Actor, deptName string, building string, budget float64) error {
 	dept := &model.Department{
 		DeptName: deptName, // 使用正确的字段名
 		Building: building,
 		Budget:   budget,
 	}
-	return s.departmentRepo.Create(dept)
+	if err := s.departmentRepo.Create(ctx, dept); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityDepartment, deptName, nil, dept)
 }
 
 // UpdateDepartment 更新系部
-func (s *DefaultAdminService) UpdateDepartment(deptName string, building string, budget float64) error {
-	dept, err := s.departmentRepo.FindByID(deptName)
+func (s *DefaultAdminService) UpdateDepartment(ctx context.Context, actor model.Actor, deptName string, building string, budget float64) error {
+	dept, err := s.departmentRepo.FindByID(ctx, deptName)
 	if err != nil {
 		return fmt.Errorf("department not found: %w", err)
 	}
@@ -314,34 +432,48 @@ func (s *DefaultAdminService) UpdateDepartment(deptName string, building string,
 		return errors.New("department not found")
 	}
 
+	before := *dept
 	dept.Building = building
 	dept.Budget = budget
-	return s.departmentRepo.Update(dept)
+	if err := s.departmentRepo.Update(ctx, dept); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityDepartment, deptName, &before, dept)
 }
 
 // DeleteDepartment 删除系部
-func (s *DefaultAdminService) DeleteDepartment(deptName string) error {
-	return s.departmentRepo.Delete(deptName)
+func (s *DefaultAdminService) DeleteDepartment(ctx context.Context, actor model.Actor, deptName string) error {
+	dept, err := s.departmentRepo.FindByID(ctx, deptName)
+	if err != nil {
+		return err
+	}
+	if err := s.departmentRepo.Delete(ctx, deptName); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityDepartment, deptName, dept, nil)
 }
 
-// GetAllClassrooms 获取所有教室
-func (s *DefaultAdminService) GetAllClassrooms() ([]*model.Classroom, error) {
-	return s.classroomRepo.FindAll()
+// ListClassrooms 分页查询教室
+func (s *DefaultAdminService) ListClassrooms(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Classroom], error) {
+	return s.classroomRepo.List(ctx, q)
 }
 
 // CreateClassroom 创建教室
-func (s *DefaultAdminService) CreateClassroom(building string, roomNumber string, capacity int) error {
+func (s *DefaultAdminService) CreateClassroom(ctx context.Context, actor model.Actor, building string, roomNumber string, capacity int) error {
 	classroom := &model.Classroom{
 		Building:   building,
 		RoomNumber: roomNumber,
 		Capacity:   capacity,
 	}
-	return s.classroomRepo.Create(classroom)
+	if err := s.classroomRepo.Create(ctx, classroom); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityClassroom, model.AuditKey(building, roomNumber), nil, classroom)
 }
 
 // UpdateClassroom 更新教室
-func (s *DefaultAdminService) UpdateClassroom(building string, roomNumber string, capacity int) error {
-	classroom, err := s.classroomRepo.FindByBuildingAndRoom(building, roomNumber)
+func (s *DefaultAdminService) UpdateClassroom(ctx context.Context, actor model.Actor, building string, roomNumber string, capacity int) error {
+	classroom, err := s.classroomRepo.FindByBuildingAndRoom(ctx, building, roomNumber)
 	if err != nil {
 		return fmt.Errorf("classroom not found: %w", err)
 	}
@@ -349,77 +481,345 @@ func (s *DefaultAdminService) UpdateClassroom(building string, roomNumber string
 		return errors.New("classroom not found")
 	}
 
+	before := *classroom
 	classroom.Capacity = capacity
-	return s.classroomRepo.Update(classroom)
+	if err := s.classroomRepo.Update(ctx, classroom); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityClassroom, model.AuditKey(building, roomNumber), &before, classroom)
 }
 
 // DeleteClassroom 删除教室
-func (s *DefaultAdminService) DeleteClassroom(building string, roomNumber string) error {
-	return s.classroomRepo.Delete(building, roomNumber)
+func (s *DefaultAdminService) DeleteClassroom(ctx context.Context, actor model.Actor, building string, roomNumber string) error {
+	classroom, err := s.classroomRepo.FindByBuildingAndRoom(ctx, building, roomNumber)
+	if err != nil {
+		return err
+	}
+	if err := s.classroomRepo.Delete(ctx, building, roomNumber); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityClassroom, model.AuditKey(building, roomNumber), classroom, nil)
 }
 
-// GetAllPrereqs 获取所有先修课程
-func (s *DefaultAdminService) GetAllPrereqs() ([]*model.Prereq, error) {
-	return s.prereqRepo.FindAll()
+// ListPrereqs 分页查询先修课程
+func (s *DefaultAdminService) ListPrereqs(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Prereq], error) {
+	return s.prereqRepo.List(ctx, q)
 }
 
 // CreatePrereq 创建先修课程关系
-func (s *DefaultAdminService) CreatePrereq(courseID string, prereqID string) error {
+func (s *DefaultAdminService) CreatePrereq(ctx context.Context, actor model.Actor, courseID string, prereqID string) error {
 	prereq := &model.Prereq{
 		CourseID: courseID,
 		PrereqID: prereqID,
 	}
-	return s.prereqRepo.Create(prereq)
+	if err := s.prereqRepo.Create(ctx, prereq); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityPrereq, model.AuditKey(courseID, prereqID), nil, prereq)
 }
 
 // DeletePrereq 删除先修课程关系
-func (s *DefaultAdminService) DeletePrereq(courseID string, prereqID string) error {
-	return s.prereqRepo.Delete(courseID, prereqID)
+func (s *DefaultAdminService) DeletePrereq(ctx context.Context, actor model.Actor, courseID string, prereqID string) error {
+	if err := s.prereqRepo.Delete(ctx, courseID, prereqID); err != nil {
+		return err
+	}
+	prereq := &model.Prereq{CourseID: courseID, PrereqID: prereqID}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityPrereq, model.AuditKey(courseID, prereqID), prereq, nil)
 }
 
-// GetAllTeaches 获取所有教学安排
-func (s *DefaultAdminService) GetAllTeaches() ([]*model.Teaches, error) {
-	return s.teachesRepo.FindAll()
+// GetPrereqRule 获取课程生效的先修规则树，没有规则树时由先修课程关系生成
+func (s *DefaultAdminService) GetPrereqRule(ctx context.Context, courseID string) (*model.PrereqRule, error) {
+	return s.prereqRepo.GetEffectiveRule(ctx, courseID)
+}
+
+// SavePrereqRule 保存课程的先修规则树
+func (s *DefaultAdminService) SavePrereqRule(ctx context.Context, actor model.Actor, req *model.PrereqRuleRequest) error {
+	if req.CourseID == "" {
+		return errors.New("course_id is required")
+	}
+	if req.Rule == nil {
+		return errors.New("rule is required")
+	}
+	if err := req.Rule.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.courseRepo.FindByID(ctx, req.CourseID); err != nil {
+		return fmt.Errorf("course %s not found", req.CourseID)
+	}
+	for _, id := range req.Rule.CourseIDs() {
+		if id == req.CourseID {
+			return fmt.Errorf("course %s cannot be its own prerequisite", id)
+		}
+		if _, err := s.courseRepo.FindByID(ctx, id); err != nil {
+			return fmt.Errorf("prerequisite course %s not found", id)
+		}
+	}
+
+	before, err := s.findPrereqRule(ctx, req.CourseID)
+	if err != nil {
+		return err
+	}
+	if err := s.prereqRepo.SaveRule(ctx, req.CourseID, req.Rule); err != nil {
+		return err
+	}
+
+	action := model.AuditActionUpdate
+	if before == nil {
+		action = model.AuditActionCreate
+	}
+	return s.audit.Record(ctx, actor, action, model.AuditEntityPrereqRule, req.CourseID, before, req.Rule)
+}
+
+// DeletePrereqRule 删除课程的先修规则树，之后回退到先修课程关系
+func (s *DefaultAdminService) DeletePrereqRule(ctx context.Context, actor model.Actor, courseID string) error {
+	before, err := s.findPrereqRule(ctx, courseID)
+	if err != nil {
+		return err
+	}
+	if err := s.prereqRepo.DeleteRule(ctx, courseID); err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityPrereqRule, courseID, before, nil)
+}
+
+// findPrereqRule 获取课程保存的先修规则树，没有时返回nil
+func (s *DefaultAdminService) findPrereqRule(ctx context.Context, courseID string) (*model.PrereqRule, error) {
+	rule, err := s.prereqRepo.GetRule(ctx, courseID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// ListTeaches 分页查询教学安排
+func (s *DefaultAdminService) ListTeaches(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Teaches], error) {
+	return s.teachesRepo.List(ctx, q)
 }
 
 // CreateTeaches 创建教学安排
-func (s *DefaultAdminService) CreateTeaches(instructorID string, courseID string, sectionID string, semester string, year int) error {
+func (s *DefaultAdminService) CreateTeaches(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error {
 	teaches := &model.Teaches{
 		InstructorID: instructorID,
-		CourseID:     courseID,
-		SectionID:    sectionID,
-		Semester:     semester,
-		Year:         year,
+		CourseID:     key.CourseID,
+		SectionID:    key.SecID,
+		Semester:     key.Semester,
+		Year:         key.Year,
+	}
+	if err := s.teachesRepo.Create(ctx, teaches); err != nil {
+		return err
 	}
-	return s.teachesRepo.Create(teaches)
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityTeaches, model.AuditKey(instructorID, key.String()), nil, teaches)
 }
 
 // DeleteTeaches 删除教学安排
-func (s *DefaultAdminService) DeleteTeaches(instructorID string, courseID string, sectionID string, semester string, year int) error {
-	return s.teachesRepo.Delete(instructorID, courseID, sectionID, semester, year)
+func (s *DefaultAdminService) DeleteTeaches(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error {
+	if err := s.teachesRepo.Delete(ctx, instructorID, key); err != nil {
+		return err
+	}
+	teaches := &model.Teaches{
+		InstructorID: instructorID,
+		CourseID:     key.CourseID,
+		SectionID:    key.SecID,
+		Semester:     key.Semester,
+		Year:         key.Year,
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityTeaches, model.AuditKey(instructorID, key.String()), teaches, nil)
 }
 
-// GetAllAdvisors 获取所有导师关系
-func (s *DefaultAdminService) GetAllAdvisors() ([]*model.Advisor, error) {
-	return s.advisorRepo.FindAll()
+// ListAdvisors 分页查询导师关系
+func (s *DefaultAdminService) ListAdvisors(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Advisor], error) {
+	return s.advisorRepo.List(ctx, q)
 }
 
 // CreateAdvisor 创建导师关系
-func (s *DefaultAdminService) CreateAdvisor(studentID string, instructorID string) error {
+func (s *DefaultAdminService) CreateAdvisor(ctx context.Context, actor model.Actor, studentID string, instructorID string) error {
 	advisor := &model.Advisor{
 		StudentID:    studentID,
 		InstructorID: instructorID,
 	}
-	return s.advisorRepo.Create(advisor)
+	if err := s.advisorRepo.Create(ctx, advisor); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityAdvisor, model.AuditKey(studentID, instructorID), nil, advisor)
 }
 
 // DeleteAdvisor 删除导师关系
-func (s *DefaultAdminService) DeleteAdvisor(studentID string, instructorID string) error {
-	return s.advisorRepo.Delete(studentID, instructorID)
+func (s *DefaultAdminService) DeleteAdvisor(ctx context.Context, actor model.Actor, studentID string, instructorID string) error {
+	if err := s.advisorRepo.Delete(ctx, studentID, instructorID); err != nil {
+		return err
+	}
+	advisor := &model.Advisor{StudentID: studentID, InstructorID: instructorID}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityAdvisor, model.AuditKey(studentID, instructorID), advisor, nil)
+}
+
+// ListTerms 分页查询校历
+func (s *DefaultAdminService) ListTerms(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Term], error) {
+	return s.termRepo.List(ctx, q)
+}
+
+// CreateTerm 创建校历
+func (s *DefaultAdminService) CreateTerm(ctx context.Context, actor model.Actor, term *model.Term) error {
+	if err := term.Validate(); err != nil {
+		return err
+	}
+	if err := s.termRepo.Create(ctx, term); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityTerm, termAuditKey(term.Semester, term.Year), nil, term)
+}
+
+// UpdateTerm 更新校历
+func (s *DefaultAdminService) UpdateTerm(ctx context.Context, actor model.Actor, term *model.Term) error {
+	if err := term.Validate(); err != nil {
+		return err
+	}
+	before, err := s.termRepo.FindByTerm(ctx, term.Semester, term.Year)
+	if err != nil {
+		return err
+	}
+	if err := s.termRepo.Update(ctx, term); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityTerm, termAuditKey(term.Semester, term.Year), before, term)
+}
+
+// DeleteTerm 删除校历
+func (s *DefaultAdminService) DeleteTerm(ctx context.Context, actor model.Actor, semester string, year int) error {
+	before, err := s.termRepo.FindByTerm(ctx, semester, year)
+	if err != nil {
+		return err
+	}
+	if err := s.termRepo.Delete(ctx, semester, year); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityTerm, termAuditKey(semester, year), before, nil)
+}
+
+// termAuditKey 返回学期在审计日志中的实体键
+func termAuditKey(semester string, year int) string {
+	return model.AuditKey(semester, strconv.Itoa(year))
+}
+
+// ListTimeTickets 分页查询已发布的时间票，可以按学期过滤
+func (s *DefaultAdminService) ListTimeTickets(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.TimeTicket], error) {
+	return s.ticketRepo.List(ctx, q)
+}
+
+// PreviewTimeTickets 按规则计算时间票但不保存
+func (s *DefaultAdminService) PreviewTimeTickets(ctx context.Context, req *model.TimeTicketRequest) ([]*model.TimeTicket, error) {
+	return s.buildTimeTickets(ctx, req)
+}
+
+// PublishTimeTickets 按规则计算时间票并替换该学期已发布的时间票
+// 审计日志只记录发布规则和生成的时间票数量，不记录每张时间票
+func (s *DefaultAdminService) PublishTimeTickets(ctx context.Context, actor model.Actor, req *model.TimeTicketRequest) ([]*model.TimeTicket, error) {
+	tickets, err := s.buildTimeTickets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Tickets.DeleteByTerm(ctx, req.Semester, req.Year); err != nil {
+			return err
+		}
+		for _, ticket := range tickets {
+			if err := repos.Tickets.Create(ctx, ticket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	after := map[string]interface{}{"request": req, "tickets": len(tickets)}
+	if err := s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityTimeTickets, termAuditKey(req.Semester, req.Year), nil, after); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+// buildTimeTickets 确定基准时间和规则，为所有学生生成时间票
+func (s *DefaultAdminService) buildTimeTickets(ctx context.Context, req *model.TimeTicketRequest) ([]*model.TimeTicket, error) {
+	if req.Semester == "" || req.Year == 0 {
+		return nil, errors.New("semester and year are required")
+	}
+
+	var base time.Time
+	if req.StartTime != nil {
+		base = *req.StartTime
+	} else {
+		term, err := s.termRepo.FindByTerm(ctx, req.Semester, req.Year)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errors.New("start_time is required when the term has no calendar")
+		}
+		if err != nil {
+			return nil, err
+		}
+		base = term.RegistrationOpen
+	}
+
+	rules := req.Rules
+	if len(rules) == 0 {
+		rules = model.DefaultTicketRules
+	}
+
+	students, err := listAll(ctx, s.studentRepo.List)
+	if err != nil {
+		return nil, err
+	}
+
+	return generateTimeTickets(students, req.Semester, req.Year, base, rules)
+}
+
+// generateTimeTickets 按总学分把学生分到梯队中：规则按最低学分从高到低匹配，
+// 学生落入第一个满足的梯队，不满足任何规则的学生不生成时间票（不受限制）
+func generateTimeTickets(students []*model.Student, semester string, year int, base time.Time, rules []model.TicketRule) ([]*model.TimeTicket, error) {
+	sorted := make([]model.TicketRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].MinCredits > sorted[j].MinCredits })
+
+	for _, rule := range sorted {
+		if rule.MinCredits < 0 || rule.OffsetMinutes < 0 {
+			return nil, errors.New("min_credits and offset_minutes must not be negative")
+		}
+	}
+
+	tickets := make([]*model.TimeTicket, 0, len(students))
+	for _, student := range students {
+		for i, rule := range sorted {
+			if student.TotCred >= rule.MinCredits {
+				tickets = append(tickets, &model.TimeTicket{
+					StudentID: student.ID,
+					Semester:  semester,
+					Year:      year,
+					Tier:      i + 1,
+					StartTime: base.Add(time.Duration(rule.OffsetMinutes) * time.Minute),
+					Student:   student.ToDTO(),
+				})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(tickets, func(i, j int) bool {
+		if !tickets[i].StartTime.Equal(tickets[j].StartTime) {
+			return tickets[i].StartTime.Before(tickets[j].StartTime)
+		}
+		return tickets[i].StudentID < tickets[j].StudentID
+	})
+
+	return tickets, nil
 }
 
 // GetStats 获取统计信息
-func (s *DefaultAdminService) GetStats() (*model.AdminStats, error) {
+func (s *DefaultAdminService) GetStats(ctx context.Context) (*model.AdminStats, error) {
 	// 这里需要实现统计逻辑
 	// 暂时返回空结构
 	return &model.AdminStats{}, nil
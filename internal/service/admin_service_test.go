@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+func TestGenerateTimeTickets(t *testing.T) {
+	base := time.Date(2024, time.September, 1, 8, 0, 0, 0, time.UTC)
+	students := []*model.Student{
+		{ID: "S001", TotCred: 95},
+		{ID: "S002", TotCred: 60},
+		{ID: "S003", TotCred: 10},
+		{ID: "S004", TotCred: 0},
+	}
+	// 规则顺序无关，按最低学分从高到低匹配；学分低于所有规则的学生不生成时间票
+	rules := []model.TicketRule{
+		{MinCredits: 30, OffsetMinutes: 120},
+		{MinCredits: 90, OffsetMinutes: 0},
+		{MinCredits: 5, OffsetMinutes: 240},
+	}
+
+	tickets, err := generateTimeTickets(students, "Fall", 2024, base, rules)
+	if err != nil {
+		t.Fatalf("generateTimeTickets() error = %v", err)
+	}
+
+	want := []struct {
+		studentID string
+		tier      int
+		start     time.Time
+	}{
+		{"S001", 1, base},
+		{"S002", 2, base.Add(2 * time.Hour)},
+		{"S003", 3, base.Add(4 * time.Hour)},
+	}
+	if len(tickets) != len(want) {
+		t.Fatalf("Expected %d tickets, got %d", len(want), len(tickets))
+	}
+	for i, w := range want {
+		got := tickets[i]
+		if got.StudentID != w.studentID || got.Tier != w.tier || !got.StartTime.Equal(w.start) {
+			t.Errorf("ticket[%d] = {%s, %d, %s}, want {%s, %d, %s}", i, got.StudentID, got.Tier, got.StartTime, w.studentID, w.tier, w.start)
+		}
+	}
+
+	if _, err := generateTimeTickets(students, "Fall", 2024, base, []model.TicketRule{{MinCredits: -1}}); err == nil {
+		t.Error("Expected error for negative min_credits")
+	}
+}
+
+// fakeInstructorRepository 只实现修改和查找教师用到的方法
+type fakeInstructorRepository struct {
+	repository.InstructorRepository
+	instructors map[string]*model.Instructor
+}
+
+func (r *fakeInstructorRepository) GetByID(ctx context.Context, id string) (*model.Instructor, error) {
+	instructor, ok := r.instructors[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *instructor
+	return &copied, nil
+}
+
+func (r *fakeInstructorRepository) ExistsByID(ctx context.Context, id string) (bool, error) {
+	_, ok := r.instructors[id]
+	return ok, nil
+}
+
+func (r *fakeInstructorRepository) Update(ctx context.Context, instructor *model.Instructor) error {
+	copied := *instructor
+	r.instructors[instructor.ID] = &copied
+	return nil
+}
+
+func TestUpdateInstructorRecordsSalaryChange(t *testing.T) {
+	ctx := context.Background()
+	instructors := &fakeInstructorRepository{instructors: map[string]*model.Instructor{
+		"I001": {ID: "I001", Name: "Srinivasan", Dept: "Comp. Sci.", Salary: 65000, Password: "hashed"},
+	}}
+	auditRepo := &fakeAuditRepository{}
+	svc := NewAdminService(nil, instructors, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, NewAuditService(auditRepo))
+
+	actor := model.Actor{UserID: "A001", Role: model.RoleAdmin}
+	if err := svc.UpdateInstructor(ctx, actor, "I001", "Srinivasan", "Comp. Sci.", 70000, &model.PermissionScope{Global: true}); err != nil {
+		t.Fatalf("UpdateInstructor() error = %v", err)
+	}
+
+	if len(auditRepo.entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %d", len(auditRepo.entries))
+	}
+	entry := auditRepo.entries[0]
+	if entry.ActorID != "A001" || entry.Action != model.AuditActionUpdate || entry.EntityType != model.AuditEntityInstructor || entry.EntityKey != "I001" {
+		t.Errorf("Unexpected audit entry %+v", entry)
+	}
+
+	var before, after model.InstructorAuditSnapshot
+	if err := json.Unmarshal(entry.Before, &before); err != nil {
+		t.Fatalf("Unmarshal(before) error = %v", err)
+	}
+	if err := json.Unmarshal(entry.After, &after); err != nil {
+		t.Fatalf("Unmarshal(after) error = %v", err)
+	}
+	if before.SalaryChanged || !after.SalaryChanged {
+		t.Errorf("Expected the salary change to be flagged, got %+v -> %+v", before, after)
+	}
+	// 审计日志只需audit.read即可读取，薪水数值和密码哈希都不能出现
+	snapshots := string(entry.Before) + string(entry.After)
+	if strings.Contains(snapshots, "65000") || strings.Contains(snapshots, "70000") {
+		t.Errorf("Expected salary values to be left out of the audit log, got %s", snapshots)
+	}
+	if strings.Contains(snapshots, "hashed") {
+		t.Error("Expected password hash to be left out of the audit log")
+	}
+}
+
+func TestUpdateInstructorKeepsSalaryOutsideSalaryScope(t *testing.T) {
+	ctx := context.Background()
+	instructors := &fakeInstructorRepository{instructors: map[string]*model.Instructor{
+		"I001": {ID: "I001", Name: "Srinivasan", Dept: "Comp. Sci.", Salary: 65000},
+	}}
+	svc := NewAdminService(nil, instructors, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	actor := model.Actor{UserID: "A001", Role: model.RoleAdmin}
+
+	tests := []struct {
+		name       string
+		dept       string
+		scope      *model.PermissionScope
+		wantSalary float64
+	}{
+		{"no salary permission", "Comp. Sci.", nil, 65000},
+		{"salary permission for another department", "Comp. Sci.", &model.PermissionScope{Depts: []string{"Physics"}}, 65000},
+		{"moving to a department outside the scope", "Physics", &model.PermissionScope{Depts: []string{"Comp. Sci."}}, 65000},
+		{"salary permission for the department", "Comp. Sci.", &model.PermissionScope{Depts: []string{"Comp. Sci."}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instructors.instructors["I001"] = &model.Instructor{ID: "I001", Name: "Srinivasan", Dept: "Comp. Sci.", Salary: 65000}
+
+			// 列表中薪水被置零的调用者原样提交时不能把薪水清零
+			if err := svc.UpdateInstructor(ctx, actor, "I001", "Srinivasan", tt.dept, 0, tt.scope); err != nil {
+				t.Fatalf("UpdateInstructor() error = %v", err)
+			}
+			got := instructors.instructors["I001"]
+			if got.Salary != tt.wantSalary {
+				t.Errorf("Expected salary %v, got %v", tt.wantSalary, got.Salary)
+			}
+			if got.Dept != tt.dept {
+				t.Errorf("Expected dept %q, got %q", tt.dept, got.Dept)
+			}
+		})
+	}
+}
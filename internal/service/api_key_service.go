@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+var (
+	// ErrInvalidAPIKey API密钥格式错误、不存在、已过期或已吊销
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrAPIKeyNotFound API密钥不存在或已吊销
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrPermissionNotHeld 不能授予自己没有的权限
+	ErrPermissionNotHeld = errors.New("cannot grant a permission you do not hold")
+)
+
+// apiKeyTouchInterval 最近使用时间的更新间隔，避免每个请求都写数据库
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService 定义API密钥服务接口
+type APIKeyService interface {
+	ListKeys(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.APIKey], error)
+	CreateKey(ctx context.Context, actor model.Actor, granted model.PermissionSet, req *model.APIKeyCreateRequest) (*model.APIKeyCreated, error)
+	RevokeKey(ctx context.Context, actor model.Actor, id string) error
+
+	// Authenticate 校验请求携带的API密钥，返回有效的密钥
+	Authenticate(ctx context.Context, key string) (*model.APIKey, error)
+}
+
+// DefaultAPIKeyService 实现APIKeyService接口
+//
+// API密钥的格式为 "<密钥ID>.<随机串>"，数据库只保存随机串的SHA-256，完整密钥只在创建时返回一次。
+// 密钥的权限在创建后不能修改，需要调整时吊销后重新创建。
+type DefaultAPIKeyService struct {
+	keyRepo        repository.APIKeyRepository
+	departmentRepo repository.DepartmentRepository
+	uow            repository.UnitOfWork
+	audit          AuditRecorder
+	now            func() time.Time // 当前时间，测试中可替换
+}
+
+// NewAPIKeyService 创建API密钥服务实例
+func NewAPIKeyService(keyRepo repository.APIKeyRepository, departmentRepo repository.DepartmentRepository, uow repository.UnitOfWork, audit AuditRecorder) APIKeyService {
+	return &DefaultAPIKeyService{
+		keyRepo:        keyRepo,
+		departmentRepo: departmentRepo,
+		uow:            uow,
+		audit:          auditRecorderOrNop(audit),
+		now:            time.Now,
+	}
+}
+
+// ListKeys 分页查询API密钥
+func (s *DefaultAPIKeyService) ListKeys(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.APIKey], error) {
+	return s.keyRepo.List(ctx, q)
+}
+
+// CreateKey 创建API密钥，granted是创建者自己的权限，密钥的权限不能超出它的范围
+func (s *DefaultAPIKeyService) CreateKey(ctx context.Context, actor model.Actor, granted model.PermissionSet, req *model.APIKeyCreateRequest) (*model.APIKeyCreated, error) {
+	now := s.now()
+	if err := req.Validate(now); err != nil {
+		return nil, err
+	}
+
+	for _, p := range req.Permissions {
+		scope := granted.Scope(p)
+		if scope == nil || (req.Dept == "" && !scope.Global) || (req.Dept != "" && !scope.Allows(req.Dept)) {
+			return nil, fmt.Errorf("%w: %s", ErrPermissionNotHeld, p)
+		}
+	}
+
+	if req.Dept != "" {
+		if _, err := s.departmentRepo.FindByDepartment(ctx, req.Dept); err != nil {
+			return nil, fmt.Errorf("department not found: %w", err)
+		}
+	}
+
+	id, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("error generating api key: %w", err)
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating api key: %w", err)
+	}
+
+	key := &model.APIKey{
+		ID:          id,
+		Name:        req.Name,
+		KeyHash:     hashTokenSecret(secret),
+		Permissions: req.Permissions,
+		Dept:        req.Dept,
+		CreatedBy:   actor.UserID,
+		CreatedAt:   now,
+		ExpiresAt:   req.ExpiresAt,
+	}
+
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		return repos.APIKeys.Create(ctx, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityAPIKey, key.ID, nil, key); err != nil {
+		return nil, err
+	}
+
+	return &model.APIKeyCreated{APIKey: key, Key: id + "." + secret}, nil
+}
+
+// RevokeKey 吊销API密钥，吊销后立即不能再使用
+func (s *DefaultAPIKeyService) RevokeKey(ctx context.Context, actor model.Actor, id string) error {
+	key, err := s.keyRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	now := s.now()
+	if err := s.keyRepo.Revoke(ctx, id, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	after := *key
+	after.RevokedAt = &now
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityAPIKey, id, key, &after)
+}
+
+// Authenticate 校验API密钥，成功时按间隔更新最近使用时间
+func (s *DefaultAPIKeyService) Authenticate(ctx context.Context, token string) (*model.APIKey, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || id == "" || secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.keyRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashTokenSecret(secret)), []byte(key.KeyHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := s.now()
+	if !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.keyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
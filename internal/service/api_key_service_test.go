@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// fakeAPIKeyRepository 在内存中保存API密钥
+type fakeAPIKeyRepository struct {
+	keys    map[string]*model.APIKey
+	touches int
+}
+
+func (r *fakeAPIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	copied := *key
+	r.keys[key.ID] = &copied
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) FindByID(ctx context.Context, id string) (*model.APIKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (r *fakeAPIKeyRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.APIKey], error) {
+	if err := q.Normalize(model.APIKeyListFields); err != nil {
+		return nil, err
+	}
+	var keys []*model.APIKey
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	return model.NewListResult(q, keys, int64(len(keys))), nil
+}
+
+func (r *fakeAPIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	key, ok := r.keys[id]
+	if !ok || key.RevokedAt != nil {
+		return repository.ErrNotFound
+	}
+	key.RevokedAt = &revokedAt
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.touches++
+	r.keys[id].LastUsedAt = &usedAt
+	return nil
+}
+
+// fakeAPIKeyUnitOfWork 直接在内存仓库上执行工作单元
+type fakeAPIKeyUnitOfWork struct {
+	repo *fakeAPIKeyRepository
+}
+
+func (u *fakeAPIKeyUnitOfWork) Do(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	return fn(&repository.Repositories{APIKeys: u.repo})
+}
+
+func newTestAPIKeyService() (*DefaultAPIKeyService, *fakeAPIKeyRepository, *time.Time) {
+	repo := &fakeAPIKeyRepository{keys: make(map[string]*model.APIKey)}
+	svc := NewAPIKeyService(repo, nil, &fakeAPIKeyUnitOfWork{repo: repo}, nil).(*DefaultAPIKeyService)
+
+	now := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func TestAPIKeyServiceCreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newTestAPIKeyService()
+	admin := model.Actor{UserID: "A001", Role: model.RoleAdmin}
+	granted := model.PermissionSet{}
+	for _, p := range model.BasePermissions[model.RoleAdmin] {
+		granted.Grant(p, "", false)
+	}
+
+	expires := now.Add(24 * time.Hour)
+	created, err := svc.CreateKey(ctx, admin, granted, &model.APIKeyCreateRequest{
+		Name:        "lms-sync",
+		Permissions: []model.Permission{model.PermStudentsManage, model.PermGradesWrite},
+		ExpiresAt:   &expires,
+	})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	if repo.keys[created.ID].KeyHash == created.Key {
+		t.Error("Expected only the hash of the key to be stored")
+	}
+
+	key, err := svc.Authenticate(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if !key.PermissionSet().Has(model.PermStudentsManage) || key.PermissionSet().Has(model.PermAdminsManage) {
+		t.Errorf("Unexpected permissions %v", key.Permissions)
+	}
+	if repo.keys[created.ID].LastUsedAt == nil || !repo.keys[created.ID].LastUsedAt.Equal(*now) {
+		t.Error("Expected last used time to be recorded")
+	}
+
+	// 间隔内重复使用不会再次写入最近使用时间
+	*now = now.Add(10 * time.Second)
+	if _, err := svc.Authenticate(ctx, created.Key); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if repo.touches != 1 {
+		t.Errorf("Expected 1 last used update, got %d", repo.touches)
+	}
+
+	if _, err := svc.Authenticate(ctx, created.ID+".wrong"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for wrong secret, got %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, "no-separator"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for malformed key, got %v", err)
+	}
+
+	*now = expires
+	if _, err := svc.Authenticate(ctx, created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for expired key, got %v", err)
+	}
+}
+
+func TestAPIKeyServiceRevoke(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestAPIKeyService()
+	admin := model.Actor{UserID: "A001", Role: model.RoleAdmin}
+	granted := model.PermissionSet{}
+	granted.Grant(model.PermCoursesRead, "", false)
+
+	created, err := svc.CreateKey(ctx, admin, granted, &model.APIKeyCreateRequest{Name: "catalog", Permissions: []model.Permission{model.PermCoursesRead}})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+
+	if err := svc.RevokeKey(ctx, admin, created.ID); err != nil {
+		t.Fatalf("RevokeKey() error = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for revoked key, got %v", err)
+	}
+	if err := svc.RevokeKey(ctx, admin, created.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound for second revoke, got %v", err)
+	}
+}
+
+func TestAPIKeyServiceCreateValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestAPIKeyService()
+	actor := model.Actor{UserID: "I001", Role: model.RoleInstructor}
+	granted := model.PermissionSet{}
+	granted.Grant(model.PermAPIKeysManage, "", false)
+	granted.Grant(model.PermCoursesManage, "Comp. Sci.", false)
+
+	past := now.Add(-time.Hour)
+	tests := []struct {
+		name string
+		req  model.APIKeyCreateRequest
+		want error
+	}{
+		{"permission not held", model.APIKeyCreateRequest{Name: "k", Permissions: []model.Permission{model.PermStudentsManage}}, ErrPermissionNotHeld},
+		{"department scope exceeds grant", model.APIKeyCreateRequest{Name: "k", Permissions: []model.Permission{model.PermCoursesManage}}, ErrPermissionNotHeld},
+		{"other department", model.APIKeyCreateRequest{Name: "k", Permissions: []model.Permission{model.PermCoursesManage}, Dept: "Physics"}, ErrPermissionNotHeld},
+		{"self-service permission", model.APIKeyCreateRequest{Name: "k", Permissions: []model.Permission{model.PermStudentPortal}}, nil},
+		{"expired", model.APIKeyCreateRequest{Name: "k", Permissions: []model.Permission{model.PermAPIKeysManage}, ExpiresAt: &past}, nil},
+		{"missing name", model.APIKeyCreateRequest{Permissions: []model.Permission{model.PermAPIKeysManage}}, nil},
+	}
+	for _, tt := range tests {
+		_, err := svc.CreateKey(ctx, actor, granted, &tt.req)
+		if err == nil {
+			t.Errorf("%s: expected error", tt.name)
+			continue
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// auditExportLimit 单次导出的最大条数，更多的日志需要按时间范围分批导出
+const auditExportLimit = 10000
+
+// AuditRecorder 记录审计日志，各服务在写操作成功后调用
+// before和after是变更前后的实体，会被序列化为JSON快照，新建时before为nil，删除时after为nil
+type AuditRecorder interface {
+	Record(ctx context.Context, actor model.Actor, action string, entityType string, entityKey string, before interface{}, after interface{}) error
+}
+
+// AuditService 定义审计日志服务接口
+type AuditService interface {
+	AuditRecorder
+	ListEntries(ctx context.Context, filter model.AuditFilter, q *model.ListQuery) (*model.ListResult[model.AuditEntry], error)
+	ExportEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+}
+
+// DefaultAuditService 实现AuditService接口
+type DefaultAuditService struct {
+	auditRepo repository.AuditRepository
+	now       func() time.Time // 当前时间，测试中可替换
+}
+
+// NewAuditService 创建审计日志服务实例
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &DefaultAuditService{
+		auditRepo: auditRepo,
+		now:       time.Now,
+	}
+}
+
+// Record 追加一条审计日志
+func (s *DefaultAuditService) Record(ctx context.Context, actor model.Actor, action string, entityType string, entityKey string, before interface{}, after interface{}) error {
+	return writeAuditEntry(ctx, s.auditRepo, s.now(), actor, action, entityType, entityKey, before, after)
+}
+
+// ListEntries 按条件分页查询审计日志，默认最新的在前
+func (s *DefaultAuditService) ListEntries(ctx context.Context, filter model.AuditFilter, q *model.ListQuery) (*model.ListResult[model.AuditEntry], error) {
+	return s.auditRepo.List(ctx, filter, q)
+}
+
+// ExportEntries 按条件导出审计日志
+func (s *DefaultAuditService) ExportEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	filter.Limit = auditExportLimit
+	return s.auditRepo.Find(ctx, filter)
+}
+
+// recordAuditInTx 在调用方的事务中写入审计日志，随业务修改一起提交或回滚
+// 用于没有单独请求对应的系统操作，例如退课时的候补转正
+func recordAuditInTx(ctx context.Context, repos *repository.Repositories, at time.Time, action string, entityType string, entityKey string, before interface{}, after interface{}) error {
+	return writeAuditEntry(ctx, repos.Audit, at, model.SystemActor, action, entityType, entityKey, before, after)
+}
+
+// writeAuditEntry 生成快照并写入一条审计日志
+func writeAuditEntry(ctx context.Context, repo repository.AuditRepository, at time.Time, actor model.Actor, action string, entityType string, entityKey string, before interface{}, after interface{}) error {
+	entry := &model.AuditEntry{
+		ActorID:    actor.UserID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityKey:  entityKey,
+		CreatedAt:  at,
+	}
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		return err
+	}
+
+	if err := repo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+
+	return nil
+}
+
+// auditSnapshot 把实体序列化为JSON快照
+func auditSnapshot(value interface{}) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding audit snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// nopAuditRecorder 不记录任何内容，未配置审计日志时使用
+type nopAuditRecorder struct{}
+
+func (nopAuditRecorder) Record(context.Context, model.Actor, string, string, string, interface{}, interface{}) error {
+	return nil
+}
+
+// auditRecorderOrNop 未配置审计日志时返回nopAuditRecorder
+func auditRecorderOrNop(audit AuditRecorder) AuditRecorder {
+	if audit == nil {
+		return nopAuditRecorder{}
+	}
+	return audit
+}
+
+// studentActor 返回学生本人操作时的审计主体
+func studentActor(studentID string) model.Actor {
+	return model.Actor{UserID: studentID, Role: model.RoleStudent}
+}
+
+// instructorActor 返回教师本人操作时的审计主体
+func instructorActor(instructorID string) model.Actor {
+	return model.Actor{UserID: instructorID, Role: model.RoleInstructor}
+}
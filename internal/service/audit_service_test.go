@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// fakeAuditRepository 在内存中保存审计日志
+type fakeAuditRepository struct {
+	entries []*model.AuditEntry
+	filters []model.AuditFilter
+}
+
+func (r *fakeAuditRepository) Create(ctx context.Context, entry *model.AuditEntry) error {
+	entry.ID = int64(len(r.entries) + 1)
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeAuditRepository) Find(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	r.filters = append(r.filters, filter)
+	var result []*model.AuditEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		entry := r.entries[i]
+		if filter.ActorID != "" && entry.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.EntityType != "" && entry.EntityType != filter.EntityType {
+			continue
+		}
+		if filter.From != nil && entry.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !entry.CreatedAt.Before(*filter.To) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func (r *fakeAuditRepository) List(ctx context.Context, filter model.AuditFilter, q *model.ListQuery) (*model.ListResult[model.AuditEntry], error) {
+	if err := q.Normalize(model.AuditListFields); err != nil {
+		return nil, err
+	}
+	entries, _ := r.Find(ctx, filter)
+	return model.NewListResult(q, entries, int64(len(entries))), nil
+}
+
+func TestAuditServiceRecord(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeAuditRepository{}
+	svc := NewAuditService(repo).(*DefaultAuditService)
+	now := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	actor := model.Actor{UserID: "A001", Role: model.RoleAdmin}
+	course := &model.Course{ID: "CS-101", Title: "Intro", Dept: "Comp. Sci.", Credits: 4}
+	if err := svc.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityCourse, "CS-101", nil, course); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	if len(repo.entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(repo.entries))
+	}
+	entry := repo.entries[0]
+	if entry.ActorID != "A001" || entry.ActorRole != model.RoleAdmin || !entry.CreatedAt.Equal(now) {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if entry.Before != nil {
+		t.Errorf("Expected empty before snapshot, got %s", entry.Before)
+	}
+	if string(entry.After) != `{"id":"CS-101","title":"Intro","dept":"Comp. Sci.","credits":4,"name":""}` {
+		t.Errorf("Unexpected after snapshot %s", entry.After)
+	}
+}
+
+func TestAuditServiceExportLimit(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeAuditRepository{}
+	svc := NewAuditService(repo)
+
+	if _, err := svc.ExportEntries(ctx, model.AuditFilter{Limit: 5}); err != nil {
+		t.Fatalf("ExportEntries() error = %v", err)
+	}
+	if got := repo.filters[len(repo.filters)-1].Limit; got != auditExportLimit {
+		t.Errorf("ExportEntries() queried limit %d, want %d", got, auditExportLimit)
+	}
+}
+
+func TestAuditServiceFilterByTimeRange(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeAuditRepository{}
+	svc := NewAuditService(repo).(*DefaultAuditService)
+	base := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		at := base.Add(time.Duration(i) * time.Hour)
+		svc.now = func() time.Time { return at }
+		actor := model.Actor{UserID: "A001", Role: model.RoleAdmin}
+		if err := svc.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityClassroom, "Watson/100", nil, nil); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	from := base.Add(time.Hour)
+	to := base.Add(2 * time.Hour)
+	entries, err := svc.ListEntries(ctx, model.AuditFilter{EntityType: model.AuditEntityClassroom, From: &from, To: &to}, &model.ListQuery{})
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if entries.Total != 1 || !entries.Items[0].CreatedAt.Equal(from) {
+		t.Errorf("Expected only the entry at %v, got %+v", from, entries.Items)
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/yourusername/student-management-system/internal/model"
@@ -9,14 +10,14 @@ import (
 
 // ClassroomService 定义教室服务接口
 type ClassroomService interface {
-	GetClassroomByID(building, roomNumber string) (*model.Classroom, error)
-	GetAllClassrooms() ([]*model.Classroom, error)
-	GetClassroomsByBuilding(building string) ([]*model.Classroom, error)
-	CreateClassroom(building, roomNumber string, capacity int) error
-	UpdateClassroom(building, roomNumber string, capacity int) error
-	DeleteClassroom(building, roomNumber string) error
-	GetAvailableClassrooms(capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error)
-	GetClassroomUsage(building, roomNumber string, semester string, year int) ([]*model.Section, error)
+	GetClassroomByID(ctx context.Context, building, roomNumber string) (*model.Classroom, error)
+	GetAllClassrooms(ctx context.Context) ([]*model.Classroom, error)
+	GetClassroomsByBuilding(ctx context.Context, building string) ([]*model.Classroom, error)
+	CreateClassroom(ctx context.Context, building, roomNumber string, capacity int) error
+	UpdateClassroom(ctx context.Context, building, roomNumber string, capacity int) error
+	DeleteClassroom(ctx context.Context, building, roomNumber string) error
+	GetAvailableClassrooms(ctx context.Context, capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error)
+	GetClassroomUsage(ctx context.Context, building, roomNumber string, semester string, year int) ([]*model.Section, error)
 }
 
 // DefaultClassroomService 实现ClassroomService接口
@@ -37,24 +38,24 @@ func NewClassroomService(
 }
 
 // GetClassroomByID 根据ID获取教室信息
-func (s *DefaultClassroomService) GetClassroomByID(building, roomNumber string) (*model.Classroom, error) {
-	return s.classroomRepo.FindByBuildingAndRoom(building, roomNumber)
+func (s *DefaultClassroomService) GetClassroomByID(ctx context.Context, building, roomNumber string) (*model.Classroom, error) {
+	return s.classroomRepo.FindByBuildingAndRoom(ctx, building, roomNumber)
 }
 
 // GetAllClassrooms 获取所有教室
-func (s *DefaultClassroomService) GetAllClassrooms() ([]*model.Classroom, error) {
-	return s.classroomRepo.FindAll()
+func (s *DefaultClassroomService) GetAllClassrooms(ctx context.Context) ([]*model.Classroom, error) {
+	return s.classroomRepo.FindAll(ctx)
 }
 
 // GetClassroomsByBuilding 获取指定教学楼的所有教室
-func (s *DefaultClassroomService) GetClassroomsByBuilding(building string) ([]*model.Classroom, error) {
-	return s.classroomRepo.FindByBuilding(building)
+func (s *DefaultClassroomService) GetClassroomsByBuilding(ctx context.Context, building string) ([]*model.Classroom, error) {
+	return s.classroomRepo.FindByBuilding(ctx, building)
 }
 
 // CreateClassroom 创建教室 - 使用正确的字段名
-func (s *DefaultClassroomService) CreateClassroom(building, roomNumber string, capacity int) error {
+func (s *DefaultClassroomService) CreateClassroom(ctx context.Context, building, roomNumber string, capacity int) error {
 	// 检查教室是否已存在
-	_, err := s.classroomRepo.FindByBuildingAndRoom(building, roomNumber)
+	_, err := s.classroomRepo.FindByBuildingAndRoom(ctx, building, roomNumber)
 	if err == nil {
 		return fmt.Errorf("classroom already exists: %s-%s", building, roomNumber)
 	}
@@ -66,13 +67,13 @@ func (s *DefaultClassroomService) CreateClassroom(building, roomNumber string, c
 		Capacity:   capacity,   // 使用Capacity字段
 	}
 
-	return s.classroomRepo.Create(classroom)
+	return s.classroomRepo.Create(ctx, classroom)
 }
 
 // UpdateClassroom 更新教室信息 - 使用正确的字段名
-func (s *DefaultClassroomService) UpdateClassroom(building, roomNumber string, capacity int) error {
+func (s *DefaultClassroomService) UpdateClassroom(ctx context.Context, building, roomNumber string, capacity int) error {
 	// 先查询教室是否存在
-	existingClassroom, err := s.classroomRepo.FindByBuildingAndRoom(building, roomNumber)
+	existingClassroom, err := s.classroomRepo.FindByBuildingAndRoom(ctx, building, roomNumber)
 	if err != nil {
 		return err
 	}
@@ -80,13 +81,13 @@ func (s *DefaultClassroomService) UpdateClassroom(building, roomNumber string, c
 	// 更新教室信息 - 使用正确的字段名
 	existingClassroom.Capacity = capacity
 
-	return s.classroomRepo.Update(existingClassroom)
+	return s.classroomRepo.Update(ctx, existingClassroom)
 }
 
 // DeleteClassroom 删除教室
-func (s *DefaultClassroomService) DeleteClassroom(building, roomNumber string) error {
+func (s *DefaultClassroomService) DeleteClassroom(ctx context.Context, building, roomNumber string) error {
 	// 检查教室是否存在
-	_, err := s.classroomRepo.FindByBuildingAndRoom(building, roomNumber)
+	_, err := s.classroomRepo.FindByBuildingAndRoom(ctx, building, roomNumber)
 	if err != nil {
 		return err
 	}
@@ -97,7 +98,7 @@ func (s *DefaultClassroomService) DeleteClassroom(building, roomNumber string) e
 		RoomNumber: roomNumber,
 	}
 
-	sections, err := s.sectionRepo.FindByParams(params)
+	sections, err := s.sectionRepo.FindByParams(ctx, params)
 	if err != nil {
 		return fmt.Errorf("error checking classroom usage: %w", err)
 	}
@@ -106,18 +107,18 @@ func (s *DefaultClassroomService) DeleteClassroom(building, roomNumber string) e
 		return fmt.Errorf("cannot delete classroom: it is being used by %d course sections", len(sections))
 	}
 
-	return s.classroomRepo.Delete(building, roomNumber)
+	return s.classroomRepo.Delete(ctx, building, roomNumber)
 }
 
 // GetAvailableClassrooms 获取可用教室
-func (s *DefaultClassroomService) GetAvailableClassrooms(capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error) {
-	return s.classroomRepo.FindAvailable(capacity, semester, year, timeSlotID)
+func (s *DefaultClassroomService) GetAvailableClassrooms(ctx context.Context, capacity int, semester string, year int, timeSlotID string) ([]*model.Classroom, error) {
+	return s.classroomRepo.FindAvailable(ctx, capacity, semester, year, timeSlotID)
 }
 
 // GetClassroomUsage 获取教室使用情况
-func (s *DefaultClassroomService) GetClassroomUsage(building, roomNumber string, semester string, year int) ([]*model.Section, error) {
+func (s *DefaultClassroomService) GetClassroomUsage(ctx context.Context, building, roomNumber string, semester string, year int) ([]*model.Section, error) {
 	// 检查教室是否存在
-	_, err := s.classroomRepo.FindByBuildingAndRoom(building, roomNumber)
+	_, err := s.classroomRepo.FindByBuildingAndRoom(ctx, building, roomNumber)
 	if err != nil {
 		return nil, err
 	}
@@ -130,5 +131,5 @@ func (s *DefaultClassroomService) GetClassroomUsage(building, roomNumber string,
 		Year:       year,
 	}
 
-	return s.sectionRepo.FindByParams(params)
+	return s.sectionRepo.FindByParams(ctx, params)
 }
\ No newline at end of file
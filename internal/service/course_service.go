@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -10,16 +11,16 @@ import (
 
 // CourseService 定义课程服务接口
 type CourseService interface {
-	GetCourseByID(id string) (*model.Course, error)
-	GetAllCourses() ([]*model.Course, error)
-	GetCoursesByDepartment(deptName string) ([]*model.Course, error)
-	CreateCourse(req *model.CourseCreateRequest) error
-	UpdateCourse(id string, req *model.CourseUpdateRequest) error
-	DeleteCourse(id string) error
-	GetCourseWithPrereqs(id string) (*model.CourseWithPrereqs, error)
-	AddPrerequisite(courseID string, prereqID string) error
-	RemovePrerequisite(courseID string, prereqID string) error
-	GetCourses(department, title, credits string) ([]*model.Course, error)
+	GetCourseByID(ctx context.Context, id string) (*model.Course, error)
+	GetAllCourses(ctx context.Context) ([]*model.Course, error)
+	GetCoursesByDepartment(ctx context.Context, deptName string) ([]*model.Course, error)
+	CreateCourse(ctx context.Context, req *model.CourseCreateRequest) error
+	UpdateCourse(ctx context.Context, id string, req *model.CourseUpdateRequest) error
+	DeleteCourse(ctx context.Context, id string) error
+	GetCourseWithPrereqs(ctx context.Context, id string) (*model.CourseWithPrereqs, error)
+	AddPrerequisite(ctx context.Context, courseID string, prereqID string) error
+	RemovePrerequisite(ctx context.Context, courseID string, prereqID string) error
+	ListCourses(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Course], error)
 }
 
 // DefaultCourseService 实现CourseService接口
@@ -39,30 +40,30 @@ func NewCourseService(courseRepo repository.CourseRepository, prereqRepo reposit
 }
 
 // GetCourseByID 根据ID获取课程信息
-func (s *DefaultCourseService) GetCourseByID(id string) (*model.Course, error) {
-	return s.courseRepo.FindByID(id)
+func (s *DefaultCourseService) GetCourseByID(ctx context.Context, id string) (*model.Course, error) {
+	return s.courseRepo.FindByID(ctx, id)
 }
 
 // GetAllCourses 获取所有课程
-func (s *DefaultCourseService) GetAllCourses() ([]*model.Course, error) {
-	return s.courseRepo.FindAll()
+func (s *DefaultCourseService) GetAllCourses(ctx context.Context) ([]*model.Course, error) {
+	return s.courseRepo.FindAll(ctx)
 }
 
 // GetCoursesByDepartment 获取指定院系的所有课程
-func (s *DefaultCourseService) GetCoursesByDepartment(deptName string) ([]*model.Course, error) {
+func (s *DefaultCourseService) GetCoursesByDepartment(ctx context.Context, deptName string) ([]*model.Course, error) {
 	// 检查院系是否存在
-	_, err := s.deptRepo.FindByID(deptName)
+	_, err := s.deptRepo.FindByID(ctx, deptName)
 	if err != nil {
 		return nil, fmt.Errorf("department not found: %w", err)
 	}
 
-	return s.courseRepo.FindByDept(deptName)
+	return s.courseRepo.FindByDept(ctx, deptName)
 }
 
 // CreateCourse 创建课程
-func (s *DefaultCourseService) CreateCourse(req *model.CourseCreateRequest) error {
+func (s *DefaultCourseService) CreateCourse(ctx context.Context, req *model.CourseCreateRequest) error {
 	// 检查院系是否存在
-	_, err := s.deptRepo.FindByID(req.Dept)
+	_, err := s.deptRepo.FindByID(ctx, req.Dept)
 	if err != nil {
 		return fmt.Errorf("department not found: %w", err)
 	}
@@ -77,7 +78,7 @@ func (s *DefaultCourseService) CreateCourse(req *model.CourseCreateRequest) erro
 	}
 
 	// 创建课程
-	err = s.courseRepo.Create(course)
+	err = s.courseRepo.Create(ctx, course)
 	if err != nil {
 		return err
 	}
@@ -86,7 +87,7 @@ func (s *DefaultCourseService) CreateCourse(req *model.CourseCreateRequest) erro
 	if len(req.PrereqIDs) > 0 {
 		for _, prereqID := range req.PrereqIDs {
 			// 检查先修课程是否存在
-			_, err := s.courseRepo.FindByID(prereqID)
+			_, err := s.courseRepo.FindByID(ctx, prereqID)
 			if err != nil {
 				return fmt.Errorf("prerequisite course not found: %s, %w", prereqID, err)
 			}
@@ -96,7 +97,7 @@ func (s *DefaultCourseService) CreateCourse(req *model.CourseCreateRequest) erro
 				CourseID: req.ID,
 				PrereqID: prereqID,
 			}
-			err = s.prereqRepo.Create(prereq)
+			err = s.prereqRepo.Create(ctx, prereq)
 			if err != nil {
 				return fmt.Errorf("error adding prerequisite: %w", err)
 			}
@@ -107,9 +108,9 @@ func (s *DefaultCourseService) CreateCourse(req *model.CourseCreateRequest) erro
 }
 
 // UpdateCourse 更新课程信息
-func (s *DefaultCourseService) UpdateCourse(id string, req *model.CourseUpdateRequest) error {
+func (s *DefaultCourseService) UpdateCourse(ctx context.Context, id string, req *model.CourseUpdateRequest) error {
 	// 先查询课程是否存在
-	course, err := s.courseRepo.FindByID(id)
+	course, err := s.courseRepo.FindByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("course not found: %w", err)
 	}
@@ -119,7 +120,7 @@ func (s *DefaultCourseService) UpdateCourse(id string, req *model.CourseUpdateRe
 
 	// 检查新院系是否存在
 	if req.Dept != "" {
-		_, err := s.deptRepo.FindByID(req.Dept)
+		_, err := s.deptRepo.FindByID(ctx, req.Dept)
 		if err != nil {
 			return fmt.Errorf("department not found: %w", err)
 		}
@@ -135,23 +136,23 @@ func (s *DefaultCourseService) UpdateCourse(id string, req *model.CourseUpdateRe
 	}
 	course.Name = req.Title // 更新Name字段，与model.Course一致
 
-	return s.courseRepo.Update(course)
+	return s.courseRepo.Update(ctx, course)
 }
 
 // DeleteCourse 删除课程
-func (s *DefaultCourseService) DeleteCourse(id string) error {
+func (s *DefaultCourseService) DeleteCourse(ctx context.Context, id string) error {
 	// 检查课程是否存在
-	_, err := s.courseRepo.FindByID(id)
+	_, err := s.courseRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	return s.courseRepo.Delete(id)
+	return s.courseRepo.Delete(ctx, id)
 }
 
 // GetCourseWithPrereqs 获取课程及其先修课程信息
-func (s *DefaultCourseService) GetCourseWithPrereqs(id string) (*model.CourseWithPrereqs, error) {
-	course, err := s.courseRepo.FindByID(id)
+func (s *DefaultCourseService) GetCourseWithPrereqs(ctx context.Context, id string) (*model.CourseWithPrereqs, error) {
+	course, err := s.courseRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("course not found: %w", err)
 	}
@@ -159,7 +160,7 @@ func (s *DefaultCourseService) GetCourseWithPrereqs(id string) (*model.CourseWit
 		return nil, errors.New("course not found")
 	}
 
-	prereqIDs, err := s.prereqRepo.GetPrereqIDs(id)
+	prereqIDs, err := s.prereqRepo.GetPrereqIDs(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("error getting prereq IDs: %w", err)
 	}
@@ -175,21 +176,21 @@ func (s *DefaultCourseService) GetCourseWithPrereqs(id string) (*model.CourseWit
 }
 
 // AddPrerequisite 添加先修课程
-func (s *DefaultCourseService) AddPrerequisite(courseID string, prereqID string) error {
+func (s *DefaultCourseService) AddPrerequisite(ctx context.Context, courseID string, prereqID string) error {
 	// 检查课程是否存在
-	_, err := s.courseRepo.FindByID(courseID)
+	_, err := s.courseRepo.FindByID(ctx, courseID)
 	if err != nil {
 		return fmt.Errorf("course not found: %w", err)
 	}
 
 	// 检查先修课程是否存在
-	_, err = s.courseRepo.FindByID(prereqID)
+	_, err = s.courseRepo.FindByID(ctx, prereqID)
 	if err != nil {
 		return fmt.Errorf("prerequisite course not found: %w", err)
 	}
 
 	// 检查是否已经是先修课程
-	prereqs, err := s.prereqRepo.FindByCourseID(courseID)
+	prereqs, err := s.prereqRepo.FindByCourseID(ctx, courseID)
 	if err != nil {
 		return fmt.Errorf("error checking existing prerequisites: %w", err)
 	}
@@ -206,33 +207,27 @@ func (s *DefaultCourseService) AddPrerequisite(courseID string, prereqID string)
 		PrereqID: prereqID,
 	}
 
-	return s.prereqRepo.Create(prereq)
+	return s.prereqRepo.Create(ctx, prereq)
 }
 
 // RemovePrerequisite 移除先修课程
-func (s *DefaultCourseService) RemovePrerequisite(courseID string, prereqID string) error {
+func (s *DefaultCourseService) RemovePrerequisite(ctx context.Context, courseID string, prereqID string) error {
 	// 检查课程是否存在
-	_, err := s.courseRepo.FindByID(courseID)
+	_, err := s.courseRepo.FindByID(ctx, courseID)
 	if err != nil {
 		return fmt.Errorf("course not found: %w", err)
 	}
 
 	// 检查先修课程是否存在
-	_, err = s.courseRepo.FindByID(prereqID)
+	_, err = s.courseRepo.FindByID(ctx, prereqID)
 	if err != nil {
 		return fmt.Errorf("prerequisite course not found: %w", err)
 	}
 
-	return s.prereqRepo.Delete(courseID, prereqID)
+	return s.prereqRepo.Delete(ctx, courseID, prereqID)
 }
 
-// GetCourses 根据参数获取课程列表
-func (s *DefaultCourseService) GetCourses(department, title, credits string) ([]*model.Course, error) {
-	// 如果有院系参数，使用GetCoursesByDepartment
-	if department != "" {
-		return s.GetCoursesByDepartment(department)
-	}
-
-	// 否则返回所有课程
-	return s.GetAllCourses()
+// ListCourses 按分页、排序和过滤条件查询课程
+func (s *DefaultCourseService) ListCourses(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Course], error) {
+	return s.courseRepo.List(ctx, q)
 }
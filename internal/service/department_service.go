@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/yourusername/student-management-system/internal/model"
@@ -9,15 +10,15 @@ import (
 
 // DepartmentService 定义院系服务接口
 type DepartmentService interface {
-	GetDepartmentByID(id string) (*model.Department, error)
-	GetAllDepartments() ([]*model.Department, error)
-	CreateDepartment(deptName string, building string, budget float64) error
-	UpdateDepartment(deptName string, building string, budget float64) error
-	DeleteDepartment(deptName string) error
-	GetStudentCount(departmentID string) (int, error)
-	GetInstructorCount(departmentID string) (int, error)
-	GetCourseCount(departmentID string) (int, error)
-	GetDepartmentStats() ([]*model.DepartmentStats, error)
+	GetDepartmentByID(ctx context.Context, id string) (*model.Department, error)
+	GetAllDepartments(ctx context.Context) ([]*model.Department, error)
+	CreateDepartment(ctx context.Context, deptName string, building string, budget float64) error
+	UpdateDepartment(ctx context.Context, deptName string, building string, budget float64) error
+	DeleteDepartment(ctx context.Context, deptName string) error
+	GetStudentCount(ctx context.Context, departmentID string) (int, error)
+	GetInstructorCount(ctx context.Context, departmentID string) (int, error)
+	GetCourseCount(ctx context.Context, departmentID string) (int, error)
+	GetDepartmentStats(ctx context.Context) ([]*model.DepartmentStats, error)
 }
 
 // DefaultDepartmentService 实现DepartmentService接口
@@ -44,19 +45,19 @@ func NewDepartmentService(
 }
 
 // GetDepartmentByID 根据ID获取院系信息
-func (s *DefaultDepartmentService) GetDepartmentByID(id string) (*model.Department, error) {
-	return s.departmentRepo.FindByID(id)
+func (s *DefaultDepartmentService) GetDepartmentByID(ctx context.Context, id string) (*model.Department, error) {
+	return s.departmentRepo.FindByID(ctx, id)
 }
 
 // GetAllDepartments 获取所有院系
-func (s *DefaultDepartmentService) GetAllDepartments() ([]*model.Department, error) {
-	return s.departmentRepo.FindAll()
+func (s *DefaultDepartmentService) GetAllDepartments(ctx context.Context) ([]*model.Department, error) {
+	return s.departmentRepo.FindAll(ctx)
 }
 
 // CreateDepartment 创建院系 - 使用正确的字段名
-func (s *DefaultDepartmentService) CreateDepartment(deptName string, building string, budget float64) error {
+func (s *DefaultDepartmentService) CreateDepartment(ctx context.Context, deptName string, building string, budget float64) error {
 	// 检查院系ID是否已存在
-	_, err := s.departmentRepo.FindByID(deptName)
+	_, err := s.departmentRepo.FindByID(ctx, deptName)
 	if err == nil {
 		return fmt.Errorf("department with name %s already exists", deptName)
 	}
@@ -68,13 +69,13 @@ func (s *DefaultDepartmentService) CreateDepartment(deptName string, building st
 		Budget:   budget,    // 使用Budget字段
 	}
 
-	return s.departmentRepo.Create(department)
+	return s.departmentRepo.Create(ctx, department)
 }
 
 // UpdateDepartment 更新院系信息 - 使用正确的字段名
-func (s *DefaultDepartmentService) UpdateDepartment(deptName string, building string, budget float64) error {
+func (s *DefaultDepartmentService) UpdateDepartment(ctx context.Context, deptName string, building string, budget float64) error {
 	// 先查询院系是否存在
-	existingDepartment, err := s.departmentRepo.FindByID(deptName)
+	existingDepartment, err := s.departmentRepo.FindByID(ctx, deptName)
 	if err != nil {
 		return err
 	}
@@ -83,19 +84,19 @@ func (s *DefaultDepartmentService) UpdateDepartment(deptName string, building st
 	existingDepartment.Building = building
 	existingDepartment.Budget = budget
 
-	return s.departmentRepo.Update(existingDepartment)
+	return s.departmentRepo.Update(ctx, existingDepartment)
 }
 
 // DeleteDepartment 删除院系
-func (s *DefaultDepartmentService) DeleteDepartment(deptName string) error {
+func (s *DefaultDepartmentService) DeleteDepartment(ctx context.Context, deptName string) error {
 	// 检查院系是否存在
-	_, err := s.departmentRepo.FindByID(deptName)
+	_, err := s.departmentRepo.FindByID(ctx, deptName)
 	if err != nil {
 		return err
 	}
 
 	// 检查院系是否有关联的学生
-	studentCount, err := s.departmentRepo.GetStudentCount(deptName)
+	studentCount, err := s.departmentRepo.GetStudentCount(ctx, deptName)
 	if err != nil {
 		return fmt.Errorf("error checking student count: %w", err)
 	}
@@ -105,7 +106,7 @@ func (s *DefaultDepartmentService) DeleteDepartment(deptName string) error {
 	}
 
 	// 检查院系是否有关联的教师
-	instructorCount, err := s.departmentRepo.GetInstructorCount(deptName)
+	instructorCount, err := s.departmentRepo.GetInstructorCount(ctx, deptName)
 	if err != nil {
 		return fmt.Errorf("error checking instructor count: %w", err)
 	}
@@ -115,7 +116,7 @@ func (s *DefaultDepartmentService) DeleteDepartment(deptName string) error {
 	}
 
 	// 检查院系是否有关联的课程
-	courseCount, err := s.departmentRepo.GetCourseCount(deptName)
+	courseCount, err := s.departmentRepo.GetCourseCount(ctx, deptName)
 	if err != nil {
 		return fmt.Errorf("error checking course count: %w", err)
 	}
@@ -124,43 +125,43 @@ func (s *DefaultDepartmentService) DeleteDepartment(deptName string) error {
 		return fmt.Errorf("cannot delete department: it has %d associated courses", courseCount)
 	}
 
-	return s.departmentRepo.Delete(deptName)
+	return s.departmentRepo.Delete(ctx, deptName)
 }
 
 // GetStudentCount 获取院系学生数量
-func (s *DefaultDepartmentService) GetStudentCount(departmentID string) (int, error) {
+func (s *DefaultDepartmentService) GetStudentCount(ctx context.Context, departmentID string) (int, error) {
 	// 检查院系是否存在
-	_, err := s.departmentRepo.FindByID(departmentID)
+	_, err := s.departmentRepo.FindByID(ctx, departmentID)
 	if err != nil {
 		return 0, err
 	}
 
-	return s.departmentRepo.GetStudentCount(departmentID)
+	return s.departmentRepo.GetStudentCount(ctx, departmentID)
 }
 
 // GetInstructorCount 获取院系教师数量
-func (s *DefaultDepartmentService) GetInstructorCount(departmentID string) (int, error) {
+func (s *DefaultDepartmentService) GetInstructorCount(ctx context.Context, departmentID string) (int, error) {
 	// 检查院系是否存在
-	_, err := s.departmentRepo.FindByID(departmentID)
+	_, err := s.departmentRepo.FindByID(ctx, departmentID)
 	if err != nil {
 		return 0, err
 	}
 
-	return s.departmentRepo.GetInstructorCount(departmentID)
+	return s.departmentRepo.GetInstructorCount(ctx, departmentID)
 }
 
 // GetCourseCount 获取院系课程数量
-func (s *DefaultDepartmentService) GetCourseCount(departmentID string) (int, error) {
+func (s *DefaultDepartmentService) GetCourseCount(ctx context.Context, departmentID string) (int, error) {
 	// 检查院系是否存在
-	_, err := s.departmentRepo.FindByID(departmentID)
+	_, err := s.departmentRepo.FindByID(ctx, departmentID)
 	if err != nil {
 		return 0, err
 	}
 
-	return s.departmentRepo.GetCourseCount(departmentID)
+	return s.departmentRepo.GetCourseCount(ctx, departmentID)
 }
 
 // GetDepartmentStats 获取所有院系统计信息
-func (s *DefaultDepartmentService) GetDepartmentStats() ([]*model.DepartmentStats, error) {
-	return s.departmentRepo.GetDepartmentStats()
+func (s *DefaultDepartmentService) GetDepartmentStats(ctx context.Context) ([]*model.DepartmentStats, error) {
+	return s.departmentRepo.GetDepartmentStats(ctx)
 }
\ No newline at end of file
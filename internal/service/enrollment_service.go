@@ -1,21 +1,145 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/utils"
 )
 
+var (
+	// ErrSectionFull 表示课程段已满，学生可以选择加入候补队列
+	ErrSectionFull = errors.New("section is full")
+
+	// ErrRegistrationClosed 表示当前不在该学期的选课时间窗口内
+	ErrRegistrationClosed = errors.New("registration is not open for this term")
+
+	// ErrBeforeTimeTicket 表示学生的选课时间票尚未开始
+	ErrBeforeTimeTicket = errors.New("registration time ticket has not started yet")
+
+	// ErrWithdrawalDeadlinePassed 表示已经超过该学期的退选截止时间
+	ErrWithdrawalDeadlinePassed = errors.New("withdrawal deadline has passed")
+
+	// ErrCreditLimitExceeded 表示选课后会超过该学期的最高学分限制
+	ErrCreditLimitExceeded = errors.New("credit limit exceeded")
+
+	// ErrPrereqsNotSatisfied 表示学生不满足课程的先修规则，错误信息中包含未满足的条件
+	ErrPrereqsNotSatisfied = errors.New("prerequisites not satisfied")
+
+	// ErrAlreadyRegistered 表示学生已经选过该课程段
+	ErrAlreadyRegistered = errors.New("already registered for this course")
+
+	// ErrTimeConflict 表示课程段与学生已选课程时间冲突
+	ErrTimeConflict = errors.New("time conflict with existing courses")
+
+	// ErrSectionNotFound 表示课程段不存在
+	ErrSectionNotFound = errors.New("section not found")
+
+	// ErrAlreadyWaitlisted 表示学生已经在该课程段的候补队列中
+	ErrAlreadyWaitlisted = errors.New("already on the waitlist for this section")
+
+	// ErrSectionNotFull 表示课程段还有空位，应直接选课而不是候补
+	ErrSectionNotFull = errors.New("section is not full, register directly instead")
+
+	// ErrEnrollmentNotFound 表示学生没有选过该课程段
+	ErrEnrollmentNotFound = errors.New("enrollment not found")
+
+	// ErrAlreadyWithdrawn 表示学生已经退选（W）该课程段
+	ErrAlreadyWithdrawn = errors.New("already withdrawn from this course")
+
+	// ErrNotWaitlisted 表示学生不在该课程段的候补队列中
+	ErrNotWaitlisted = errors.New("not on the waitlist for this section")
+
+	// ErrAmbiguousSection 表示旧客户端只提供的sec_id对应多个课程段，需要提供完整主键
+	ErrAmbiguousSection = errors.New("section_id matches more than one section")
+
+	// ErrCartEmpty 表示学生的购物车中没有课程段
+	ErrCartEmpty = errors.New("cart is empty")
+
+	// ErrAlreadyInCart 表示课程段已经在购物车中
+	ErrAlreadyInCart = errors.New("section is already in the cart")
+
+	// ErrInvalidPermissionCode 表示许可码不存在、已使用、已撤销或不属于该学生和课程段
+	ErrInvalidPermissionCode = errors.New("invalid permission code")
+
+	// ErrNotSectionInstructor 表示教师没有教授该课程段
+	ErrNotSectionInstructor = errors.New("instructor is not teaching this section")
+
+	// errCartRollback 用于在校验购物车或原子提交失败时回滚事务，不会返回给调用方
+	errCartRollback = errors.New("cart rollback")
+)
+
+// reasonErrors 原因代码对应的哨兵错误，使调用方可以继续用errors.Is判断
+var reasonErrors = map[model.EligibilityReasonCode]error{
+	model.ReasonSectionNotFound:     ErrSectionNotFound,
+	model.ReasonRegistrationClosed:  ErrRegistrationClosed,
+	model.ReasonBeforeTimeTicket:    ErrBeforeTimeTicket,
+	model.ReasonAlreadyRegistered:   ErrAlreadyRegistered,
+	model.ReasonPrereqsNotSatisfied: ErrPrereqsNotSatisfied,
+	model.ReasonTimeConflict:        ErrTimeConflict,
+	model.ReasonCreditLimitExceeded: ErrCreditLimitExceeded,
+	model.ReasonSectionFull:         ErrSectionFull,
+	model.ReasonInvalidPermission:   ErrInvalidPermissionCode,
+}
+
+// RegistrationError 表示选课资格检查未通过，包含结构化的检查结果
+type RegistrationError struct {
+	Result *model.EligibilityResult
+}
+
+// Error 返回所有未通过检查的描述
+func (e *RegistrationError) Error() string {
+	messages := make([]string, 0, len(e.Result.Reasons))
+	for _, reason := range e.Result.Reasons {
+		messages = append(messages, reason.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap 返回每个未通过检查对应的哨兵错误
+func (e *RegistrationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Result.Reasons))
+	for _, reason := range e.Result.Reasons {
+		if err, ok := reasonErrors[reason.Code]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 // EnrollmentService 定义选课服务接口
 type EnrollmentService interface {
-	RegisterForCourse(studentID string, sectionID string) error
-	DropCourse(studentID string, sectionID string) error
-	GetRegisteredCourses(studentID string) ([]*model.Course, error)
-	CheckPrerequisites(studentID string, courseID string) (bool, error)
-	CheckTimeConflict(studentID string, sectionID string) (bool, error)
-	CheckCapacity(sectionID string) (bool, error)
+	RegisterForCourse(ctx context.Context, studentID string, key model.SectionKey) error
+	RegisterWithPermissionCode(ctx context.Context, studentID string, key model.SectionKey, code string) error
+	CheckEligibility(ctx context.Context, studentID string, key model.SectionKey) (*model.EligibilityResult, error)
+	DropCourse(ctx context.Context, studentID string, key model.SectionKey) error
+	GetRegisteredCourses(ctx context.Context, studentID string) ([]*model.Course, error)
+	CheckPrerequisites(ctx context.Context, studentID string, courseID string) (bool, error)
+	CheckTimeConflict(ctx context.Context, studentID string, key model.SectionKey) (bool, error)
+	CheckCapacity(ctx context.Context, key model.SectionKey) (bool, error)
+	JoinWaitlist(ctx context.Context, studentID string, key model.SectionKey) (*model.WaitlistEntry, error)
+	LeaveWaitlist(ctx context.Context, studentID string, key model.SectionKey) error
+	GetWaitlistPositions(ctx context.Context, studentID string) ([]*model.WaitlistEntry, error)
+	GetSectionWaitlist(ctx context.Context, instructorID string, key model.SectionKey) ([]*model.WaitlistEntry, error)
+	GetCreditLoad(ctx context.Context, studentID string, semester string, year int) (*model.CreditLoad, error)
+	RequestOverload(ctx context.Context, studentID string, req *model.OverloadCreateRequest) (*model.OverloadRequest, error)
+	GetOverloadRequests(ctx context.Context, studentID string) ([]*model.OverloadRequest, error)
+	GetPendingOverloadRequests(ctx context.Context, instructorID string) ([]*model.OverloadRequest, error)
+	DecideOverload(ctx context.Context, instructorID string, req *model.OverloadDecisionRequest) (*model.OverloadRequest, error)
+	GetCart(ctx context.Context, studentID string) ([]*model.CartItem, error)
+	AddToCart(ctx context.Context, studentID string, key model.SectionKey) (*model.CartItem, error)
+	RemoveFromCart(ctx context.Context, studentID string, key model.SectionKey) error
+	ValidateCart(ctx context.Context, studentID string) (*model.CartResult, error)
+	SubmitCart(ctx context.Context, studentID string, atomic bool) (*model.CartResult, error)
+	GrantOverride(ctx context.Context, instructorID string, req *model.OverrideGrantRequest) (*model.RegistrationOverride, error)
+	GetSectionOverrides(ctx context.Context, instructorID string, key model.SectionKey) ([]*model.RegistrationOverride, error)
+	RevokeOverride(ctx context.Context, instructorID string, id string) error
+	ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error)
 }
 
 // DefaultEnrollmentService 实现EnrollmentService接口
@@ -27,10 +151,18 @@ type DefaultEnrollmentService struct {
 	prereqRepo   repository.PrereqRepository
 	timeSlotRepo repository.TimeSlotRepository
 	teachesRepo  repository.TeachesRepository
+	waitlistRepo repository.WaitlistRepository
+	termRepo     repository.TermRepository
+	overloadRepo repository.OverloadRepository
+	advisorRepo  repository.AdvisorRepository
+	cartRepo     repository.CartRepository
+	uow          repository.UnitOfWork
+	audit        AuditRecorder
+	now          func() time.Time // 当前时间，测试中可替换
 }
 
 // NewEnrollmentService 创建选课服务实例
-func NewEnrollmentService(takesRepo repository.TakesRepository, studentRepo repository.StudentRepository, sectionRepo repository.SectionRepository, courseRepo repository.CourseRepository, prereqRepo *repository.SQLPrereqRepository, timeSlotRepo repository.TimeSlotRepository, teachesRepo repository.TeachesRepository) EnrollmentService {
+func NewEnrollmentService(takesRepo repository.TakesRepository, studentRepo repository.StudentRepository, sectionRepo repository.SectionRepository, courseRepo repository.CourseRepository, prereqRepo repository.PrereqRepository, timeSlotRepo repository.TimeSlotRepository, teachesRepo repository.TeachesRepository, waitlistRepo repository.WaitlistRepository, termRepo repository.TermRepository, overloadRepo repository.OverloadRepository, advisorRepo repository.AdvisorRepository, cartRepo repository.CartRepository, uow repository.UnitOfWork, audit AuditRecorder) EnrollmentService {
 	return &DefaultEnrollmentService{
 		takesRepo:    takesRepo,
 		studentRepo:  studentRepo,
@@ -39,89 +171,486 @@ func NewEnrollmentService(takesRepo repository.TakesRepository, studentRepo repo
 		prereqRepo:   prereqRepo,
 		timeSlotRepo: timeSlotRepo,
 		teachesRepo:  teachesRepo,
+		waitlistRepo: waitlistRepo,
+		termRepo:     termRepo,
+		overloadRepo: overloadRepo,
+		advisorRepo:  advisorRepo,
+		cartRepo:     cartRepo,
+		uow:          uow,
+		audit:        auditRecorderOrNop(audit),
+		now:          time.Now,
 	}
 }
 
 // RegisterForCourse 学生选课
-func (s *DefaultEnrollmentService) RegisterForCourse(studentID string, sectionID string) error {
-	// 检查学生是否存在
-	_, err := s.studentRepo.GetByID(studentID)
+// 所有检查和插入都在同一个事务中完成，并先锁定课程段，避免并发选课时超出容量。
+// 检查未通过时返回*RegistrationError，其中包含所有未通过的检查
+func (s *DefaultEnrollmentService) RegisterForCourse(ctx context.Context, studentID string, key model.SectionKey) error {
+	return s.register(ctx, studentID, key, "")
+}
+
+// RegisterWithPermissionCode 学生使用教师签发的许可码选课，许可码豁免的检查不再阻止选课
+func (s *DefaultEnrollmentService) RegisterWithPermissionCode(ctx context.Context, studentID string, key model.SectionKey, code string) error {
+	return s.register(ctx, studentID, key, code)
+}
+
+// register 选课的实现，code为空时只应用学生在该课程段上直接生效的特许
+func (s *DefaultEnrollmentService) register(ctx context.Context, studentID string, key model.SectionKey, code string) error {
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		// 检查学生是否存在
+		_, err := repos.Students.GetByID(ctx, studentID)
+		if err != nil {
+			return fmt.Errorf("student not found: %w", err)
+		}
+
+		result := model.NewEligibilityResult(studentID, key)
+
+		// 检查课程段是否存在并加锁，同一课程段的选课请求在这里排队
+		section, err := repos.Sections.FindByIDForUpdate(ctx, key)
+		if err != nil {
+			result.AddReason(&model.EligibilityReason{Code: model.ReasonSectionNotFound, Message: "section not found"})
+			return &RegistrationError{Result: result}
+		}
+
+		if err := s.checkRegistrationWindow(ctx, repos, studentID, section, result); err != nil {
+			return err
+		}
+		if err := s.checkRegistration(ctx, repos, studentID, section, result); err != nil {
+			return err
+		}
+		if err := s.applyOverrides(ctx, repos, studentID, section, code, result, true); err != nil {
+			return err
+		}
+		if !result.Eligible {
+			return &RegistrationError{Result: result}
+		}
+
+		return s.enroll(ctx, repos, studentID, section)
+	})
 	if err != nil {
-		return fmt.Errorf("student not found: %w", err)
+		return err
 	}
 
-	// 检查课程段是否存在
-	section, err := s.sectionRepo.FindByID(sectionID)
+	takes := &model.Takes{StudentID: studentID, CourseID: key.CourseID, SectionID: key.SecID, Semester: key.Semester, Year: key.Year}
+	return s.audit.Record(ctx, studentActor(studentID), model.AuditActionCreate, model.AuditEntityEnrollment, model.AuditKey(studentID, key.String()), nil, takes)
+}
+
+// enroll 创建选课记录，如果学生之前在候补队列中，将其候补记录标记为已转正
+func (s *DefaultEnrollmentService) enroll(ctx context.Context, repos *repository.Repositories, studentID string, section *model.Section) error {
+	if err := s.createTakes(ctx, repos, studentID, section); err != nil {
+		return err
+	}
+
+	if entry, err := repos.Waitlist.FindWaitingByStudentAndSection(ctx, studentID, section.Key()); err == nil && entry != nil {
+		if err := repos.Waitlist.UpdateStatus(ctx, entry.ID, model.EnrollmentStatusActive); err != nil {
+			return fmt.Errorf("error updating waitlist entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CheckEligibility 试运行选课的所有检查但不写入数据，返回所有未通过的检查
+func (s *DefaultEnrollmentService) CheckEligibility(ctx context.Context, studentID string, key model.SectionKey) (*model.EligibilityResult, error) {
+	result := model.NewEligibilityResult(studentID, key)
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		_, err := repos.Students.GetByID(ctx, studentID)
+		if err != nil {
+			return fmt.Errorf("student not found: %w", err)
+		}
+
+		// 只读检查，不锁定课程段
+		section, err := repos.Sections.FindByID(ctx, key)
+		if err != nil {
+			result.AddReason(&model.EligibilityReason{Code: model.ReasonSectionNotFound, Message: "section not found"})
+			return nil
+		}
+
+		if err := s.checkRegistrationWindow(ctx, repos, studentID, section, result); err != nil {
+			return err
+		}
+		if err := s.checkRegistration(ctx, repos, studentID, section, result); err != nil {
+			return err
+		}
+		// 试运行只展示特许会豁免的检查，不消耗特许
+		return s.applyOverrides(ctx, repos, studentID, section, "", result, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findTerm 查找课程段所在学期的校历，没有配置校历的学期返回nil，不做时间限制
+func (s *DefaultEnrollmentService) findTerm(ctx context.Context, repos *repository.Repositories, section *model.Section) (*model.Term, error) {
+	term, err := repos.Terms.FindByTerm(ctx, section.Semester, section.Year)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("section not found: %w", err)
+		return nil, fmt.Errorf("error getting term calendar: %w", err)
 	}
+	return term, nil
+}
+
+// checkRegistrationWindow 检查当前是否在课程段所在学期的选课时间窗口内，并且已到学生的选课时间票
+// 未通过的检查记录到result中，返回的错误只表示检查本身失败
+func (s *DefaultEnrollmentService) checkRegistrationWindow(ctx context.Context, repos *repository.Repositories, studentID string, section *model.Section, result *model.EligibilityResult) error {
+	now := s.now()
+
+	term, err := s.findTerm(ctx, repos, section)
+	if err != nil {
+		return err
+	}
+	if term != nil && !term.IsRegistrationOpen(now) {
+		result.AddReason(&model.EligibilityReason{
+			Code:    model.ReasonRegistrationClosed,
+			Message: fmt.Sprintf("%s: registration for %s %d is open from %s to %s", ErrRegistrationClosed, term.Semester, term.Year, term.RegistrationOpen.Format(time.RFC3339), term.RegistrationClose.Format(time.RFC3339)),
+		})
+	}
+
+	// 没有发布时间票的学生不受限制
+	ticket, err := repos.Tickets.FindByStudentAndTerm(ctx, studentID, section.Semester, section.Year)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error getting time ticket: %w", err)
+	}
+	if now.Before(ticket.StartTime) {
+		result.AddReason(&model.EligibilityReason{
+			Code:    model.ReasonBeforeTimeTicket,
+			Message: fmt.Sprintf("%s: opens at %s", ErrBeforeTimeTicket, ticket.StartTime.Format(time.RFC3339)),
+		})
+	}
+
+	return nil
+}
+
+// checkRegistration 检查学生是否可以选择该课程段（重复选课、先修课程、时间冲突、学分上限、容量）
+// 所有检查都会执行，未通过的检查记录到result中，返回的错误只表示检查本身失败
+func (s *DefaultEnrollmentService) checkRegistration(ctx context.Context, repos *repository.Repositories, studentID string, section *model.Section, result *model.EligibilityResult) error {
+	key := section.Key()
 
 	// 检查是否已经选过这门课
-	existingTakes, err := s.takesRepo.FindByStudentAndSection(studentID, sectionID)
+	existingTakes, err := repos.Takes.FindByStudentAndSection(ctx, studentID, key)
 	if err == nil && existingTakes != nil {
-		return errors.New("already registered for this course")
+		result.AddReason(&model.EligibilityReason{
+			Code:    model.ReasonAlreadyRegistered,
+			Message: ErrAlreadyRegistered.Error(),
+			Section: &key,
+		})
 	}
 
 	// 检查先修课程要求
-	satisfied, err := s.prereqRepo.CheckPrereqsSatisfied(studentID, section.CourseID)
+	prereqResult, err := repos.Prereqs.EvaluatePrereqs(ctx, studentID, section.CourseID, section.Semester, section.Year)
 	if err != nil {
 		return fmt.Errorf("error checking prerequisites: %w", err)
 	}
-	if !satisfied {
-		return errors.New("prerequisites not satisfied")
+	if !prereqResult.Satisfied {
+		result.AddReason(&model.EligibilityReason{
+			Code:      model.ReasonPrereqsNotSatisfied,
+			Message:   fmt.Sprintf("%s: requires %s", ErrPrereqsNotSatisfied, prereqResult.FailedClause),
+			CourseIDs: prereqResult.CourseIDs,
+		})
 	}
 
-	// 检查时间冲突
-	hasConflict, err := s.takesRepo.CheckTimeConflict(studentID, sectionID)
+	// 检查时间冲突，每个冲突的已选课程段单独记录
+	conflicts, err := repos.Takes.FindTimeConflicts(ctx, studentID, key)
 	if err != nil {
 		return fmt.Errorf("error checking time conflict: %w", err)
 	}
-	if hasConflict {
-		return errors.New("time conflict with existing courses")
+	for _, conflict := range conflicts {
+		result.AddReason(&model.EligibilityReason{
+			Code:      model.ReasonTimeConflict,
+			Message:   fmt.Sprintf("%s: %s meets %s %s-%s", ErrTimeConflict, conflict.Section, conflict.Day, conflict.StartTime, conflict.EndTime),
+			CourseIDs: []string{conflict.Section.CourseID},
+			Section:   &conflict.Section,
+			Conflict:  conflict,
+		})
+	}
+
+	// 检查学分上限
+	if err := s.checkCreditLimit(ctx, repos, studentID, section, result); err != nil {
+		return err
 	}
 
 	// 检查容量
-	enrollmentCount, err := s.sectionRepo.GetEnrollmentCount(sectionID)
+	available, err := s.checkCapacity(ctx, repos.Sections, key)
+	if err != nil {
+		return fmt.Errorf("error checking capacity: %w", err)
+	}
+	if !available {
+		result.AddReason(&model.EligibilityReason{
+			Code:    model.ReasonSectionFull,
+			Message: ErrSectionFull.Error(),
+			Section: &key,
+		})
+	}
+
+	return nil
+}
+
+// applyOverrides 应用教师签发的选课特许：学生在该课程段上直接生效的特许，以及code对应的许可码
+// 被豁免的检查从result.Reasons移到result.Waived；consume为true且豁免后可以选课时，将用到的特许标记为已使用
+func (s *DefaultEnrollmentService) applyOverrides(ctx context.Context, repos *repository.Repositories, studentID string, section *model.Section, code string, result *model.EligibilityResult, consume bool) error {
+	key := section.Key()
+	overrides, err := repos.Overrides.FindActiveByStudentAndSection(ctx, studentID, key)
+	if err != nil {
+		return fmt.Errorf("error getting registration overrides: %w", err)
+	}
+
+	if code != "" {
+		override, err := repos.Overrides.FindByCode(ctx, code)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("error getting permission code: %w", err)
+		}
+		if err != nil || override.Status != model.OverrideStatusActive || override.StudentID != studentID || override.Key() != key {
+			result.AddReason(&model.EligibilityReason{
+				Code:    model.ReasonInvalidPermission,
+				Message: ErrInvalidPermissionCode.Error(),
+				Section: &key,
+			})
+		} else {
+			overrides = append(overrides, override)
+		}
+	}
+
+	if len(overrides) == 0 || result.Eligible {
+		return nil
+	}
+
+	used := make(map[string]bool)
+	result.Waive(func(reason model.EligibilityReasonCode) string {
+		for _, override := range overrides {
+			if override.Waived(reason) {
+				used[override.ID] = true
+				return override.ID
+			}
+		}
+		return ""
+	})
+
+	if !consume || !result.Eligible {
+		return nil
+	}
+
+	now := s.now()
+	for _, override := range overrides {
+		if !used[override.ID] {
+			continue
+		}
+		if err := repos.Overrides.MarkUsed(ctx, override.ID, now); err != nil {
+			return fmt.Errorf("error using registration override %s: %w", override.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// checkCreditLimit 检查选择该课程段后学生当学期的学分是否超过上限，已批准的超载申请会提高上限
+func (s *DefaultEnrollmentService) checkCreditLimit(ctx context.Context, repos *repository.Repositories, studentID string, section *model.Section, result *model.EligibilityResult) error {
+	term, err := s.findTerm(ctx, repos, section)
+	if err != nil {
+		return err
+	}
+	if term == nil || term.MaxCredits == 0 {
+		return nil
+	}
+
+	course, err := repos.Courses.FindByID(ctx, section.CourseID)
+	if err != nil {
+		return fmt.Errorf("course not found: %w", err)
+	}
+
+	credits, err := repos.Takes.GetTermCredits(ctx, studentID, section.Semester, section.Year)
 	if err != nil {
-		return fmt.Errorf("error getting enrollment count: %w", err)
+		return err
 	}
 
-	classroom, err := s.sectionRepo.GetSectionClassroom(sectionID)
+	maxCredits, err := s.effectiveMaxCredits(ctx, repos.Overloads, studentID, term)
 	if err != nil {
-		return fmt.Errorf("error getting classroom info: %w", err)
+		return err
+	}
+
+	if credits+course.Credits > maxCredits {
+		result.AddReason(&model.EligibilityReason{
+			Code:      model.ReasonCreditLimitExceeded,
+			Message:   fmt.Sprintf("%s: %g + %g credits exceeds the limit of %g", ErrCreditLimitExceeded, credits, course.Credits, maxCredits),
+			CourseIDs: []string{section.CourseID},
+		})
+	}
+
+	return nil
+}
+
+// effectiveMaxCredits 计算学生某学期生效的最高学分：校历中的上限和已批准超载申请中的较大值
+func (s *DefaultEnrollmentService) effectiveMaxCredits(ctx context.Context, overloadRepo repository.OverloadRepository, studentID string, term *model.Term) (float64, error) {
+	maxCredits := term.MaxCredits
+	if maxCredits == 0 {
+		return 0, nil
 	}
 
-	if enrollmentCount >= classroom.Capacity {
-		return errors.New("section is full")
+	reqs, err := overloadRepo.FindByStudentAndTerm(ctx, studentID, term.Semester, term.Year)
+	if err != nil {
+		return 0, fmt.Errorf("error getting overload requests: %w", err)
 	}
+	for _, req := range reqs {
+		if req.Status == model.OverloadStatusApproved && req.RequestedCredits > maxCredits {
+			maxCredits = req.RequestedCredits
+		}
+	}
+
+	return maxCredits, nil
+}
 
-	// 创建选课记录 - 使用正确的字段名
+// createTakes 创建选课记录
+func (s *DefaultEnrollmentService) createTakes(ctx context.Context, repos *repository.Repositories, studentID string, section *model.Section) error {
 	takes := &model.Takes{
 		StudentID: studentID,
 		CourseID:  section.CourseID,
-		SectionID: sectionID,
+		SectionID: section.ID,
 		Semester:  section.Semester,
 		Year:      section.Year,
 		Grade:     "", // 新选课没有成绩
 	}
 
-	return s.takesRepo.Create(takes)
+	return repos.Takes.Create(ctx, takes)
 }
 
 // DropCourse 学生退课
-func (s *DefaultEnrollmentService) DropCourse(studentID string, sectionID string) error {
-	// 检查选课记录是否存在
-	_, err := s.takesRepo.FindByStudentAndSection(studentID, sectionID)
+// 退课截止前直接删除选课记录；退课截止后、退选截止前记为退选（W）并保留记录；
+// 两种情况都会在同一事务中为候补队列中的学生转正。退选截止后不允许退课。没有配置校历的学期按退课截止前处理
+func (s *DefaultEnrollmentService) DropCourse(ctx context.Context, studentID string, key model.SectionKey) error {
+	deleted := false
+	var before, after *model.Takes
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		// 锁定课程段，避免与同时进行的选课或转正交错
+		section, err := repos.Sections.FindByIDForUpdate(ctx, key)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSectionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// 检查选课记录是否存在
+		takes, err := repos.Takes.FindByStudentAndSection(ctx, studentID, key)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrEnrollmentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if takes.Grade == model.GradeWithdrawn {
+			return ErrAlreadyWithdrawn
+		}
+		before = takes
+
+		term, err := s.findTerm(ctx, repos, section)
+		if err != nil {
+			return err
+		}
+
+		now := s.now()
+		switch {
+		case term == nil || !now.After(term.AddDropDeadline):
+			deleted = true
+			if err := repos.Takes.Delete(ctx, studentID, key); err != nil {
+				return err
+			}
+			// 空出的名额在同一事务中转给候补学生，避免退课提交后被候补队列之外的学生抢先
+			return s.promoteFromWaitlist(ctx, repos, section)
+		case !now.After(term.WithdrawalDeadline):
+			withdrawn := *takes
+			withdrawn.Grade = model.GradeWithdrawn
+			after = &withdrawn
+			if err := repos.Takes.UpdateGrade(ctx, studentID, key, model.GradeWithdrawn); err != nil {
+				return err
+			}
+			// 退选的记录不占名额，与退课走同一个转正流程
+			return s.promoteFromWaitlist(ctx, repos, section)
+		default:
+			return ErrWithdrawalDeadlinePassed
+		}
+	})
 	if err != nil {
-		return fmt.Errorf("enrollment not found: %w", err)
+		return err
 	}
 
-	return s.takesRepo.Delete(studentID, sectionID)
+	entityKey := model.AuditKey(studentID, key.String())
+	if !deleted {
+		return s.audit.Record(ctx, studentActor(studentID), model.AuditActionUpdate, model.AuditEntityEnrollment, entityKey, before, after)
+	}
+	return s.audit.Record(ctx, studentActor(studentID), model.AuditActionDelete, model.AuditEntityEnrollment, entityKey, before, nil)
+}
+
+// promoteFromWaitlist 按排队顺序为候补学生转正，转正时重新检查先修课程和时间冲突，
+// 不满足条件的学生会被标记为rejected并跳过。选课窗口关闭后不再转正，候补学生继续等待。
+// 每次转正或拒绝都以系统身份写入审计日志。
+// 调用方必须已经在同一事务中锁定section
+func (s *DefaultEnrollmentService) promoteFromWaitlist(ctx context.Context, repos *repository.Repositories, section *model.Section) error {
+	key := section.Key()
+
+	term, err := s.findTerm(ctx, repos, section)
+	if err != nil {
+		return err
+	}
+	if term != nil && !term.IsRegistrationOpen(s.now()) {
+		return nil
+	}
+
+	entries, err := repos.Waitlist.FindBySection(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error getting waitlist: %w", err)
+	}
+
+	for _, entry := range entries {
+		result := model.NewEligibilityResult(entry.StudentID, key)
+		if err := s.checkRegistration(ctx, repos, entry.StudentID, section, result); err != nil {
+			return err
+		}
+		if len(result.Reasons) == 1 && result.HasReason(model.ReasonSectionFull) {
+			// 没有空位了，剩余学生继续等待
+			return nil
+		}
+		if !result.Eligible {
+			if err := repos.Waitlist.UpdateStatus(ctx, entry.ID, model.EnrollmentStatusRejected); err != nil {
+				return fmt.Errorf("error rejecting waitlist entry: %w", err)
+			}
+			rejected := *entry
+			rejected.Status = model.EnrollmentStatusRejected
+			if err := recordAuditInTx(ctx, repos, s.now(), model.AuditActionUpdate, model.AuditEntityWaitlist, entry.ID, entry, &rejected); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.createTakes(ctx, repos, entry.StudentID, section); err != nil {
+			return fmt.Errorf("error promoting waitlist entry: %w", err)
+		}
+		if err := repos.Waitlist.UpdateStatus(ctx, entry.ID, model.EnrollmentStatusActive); err != nil {
+			return fmt.Errorf("error updating waitlist entry: %w", err)
+		}
+
+		// 转正没有学生本人的请求，以系统身份在同一事务中记录选课和候补状态的变更
+		takes := &model.Takes{StudentID: entry.StudentID, CourseID: key.CourseID, SectionID: key.SecID, Semester: key.Semester, Year: key.Year}
+		if err := recordAuditInTx(ctx, repos, s.now(), model.AuditActionCreate, model.AuditEntityEnrollment, model.AuditKey(entry.StudentID, key.String()), nil, takes); err != nil {
+			return err
+		}
+		promoted := *entry
+		promoted.Status = model.EnrollmentStatusActive
+		if err := recordAuditInTx(ctx, repos, s.now(), model.AuditActionUpdate, model.AuditEntityWaitlist, entry.ID, entry, &promoted); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // GetRegisteredCourses 获取学生已选课程
-func (s *DefaultEnrollmentService) GetRegisteredCourses(studentID string) ([]*model.Course, error) {
-	takes, err := s.takesRepo.FindByStudentID(studentID)
+func (s *DefaultEnrollmentService) GetRegisteredCourses(ctx context.Context, studentID string) ([]*model.Course, error) {
+	takes, err := s.takesRepo.FindByStudentID(ctx, studentID)
 	if err != nil {
 		return nil, err
 	}
@@ -136,26 +665,597 @@ func (s *DefaultEnrollmentService) GetRegisteredCourses(studentID string) ([]*mo
 }
 
 // CheckPrerequisites 检查先修课程
-func (s *DefaultEnrollmentService) CheckPrerequisites(studentID string, courseID string) (bool, error) {
-	return s.prereqRepo.CheckPrereqsSatisfied(studentID, courseID)
+func (s *DefaultEnrollmentService) CheckPrerequisites(ctx context.Context, studentID string, courseID string) (bool, error) {
+	return s.prereqRepo.CheckPrereqsSatisfied(ctx, studentID, courseID)
 }
 
 // CheckTimeConflict 检查时间冲突
-func (s *DefaultEnrollmentService) CheckTimeConflict(studentID string, sectionID string) (bool, error) {
-	return s.takesRepo.CheckTimeConflict(studentID, sectionID)
+func (s *DefaultEnrollmentService) CheckTimeConflict(ctx context.Context, studentID string, key model.SectionKey) (bool, error) {
+	return s.takesRepo.CheckTimeConflict(ctx, studentID, key)
 }
 
 // CheckCapacity 检查容量
-func (s *DefaultEnrollmentService) CheckCapacity(sectionID string) (bool, error) {
-	enrollmentCount, err := s.sectionRepo.GetEnrollmentCount(sectionID)
+func (s *DefaultEnrollmentService) CheckCapacity(ctx context.Context, key model.SectionKey) (bool, error) {
+	return s.checkCapacity(ctx, s.sectionRepo, key)
+}
+
+// checkCapacity 使用指定的课程段仓库检查容量，事务内调用时传入事务中的仓库
+func (s *DefaultEnrollmentService) checkCapacity(ctx context.Context, sectionRepo repository.SectionRepository, key model.SectionKey) (bool, error) {
+	enrollmentCount, err := sectionRepo.GetEnrollmentCount(ctx, key)
 	if err != nil {
 		return false, err
 	}
 
-	classroom, err := s.sectionRepo.GetSectionClassroom(sectionID)
+	classroom, err := sectionRepo.GetSectionClassroom(ctx, key)
 	if err != nil {
 		return false, err
 	}
 
 	return enrollmentCount < classroom.Capacity, nil
 }
+
+// JoinWaitlist 学生加入已满课程段的候补队列
+func (s *DefaultEnrollmentService) JoinWaitlist(ctx context.Context, studentID string, key model.SectionKey) (*model.WaitlistEntry, error) {
+	var entry *model.WaitlistEntry
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		// 检查学生是否存在
+		_, err := repos.Students.GetByID(ctx, studentID)
+		if err != nil {
+			return fmt.Errorf("student not found: %w", err)
+		}
+
+		// 检查课程段是否存在并加锁，保证容量判断和队列序号的分配不会与其他请求交错
+		section, err := repos.Sections.FindByIDForUpdate(ctx, key)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSectionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		result := model.NewEligibilityResult(studentID, key)
+		if err := s.checkRegistrationWindow(ctx, repos, studentID, section, result); err != nil {
+			return err
+		}
+		if !result.Eligible {
+			return &RegistrationError{Result: result}
+		}
+
+		// 已选课的学生不能候补
+		existingTakes, err := repos.Takes.FindByStudentAndSection(ctx, studentID, key)
+		if err == nil && existingTakes != nil {
+			return ErrAlreadyRegistered
+		}
+
+		// 不能重复候补
+		existingEntry, err := repos.Waitlist.FindWaitingByStudentAndSection(ctx, studentID, key)
+		if err == nil && existingEntry != nil {
+			return ErrAlreadyWaitlisted
+		}
+
+		// 只有已满的课程段才允许候补
+		available, err := s.checkCapacity(ctx, repos.Sections, key)
+		if err != nil {
+			return fmt.Errorf("error checking capacity: %w", err)
+		}
+		if available {
+			return ErrSectionNotFull
+		}
+
+		id, err := utils.GenerateRandomString(16)
+		if err != nil {
+			return fmt.Errorf("error generating waitlist id: %w", err)
+		}
+
+		now := s.now()
+		entry = &model.WaitlistEntry{
+			ID:        id,
+			StudentID: studentID,
+			CourseID:  section.CourseID,
+			SectionID: section.ID,
+			Semester:  section.Semester,
+			Year:      section.Year,
+			Status:    model.EnrollmentStatusWaiting,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		if err := repos.Waitlist.Create(ctx, entry); err != nil {
+			return err
+		}
+
+		position, err := repos.Waitlist.GetPosition(ctx, entry.ID)
+		if err != nil {
+			return fmt.Errorf("error getting waitlist position: %w", err)
+		}
+		entry.Position = position
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, studentActor(studentID), model.AuditActionCreate, model.AuditEntityWaitlist, entry.ID, nil, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// LeaveWaitlist 学生退出候补队列
+func (s *DefaultEnrollmentService) LeaveWaitlist(ctx context.Context, studentID string, key model.SectionKey) error {
+	entry, err := s.waitlistRepo.FindWaitingByStudentAndSection(ctx, studentID, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotWaitlisted
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.waitlistRepo.UpdateStatus(ctx, entry.ID, model.EnrollmentStatusDropped); err != nil {
+		return err
+	}
+
+	after := *entry
+	after.Status = model.EnrollmentStatusDropped
+	return s.audit.Record(ctx, studentActor(studentID), model.AuditActionUpdate, model.AuditEntityWaitlist, entry.ID, entry, &after)
+}
+
+// GetWaitlistPositions 获取学生所有候补记录及当前排队位置
+func (s *DefaultEnrollmentService) GetWaitlistPositions(ctx context.Context, studentID string) ([]*model.WaitlistEntry, error) {
+	return s.waitlistRepo.FindByStudentID(ctx, studentID)
+}
+
+// GetSectionWaitlist 获取教师所授课程段的候补队列
+func (s *DefaultEnrollmentService) GetSectionWaitlist(ctx context.Context, instructorID string, key model.SectionKey) ([]*model.WaitlistEntry, error) {
+	// 检查教师是否教授这门课
+	_, err := s.teachesRepo.FindByInstructorAndSection(ctx, instructorID, key)
+	if err != nil {
+		return nil, fmt.Errorf("instructor not teaching this section: %w", err)
+	}
+
+	return s.waitlistRepo.FindBySection(ctx, key)
+}
+
+// GetCreditLoad 获取学生某学期的学分负载及上下限
+func (s *DefaultEnrollmentService) GetCreditLoad(ctx context.Context, studentID string, semester string, year int) (*model.CreditLoad, error) {
+	credits, err := s.takesRepo.GetTermCredits(ctx, studentID, semester, year)
+	if err != nil {
+		return nil, err
+	}
+
+	load := &model.CreditLoad{
+		Semester: semester,
+		Year:     year,
+		Credits:  credits,
+	}
+
+	term, err := s.termRepo.FindByTerm(ctx, semester, year)
+	if errors.Is(err, repository.ErrNotFound) {
+		return load, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting term calendar: %w", err)
+	}
+
+	load.MinCredits = term.MinCredits
+	load.MaxCredits, err = s.effectiveMaxCredits(ctx, s.overloadRepo, studentID, term)
+	if err != nil {
+		return nil, err
+	}
+	load.BelowMinimum = term.MinCredits > 0 && credits < term.MinCredits
+
+	return load, nil
+}
+
+// RequestOverload 学生申请在某学期超过最高学分限制，由导师审批
+func (s *DefaultEnrollmentService) RequestOverload(ctx context.Context, studentID string, req *model.OverloadCreateRequest) (*model.OverloadRequest, error) {
+	if req.Semester == "" || req.Year == 0 {
+		return nil, errors.New("semester and year are required")
+	}
+
+	term, err := s.termRepo.FindByTerm(ctx, req.Semester, req.Year)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && term.MaxCredits == 0) {
+		return nil, errors.New("this term has no credit limit")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting term calendar: %w", err)
+	}
+	if req.RequestedCredits <= term.MaxCredits {
+		return nil, fmt.Errorf("requested credits must be greater than the limit of %g", term.MaxCredits)
+	}
+
+	// 超载申请需要导师审批，没有导师的学生无法申请
+	advisors, err := s.advisorRepo.FindByStudentID(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting advisor: %w", err)
+	}
+	if len(advisors) == 0 {
+		return nil, errors.New("no advisor assigned")
+	}
+
+	existing, err := s.overloadRepo.FindByStudentAndTerm(ctx, studentID, req.Semester, req.Year)
+	if err != nil {
+		return nil, fmt.Errorf("error getting overload requests: %w", err)
+	}
+	for _, e := range existing {
+		if e.Status == model.OverloadStatusPending {
+			return nil, errors.New("an overload request for this term is already pending")
+		}
+	}
+
+	id, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("error generating overload request id: %w", err)
+	}
+
+	overload := &model.OverloadRequest{
+		ID:               id,
+		StudentID:        studentID,
+		Semester:         req.Semester,
+		Year:             req.Year,
+		RequestedCredits: req.RequestedCredits,
+		Reason:           req.Reason,
+		Status:           model.OverloadStatusPending,
+		CreatedAt:        s.now(),
+	}
+
+	if err := s.overloadRepo.Create(ctx, overload); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, studentActor(studentID), model.AuditActionCreate, model.AuditEntityOverload, overload.ID, nil, overload); err != nil {
+		return nil, err
+	}
+
+	return overload, nil
+}
+
+// GetOverloadRequests 获取学生的所有超载申请
+func (s *DefaultEnrollmentService) GetOverloadRequests(ctx context.Context, studentID string) ([]*model.OverloadRequest, error) {
+	return s.overloadRepo.FindByStudentID(ctx, studentID)
+}
+
+// GetPendingOverloadRequests 获取导师名下学生等待审批的超载申请
+func (s *DefaultEnrollmentService) GetPendingOverloadRequests(ctx context.Context, instructorID string) ([]*model.OverloadRequest, error) {
+	advisees, err := s.advisorRepo.FindByInstructorID(ctx, instructorID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting advisees: %w", err)
+	}
+
+	pending := make([]*model.OverloadRequest, 0)
+	for _, advisee := range advisees {
+		reqs, err := s.overloadRepo.FindByStudentID(ctx, advisee.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range reqs {
+			if req.Status == model.OverloadStatusPending {
+				pending = append(pending, req)
+			}
+		}
+	}
+
+	return pending, nil
+}
+
+// DecideOverload 导师批准或拒绝名下学生的超载申请
+func (s *DefaultEnrollmentService) DecideOverload(ctx context.Context, instructorID string, req *model.OverloadDecisionRequest) (*model.OverloadRequest, error) {
+	overload, err := s.overloadRepo.FindByID(ctx, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("overload request not found: %w", err)
+	}
+
+	// 只有学生的导师可以审批
+	if _, err := s.advisorRepo.FindByStudentAndInstructor(ctx, overload.StudentID, instructorID); err != nil {
+		return nil, fmt.Errorf("instructor is not the student's advisor: %w", err)
+	}
+
+	if overload.Status != model.OverloadStatusPending {
+		return nil, errors.New("overload request has already been decided")
+	}
+
+	before := *overload
+	now := s.now()
+	overload.Status = model.OverloadStatusDenied
+	if req.Approve {
+		overload.Status = model.OverloadStatusApproved
+	}
+	overload.AdvisorID = instructorID
+	overload.DecisionNote = req.Note
+	overload.DecidedAt = &now
+
+	if err := s.overloadRepo.UpdateDecision(ctx, overload); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, instructorActor(instructorID), model.AuditActionUpdate, model.AuditEntityOverload, overload.ID, &before, overload); err != nil {
+		return nil, err
+	}
+
+	return overload, nil
+}
+
+// GetCart 获取学生购物车中的课程段
+func (s *DefaultEnrollmentService) GetCart(ctx context.Context, studentID string) ([]*model.CartItem, error) {
+	return s.cartRepo.FindByStudentID(ctx, studentID)
+}
+
+// AddToCart 将课程段加入学生的购物车，此时只检查课程段是否存在以及选课是否已经结束，其余检查在校验和提交时进行
+func (s *DefaultEnrollmentService) AddToCart(ctx context.Context, studentID string, key model.SectionKey) (*model.CartItem, error) {
+	var item *model.CartItem
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		section, err := repos.Sections.FindByID(ctx, key)
+		if err != nil {
+			return ErrSectionNotFound
+		}
+
+		term, err := s.findTerm(ctx, repos, section)
+		if err != nil {
+			return err
+		}
+		if term != nil && s.now().After(term.RegistrationClose) {
+			return ErrRegistrationClosed
+		}
+
+		if existing, err := repos.Carts.FindByStudentAndSection(ctx, studentID, key); err == nil && existing != nil {
+			return ErrAlreadyInCart
+		}
+		if existing, err := repos.Takes.FindByStudentAndSection(ctx, studentID, key); err == nil && existing != nil {
+			return ErrAlreadyRegistered
+		}
+
+		item = &model.CartItem{
+			StudentID: studentID,
+			CourseID:  section.CourseID,
+			SectionID: section.ID,
+			Semester:  section.Semester,
+			Year:      section.Year,
+			AddedAt:   s.now(),
+		}
+		return repos.Carts.Create(ctx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, studentActor(studentID), model.AuditActionCreate, model.AuditEntityCart, model.AuditKey(studentID, key.String()), nil, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// RemoveFromCart 将课程段移出学生的购物车
+func (s *DefaultEnrollmentService) RemoveFromCart(ctx context.Context, studentID string, key model.SectionKey) error {
+	if err := s.cartRepo.Delete(ctx, studentID, key); err != nil {
+		return err
+	}
+
+	item := &model.CartItem{StudentID: studentID, CourseID: key.CourseID, SectionID: key.SecID, Semester: key.Semester, Year: key.Year}
+	return s.audit.Record(ctx, studentActor(studentID), model.AuditActionDelete, model.AuditEntityCart, model.AuditKey(studentID, key.String()), item, nil)
+}
+
+// ValidateCart 对购物车中的课程段逐个执行选课检查但不写入数据，
+// 排在前面的课程段视为已选，因此购物车内部的时间冲突和学分上限也会被检查出来
+func (s *DefaultEnrollmentService) ValidateCart(ctx context.Context, studentID string) (*model.CartResult, error) {
+	return s.processCart(ctx, studentID, false, false)
+}
+
+// SubmitCart 提交购物车选课，选课成功的课程段从购物车中移除
+// atomic为true时任一课程段未通过检查则全部不选，否则逐个选课并返回每一项的结果
+func (s *DefaultEnrollmentService) SubmitCart(ctx context.Context, studentID string, atomic bool) (*model.CartResult, error) {
+	result, err := s.processCart(ctx, studentID, atomic, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个选课成功的课程段记录一条选课日志，对应的购物车项随之删除
+	for _, item := range result.Items {
+		if !item.Registered {
+			continue
+		}
+		key := item.Section
+		takes := &model.Takes{StudentID: studentID, CourseID: key.CourseID, SectionID: key.SecID, Semester: key.Semester, Year: key.Year}
+		if err := s.audit.Record(ctx, studentActor(studentID), model.AuditActionCreate, model.AuditEntityEnrollment, model.AuditKey(studentID, key.String()), nil, takes); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// processCart 在一个事务中按课程段顺序检查并选课，submit为false或原子提交失败时回滚事务
+func (s *DefaultEnrollmentService) processCart(ctx context.Context, studentID string, atomic bool, submit bool) (*model.CartResult, error) {
+	result := &model.CartResult{Valid: true, Atomic: atomic}
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		_, err := repos.Students.GetByID(ctx, studentID)
+		if err != nil {
+			return fmt.Errorf("student not found: %w", err)
+		}
+
+		items, err := repos.Carts.FindByStudentID(ctx, studentID)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return ErrCartEmpty
+		}
+
+		for _, item := range items {
+			itemResult := &model.CartItemResult{
+				Section:     item.Key(),
+				Eligibility: model.NewEligibilityResult(studentID, item.Key()),
+			}
+			result.Items = append(result.Items, itemResult)
+
+			// 购物车按课程段排序，加锁顺序固定，避免并发提交时死锁
+			section, err := repos.Sections.FindByIDForUpdate(ctx, item.Key())
+			if err != nil {
+				itemResult.Eligibility.AddReason(&model.EligibilityReason{Code: model.ReasonSectionNotFound, Message: "section not found"})
+				result.Valid = false
+				continue
+			}
+
+			if err := s.checkRegistrationWindow(ctx, repos, studentID, section, itemResult.Eligibility); err != nil {
+				return err
+			}
+			if err := s.checkRegistration(ctx, repos, studentID, section, itemResult.Eligibility); err != nil {
+				return err
+			}
+			if err := s.applyOverrides(ctx, repos, studentID, section, "", itemResult.Eligibility, true); err != nil {
+				return err
+			}
+			if !itemResult.Eligibility.Eligible {
+				result.Valid = false
+				continue
+			}
+
+			// 先在事务中写入选课记录，后面的课程段检查时会把它计算在内
+			if err := s.enroll(ctx, repos, studentID, section); err != nil {
+				return err
+			}
+			itemResult.Registered = true
+		}
+
+		if !submit || (atomic && !result.Valid) {
+			return errCartRollback
+		}
+
+		for _, itemResult := range result.Items {
+			if !itemResult.Registered {
+				continue
+			}
+			if err := repos.Carts.Delete(ctx, studentID, itemResult.Section); err != nil {
+				return fmt.Errorf("error removing cart item: %w", err)
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errCartRollback) {
+		for _, itemResult := range result.Items {
+			itemResult.Registered = false
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GrantOverride 教师为学生签发一次性选课特许，只有课程段的授课教师可以签发
+// IssueCode为true时生成许可码，学生选课时需要提供；否则学生选课时自动生效
+func (s *DefaultEnrollmentService) GrantOverride(ctx context.Context, instructorID string, req *model.OverrideGrantRequest) (*model.RegistrationOverride, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := s.ResolveSection(ctx, &req.SectionRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.teachesRepo.FindByInstructorAndSection(ctx, instructorID, key); err != nil {
+		return nil, ErrNotSectionInstructor
+	}
+
+	var override *model.RegistrationOverride
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		if _, err := repos.Students.GetByID(ctx, req.StudentID); err != nil {
+			return fmt.Errorf("student not found: %w", err)
+		}
+
+		section, err := repos.Sections.FindByID(ctx, key)
+		if err != nil {
+			return ErrSectionNotFound
+		}
+
+		id, err := utils.GenerateRandomString(16)
+		if err != nil {
+			return fmt.Errorf("error generating override id: %w", err)
+		}
+
+		override = &model.RegistrationOverride{
+			ID:        id,
+			StudentID: req.StudentID,
+			CourseID:  section.CourseID,
+			SectionID: section.ID,
+			Semester:  section.Semester,
+			Year:      section.Year,
+			Waives:    req.Waives,
+			GrantedBy: instructorID,
+			Reason:    req.Reason,
+			Status:    model.OverrideStatusActive,
+			CreatedAt: s.now(),
+		}
+
+		if req.IssueCode {
+			code, err := utils.GenerateRandomString(4)
+			if err != nil {
+				return fmt.Errorf("error generating permission code: %w", err)
+			}
+			override.Code = strings.ToUpper(code)
+		}
+
+		return repos.Overrides.Create(ctx, override)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, instructorActor(instructorID), model.AuditActionCreate, model.AuditEntityOverride, override.ID, nil, override); err != nil {
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// GetSectionOverrides 获取教师所授课程段签发的所有选课特许
+func (s *DefaultEnrollmentService) GetSectionOverrides(ctx context.Context, instructorID string, key model.SectionKey) ([]*model.RegistrationOverride, error) {
+	if _, err := s.teachesRepo.FindByInstructorAndSection(ctx, instructorID, key); err != nil {
+		return nil, ErrNotSectionInstructor
+	}
+
+	var overrides []*model.RegistrationOverride
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		var err error
+		overrides, err = repos.Overrides.FindBySection(ctx, key)
+		return err
+	})
+	return overrides, err
+}
+
+// RevokeOverride 撤销尚未使用的选课特许，课程段的授课教师都可以撤销
+func (s *DefaultEnrollmentService) RevokeOverride(ctx context.Context, instructorID string, id string) error {
+	var before *model.RegistrationOverride
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		override, err := repos.Overrides.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = override
+
+		if _, err := s.teachesRepo.FindByInstructorAndSection(ctx, instructorID, override.Key()); err != nil {
+			return ErrNotSectionInstructor
+		}
+
+		if override.Status != model.OverrideStatusActive {
+			return errors.New("override has already been used or revoked")
+		}
+
+		return repos.Overrides.Revoke(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	after := *before
+	after.Status = model.OverrideStatusRevoked
+	return s.audit.Record(ctx, instructorActor(instructorID), model.AuditActionUpdate, model.AuditEntityOverride, id, before, &after)
+}
+
+// ResolveSection 将请求中的课程段引用解析为完整主键，旧客户端只提供sec_id时按其余字段缩小范围
+func (s *DefaultEnrollmentService) ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error) {
+	return resolveSectionRef(ctx, s.sectionRepo, ref)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/dialect"
+	"github.com/yourusername/student-management-system/pkg/migrate"
+)
+
+// TestEnrollmentService_RegisterForCourse_SQL 在真实数据库上并发抢同一个课程段，
+// 验证课程段行锁保证选课人数不超过容量。SQLite总是运行（依赖_txlock=immediate串行化写事务）；
+// MySQL和PostgreSQL需要通过TEST_MYSQL_DSN（需带parseTime=true）和TEST_POSTGRES_DSN
+// 指定一个可以随意清空的测试库。
+func TestEnrollmentService_RegisterForCourse_SQL(t *testing.T) {
+	t.Run(dialect.SQLite, func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "enrollment.db")
+		dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+		testConcurrentRegistration(t, openMigratedDB(t, dialect.SQLite, dsn))
+	})
+
+	servers := []struct {
+		driver string
+		env    string
+	}{
+		{dialect.MySQL, "TEST_MYSQL_DSN"},
+		{dialect.Postgres, "TEST_POSTGRES_DSN"},
+	}
+	for _, server := range servers {
+		server := server
+		t.Run(server.driver, func(t *testing.T) {
+			dsn := os.Getenv(server.env)
+			if dsn == "" {
+				t.Skipf("%s not set", server.env)
+			}
+			testConcurrentRegistration(t, openMigratedDB(t, server.driver, dsn))
+		})
+	}
+}
+
+// openMigratedDB 打开数据库，回滚全部迁移后重新执行，返回一个空库
+func openMigratedDB(t *testing.T, driver, dsn string) *repository.DB {
+	t.Helper()
+	db, d, err := dialect.Open(driver, dsn)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := migrate.Load(os.DirFS(filepath.Join("../../scripts/migrations", d.Name())))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	migrator := migrate.New(db, d, migrations)
+	if _, err := migrator.Down(len(migrations)); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if _, err := migrator.Up(0); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	return repository.NewDB(db, d)
+}
+
+func testConcurrentRegistration(t *testing.T, db *repository.DB) {
+	ctx := context.Background()
+	const (
+		capacity = 3
+		students = 20
+	)
+
+	sectionRepo := repository.NewSectionRepository(db)
+	studentRepo := repository.NewStudentRepository(db)
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("seed error = %v", err)
+		}
+	}
+	must(repository.NewDepartmentRepository(db).Create(ctx, &model.Department{DeptName: "Comp. Sci.", Building: "Taylor", Budget: 100000}))
+	must(repository.NewCourseRepository(db).Create(ctx, &model.Course{ID: "CS-101", Title: "Intro. to Computer Science", Dept: "Comp. Sci.", Credits: 4}))
+	must(repository.NewClassroomRepository(db).Create(ctx, &model.Classroom{Building: "Packard", RoomNumber: "101", Capacity: capacity}))
+	must(repository.NewTimeSlotRepository(db).Create(ctx, &model.TimeSlot{ID: "A", Days: []int{1}, StartHr: 8, StartMin: 0, EndHr: 8, EndMin: 50}))
+	must(sectionRepo.Create(ctx, &model.Section{ID: "1", CourseID: "CS-101", Semester: "Fall", Year: 2024, Building: "Packard", RoomNumber: "101", TimeSlotID: "A"}))
+	for i := 0; i < students; i++ {
+		id := fmt.Sprintf("S%03d", i)
+		must(studentRepo.Create(ctx, &model.Student{ID: id, Name: id, Dept: "Comp. Sci.", Password: "hash", Salt: "salt"}))
+	}
+	key := model.SectionKey{CourseID: "CS-101", SecID: "1", Semester: "Fall", Year: 2024}
+
+	svc := NewEnrollmentService(repository.NewTakesRepository(db), studentRepo, sectionRepo, nil, nil, nil, nil, repository.NewWaitlistRepository(db), nil, nil, nil, nil, repository.NewUnitOfWork(db), nil)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	start := make(chan struct{})
+	for i := 0; i < students; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			<-start
+			err := svc.RegisterForCourse(ctx, id, key)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, ErrSectionFull):
+			default:
+				t.Errorf("RegisterForCourse(%s) unexpected error: %v", id, err)
+			}
+		}(fmt.Sprintf("S%03d", i))
+	}
+	close(start)
+	wg.Wait()
+
+	var count int
+	row := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM takes WHERE course_id = ? AND sec_id = ? AND semester = ? AND year = ?`, key.CourseID, key.SecID, key.Semester, key.Year)
+	if err := row.Scan(&count); err != nil {
+		t.Fatalf("count takes error = %v", err)
+	}
+	if count != capacity {
+		t.Errorf("Expected %d rows in takes, got %d", capacity, count)
+	}
+	if succeeded != capacity {
+		t.Errorf("Expected %d successful registrations, got %d", capacity, succeeded)
+	}
+}
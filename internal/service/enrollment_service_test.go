@@ -0,0 +1,1230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/internal/repository/memory"
+)
+
+// enrollmentStore 是选课测试使用的内存数据，模拟数据库中的行和行锁
+type enrollmentStore struct {
+	mu           sync.Mutex
+	students     map[string]*model.Student
+	sections     map[string]*model.Section // 课程段主键 -> section，下同
+	courses      map[string]*model.Course
+	capacity     map[string]int
+	takes        map[string]map[string]*model.Takes // 课程段主键 -> student_id -> takes
+	sectionLocks map[string]*sync.Mutex
+	terms        map[string]*model.Term       // semester+year -> term
+	tickets      map[string]*model.TimeTicket // student_id -> ticket
+	overloads    []*model.OverloadRequest
+	carts        map[string][]*model.CartItem // student_id -> 按课程段排序的购物车
+	overrides    []*model.RegistrationOverride
+	waitlist     []*model.WaitlistEntry
+	noPrereqs    map[string]bool                  // student_id -> 不满足先修课程
+	conflicts    map[string][]*model.TimeConflict // student_id -> 时间冲突
+	audit        []*model.AuditEntry              // 事务内写入的审计日志
+}
+
+func newEnrollmentStore() *enrollmentStore {
+	return &enrollmentStore{
+		students:     make(map[string]*model.Student),
+		sections:     make(map[string]*model.Section),
+		courses:      make(map[string]*model.Course),
+		capacity:     make(map[string]int),
+		takes:        make(map[string]map[string]*model.Takes),
+		sectionLocks: make(map[string]*sync.Mutex),
+		terms:        make(map[string]*model.Term),
+		tickets:      make(map[string]*model.TimeTicket),
+		carts:        make(map[string][]*model.CartItem),
+		noPrereqs:    make(map[string]bool),
+		conflicts:    make(map[string][]*model.TimeConflict),
+	}
+}
+
+func (s *enrollmentStore) addSection(section *model.Section, capacity int) {
+	key := section.Key().String()
+	s.sections[key] = section
+	s.capacity[key] = capacity
+	s.takes[key] = make(map[string]*model.Takes)
+	s.sectionLocks[key] = &sync.Mutex{}
+}
+
+// key 返回sec_id对应课程段的完整主键，只用于sec_id不重复的测试
+func (s *enrollmentStore) key(secID string) model.SectionKey {
+	for _, section := range s.sections {
+		if section.ID == secID {
+			return section.Key()
+		}
+	}
+	return model.SectionKey{}
+}
+
+// enrollmentCount 返回占用名额的选课人数，退选（W）的记录不占名额
+func (s *enrollmentStore) enrollmentCount(key model.SectionKey) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, takes := range s.takes[key.String()] {
+		if takes.Grade != model.GradeWithdrawn {
+			count++
+		}
+	}
+	return count
+}
+
+// fakeTx 记录一个工作单元持有的行锁和回滚操作
+type fakeTx struct {
+	store *enrollmentStore
+	locks []*sync.Mutex
+	undo  []func()
+}
+
+// fakeUnitOfWork 是UnitOfWork的内存实现：FindByIDForUpdate 持有课程段锁直到 Do 结束，fn 返回错误时撤销所有写入
+type fakeUnitOfWork struct {
+	store *enrollmentStore
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	tx := &fakeTx{store: u.store}
+	defer func() {
+		for i := len(tx.locks) - 1; i >= 0; i-- {
+			tx.locks[i].Unlock()
+		}
+	}()
+
+	repos := &repository.Repositories{
+		Students:  &fakeStudentRepository{tx: tx},
+		Sections:  &fakeSectionRepository{tx: tx},
+		Courses:   &fakeCourseRepository{store: u.store},
+		Takes:     &fakeTakesRepository{tx: tx},
+		Prereqs:   &fakePrereqRepository{PrereqRepository: memory.NewPrereqRepository(memory.NewStore()), store: u.store},
+		Waitlist:  &fakeWaitlistRepository{tx: tx},
+		Terms:     &fakeTermRepository{store: u.store},
+		Tickets:   &fakeTimeTicketRepository{store: u.store},
+		Overloads: &fakeOverloadRepository{store: u.store},
+		Carts:     &fakeCartRepository{tx: tx},
+		Overrides: &fakeOverrideRepository{tx: tx},
+		Audit:     &fakeTxAuditRepository{tx: tx},
+	}
+
+	if err := fn(repos); err != nil {
+		u.store.mu.Lock()
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		u.store.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+type fakeTxAuditRepository struct {
+	repository.AuditRepository
+	tx *fakeTx
+}
+
+func (r *fakeTxAuditRepository) Create(ctx context.Context, entry *model.AuditEntry) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	n := len(r.tx.store.audit)
+	r.tx.store.audit = append(r.tx.store.audit, entry)
+	r.tx.undo = append(r.tx.undo, func() { r.tx.store.audit = r.tx.store.audit[:n] })
+	return nil
+}
+
+type fakeStudentRepository struct {
+	repository.StudentRepository
+	tx *fakeTx
+}
+
+func (r *fakeStudentRepository) GetByID(ctx context.Context, id string) (*model.Student, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	if student, ok := r.tx.store.students[id]; ok {
+		return student, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+type fakeSectionRepository struct {
+	repository.SectionRepository
+	tx *fakeTx
+}
+
+func (r *fakeSectionRepository) FindByIDForUpdate(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	r.tx.store.mu.Lock()
+	section, ok := r.tx.store.sections[key.String()]
+	lock := r.tx.store.sectionLocks[key.String()]
+	r.tx.store.mu.Unlock()
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	lock.Lock()
+	r.tx.locks = append(r.tx.locks, lock)
+	return section, nil
+}
+
+func (r *fakeSectionRepository) FindByID(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	section, ok := r.tx.store.sections[key.String()]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return section, nil
+}
+
+func (r *fakeSectionRepository) FindBySecID(ctx context.Context, secID string) ([]*model.Section, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	var sections []*model.Section
+	for _, section := range r.tx.store.sections {
+		if section.ID == secID {
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+func (r *fakeSectionRepository) GetEnrollmentCount(ctx context.Context, key model.SectionKey) (int, error) {
+	count := r.tx.store.enrollmentCount(key)
+	// 让出调度，放大检查和插入之间的竞争窗口
+	runtime.Gosched()
+	return count, nil
+}
+
+func (r *fakeSectionRepository) GetSectionClassroom(ctx context.Context, key model.SectionKey) (*model.Classroom, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	return &model.Classroom{Capacity: r.tx.store.capacity[key.String()]}, nil
+}
+
+type fakeCourseRepository struct {
+	repository.CourseRepository
+	store *enrollmentStore
+}
+
+func (r *fakeCourseRepository) FindByID(ctx context.Context, id string) (*model.Course, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if course, ok := r.store.courses[id]; ok {
+		return course, nil
+	}
+	return &model.Course{ID: id}, nil
+}
+
+type fakeTakesRepository struct {
+	repository.TakesRepository
+	tx *fakeTx
+}
+
+func (r *fakeTakesRepository) FindByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.Takes, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	if takes, ok := r.tx.store.takes[key.String()][studentID]; ok {
+		return takes, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeTakesRepository) FindTimeConflicts(ctx context.Context, studentID string, key model.SectionKey) ([]*model.TimeConflict, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	return r.tx.store.conflicts[studentID], nil
+}
+
+func (r *fakeTakesRepository) Create(ctx context.Context, takes *model.Takes) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	sectionTakes := r.tx.store.takes[takes.Key().String()]
+	if _, ok := sectionTakes[takes.StudentID]; ok {
+		return repository.ErrDuplicate
+	}
+	sectionTakes[takes.StudentID] = takes
+	r.tx.undo = append(r.tx.undo, func() { delete(sectionTakes, takes.StudentID) })
+	return nil
+}
+
+func (r *fakeTakesRepository) Delete(ctx context.Context, studentID string, key model.SectionKey) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	sectionTakes := r.tx.store.takes[key.String()]
+	takes, ok := sectionTakes[studentID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	delete(sectionTakes, studentID)
+	r.tx.undo = append(r.tx.undo, func() { sectionTakes[studentID] = takes })
+	return nil
+}
+
+func (r *fakeTakesRepository) UpdateGrade(ctx context.Context, studentID string, key model.SectionKey, grade string) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	takes, ok := r.tx.store.takes[key.String()][studentID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	oldGrade := takes.Grade
+	takes.Grade = grade
+	r.tx.undo = append(r.tx.undo, func() { takes.Grade = oldGrade })
+	return nil
+}
+
+func (r *fakeTakesRepository) GetTermCredits(ctx context.Context, studentID string, semester string, year int) (float64, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	var credits float64
+	for _, sectionTakes := range r.tx.store.takes {
+		if takes, ok := sectionTakes[studentID]; ok && takes.Semester == semester && takes.Year == year && takes.Grade != model.GradeWithdrawn {
+			if course, ok := r.tx.store.courses[takes.CourseID]; ok {
+				credits += course.Credits
+			}
+		}
+	}
+	return credits, nil
+}
+
+type fakeTermRepository struct {
+	repository.TermRepository
+	store *enrollmentStore
+}
+
+func (r *fakeTermRepository) FindByTerm(ctx context.Context, semester string, year int) (*model.Term, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if term, ok := r.store.terms[fmt.Sprintf("%s%d", semester, year)]; ok {
+		return term, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+type fakeTimeTicketRepository struct {
+	repository.TimeTicketRepository
+	store *enrollmentStore
+}
+
+func (r *fakeTimeTicketRepository) FindByStudentAndTerm(ctx context.Context, studentID string, semester string, year int) (*model.TimeTicket, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if ticket, ok := r.store.tickets[studentID]; ok && ticket.Semester == semester && ticket.Year == year {
+		return ticket, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+type fakeOverloadRepository struct {
+	repository.OverloadRepository
+	store *enrollmentStore
+}
+
+func (r *fakeOverloadRepository) FindByStudentAndTerm(ctx context.Context, studentID string, semester string, year int) ([]*model.OverloadRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var reqs []*model.OverloadRequest
+	for _, req := range r.store.overloads {
+		if req.StudentID == studentID && req.Semester == semester && req.Year == year {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs, nil
+}
+
+type fakeCartRepository struct {
+	repository.CartRepository
+	tx *fakeTx
+}
+
+func (r *fakeCartRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.CartItem, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	return append([]*model.CartItem(nil), r.tx.store.carts[studentID]...), nil
+}
+
+func (r *fakeCartRepository) Delete(ctx context.Context, studentID string, key model.SectionKey) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	items := r.tx.store.carts[studentID]
+	for i, item := range items {
+		if item.Key() == key {
+			r.tx.store.carts[studentID] = append(append([]*model.CartItem(nil), items[:i]...), items[i+1:]...)
+			r.tx.undo = append(r.tx.undo, func() { r.tx.store.carts[studentID] = items })
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+type fakeOverrideRepository struct {
+	repository.OverrideRepository
+	tx *fakeTx
+}
+
+func (r *fakeOverrideRepository) FindActiveByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) ([]*model.RegistrationOverride, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	var overrides []*model.RegistrationOverride
+	for _, override := range r.tx.store.overrides {
+		if override.StudentID == studentID && override.Key() == key && override.Status == model.OverrideStatusActive && override.Code == "" {
+			overrides = append(overrides, override)
+		}
+	}
+	return overrides, nil
+}
+
+func (r *fakeOverrideRepository) FindByCode(ctx context.Context, code string) (*model.RegistrationOverride, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	for _, override := range r.tx.store.overrides {
+		if override.Code == code {
+			return override, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeOverrideRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	for _, override := range r.tx.store.overrides {
+		if override.ID == id && override.Status == model.OverrideStatusActive {
+			override.Status = model.OverrideStatusUsed
+			override.UsedAt = &usedAt
+			r.tx.undo = append(r.tx.undo, func() {
+				override.Status = model.OverrideStatusActive
+				override.UsedAt = nil
+			})
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// fakePrereqRepository 对noPrereqs中的学生报告先修课程不满足，其余学生都满足
+type fakePrereqRepository struct {
+	repository.PrereqRepository
+	store *enrollmentStore
+}
+
+func (r *fakePrereqRepository) EvaluatePrereqs(ctx context.Context, studentID string, courseID string, semester string, year int) (*model.PrereqCheckResult, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.noPrereqs[studentID] {
+		return &model.PrereqCheckResult{FailedClause: "CS100", CourseIDs: []string{"CS100"}}, nil
+	}
+	return &model.PrereqCheckResult{Satisfied: true}, nil
+}
+
+// fakeWaitlistRepository 按入队顺序保存候补记录，Position 只对等待中的记录计算
+type fakeWaitlistRepository struct {
+	repository.WaitlistRepository
+	tx *fakeTx
+}
+
+// position 返回等待中的记录在所属课程段队列中的位置，调用方需持有store.mu
+func (r *fakeWaitlistRepository) position(entry *model.WaitlistEntry) int {
+	position := 0
+	for _, other := range r.tx.store.waitlist {
+		if other.Key() == entry.Key() && other.Status == model.EnrollmentStatusWaiting {
+			position++
+		}
+		if other == entry {
+			return position
+		}
+	}
+	return 0
+}
+
+func (r *fakeWaitlistRepository) withPosition(entry *model.WaitlistEntry) *model.WaitlistEntry {
+	copied := *entry
+	copied.Position = r.position(entry)
+	return &copied
+}
+
+func (r *fakeWaitlistRepository) FindByStudentID(ctx context.Context, studentID string) ([]*model.WaitlistEntry, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	var entries []*model.WaitlistEntry
+	for _, entry := range r.tx.store.waitlist {
+		if entry.StudentID == studentID && entry.Status == model.EnrollmentStatusWaiting {
+			entries = append(entries, r.withPosition(entry))
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeWaitlistRepository) FindBySection(ctx context.Context, key model.SectionKey) ([]*model.WaitlistEntry, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	var entries []*model.WaitlistEntry
+	for _, entry := range r.tx.store.waitlist {
+		if entry.Key() == key && entry.Status == model.EnrollmentStatusWaiting {
+			entries = append(entries, r.withPosition(entry))
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeWaitlistRepository) FindWaitingByStudentAndSection(ctx context.Context, studentID string, key model.SectionKey) (*model.WaitlistEntry, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	for _, entry := range r.tx.store.waitlist {
+		if entry.StudentID == studentID && entry.Key() == key && entry.Status == model.EnrollmentStatusWaiting {
+			return r.withPosition(entry), nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeWaitlistRepository) Create(ctx context.Context, entry *model.WaitlistEntry) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	entries := r.tx.store.waitlist
+	stored := *entry
+	stored.QueueNo = len(entries) + 1
+	r.tx.store.waitlist = append(entries, &stored)
+	r.tx.undo = append(r.tx.undo, func() { r.tx.store.waitlist = entries })
+	return nil
+}
+
+func (r *fakeWaitlistRepository) UpdateStatus(ctx context.Context, id string, status model.EnrollmentStatus) error {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	for _, entry := range r.tx.store.waitlist {
+		if entry.ID == id {
+			oldStatus := entry.Status
+			entry.Status = status
+			r.tx.undo = append(r.tx.undo, func() { entry.Status = oldStatus })
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *fakeWaitlistRepository) GetPosition(ctx context.Context, id string) (int, error) {
+	r.tx.store.mu.Lock()
+	defer r.tx.store.mu.Unlock()
+	for _, entry := range r.tx.store.waitlist {
+		if entry.ID == id {
+			return r.position(entry), nil
+		}
+	}
+	return 0, repository.ErrNotFound
+}
+
+// waitlistStatus 返回学生在课程段上最近一条候补记录的状态
+func (s *enrollmentStore) waitlistStatus(studentID string, key model.SectionKey) model.EnrollmentStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var status model.EnrollmentStatus
+	for _, entry := range s.waitlist {
+		if entry.StudentID == studentID && entry.Key() == key {
+			status = entry.Status
+		}
+	}
+	return status
+}
+
+func newTestEnrollmentService(store *enrollmentStore) EnrollmentService {
+	sectionRepo := &fakeSectionRepository{tx: &fakeTx{store: store}}
+	waitlistRepo := &fakeWaitlistRepository{tx: &fakeTx{store: store}}
+	return NewEnrollmentService(nil, nil, sectionRepo, nil, nil, nil, nil, waitlistRepo, nil, nil, nil, nil, &fakeUnitOfWork{store: store}, nil)
+}
+
+func TestEnrollmentService_RegisterForCourse_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	const (
+		capacity = 5
+		students = 50
+	)
+
+	store := newEnrollmentStore()
+	store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, capacity)
+	for i := 0; i < students; i++ {
+		id := fmt.Sprintf("S%03d", i)
+		store.students[id] = &model.Student{ID: id}
+	}
+
+	service := newTestEnrollmentService(store)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded, full := 0, 0
+	start := make(chan struct{})
+	for i := 0; i < students; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			<-start
+			err := service.RegisterForCourse(ctx, id, store.key("1"))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, ErrSectionFull):
+				full++
+			default:
+				t.Errorf("RegisterForCourse(%s) unexpected error: %v", id, err)
+			}
+		}(fmt.Sprintf("S%03d", i))
+	}
+	close(start)
+	wg.Wait()
+
+	if got := store.enrollmentCount(store.key("1")); got != capacity {
+		t.Errorf("Expected %d enrollments, got %d", capacity, got)
+	}
+	if succeeded != capacity {
+		t.Errorf("Expected %d successful registrations, got %d", capacity, succeeded)
+	}
+	if full != students-capacity {
+		t.Errorf("Expected %d section full errors, got %d", students-capacity, full)
+	}
+}
+
+func TestEnrollmentService_RegisterAndDrop_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	const (
+		capacity = 3
+		students = 30
+		rounds   = 20
+	)
+
+	store := newEnrollmentStore()
+	store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, capacity)
+	for i := 0; i < students; i++ {
+		id := fmt.Sprintf("S%03d", i)
+		store.students[id] = &model.Student{ID: id}
+	}
+
+	service := newTestEnrollmentService(store)
+
+	// 每个学生反复选课、退课，任何时刻选课人数都不能超过容量
+	var wg sync.WaitGroup
+	var overEnrolled sync.Once
+	for i := 0; i < students; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				if err := service.RegisterForCourse(ctx, id, store.key("1")); err == nil {
+					if count := store.enrollmentCount(store.key("1")); count > capacity {
+						overEnrolled.Do(func() { t.Errorf("Section over-enrolled: %d > %d", count, capacity) })
+					}
+					if err := service.DropCourse(ctx, id, store.key("1")); err != nil {
+						t.Errorf("DropCourse(%s) unexpected error: %v", id, err)
+					}
+				} else if !errors.Is(err, ErrSectionFull) {
+					t.Errorf("RegisterForCourse(%s) unexpected error: %v", id, err)
+				}
+			}
+		}(fmt.Sprintf("S%03d", i))
+	}
+	wg.Wait()
+
+	if got := store.enrollmentCount(store.key("1")); got != 0 {
+		t.Errorf("Expected all students dropped, got %d enrollments", got)
+	}
+}
+
+func TestEnrollmentService_TermDeadlines(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, time.September, d, 12, 0, 0, 0, time.UTC) }
+	term := &model.Term{
+		Semester:           "Fall",
+		Year:               2024,
+		RegistrationOpen:   day(1),
+		RegistrationClose:  day(10),
+		AddDropDeadline:    day(15),
+		WithdrawalDeadline: day(25),
+	}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		registerErr error
+		dropErr     error
+		wantGrade   string // 退课后选课记录的成绩，"-" 表示记录已删除
+	}{
+		{"before registration opens", day(0), ErrRegistrationClosed, nil, "-"},
+		{"during registration", day(5), nil, nil, "-"},
+		{"after registration closes, before add/drop deadline", day(12), ErrRegistrationClosed, nil, "-"},
+		{"after add/drop deadline", day(20), ErrRegistrationClosed, nil, model.GradeWithdrawn},
+		{"after withdrawal deadline", day(28), ErrRegistrationClosed, ErrWithdrawalDeadlinePassed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newEnrollmentStore()
+			store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, 10)
+			store.terms["Fall2024"] = term
+			store.students["S001"] = &model.Student{ID: "S001"}
+			store.students["S002"] = &model.Student{ID: "S002"}
+			store.takes[store.key("1").String()]["S002"] = &model.Takes{StudentID: "S002", CourseID: "CS101", SectionID: "1", Semester: "Fall", Year: 2024}
+
+			svc := newTestEnrollmentService(store)
+			svc.(*DefaultEnrollmentService).now = func() time.Time { return tt.now }
+
+			if err := svc.RegisterForCourse(ctx, "S001", store.key("1")); !errors.Is(err, tt.registerErr) {
+				t.Errorf("RegisterForCourse() error = %v, want %v", err, tt.registerErr)
+			}
+
+			if err := svc.DropCourse(ctx, "S002", store.key("1")); !errors.Is(err, tt.dropErr) {
+				t.Errorf("DropCourse() error = %v, want %v", err, tt.dropErr)
+			}
+			takes, ok := store.takes[store.key("1").String()]["S002"]
+			switch {
+			case tt.wantGrade == "-" && ok:
+				t.Errorf("Expected takes record to be deleted, got grade %q", takes.Grade)
+			case tt.wantGrade != "-" && !ok:
+				t.Errorf("Expected takes record to be kept with grade %q", tt.wantGrade)
+			case tt.wantGrade != "-" && takes.Grade != tt.wantGrade:
+				t.Errorf("Expected grade %q, got %q", tt.wantGrade, takes.Grade)
+			}
+
+			// 已退选的记录不能再退，没有选过的课程段也不能退
+			if tt.wantGrade == model.GradeWithdrawn {
+				if err := svc.DropCourse(ctx, "S002", store.key("1")); !errors.Is(err, ErrAlreadyWithdrawn) {
+					t.Errorf("DropCourse() twice error = %v, want %v", err, ErrAlreadyWithdrawn)
+				}
+			}
+			if tt.wantGrade == "-" && tt.dropErr == nil {
+				if err := svc.DropCourse(ctx, "S002", store.key("1")); !errors.Is(err, ErrEnrollmentNotFound) {
+					t.Errorf("DropCourse() after delete error = %v, want %v", err, ErrEnrollmentNotFound)
+				}
+			}
+		})
+	}
+}
+
+func TestEnrollmentService_TimeTicket(t *testing.T) {
+	ctx := context.Background()
+	ticketTime := time.Date(2024, time.September, 3, 9, 0, 0, 0, time.UTC)
+
+	store := newEnrollmentStore()
+	store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, 10)
+	store.students["S001"] = &model.Student{ID: "S001"}
+	store.students["S002"] = &model.Student{ID: "S002"}
+	store.tickets["S001"] = &model.TimeTicket{StudentID: "S001", Semester: "Fall", Year: 2024, Tier: 2, StartTime: ticketTime}
+
+	svc := newTestEnrollmentService(store)
+	svc.(*DefaultEnrollmentService).now = func() time.Time { return ticketTime.Add(-time.Minute) }
+
+	if err := svc.RegisterForCourse(ctx, "S001", store.key("1")); !errors.Is(err, ErrBeforeTimeTicket) {
+		t.Errorf("Expected ErrBeforeTimeTicket before ticket time, got %v", err)
+	}
+	// 没有时间票的学生不受限制
+	if err := svc.RegisterForCourse(ctx, "S002", store.key("1")); err != nil {
+		t.Errorf("Expected student without ticket to register, got %v", err)
+	}
+
+	svc.(*DefaultEnrollmentService).now = func() time.Time { return ticketTime }
+	if err := svc.RegisterForCourse(ctx, "S001", store.key("1")); err != nil {
+		t.Errorf("Expected registration at ticket time to succeed, got %v", err)
+	}
+}
+
+func TestEnrollmentService_CreditLimit(t *testing.T) {
+	ctx := context.Background()
+	store := newEnrollmentStore()
+	store.terms["Fall2024"] = &model.Term{
+		Semester:           "Fall",
+		Year:               2024,
+		RegistrationClose:  time.Now().Add(time.Hour),
+		AddDropDeadline:    time.Now().Add(time.Hour),
+		WithdrawalDeadline: time.Now().Add(time.Hour),
+		MaxCredits:         8,
+	}
+	for _, id := range []string{"CS101", "CS102", "MATH101"} {
+		store.courses[id] = &model.Course{ID: id, Credits: 4}
+		store.addSection(&model.Section{ID: id + "-1", CourseID: id, Semester: "Fall", Year: 2024}, 10)
+	}
+	store.students["S001"] = &model.Student{ID: "S001"}
+
+	svc := newTestEnrollmentService(store)
+
+	for _, sectionID := range []string{"CS101-1", "CS102-1"} {
+		if err := svc.RegisterForCourse(ctx, "S001", store.key(sectionID)); err != nil {
+			t.Fatalf("RegisterForCourse(%s) error = %v", sectionID, err)
+		}
+	}
+	if err := svc.RegisterForCourse(ctx, "S001", store.key("MATH101-1")); !errors.Is(err, ErrCreditLimitExceeded) {
+		t.Fatalf("Expected ErrCreditLimitExceeded, got %v", err)
+	}
+
+	// 待审批和被拒绝的申请不提高上限，批准后才生效
+	store.overloads = append(store.overloads,
+		&model.OverloadRequest{StudentID: "S001", Semester: "Fall", Year: 2024, RequestedCredits: 12, Status: model.OverloadStatusPending},
+		&model.OverloadRequest{StudentID: "S001", Semester: "Fall", Year: 2024, RequestedCredits: 16, Status: model.OverloadStatusDenied},
+	)
+	if err := svc.RegisterForCourse(ctx, "S001", store.key("MATH101-1")); !errors.Is(err, ErrCreditLimitExceeded) {
+		t.Fatalf("Expected ErrCreditLimitExceeded with unapproved overloads, got %v", err)
+	}
+
+	store.overloads[0].Status = model.OverloadStatusApproved
+	if err := svc.RegisterForCourse(ctx, "S001", store.key("MATH101-1")); err != nil {
+		t.Errorf("Expected registration with approved overload to succeed, got %v", err)
+	}
+}
+
+func TestEnrollmentService_CheckEligibility(t *testing.T) {
+	ctx := context.Background()
+	store := newEnrollmentStore()
+	store.terms["Fall2024"] = &model.Term{
+		Semester:           "Fall",
+		Year:               2024,
+		RegistrationClose:  time.Now().Add(time.Hour),
+		AddDropDeadline:    time.Now().Add(time.Hour),
+		WithdrawalDeadline: time.Now().Add(time.Hour),
+		MaxCredits:         4,
+	}
+	for _, id := range []string{"CS101", "CS102"} {
+		store.courses[id] = &model.Course{ID: id, Credits: 4}
+	}
+	store.addSection(&model.Section{ID: "CS101-1", CourseID: "CS101", Semester: "Fall", Year: 2024}, 10)
+	store.addSection(&model.Section{ID: "CS102-1", CourseID: "CS102", Semester: "Fall", Year: 2024}, 1)
+	store.students["S001"] = &model.Student{ID: "S001"}
+	store.students["S002"] = &model.Student{ID: "S002"}
+
+	svc := newTestEnrollmentService(store)
+	if err := svc.RegisterForCourse(ctx, "S002", store.key("CS102-1")); err != nil {
+		t.Fatalf("RegisterForCourse error = %v", err)
+	}
+	if err := svc.RegisterForCourse(ctx, "S001", store.key("CS101-1")); err != nil {
+		t.Fatalf("RegisterForCourse error = %v", err)
+	}
+
+	// 试运行报告所有未通过的检查，而不是只报告第一个
+	result, err := svc.CheckEligibility(ctx, "S001", store.key("CS102-1"))
+	if err != nil {
+		t.Fatalf("CheckEligibility error = %v", err)
+	}
+	if result.Eligible {
+		t.Fatalf("Expected student to be ineligible, got %+v", result)
+	}
+	for _, code := range []model.EligibilityReasonCode{model.ReasonCreditLimitExceeded, model.ReasonSectionFull} {
+		if !result.HasReason(code) {
+			t.Errorf("Expected reason %s, got %+v", code, result.Reasons)
+		}
+	}
+	if store.enrollmentCount(store.key("CS102-1")) != 1 {
+		t.Errorf("Expected dry run not to write, got %d enrollments", store.enrollmentCount(store.key("CS102-1")))
+	}
+
+	// 选课返回同样的结构化结果，并且仍然可以用哨兵错误判断
+	err = svc.RegisterForCourse(ctx, "S001", store.key("CS102-1"))
+	var regErr *RegistrationError
+	if !errors.As(err, &regErr) {
+		t.Fatalf("Expected *RegistrationError, got %v", err)
+	}
+	if len(regErr.Result.Reasons) != len(result.Reasons) {
+		t.Errorf("Expected %d reasons, got %+v", len(result.Reasons), regErr.Result.Reasons)
+	}
+	if !errors.Is(err, ErrSectionFull) || !errors.Is(err, ErrCreditLimitExceeded) {
+		t.Errorf("Expected error to match ErrSectionFull and ErrCreditLimitExceeded, got %v", err)
+	}
+
+	result, err = svc.CheckEligibility(ctx, "S001", model.SectionKey{CourseID: "CS101", SecID: "missing", Semester: "Fall", Year: 2024})
+	if err != nil {
+		t.Fatalf("CheckEligibility error = %v", err)
+	}
+	if !result.HasReason(model.ReasonSectionNotFound) {
+		t.Errorf("Expected SECTION_NOT_FOUND, got %+v", result.Reasons)
+	}
+}
+
+func TestEnrollmentService_Cart(t *testing.T) {
+	ctx := context.Background()
+	newStore := func() *enrollmentStore {
+		store := newEnrollmentStore()
+		store.terms["Fall2024"] = &model.Term{
+			Semester:           "Fall",
+			Year:               2024,
+			RegistrationClose:  time.Now().Add(time.Hour),
+			AddDropDeadline:    time.Now().Add(time.Hour),
+			WithdrawalDeadline: time.Now().Add(time.Hour),
+			MaxCredits:         8,
+		}
+		for _, id := range []string{"CS101", "CS102", "MATH101"} {
+			store.courses[id] = &model.Course{ID: id, Credits: 4}
+			store.addSection(&model.Section{ID: id + "-1", CourseID: id, Semester: "Fall", Year: 2024}, 10)
+			store.carts["S001"] = append(store.carts["S001"], &model.CartItem{StudentID: "S001", CourseID: id, SectionID: id + "-1", Semester: "Fall", Year: 2024})
+		}
+		store.students["S001"] = &model.Student{ID: "S001"}
+		return store
+	}
+	registered := func(store *enrollmentStore) int {
+		count := 0
+		for _, id := range []string{"CS101-1", "CS102-1", "MATH101-1"} {
+			count += store.enrollmentCount(store.key(id))
+		}
+		return count
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		store := newStore()
+		result, err := newTestEnrollmentService(store).ValidateCart(ctx, "S001")
+		if err != nil {
+			t.Fatalf("ValidateCart error = %v", err)
+		}
+		// 前两门课已经占满8学分，第三门课在购物车内部超过学分上限
+		if result.Valid || len(result.Items) != 3 {
+			t.Fatalf("Expected invalid cart with 3 items, got %+v", result)
+		}
+		if !result.Items[0].Eligibility.Eligible || !result.Items[1].Eligibility.Eligible {
+			t.Errorf("Expected first two items to be eligible")
+		}
+		if !result.Items[2].Eligibility.HasReason(model.ReasonCreditLimitExceeded) {
+			t.Errorf("Expected CREDIT_LIMIT_EXCEEDED for third item, got %+v", result.Items[2].Eligibility.Reasons)
+		}
+		if n := registered(store); n != 0 {
+			t.Errorf("Expected validation not to write, got %d enrollments", n)
+		}
+	})
+
+	t.Run("atomic submit rolls back", func(t *testing.T) {
+		store := newStore()
+		result, err := newTestEnrollmentService(store).SubmitCart(ctx, "S001", true)
+		if err != nil {
+			t.Fatalf("SubmitCart error = %v", err)
+		}
+		if result.Valid {
+			t.Fatalf("Expected atomic submission to fail")
+		}
+		for _, item := range result.Items {
+			if item.Registered {
+				t.Errorf("Expected %s not to be registered", item.Section)
+			}
+		}
+		if n := registered(store); n != 0 {
+			t.Errorf("Expected no enrollments after rollback, got %d", n)
+		}
+		if len(store.carts["S001"]) != 3 {
+			t.Errorf("Expected cart to be unchanged, got %d items", len(store.carts["S001"]))
+		}
+	})
+
+	t.Run("partial submit", func(t *testing.T) {
+		store := newStore()
+		result, err := newTestEnrollmentService(store).SubmitCart(ctx, "S001", false)
+		if err != nil {
+			t.Fatalf("SubmitCart error = %v", err)
+		}
+		if !result.Items[0].Registered || !result.Items[1].Registered || result.Items[2].Registered {
+			t.Errorf("Expected first two items to be registered, got %+v", result.Items)
+		}
+		if n := registered(store); n != 2 {
+			t.Errorf("Expected 2 enrollments, got %d", n)
+		}
+		if cart := store.carts["S001"]; len(cart) != 1 || cart[0].SectionID != "MATH101-1" {
+			t.Errorf("Expected only MATH101-1 to remain in cart, got %+v", cart)
+		}
+	})
+}
+
+func TestEnrollmentService_Overrides(t *testing.T) {
+	ctx := context.Background()
+	store := newEnrollmentStore()
+	store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, 1)
+	store.addSection(&model.Section{ID: "2", CourseID: "CS102", Semester: "Fall", Year: 2024}, 10)
+	for _, id := range []string{"S001", "S002", "S003", "S004"} {
+		store.students[id] = &model.Student{ID: id}
+	}
+	prereqOnly := &model.RegistrationOverride{ID: "O1", StudentID: "S002", CourseID: "CS101", SectionID: "1", Semester: "Fall", Year: 2024, Waives: []model.EligibilityReasonCode{model.ReasonPrereqsNotSatisfied}, Status: model.OverrideStatusActive}
+	capacity := &model.RegistrationOverride{ID: "O2", StudentID: "S002", CourseID: "CS101", SectionID: "1", Semester: "Fall", Year: 2024, Waives: []model.EligibilityReasonCode{model.ReasonSectionFull}, Status: model.OverrideStatusActive}
+	code := &model.RegistrationOverride{ID: "O3", StudentID: "S003", CourseID: "CS101", SectionID: "1", Semester: "Fall", Year: 2024, Waives: []model.EligibilityReasonCode{model.ReasonSectionFull}, Code: "PERMIT01", Status: model.OverrideStatusActive}
+	store.overrides = []*model.RegistrationOverride{prereqOnly, capacity, code}
+
+	svc := newTestEnrollmentService(store)
+	if err := svc.RegisterForCourse(ctx, "S001", store.key("1")); err != nil {
+		t.Fatalf("RegisterForCourse error = %v", err)
+	}
+
+	// 没有特许的学生仍然被容量限制
+	if err := svc.RegisterForCourse(ctx, "S004", store.key("1")); !errors.Is(err, ErrSectionFull) {
+		t.Fatalf("Expected ErrSectionFull, got %v", err)
+	}
+
+	// 试运行展示被豁免的检查，但不消耗特许
+	result, err := svc.CheckEligibility(ctx, "S002", store.key("1"))
+	if err != nil {
+		t.Fatalf("CheckEligibility error = %v", err)
+	}
+	if !result.Eligible || len(result.Waived) != 1 || result.Waived[0].OverrideID != "O2" {
+		t.Fatalf("Expected capacity to be waived by O2, got %+v", result)
+	}
+	if capacity.Status != model.OverrideStatusActive {
+		t.Fatalf("Expected dry run not to consume override")
+	}
+
+	// 直接生效的特许只豁免它声明的检查，并且只有用到的特许会被消耗
+	if err := svc.RegisterForCourse(ctx, "S002", store.key("1")); err != nil {
+		t.Fatalf("Expected override to admit student into full section, got %v", err)
+	}
+	if capacity.Status != model.OverrideStatusUsed || capacity.UsedAt == nil {
+		t.Errorf("Expected capacity override to be used, got %s", capacity.Status)
+	}
+	if prereqOnly.Status != model.OverrideStatusActive {
+		t.Errorf("Expected unused prereq override to stay active, got %s", prereqOnly.Status)
+	}
+
+	// 许可码只对签发的学生和课程段有效，并且只能使用一次
+	if err := svc.RegisterWithPermissionCode(ctx, "S004", store.key("1"), "PERMIT01"); !errors.Is(err, ErrInvalidPermissionCode) {
+		t.Errorf("Expected ErrInvalidPermissionCode for another student, got %v", err)
+	}
+	if err := svc.RegisterWithPermissionCode(ctx, "S003", store.key("1"), "PERMIT01"); err != nil {
+		t.Fatalf("Expected permission code to admit student, got %v", err)
+	}
+	if code.Status != model.OverrideStatusUsed {
+		t.Errorf("Expected permission code to be used, got %s", code.Status)
+	}
+	if err := svc.DropCourse(ctx, "S003", store.key("1")); err != nil {
+		t.Fatalf("DropCourse error = %v", err)
+	}
+	if err := svc.RegisterWithPermissionCode(ctx, "S003", store.key("2"), "PERMIT01"); !errors.Is(err, ErrInvalidPermissionCode) {
+		t.Errorf("Expected used permission code to be rejected, got %v", err)
+	}
+	if got := store.enrollmentCount(store.key("1")); got != 2 {
+		t.Errorf("Expected 2 enrollments in section 1, got %d", got)
+	}
+}
+
+func TestEnrollmentService_SectionKey(t *testing.T) {
+	ctx := context.Background()
+	store := newEnrollmentStore()
+	// 两门课程都有sec_id为1的课程段
+	cs101 := model.SectionKey{CourseID: "CS101", SecID: "1", Semester: "Fall", Year: 2024}
+	cs102 := model.SectionKey{CourseID: "CS102", SecID: "1", Semester: "Fall", Year: 2024}
+	store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, 1)
+	store.addSection(&model.Section{ID: "1", CourseID: "CS102", Semester: "Fall", Year: 2024}, 1)
+	store.students["S001"] = &model.Student{ID: "S001"}
+	store.students["S002"] = &model.Student{ID: "S002"}
+
+	svc := newTestEnrollmentService(store)
+	if err := svc.RegisterForCourse(ctx, "S001", cs101); err != nil {
+		t.Fatalf("RegisterForCourse(%s) error = %v", cs101, err)
+	}
+	if err := svc.RegisterForCourse(ctx, "S002", cs102); err != nil {
+		t.Fatalf("Expected %s to be independent of %s, got %v", cs102, cs101, err)
+	}
+	if err := svc.DropCourse(ctx, "S001", cs102); err == nil {
+		t.Errorf("Expected dropping a section the student is not in to fail")
+	}
+	if store.enrollmentCount(cs101) != 1 || store.enrollmentCount(cs102) != 1 {
+		t.Errorf("Expected one enrollment in each section")
+	}
+
+	tests := []struct {
+		name    string
+		ref     model.SectionRef
+		want    model.SectionKey
+		wantErr error
+	}{
+		{"full key", model.SectionRef{SectionKey: cs102}, cs102, nil},
+		{"canonical section_id", model.SectionRef{SectionID: cs101.String()}, cs101, nil},
+		{"legacy section_id narrowed by course", model.SectionRef{SectionKey: model.SectionKey{CourseID: "CS102"}, SectionID: "1"}, cs102, nil},
+		{"ambiguous legacy section_id", model.SectionRef{SectionID: "1"}, model.SectionKey{}, ErrAmbiguousSection},
+		{"unknown legacy section_id", model.SectionRef{SectionID: "9"}, model.SectionKey{}, ErrSectionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.ResolveSection(ctx, &tt.ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolveSection() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSection() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnrollmentService_Waitlist(t *testing.T) {
+	ctx := context.Background()
+	store := newEnrollmentStore()
+	store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, 1)
+	store.addSection(&model.Section{ID: "2", CourseID: "CS102", Semester: "Fall", Year: 2024}, 10)
+	for _, id := range []string{"S000", "S001", "S002", "S003", "S004"} {
+		store.students[id] = &model.Student{ID: id}
+	}
+	key := store.key("1")
+
+	svc := newTestEnrollmentService(store)
+	if err := svc.RegisterForCourse(ctx, "S000", key); err != nil {
+		t.Fatalf("RegisterForCourse error = %v", err)
+	}
+
+	// 入队时返回当前排队位置
+	for i, id := range []string{"S001", "S002", "S003", "S004"} {
+		entry, err := svc.JoinWaitlist(ctx, id, key)
+		if err != nil {
+			t.Fatalf("JoinWaitlist(%s) error = %v", id, err)
+		}
+		if entry.Position != i+1 {
+			t.Errorf("JoinWaitlist(%s) position = %d, want %d", id, entry.Position, i+1)
+		}
+	}
+
+	if _, err := svc.JoinWaitlist(ctx, "S001", key); !errors.Is(err, ErrAlreadyWaitlisted) {
+		t.Errorf("Duplicate JoinWaitlist error = %v, want %v", err, ErrAlreadyWaitlisted)
+	}
+	if _, err := svc.JoinWaitlist(ctx, "S000", key); !errors.Is(err, ErrAlreadyRegistered) {
+		t.Errorf("JoinWaitlist by enrolled student error = %v, want %v", err, ErrAlreadyRegistered)
+	}
+	if _, err := svc.JoinWaitlist(ctx, "S001", store.key("2")); !errors.Is(err, ErrSectionNotFull) {
+		t.Errorf("JoinWaitlist on open section error = %v, want %v", err, ErrSectionNotFull)
+	}
+
+	// 退出队列后排在后面的学生位置前移，重复退出报告不在队列中
+	if err := svc.LeaveWaitlist(ctx, "S002", key); err != nil {
+		t.Fatalf("LeaveWaitlist error = %v", err)
+	}
+	if err := svc.LeaveWaitlist(ctx, "S002", key); !errors.Is(err, ErrNotWaitlisted) {
+		t.Errorf("Second LeaveWaitlist error = %v, want %v", err, ErrNotWaitlisted)
+	}
+	entries, err := svc.GetWaitlistPositions(ctx, "S003")
+	if err != nil {
+		t.Fatalf("GetWaitlistPositions error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Position != 2 {
+		t.Fatalf("Expected S003 at position 2, got %+v", entries)
+	}
+
+	// 退课后按顺序转正，跳过此时已不满足先修课程或有时间冲突的学生
+	store.noPrereqs["S001"] = true
+	store.conflicts["S003"] = []*model.TimeConflict{{Section: store.key("2"), Day: "M", StartTime: "09:00", EndTime: "09:50"}}
+	if err := svc.DropCourse(ctx, "S000", key); err != nil {
+		t.Fatalf("DropCourse error = %v", err)
+	}
+
+	wantStatus := map[string]model.EnrollmentStatus{
+		"S001": model.EnrollmentStatusRejected,
+		"S002": model.EnrollmentStatusDropped,
+		"S003": model.EnrollmentStatusRejected,
+		"S004": model.EnrollmentStatusActive,
+	}
+	for id, want := range wantStatus {
+		if got := store.waitlistStatus(id, key); got != want {
+			t.Errorf("Waitlist status of %s = %q, want %q", id, got, want)
+		}
+	}
+	if _, ok := store.takes[key.String()]["S004"]; !ok {
+		t.Errorf("Expected S004 to be enrolled after promotion")
+	}
+	if got := store.enrollmentCount(key); got != 1 {
+		t.Errorf("Expected 1 enrollment after promotion, got %d", got)
+	}
+
+	// 转正和拒绝都以系统身份记录审计日志
+	var audited []string
+	for _, entry := range store.audit {
+		if entry.ActorID != model.SystemActor.UserID {
+			t.Errorf("Expected system actor, got %s", entry.ActorID)
+		}
+		audited = append(audited, entry.Action+" "+entry.EntityType)
+	}
+	wantAudit := []string{
+		model.AuditActionUpdate + " " + model.AuditEntityWaitlist,
+		model.AuditActionUpdate + " " + model.AuditEntityWaitlist,
+		model.AuditActionCreate + " " + model.AuditEntityEnrollment,
+		model.AuditActionUpdate + " " + model.AuditEntityWaitlist,
+	}
+	if strings.Join(audited, ",") != strings.Join(wantAudit, ",") {
+		t.Errorf("Expected audit entries %v, got %v", wantAudit, audited)
+	}
+}
+
+func TestEnrollmentService_WaitlistPromotionWindow(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, time.September, d, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name         string
+		term         *model.Term
+		now          time.Time
+		wantGrade    string // 退课后S000选课记录的成绩，"-" 表示记录已删除
+		wantPromoted bool
+	}{
+		{
+			name:         "drop while registration is open",
+			term:         &model.Term{RegistrationClose: day(10), AddDropDeadline: day(15), WithdrawalDeadline: day(25)},
+			now:          day(5),
+			wantGrade:    "-",
+			wantPromoted: true,
+		},
+		{
+			name:         "drop after registration closes",
+			term:         &model.Term{RegistrationClose: day(10), AddDropDeadline: day(15), WithdrawalDeadline: day(25)},
+			now:          day(12),
+			wantGrade:    "-",
+			wantPromoted: false,
+		},
+		{
+			name:         "withdrawal while registration is open",
+			term:         &model.Term{RegistrationClose: day(30), AddDropDeadline: day(15), WithdrawalDeadline: day(25)},
+			now:          day(20),
+			wantGrade:    model.GradeWithdrawn,
+			wantPromoted: true,
+		},
+		{
+			name:         "withdrawal after registration closes",
+			term:         &model.Term{RegistrationClose: day(10), AddDropDeadline: day(15), WithdrawalDeadline: day(25)},
+			now:          day(20),
+			wantGrade:    model.GradeWithdrawn,
+			wantPromoted: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newEnrollmentStore()
+			store.addSection(&model.Section{ID: "1", CourseID: "CS101", Semester: "Fall", Year: 2024}, 1)
+			key := store.key("1")
+			term := *tt.term
+			term.Semester, term.Year, term.RegistrationOpen = "Fall", 2024, day(1)
+			store.terms["Fall2024"] = &term
+			store.students["S000"] = &model.Student{ID: "S000"}
+			store.students["S001"] = &model.Student{ID: "S001"}
+			store.takes[key.String()]["S000"] = &model.Takes{StudentID: "S000", CourseID: "CS101", SectionID: "1", Semester: "Fall", Year: 2024}
+			store.waitlist = append(store.waitlist, &model.WaitlistEntry{ID: "W1", StudentID: "S001", CourseID: "CS101", SectionID: "1", Semester: "Fall", Year: 2024, QueueNo: 1, Status: model.EnrollmentStatusWaiting})
+
+			svc := newTestEnrollmentService(store)
+			svc.(*DefaultEnrollmentService).now = func() time.Time { return tt.now }
+
+			if err := svc.DropCourse(ctx, "S000", key); err != nil {
+				t.Fatalf("DropCourse error = %v", err)
+			}
+			takes, ok := store.takes[key.String()]["S000"]
+			switch {
+			case tt.wantGrade == "-" && ok:
+				t.Errorf("Expected takes record to be deleted, got grade %q", takes.Grade)
+			case tt.wantGrade != "-" && (!ok || takes.Grade != tt.wantGrade):
+				t.Errorf("Expected takes record to be kept with grade %q", tt.wantGrade)
+			}
+
+			_, promoted := store.takes[key.String()]["S001"]
+			if promoted != tt.wantPromoted {
+				t.Errorf("S001 promoted = %v, want %v", promoted, tt.wantPromoted)
+			}
+			wantStatus := model.EnrollmentStatusWaiting
+			if tt.wantPromoted {
+				wantStatus = model.EnrollmentStatusActive
+			}
+			if got := store.waitlistStatus("S001", key); got != wantStatus {
+				t.Errorf("Waitlist status = %q, want %q", got, wantStatus)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -11,24 +12,25 @@ import (
 
 // InstructorService 定义教师服务接口
 type InstructorService interface {
-	GetInstructorByID(id string) (*model.Instructor, error)
-	GetAllInstructors() ([]*model.Instructor, error)
-	CreateInstructor(req *model.InstructorCreateRequest) error
-	UpdateInstructor(id string, req *model.InstructorUpdateRequest) error
-	DeleteInstructor(id string) error
-	ChangePassword(id string, req *model.ChangePasswordRequest) error
-	GetCurrentTeaching(id string, semester string, year int) ([]*model.Teaches, error)
-	AssignGrade(instructorID string, studentID string, sectionID string, grade string) error
-	GetAdvisees(instructorID string) ([]*model.Advisor, error)
-	AssignTeaching(instructorID string, sectionID string, courseID string, semester string, year int) error
-	RemoveTeaching(instructorID string, sectionID string) error
-	GetByID(id string) (*model.Instructor, error)
-	UpdateProfile(id string, name string) error
-	GetTeachingSections(id string) ([]*model.Section, error)
-	GetSectionStudents(instructorID string, sectionID string) ([]*model.Student, error)
-	UpdateGrade(instructorID string, studentID string, sectionID string, grade string) error
-	GetAdviseeInfo(instructorID string, studentID string) (*model.Student, error)
-	Authenticate(id string, password string) (string, error)
+	GetInstructorByID(ctx context.Context, id string) (*model.Instructor, error)
+	GetAllInstructors(ctx context.Context) ([]*model.Instructor, error)
+	CreateInstructor(ctx context.Context, req *model.InstructorCreateRequest) error
+	UpdateInstructor(ctx context.Context, actor model.Actor, id string, req *model.InstructorUpdateRequest) error
+	DeleteInstructor(ctx context.Context, actor model.Actor, id string) error
+	GetCurrentTeaching(ctx context.Context, id string, semester string, year int) ([]*model.Teaches, error)
+	AssignGrade(ctx context.Context, instructorID string, studentID string, key model.SectionKey, grade string) error
+	GetAdvisees(ctx context.Context, instructorID string) ([]*model.Advisor, error)
+	AssignTeaching(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error
+	RemoveTeaching(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error
+	GetByID(ctx context.Context, id string) (*model.Instructor, error)
+	UpdateProfile(ctx context.Context, id string, name string) error
+	GetTeachingSections(ctx context.Context, id string) ([]*model.Section, error)
+	GetSectionStudents(ctx context.Context, instructorID string, key model.SectionKey) ([]*model.Student, error)
+	UpdateGrade(ctx context.Context, instructorID string, studentID string, key model.SectionKey, grade string) error
+	RecordGrade(ctx context.Context, actor model.Actor, studentID string, key model.SectionKey, grade string) error
+	GetAdviseeInfo(ctx context.Context, instructorID string, studentID string) (*model.Student, error)
+	Authenticate(ctx context.Context, id string, password string) (string, error)
+	ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error)
 }
 
 // DefaultInstructorService 实现InstructorService接口
@@ -39,10 +41,13 @@ type DefaultInstructorService struct {
 	advisorRepo    repository.AdvisorRepository
 	sectionRepo    repository.SectionRepository
 	studentRepo    repository.StudentRepository
+	sessions       SessionRevoker
+	policy         utils.PasswordPolicy
+	audit          AuditRecorder
 }
 
 // NewInstructorService 创建教师服务实例
-func NewInstructorService(instructorRepo repository.InstructorRepository, teachesRepo repository.TeachesRepository, takesRepo repository.TakesRepository, advisorRepo repository.AdvisorRepository, sectionRepo repository.SectionRepository, studentRepo repository.StudentRepository) InstructorService {
+func NewInstructorService(instructorRepo repository.InstructorRepository, teachesRepo repository.TeachesRepository, takesRepo repository.TakesRepository, advisorRepo repository.AdvisorRepository, sectionRepo repository.SectionRepository, studentRepo repository.StudentRepository, sessions SessionRevoker, policy utils.PasswordPolicy, audit AuditRecorder) InstructorService {
 	return &DefaultInstructorService{
 		instructorRepo: instructorRepo,
 		teachesRepo:    teachesRepo,
@@ -50,25 +55,28 @@ func NewInstructorService(instructorRepo repository.InstructorRepository, teache
 		advisorRepo:    advisorRepo,
 		sectionRepo:    sectionRepo,
 		studentRepo:    studentRepo,
+		sessions:       sessions,
+		policy:         policy,
+		audit:          auditRecorderOrNop(audit),
 	}
 }
 
 // GetInstructorByID 根据ID获取教师信息
-func (s *DefaultInstructorService) GetInstructorByID(id string) (*model.Instructor, error) {
-	return s.instructorRepo.GetByID(id)
+func (s *DefaultInstructorService) GetInstructorByID(ctx context.Context, id string) (*model.Instructor, error) {
+	return s.instructorRepo.GetByID(ctx, id)
 }
 
 // GetAllInstructors 获取所有教师
-func (s *DefaultInstructorService) GetAllInstructors() ([]*model.Instructor, error) {
-	instructors, _, err := s.instructorRepo.List(1, 1000)
-	if err != nil {
-		return nil, err
-	}
-	return instructors, nil
+func (s *DefaultInstructorService) GetAllInstructors(ctx context.Context) ([]*model.Instructor, error) {
+	return listAll(ctx, s.instructorRepo.List)
 }
 
-// CreateInstructor 创建教师
-func (s *DefaultInstructorService) CreateInstructor(req *model.InstructorCreateRequest) error {
+// CreateInstructor 创建教师，用于教师自行注册，审计日志中的操作者是新账号本身
+func (s *DefaultInstructorService) CreateInstructor(ctx context.Context, req *model.InstructorCreateRequest) error {
+	if err := s.policy.Validate(req.Password); err != nil {
+		return err
+	}
+
 	// 密码加密
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -84,91 +92,101 @@ func (s *DefaultInstructorService) CreateInstructor(req *model.InstructorCreateR
 		Password: hashedPassword,
 	}
 
-	return s.instructorRepo.Create(instructor)
+	if err := s.instructorRepo.Create(ctx, instructor); err != nil {
+		return err
+	}
+	actor := instructorActor(req.ID)
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityInstructor, req.ID, nil, instructor.AuditSnapshot())
 }
 
 // UpdateInstructor 更新教师信息
-func (s *DefaultInstructorService) UpdateInstructor(id string, req *model.InstructorUpdateRequest) error {
+func (s *DefaultInstructorService) UpdateInstructor(ctx context.Context, actor model.Actor, id string, req *model.InstructorUpdateRequest) error {
 	// 先查询教师是否存在
-	existingInstructor, err := s.instructorRepo.GetByID(id)
+	existingInstructor, err := s.instructorRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// 更新教师信息
+	before := existingInstructor.AuditSnapshot()
+	oldSalary := existingInstructor.Salary
 	existingInstructor.Name = req.Name
 	existingInstructor.Dept = req.Dept
 	existingInstructor.Salary = req.Salary
 
-	return s.instructorRepo.Update(existingInstructor)
+	if err := s.instructorRepo.Update(ctx, existingInstructor); err != nil {
+		return err
+	}
+	after := existingInstructor.AuditSnapshot()
+	after.SalaryChanged = existingInstructor.Salary != oldSalary
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityInstructor, id, before, after)
 }
 
 // DeleteInstructor 删除教师
-func (s *DefaultInstructorService) DeleteInstructor(id string) error {
-	return s.instructorRepo.Delete(id)
-}
-
-// ChangePassword 修改密码
-func (s *DefaultInstructorService) ChangePassword(id string, req *model.ChangePasswordRequest) error {
-	// 先查询教师是否存在
-	instructor, err := s.instructorRepo.GetByID(id)
+func (s *DefaultInstructorService) DeleteInstructor(ctx context.Context, actor model.Actor, id string) error {
+	instructor, err := s.instructorRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
-
-	// 验证旧密码
-	if !utils.CheckPassword(req.OldPassword, instructor.Password) {
-		return errors.New("invalid old password")
+	if err := s.instructorRepo.Delete(ctx, id); err != nil {
+		return err
 	}
-
-	// 加密新密码
-	hashedPassword, err := utils.HashPassword(req.NewPassword)
-	if err != nil {
-		return fmt.Errorf("error hashing password: %w", err)
+	if err := s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityInstructor, id, instructor.AuditSnapshot(), nil); err != nil {
+		return err
 	}
-
-	// 更新密码 - 根据model.Instructor的Salt字段，这里应该传入salt
-	return s.instructorRepo.UpdatePassword(id, hashedPassword, instructor.Salt)
+	return s.sessions.RevokeUserSessions(ctx, id, model.RoleInstructor)
 }
 
 // GetCurrentTeaching 获取教师当前学期的教学任务
-func (s *DefaultInstructorService) GetCurrentTeaching(id string, semester string, year int) ([]*model.Teaches, error) {
-	return s.teachesRepo.GetCurrentTeaching(id, semester, year)
+func (s *DefaultInstructorService) GetCurrentTeaching(ctx context.Context, id string, semester string, year int) ([]*model.Teaches, error) {
+	return s.teachesRepo.GetCurrentTeaching(ctx, id, semester, year)
 }
 
 // AssignGrade 分配成绩
-func (s *DefaultInstructorService) AssignGrade(instructorID string, studentID string, sectionID string, grade string) error {
+func (s *DefaultInstructorService) AssignGrade(ctx context.Context, instructorID string, studentID string, key model.SectionKey, grade string) error {
 	// 检查教师是否教授这门课
-	_, err := s.teachesRepo.FindByInstructorAndSection(instructorID, sectionID)
+	_, err := s.teachesRepo.FindByInstructorAndSection(ctx, instructorID, key)
 	if err != nil {
 		return fmt.Errorf("instructor not teaching this section: %w", err)
 	}
 
+	return s.RecordGrade(ctx, instructorActor(instructorID), studentID, key, grade)
+}
+
+// RecordGrade 登记成绩，不检查任课关系，调用方需要先确认有权登记该课程段的成绩
+func (s *DefaultInstructorService) RecordGrade(ctx context.Context, actor model.Actor, studentID string, key model.SectionKey, grade string) error {
 	// 检查学生是否选了这门课
-	_, err = s.takesRepo.FindByStudentAndSection(studentID, sectionID)
+	takes, err := s.takesRepo.FindByStudentAndSection(ctx, studentID, key)
 	if err != nil {
 		return fmt.Errorf("student not enrolled in this section: %w", err)
 	}
 
 	// 更新成绩
-	return s.takesRepo.UpdateGrade(studentID, sectionID, grade)
+	if err := s.takesRepo.UpdateGrade(ctx, studentID, key, grade); err != nil {
+		return err
+	}
+
+	entityKey := model.AuditKey(studentID, key.String())
+	before := map[string]string{"grade": takes.Grade}
+	after := map[string]string{"grade": grade}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityGrade, entityKey, before, after)
 }
 
 // GetAdvisees 获取导师指导的学生列表
-func (s *DefaultInstructorService) GetAdvisees(instructorID string) ([]*model.Advisor, error) {
-	return s.advisorRepo.FindByInstructorID(instructorID)
+func (s *DefaultInstructorService) GetAdvisees(ctx context.Context, instructorID string) ([]*model.Advisor, error) {
+	return s.advisorRepo.FindByInstructorID(ctx, instructorID)
 }
 
 // AssignTeaching 分配教学任务
-func (s *DefaultInstructorService) AssignTeaching(instructorID string, sectionID string, courseID string, semester string, year int) error {
+func (s *DefaultInstructorService) AssignTeaching(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error {
 	// 检查教师是否存在
-	_, err := s.instructorRepo.GetByID(instructorID)
+	_, err := s.instructorRepo.GetByID(ctx, instructorID)
 	if err != nil {
 		return fmt.Errorf("instructor not found: %w", err)
 	}
 
 	// 检查课程段是否存在
-	_, err = s.sectionRepo.FindByID(sectionID)
+	_, err = s.sectionRepo.FindByID(ctx, key)
 	if err != nil {
 		return fmt.Errorf("section not found: %w", err)
 	}
@@ -176,53 +194,62 @@ func (s *DefaultInstructorService) AssignTeaching(instructorID string, sectionID
 	// 创建教学关系
 	teaches := &model.Teaches{
 		InstructorID: instructorID,
-		CourseID:     courseID,
-		SectionID:    sectionID,
-		Semester:     semester,
-		Year:         year,
+		CourseID:     key.CourseID,
+		SectionID:    key.SecID,
+		Semester:     key.Semester,
+		Year:         key.Year,
 	}
 
-	return s.teachesRepo.Create(teaches)
+	if err := s.teachesRepo.Create(ctx, teaches); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityTeaches, model.AuditKey(instructorID, key.String()), nil, teaches)
 }
 
 // RemoveTeaching 移除教学任务
-func (s *DefaultInstructorService) RemoveTeaching(instructorID string, sectionID string) error {
-	// 需要根据teaches表的完整主键来删除
-	// 先查询teaches记录获取完整信息
-	teaches, err := s.teachesRepo.FindByInstructorAndSection(instructorID, sectionID)
+func (s *DefaultInstructorService) RemoveTeaching(ctx context.Context, actor model.Actor, instructorID string, key model.SectionKey) error {
+	teaches, err := s.teachesRepo.FindByInstructorAndSection(ctx, instructorID, key)
 	if err != nil {
 		return fmt.Errorf("teaching assignment not found: %w", err)
 	}
 
-	return s.teachesRepo.Delete(instructorID, teaches.CourseID, sectionID, teaches.Semester, teaches.Year)
+	if err := s.teachesRepo.Delete(ctx, instructorID, key); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityTeaches, model.AuditKey(instructorID, key.String()), teaches, nil)
 }
 
 // GetByID 根据ID获取教师信息（别名方法）
-func (s *DefaultInstructorService) GetByID(id string) (*model.Instructor, error) {
-	return s.GetInstructorByID(id)
+func (s *DefaultInstructorService) GetByID(ctx context.Context, id string) (*model.Instructor, error) {
+	return s.GetInstructorByID(ctx, id)
 }
 
 // UpdateProfile 更新教师个人信息
-func (s *DefaultInstructorService) UpdateProfile(id string, name string) error {
-	instructor, err := s.instructorRepo.GetByID(id)
+func (s *DefaultInstructorService) UpdateProfile(ctx context.Context, id string, name string) error {
+	instructor, err := s.instructorRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	before := instructor.AuditSnapshot()
 	instructor.Name = name
-	return s.instructorRepo.Update(instructor)
+	if err := s.instructorRepo.Update(ctx, instructor); err != nil {
+		return err
+	}
+	actor := instructorActor(id)
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityInstructor, id, before, instructor.AuditSnapshot())
 }
 
 // GetTeachingSections 获取教师授课的课程段
-func (s *DefaultInstructorService) GetTeachingSections(id string) ([]*model.Section, error) {
-	teaches, err := s.teachesRepo.FindByInstructorID(id)
+func (s *DefaultInstructorService) GetTeachingSections(ctx context.Context, id string) ([]*model.Section, error) {
+	teaches, err := s.teachesRepo.FindByInstructorID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	var sections []*model.Section
 	for _, teach := range teaches {
-		section, err := s.sectionRepo.FindByID(teach.SectionID)
+		section, err := s.sectionRepo.FindByID(ctx, teach.Key())
 		if err != nil {
 			continue
 		}
@@ -233,22 +260,22 @@ func (s *DefaultInstructorService) GetTeachingSections(id string) ([]*model.Sect
 }
 
 // GetSectionStudents 获取课程段的学生名单
-func (s *DefaultInstructorService) GetSectionStudents(instructorID string, sectionID string) ([]*model.Student, error) {
+func (s *DefaultInstructorService) GetSectionStudents(ctx context.Context, instructorID string, key model.SectionKey) ([]*model.Student, error) {
 	// 检查教师是否教授这门课
-	_, err := s.teachesRepo.FindByInstructorAndSection(instructorID, sectionID)
+	_, err := s.teachesRepo.FindByInstructorAndSection(ctx, instructorID, key)
 	if err != nil {
 		return nil, fmt.Errorf("instructor not teaching this section: %w", err)
 	}
 
 	// 获取选课学生
-	takes, err := s.takesRepo.FindBySection(sectionID)
+	takes, err := s.takesRepo.FindBySection(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
 	var students []*model.Student
 	for _, take := range takes {
-		student, err := s.studentRepo.GetByID(take.StudentID)
+		student, err := s.studentRepo.GetByID(ctx, take.StudentID)
 		if err != nil {
 			continue
 		}
@@ -259,14 +286,14 @@ func (s *DefaultInstructorService) GetSectionStudents(instructorID string, secti
 }
 
 // UpdateGrade 更新学生成绩
-func (s *DefaultInstructorService) UpdateGrade(instructorID string, studentID string, sectionID string, grade string) error {
-	return s.AssignGrade(instructorID, studentID, sectionID, grade)
+func (s *DefaultInstructorService) UpdateGrade(ctx context.Context, instructorID string, studentID string, key model.SectionKey, grade string) error {
+	return s.AssignGrade(ctx, instructorID, studentID, key, grade)
 }
 
 // GetAdviseeInfo 获取指导学生的详细信息
-func (s *DefaultInstructorService) GetAdviseeInfo(instructorID string, studentID string) (*model.Student, error) {
+func (s *DefaultInstructorService) GetAdviseeInfo(ctx context.Context, instructorID string, studentID string) (*model.Student, error) {
 	// 检查导师关系 - 使用正确的方法名
-	advisors, err := s.advisorRepo.FindByInstructorID(instructorID)
+	advisors, err := s.advisorRepo.FindByInstructorID(ctx, instructorID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting advisor relationships: %w", err)
 	}
@@ -283,12 +310,12 @@ func (s *DefaultInstructorService) GetAdviseeInfo(instructorID string, studentID
 		return nil, fmt.Errorf("advisor relationship not found")
 	}
 
-	return s.studentRepo.GetByID(studentID)
+	return s.studentRepo.GetByID(ctx, studentID)
 }
 
 // Authenticate 教师认证
-func (s *DefaultInstructorService) Authenticate(id string, password string) (string, error) {
-	instructor, err := s.instructorRepo.GetByID(id)
+func (s *DefaultInstructorService) Authenticate(ctx context.Context, id string, password string) (string, error) {
+	instructor, err := s.instructorRepo.GetByID(ctx, id)
 	if err != nil {
 		return "", fmt.Errorf("instructor not found: %w", err)
 	}
@@ -299,3 +326,8 @@ func (s *DefaultInstructorService) Authenticate(id string, password string) (str
 
 	return instructor.ID, nil
 }
+
+// ResolveSection 将请求中的课程段引用解析为完整主键
+func (s *DefaultInstructorService) ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error) {
+	return resolveSectionRef(ctx, s.sectionRepo, ref)
+}
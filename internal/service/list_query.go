@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/yourusername/student-management-system/internal/model"
+)
+
+// listAll 按最大页大小沿着游标读完所有记录，用于需要处理全部数据的批量操作
+func listAll[T any](ctx context.Context, list func(context.Context, *model.ListQuery) (*model.ListResult[T], error)) ([]*T, error) {
+	var items []*T
+	q := &model.ListQuery{PageSize: model.MaxPageSize}
+	for {
+		page, err := list(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items, nil
+		}
+		q = &model.ListQuery{PageSize: model.MaxPageSize, Cursor: page.NextCursor}
+	}
+}
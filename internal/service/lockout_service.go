@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/config"
+)
+
+// ErrAccountLocked 账号因连续登录失败被锁定，需要管理员解锁
+var ErrAccountLocked = errors.New("account is locked")
+
+// LoginThrottledError 表示登录过于频繁，需要等待RetryAfter后再试
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter)
+}
+
+// LockoutService 定义登录防暴力破解服务接口
+type LockoutService interface {
+	CheckLogin(ctx context.Context, attempt *model.LoginAttempt) error
+	RecordFailure(ctx context.Context, attempt *model.LoginAttempt) error
+	RecordSuccess(ctx context.Context, attempt *model.LoginAttempt) error
+	GetLockStatus(ctx context.Context, userID string, role string) (*model.LockStatus, error)
+	ListLockedAccounts(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LockStatus], error)
+	Unlock(ctx context.Context, actor model.Actor, userID string, role string) error
+	ListLoginAttempts(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginAttempt], error)
+}
+
+// DefaultLockoutService 实现LockoutService接口
+//
+// 账号和IP分别统计连续失败次数：超过免费次数后按指数退避，
+// 账号连续失败达到上限后锁定，直到登录成功前由管理员解锁。
+// 被拒绝的尝试也会记录，但不计入失败次数，避免攻击者不断延长退避时间。
+// 不存在的账号只计入IP的失败次数，避免为随意猜测的用户名建立计数。
+// 注意：知道学号或工号的人可以故意输错密码把该账号锁定，锁定不会自动解除，
+// 需要时调大MaxFailures，由IP退避来限制暴力破解。
+type DefaultLockoutService struct {
+	attemptRepo    repository.LoginAttemptRepository
+	studentRepo    repository.StudentRepository
+	instructorRepo repository.InstructorRepository
+	adminRepo      repository.AdminRepository
+	cfg            config.LockoutConfig
+	audit          AuditRecorder
+	now            func() time.Time // 当前时间，测试中可替换
+}
+
+// NewLockoutService 创建登录防暴力破解服务实例
+func NewLockoutService(attemptRepo repository.LoginAttemptRepository, studentRepo repository.StudentRepository, instructorRepo repository.InstructorRepository, adminRepo repository.AdminRepository, cfg config.LockoutConfig, audit AuditRecorder) LockoutService {
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 10
+	}
+	if cfg.FreeAttempts <= 0 {
+		cfg.FreeAttempts = 3
+	}
+	if cfg.IPFreeAttempts <= 0 {
+		cfg.IPFreeAttempts = 20
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = 1
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 900
+	}
+	if cfg.IPWindow <= 0 {
+		cfg.IPWindow = 3600
+	}
+	return &DefaultLockoutService{
+		attemptRepo:    attemptRepo,
+		studentRepo:    studentRepo,
+		instructorRepo: instructorRepo,
+		adminRepo:      adminRepo,
+		cfg:            cfg,
+		audit:          auditRecorderOrNop(audit),
+		now:            time.Now,
+	}
+}
+
+// CheckLogin 在验证密码之前检查账号和IP是否允许登录，被拒绝的尝试会被记录
+func (s *DefaultLockoutService) CheckLogin(ctx context.Context, attempt *model.LoginAttempt) error {
+	now := s.now()
+	accountKey := model.AccountThrottleKey(attempt.UserID, attempt.Role)
+
+	account, err := s.findThrottle(ctx, model.ThrottleScopeAccount, accountKey)
+	if err != nil {
+		return err
+	}
+
+	if account != nil && account.LockedAt != nil {
+		if err := s.record(ctx, attempt, false, model.LoginReasonLocked, now); err != nil {
+			return err
+		}
+		return ErrAccountLocked
+	}
+
+	ip, err := s.findIPThrottle(ctx, attempt.IP, now)
+	if err != nil {
+		return err
+	}
+
+	wait := maxDuration(
+		s.retryAfter(account, s.cfg.FreeAttempts, now),
+		s.retryAfter(ip, s.cfg.IPFreeAttempts, now),
+	)
+	if wait > 0 {
+		if err := s.record(ctx, attempt, false, model.LoginReasonThrottled, now); err != nil {
+			return err
+		}
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+// RecordFailure 记录一次密码错误，增加账号和IP的失败次数，达到上限时锁定账号
+// 账号不存在时只增加IP的失败次数
+func (s *DefaultLockoutService) RecordFailure(ctx context.Context, attempt *model.LoginAttempt) error {
+	now := s.now()
+	if err := s.record(ctx, attempt, false, model.LoginReasonInvalidCredentials, now); err != nil {
+		return err
+	}
+
+	exists, err := s.accountExists(ctx, attempt.UserID, attempt.Role)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := s.recordAccountFailure(ctx, model.AccountThrottleKey(attempt.UserID, attempt.Role), now); err != nil {
+			return err
+		}
+	}
+
+	// 超过统计窗口的IP计数先清零
+	if _, err := s.findIPThrottle(ctx, attempt.IP, now); err != nil {
+		return err
+	}
+
+	_, err = s.attemptRepo.IncrementFailures(ctx, model.ThrottleScopeIP, attempt.IP, now)
+	return err
+}
+
+// recordAccountFailure 增加账号的失败次数，达到上限时锁定账号
+func (s *DefaultLockoutService) recordAccountFailure(ctx context.Context, accountKey string, now time.Time) error {
+	account, err := s.attemptRepo.IncrementFailures(ctx, model.ThrottleScopeAccount, accountKey, now)
+	if err != nil {
+		return err
+	}
+
+	if account.Failures >= s.cfg.MaxFailures {
+		return s.attemptRepo.Lock(ctx, model.ThrottleScopeAccount, accountKey, now)
+	}
+	return nil
+}
+
+// accountExists 检查登录的账号是否存在，未知角色视为不存在
+func (s *DefaultLockoutService) accountExists(ctx context.Context, userID string, role string) (bool, error) {
+	switch role {
+	case model.RoleStudent:
+		return s.studentRepo.ExistsByID(ctx, userID)
+	case model.RoleInstructor:
+		return s.instructorRepo.ExistsByID(ctx, userID)
+	case model.RoleAdmin:
+		if _, err := s.adminRepo.FindByID(ctx, userID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// RecordSuccess 记录一次成功登录并清除账号的失败次数
+// IP的失败次数不清除，避免攻击者用自己的账号登录来重置计数
+func (s *DefaultLockoutService) RecordSuccess(ctx context.Context, attempt *model.LoginAttempt) error {
+	if err := s.record(ctx, attempt, true, model.LoginReasonSuccess, s.now()); err != nil {
+		return err
+	}
+
+	return s.attemptRepo.ResetThrottle(ctx, model.ThrottleScopeAccount, model.AccountThrottleKey(attempt.UserID, attempt.Role))
+}
+
+// GetLockStatus 获取账号的锁定状态
+func (s *DefaultLockoutService) GetLockStatus(ctx context.Context, userID string, role string) (*model.LockStatus, error) {
+	account, err := s.findThrottle(ctx, model.ThrottleScopeAccount, model.AccountThrottleKey(userID, role))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.LockStatus{UserID: userID, Role: role}
+	if account != nil {
+		s.fillStatus(status, account)
+	}
+
+	return status, nil
+}
+
+// ListLockedAccounts 分页查询被锁定的账号
+func (s *DefaultLockoutService) ListLockedAccounts(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LockStatus], error) {
+	throttles, err := s.attemptRepo.ListLockedAccounts(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*model.LockStatus, 0, len(throttles.Items))
+	for _, throttle := range throttles.Items {
+		role, userID, _ := strings.Cut(throttle.Key, "/")
+		status := &model.LockStatus{UserID: userID, Role: role}
+		s.fillStatus(status, throttle)
+		statuses = append(statuses, status)
+	}
+
+	return &model.ListResult[model.LockStatus]{
+		Items:      statuses,
+		Total:      throttles.Total,
+		Page:       throttles.Page,
+		PageSize:   throttles.PageSize,
+		NextCursor: throttles.NextCursor,
+	}, nil
+}
+
+// Unlock 管理员解锁账号并清除失败次数
+func (s *DefaultLockoutService) Unlock(ctx context.Context, actor model.Actor, userID string, role string) error {
+	before, err := s.GetLockStatus(ctx, userID, role)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attemptRepo.ResetThrottle(ctx, model.ThrottleScopeAccount, model.AccountThrottleKey(userID, role)); err != nil {
+		return err
+	}
+
+	after, err := s.GetLockStatus(ctx, userID, role)
+	if err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityLockout, model.AuditKey(role, userID), before, after)
+}
+
+// ListLoginAttempts 分页查询登录尝试记录
+func (s *DefaultLockoutService) ListLoginAttempts(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginAttempt], error) {
+	return s.attemptRepo.List(ctx, q)
+}
+
+// record 保存一次登录尝试
+func (s *DefaultLockoutService) record(ctx context.Context, attempt *model.LoginAttempt, success bool, reason string, now time.Time) error {
+	attempt.Success = success
+	attempt.Reason = reason
+	attempt.CreatedAt = now
+	return s.attemptRepo.Create(ctx, attempt)
+}
+
+// findThrottle 查找失败计数，没有记录时返回nil
+func (s *DefaultLockoutService) findThrottle(ctx context.Context, scope string, key string) (*model.LoginThrottle, error) {
+	throttle, err := s.attemptRepo.FindThrottle(ctx, scope, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return throttle, nil
+}
+
+// findIPThrottle 查找IP的失败计数，超过统计窗口没有失败时清零并返回nil
+func (s *DefaultLockoutService) findIPThrottle(ctx context.Context, ip string, now time.Time) (*model.LoginThrottle, error) {
+	throttle, err := s.findThrottle(ctx, model.ThrottleScopeIP, ip)
+	if err != nil || throttle == nil {
+		return nil, err
+	}
+
+	if now.Sub(throttle.LastFailureAt) > time.Duration(s.cfg.IPWindow)*time.Second {
+		if err := s.attemptRepo.ResetThrottle(ctx, model.ThrottleScopeIP, ip); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return throttle, nil
+}
+
+// backoff 计算连续失败后需要等待的时间：超过免费次数后从BaseDelay开始每次翻倍，不超过MaxDelay
+func (s *DefaultLockoutService) backoff(failures int, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+
+	maxDelay := time.Duration(s.cfg.MaxDelay) * time.Second
+	delay := time.Duration(s.cfg.BaseDelay) * time.Second
+	for i := free; i < failures; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return delay
+}
+
+// retryAfter 计算距离允许下次尝试的剩余时间
+func (s *DefaultLockoutService) retryAfter(throttle *model.LoginThrottle, free int, now time.Time) time.Duration {
+	if throttle == nil {
+		return 0
+	}
+
+	wait := throttle.LastFailureAt.Add(s.backoff(throttle.Failures, free)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// fillStatus 根据失败计数填写锁定状态
+func (s *DefaultLockoutService) fillStatus(status *model.LockStatus, throttle *model.LoginThrottle) {
+	lastFailureAt := throttle.LastFailureAt
+	status.Locked = throttle.LockedAt != nil
+	status.LockedAt = throttle.LockedAt
+	status.Failures = throttle.Failures
+	status.LastFailureAt = &lastFailureAt
+	if !status.Locked {
+		status.RetryAfter = int64(s.retryAfter(throttle, s.cfg.FreeAttempts, s.now()).Seconds())
+	}
+}
+
+// maxDuration 返回两个时长中较大的一个
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/internal/repository/memory"
+	"github.com/yourusername/student-management-system/pkg/config"
+)
+
+// fakeLoginAttemptRepository 基于内存的登录尝试仓库
+type fakeLoginAttemptRepository struct {
+	attempts  []*model.LoginAttempt
+	throttles map[string]*model.LoginThrottle
+}
+
+func (r *fakeLoginAttemptRepository) Create(ctx context.Context, attempt *model.LoginAttempt) error {
+	copied := *attempt
+	copied.ID = int64(len(r.attempts) + 1)
+	r.attempts = append(r.attempts, &copied)
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) List(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginAttempt], error) {
+	if err := q.Normalize(model.LoginAttemptListFields); err != nil {
+		return nil, err
+	}
+	var attempts []*model.LoginAttempt
+	for i := len(r.attempts) - 1; i >= 0; i-- {
+		attempt := r.attempts[i]
+		if (q.Filters["user_id"] == "" || attempt.UserID == q.Filters["user_id"]) &&
+			(q.Filters["role"] == "" || attempt.Role == q.Filters["role"]) &&
+			(q.Filters["ip"] == "" || attempt.IP == q.Filters["ip"]) {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return model.NewListResult(q, attempts, int64(len(attempts))), nil
+}
+
+func (r *fakeLoginAttemptRepository) FindThrottle(ctx context.Context, scope string, key string) (*model.LoginThrottle, error) {
+	throttle, ok := r.throttles[scope+":"+key]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *fakeLoginAttemptRepository) ListLockedAccounts(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.LoginThrottle], error) {
+	if err := q.Normalize(model.LockedAccountListFields); err != nil {
+		return nil, err
+	}
+	var throttles []*model.LoginThrottle
+	for _, throttle := range r.throttles {
+		if throttle.Scope == model.ThrottleScopeAccount && throttle.LockedAt != nil {
+			copied := *throttle
+			throttles = append(throttles, &copied)
+		}
+	}
+	return model.NewListResult(q, throttles, int64(len(throttles))), nil
+}
+
+func (r *fakeLoginAttemptRepository) IncrementFailures(ctx context.Context, scope string, key string, at time.Time) (*model.LoginThrottle, error) {
+	throttle, ok := r.throttles[scope+":"+key]
+	if !ok {
+		throttle = &model.LoginThrottle{Scope: scope, Key: key}
+		r.throttles[scope+":"+key] = throttle
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = at
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *fakeLoginAttemptRepository) Lock(ctx context.Context, scope string, key string, at time.Time) error {
+	if throttle, ok := r.throttles[scope+":"+key]; ok {
+		throttle.LockedAt = &at
+	}
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) ResetThrottle(ctx context.Context, scope string, key string) error {
+	delete(r.throttles, scope+":"+key)
+	return nil
+}
+
+func newTestLockoutService(t *testing.T) (*DefaultLockoutService, *fakeLoginAttemptRepository, *time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	repo := &fakeLoginAttemptRepository{throttles: make(map[string]*model.LoginThrottle)}
+	store := memory.NewStore()
+	if err := memory.NewDepartmentRepository(store).Create(ctx, &model.Department{DeptName: "Comp. Sci.", Building: "Taylor", Budget: 100000}); err != nil {
+		t.Fatal(err)
+	}
+	students := memory.NewStudentRepository(store)
+	for _, id := range []string{"S001", "S009"} {
+		if err := students.Create(ctx, &model.Student{ID: id, Name: id, Dept: "Comp. Sci."}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	instructors := &fakeInstructorRepository{instructors: map[string]*model.Instructor{"I001": {ID: "I001"}}}
+	admins := newFakeAdminRepository()
+	admins.admins["root"] = &model.Admin{ID: "root"}
+	svc := NewLockoutService(repo, students, instructors, admins, config.LockoutConfig{
+		MaxFailures:    6,
+		FreeAttempts:   3,
+		IPFreeAttempts: 5,
+		BaseDelay:      1,
+		MaxDelay:       4,
+		IPWindow:       60,
+	}, nil).(*DefaultLockoutService)
+	now := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func TestLockoutServiceBackoffAndLock(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newTestLockoutService(t)
+	attempt := func() *model.LoginAttempt {
+		return &model.LoginAttempt{UserID: "S001", Role: model.RoleStudent, IP: "10.0.0.1"}
+	}
+
+	// 免费次数内不需要等待
+	for i := 0; i < 3; i++ {
+		if err := svc.CheckLogin(ctx, attempt()); err != nil {
+			t.Fatalf("CheckLogin() attempt %d error = %v", i+1, err)
+		}
+		if err := svc.RecordFailure(ctx, attempt()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 之后等待时间按指数增长，不超过上限
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		var throttled *LoginThrottledError
+		if err := svc.CheckLogin(ctx, attempt()); !errors.As(err, &throttled) || throttled.RetryAfter != want {
+			t.Fatalf("Expected retry after %s, got %v", want, err)
+		}
+		*now = now.Add(want)
+		if err := svc.CheckLogin(ctx, attempt()); err != nil {
+			t.Fatalf("Expected login allowed after %s, got %v", want, err)
+		}
+		if err := svc.RecordFailure(ctx, attempt()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 达到上限后锁定，等待也不能解锁
+	*now = now.Add(time.Hour)
+	if err := svc.CheckLogin(ctx, attempt()); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("Expected ErrAccountLocked, got %v", err)
+	}
+	locked, _ := svc.ListLockedAccounts(ctx, &model.ListQuery{})
+	if locked.Total != 1 || locked.Items[0].UserID != "S001" || locked.Items[0].Role != model.RoleStudent || locked.Items[0].Failures != 6 {
+		t.Fatalf("Expected S001 in locked accounts, got %+v", locked)
+	}
+
+	// 被拒绝的尝试会记录，但不增加失败次数
+	status, _ := svc.GetLockStatus(ctx, "S001", model.RoleStudent)
+	if !status.Locked || status.Failures != 6 {
+		t.Errorf("Expected locked status with 6 failures, got %+v", status)
+	}
+	attempts, _ := svc.ListLoginAttempts(ctx, &model.ListQuery{Filters: map[string]string{"user_id": "S001"}})
+	if len(attempts.Items) != len(repo.attempts) || attempts.Items[0].Reason != model.LoginReasonLocked {
+		t.Errorf("Expected latest attempt to be recorded as locked, got %+v", attempts.Items[0])
+	}
+
+	// 同ID的其他角色不受影响
+	if err := svc.CheckLogin(ctx, &model.LoginAttempt{UserID: "S001", Role: model.RoleInstructor, IP: "10.0.0.2"}); err != nil {
+		t.Errorf("Expected other role to be unaffected, got %v", err)
+	}
+
+	if err := svc.Unlock(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, "S001", model.RoleStudent); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CheckLogin(ctx, attempt()); err != nil {
+		t.Errorf("Expected login allowed after unlock, got %v", err)
+	}
+}
+
+func TestLockoutServiceIPThrottle(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestLockoutService(t)
+
+	// 同一IP尝试不同账号，每个账号都在免费次数内，但IP被限制
+	for i := 0; i < 5; i++ {
+		attempt := &model.LoginAttempt{UserID: "S00" + string(rune('0'+i)), Role: model.RoleStudent, IP: "10.0.0.9"}
+		if err := svc.RecordFailure(ctx, attempt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var throttled *LoginThrottledError
+	if err := svc.CheckLogin(ctx, &model.LoginAttempt{UserID: "S009", Role: model.RoleStudent, IP: "10.0.0.9"}); !errors.As(err, &throttled) {
+		t.Fatalf("Expected IP to be throttled, got %v", err)
+	}
+	if err := svc.CheckLogin(ctx, &model.LoginAttempt{UserID: "S009", Role: model.RoleStudent, IP: "10.0.0.10"}); err != nil {
+		t.Errorf("Expected other IP to be allowed, got %v", err)
+	}
+
+	// 成功登录不清除IP的失败次数
+	if err := svc.RecordSuccess(ctx, &model.LoginAttempt{UserID: "S001", Role: model.RoleStudent, IP: "10.0.0.9"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CheckLogin(ctx, &model.LoginAttempt{UserID: "S009", Role: model.RoleStudent, IP: "10.0.0.9"}); !errors.As(err, &throttled) {
+		t.Errorf("Expected IP to stay throttled after success, got %v", err)
+	}
+
+	// 超过统计窗口后IP计数清零
+	*now = now.Add(2 * time.Minute)
+	if err := svc.CheckLogin(ctx, &model.LoginAttempt{UserID: "S009", Role: model.RoleStudent, IP: "10.0.0.9"}); err != nil {
+		t.Errorf("Expected IP throttle to reset after window, got %v", err)
+	}
+}
+
+func TestLockoutServiceSuccessResetsAccount(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestLockoutService(t)
+	attempt := &model.LoginAttempt{UserID: "I001", Role: model.RoleInstructor, IP: "10.0.0.1"}
+
+	for i := 0; i < 3; i++ {
+		if err := svc.RecordFailure(ctx, attempt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.RecordSuccess(ctx, attempt); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := svc.GetLockStatus(ctx, "I001", model.RoleInstructor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Locked || status.Failures != 0 || status.RetryAfter != 0 {
+		t.Errorf("Expected account counters reset after success, got %+v", status)
+	}
+}
+
+func TestLockoutServiceUnknownAccount(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestLockoutService(t)
+
+	// 不存在的账号不建立账号计数，也就不会被锁定，但仍计入IP的失败次数
+	for _, attempt := range []*model.LoginAttempt{
+		{UserID: "nobody", Role: model.RoleStudent, IP: "10.0.0.1"},
+		{UserID: "nobody", Role: model.RoleInstructor, IP: "10.0.0.1"},
+		{UserID: "nobody", Role: model.RoleAdmin, IP: "10.0.0.1"},
+	} {
+		for i := 0; i < 10; i++ {
+			if err := svc.RecordFailure(ctx, attempt); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, ok := repo.throttles[model.ThrottleScopeAccount+":"+model.AccountThrottleKey(attempt.UserID, attempt.Role)]; ok {
+			t.Errorf("Expected no account throttle for unknown %s account", attempt.Role)
+		}
+	}
+	if ip := repo.throttles[model.ThrottleScopeIP+":10.0.0.1"]; ip == nil || ip.Failures != 30 {
+		t.Errorf("Expected 30 IP failures, got %+v", ip)
+	}
+
+	// 已存在的管理员账号照常计数
+	if err := svc.RecordFailure(ctx, &model.LoginAttempt{UserID: "root", Role: model.RoleAdmin, IP: "10.0.0.2"}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := svc.GetLockStatus(ctx, "root", model.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Failures != 1 {
+		t.Errorf("Expected 1 failure for known admin, got %+v", status)
+	}
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/oidc"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+var (
+	// ErrSSODisabled 没有启用单点登录
+	ErrSSODisabled = errors.New("single sign-on is not enabled")
+
+	// ErrInvalidSSOState 回调中的state无效、过期或已使用
+	ErrInvalidSSOState = errors.New("invalid or expired single sign-on state")
+
+	// ErrSSORoleNotMapped 身份提供方账号的角色声明没有匹配任何映射
+	ErrSSORoleNotMapped = errors.New("identity provider account has no mapped role")
+
+	// ErrSSOAccountNotLinked 身份提供方账号没有对应的本地账号
+	ErrSSOAccountNotLinked = errors.New("identity provider account is not linked to a local account")
+
+	// ErrSSOIdentityNotFound 身份绑定不存在
+	ErrSSOIdentityNotFound = errors.New("single sign-on identity not found")
+
+	// ErrSSOIdentityExists 账号已绑定，或subject、邮箱已绑定到其他账号
+	ErrSSOIdentityExists = errors.New("single sign-on identity already exists")
+)
+
+// OIDCService 定义单点登录服务接口
+type OIDCService interface {
+	// BeginLogin 返回跳转到身份提供方登录页的地址和需要绑定到浏览器的state
+	BeginLogin(ctx context.Context) (*model.OIDCLoginStart, error)
+	// CompleteLogin 处理身份提供方的回调，browserState是发起登录时写入浏览器的state，返回映射到的本地账号
+	CompleteLogin(ctx context.Context, state string, browserState string, code string) (*model.OIDCLoginResult, error)
+
+	ListIdentities(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.OIDCIdentity], error)
+	LinkIdentity(ctx context.Context, actor model.Actor, req *model.OIDCIdentityRequest) (*model.OIDCIdentity, error)
+	UnlinkIdentity(ctx context.Context, actor model.Actor, role string, userID string) error
+}
+
+// DefaultOIDCService 实现OIDCService接口
+//
+// 登录角色由角色声明按配置的映射决定，只能映射为学生或教师，管理员不支持单点登录。
+// 本地账号按以下顺序确定：已绑定的subject；配置了学号或工号声明时取声明的值；
+// 已验证的邮箱匹配管理员预先绑定的邮箱，此时同时记录subject，之后按subject匹配。
+type DefaultOIDCService struct {
+	provider       *oidc.Provider
+	repo           repository.OIDCRepository
+	studentRepo    repository.StudentRepository
+	instructorRepo repository.InstructorRepository
+	cfg            config.OIDCConfig
+	audit          AuditRecorder
+	now            func() time.Time // 当前时间，测试中可替换
+}
+
+// NewOIDCService 创建单点登录服务实例，provider为nil表示没有启用单点登录
+func NewOIDCService(provider *oidc.Provider, repo repository.OIDCRepository, studentRepo repository.StudentRepository, instructorRepo repository.InstructorRepository, cfg config.OIDCConfig, audit AuditRecorder) OIDCService {
+	if cfg.StateExpiration <= 0 {
+		cfg.StateExpiration = 600
+	}
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "groups"
+	}
+	return &DefaultOIDCService{
+		provider:       provider,
+		repo:           repo,
+		studentRepo:    studentRepo,
+		instructorRepo: instructorRepo,
+		cfg:            cfg,
+		audit:          auditRecorderOrNop(audit),
+		now:            time.Now,
+	}
+}
+
+// BeginLogin 保存登录状态并生成授权地址
+func (s *DefaultOIDCService) BeginLogin(ctx context.Context) (*model.OIDCLoginStart, error) {
+	if s.provider == nil {
+		return nil, ErrSSODisabled
+	}
+
+	id, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("error generating oidc state: %w", err)
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating oidc state: %w", err)
+	}
+
+	nonce, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("error generating oidc nonce: %w", err)
+	}
+
+	verifier, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating oidc code verifier: %w", err)
+	}
+
+	now := s.now()
+	state := &model.OIDCLoginState{
+		ID:           id,
+		StateHash:    hashTokenSecret(secret),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(time.Duration(s.cfg.StateExpiration) * time.Second),
+	}
+
+	stateToken := id + "." + secret
+	authURL, err := s.provider.AuthCodeURL(ctx, stateToken, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateState(ctx, state); err != nil {
+		return nil, err
+	}
+
+	return &model.OIDCLoginStart{
+		AuthURL:   authURL,
+		State:     stateToken,
+		ExpiresAt: state.ExpiresAt,
+		Secure:    strings.HasPrefix(s.cfg.RedirectURL, "https://"),
+	}, nil
+}
+
+// CompleteLogin 校验state后用授权码换取ID令牌，再把身份提供方账号映射为本地账号
+// state必须与发起登录的浏览器保存的一致，回调地址转给其他浏览器无效；
+// state在换取令牌之前就标记为已使用，同一个回调地址不能重放
+func (s *DefaultOIDCService) CompleteLogin(ctx context.Context, stateToken string, browserState string, code string) (*model.OIDCLoginResult, error) {
+	if s.provider == nil {
+		return nil, ErrSSODisabled
+	}
+
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(stateToken), []byte(browserState)) != 1 {
+		return nil, ErrInvalidSSOState
+	}
+
+	state, err := s.useState(ctx, stateToken)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.provider.Exchange(ctx, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	role := s.mapRole(token)
+	if role == "" {
+		return nil, ErrSSORoleNotMapped
+	}
+
+	userID, err := s.resolveUser(ctx, role, token)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.accountExists(ctx, role, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrSSOAccountNotLinked
+	}
+
+	return &model.OIDCLoginResult{UserID: userID, Role: role}, nil
+}
+
+// ListIdentities 分页查询身份绑定
+func (s *DefaultOIDCService) ListIdentities(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.OIDCIdentity], error) {
+	return s.repo.ListIdentities(ctx, q)
+}
+
+// LinkIdentity 把身份提供方账号绑定到学生或教师账号
+func (s *DefaultOIDCService) LinkIdentity(ctx context.Context, actor model.Actor, req *model.OIDCIdentityRequest) (*model.OIDCIdentity, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.accountExists(ctx, req.Role, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s %s not found", req.Role, req.UserID)
+	}
+
+	identity := &model.OIDCIdentity{
+		Role:      req.Role,
+		UserID:    req.UserID,
+		Subject:   req.Subject,
+		Email:     strings.ToLower(strings.TrimSpace(req.Email)),
+		CreatedAt: s.now(),
+	}
+
+	if err := s.repo.CreateIdentity(ctx, identity); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrSSOIdentityExists
+		}
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntitySSOIdentity, model.AuditKey(identity.Role, identity.UserID), nil, identity); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// UnlinkIdentity 解除身份绑定，之后该账号只能通过学号或工号声明登录
+func (s *DefaultOIDCService) UnlinkIdentity(ctx context.Context, actor model.Actor, role string, userID string) error {
+	identity, err := s.repo.FindIdentity(ctx, role, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSSOIdentityNotFound
+		}
+		return err
+	}
+
+	if err := s.repo.DeleteIdentity(ctx, role, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSSOIdentityNotFound
+		}
+		return err
+	}
+
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntitySSOIdentity, model.AuditKey(role, userID), identity, nil)
+}
+
+// useState 校验回调中的state并标记为已使用
+func (s *DefaultOIDCService) useState(ctx context.Context, token string) (*model.OIDCLoginState, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || id == "" || secret == "" {
+		return nil, ErrInvalidSSOState
+	}
+
+	state, err := s.repo.FindState(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidSSOState
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashTokenSecret(secret)), []byte(state.StateHash)) != 1 {
+		return nil, ErrInvalidSSOState
+	}
+
+	now := s.now()
+	if !state.IsUsable(now) {
+		return nil, ErrInvalidSSOState
+	}
+
+	if err := s.repo.MarkStateUsed(ctx, state.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidSSOState
+		}
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// mapRole 按配置顺序取第一个匹配角色声明的映射，没有匹配时返回空字符串
+func (s *DefaultOIDCService) mapRole(token *oidc.IDToken) string {
+	values := token.ClaimStrings(s.cfg.RoleClaim)
+	for _, mapping := range s.cfg.RoleMappings {
+		if mapping.Role != model.RoleStudent && mapping.Role != model.RoleInstructor {
+			continue
+		}
+		for _, v := range values {
+			if v == mapping.Value {
+				return mapping.Role
+			}
+		}
+	}
+	return ""
+}
+
+// resolveUser 确定身份提供方账号对应的学号或工号
+func (s *DefaultOIDCService) resolveUser(ctx context.Context, role string, token *oidc.IDToken) (string, error) {
+	identity, err := s.repo.FindIdentityBySubject(ctx, role, token.Subject)
+	if err == nil {
+		return identity.UserID, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+
+	if claim := s.cfg.IDClaims[role]; claim != "" {
+		if values := token.ClaimStrings(claim); len(values) > 0 && values[0] != "" {
+			return values[0], nil
+		}
+	}
+
+	// 未验证的邮箱可以由用户自己随意填写，不能用来匹配账号
+	if token.Email == "" || !token.EmailVerified {
+		return "", ErrSSOAccountNotLinked
+	}
+
+	identity, err = s.repo.FindIdentityByEmail(ctx, role, strings.ToLower(token.Email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrSSOAccountNotLinked
+		}
+		return "", err
+	}
+
+	// 邮箱已经绑定了另一个subject，说明邮箱被身份提供方重新分配给了别人
+	if identity.Subject != "" {
+		return "", ErrSSOAccountNotLinked
+	}
+	if err := s.repo.BindSubject(ctx, role, identity.UserID, token.Subject); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrSSOAccountNotLinked
+		}
+		return "", err
+	}
+
+	return identity.UserID, nil
+}
+
+// accountExists 判断学生或教师账号是否存在
+func (s *DefaultOIDCService) accountExists(ctx context.Context, role string, userID string) (bool, error) {
+	switch role {
+	case model.RoleStudent:
+		return s.studentRepo.ExistsByID(ctx, userID)
+	case model.RoleInstructor:
+		return s.instructorRepo.ExistsByID(ctx, userID)
+	}
+	return false, ErrUnknownRole
+}
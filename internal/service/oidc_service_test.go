@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/oidc"
+	"github.com/yourusername/student-management-system/pkg/oidc/oidctest"
+)
+
+// fakeOIDCRepository 在内存中保存登录状态和身份绑定
+type fakeOIDCRepository struct {
+	states     map[string]*model.OIDCLoginState
+	identities []*model.OIDCIdentity
+}
+
+func (r *fakeOIDCRepository) CreateState(ctx context.Context, state *model.OIDCLoginState) error {
+	copied := *state
+	r.states[state.ID] = &copied
+	return nil
+}
+
+func (r *fakeOIDCRepository) FindState(ctx context.Context, id string) (*model.OIDCLoginState, error) {
+	state, ok := r.states[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (r *fakeOIDCRepository) MarkStateUsed(ctx context.Context, id string, at time.Time) error {
+	state, ok := r.states[id]
+	if !ok || state.UsedAt != nil {
+		return repository.ErrNotFound
+	}
+	state.UsedAt = &at
+	return nil
+}
+
+func (r *fakeOIDCRepository) ListIdentities(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.OIDCIdentity], error) {
+	if err := q.Normalize(model.OIDCIdentityListFields); err != nil {
+		return nil, err
+	}
+	return model.NewListResult(q, r.identities, int64(len(r.identities))), nil
+}
+
+func (r *fakeOIDCRepository) find(match func(*model.OIDCIdentity) bool) (*model.OIDCIdentity, error) {
+	for _, identity := range r.identities {
+		if match(identity) {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeOIDCRepository) FindIdentity(ctx context.Context, role string, userID string) (*model.OIDCIdentity, error) {
+	return r.find(func(i *model.OIDCIdentity) bool { return i.Role == role && i.UserID == userID })
+}
+
+func (r *fakeOIDCRepository) FindIdentityBySubject(ctx context.Context, role string, subject string) (*model.OIDCIdentity, error) {
+	return r.find(func(i *model.OIDCIdentity) bool { return i.Role == role && i.Subject != "" && i.Subject == subject })
+}
+
+func (r *fakeOIDCRepository) FindIdentityByEmail(ctx context.Context, role string, email string) (*model.OIDCIdentity, error) {
+	return r.find(func(i *model.OIDCIdentity) bool { return i.Role == role && i.Email != "" && i.Email == email })
+}
+
+func (r *fakeOIDCRepository) CreateIdentity(ctx context.Context, identity *model.OIDCIdentity) error {
+	for _, existing := range r.identities {
+		if existing.Role == identity.Role && (existing.UserID == identity.UserID ||
+			(identity.Subject != "" && existing.Subject == identity.Subject) ||
+			(identity.Email != "" && existing.Email == identity.Email)) {
+			return repository.ErrDuplicate
+		}
+	}
+	copied := *identity
+	r.identities = append(r.identities, &copied)
+	return nil
+}
+
+func (r *fakeOIDCRepository) BindSubject(ctx context.Context, role string, userID string, subject string) error {
+	for _, identity := range r.identities {
+		if identity.Role == role && identity.UserID == userID && identity.Subject == "" {
+			identity.Subject = subject
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *fakeOIDCRepository) DeleteIdentity(ctx context.Context, role string, userID string) error {
+	for i, identity := range r.identities {
+		if identity.Role == role && identity.UserID == userID {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// fakeStudentIDs 只实现判断学生是否存在
+type fakeStudentIDs struct {
+	repository.StudentRepository
+	ids map[string]bool
+}
+
+func (r *fakeStudentIDs) ExistsByID(ctx context.Context, id string) (bool, error) {
+	return r.ids[id], nil
+}
+
+func newTestOIDCService(t *testing.T) (*DefaultOIDCService, *oidctest.Server, *fakeOIDCRepository) {
+	idp := oidctest.NewServer("sms", "sms-secret")
+	t.Cleanup(idp.Close)
+
+	cfg := config.OIDCConfig{
+		Enabled:      true,
+		Issuer:       idp.Issuer(),
+		ClientID:     "sms",
+		ClientSecret: "sms-secret",
+		RedirectURL:  "http://localhost:8080/api/oidc/callback",
+		RoleClaim:    "groups",
+		RoleMappings: []config.OIDCRoleMapping{
+			{Value: "faculty", Role: model.RoleInstructor},
+			{Value: "students", Role: model.RoleStudent},
+			{Value: "staff", Role: model.RoleAdmin}, // 管理员不能通过单点登录，映射被忽略
+		},
+		IDClaims: map[string]string{model.RoleStudent: "student_number"},
+	}
+	provider, err := oidc.NewProvider(cfg, idp.Client())
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	repo := &fakeOIDCRepository{states: make(map[string]*model.OIDCLoginState)}
+	students := &fakeStudentIDs{ids: map[string]bool{"S001": true}}
+	instructors := &fakeInstructorRepository{instructors: map[string]*model.Instructor{"I001": {ID: "I001"}}}
+	svc := NewOIDCService(provider, repo, students, instructors, cfg, nil).(*DefaultOIDCService)
+	return svc, idp, repo
+}
+
+// ssoLogin 走完一次跳转、登录、回调
+func ssoLogin(t *testing.T, svc *DefaultOIDCService, idp *oidctest.Server, login string) (*model.OIDCLoginResult, error) {
+	ctx := context.Background()
+	start, err := svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	code, state, err := idp.Authorize(start.AuthURL, login)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	return svc.CompleteLogin(ctx, state, start.State, code)
+}
+
+func TestOIDCServiceLoginByIDClaim(t *testing.T) {
+	svc, idp, _ := newTestOIDCService(t)
+	idp.AddUser("alice", map[string]interface{}{"groups": []string{"all", "students"}, "student_number": "S001"})
+	idp.AddUser("mallory", map[string]interface{}{"groups": "students", "student_number": "S999"})
+	idp.AddUser("carol", map[string]interface{}{"groups": []string{"staff"}, "student_number": "S001"})
+
+	result, err := ssoLogin(t, svc, idp, "alice")
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if result.Role != model.RoleStudent || result.UserID != "S001" {
+		t.Errorf("Expected student S001, got %s %s", result.Role, result.UserID)
+	}
+
+	if _, err := ssoLogin(t, svc, idp, "mallory"); !errors.Is(err, ErrSSOAccountNotLinked) {
+		t.Errorf("Expected ErrSSOAccountNotLinked for unknown student, got %v", err)
+	}
+	if _, err := ssoLogin(t, svc, idp, "carol"); !errors.Is(err, ErrSSORoleNotMapped) {
+		t.Errorf("Expected ErrSSORoleNotMapped, got %v", err)
+	}
+}
+
+func TestOIDCServiceLoginByLinkedEmail(t *testing.T) {
+	ctx := context.Background()
+	svc, idp, repo := newTestOIDCService(t)
+	idp.AddUser("bob", map[string]interface{}{"sub": "idp-bob", "groups": "faculty", "email": "Bob@Uni.edu", "email_verified": true})
+	idp.AddUser("eve", map[string]interface{}{"sub": "idp-eve", "groups": "faculty", "email": "bob@uni.edu", "email_verified": false})
+
+	admin := model.Actor{UserID: "A001", Role: model.RoleAdmin}
+	if _, err := svc.LinkIdentity(ctx, admin, &model.OIDCIdentityRequest{Role: model.RoleInstructor, UserID: "I001", Email: "bob@uni.edu"}); err != nil {
+		t.Fatalf("LinkIdentity() error = %v", err)
+	}
+	if _, err := svc.LinkIdentity(ctx, admin, &model.OIDCIdentityRequest{Role: model.RoleInstructor, UserID: "I404", Email: "x@uni.edu"}); err == nil {
+		t.Error("Expected error linking unknown instructor")
+	}
+
+	// 未验证的邮箱不能匹配账号
+	if _, err := ssoLogin(t, svc, idp, "eve"); !errors.Is(err, ErrSSOAccountNotLinked) {
+		t.Errorf("Expected ErrSSOAccountNotLinked for unverified email, got %v", err)
+	}
+
+	result, err := ssoLogin(t, svc, idp, "bob")
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if result.Role != model.RoleInstructor || result.UserID != "I001" {
+		t.Errorf("Expected instructor I001, got %s %s", result.Role, result.UserID)
+	}
+	if repo.identities[0].Subject != "idp-bob" {
+		t.Errorf("Expected subject to be bound on first login, got %q", repo.identities[0].Subject)
+	}
+
+	// 绑定subject之后即使身份提供方修改了邮箱也能登录
+	idp.AddUser("bob", map[string]interface{}{"sub": "idp-bob", "groups": "faculty", "email": "robert@uni.edu", "email_verified": true})
+	if result, err := ssoLogin(t, svc, idp, "bob"); err != nil || result.UserID != "I001" {
+		t.Errorf("Expected login by subject, got %v %v", result, err)
+	}
+
+	if err := svc.UnlinkIdentity(ctx, admin, model.RoleInstructor, "I001"); err != nil {
+		t.Fatalf("UnlinkIdentity() error = %v", err)
+	}
+	if _, err := ssoLogin(t, svc, idp, "bob"); !errors.Is(err, ErrSSOAccountNotLinked) {
+		t.Errorf("Expected ErrSSOAccountNotLinked after unlink, got %v", err)
+	}
+}
+
+func TestOIDCServiceStateIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	svc, idp, _ := newTestOIDCService(t)
+	idp.AddUser("alice", map[string]interface{}{"groups": "students", "student_number": "S001"})
+
+	start, err := svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	code, state, err := idp.Authorize(start.AuthURL, "alice")
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	if _, err := svc.CompleteLogin(ctx, state+"x", start.State+"x", code); !errors.Is(err, ErrInvalidSSOState) {
+		t.Errorf("Expected ErrInvalidSSOState for tampered state, got %v", err)
+	}
+	// 回调被转到没有发起登录的浏览器（登录CSRF），或者浏览器中是另一次登录的state
+	if _, err := svc.CompleteLogin(ctx, state, "", code); !errors.Is(err, ErrInvalidSSOState) {
+		t.Errorf("Expected ErrInvalidSSOState without browser state, got %v", err)
+	}
+	other, err := svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	if _, err := svc.CompleteLogin(ctx, state, other.State, code); !errors.Is(err, ErrInvalidSSOState) {
+		t.Errorf("Expected ErrInvalidSSOState for another browser's state, got %v", err)
+	}
+	if _, err := svc.CompleteLogin(ctx, state, start.State, code); err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if _, err := svc.CompleteLogin(ctx, state, start.State, code); !errors.Is(err, ErrInvalidSSOState) {
+		t.Errorf("Expected ErrInvalidSSOState for replayed state, got %v", err)
+	}
+
+	// 超时未回调的state不能使用
+	start, err = svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	code, state, err = idp.Authorize(start.AuthURL, "alice")
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	svc.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if _, err := svc.CompleteLogin(ctx, state, start.State, code); !errors.Is(err, ErrInvalidSSOState) {
+		t.Errorf("Expected ErrInvalidSSOState for expired state, got %v", err)
+	}
+}
+
+func TestOIDCServiceDisabled(t *testing.T) {
+	ctx := context.Background()
+	svc := NewOIDCService(nil, nil, nil, nil, config.OIDCConfig{}, nil)
+	if _, err := svc.BeginLogin(ctx); !errors.Is(err, ErrSSODisabled) {
+		t.Errorf("Expected ErrSSODisabled, got %v", err)
+	}
+	if _, err := svc.CompleteLogin(ctx, "a.b", "a.b", "code"); !errors.Is(err, ErrSSODisabled) {
+		t.Errorf("Expected ErrSSODisabled, got %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/notify"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+var (
+	// ErrInvalidOldPassword 修改密码时旧密码不正确
+	ErrInvalidOldPassword = errors.New("invalid old password")
+
+	// ErrPasswordUnchanged 新密码与旧密码相同
+	ErrPasswordUnchanged = errors.New("new password must differ from the old password")
+
+	// ErrInvalidResetToken 重置令牌无效、过期或已使用
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+	// ErrUnknownRole 不支持的角色
+	ErrUnknownRole = errors.New("unknown role")
+)
+
+// PasswordService 定义密码自助服务接口，覆盖学生、教师和管理员三种角色
+type PasswordService interface {
+	MustChangePassword(ctx context.Context, userID string, role string) (bool, error)
+	ChangePassword(ctx context.Context, userID string, role string, req *model.ChangePasswordRequest) error
+	RequestReset(ctx context.Context, req *model.PasswordResetRequest) error
+	ResetPassword(ctx context.Context, req *model.PasswordResetConfirmRequest) error
+}
+
+// credentialStore 读取和更新某个角色账号的密码
+type credentialStore interface {
+	getCredentials(ctx context.Context, id string) (*model.Credentials, error)
+	setPassword(ctx context.Context, id string, hashedPassword string, mustChange bool) error
+}
+
+// studentCredentials 学生账号的密码存取
+type studentCredentials struct {
+	repo repository.StudentRepository
+}
+
+func (c studentCredentials) getCredentials(ctx context.Context, id string) (*model.Credentials, error) {
+	student, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Credentials{UserID: student.ID, Role: model.RoleStudent, PasswordHash: student.Password, MustChangePassword: student.MustChangePassword}, nil
+}
+
+func (c studentCredentials) setPassword(ctx context.Context, id string, hashedPassword string, mustChange bool) error {
+	// bcrypt哈希自带盐值，salt列不再使用
+	return c.repo.UpdatePassword(ctx, id, hashedPassword, "", mustChange)
+}
+
+// instructorCredentials 教师账号的密码存取
+type instructorCredentials struct {
+	repo repository.InstructorRepository
+}
+
+func (c instructorCredentials) getCredentials(ctx context.Context, id string) (*model.Credentials, error) {
+	instructor, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Credentials{UserID: instructor.ID, Role: model.RoleInstructor, PasswordHash: instructor.Password, MustChangePassword: instructor.MustChangePassword}, nil
+}
+
+func (c instructorCredentials) setPassword(ctx context.Context, id string, hashedPassword string, mustChange bool) error {
+	return c.repo.UpdatePassword(ctx, id, hashedPassword, "", mustChange)
+}
+
+// adminCredentials 管理员账号的密码存取，已停用的账号视为不存在
+type adminCredentials struct {
+	repo repository.AdminRepository
+	now  func() time.Time
+}
+
+func (c adminCredentials) getCredentials(ctx context.Context, id string) (*model.Credentials, error) {
+	admin, err := c.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if admin.Status != model.AdminStatusActive {
+		return nil, ErrAdminDisabled
+	}
+	return &model.Credentials{UserID: admin.ID, Role: model.RoleAdmin, PasswordHash: admin.Password, MustChangePassword: admin.MustChangePassword}, nil
+}
+
+func (c adminCredentials) setPassword(ctx context.Context, id string, hashedPassword string, mustChange bool) error {
+	return c.repo.UpdatePassword(ctx, id, hashedPassword, mustChange, c.now())
+}
+
+// DefaultPasswordService 实现PasswordService接口
+//
+// 重置令牌的格式为 "<令牌ID>.<随机串>"，数据库只保存随机串的SHA-256，令牌只能使用一次。
+// 修改或重置密码成功后作废其余重置令牌并吊销该用户的全部会话。
+type DefaultPasswordService struct {
+	stores    map[string]credentialStore
+	resetRepo repository.PasswordResetRepository
+	notifier  notify.Notifier
+	policy    utils.PasswordPolicy
+	resetTTL  time.Duration
+	sessions  SessionRevoker
+	audit     AuditRecorder
+	now       func() time.Time // 当前时间，测试中可替换
+}
+
+// NewPasswordService 创建密码自助服务实例
+func NewPasswordService(studentRepo repository.StudentRepository, instructorRepo repository.InstructorRepository, adminRepo repository.AdminRepository, resetRepo repository.PasswordResetRepository, notifier notify.Notifier, policy utils.PasswordPolicy, resetTTL time.Duration, sessions SessionRevoker, audit AuditRecorder) PasswordService {
+	if resetTTL <= 0 {
+		resetTTL = 30 * time.Minute
+	}
+	s := &DefaultPasswordService{
+		resetRepo: resetRepo,
+		notifier:  notifier,
+		policy:    policy,
+		resetTTL:  resetTTL,
+		sessions:  sessions,
+		audit:     auditRecorderOrNop(audit),
+		now:       time.Now,
+	}
+	s.stores = map[string]credentialStore{
+		model.RoleStudent:    studentCredentials{repo: studentRepo},
+		model.RoleInstructor: instructorCredentials{repo: instructorRepo},
+		model.RoleAdmin:      adminCredentials{repo: adminRepo, now: func() time.Time { return s.now() }},
+	}
+	return s
+}
+
+// MustChangePassword 判断账号是否必须先修改密码
+func (s *DefaultPasswordService) MustChangePassword(ctx context.Context, userID string, role string) (bool, error) {
+	store, err := s.store(role)
+	if err != nil {
+		return false, err
+	}
+
+	creds, err := store.getCredentials(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return creds.MustChangePassword, nil
+}
+
+// ChangePassword 验证旧密码后设置新密码
+func (s *DefaultPasswordService) ChangePassword(ctx context.Context, userID string, role string, req *model.ChangePasswordRequest) error {
+	store, err := s.store(role)
+	if err != nil {
+		return err
+	}
+
+	creds, err := store.getCredentials(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckPassword(req.OldPassword, creds.PasswordHash) {
+		return ErrInvalidOldPassword
+	}
+
+	if req.NewPassword == req.OldPassword {
+		return ErrPasswordUnchanged
+	}
+
+	return s.setPassword(ctx, store, userID, role, req.NewPassword)
+}
+
+// RequestReset 生成重置令牌并通过通知发送给用户
+// 账号不存在或已停用时也返回nil，避免通过该接口探测账号
+func (s *DefaultPasswordService) RequestReset(ctx context.Context, req *model.PasswordResetRequest) error {
+	store, err := s.store(req.Role)
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.getCredentials(ctx, req.UserID); err != nil {
+		return nil
+	}
+
+	now := s.now()
+
+	// 新令牌生效后，之前发出的令牌全部作废
+	if err := s.resetRepo.InvalidateByUser(ctx, req.UserID, req.Role, now); err != nil {
+		return err
+	}
+
+	tokenID, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return fmt.Errorf("error generating reset token: %w", err)
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return fmt.Errorf("error generating reset token: %w", err)
+	}
+
+	token := &model.PasswordResetToken{
+		ID:        tokenID,
+		UserID:    req.UserID,
+		Role:      req.Role,
+		TokenHash: hashTokenSecret(secret),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.resetTTL),
+	}
+
+	if err := s.resetRepo.Create(ctx, token); err != nil {
+		return err
+	}
+
+	return s.notifier.Send(&notify.Message{
+		UserID:  req.UserID,
+		Role:    req.Role,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Use this token to reset your password before %s: %s.%s",
+			token.ExpiresAt.Format(time.RFC3339), tokenID, secret),
+		SentAt: now,
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (s *DefaultPasswordService) ResetPassword(ctx context.Context, req *model.PasswordResetConfirmRequest) error {
+	tokenID, secret, ok := strings.Cut(req.Token, ".")
+	if !ok || tokenID == "" || secret == "" {
+		return ErrInvalidResetToken
+	}
+
+	token, err := s.resetRepo.FindByID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	now := s.now()
+	if !token.IsUsable(now) {
+		return ErrInvalidResetToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashTokenSecret(secret)), []byte(token.TokenHash)) != 1 {
+		return ErrInvalidResetToken
+	}
+
+	// 先检查密码策略，不满足时令牌仍可再次使用
+	if err := s.policy.Validate(req.NewPassword); err != nil {
+		return err
+	}
+
+	store, err := s.store(token.Role)
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.getCredentials(ctx, token.UserID); err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.resetRepo.MarkUsed(ctx, token.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	return s.setPassword(ctx, store, token.UserID, token.Role, req.NewPassword)
+}
+
+// setPassword 按策略检查并保存新密码，然后作废重置令牌、吊销会话
+// 修改和重置都由账号本人完成，审计日志只记录哪个账号改了密码，不记录密码本身
+func (s *DefaultPasswordService) setPassword(ctx context.Context, store credentialStore, userID string, role string, password string) error {
+	if err := s.policy.Validate(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	if err := store.setPassword(ctx, userID, hashedPassword, false); err != nil {
+		return err
+	}
+
+	if err := s.resetRepo.InvalidateByUser(ctx, userID, role, s.now()); err != nil {
+		return err
+	}
+
+	if err := s.sessions.RevokeUserSessions(ctx, userID, role); err != nil {
+		return err
+	}
+
+	actor := model.Actor{UserID: userID, Role: role}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityPassword, model.AuditKey(role, userID), nil, nil)
+}
+
+// store 返回角色对应的密码存取
+func (s *DefaultPasswordService) store(role string) (credentialStore, error) {
+	store, ok := s.stores[role]
+	if !ok {
+		return nil, ErrUnknownRole
+	}
+	return store, nil
+}
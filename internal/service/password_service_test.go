@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/internal/repository/memory"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/notify"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+// fakeNotifier 记录发送的通知
+type fakeNotifier struct {
+	messages []*notify.Message
+}
+
+func (n *fakeNotifier) Send(msg *notify.Message) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+// lastToken 从最近一条通知中取出重置令牌
+func (n *fakeNotifier) lastToken() string {
+	body := n.messages[len(n.messages)-1].Body
+	return body[strings.LastIndex(body, " ")+1:]
+}
+
+// fakePasswordResetRepository 基于内存的重置令牌仓库
+type fakePasswordResetRepository struct {
+	tokens map[string]*model.PasswordResetToken
+}
+
+func (r *fakePasswordResetRepository) Create(ctx context.Context, token *model.PasswordResetToken) error {
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *fakePasswordResetRepository) FindByID(ctx context.Context, id string) (*model.PasswordResetToken, error) {
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakePasswordResetRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil {
+		return repository.ErrNotFound
+	}
+	token.UsedAt = &usedAt
+	return nil
+}
+
+func (r *fakePasswordResetRepository) InvalidateByUser(ctx context.Context, userID string, role string, at time.Time) error {
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Role == role && token.UsedAt == nil {
+			token.UsedAt = &at
+		}
+	}
+	return nil
+}
+
+func newTestPasswordService(t *testing.T) (*DefaultPasswordService, repository.StudentRepository, *fakeNotifier, *fakeSessionRevoker) {
+	t.Helper()
+	ctx := context.Background()
+
+	store := memory.NewStore()
+	if err := memory.NewDepartmentRepository(store).Create(ctx, &model.Department{DeptName: "Comp. Sci.", Building: "Taylor", Budget: 100000}); err != nil {
+		t.Fatal(err)
+	}
+	students := memory.NewStudentRepository(store)
+	hashed, err := utils.HashPassword("Initial1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := students.Create(ctx, &model.Student{ID: "S001", Name: "Alice", Dept: "Comp. Sci.", Password: hashed, MustChangePassword: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := &fakeNotifier{}
+	revoker := &fakeSessionRevoker{}
+	policy := utils.NewPasswordPolicy(config.PasswordConfig{MinLength: 8, RequireDigit: true})
+	resets := &fakePasswordResetRepository{tokens: make(map[string]*model.PasswordResetToken)}
+
+	svc := NewPasswordService(students, nil, newFakeAdminRepository(), resets, notifier, policy, time.Hour, revoker, nil)
+	return svc.(*DefaultPasswordService), students, notifier, revoker
+}
+
+func TestPasswordService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	svc, students, _, revoker := newTestPasswordService(t)
+
+	if must, err := svc.MustChangePassword(ctx, "S001", model.RoleStudent); err != nil || !must {
+		t.Fatalf("MustChangePassword() = %v, %v, want true", must, err)
+	}
+
+	err := svc.ChangePassword(ctx, "S001", model.RoleStudent, &model.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "Changed22"})
+	if !errors.Is(err, ErrInvalidOldPassword) {
+		t.Errorf("Expected ErrInvalidOldPassword, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, "S001", model.RoleStudent, &model.ChangePasswordRequest{OldPassword: "Initial1", NewPassword: "nodigits"}); err == nil {
+		t.Error("Expected password policy violation")
+	}
+	if err := svc.ChangePassword(ctx, "S001", model.RoleStudent, &model.ChangePasswordRequest{OldPassword: "Initial1", NewPassword: "Changed22"}); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+
+	student, err := students.GetByID(ctx, "S001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !utils.CheckPassword("Changed22", student.Password) || student.MustChangePassword {
+		t.Error("Expected new password to be saved and forced change to be cleared")
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != "student/S001" {
+		t.Errorf("Expected sessions to be revoked, got %v", revoker.revoked)
+	}
+
+	if err := svc.ChangePassword(ctx, "S001", "guest", &model.ChangePasswordRequest{}); !errors.Is(err, ErrUnknownRole) {
+		t.Errorf("Expected ErrUnknownRole, got %v", err)
+	}
+}
+
+func TestPasswordService_Reset(t *testing.T) {
+	ctx := context.Background()
+	svc, students, notifier, revoker := newTestPasswordService(t)
+
+	// 不存在的账号不发送通知，也不返回错误
+	if err := svc.RequestReset(ctx, &model.PasswordResetRequest{UserID: "S999", Role: model.RoleStudent}); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	if len(notifier.messages) != 0 {
+		t.Fatal("Expected no notification for unknown account")
+	}
+
+	// 再次申请后，之前的令牌作废
+	if err := svc.RequestReset(ctx, &model.PasswordResetRequest{UserID: "S001", Role: model.RoleStudent}); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	first := notifier.lastToken()
+	if err := svc.RequestReset(ctx, &model.PasswordResetRequest{UserID: "S001", Role: model.RoleStudent}); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	second := notifier.lastToken()
+	if err := svc.ResetPassword(ctx, &model.PasswordResetConfirmRequest{Token: first, NewPassword: "Reset1234"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected superseded token to be rejected, got %v", err)
+	}
+
+	// 密码不满足策略时令牌不会被消耗
+	if err := svc.ResetPassword(ctx, &model.PasswordResetConfirmRequest{Token: second, NewPassword: "short"}); err == nil {
+		t.Error("Expected password policy violation")
+	}
+	if err := svc.ResetPassword(ctx, &model.PasswordResetConfirmRequest{Token: second + "x", NewPassword: "Reset1234"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected tampered token to be rejected, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, &model.PasswordResetConfirmRequest{Token: second, NewPassword: "Reset1234"}); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	student, err := students.GetByID(ctx, "S001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !utils.CheckPassword("Reset1234", student.Password) {
+		t.Error("Expected password to be reset")
+	}
+	if len(revoker.revoked) != 1 {
+		t.Errorf("Expected sessions to be revoked after reset, got %v", revoker.revoked)
+	}
+
+	// 令牌只能使用一次
+	if err := svc.ResetPassword(ctx, &model.PasswordResetConfirmRequest{Token: second, NewPassword: "Again1234"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected used token to be rejected, got %v", err)
+	}
+
+	// 过期的令牌不能使用
+	if err := svc.RequestReset(ctx, &model.PasswordResetRequest{UserID: "S001", Role: model.RoleStudent}); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	expired := notifier.lastToken()
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := svc.ResetPassword(ctx, &model.PasswordResetConfirmRequest{Token: expired, NewPassword: "Later1234"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected expired token to be rejected, got %v", err)
+	}
+}
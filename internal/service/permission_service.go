@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+var (
+	// ErrRoleNotFound 角色不存在
+	ErrRoleNotFound = errors.New("role not found")
+
+	// ErrRoleAssignmentNotFound 角色分配不存在
+	ErrRoleAssignmentNotFound = errors.New("role assignment not found")
+
+	// ErrRoleAlreadyAssigned 账号已经拥有该角色
+	ErrRoleAlreadyAssigned = errors.New("role already assigned")
+
+	// ErrDeptRequired 按系部授权的角色必须指定系部
+	ErrDeptRequired = errors.New("department is required for a department-scoped role")
+
+	// ErrDeptNotAllowed 不限系部的角色不能指定系部
+	ErrDeptNotAllowed = errors.New("department is not allowed for a global role")
+
+	// ErrOutOfScope 操作的数据不在权限范围内
+	ErrOutOfScope = errors.New("resource is outside the permission scope")
+)
+
+// PermissionService 定义权限服务接口
+type PermissionService interface {
+	// Permissions 计算账号的全部权限：登录角色自带的权限加上分配的角色
+	Permissions(ctx context.Context, userID string, role string) (model.PermissionSet, error)
+
+	// 系部范围检查，scope为nil或不覆盖数据所属系部时返回ErrOutOfScope
+	CheckDepartment(ctx context.Context, scope *model.PermissionScope, dept string) error
+	CheckCourse(ctx context.Context, scope *model.PermissionScope, courseID string) error
+	CheckSection(ctx context.Context, scope *model.PermissionScope, key model.SectionKey) error
+
+	// 角色管理
+	GetRoles(ctx context.Context) ([]*model.Role, error)
+	SaveRole(ctx context.Context, actor model.Actor, role *model.Role) error
+	DeleteRole(ctx context.Context, actor model.Actor, name string) error
+	ListAssignments(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.RoleAssignment], error)
+	AssignRole(ctx context.Context, actor model.Actor, req *model.RoleAssignmentRequest) (*model.RoleAssignment, error)
+	UnassignRole(ctx context.Context, actor model.Actor, id int64) error
+}
+
+// DefaultPermissionService 实现PermissionService接口
+type DefaultPermissionService struct {
+	roleRepo       repository.RoleRepository
+	courseRepo     repository.CourseRepository
+	departmentRepo repository.DepartmentRepository
+	uow            repository.UnitOfWork
+	audit          AuditRecorder
+	now            func() time.Time // 当前时间，测试中可替换
+}
+
+// NewPermissionService 创建权限服务实例
+func NewPermissionService(roleRepo repository.RoleRepository, courseRepo repository.CourseRepository, departmentRepo repository.DepartmentRepository, uow repository.UnitOfWork, audit AuditRecorder) PermissionService {
+	return &DefaultPermissionService{
+		roleRepo:       roleRepo,
+		courseRepo:     courseRepo,
+		departmentRepo: departmentRepo,
+		uow:            uow,
+		audit:          auditRecorderOrNop(audit),
+		now:            time.Now,
+	}
+}
+
+// Permissions 计算账号的全部权限
+// 学生和教师自带的权限只作用于本人的数据；管理员自带的权限和不限系部的角色分配全局有效
+func (s *DefaultPermissionService) Permissions(ctx context.Context, userID string, role string) (model.PermissionSet, error) {
+	permissions := make(model.PermissionSet)
+	for _, p := range model.BasePermissions[role] {
+		permissions.Grant(p, "", role != model.RoleAdmin)
+	}
+
+	grants, err := s.roleRepo.FindGrants(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range grants {
+		// 不支持系部范围的权限只能全局授予，忽略按系部授予的记录
+		if grant.Dept != "" && !model.IsDeptScopedPermission(grant.Permission) {
+			continue
+		}
+		permissions.Grant(grant.Permission, grant.Dept, false)
+	}
+
+	return permissions, nil
+}
+
+// CheckDepartment 检查权限是否覆盖系部
+func (s *DefaultPermissionService) CheckDepartment(ctx context.Context, scope *model.PermissionScope, dept string) error {
+	if !scope.Allows(dept) {
+		return ErrOutOfScope
+	}
+	return nil
+}
+
+// CheckCourse 检查权限是否覆盖课程所属的系部，课程不存在时只有全局权限可以通过
+func (s *DefaultPermissionService) CheckCourse(ctx context.Context, scope *model.PermissionScope, courseID string) error {
+	if scope != nil && scope.Global {
+		return nil
+	}
+	if scope == nil || len(scope.Depts) == 0 {
+		return ErrOutOfScope
+	}
+
+	course, err := s.courseRepo.FindByID(ctx, courseID)
+	if err != nil || course == nil {
+		return ErrOutOfScope
+	}
+
+	return s.CheckDepartment(ctx, scope, course.Dept)
+}
+
+// CheckSection 检查权限是否覆盖课程段所属课程的系部
+func (s *DefaultPermissionService) CheckSection(ctx context.Context, scope *model.PermissionScope, key model.SectionKey) error {
+	return s.CheckCourse(ctx, scope, key.CourseID)
+}
+
+// GetRoles 获取所有角色
+func (s *DefaultPermissionService) GetRoles(ctx context.Context) ([]*model.Role, error) {
+	return s.roleRepo.FindAllRoles(ctx)
+}
+
+// SaveRole 创建或更新角色，角色信息和权限在同一个事务中保存
+func (s *DefaultPermissionService) SaveRole(ctx context.Context, actor model.Actor, role *model.Role) error {
+	if err := role.Validate(); err != nil {
+		return err
+	}
+
+	var before *model.Role
+	err := s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		existing, err := repos.Roles.FindRole(ctx, role.Name)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		before = existing
+
+		if err := repos.Roles.SaveRole(ctx, role); err != nil {
+			return err
+		}
+		return repos.Roles.ReplacePermissions(ctx, role.Name, role.Permissions)
+	})
+	if err != nil {
+		return err
+	}
+
+	if before == nil {
+		return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityRole, role.Name, nil, role)
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityRole, role.Name, before, role)
+}
+
+// DeleteRole 删除角色，已分配的账号随之失去该角色
+func (s *DefaultPermissionService) DeleteRole(ctx context.Context, actor model.Actor, name string) error {
+	role, err := s.roleRepo.FindRole(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	if err := s.roleRepo.DeleteRole(ctx, name); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityRole, name, role, nil)
+}
+
+// ListAssignments 分页查询角色分配，可以按账号、角色或院系过滤
+func (s *DefaultPermissionService) ListAssignments(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.RoleAssignment], error) {
+	return s.roleRepo.ListAssignments(ctx, q)
+}
+
+// AssignRole 为账号分配角色
+func (s *DefaultPermissionService) AssignRole(ctx context.Context, actor model.Actor, req *model.RoleAssignmentRequest) (*model.RoleAssignment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.FindRole(ctx, req.RoleName)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	if role.DeptScoped && req.Dept == "" {
+		return nil, ErrDeptRequired
+	}
+	if !role.DeptScoped && req.Dept != "" {
+		return nil, ErrDeptNotAllowed
+	}
+	if req.Dept != "" {
+		if _, err := s.departmentRepo.FindByDepartment(ctx, req.Dept); err != nil {
+			return nil, fmt.Errorf("department not found: %w", err)
+		}
+	}
+
+	assignment := &model.RoleAssignment{
+		UserID:    req.UserID,
+		UserRole:  req.UserRole,
+		RoleName:  role.Name,
+		Dept:      req.Dept,
+		CreatedAt: s.now(),
+	}
+
+	if err := s.roleRepo.CreateAssignment(ctx, assignment); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrRoleAlreadyAssigned
+		}
+		return nil, err
+	}
+
+	if err := s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityRoleAssignment, strconv.FormatInt(assignment.ID, 10), nil, assignment); err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+// UnassignRole 撤销角色分配
+func (s *DefaultPermissionService) UnassignRole(ctx context.Context, actor model.Actor, id int64) error {
+	assignment, err := s.roleRepo.FindAssignment(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRoleAssignmentNotFound
+		}
+		return err
+	}
+
+	if err := s.roleRepo.DeleteAssignment(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRoleAssignmentNotFound
+		}
+		return err
+	}
+
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityRoleAssignment, strconv.FormatInt(id, 10), assignment, nil)
+}
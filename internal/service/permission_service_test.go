@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+)
+
+// fakeRoleRepository 基于内存的角色仓库
+type fakeRoleRepository struct {
+	repository.RoleRepository
+	roles       map[string]*model.Role
+	assignments []*model.RoleAssignment
+}
+
+func (r *fakeRoleRepository) FindRole(ctx context.Context, name string) (*model.Role, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepository) CreateAssignment(ctx context.Context, assignment *model.RoleAssignment) error {
+	for _, existing := range r.assignments {
+		if existing.UserID == assignment.UserID && existing.UserRole == assignment.UserRole &&
+			existing.RoleName == assignment.RoleName && existing.Dept == assignment.Dept {
+			return repository.ErrDuplicate
+		}
+	}
+	assignment.ID = int64(len(r.assignments) + 1)
+	r.assignments = append(r.assignments, assignment)
+	return nil
+}
+
+func (r *fakeRoleRepository) FindGrants(ctx context.Context, userID string, userRole string) ([]model.PermissionGrant, error) {
+	var grants []model.PermissionGrant
+	for _, assignment := range r.assignments {
+		if assignment.UserID != userID || assignment.UserRole != userRole {
+			continue
+		}
+		for _, p := range r.roles[assignment.RoleName].Permissions {
+			grants = append(grants, model.PermissionGrant{Permission: p, Dept: assignment.Dept})
+		}
+	}
+	return grants, nil
+}
+
+// fakeCatalogRepository 提供课程查询
+type fakeCatalogRepository struct {
+	repository.CourseRepository
+	courses map[string]*model.Course
+}
+
+func (r *fakeCatalogRepository) FindByID(ctx context.Context, id string) (*model.Course, error) {
+	course, ok := r.courses[id]
+	if !ok {
+		return nil, errors.New("course not found")
+	}
+	return course, nil
+}
+
+// fakeDepartmentRepository 提供系部查询
+type fakeDepartmentRepository struct {
+	repository.DepartmentRepository
+	depts []string
+}
+
+func (r *fakeDepartmentRepository) FindByDepartment(ctx context.Context, deptName string) (*model.Department, error) {
+	for _, dept := range r.depts {
+		if dept == deptName {
+			return &model.Department{DeptName: deptName}, nil
+		}
+	}
+	return nil, errors.New("department not found")
+}
+
+func newTestPermissionService() (PermissionService, *fakeRoleRepository) {
+	roles := &fakeRoleRepository{roles: map[string]*model.Role{
+		"registrar": {Name: "registrar", Permissions: []model.Permission{model.PermStudentsManage, model.PermSectionsManage}},
+		"department_chair": {Name: "department_chair", DeptScoped: true, Permissions: []model.Permission{
+			model.PermCoursesManage, model.PermSectionsManage, model.PermGradesWrite,
+		}},
+	}}
+	catalog := &fakeCatalogRepository{courses: map[string]*model.Course{
+		"CS101":   {ID: "CS101", Dept: "CS"},
+		"MATH101": {ID: "MATH101", Dept: "MATH"},
+	}}
+	departments := &fakeDepartmentRepository{depts: []string{"CS", "MATH"}}
+	return NewPermissionService(roles, catalog, departments, nil, nil), roles
+}
+
+func TestPermissionServicePermissions(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestPermissionService()
+
+	if _, err := svc.AssignRole(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, &model.RoleAssignmentRequest{UserID: "I001", UserRole: model.RoleInstructor, RoleName: "department_chair", Dept: "CS"}); err != nil {
+		t.Fatalf("AssignRole() error = %v", err)
+	}
+
+	chair, err := svc.Permissions(ctx, "I001", model.RoleInstructor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !chair.Has(model.PermInstructorPortal) || chair.Has(model.PermStudentsManage) {
+		t.Errorf("Expected instructor base permissions only plus chair role, got %v", chair)
+	}
+
+	// 系主任只能管理本系的课程
+	scope := chair.Scope(model.PermCoursesManage)
+	if err := svc.CheckCourse(ctx, scope, "CS101"); err != nil {
+		t.Errorf("Expected CS course in scope, got %v", err)
+	}
+	if err := svc.CheckCourse(ctx, scope, "MATH101"); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("Expected MATH course out of scope, got %v", err)
+	}
+	if err := svc.CheckCourse(ctx, scope, "NOPE"); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("Expected unknown course out of scope, got %v", err)
+	}
+	if err := svc.CheckSection(ctx, chair.Scope(model.PermGradesWrite), model.SectionKey{CourseID: "CS101"}); err != nil {
+		t.Errorf("Expected chair to write grades for CS sections, got %v", err)
+	}
+
+	// 普通教师的grades.write只作用于自己任教的课程段，不覆盖任何系部
+	plain, _ := svc.Permissions(ctx, "I002", model.RoleInstructor)
+	if !plain.Has(model.PermGradesWrite) {
+		t.Fatal("Expected instructors to have grades.write")
+	}
+	if err := svc.CheckSection(ctx, plain.Scope(model.PermGradesWrite), model.SectionKey{CourseID: "CS101"}); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("Expected own-only scope to be out of scope, got %v", err)
+	}
+
+	// 管理员拥有全部权限且不限系部
+	admin, _ := svc.Permissions(ctx, "root", model.RoleAdmin)
+	for _, p := range model.AllPermissions {
+		if !admin.Scope(p).Allows("MATH") {
+			t.Errorf("Expected admin to have global %s", p)
+		}
+	}
+
+	// 学生默认不能进入教师和管理接口
+	student, _ := svc.Permissions(ctx, "S001", model.RoleStudent)
+	if student.Has(model.PermGradesWrite) || student.Has(model.PermCoursesManage) || !student.Has(model.PermStudentPortal) {
+		t.Errorf("Unexpected student permissions %v", student)
+	}
+}
+
+func TestPermissionServiceAssignRole(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestPermissionService()
+
+	tests := []struct {
+		name string
+		req  model.RoleAssignmentRequest
+		want error
+	}{
+		{"unknown role", model.RoleAssignmentRequest{UserID: "I001", UserRole: model.RoleInstructor, RoleName: "dean"}, ErrRoleNotFound},
+		{"scoped role without dept", model.RoleAssignmentRequest{UserID: "I001", UserRole: model.RoleInstructor, RoleName: "department_chair"}, ErrDeptRequired},
+		{"global role with dept", model.RoleAssignmentRequest{UserID: "A001", UserRole: model.RoleAdmin, RoleName: "registrar", Dept: "CS"}, ErrDeptNotAllowed},
+	}
+	for _, tt := range tests {
+		if _, err := svc.AssignRole(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, &tt.req); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	req := &model.RoleAssignmentRequest{UserID: "S001", UserRole: model.RoleStudent, RoleName: "registrar"}
+	if _, err := svc.AssignRole(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, req); err != nil {
+		t.Fatalf("AssignRole() error = %v", err)
+	}
+	if _, err := svc.AssignRole(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, req); !errors.Is(err, ErrRoleAlreadyAssigned) {
+		t.Errorf("Expected ErrRoleAlreadyAssigned, got %v", err)
+	}
+
+	// 角色分配到账号的登录角色上，同ID的其他登录角色不受影响
+	registrar, _ := svc.Permissions(ctx, "S001", model.RoleStudent)
+	if !registrar.Scope(model.PermSectionsManage).Allows("MATH") {
+		t.Error("Expected registrar to manage sections of every department")
+	}
+	other, _ := svc.Permissions(ctx, "S001", model.RoleInstructor)
+	if other.Has(model.PermStudentsManage) {
+		t.Error("Expected role assignment to be bound to the login role")
+	}
+
+	// 按系部授权的角色不能包含全局权限
+	err := svc.SaveRole(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, &model.Role{Name: "dept_registrar", DeptScoped: true, Permissions: []model.Permission{model.PermStudentsManage}})
+	if err == nil {
+		t.Error("Expected error for department-scoped role with a global-only permission")
+	}
+}
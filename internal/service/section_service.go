@@ -1,8 +1,10 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/yourusername/student-management-system/internal/model"
 	"github.com/yourusername/student-management-system/internal/repository"
@@ -10,15 +12,16 @@ import (
 
 // SectionService 定义课程章节服务接口
 type SectionService interface {
-	GetSectionByID(id string) (*model.Section, error)
-	GetAllSections() ([]*model.Section, error)
-	GetSectionsByCourseID(courseID string) ([]*model.Section, error)
-	GetSectionsByParams(params *model.SectionQueryParams) ([]*model.Section, error)
-	CreateSection(req *model.SectionCreateRequest) error
-	UpdateSection(id string, req *model.SectionUpdateRequest) error
-	DeleteSection(id string) error
-	GetSectionWithDetails(id string) (*model.Section, error)
-	GetSections(courseID, semester string, year int, instructorID string) ([]*model.Section, error)
+	GetSectionByID(ctx context.Context, key model.SectionKey) (*model.Section, error)
+	GetAllSections(ctx context.Context) ([]*model.Section, error)
+	GetSectionsByCourseID(ctx context.Context, courseID string) ([]*model.Section, error)
+	GetSectionsByParams(ctx context.Context, params *model.SectionQueryParams) ([]*model.Section, error)
+	CreateSection(ctx context.Context, req *model.SectionCreateRequest) error
+	UpdateSection(ctx context.Context, key model.SectionKey, req *model.SectionUpdateRequest) error
+	DeleteSection(ctx context.Context, key model.SectionKey) error
+	GetSectionWithDetails(ctx context.Context, key model.SectionKey) (*model.Section, error)
+	ListSections(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Section], error)
+	ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error)
 }
 
 // DefaultSectionService 实现SectionService接口
@@ -39,41 +42,35 @@ func NewSectionService(sectionRepo repository.SectionRepository, courseRepo repo
 	}
 }
 
-// GetSectionByID 根据ID获取课程章节
-func (s *DefaultSectionService) GetSectionByID(id string) (*model.Section, error) {
-	return s.sectionRepo.FindByID(id)
+// GetSectionByID 根据主键获取课程章节
+func (s *DefaultSectionService) GetSectionByID(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	return s.sectionRepo.FindByID(ctx, key)
 }
 
 // GetAllSections 获取所有课程章节
-func (s *DefaultSectionService) GetAllSections() ([]*model.Section, error) {
-	return s.sectionRepo.FindAll()
+func (s *DefaultSectionService) GetAllSections(ctx context.Context) ([]*model.Section, error) {
+	return s.sectionRepo.FindAll(ctx)
 }
 
 // GetSectionsByCourseID 根据课程ID获取章节列表
-func (s *DefaultSectionService) GetSectionsByCourseID(courseID string) ([]*model.Section, error) {
-	return s.sectionRepo.FindByCourseID(courseID)
+func (s *DefaultSectionService) GetSectionsByCourseID(ctx context.Context, courseID string) ([]*model.Section, error) {
+	return s.sectionRepo.FindByCourseID(ctx, courseID)
 }
 
 // GetSectionsByParams 根据参数获取章节列表
-func (s *DefaultSectionService) GetSectionsByParams(params *model.SectionQueryParams) ([]*model.Section, error) {
-	return s.sectionRepo.FindByParams(params)
+func (s *DefaultSectionService) GetSectionsByParams(ctx context.Context, params *model.SectionQueryParams) ([]*model.Section, error) {
+	return s.sectionRepo.FindByParams(ctx, params)
 }
 
-// GetSections 根据参数获取章节列表
-func (s *DefaultSectionService) GetSections(courseID, semester string, year int, instructorID string) ([]*model.Section, error) {
-	params := &model.SectionQueryParams{
-		CourseID:     courseID,
-		Semester:     semester,
-		Year:         year,
-		InstructorID: instructorID,
-	}
-	return s.sectionRepo.FindByParams(params)
+// ListSections 按分页、排序和过滤条件查询章节
+func (s *DefaultSectionService) ListSections(ctx context.Context, q *model.ListQuery) (*model.ListResult[model.Section], error) {
+	return s.sectionRepo.List(ctx, q)
 }
 
 // CreateSection 创建课程章节
-func (s *DefaultSectionService) CreateSection(req *model.SectionCreateRequest) error {
+func (s *DefaultSectionService) CreateSection(ctx context.Context, req *model.SectionCreateRequest) error {
 	// 验证课程是否存在
-	course, err := s.courseRepo.FindByID(req.CourseID)
+	course, err := s.courseRepo.FindByID(ctx, req.CourseID)
 	if err != nil {
 		return fmt.Errorf("course not found: %w", err)
 	}
@@ -82,7 +79,7 @@ func (s *DefaultSectionService) CreateSection(req *model.SectionCreateRequest) e
 	}
 
 	// 验证教室是否存在
-	classroom, err := s.classroomRepo.FindByBuildingAndRoom(req.Building, req.RoomNumber)
+	classroom, err := s.classroomRepo.FindByBuildingAndRoom(ctx, req.Building, req.RoomNumber)
 	if err != nil {
 		return fmt.Errorf("classroom not found: %w", err)
 	}
@@ -91,7 +88,7 @@ func (s *DefaultSectionService) CreateSection(req *model.SectionCreateRequest) e
 	}
 
 	// 验证时间段是否存在
-	timeSlot, err := s.timeSlotRepo.FindByID(req.TimeSlotID)
+	timeSlot, err := s.timeSlotRepo.FindByID(ctx, req.TimeSlotID)
 	if err != nil {
 		return fmt.Errorf("time slot not found: %w", err)
 	}
@@ -111,12 +108,12 @@ func (s *DefaultSectionService) CreateSection(req *model.SectionCreateRequest) e
 		Enrollment: 0,
 	}
 
-	return s.sectionRepo.Create(section)
+	return s.sectionRepo.Create(ctx, section)
 }
 
-// UpdateSection 更新课程章节
-func (s *DefaultSectionService) UpdateSection(id string, req *model.SectionUpdateRequest) error {
-	section, err := s.sectionRepo.FindByID(id)
+// UpdateSection 更新课程章节，学期和年份属于主键，只能修改教室和时间段
+func (s *DefaultSectionService) UpdateSection(ctx context.Context, key model.SectionKey, req *model.SectionUpdateRequest) error {
+	section, err := s.sectionRepo.FindByID(ctx, key)
 	if err != nil {
 		return fmt.Errorf("section not found: %w", err)
 	}
@@ -125,12 +122,6 @@ func (s *DefaultSectionService) UpdateSection(id string, req *model.SectionUpdat
 	}
 
 	// 更新字段
-	if req.Semester != "" {
-		section.Semester = req.Semester
-	}
-	if req.Year != 0 {
-		section.Year = req.Year
-	}
 	if req.Building != "" {
 		section.Building = req.Building
 	}
@@ -139,7 +130,7 @@ func (s *DefaultSectionService) UpdateSection(id string, req *model.SectionUpdat
 	}
 	if req.TimeSlotID != "" {
 		// 验证时间段是否存在
-		timeSlot, err := s.timeSlotRepo.FindByID(req.TimeSlotID)
+		timeSlot, err := s.timeSlotRepo.FindByID(ctx, req.TimeSlotID)
 		if err != nil {
 			return fmt.Errorf("time slot not found: %w", err)
 		}
@@ -149,15 +140,60 @@ func (s *DefaultSectionService) UpdateSection(id string, req *model.SectionUpdat
 		section.TimeSlotID = req.TimeSlotID
 	}
 
-	return s.sectionRepo.Update(section)
+	return s.sectionRepo.Update(ctx, section)
 }
 
 // DeleteSection 删除课程章节
-func (s *DefaultSectionService) DeleteSection(id string) error {
-	return s.sectionRepo.Delete(id)
+func (s *DefaultSectionService) DeleteSection(ctx context.Context, key model.SectionKey) error {
+	return s.sectionRepo.Delete(ctx, key)
 }
 
 // GetSectionWithDetails 获取章节详细信息
-func (s *DefaultSectionService) GetSectionWithDetails(id string) (*model.Section, error) {
-	return s.sectionRepo.FindWithDetails(id)
+func (s *DefaultSectionService) GetSectionWithDetails(ctx context.Context, key model.SectionKey) (*model.Section, error) {
+	return s.sectionRepo.FindWithDetails(ctx, key)
+}
+
+// ResolveSection 将请求中的课程段引用解析为完整主键
+func (s *DefaultSectionService) ResolveSection(ctx context.Context, ref *model.SectionRef) (model.SectionKey, error) {
+	return resolveSectionRef(ctx, s.sectionRepo, ref)
+}
+
+// resolveSectionRef 解析课程段引用：完整主键直接返回；旧客户端只提供sec_id时，
+// 按sec_id查找并用请求中已填写的course_id、semester、year过滤，唯一匹配时返回其主键
+func resolveSectionRef(ctx context.Context, sectionRepo repository.SectionRepository, ref *model.SectionRef) (model.SectionKey, error) {
+	key, ok := ref.Key()
+	if ok {
+		return key, nil
+	}
+	if key.SecID == "" {
+		return model.SectionKey{}, key.Validate()
+	}
+
+	sections, err := sectionRepo.FindBySecID(ctx, key.SecID)
+	if err != nil {
+		return model.SectionKey{}, fmt.Errorf("error finding section: %w", err)
+	}
+
+	var matches []model.SectionKey
+	for _, section := range sections {
+		if (key.CourseID != "" && section.CourseID != key.CourseID) ||
+			(key.Semester != "" && section.Semester != key.Semester) ||
+			(key.Year != 0 && section.Year != key.Year) {
+			continue
+		}
+		matches = append(matches, section.Key())
+	}
+
+	switch len(matches) {
+	case 0:
+		return model.SectionKey{}, ErrSectionNotFound
+	case 1:
+		return matches[0], nil
+	default:
+		candidates := make([]string, 0, len(matches))
+		for _, match := range matches {
+			candidates = append(candidates, match.String())
+		}
+		return model.SectionKey{}, fmt.Errorf("%w: %s matches %s", ErrAmbiguousSection, key.SecID, strings.Join(candidates, ", "))
+	}
 }
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌无效、过期或会话已吊销
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRefreshTokenReused 已经轮换过的刷新令牌被再次使用，会话已被吊销
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// SessionRevoker 吊销用户的全部登录会话，修改密码或删除账号后使用
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID string, role string) error
+}
+
+// SessionService 定义登录会话服务接口
+type SessionService interface {
+	SessionRevoker
+	StartSession(ctx context.Context, userID string, username string, role string, passwordChangeRequired bool) (*model.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error)
+	Logout(ctx context.Context, sessionID string) error
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// DefaultSessionService 实现SessionService接口
+//
+// 刷新令牌的格式为 "<会话ID>.<随机串>"，数据库只保存随机串的SHA-256。
+// 每次刷新都会换发新的刷新令牌，已轮换的旧令牌再次出现说明可能已泄露，此时吊销整个会话；
+// 其他不匹配的令牌只视为无效。
+type DefaultSessionService struct {
+	sessionRepo repository.SessionRepository
+	jwtManager  *utils.JWTManager
+	refreshTTL  time.Duration
+	now         func() time.Time // 当前时间，测试中可替换
+}
+
+// NewSessionService 创建登录会话服务实例
+func NewSessionService(sessionRepo repository.SessionRepository, jwtManager *utils.JWTManager, refreshTTL time.Duration) SessionService {
+	if refreshTTL <= 0 {
+		refreshTTL = 14 * 24 * time.Hour
+	}
+	return &DefaultSessionService{
+		sessionRepo: sessionRepo,
+		jwtManager:  jwtManager,
+		refreshTTL:  refreshTTL,
+		now:         time.Now,
+	}
+}
+
+// StartSession 登录成功后创建会话并签发令牌
+// passwordChangeRequired 为true时签发的令牌只能用于修改密码，刷新后仍然如此
+func (s *DefaultSessionService) StartSession(ctx context.Context, userID string, username string, role string, passwordChangeRequired bool) (*model.TokenPair, error) {
+	sessionID, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("error generating session id: %w", err)
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating refresh token: %w", err)
+	}
+
+	now := s.now()
+	session := &model.Session{
+		ID:                     sessionID,
+		UserID:                 userID,
+		Role:                   role,
+		Username:               username,
+		RefreshHash:            hashTokenSecret(secret),
+		CreatedAt:              now,
+		PasswordChangeRequired: passwordChangeRequired,
+		LastUsedAt:             now,
+		ExpiresAt:              now.Add(s.refreshTTL),
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issue(session, secret)
+}
+
+// Refresh 用刷新令牌换取新的访问令牌和刷新令牌
+func (s *DefaultSessionService) Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	sessionID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	if !session.IsActive(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	oldHash := hashTokenSecret(secret)
+	if oldHash != session.RefreshHash {
+		// 会话ID随访问令牌公开，只有已轮换的旧令牌才说明泄露；随意伪造的令牌不能注销用户
+		reused, err := s.sessionRepo.WasRotated(ctx, session.ID, oldHash)
+		if err != nil {
+			return nil, err
+		}
+		if reused {
+			return nil, s.revokeReusedSession(ctx, session.ID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newSecret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating refresh token: %w", err)
+	}
+
+	session.RefreshHash = hashTokenSecret(newSecret)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshTTL)
+
+	err = s.sessionRepo.RotateRefreshHash(ctx, session.ID, oldHash, session.RefreshHash, session.LastUsedAt, session.ExpiresAt)
+	if err != nil {
+		// 另一个请求已经用同一个令牌完成了刷新
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, s.revokeReusedSession(ctx, session.ID)
+		}
+		return nil, err
+	}
+
+	return s.issue(session, newSecret)
+}
+
+// Logout 吊销当前会话
+func (s *DefaultSessionService) Logout(ctx context.Context, sessionID string) error {
+	return s.sessionRepo.Revoke(ctx, sessionID, s.now())
+}
+
+// RevokeUserSessions 吊销某个用户的全部会话
+func (s *DefaultSessionService) RevokeUserSessions(ctx context.Context, userID string, role string) error {
+	_, err := s.sessionRepo.RevokeByUser(ctx, userID, role, s.now())
+	return err
+}
+
+// IsSessionActive 判断访问令牌所属的会话是否仍然有效
+func (s *DefaultSessionService) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return session.IsActive(s.now()), nil
+}
+
+// issue 为会话签发访问令牌，并组合刷新令牌
+func (s *DefaultSessionService) issue(session *model.Session, secret string) (*model.TokenPair, error) {
+	accessToken, err := s.jwtManager.Generate(utils.JWTClaims{
+		UserID:                 session.UserID,
+		Username:               session.Username,
+		Role:                   session.Role,
+		SessionID:              session.ID,
+		PasswordChangeRequired: session.PasswordChangeRequired,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generating access token: %w", err)
+	}
+
+	return &model.TokenPair{
+		AccessToken:            accessToken,
+		RefreshToken:           session.ID + "." + secret,
+		ExpiresIn:              int64(s.jwtManager.Expiration().Seconds()),
+		SessionID:              session.ID,
+		PasswordChangeRequired: session.PasswordChangeRequired,
+	}, nil
+}
+
+// revokeReusedSession 检测到刷新令牌被重复使用时吊销会话
+func (s *DefaultSessionService) revokeReusedSession(ctx context.Context, sessionID string) error {
+	if err := s.sessionRepo.Revoke(ctx, sessionID, s.now()); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// hashTokenSecret 计算刷新令牌、重置令牌中随机串的哈希
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+// fakeSessionRevoker 记录被吊销会话的用户，格式为 "role/userID"
+type fakeSessionRevoker struct {
+	revoked []string
+}
+
+func (r *fakeSessionRevoker) RevokeUserSessions(ctx context.Context, userID string, role string) error {
+	r.revoked = append(r.revoked, role+"/"+userID)
+	return nil
+}
+
+// fakeSessionRepository 基于内存的会话仓库
+type fakeSessionRepository struct {
+	sessions map[string]*model.Session
+	rotated  map[string]bool // 格式为 "会话ID/哈希"
+}
+
+func (r *fakeSessionRepository) Create(ctx context.Context, session *model.Session) error {
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *fakeSessionRepository) FindByID(ctx context.Context, id string) (*model.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *fakeSessionRepository) RotateRefreshHash(ctx context.Context, id string, oldHash string, newHash string, usedAt time.Time, expiresAt time.Time) error {
+	session, ok := r.sessions[id]
+	if !ok || session.RefreshHash != oldHash || session.RevokedAt != nil {
+		return repository.ErrNotFound
+	}
+	if r.rotated == nil {
+		r.rotated = make(map[string]bool)
+	}
+	r.rotated[id+"/"+oldHash] = true
+	session.RefreshHash = newHash
+	session.LastUsedAt = usedAt
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *fakeSessionRepository) WasRotated(ctx context.Context, id string, hash string) (bool, error) {
+	return r.rotated[id+"/"+hash], nil
+}
+
+func (r *fakeSessionRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &revokedAt
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeByUser(ctx context.Context, userID string, role string, revokedAt time.Time) (int64, error) {
+	var count int64
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.Role == role && session.RevokedAt == nil {
+			session.RevokedAt = &revokedAt
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestSessionService(t *testing.T) {
+	ctx := context.Background()
+	jwtManager, err := utils.NewJWTManager(config.JWTConfig{Secret: "test-secret", Expiration: 900})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &fakeSessionRepository{sessions: make(map[string]*model.Session)}
+	svc := NewSessionService(repo, jwtManager, time.Hour)
+
+	first, err := svc.StartSession(ctx, "S001", "S001", model.RoleStudent, false)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	claims, err := jwtManager.Validate(first.AccessToken)
+	if err != nil || claims.SessionID != first.SessionID {
+		t.Fatalf("Expected access token bound to session %s, got %+v, %v", first.SessionID, claims, err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, first.SessionID); !active {
+		t.Fatal("Expected new session to be active")
+	}
+
+	// 刷新后换发新的刷新令牌，会话不变
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.SessionID != first.SessionID || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("Expected rotated refresh token for the same session")
+	}
+
+	// 会话ID是公开的，伪造的随机串只是无效令牌，不会注销用户
+	if _, err := svc.Refresh(ctx, first.SessionID+".forged"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Expected ErrInvalidRefreshToken for a forged secret, got %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, first.SessionID); !active {
+		t.Fatal("Expected session to stay active after a forged refresh token")
+	}
+
+	// 旧的刷新令牌再次出现时吊销整个会话
+	if _, err := svc.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Expected ErrRefreshTokenReused, got %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, first.SessionID); active {
+		t.Error("Expected session to be revoked after refresh token reuse")
+	}
+	if _, err := svc.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken after revocation, got %v", err)
+	}
+
+	// 注销只影响当前会话
+	laptop, _ := svc.StartSession(ctx, "S001", "S001", model.RoleStudent, false)
+	phone, _ := svc.StartSession(ctx, "S001", "S001", model.RoleStudent, false)
+	other, _ := svc.StartSession(ctx, "S001", "S001", model.RoleInstructor, false)
+	if err := svc.Logout(ctx, laptop.SessionID); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, laptop.SessionID); active {
+		t.Error("Expected logged out session to be inactive")
+	}
+	if active, _ := svc.IsSessionActive(ctx, phone.SessionID); !active {
+		t.Error("Expected other session to stay active after logout")
+	}
+
+	// 吊销用户的全部会话，同ID的其他角色不受影响
+	if err := svc.RevokeUserSessions(ctx, "S001", model.RoleStudent); err != nil {
+		t.Fatalf("RevokeUserSessions() error = %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, phone.SessionID); active {
+		t.Error("Expected all student sessions to be revoked")
+	}
+	if active, _ := svc.IsSessionActive(ctx, other.SessionID); !active {
+		t.Error("Expected sessions of another role to stay active")
+	}
+
+	// 过期的刷新令牌不能使用
+	expired, _ := svc.StartSession(ctx, "I001", "I001", model.RoleInstructor, false)
+	svc.(*DefaultSessionService).now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := svc.Refresh(ctx, expired.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken for expired session, got %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, ""); active {
+		t.Error("Expected token without session id to be rejected")
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -11,23 +12,22 @@ import (
 
 // StudentService 定义学生服务接口
 type StudentService interface {
-	GetStudentByID(id string) (*model.Student, error)
-	GetAllStudents() ([]*model.Student, error)
-	CreateStudent(req *model.StudentCreateRequest) error
-	UpdateStudent(id string, req *model.StudentUpdateRequest) error
-	DeleteStudent(id string) error
-	ChangePassword(id string, req *model.ChangePasswordRequest) error
-	GetStudentTranscript(id string) (*model.Transcript, error)
-	GetCurrentCourses(id string, semester string, year int) ([]*model.Takes, error)
-	RegisterForCourse(studentID string, sectionID string, courseID string, semester string, year int) error
-	DropCourse(studentID string, sectionID string) error
-	GetByID(id string) (*model.Student, error)
-	UpdateProfile(id string, name string) error
-	GetAdvisor(id string) (*model.Advisor, error)
-	GetEnrolledCourses(id string) ([]*model.Takes, error)
-	GetTranscript(id string) (*model.Transcript, error)
-	Authenticate(id string, password string) (string, error)
-	Create(student *model.Student) error
+	GetStudentByID(ctx context.Context, id string) (*model.Student, error)
+	GetAllStudents(ctx context.Context) ([]*model.Student, error)
+	CreateStudent(ctx context.Context, req *model.StudentCreateRequest) error
+	UpdateStudent(ctx context.Context, actor model.Actor, id string, req *model.StudentUpdateRequest) error
+	DeleteStudent(ctx context.Context, actor model.Actor, id string) error
+	GetStudentTranscript(ctx context.Context, id string) (*model.Transcript, error)
+	GetCurrentCourses(ctx context.Context, id string, semester string, year int) ([]*model.Takes, error)
+	RegisterForCourse(ctx context.Context, studentID string, key model.SectionKey) error
+	DropCourse(ctx context.Context, studentID string, key model.SectionKey) error
+	GetByID(ctx context.Context, id string) (*model.Student, error)
+	UpdateProfile(ctx context.Context, id string, name string) error
+	GetAdvisor(ctx context.Context, id string) (*model.Advisor, error)
+	GetEnrolledCourses(ctx context.Context, id string) ([]*model.Takes, error)
+	GetTranscript(ctx context.Context, id string) (*model.Transcript, error)
+	Authenticate(ctx context.Context, id string, password string) (string, error)
+	Create(ctx context.Context, actor model.Actor, student *model.Student) error
 }
 
 // DefaultStudentService 实现StudentService接口
@@ -37,35 +37,41 @@ type DefaultStudentService struct {
 	prereqRepo  repository.PrereqRepository
 	sectionRepo repository.SectionRepository
 	advisorRepo repository.AdvisorRepository
+	sessions    SessionRevoker
+	policy      utils.PasswordPolicy
+	audit       AuditRecorder
 }
 
 // NewStudentService 创建学生服务实例
-func NewStudentService(studentRepo repository.StudentRepository, takesRepo repository.TakesRepository, prereqRepo repository.PrereqRepository, sectionRepo repository.SectionRepository, advisorRepo repository.AdvisorRepository) StudentService {
+func NewStudentService(studentRepo repository.StudentRepository, takesRepo repository.TakesRepository, prereqRepo repository.PrereqRepository, sectionRepo repository.SectionRepository, advisorRepo repository.AdvisorRepository, sessions SessionRevoker, policy utils.PasswordPolicy, audit AuditRecorder) StudentService {
 	return &DefaultStudentService{
 		studentRepo: studentRepo,
 		takesRepo:   takesRepo,
 		prereqRepo:  prereqRepo,
 		sectionRepo: sectionRepo,
 		advisorRepo: advisorRepo,
+		sessions:    sessions,
+		policy:      policy,
+		audit:       auditRecorderOrNop(audit),
 	}
 }
 
 // GetStudentByID 根据ID获取学生信息
-func (s *DefaultStudentService) GetStudentByID(id string) (*model.Student, error) {
-	return s.studentRepo.GetByID(id)
+func (s *DefaultStudentService) GetStudentByID(ctx context.Context, id string) (*model.Student, error) {
+	return s.studentRepo.GetByID(ctx, id)
 }
 
 // GetAllStudents 获取所有学生
-func (s *DefaultStudentService) GetAllStudents() ([]*model.Student, error) {
-	students, _, err := s.studentRepo.List(1, 1000) // 设置一个较大的页大小来获取所有学生
-	if err != nil {
-		return nil, err
-	}
-	return students, nil
+func (s *DefaultStudentService) GetAllStudents(ctx context.Context) ([]*model.Student, error) {
+	return listAll(ctx, s.studentRepo.List)
 }
 
-// CreateStudent 创建学生
-func (s *DefaultStudentService) CreateStudent(req *model.StudentCreateRequest) error {
+// CreateStudent 创建学生，用于学生自行注册，审计日志中的操作者是新账号本身
+func (s *DefaultStudentService) CreateStudent(ctx context.Context, req *model.StudentCreateRequest) error {
+	if err := s.policy.Validate(req.Password); err != nil {
+		return err
+	}
+
 	// 密码加密
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -81,89 +87,84 @@ func (s *DefaultStudentService) CreateStudent(req *model.StudentCreateRequest) e
 		TotCred:  0.0, // 新学生总学分为0，使用float64类型
 	}
 
-	return s.studentRepo.Create(student)
+	actor := studentActor(req.ID)
+	return s.Create(ctx, actor, student)
 }
 
 // UpdateStudent 更新学生信息
-func (s *DefaultStudentService) UpdateStudent(id string, req *model.StudentUpdateRequest) error {
+func (s *DefaultStudentService) UpdateStudent(ctx context.Context, actor model.Actor, id string, req *model.StudentUpdateRequest) error {
 	// 先查询学生是否存在
-	existingStudent, err := s.studentRepo.GetByID(id)
+	existingStudent, err := s.studentRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// 更新学生信息
+	before := existingStudent.ToDTO()
 	existingStudent.Name = req.Name
 	existingStudent.Dept = req.Dept
 
-	return s.studentRepo.Update(existingStudent)
+	if err := s.studentRepo.Update(ctx, existingStudent); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityStudent, id, before, existingStudent.ToDTO())
 }
 
 // DeleteStudent 删除学生
-func (s *DefaultStudentService) DeleteStudent(id string) error {
-	return s.studentRepo.Delete(id)
-}
-
-// Create 创建学生
-func (s *DefaultStudentService) Create(student *model.Student) error {
-	return s.studentRepo.Create(student)
-}
-
-// ChangePassword 修改密码
-func (s *DefaultStudentService) ChangePassword(id string, req *model.ChangePasswordRequest) error {
-	// 先查询学生是否存在
-	student, err := s.studentRepo.GetByID(id)
+func (s *DefaultStudentService) DeleteStudent(ctx context.Context, actor model.Actor, id string) error {
+	student, err := s.studentRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
-
-	// 验证旧密码
-	if !utils.CheckPassword(req.OldPassword, student.Password) {
-		return errors.New("invalid old password")
+	if err := s.studentRepo.Delete(ctx, id); err != nil {
+		return err
 	}
-
-	// 加密新密码
-	hashedPassword, err := utils.HashPassword(req.NewPassword)
-	if err != nil {
-		return fmt.Errorf("error hashing password: %w", err)
+	if err := s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityStudent, id, student.ToDTO(), nil); err != nil {
+		return err
 	}
+	return s.sessions.RevokeUserSessions(ctx, id, model.RoleStudent)
+}
 
-	// 更新密码 - 根据model.Student的Salt字段，这里应该传入salt
-	return s.studentRepo.UpdatePassword(id, hashedPassword, student.Salt)
+// Create 创建学生
+func (s *DefaultStudentService) Create(ctx context.Context, actor model.Actor, student *model.Student) error {
+	if err := s.studentRepo.Create(ctx, student); err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityStudent, student.ID, nil, student.ToDTO())
 }
 
 // GetStudentTranscript 获取学生成绩单
-func (s *DefaultStudentService) GetStudentTranscript(id string) (*model.Transcript, error) {
-	return s.takesRepo.GetStudentTranscript(id)
+func (s *DefaultStudentService) GetStudentTranscript(ctx context.Context, id string) (*model.Transcript, error) {
+	return s.takesRepo.GetStudentTranscript(ctx, id)
 }
 
 // GetCurrentCourses 获取学生当前学期的课程
-func (s *DefaultStudentService) GetCurrentCourses(id string, semester string, year int) ([]*model.Takes, error) {
-	return s.takesRepo.GetCurrentCourses(id, semester, year)
+func (s *DefaultStudentService) GetCurrentCourses(ctx context.Context, id string, semester string, year int) ([]*model.Takes, error) {
+	return s.takesRepo.GetCurrentCourses(ctx, id, semester, year)
 }
 
 // RegisterForCourse 学生选课
-func (s *DefaultStudentService) RegisterForCourse(studentID string, sectionID string, courseID string, semester string, year int) error {
+func (s *DefaultStudentService) RegisterForCourse(ctx context.Context, studentID string, key model.SectionKey) error {
 	// 检查学生是否存在
-	_, err := s.studentRepo.GetByID(studentID)
+	_, err := s.studentRepo.GetByID(ctx, studentID)
 	if err != nil {
 		return fmt.Errorf("student not found: %w", err)
 	}
 
 	// 检查课程段是否存在
-	_, err = s.sectionRepo.FindByID(sectionID)
+	_, err = s.sectionRepo.FindByID(ctx, key)
 	if err != nil {
 		return fmt.Errorf("section not found: %w", err)
 	}
 
 	// 检查是否已经选过这门课
-	existingTakes, err := s.takesRepo.FindByStudentAndSection(studentID, sectionID)
+	existingTakes, err := s.takesRepo.FindByStudentAndSection(ctx, studentID, key)
 	if err == nil && existingTakes != nil {
 		return errors.New("already registered for this course")
 	}
 
 	// 检查先修课程要求
-	satisfied, err := s.prereqRepo.CheckPrereqsSatisfied(studentID, courseID)
+	satisfied, err := s.prereqRepo.CheckPrereqsSatisfied(ctx, studentID, key.CourseID)
 	if err != nil {
 		return fmt.Errorf("error checking prerequisites: %w", err)
 	}
@@ -172,7 +173,7 @@ func (s *DefaultStudentService) RegisterForCourse(studentID string, sectionID st
 	}
 
 	// 检查时间冲突
-	hasConflict, err := s.takesRepo.CheckTimeConflict(studentID, sectionID)
+	hasConflict, err := s.takesRepo.CheckTimeConflict(ctx, studentID, key)
 	if err != nil {
 		return fmt.Errorf("error checking time conflict: %w", err)
 	}
@@ -183,46 +184,59 @@ func (s *DefaultStudentService) RegisterForCourse(studentID string, sectionID st
 	// 创建选课记录
 	takes := &model.Takes{
 		StudentID: studentID,
-		CourseID:  courseID,
-		SectionID: sectionID,
-		Semester:  semester,
-		Year:      year,
+		CourseID:  key.CourseID,
+		SectionID: key.SecID,
+		Semester:  key.Semester,
+		Year:      key.Year,
 		Grade:     "", // 新选课没有成绩
 	}
 
-	return s.takesRepo.Create(takes)
+	if err := s.takesRepo.Create(ctx, takes); err != nil {
+		return err
+	}
+	actor := studentActor(studentID)
+	return s.audit.Record(ctx, actor, model.AuditActionCreate, model.AuditEntityEnrollment, model.AuditKey(studentID, key.String()), nil, takes)
 }
 
 // DropCourse 学生退课
-func (s *DefaultStudentService) DropCourse(studentID string, sectionID string) error {
+func (s *DefaultStudentService) DropCourse(ctx context.Context, studentID string, key model.SectionKey) error {
 	// 检查选课记录是否存在
-	_, err := s.takesRepo.FindByStudentAndSection(studentID, sectionID)
+	takes, err := s.takesRepo.FindByStudentAndSection(ctx, studentID, key)
 	if err != nil {
 		return fmt.Errorf("enrollment not found: %w", err)
 	}
 
-	return s.takesRepo.Delete(studentID, sectionID)
+	if err := s.takesRepo.Delete(ctx, studentID, key); err != nil {
+		return err
+	}
+	actor := studentActor(studentID)
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityEnrollment, model.AuditKey(studentID, key.String()), takes, nil)
 }
 
 // GetByID 根据ID获取学生信息（别名方法）
-func (s *DefaultStudentService) GetByID(id string) (*model.Student, error) {
-	return s.GetStudentByID(id)
+func (s *DefaultStudentService) GetByID(ctx context.Context, id string) (*model.Student, error) {
+	return s.GetStudentByID(ctx, id)
 }
 
 // UpdateProfile 更新学生个人信息
-func (s *DefaultStudentService) UpdateProfile(id string, name string) error {
-	student, err := s.studentRepo.GetByID(id)
+func (s *DefaultStudentService) UpdateProfile(ctx context.Context, id string, name string) error {
+	student, err := s.studentRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	before := student.ToDTO()
 	student.Name = name
-	return s.studentRepo.Update(student)
+	if err := s.studentRepo.Update(ctx, student); err != nil {
+		return err
+	}
+	actor := studentActor(id)
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityStudent, id, before, student.ToDTO())
 }
 
 // GetAdvisor 获取学生导师信息
-func (s *DefaultStudentService) GetAdvisor(id string) (*model.Advisor, error) {
-	advisors, err := s.advisorRepo.FindByStudentID(id)
+func (s *DefaultStudentService) GetAdvisor(ctx context.Context, id string) (*model.Advisor, error) {
+	advisors, err := s.advisorRepo.FindByStudentID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -233,18 +247,18 @@ func (s *DefaultStudentService) GetAdvisor(id string) (*model.Advisor, error) {
 }
 
 // GetEnrolledCourses 获取学生已选课程
-func (s *DefaultStudentService) GetEnrolledCourses(id string) ([]*model.Takes, error) {
-	return s.takesRepo.FindByStudentID(id)
+func (s *DefaultStudentService) GetEnrolledCourses(ctx context.Context, id string) ([]*model.Takes, error) {
+	return s.takesRepo.FindByStudentID(ctx, id)
 }
 
 // GetTranscript 获取学生成绩单（别名方法）
-func (s *DefaultStudentService) GetTranscript(id string) (*model.Transcript, error) {
-	return s.GetStudentTranscript(id)
+func (s *DefaultStudentService) GetTranscript(ctx context.Context, id string) (*model.Transcript, error) {
+	return s.GetStudentTranscript(ctx, id)
 }
 
 // Authenticate 学生认证
-func (s *DefaultStudentService) Authenticate(id string, password string) (string, error) {
-	student, err := s.studentRepo.GetByID(id)
+func (s *DefaultStudentService) Authenticate(ctx context.Context, id string, password string) (string, error) {
+	student, err := s.studentRepo.GetByID(ctx, id)
 	if err != nil {
 		return "", fmt.Errorf("student not found: %w", err)
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/yourusername/student-management-system/internal/model"
@@ -9,14 +10,14 @@ import (
 
 // TimeSlotService 定义时间段服务接口
 type TimeSlotService interface {
-	GetTimeSlotByID(id string) (*model.TimeSlot, error)
-	GetAllTimeSlots() ([]*model.TimeSlot, error)
-	GetTimeSlotsByDayOfWeek(dayOfWeek int) ([]*model.TimeSlot, error)
-	GetTimeSlotsByTimeRange(startTime, endTime string) ([]*model.TimeSlot, error)
-	CreateTimeSlot(req *model.TimeSlotCreateRequest) error
-	UpdateTimeSlot(id string, req *model.TimeSlotUpdateRequest) error
-	DeleteTimeSlot(id string) error
-	GetTimeSlotUsage(id string, semester string, year int) ([]*model.Section, error)
+	GetTimeSlotByID(ctx context.Context, id string) (*model.TimeSlot, error)
+	GetAllTimeSlots(ctx context.Context) ([]*model.TimeSlot, error)
+	GetTimeSlotsByDayOfWeek(ctx context.Context, dayOfWeek int) ([]*model.TimeSlot, error)
+	GetTimeSlotsByTimeRange(ctx context.Context, startTime, endTime string) ([]*model.TimeSlot, error)
+	CreateTimeSlot(ctx context.Context, req *model.TimeSlotCreateRequest) error
+	UpdateTimeSlot(ctx context.Context, id string, req *model.TimeSlotUpdateRequest) error
+	DeleteTimeSlot(ctx context.Context, id string) error
+	GetTimeSlotUsage(ctx context.Context, id string, semester string, year int) ([]*model.Section, error)
 }
 
 // DefaultTimeSlotService 实现TimeSlotService接口
@@ -37,31 +38,31 @@ func NewTimeSlotService(
 }
 
 // GetTimeSlotByID 根据ID获取时间段信息
-func (s *DefaultTimeSlotService) GetTimeSlotByID(id string) (*model.TimeSlot, error) {
-	return s.timeslotRepo.FindByID(id)
+func (s *DefaultTimeSlotService) GetTimeSlotByID(ctx context.Context, id string) (*model.TimeSlot, error) {
+	return s.timeslotRepo.FindByID(ctx, id)
 }
 
 // GetAllTimeSlots 获取所有时间段
-func (s *DefaultTimeSlotService) GetAllTimeSlots() ([]*model.TimeSlot, error) {
-	return s.timeslotRepo.FindAll()
+func (s *DefaultTimeSlotService) GetAllTimeSlots(ctx context.Context) ([]*model.TimeSlot, error) {
+	return s.timeslotRepo.FindAll(ctx)
 }
 
 // GetTimeSlotsByDayOfWeek 获取指定星期几的所有时间段
-func (s *DefaultTimeSlotService) GetTimeSlotsByDayOfWeek(dayOfWeek int) ([]*model.TimeSlot, error) {
+func (s *DefaultTimeSlotService) GetTimeSlotsByDayOfWeek(ctx context.Context, dayOfWeek int) ([]*model.TimeSlot, error) {
 	if dayOfWeek < 1 || dayOfWeek > 7 {
 		return nil, fmt.Errorf("invalid day of week: %d, must be between 1 and 7", dayOfWeek)
 	}
 
-	return s.timeslotRepo.FindByDayOfWeek(dayOfWeek)
+	return s.timeslotRepo.FindByDayOfWeek(ctx, dayOfWeek)
 }
 
 // GetTimeSlotsByTimeRange 获取指定时间范围内的所有时间段
-func (s *DefaultTimeSlotService) GetTimeSlotsByTimeRange(startTime, endTime string) ([]*model.TimeSlot, error) {
-	return s.timeslotRepo.FindByTimeRange(startTime, endTime)
+func (s *DefaultTimeSlotService) GetTimeSlotsByTimeRange(ctx context.Context, startTime, endTime string) ([]*model.TimeSlot, error) {
+	return s.timeslotRepo.FindByTimeRange(ctx, startTime, endTime)
 }
 
 // CreateTimeSlot 创建时间段
-func (s *DefaultTimeSlotService) CreateTimeSlot(req *model.TimeSlotCreateRequest) error {
+func (s *DefaultTimeSlotService) CreateTimeSlot(ctx context.Context, req *model.TimeSlotCreateRequest) error {
 	// 验证时间段数据
 	if len(req.Days) == 0 {
 		return fmt.Errorf("at least one day must be specified")
@@ -81,13 +82,13 @@ func (s *DefaultTimeSlotService) CreateTimeSlot(req *model.TimeSlotCreateRequest
 		Days:      req.Days,
 	}
 
-	return s.timeslotRepo.Create(timeSlot)
+	return s.timeslotRepo.Create(ctx, timeSlot)
 }
 
 // UpdateTimeSlot 更新时间段信息
-func (s *DefaultTimeSlotService) UpdateTimeSlot(id string, req *model.TimeSlotUpdateRequest) error {
+func (s *DefaultTimeSlotService) UpdateTimeSlot(ctx context.Context, id string, req *model.TimeSlotUpdateRequest) error {
 	// 先查询时间段是否存在
-	existingTimeSlot, err := s.timeslotRepo.FindByID(id)
+	existingTimeSlot, err := s.timeslotRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -117,7 +118,7 @@ func (s *DefaultTimeSlotService) UpdateTimeSlot(id string, req *model.TimeSlotUp
 			TimeSlotID: id,
 		}
 
-		sections, err := s.sectionRepo.FindByParams(params)
+		sections, err := s.sectionRepo.FindByParams(ctx, params)
 		if err != nil {
 			return fmt.Errorf("error checking time slot usage: %w", err)
 		}
@@ -127,13 +128,13 @@ func (s *DefaultTimeSlotService) UpdateTimeSlot(id string, req *model.TimeSlotUp
 		}
 	}
 
-	return s.timeslotRepo.Update(existingTimeSlot)
+	return s.timeslotRepo.Update(ctx, existingTimeSlot)
 }
 
 // DeleteTimeSlot 删除时间段
-func (s *DefaultTimeSlotService) DeleteTimeSlot(id string) error {
+func (s *DefaultTimeSlotService) DeleteTimeSlot(ctx context.Context, id string) error {
 	// 检查时间段是否存在
-	_, err := s.timeslotRepo.FindByID(id)
+	_, err := s.timeslotRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -143,7 +144,7 @@ func (s *DefaultTimeSlotService) DeleteTimeSlot(id string) error {
 		TimeSlotID: id,
 	}
 
-	sections, err := s.sectionRepo.FindByParams(params)
+	sections, err := s.sectionRepo.FindByParams(ctx, params)
 	if err != nil {
 		return fmt.Errorf("error checking time slot usage: %w", err)
 	}
@@ -152,13 +153,13 @@ func (s *DefaultTimeSlotService) DeleteTimeSlot(id string) error {
 		return fmt.Errorf("cannot delete time slot: it is being used by %d course sections", len(sections))
 	}
 
-	return s.timeslotRepo.Delete(id)
+	return s.timeslotRepo.Delete(ctx, id)
 }
 
 // GetTimeSlotUsage 获取时间段使用情况
-func (s *DefaultTimeSlotService) GetTimeSlotUsage(id string, semester string, year int) ([]*model.Section, error) {
+func (s *DefaultTimeSlotService) GetTimeSlotUsage(ctx context.Context, id string, semester string, year int) ([]*model.Section, error) {
 	// 检查时间段是否存在
-	_, err := s.timeslotRepo.FindByID(id)
+	_, err := s.timeslotRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -170,5 +171,5 @@ func (s *DefaultTimeSlotService) GetTimeSlotUsage(id string, semester string, ye
 		Year:       year,
 	}
 
-	return s.sectionRepo.FindByParams(params)
+	return s.sectionRepo.FindByParams(ctx, params)
 }
@@ -0,0 +1,578 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+var (
+	// ErrTwoFactorNotEnrolled 账号没有绑定两步验证
+	ErrTwoFactorNotEnrolled = errors.New("two factor authentication is not enrolled")
+
+	// ErrTwoFactorAlreadyEnabled 账号已经启用两步验证
+	ErrTwoFactorAlreadyEnabled = errors.New("two factor authentication is already enabled")
+
+	// ErrTwoFactorRequired 账号所属角色要求启用两步验证，不能关闭
+	ErrTwoFactorRequired = errors.New("two factor authentication is required for this role")
+
+	// ErrInvalidTwoFactorCode 验证码或恢复码错误、已使用或已过期
+	ErrInvalidTwoFactorCode = errors.New("invalid two factor code")
+
+	// ErrInvalidChallenge 登录挑战令牌无效、过期、已使用或错误次数过多
+	ErrInvalidChallenge = errors.New("invalid or expired login challenge")
+)
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// TwoFactorService 定义两步验证服务接口
+type TwoFactorService interface {
+	GetStatus(ctx context.Context, userID string, role string) (*model.TwoFactorStatus, error)
+	Enroll(ctx context.Context, userID string, role string) (*model.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID string, role string, code string) ([]string, error)
+	Disable(ctx context.Context, userID string, role string, req *model.TwoFactorCodeRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, role string, req *model.TwoFactorCodeRequest) ([]string, error)
+	Reset(ctx context.Context, actor model.Actor, userID string, role string) error
+
+	BeginLogin(ctx context.Context, userID string, role string, username string) (*model.TwoFactorLoginChallenge, error)
+	FindChallenge(ctx context.Context, token string) (*model.TwoFactorChallenge, error)
+	EnrollWithChallenge(ctx context.Context, token string) (*model.TwoFactorEnrollment, error)
+	CompleteLogin(ctx context.Context, req *model.TwoFactorLoginRequest) (*model.TwoFactorLoginResult, error)
+
+	GetPolicies(ctx context.Context) ([]*model.TwoFactorPolicy, error)
+	SetPolicy(ctx context.Context, actor model.Actor, policy *model.TwoFactorPolicy) error
+}
+
+// DefaultTwoFactorService 实现TwoFactorService接口
+//
+// 密码验证通过后，已启用两步验证或角色要求两步验证的账号不会直接拿到令牌，
+// 而是得到一个 "<挑战ID>.<随机串>" 格式的挑战令牌，提交验证码或恢复码后才创建会话。
+// 角色要求两步验证但账号尚未绑定时，挑战令牌也可以用来完成绑定。
+type DefaultTwoFactorService struct {
+	repo  repository.TwoFactorRepository
+	uow   repository.UnitOfWork
+	cfg   config.TwoFactorConfig
+	audit AuditRecorder
+	now   func() time.Time // 当前时间，测试中可替换
+}
+
+// NewTwoFactorService 创建两步验证服务实例
+func NewTwoFactorService(repo repository.TwoFactorRepository, uow repository.UnitOfWork, cfg config.TwoFactorConfig, audit AuditRecorder) TwoFactorService {
+	if cfg.Issuer == "" {
+		cfg.Issuer = "Student Management System"
+	}
+	if cfg.Skew < 0 {
+		cfg.Skew = 0
+	}
+	if cfg.ChallengeExpiration <= 0 {
+		cfg.ChallengeExpiration = 300
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	return &DefaultTwoFactorService{
+		repo:  repo,
+		uow:   uow,
+		cfg:   cfg,
+		audit: auditRecorderOrNop(audit),
+		now:   time.Now,
+	}
+}
+
+// GetStatus 获取账号的两步验证状态
+func (s *DefaultTwoFactorService) GetStatus(ctx context.Context, userID string, role string) (*model.TwoFactorStatus, error) {
+	required, err := s.isRequired(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.TwoFactorStatus{Required: required}
+
+	tf, err := s.find(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || !tf.Enabled {
+		return status, nil
+	}
+
+	count, err := s.repo.CountRecoveryCodes(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Enabled = true
+	status.RecoveryCodesRemaining = count
+	return status, nil
+}
+
+// Enroll 生成新的密钥，需要用验证器应用中的验证码确认后才会启用
+func (s *DefaultTwoFactorService) Enroll(ctx context.Context, userID string, role string) (*model.TwoFactorEnrollment, error) {
+	tf, err := s.find(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if tf != nil && tf.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("error generating totp secret: %w", err)
+	}
+
+	pending := &model.TwoFactor{
+		UserID:    userID,
+		Role:      role,
+		Secret:    secret,
+		CreatedAt: s.now(),
+	}
+	if err := s.repo.SavePending(ctx, pending); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(s.cfg.Issuer, userID+" ("+role+")", secret),
+	}, nil
+}
+
+// Confirm 用验证码确认绑定并启用两步验证，返回只显示一次的恢复码
+func (s *DefaultTwoFactorService) Confirm(ctx context.Context, userID string, role string, code string) ([]string, error) {
+	tf, err := s.find(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if tf.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(tf.Secret, code, s.now(), s.cfg.Skew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		var err error
+		codes, err = s.enable(ctx, repos.TwoFactor, tf, step)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordEnabled(ctx, tf); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable 验证后关闭两步验证，角色要求两步验证时不能关闭
+func (s *DefaultTwoFactorService) Disable(ctx context.Context, userID string, role string, req *model.TwoFactorCodeRequest) error {
+	required, err := s.isRequired(ctx, role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	tf, err := s.find(ctx, userID, role)
+	if err != nil {
+		return err
+	}
+	if tf == nil {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		// 尚未确认的绑定不需要验证码，直接丢弃
+		if tf.Enabled {
+			if err := s.verify(ctx, repos.TwoFactor, tf, req); err != nil {
+				return err
+			}
+		}
+		return s.remove(ctx, repos.TwoFactor, userID, role)
+	})
+	if err != nil {
+		return err
+	}
+
+	actor := model.Actor{UserID: userID, Role: role}
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityTwoFactor, model.AuditKey(role, userID), tf, nil)
+}
+
+// RegenerateRecoveryCodes 验证后重新生成恢复码，之前的恢复码全部作废
+func (s *DefaultTwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID string, role string, req *model.TwoFactorCodeRequest) ([]string, error) {
+	tf, err := s.find(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || !tf.Enabled {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		if err := s.verify(ctx, repos.TwoFactor, tf, req); err != nil {
+			return err
+		}
+		return repos.TwoFactor.ReplaceRecoveryCodes(ctx, userID, role, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Reset 管理员为丢失设备的账号清除两步验证，角色要求时下次登录需要重新绑定
+func (s *DefaultTwoFactorService) Reset(ctx context.Context, actor model.Actor, userID string, role string) error {
+	tf, err := s.find(ctx, userID, role)
+	if err != nil {
+		return err
+	}
+	if tf == nil {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		return s.remove(ctx, repos.TwoFactor, userID, role)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.audit.Record(ctx, actor, model.AuditActionDelete, model.AuditEntityTwoFactor, model.AuditKey(role, userID), tf, nil)
+}
+
+// BeginLogin 密码验证通过后调用，不需要两步验证时返回nil
+func (s *DefaultTwoFactorService) BeginLogin(ctx context.Context, userID string, role string, username string) (*model.TwoFactorLoginChallenge, error) {
+	tf, err := s.find(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	enabled := tf != nil && tf.Enabled
+
+	required, err := s.isRequired(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	id, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("error generating challenge id: %w", err)
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating challenge token: %w", err)
+	}
+
+	now := s.now()
+	expiration := time.Duration(s.cfg.ChallengeExpiration) * time.Second
+	challenge := &model.TwoFactorChallenge{
+		ID:        id,
+		TokenHash: hashTokenSecret(secret),
+		UserID:    userID,
+		Role:      role,
+		Username:  username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(expiration),
+	}
+
+	if err := s.repo.CreateChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorLoginChallenge{
+		Token:         id + "." + secret,
+		SetupRequired: !enabled,
+		ExpiresIn:     int64(expiration.Seconds()),
+	}, nil
+}
+
+// FindChallenge 根据挑战令牌查找仍然有效的登录挑战
+func (s *DefaultTwoFactorService) FindChallenge(ctx context.Context, token string) (*model.TwoFactorChallenge, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || id == "" || secret == "" {
+		return nil, ErrInvalidChallenge
+	}
+
+	challenge, err := s.repo.FindChallenge(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashTokenSecret(secret)), []byte(challenge.TokenHash)) != 1 {
+		return nil, ErrInvalidChallenge
+	}
+
+	if !challenge.IsUsable(s.now(), s.cfg.MaxAttempts) {
+		return nil, ErrInvalidChallenge
+	}
+
+	return challenge, nil
+}
+
+// EnrollWithChallenge 角色要求两步验证但账号尚未绑定时，凭登录挑战开始绑定
+func (s *DefaultTwoFactorService) EnrollWithChallenge(ctx context.Context, token string) (*model.TwoFactorEnrollment, error) {
+	challenge, err := s.FindChallenge(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Enroll(ctx, challenge.UserID, challenge.Role)
+}
+
+// CompleteLogin 校验登录挑战的验证码或恢复码
+// 账号在登录时完成绑定的，提交的验证码同时用于确认绑定，结果中带有新生成的恢复码
+func (s *DefaultTwoFactorService) CompleteLogin(ctx context.Context, req *model.TwoFactorLoginRequest) (*model.TwoFactorLoginResult, error) {
+	challenge, err := s.FindChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	tf, err := s.find(ctx, challenge.UserID, challenge.Role)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	result := &model.TwoFactorLoginResult{
+		UserID:   challenge.UserID,
+		Role:     challenge.Role,
+		Username: challenge.Username,
+	}
+
+	err = s.uow.Do(ctx, func(repos *repository.Repositories) error {
+		if tf.Enabled {
+			if err := s.verify(ctx, repos.TwoFactor, tf, &req.TwoFactorCodeRequest); err != nil {
+				return err
+			}
+		} else {
+			// 尚未确认的绑定只接受验证码
+			step, ok := utils.ValidateTOTP(tf.Secret, req.Code, s.now(), s.cfg.Skew)
+			if !ok {
+				return ErrInvalidTwoFactorCode
+			}
+			codes, err := s.enable(ctx, repos.TwoFactor, tf, step)
+			if err != nil {
+				return err
+			}
+			result.RecoveryCodes = codes
+		}
+
+		if err := repos.TwoFactor.MarkChallengeUsed(ctx, challenge.ID, s.now()); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidChallenge
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if incErr := s.repo.IncrementChallengeAttempts(ctx, challenge.ID); incErr != nil {
+				return nil, incErr
+			}
+		}
+		return nil, err
+	}
+
+	if result.RecoveryCodes != nil {
+		if err := s.recordEnabled(ctx, tf); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetPolicies 获取所有角色的两步验证要求，没有设置过的角色视为不要求
+func (s *DefaultTwoFactorService) GetPolicies(ctx context.Context) ([]*model.TwoFactorPolicy, error) {
+	saved, err := s.repo.FindPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	required := make(map[string]bool, len(saved))
+	for _, policy := range saved {
+		required[policy.Role] = policy.Required
+	}
+
+	var policies []*model.TwoFactorPolicy
+	for _, role := range []string{model.RoleStudent, model.RoleInstructor, model.RoleAdmin} {
+		policies = append(policies, &model.TwoFactorPolicy{Role: role, Required: required[role]})
+	}
+
+	return policies, nil
+}
+
+// SetPolicy 设置角色是否必须启用两步验证
+func (s *DefaultTwoFactorService) SetPolicy(ctx context.Context, actor model.Actor, policy *model.TwoFactorPolicy) error {
+	switch policy.Role {
+	case model.RoleStudent, model.RoleInstructor, model.RoleAdmin:
+	default:
+		return ErrUnknownRole
+	}
+
+	required, err := s.isRequired(ctx, policy.Role)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SavePolicy(ctx, policy); err != nil {
+		return err
+	}
+
+	before := &model.TwoFactorPolicy{Role: policy.Role, Required: required}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityTwoFactorPolicy, policy.Role, before, policy)
+}
+
+// find 查找账号的两步验证设置，没有设置时返回nil
+func (s *DefaultTwoFactorService) find(ctx context.Context, userID string, role string) (*model.TwoFactor, error) {
+	tf, err := s.repo.Find(ctx, userID, role)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return tf, nil
+}
+
+// isRequired 判断角色是否要求两步验证
+func (s *DefaultTwoFactorService) isRequired(ctx context.Context, role string) (bool, error) {
+	policies, err := s.repo.FindPolicies(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, policy := range policies {
+		if policy.Role == role {
+			return policy.Required, nil
+		}
+	}
+	return false, nil
+}
+
+// verify 校验已启用账号提交的验证码或恢复码
+// 验证码对应的时间步不晚于上次使用的时间步时视为重放；恢复码使用一次后作废
+func (s *DefaultTwoFactorService) verify(ctx context.Context, repo repository.TwoFactorRepository, tf *model.TwoFactor, req *model.TwoFactorCodeRequest) error {
+	if req == nil {
+		return ErrInvalidTwoFactorCode
+	}
+
+	var err error
+	switch {
+	case req.Code != "":
+		step, ok := utils.ValidateTOTP(tf.Secret, req.Code, s.now(), s.cfg.Skew)
+		if !ok || step <= tf.LastUsedStep {
+			return ErrInvalidTwoFactorCode
+		}
+		err = repo.UseStep(ctx, tf.UserID, tf.Role, step)
+	case req.RecoveryCode != "":
+		err = repo.UseRecoveryCode(ctx, tf.UserID, tf.Role, hashRecoveryCode(req.RecoveryCode), s.now())
+	default:
+		return ErrInvalidTwoFactorCode
+	}
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+// enable 启用尚未确认的绑定并生成恢复码
+func (s *DefaultTwoFactorService) enable(ctx context.Context, repo repository.TwoFactorRepository, tf *model.TwoFactor, step int64) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.Enable(ctx, tf.UserID, tf.Role, step, s.now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	if err := repo.ReplaceRecoveryCodes(ctx, tf.UserID, tf.Role, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// recordEnabled 记录账号启用两步验证的审计日志，密钥和恢复码不会写入日志
+func (s *DefaultTwoFactorService) recordEnabled(ctx context.Context, tf *model.TwoFactor) error {
+	after := *tf
+	after.Enabled = true
+	enabledAt := s.now()
+	after.EnabledAt = &enabledAt
+
+	actor := model.Actor{UserID: tf.UserID, Role: tf.Role}
+	return s.audit.Record(ctx, actor, model.AuditActionUpdate, model.AuditEntityTwoFactor, model.AuditKey(tf.Role, tf.UserID), tf, &after)
+}
+
+// remove 删除账号的两步验证设置和恢复码
+func (s *DefaultTwoFactorService) remove(ctx context.Context, repo repository.TwoFactorRepository, userID string, role string) error {
+	if err := repo.Delete(ctx, userID, role); err != nil {
+		return err
+	}
+	return repo.ReplaceRecoveryCodes(ctx, userID, role, nil)
+}
+
+// generateRecoveryCodes 生成恢复码及其哈希，恢复码格式为 "xxxxx-xxxxx"
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("error generating recovery code: %w", err)
+		}
+		encoded := hex.EncodeToString(raw)
+		codes = append(codes, encoded[:5]+"-"+encoded[5:])
+		hashes = append(hashes, hashRecoveryCode(encoded))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode 计算恢复码的哈希，忽略大小写、空格和连字符
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashTokenSecret(normalized)
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/internal/model"
+	"github.com/yourusername/student-management-system/internal/repository"
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/utils"
+)
+
+// fakeTwoFactorRepository 基于内存的两步验证仓库
+type fakeTwoFactorRepository struct {
+	settings   map[string]*model.TwoFactor
+	codes      map[string]map[string]bool // 账号 -> 恢复码哈希 -> 是否已使用
+	challenges map[string]*model.TwoFactorChallenge
+	policies   map[string]bool
+}
+
+func newFakeTwoFactorRepository() *fakeTwoFactorRepository {
+	return &fakeTwoFactorRepository{
+		settings:   make(map[string]*model.TwoFactor),
+		codes:      make(map[string]map[string]bool),
+		challenges: make(map[string]*model.TwoFactorChallenge),
+		policies:   make(map[string]bool),
+	}
+}
+
+// clone 复制仓库状态，供fakeTwoFactorUnitOfWork在出错时回滚
+func (r *fakeTwoFactorRepository) clone() *fakeTwoFactorRepository {
+	copied := newFakeTwoFactorRepository()
+	for k, v := range r.settings {
+		tf := *v
+		copied.settings[k] = &tf
+	}
+	for k, v := range r.codes {
+		codes := make(map[string]bool, len(v))
+		for hash, used := range v {
+			codes[hash] = used
+		}
+		copied.codes[k] = codes
+	}
+	for k, v := range r.challenges {
+		challenge := *v
+		copied.challenges[k] = &challenge
+	}
+	for k, v := range r.policies {
+		copied.policies[k] = v
+	}
+	return copied
+}
+
+func (r *fakeTwoFactorRepository) Find(ctx context.Context, userID string, role string) (*model.TwoFactor, error) {
+	tf, ok := r.settings[userID+":"+role]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *tf
+	return &copied, nil
+}
+
+func (r *fakeTwoFactorRepository) SavePending(ctx context.Context, tf *model.TwoFactor) error {
+	copied := *tf
+	copied.Enabled = false
+	copied.LastUsedStep = 0
+	copied.EnabledAt = nil
+	r.settings[tf.UserID+":"+tf.Role] = &copied
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) Enable(ctx context.Context, userID string, role string, step int64, at time.Time) error {
+	tf, ok := r.settings[userID+":"+role]
+	if !ok || tf.Enabled {
+		return repository.ErrNotFound
+	}
+	tf.Enabled = true
+	tf.LastUsedStep = step
+	tf.EnabledAt = &at
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) UseStep(ctx context.Context, userID string, role string, step int64) error {
+	tf, ok := r.settings[userID+":"+role]
+	if !ok || !tf.Enabled || tf.LastUsedStep >= step {
+		return repository.ErrNotFound
+	}
+	tf.LastUsedStep = step
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) Delete(ctx context.Context, userID string, role string) error {
+	delete(r.settings, userID+":"+role)
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, role string, codeHashes []string) error {
+	codes := make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes[hash] = false
+	}
+	r.codes[userID+":"+role] = codes
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID string, role string, codeHash string, at time.Time) error {
+	codes := r.codes[userID+":"+role]
+	used, ok := codes[codeHash]
+	if !ok || used {
+		return repository.ErrNotFound
+	}
+	codes[codeHash] = true
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID string, role string) (int, error) {
+	count := 0
+	for _, used := range r.codes[userID+":"+role] {
+		if !used {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeTwoFactorRepository) CreateChallenge(ctx context.Context, challenge *model.TwoFactorChallenge) error {
+	copied := *challenge
+	r.challenges[challenge.ID] = &copied
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) FindChallenge(ctx context.Context, id string) (*model.TwoFactorChallenge, error) {
+	challenge, ok := r.challenges[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *challenge
+	return &copied, nil
+}
+
+func (r *fakeTwoFactorRepository) IncrementChallengeAttempts(ctx context.Context, id string) error {
+	if challenge, ok := r.challenges[id]; ok {
+		challenge.Attempts++
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) MarkChallengeUsed(ctx context.Context, id string, at time.Time) error {
+	challenge, ok := r.challenges[id]
+	if !ok || challenge.UsedAt != nil {
+		return repository.ErrNotFound
+	}
+	challenge.UsedAt = &at
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) FindPolicies(ctx context.Context) ([]*model.TwoFactorPolicy, error) {
+	var policies []*model.TwoFactorPolicy
+	for role, required := range r.policies {
+		policies = append(policies, &model.TwoFactorPolicy{Role: role, Required: required})
+	}
+	return policies, nil
+}
+
+func (r *fakeTwoFactorRepository) SavePolicy(ctx context.Context, policy *model.TwoFactorPolicy) error {
+	r.policies[policy.Role] = policy.Required
+	return nil
+}
+
+// fakeTwoFactorUnitOfWork 在仓库副本上执行fn，成功时才替换仓库状态
+type fakeTwoFactorUnitOfWork struct {
+	repo *fakeTwoFactorRepository
+}
+
+func (u *fakeTwoFactorUnitOfWork) Do(ctx context.Context, fn func(repos *repository.Repositories) error) error {
+	tx := u.repo.clone()
+	if err := fn(&repository.Repositories{TwoFactor: tx}); err != nil {
+		return err
+	}
+	*u.repo = *tx
+	return nil
+}
+
+// newTestTwoFactorService 创建使用内存仓库和可控时钟的两步验证服务
+func newTestTwoFactorService() (*DefaultTwoFactorService, *fakeTwoFactorRepository, *time.Time) {
+	repo := newFakeTwoFactorRepository()
+	svc := NewTwoFactorService(repo, &fakeTwoFactorUnitOfWork{repo: repo}, config.TwoFactorConfig{
+		Issuer:              "Test",
+		Skew:                1,
+		ChallengeExpiration: 300,
+		MaxAttempts:         3,
+	}, nil).(*DefaultTwoFactorService)
+
+	now := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+// codeAt 计算指定时间的验证码
+func codeAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(at))
+	if err != nil {
+		t.Fatalf("Failed to compute code: %v", err)
+	}
+	return code
+}
+
+// enrollTestAccount 为账号完成绑定，返回密钥和恢复码
+func enrollTestAccount(t *testing.T, svc *DefaultTwoFactorService, now *time.Time) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	enrollment, err := svc.Enroll(ctx, "S001", model.RoleStudent)
+	if err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	codes, err := svc.Confirm(ctx, "S001", model.RoleStudent, codeAt(t, enrollment.Secret, *now))
+	if err != nil {
+		t.Fatalf("Confirm failed: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+func TestTwoFactorEnrollAndConfirm(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestTwoFactorService()
+
+	enrollment, err := svc.Enroll(ctx, "S001", model.RoleStudent)
+	if err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	if enrollment.URI == "" || enrollment.Secret == "" {
+		t.Fatalf("Expected secret and otpauth URI, got %+v", enrollment)
+	}
+
+	if _, err := svc.Confirm(ctx, "S001", model.RoleStudent, "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected ErrInvalidTwoFactorCode for wrong code, got %v", err)
+	}
+
+	codes, err := svc.Confirm(ctx, "S001", model.RoleStudent, codeAt(t, enrollment.Secret, *now))
+	if err != nil {
+		t.Fatalf("Confirm failed: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("Expected %d recovery codes, got %d", recoveryCodeCount, len(codes))
+	}
+
+	status, err := svc.GetStatus(ctx, "S001", model.RoleStudent)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if !status.Enabled || status.RecoveryCodesRemaining != recoveryCodeCount {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	if _, err := svc.Enroll(ctx, "S001", model.RoleStudent); !errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+		t.Errorf("Expected ErrTwoFactorAlreadyEnabled, got %v", err)
+	}
+}
+
+func TestTwoFactorLoginRejectsReplayedCode(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestTwoFactorService()
+	secret, _ := enrollTestAccount(t, svc, now)
+
+	// 绑定时用过的验证码不能再用于登录
+	challenge, err := svc.BeginLogin(ctx, "S001", model.RoleStudent, "S001")
+	if err != nil || challenge == nil {
+		t.Fatalf("Expected challenge, got %v, %v", challenge, err)
+	}
+	req := &model.TwoFactorLoginRequest{ChallengeToken: challenge.Token}
+	req.Code = codeAt(t, secret, *now)
+	if _, err := svc.CompleteLogin(ctx, req); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected replayed code to be rejected, got %v", err)
+	}
+
+	*now = now.Add(30 * time.Second)
+	req.Code = codeAt(t, secret, *now)
+	result, err := svc.CompleteLogin(ctx, req)
+	if err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	if result.UserID != "S001" || result.Role != model.RoleStudent {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// 挑战只能使用一次
+	if _, err := svc.CompleteLogin(ctx, req); !errors.Is(err, ErrInvalidChallenge) {
+		t.Errorf("Expected ErrInvalidChallenge for used challenge, got %v", err)
+	}
+}
+
+func TestTwoFactorRecoveryCodeSingleUse(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newTestTwoFactorService()
+	_, codes := enrollTestAccount(t, svc, now)
+
+	for i, want := range []error{nil, ErrInvalidTwoFactorCode} {
+		challenge, err := svc.BeginLogin(ctx, "S001", model.RoleStudent, "S001")
+		if err != nil {
+			t.Fatalf("BeginLogin failed: %v", err)
+		}
+		req := &model.TwoFactorLoginRequest{ChallengeToken: challenge.Token}
+		req.RecoveryCode = codes[0]
+		if _, err := svc.CompleteLogin(ctx, req); !errors.Is(err, want) {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, want, err)
+		}
+	}
+
+	count, _ := repo.CountRecoveryCodes(ctx, "S001", model.RoleStudent)
+	if count != recoveryCodeCount-1 {
+		t.Errorf("Expected %d recovery codes left, got %d", recoveryCodeCount-1, count)
+	}
+}
+
+func TestTwoFactorChallengeLimits(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestTwoFactorService()
+	secret, _ := enrollTestAccount(t, svc, now)
+	*now = now.Add(time.Minute)
+
+	challenge, err := svc.BeginLogin(ctx, "S001", model.RoleStudent, "S001")
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+	req := &model.TwoFactorLoginRequest{ChallengeToken: challenge.Token}
+	req.Code = "000000"
+	for i := 0; i < 3; i++ {
+		if _, err := svc.CompleteLogin(ctx, req); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("Attempt %d: expected ErrInvalidTwoFactorCode, got %v", i+1, err)
+		}
+	}
+
+	req.Code = codeAt(t, secret, *now)
+	if _, err := svc.CompleteLogin(ctx, req); !errors.Is(err, ErrInvalidChallenge) {
+		t.Errorf("Expected challenge to be exhausted, got %v", err)
+	}
+
+	challenge, _ = svc.BeginLogin(ctx, "S001", model.RoleStudent, "S001")
+	*now = now.Add(5 * time.Minute)
+	req = &model.TwoFactorLoginRequest{ChallengeToken: challenge.Token}
+	req.Code = codeAt(t, secret, *now)
+	if _, err := svc.CompleteLogin(ctx, req); !errors.Is(err, ErrInvalidChallenge) {
+		t.Errorf("Expected challenge to expire, got %v", err)
+	}
+}
+
+func TestTwoFactorRequiredByRole(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestTwoFactorService()
+
+	challenge, err := svc.BeginLogin(ctx, "A001", model.RoleAdmin, "A001")
+	if err != nil || challenge != nil {
+		t.Fatalf("Expected no challenge without policy, got %v, %v", challenge, err)
+	}
+
+	if err := svc.SetPolicy(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, &model.TwoFactorPolicy{Role: "guest", Required: true}); !errors.Is(err, ErrUnknownRole) {
+		t.Errorf("Expected ErrUnknownRole, got %v", err)
+	}
+	if err := svc.SetPolicy(ctx, model.Actor{UserID: "A001", Role: model.RoleAdmin}, &model.TwoFactorPolicy{Role: model.RoleAdmin, Required: true}); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+
+	challenge, err = svc.BeginLogin(ctx, "A001", model.RoleAdmin, "A001")
+	if err != nil || challenge == nil || !challenge.SetupRequired {
+		t.Fatalf("Expected setup challenge, got %+v, %v", challenge, err)
+	}
+
+	req := &model.TwoFactorLoginRequest{ChallengeToken: challenge.Token}
+	req.Code = "123456"
+	if _, err := svc.CompleteLogin(ctx, req); !errors.Is(err, ErrTwoFactorNotEnrolled) {
+		t.Errorf("Expected ErrTwoFactorNotEnrolled before setup, got %v", err)
+	}
+
+	enrollment, err := svc.EnrollWithChallenge(ctx, challenge.Token)
+	if err != nil {
+		t.Fatalf("EnrollWithChallenge failed: %v", err)
+	}
+	req.Code = codeAt(t, enrollment.Secret, *now)
+	result, err := svc.CompleteLogin(ctx, req)
+	if err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	if len(result.RecoveryCodes) != recoveryCodeCount {
+		t.Errorf("Expected recovery codes after setup, got %d", len(result.RecoveryCodes))
+	}
+
+	*now = now.Add(30 * time.Second)
+	disable := &model.TwoFactorCodeRequest{Code: codeAt(t, enrollment.Secret, *now)}
+	if err := svc.Disable(ctx, "A001", model.RoleAdmin, disable); !errors.Is(err, ErrTwoFactorRequired) {
+		t.Errorf("Expected ErrTwoFactorRequired, got %v", err)
+	}
+
+	if err := svc.Reset(ctx, model.Actor{UserID: "A002", Role: model.RoleAdmin}, "A001", model.RoleAdmin); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	challenge, _ = svc.BeginLogin(ctx, "A001", model.RoleAdmin, "A001")
+	if challenge == nil || !challenge.SetupRequired {
+		t.Errorf("Expected setup challenge after reset, got %+v", challenge)
+	}
+}
+
+func TestTwoFactorDisable(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestTwoFactorService()
+	_, codes := enrollTestAccount(t, svc, now)
+
+	if err := svc.Disable(ctx, "S001", model.RoleStudent, &model.TwoFactorCodeRequest{Code: "000000"}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected ErrInvalidTwoFactorCode, got %v", err)
+	}
+	if err := svc.Disable(ctx, "S001", model.RoleStudent, &model.TwoFactorCodeRequest{RecoveryCode: codes[1]}); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+
+	challenge, err := svc.BeginLogin(ctx, "S001", model.RoleStudent, "S001")
+	if err != nil || challenge != nil {
+		t.Errorf("Expected no challenge after disable, got %v, %v", challenge, err)
+	}
+}
@@ -10,30 +10,103 @@ import (
 
 // Config 包含应用程序的所有配置
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	JWT      JWTConfig      `yaml:"jwt"`
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Password  PasswordConfig  `yaml:"password"`
+	Notifier  NotifierConfig  `yaml:"notifier"`
+	Lockout   LockoutConfig   `yaml:"lockout"`
+	TwoFactor TwoFactorConfig `yaml:"twoFactor"`
+	OIDC      OIDCConfig      `yaml:"oidc"`
 }
 
 // ServerConfig 包含服务器相关配置
 type ServerConfig struct {
 	Port    int `yaml:"port"`
-	Timeout int `yaml:"timeout"`
+	Timeout int `yaml:"timeout"` // 单个请求的处理时限（秒），不大于0时不限制
 }
 
 // DatabaseConfig 包含数据库相关配置
 type DatabaseConfig struct {
-	Driver          string `yaml:"driver"`
+	Driver          string `yaml:"driver"` // 数据库类型：mysql、postgres 或 sqlite
 	DSN             string `yaml:"dsn"`
 	MaxOpenConns    int    `yaml:"maxOpenConns"`
 	MaxIdleConns    int    `yaml:"maxIdleConns"`
 	ConnMaxLifetime int    `yaml:"connMaxLifetime"`
+	MigrationsDir   string `yaml:"migrationsDir"` // 迁移文件根目录，每种数据库的迁移在同名子目录中
+	AutoMigrate     bool   `yaml:"autoMigrate"`   // 启动时执行尚未执行的迁移，关闭后需要用 cmd/migrate 手动迁移
 }
 
 // JWTConfig 包含JWT相关配置
 type JWTConfig struct {
-	Secret     string `yaml:"secret"`
-	Expiration int    `yaml:"expiration"`
+	Secret            string         `yaml:"secret"`            // HS256密钥，kid为 "default"
+	Expiration        int            `yaml:"expiration"`        // 访问令牌有效期（秒）
+	RefreshExpiration int            `yaml:"refreshExpiration"` // 刷新令牌有效期（秒）
+	ActiveKey         string         `yaml:"activeKey"`         // 签发新令牌使用的kid
+	Keys              []JWTKeyConfig `yaml:"keys"`              // 可用于验证的全部密钥
+}
+
+// JWTKeyConfig 包含一把JWT签名密钥的配置
+type JWTKeyConfig struct {
+	ID             string `yaml:"id"`             // kid
+	Algorithm      string `yaml:"algorithm"`      // HS256、RS256或EdDSA，默认HS256
+	Secret         string `yaml:"secret"`         // HS256密钥
+	PrivateKeyFile string `yaml:"privateKeyFile"` // RS256/EdDSA私钥PEM文件，只用于验证的旧密钥可以省略
+	PublicKeyFile  string `yaml:"publicKeyFile"`  // RS256/EdDSA公钥PEM文件，省略时从私钥推导
+}
+
+// PasswordConfig 包含密码策略和找回密码相关配置
+type PasswordConfig struct {
+	MinLength       int  `yaml:"minLength"`       // 最小长度，默认8
+	RequireUpper    bool `yaml:"requireUpper"`    // 必须包含大写字母
+	RequireLower    bool `yaml:"requireLower"`    // 必须包含小写字母
+	RequireDigit    bool `yaml:"requireDigit"`    // 必须包含数字
+	RequireSymbol   bool `yaml:"requireSymbol"`   // 必须包含符号
+	ResetExpiration int  `yaml:"resetExpiration"` // 重置令牌有效期（秒）
+}
+
+// NotifierConfig 包含通知发送相关配置
+type NotifierConfig struct {
+	Type string `yaml:"type"` // log 或 file
+	Path string `yaml:"path"` // file 类型写入的文件
+}
+
+// LockoutConfig 包含登录失败退避和账号锁定相关配置
+type LockoutConfig struct {
+	MaxFailures    int `yaml:"maxFailures"`    // 账号连续失败多少次后锁定，需管理员解锁；知道账号的人可以故意输错来触发，不宜设得过小
+	FreeAttempts   int `yaml:"freeAttempts"`   // 账号开始退避前允许的失败次数
+	IPFreeAttempts int `yaml:"ipFreeAttempts"` // 同一IP开始退避前允许的失败次数
+	BaseDelay      int `yaml:"baseDelay"`      // 首次退避时间（秒），之后每次失败翻倍
+	MaxDelay       int `yaml:"maxDelay"`       // 最长退避时间（秒）
+	IPWindow       int `yaml:"ipWindow"`       // 同一IP多久没有失败后清零计数（秒）
+}
+
+// TwoFactorConfig 包含TOTP两步验证相关配置
+type TwoFactorConfig struct {
+	Issuer              string `yaml:"issuer"`              // 验证器应用中显示的发行方名称
+	Skew                int    `yaml:"skew"`                // 允许前后偏差的时间步数（每步30秒）
+	ChallengeExpiration int    `yaml:"challengeExpiration"` // 登录第二步的有效期（秒）
+	MaxAttempts         int    `yaml:"maxAttempts"`         // 每次登录挑战允许输错验证码的次数
+}
+
+// OIDCConfig 包含OpenID Connect单点登录相关配置
+type OIDCConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	Issuer          string            `yaml:"issuer"`          // 身份提供方的issuer，从 <issuer>/.well-known/openid-configuration 获取端点
+	ClientID        string            `yaml:"clientID"`        // 在身份提供方注册的客户端ID
+	ClientSecret    string            `yaml:"clientSecret"`    // 客户端密钥
+	RedirectURL     string            `yaml:"redirectURL"`     // 回调地址，指向 /api/oidc/callback
+	Scopes          []string          `yaml:"scopes"`          // 申请的scope，默认 openid email profile
+	StateExpiration int               `yaml:"stateExpiration"` // 从跳转到回调的最长时间（秒）
+	RoleClaim       string            `yaml:"roleClaim"`       // 决定登录角色的声明，值可以是字符串或字符串数组
+	RoleMappings    []OIDCRoleMapping `yaml:"roleMappings"`    // 声明值到登录角色的映射，按顺序取第一个匹配
+	IDClaims        map[string]string `yaml:"idClaims"`        // 登录角色到携带学号或工号的声明，例如 student: student_number
+}
+
+// OIDCRoleMapping 把角色声明中的一个值映射为登录角色
+type OIDCRoleMapping struct {
+	Value string `yaml:"value"` // 声明值，例如 "students"
+	Role  string `yaml:"role"`  // student 或 instructor
 }
 
 // Load 从文件加载配置
@@ -64,10 +137,49 @@ func LoadFromEnv() *Config {
 			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 10),
 			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
 			ConnMaxLifetime: getEnvAsInt("DB_CONN_MAX_LIFETIME", 3600),
+			MigrationsDir:   getEnv("DB_MIGRATIONS_DIR", "../scripts/migrations"),
+			AutoMigrate:     getEnvAsBool("DB_AUTO_MIGRATE", true),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-secret-key-here"),
-			Expiration: getEnvAsInt("JWT_EXPIRATION", 86400),
+			Secret:            getEnv("JWT_SECRET", "your-secret-key-here"),
+			Expiration:        getEnvAsInt("JWT_EXPIRATION", 900),
+			RefreshExpiration: getEnvAsInt("JWT_REFRESH_EXPIRATION", 1209600),
+			ActiveKey:         getEnv("JWT_ACTIVE_KEY", ""),
+		},
+		Password: PasswordConfig{
+			MinLength:       getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			RequireUpper:    getEnvAsBool("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:    getEnvAsBool("PASSWORD_REQUIRE_LOWER", false),
+			RequireDigit:    getEnvAsBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:   getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+			ResetExpiration: getEnvAsInt("PASSWORD_RESET_EXPIRATION", 1800),
+		},
+		Lockout: LockoutConfig{
+			MaxFailures:    getEnvAsInt("LOCKOUT_MAX_FAILURES", 10),
+			FreeAttempts:   getEnvAsInt("LOCKOUT_FREE_ATTEMPTS", 3),
+			IPFreeAttempts: getEnvAsInt("LOCKOUT_IP_FREE_ATTEMPTS", 20),
+			BaseDelay:      getEnvAsInt("LOCKOUT_BASE_DELAY", 1),
+			MaxDelay:       getEnvAsInt("LOCKOUT_MAX_DELAY", 900),
+			IPWindow:       getEnvAsInt("LOCKOUT_IP_WINDOW", 3600),
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:              getEnv("TWO_FACTOR_ISSUER", "Student Management System"),
+			Skew:                getEnvAsInt("TWO_FACTOR_SKEW", 1),
+			ChallengeExpiration: getEnvAsInt("TWO_FACTOR_CHALLENGE_EXPIRATION", 300),
+			MaxAttempts:         getEnvAsInt("TWO_FACTOR_MAX_ATTEMPTS", 5),
+		},
+		OIDC: OIDCConfig{
+			Enabled:         getEnvAsBool("OIDC_ENABLED", false),
+			Issuer:          getEnv("OIDC_ISSUER", ""),
+			ClientID:        getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:    getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:     getEnv("OIDC_REDIRECT_URL", ""),
+			StateExpiration: getEnvAsInt("OIDC_STATE_EXPIRATION", 600),
+			RoleClaim:       getEnv("OIDC_ROLE_CLAIM", "groups"),
+		},
+		Notifier: NotifierConfig{
+			Type: getEnv("NOTIFIER_TYPE", "log"),
+			Path: getEnv("NOTIFIER_PATH", ""),
 		},
 	}
 }
@@ -86,4 +198,13 @@ func getEnvAsInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
 }
\ No newline at end of file
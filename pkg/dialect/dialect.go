@@ -0,0 +1,93 @@
+// Package dialect 屏蔽MySQL、PostgreSQL和SQLite之间的SQL差异
+//
+// 仓库中的SQL统一使用 ? 作为占位符、使用三种数据库共有的语法书写，
+// 各数据库不同的部分（占位符、upsert、分页、行锁、错误码）由Dialect生成或识别。
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// 支持的数据库名称，与配置文件中的 database.driver 对应
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// Dialect 描述一种数据库的SQL差异
+type Dialect interface {
+	// Name 返回数据库名称，同时是迁移文件所在的子目录名
+	Name() string
+
+	// DriverName 返回 sql.Open 使用的驱动名称
+	DriverName() string
+
+	// Rebind 把 ? 占位符转换为数据库使用的占位符，引号和注释中的 ? 不转换
+	Rebind(query string) string
+
+	// ConvertArgs 把参数转换为数据库能正确比较和保存的形式
+	ConvertArgs(args []interface{}) []interface{}
+
+	// Upsert 返回接在 INSERT ... VALUES (...) 之后的冲突更新子句
+	// assignments 形如 "col = excluded.col"，excluded.col 表示本次要插入的值；
+	// 引用已有行的值时用表名限定列名，例如 "failures = login_throttle.failures + 1"
+	Upsert(conflictColumns []string, assignments ...string) string
+
+	// LimitOffset 返回分页子句，limit小于等于0表示不限制条数
+	LimitOffset(limit int, offset int) string
+
+	// ForUpdate 返回对查询结果加排他锁的子句，不支持行锁的数据库返回空字符串
+	ForUpdate() string
+
+	// SupportsLastInsertID 返回是否可以通过 sql.Result.LastInsertId 获取自增主键，
+	// 不支持时需要在INSERT语句后加 RETURNING 子句
+	SupportsLastInsertID() bool
+
+	// IsUniqueViolation 判断错误是否为主键或唯一索引冲突
+	IsUniqueViolation(err error) bool
+
+	// IsForeignKeyViolation 判断错误是否为外键约束失败
+	IsForeignKeyViolation(err error) bool
+}
+
+// Names 返回所有支持的数据库名称
+func Names() []string {
+	return []string{MySQL, Postgres, SQLite}
+}
+
+// Get 根据配置中的驱动名称返回对应的Dialect
+func Get(driver string) (Dialect, error) {
+	switch strings.ToLower(driver) {
+	case MySQL:
+		return mysqlDialect{}, nil
+	case Postgres, "postgresql", "pq":
+		return postgresDialect{}, nil
+	case SQLite, "sqlite3":
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q, expected one of %s", driver, strings.Join(Names(), ", "))
+	}
+}
+
+// Open 按配置中的驱动名称打开数据库，返回连接池和对应的Dialect
+func Open(driver string, dsn string) (*sql.DB, Dialect, error) {
+	d, err := Get(driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sql.Open(d.DriverName(), dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening %s database: %w", d.Name(), err)
+	}
+
+	return db, d, nil
+}
+
+// upsert 生成 ON CONFLICT (...) DO UPDATE SET 子句，PostgreSQL和SQLite共用
+func upsert(conflictColumns []string, assignments []string) string {
+	return ` ON CONFLICT (` + strings.Join(conflictColumns, ", ") + `) DO UPDATE SET ` + strings.Join(assignments, ", ")
+}
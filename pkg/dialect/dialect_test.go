@@ -0,0 +1,128 @@
+package dialect
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	for driver, want := range map[string]string{"mysql": MySQL, "PostgreSQL": Postgres, "pq": Postgres, "sqlite3": SQLite} {
+		d, err := Get(driver)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", driver, err)
+		}
+		if d.Name() != want {
+			t.Errorf("Get(%q) = %s, expected %s", driver, d.Name(), want)
+		}
+	}
+
+	if _, err := Get("oracle"); err == nil {
+		t.Error("Expected error for unsupported driver")
+	}
+}
+
+func TestPostgresRebind(t *testing.T) {
+	query := `SELECT '?', "a?b" FROM t -- why?
+WHERE a = ? /* ? */ AND b IN (?, ?)`
+	want := `SELECT '?', "a?b" FROM t -- why?
+WHERE a = $1 /* ? */ AND b IN ($2, $3)`
+
+	if got := (postgresDialect{}).Rebind(query); got != want {
+		t.Errorf("Rebind() = %q, expected %q", got, want)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	columns := []string{"scope", "throttle_key"}
+	assignments := []string{"failures = login_throttle.failures + 1", "last_failure_at = excluded.last_failure_at"}
+
+	want := map[string]string{
+		MySQL:    ` ON DUPLICATE KEY UPDATE failures = login_throttle.failures + 1, last_failure_at = VALUES(last_failure_at)`,
+		Postgres: ` ON CONFLICT (scope, throttle_key) DO UPDATE SET failures = login_throttle.failures + 1, last_failure_at = excluded.last_failure_at`,
+		SQLite:   ` ON CONFLICT (scope, throttle_key) DO UPDATE SET failures = login_throttle.failures + 1, last_failure_at = excluded.last_failure_at`,
+	}
+	for _, name := range Names() {
+		d, _ := Get(name)
+		if got := d.Upsert(columns, assignments...); got != want[name] {
+			t.Errorf("%s Upsert() = %q, expected %q", name, got, want[name])
+		}
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit, offset int
+		want          string
+	}{
+		{MySQL, 10, 20, " LIMIT 10 OFFSET 20"},
+		{MySQL, 0, 20, " LIMIT 18446744073709551615 OFFSET 20"},
+		{Postgres, 0, 20, " OFFSET 20"},
+		{SQLite, 0, 20, " LIMIT -1 OFFSET 20"},
+		{SQLite, 5, 0, " LIMIT 5"},
+		{Postgres, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		d, _ := Get(tt.name)
+		if got := d.LimitOffset(tt.limit, tt.offset); got != tt.want {
+			t.Errorf("%s LimitOffset(%d, %d) = %q, expected %q", tt.name, tt.limit, tt.offset, got, tt.want)
+		}
+	}
+}
+
+// TestSQLite 在临时数据库上检查时间参数的比较和约束错误的识别
+func TestSQLite(t *testing.T) {
+	db, d, err := Open(SQLite, "file:"+filepath.Join(t.TempDir(), "dialect.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	exec := func(query string, args ...interface{}) error {
+		_, err := db.Exec(d.Rebind(query), d.ConvertArgs(args)...)
+		return err
+	}
+
+	if err := exec(`CREATE TABLE parent (id INTEGER PRIMARY KEY, at DATETIME NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := exec(`CREATE TABLE child (id INTEGER PRIMARY KEY, parent_id INTEGER NOT NULL REFERENCES parent(id))`); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不同时区的同一时刻保存后按UTC比较
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	if err := exec(`INSERT INTO parent (id, at) VALUES (?, ?)`, 1, at); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM parent WHERE at < ?`, d.ConvertArgs([]interface{}{at.Add(time.Second).UTC()})...).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected the stored time to compare before a later UTC time, got count %d", count)
+	}
+
+	var stored time.Time
+	if err := db.QueryRow(`SELECT at FROM parent WHERE id = 1`).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Equal(at) {
+		t.Errorf("Expected %v, got %v", at, stored)
+	}
+
+	var null sql.NullTime
+	if got := d.ConvertArgs([]interface{}{null})[0]; got != null {
+		t.Errorf("Expected invalid NullTime to be passed through, got %v", got)
+	}
+
+	if err := exec(`INSERT INTO parent (id, at) VALUES (?, ?)`, 1, at); !d.IsUniqueViolation(err) {
+		t.Errorf("Expected unique violation, got %v", err)
+	}
+	if err := exec(`INSERT INTO child (id, parent_id) VALUES (?, ?)`, 1, 2); !d.IsForeignKeyViolation(err) {
+		t.Errorf("Expected foreign key violation, got %v", err)
+	}
+}
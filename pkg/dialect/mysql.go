@@ -0,0 +1,68 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL错误码
+const (
+	mysqlDuplicateEntry  = 1062 // ER_DUP_ENTRY
+	mysqlRowIsReferenced = 1451 // ER_ROW_IS_REFERENCED_2，删除或修改被引用的行
+	mysqlNoReferencedRow = 1452 // ER_NO_REFERENCED_ROW_2，引用的行不存在
+)
+
+// excludedColumn 匹配upsert赋值中引用待插入值的 excluded.col
+var excludedColumn = regexp.MustCompile(`\bexcluded\.(\w+)`)
+
+// mysqlDialect MySQL方言
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string       { return MySQL }
+func (mysqlDialect) DriverName() string { return "mysql" }
+
+// Rebind MySQL本身使用 ? 占位符
+func (mysqlDialect) Rebind(query string) string { return query }
+
+// ConvertArgs 时间参数由驱动按DSN中的loc转换，不需要处理
+func (mysqlDialect) ConvertArgs(args []interface{}) []interface{} { return args }
+
+// Upsert 使用 ON DUPLICATE KEY UPDATE，冲突列由表的主键和唯一索引决定，excluded.col 改写为 VALUES(col)
+func (mysqlDialect) Upsert(conflictColumns []string, assignments ...string) string {
+	rewritten := make([]string, len(assignments))
+	for i, assignment := range assignments {
+		rewritten[i] = excludedColumn.ReplaceAllString(assignment, "VALUES($1)")
+	}
+	return ` ON DUPLICATE KEY UPDATE ` + strings.Join(rewritten, ", ")
+}
+
+// LimitOffset MySQL不支持单独的OFFSET，不限制条数时使用无符号BIGINT的最大值
+func (mysqlDialect) LimitOffset(limit int, offset int) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf(" LIMIT %d", limit)
+	case offset > 0:
+		return fmt.Sprintf(" LIMIT 18446744073709551615 OFFSET %d", offset)
+	default:
+		return ""
+	}
+}
+
+func (mysqlDialect) ForUpdate() string          { return " FOR UPDATE" }
+func (mysqlDialect) SupportsLastInsertID() bool { return true }
+
+func (mysqlDialect) IsUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
+
+func (mysqlDialect) IsForeignKeyViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && (mysqlErr.Number == mysqlNoReferencedRow || mysqlErr.Number == mysqlRowIsReferenced)
+}
@@ -0,0 +1,105 @@
+package dialect
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTimeFormat SQLite中保存时间使用的格式
+// 统一转换为UTC后按该格式保存，字符串顺序与时间先后一致，SQL中可以直接比较时间；
+// 驱动读取声明为DATETIME的列时按该格式解析回 time.Time
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// sqliteDialect SQLite方言
+//
+// DSN建议加上 _pragma=foreign_keys(1) 启用外键（级联删除依赖外键），
+// _pragma=busy_timeout(5000) 等待其他连接释放写锁，
+// _txlock=immediate 使事务开始时即获得写锁，代替 SELECT ... FOR UPDATE 串行化选课操作
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string       { return SQLite }
+func (sqliteDialect) DriverName() string { return "sqlite" }
+
+// Rebind SQLite支持 ? 占位符
+func (sqliteDialect) Rebind(query string) string { return query }
+
+// ConvertArgs 把时间参数转换为UTC字符串，驱动默认使用 time.Time.String()，无法在SQL中比较先后
+func (sqliteDialect) ConvertArgs(args []interface{}) []interface{} {
+	var converted []interface{}
+	for i, arg := range args {
+		var value interface{}
+		switch v := arg.(type) {
+		case time.Time:
+			value = v.UTC().Format(sqliteTimeFormat)
+		case *time.Time:
+			if v == nil {
+				continue
+			}
+			value = v.UTC().Format(sqliteTimeFormat)
+		case sql.NullTime:
+			if !v.Valid {
+				continue
+			}
+			value = v.Time.UTC().Format(sqliteTimeFormat)
+		default:
+			continue
+		}
+
+		if converted == nil {
+			converted = make([]interface{}, len(args))
+			copy(converted, args)
+		}
+		converted[i] = value
+	}
+
+	if converted == nil {
+		return args
+	}
+	return converted
+}
+
+func (sqliteDialect) Upsert(conflictColumns []string, assignments ...string) string {
+	return upsert(conflictColumns, assignments)
+}
+
+// LimitOffset SQLite用 LIMIT -1 表示不限制条数
+func (sqliteDialect) LimitOffset(limit int, offset int) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf(" LIMIT %d", limit)
+	case offset > 0:
+		return fmt.Sprintf(" LIMIT -1 OFFSET %d", offset)
+	default:
+		return ""
+	}
+}
+
+// ForUpdate SQLite没有行锁，写事务本身是串行的
+func (sqliteDialect) ForUpdate() string          { return "" }
+func (sqliteDialect) SupportsLastInsertID() bool { return true }
+
+func (sqliteDialect) IsUniqueViolation(err error) bool {
+	code, ok := sqliteCode(err)
+	return ok && (code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
+
+func (sqliteDialect) IsForeignKeyViolation(err error) bool {
+	code, ok := sqliteCode(err)
+	return ok && code == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
+
+// sqliteCode 返回SQLite的扩展错误码
+func sqliteCode(err error) (int, bool) {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return 0, false
+	}
+	return sqliteErr.Code(), true
+}
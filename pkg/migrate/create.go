@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// invalidNameChars 迁移名称中需要替换为下划线的字符
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create 在目录中新建下一个版本的up和down迁移文件，返回两个文件的路径
+func Create(dir string, name string) (string, string, error) {
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("migration name must contain letters or digits")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+
+	version := int64(1)
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", version, name)
+	upPath := filepath.Join(dir, base+".up.sql")
+	downPath := filepath.Join(dir, base+".down.sql")
+
+	up := fmt.Sprintf("-- %s\n-- 执行后不要再修改本文件，需要修正时新建一个迁移\n\n", base)
+	down := fmt.Sprintf("-- %s 的回滚，撤销up脚本的全部变更\n\n", base)
+
+	if err := writeNewFile(upPath, up); err != nil {
+		return "", "", err
+	}
+	if err := writeNewFile(downPath, down); err != nil {
+		os.Remove(upPath)
+		return "", "", err
+	}
+
+	return upPath, downPath, nil
+}
+
+// writeNewFile 创建文件，文件已存在时返回错误
+func writeNewFile(path string, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("error creating migration file: %w", err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing migration file: %w", err)
+	}
+	return f.Close()
+}
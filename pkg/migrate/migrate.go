@@ -0,0 +1,565 @@
+// Package migrate 实现带版本号的数据库结构迁移
+//
+// 迁移文件命名为 "<版本号>_<名称>.up.sql" 和 "<版本号>_<名称>.down.sql"，例如 0003_add_waitlist.up.sql。
+// 已执行的迁移记录在 schema_migrations 表中，连同up脚本的SHA-256；
+// 已执行的迁移文件被修改或删除时拒绝继续迁移，需要新建一个迁移来修正。
+// 数据库中已经有表却没有任何迁移记录时（引入迁移之前创建的库）同样拒绝迁移，需要先把数据迁到新库。
+// 不同数据库的迁移文件放在以数据库名称命名的子目录中（mysql、postgres、sqlite），
+// 各子目录的版本号和名称必须一一对应。
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/student-management-system/pkg/dialect"
+)
+
+var (
+	// ErrChecksumMismatch 已执行的迁移文件在执行后被修改
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+
+	// ErrMissingMigration 数据库中记录已执行的迁移找不到对应文件
+	ErrMissingMigration = errors.New("applied migration is missing")
+
+	// ErrLocked 等待其他实例完成迁移超时
+	ErrLocked = errors.New("timed out waiting for migration lock")
+
+	// ErrNoDownMigration 迁移没有down脚本，不能回滚
+	ErrNoDownMigration = errors.New("migration has no down script")
+
+	// ErrUnversionedSchema 数据库中已经有表但没有迁移记录，通常是引入迁移之前用建表脚本创建的库
+	ErrUnversionedSchema = errors.New("database has tables but no migration history")
+)
+
+const (
+	// versionTable 记录已执行迁移的表
+	versionTable = "schema_migrations"
+
+	// lockName 迁移锁的名称，同一数据库的所有实例共用
+	lockName = "schema_migrations"
+
+	// pgLockKey PostgreSQL咨询锁的键，同一数据库的所有实例共用
+	pgLockKey = 4120731906
+
+	// defaultLockTimeout 等待其他实例完成迁移的最长时间
+	defaultLockTimeout = 60 * time.Second
+
+	// lockPollInterval 等待PostgreSQL咨询锁时重试的间隔
+	lockPollInterval = 500 * time.Millisecond
+)
+
+// fileNamePattern 迁移文件名格式
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 表示一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum 返回up脚本的SHA-256，用于发现执行后被修改的迁移
+func (m *Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// String 返回 "<版本号>_<名称>"
+func (m *Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Applied 表示一条已执行迁移的记录
+type Applied struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Status 表示一个迁移的执行状态
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"` // 为空表示尚未执行
+	Modified  bool       `json:"modified"`             // 执行后文件被修改
+	Missing   bool       `json:"missing"`              // 已执行但找不到文件
+}
+
+// Load 读取目录中的迁移文件，按版本号排序
+// 每个版本必须有up脚本，down脚本可以没有；目录中的其他文件被忽略
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, expected <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s has no up script", m)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator 在数据库上执行迁移
+//
+// 执行和回滚期间持有迁移锁，多个实例同时启动时只有一个执行迁移，
+// 其余实例等待锁释放后发现没有待执行的迁移直接返回：
+// MySQL使用命名锁（GET_LOCK），PostgreSQL使用咨询锁（pg_try_advisory_lock），
+// SQLite在整个执行期间持有写事务（BEGIN IMMEDIATE），等待时间由DSN中的busy_timeout决定。
+//
+// MySQL的DDL会隐式提交，迁移不在事务中执行；迁移中途失败时版本不会被记录，
+// 修正后重新执行，因此迁移脚本应尽量写成可重复执行的形式（IF NOT EXISTS等）。
+// PostgreSQL的每个迁移连同版本记录在一个事务中执行，SQLite的一次执行整体在一个事务中，失败时全部回滚。
+type Migrator struct {
+	db          *sql.DB
+	dialect     dialect.Dialect
+	migrations  []*Migration
+	lockTimeout time.Duration
+	logf        func(format string, args ...interface{})
+	now         func() time.Time // 当前时间，测试中可替换
+}
+
+// New 创建迁移执行器，migrations必须是该数据库子目录中的迁移
+func New(db *sql.DB, d dialect.Dialect, migrations []*Migration) *Migrator {
+	return &Migrator{
+		db:          db,
+		dialect:     d,
+		migrations:  migrations,
+		lockTimeout: defaultLockTimeout,
+		logf:        log.Printf,
+		now:         time.Now,
+	}
+}
+
+// Up 按版本号顺序执行尚未执行的迁移，target大于0时只执行到该版本为止，返回本次执行的迁移
+func (m *Migrator) Up(target int64) ([]*Migration, error) {
+	var done []*Migration
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.readApplied(conn)
+		if err != nil {
+			return err
+		}
+
+		// 初始迁移使用CREATE TABLE IF NOT EXISTS，在旧库上执行会被记为已执行而缺少后来加的列
+		if len(applied) == 0 {
+			if err := m.checkEmpty(conn); err != nil {
+				return err
+			}
+		}
+
+		pending, err := plan(m.migrations, applied)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			if target > 0 && migration.Version > target {
+				break
+			}
+
+			m.logf("Applying migration %s", migration)
+			err := m.inTransaction(conn, func() error {
+				if err := execScript(conn, migration.Up); err != nil {
+					return fmt.Errorf("error applying migration %s: %w", migration, err)
+				}
+
+				query := `INSERT INTO ` + versionTable + ` (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`
+				if err := m.exec(conn, query, migration.Version, migration.Name, migration.Checksum(), m.now()); err != nil {
+					return fmt.Errorf("error recording migration %s: %w", migration, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 按版本号倒序回滚最近执行的steps个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(steps int) ([]*Migration, error) {
+	var done []*Migration
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.readApplied(conn)
+		if err != nil {
+			return err
+		}
+
+		if _, err := plan(m.migrations, applied); err != nil {
+			return err
+		}
+
+		byVersion := make(map[int64]*Migration, len(m.migrations))
+		for _, migration := range m.migrations {
+			byVersion[migration.Version] = migration
+		}
+
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := byVersion[applied[i].Version]
+			if migration.Down == "" {
+				return fmt.Errorf("%w: %s", ErrNoDownMigration, migration)
+			}
+
+			m.logf("Rolling back migration %s", migration)
+			err := m.inTransaction(conn, func() error {
+				if err := execScript(conn, migration.Down); err != nil {
+					return fmt.Errorf("error rolling back migration %s: %w", migration, err)
+				}
+
+				query := `DELETE FROM ` + versionTable + ` WHERE version = ?`
+				if err := m.exec(conn, query, migration.Version); err != nil {
+					return fmt.Errorf("error removing migration record %s: %w", migration, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status 返回所有迁移的执行状态，包括已执行但找不到文件的迁移
+func (m *Migrator) Status() ([]*Status, error) {
+	conn, err := m.db.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("error getting database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := m.ensureVersionTable(conn); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.readApplied(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return status(m.migrations, applied), nil
+}
+
+// withLock 在持有迁移锁的连接上执行fn
+// 锁属于数据库会话，因此加锁、迁移和解锁必须使用同一个连接
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) (err error) {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting database connection: %w", err)
+	}
+	defer conn.Close()
+
+	unlock, err := m.lock(conn)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := unlock(err); unlockErr != nil {
+			if err == nil {
+				err = unlockErr
+			} else {
+				m.logf("Failed to release migration lock: %v", unlockErr)
+			}
+		}
+	}()
+
+	if err := m.ensureVersionTable(conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// lock 获取迁移锁，返回的unlock根据迁移结果释放锁
+func (m *Migrator) lock(conn *sql.Conn) (unlock func(result error) error, err error) {
+	ctx := context.Background()
+
+	switch m.dialect.Name() {
+	case dialect.Postgres:
+		deadline := time.Now().Add(m.lockTimeout)
+		for {
+			var acquired bool
+			if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, pgLockKey).Scan(&acquired); err != nil {
+				return nil, fmt.Errorf("error acquiring migration lock: %w", err)
+			}
+			if acquired {
+				break
+			}
+			if time.Now().After(deadline) {
+				return nil, ErrLocked
+			}
+			time.Sleep(lockPollInterval)
+		}
+		return func(error) error {
+			_, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, pgLockKey)
+			return err
+		}, nil
+
+	case dialect.SQLite:
+		if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+			return nil, fmt.Errorf("error acquiring migration lock: %w", err)
+		}
+		return func(result error) error {
+			if result != nil {
+				_, err := conn.ExecContext(ctx, `ROLLBACK`)
+				return err
+			}
+			_, err := conn.ExecContext(ctx, `COMMIT`)
+			return err
+		}, nil
+
+	default:
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, int(m.lockTimeout.Seconds())).Scan(&acquired); err != nil {
+			return nil, fmt.Errorf("error acquiring migration lock: %w", err)
+		}
+		if !acquired.Valid || acquired.Int64 != 1 {
+			return nil, ErrLocked
+		}
+		return func(error) error {
+			_, err := conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, lockName)
+			return err
+		}, nil
+	}
+}
+
+// inTransaction 在PostgreSQL上把一个迁移的脚本和版本记录放在同一个事务中执行
+// MySQL的DDL会隐式提交，SQLite的整次执行已经在事务中，直接执行fn
+func (m *Migrator) inTransaction(conn *sql.Conn, fn func() error) error {
+	if m.dialect.Name() != dialect.Postgres {
+		return fn()
+	}
+
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, `BEGIN`); err != nil {
+		return fmt.Errorf("error beginning migration transaction: %w", err)
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := conn.ExecContext(ctx, `ROLLBACK`); rbErr != nil {
+			m.logf("Failed to roll back migration transaction: %v", rbErr)
+		}
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `COMMIT`); err != nil {
+		return fmt.Errorf("error committing migration transaction: %w", err)
+	}
+	return nil
+}
+
+// exec 按方言改写占位符和参数后执行语句
+func (m *Migrator) exec(conn *sql.Conn, query string, args ...interface{}) error {
+	_, err := conn.ExecContext(context.Background(), m.dialect.Rebind(query), m.dialect.ConvertArgs(args)...)
+	return err
+}
+
+// ensureVersionTable 创建记录已执行迁移的表
+func (m *Migrator) ensureVersionTable(conn *sql.Conn) error {
+	timeType := "DATETIME"
+	if m.dialect.Name() == dialect.Postgres {
+		timeType = "TIMESTAMPTZ"
+	}
+
+	query := `
+		CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at ` + timeType + ` NOT NULL
+		)
+	`
+
+	if _, err := conn.ExecContext(context.Background(), query); err != nil {
+		return fmt.Errorf("error creating %s table: %w", versionTable, err)
+	}
+	return nil
+}
+
+// checkEmpty 确认数据库中除了迁移记录表之外没有其他表
+func (m *Migrator) checkEmpty(conn *sql.Conn) error {
+	var query string
+	switch m.dialect.Name() {
+	case dialect.SQLite:
+		query = `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> ?`
+	case dialect.Postgres:
+		query = `SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_name <> ?`
+	default:
+		query = `SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name <> ?`
+	}
+
+	var table string
+	err := conn.QueryRowContext(context.Background(), m.dialect.Rebind(query), versionTable).Scan(&table)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error checking existing tables: %w", err)
+	}
+	return fmt.Errorf("%w (found table %q): back up the data, migrate an empty database and load the data into it, "+
+		"or drop the existing tables before running the migrations", ErrUnversionedSchema, table)
+}
+
+// readApplied 按版本号顺序读取已执行的迁移
+func (m *Migrator) readApplied(conn *sql.Conn) ([]*Applied, error) {
+	query := `SELECT version, name, checksum, applied_at FROM ` + versionTable + ` ORDER BY version`
+
+	rows, err := conn.QueryContext(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []*Applied
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("error scanning applied migration: %w", err)
+		}
+		applied = append(applied, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// execScript 逐条执行脚本中的语句
+func execScript(conn *sql.Conn, script string) error {
+	for _, stmt := range SplitStatements(script) {
+		if _, err := conn.ExecContext(context.Background(), stmt); err != nil {
+			return fmt.Errorf("%w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// Exec 逐条执行SQL脚本，用于不属于迁移的脚本，例如示例数据
+func Exec(db *sql.DB, script string) error {
+	for _, stmt := range SplitStatements(script) {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("%w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// plan 校验已执行的迁移与文件一致，返回按版本号排序的待执行迁移
+// 比已执行的最大版本号小但尚未执行的迁移（例如合并分支带来的迁移）同样会被执行
+func plan(migrations []*Migration, applied []*Applied) ([]*Migration, error) {
+	byVersion := make(map[int64]*Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		migration, ok := byVersion[a.Version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %04d_%s", ErrMissingMigration, a.Version, a.Name)
+		}
+		if migration.Checksum() != a.Checksum {
+			return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, migration)
+		}
+		done[a.Version] = true
+	}
+
+	var pending []*Migration
+	for _, migration := range migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// status 合并迁移文件和执行记录
+func status(migrations []*Migration, applied []*Applied) []*Status {
+	byVersion := make(map[int64]*Applied, len(applied))
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+
+	var statuses []*Status
+	for _, migration := range migrations {
+		s := &Status{Version: migration.Version, Name: migration.Name}
+		if a, ok := byVersion[migration.Version]; ok {
+			appliedAt := a.AppliedAt
+			s.AppliedAt = &appliedAt
+			s.Modified = a.Checksum != migration.Checksum()
+			delete(byVersion, migration.Version)
+		}
+		statuses = append(statuses, s)
+	}
+
+	for _, a := range applied {
+		if _, ok := byVersion[a.Version]; ok {
+			appliedAt := a.AppliedAt
+			statuses = append(statuses, &Status{Version: a.Version, Name: a.Name, AppliedAt: &appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses
+}
@@ -0,0 +1,262 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/yourusername/student-management-system/pkg/dialect"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `
+-- 注释中的分号; 不拆分
+CREATE TABLE a (id INT); # 井号注释;
+INSERT INTO a VALUES ('x;y', "it''s", 'a\'b;');
+/* 块注释; */ UPDATE a SET id = 2;
+-- 只有注释的片段被丢弃
+;
+SELECT ` + "`odd;name`" + ` FROM a`
+
+	got := SplitStatements(script)
+	want := []string{
+		"-- 注释中的分号; 不拆分\nCREATE TABLE a (id INT)",
+		"# 井号注释;\nINSERT INTO a VALUES ('x;y', \"it''s\", 'a\\'b;')",
+		"/* 块注释; */ UPDATE a SET id = 2",
+		"SELECT `odd;name` FROM a",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d statements, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("statement %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"0001_init.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"0001_init.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"README.md":           {Data: []byte("ignored")},
+		"0003_no_up.down.sql": {Data: []byte("DROP TABLE c;")},
+	}
+
+	if _, err := Load(fsys); err == nil || !strings.Contains(err.Error(), "no up script") {
+		t.Errorf("Expected missing up script error, got %v", err)
+	}
+
+	delete(fsys, "0003_no_up.down.sql")
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("Expected versions 1 and 2 in order, got %v", migrations)
+	}
+	if migrations[0].Down != "DROP TABLE a;" || migrations[1].Down != "" {
+		t.Errorf("Unexpected down scripts %q %q", migrations[0].Down, migrations[1].Down)
+	}
+
+	fsys["0002_other.down.sql"] = &fstest.MapFile{Data: []byte("")}
+	if _, err := Load(fsys); err == nil {
+		t.Error("Expected error for two names sharing a version")
+	}
+
+	delete(fsys, "0002_other.down.sql")
+	fsys["3_Bad-Name.up.sql"] = &fstest.MapFile{Data: []byte("")}
+	if _, err := Load(fsys); err == nil {
+		t.Error("Expected error for invalid file name")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	migrations := []*Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE a (id INT);"},
+		{Version: 2, Name: "add_b", Up: "CREATE TABLE b (id INT);"},
+		{Version: 3, Name: "add_c", Up: "CREATE TABLE c (id INT);"},
+	}
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+
+	// 版本2尚未执行但比已执行的版本3小，同样需要执行
+	applied := []*Applied{
+		{Version: 1, Name: "init", Checksum: migrations[0].Checksum(), AppliedAt: at},
+		{Version: 3, Name: "add_c", Checksum: migrations[2].Checksum(), AppliedAt: at},
+	}
+	pending, err := plan(migrations, applied)
+	if err != nil {
+		t.Fatalf("plan() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("Expected only version 2 pending, got %v", pending)
+	}
+
+	edited := []*Applied{{Version: 1, Name: "init", Checksum: "edited", AppliedAt: at}}
+	if _, err := plan(migrations, edited); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+
+	missing := []*Applied{{Version: 9, Name: "gone", Checksum: "x", AppliedAt: at}}
+	if _, err := plan(migrations, missing); !errors.Is(err, ErrMissingMigration) {
+		t.Errorf("Expected ErrMissingMigration, got %v", err)
+	}
+
+	statuses := status(migrations, append(edited, missing...))
+	if len(statuses) != 4 || !statuses[0].Modified || statuses[1].AppliedAt != nil || !statuses[3].Missing {
+		t.Errorf("Unexpected statuses %+v %+v %+v %+v", statuses[0], statuses[1], statuses[2], statuses[3])
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/0007_existing.up.sql", []byte("SELECT 1;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	upPath, downPath, err := Create(dir, "Add Student Email")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.HasSuffix(upPath, "0008_add_student_email.up.sql") || !strings.HasSuffix(downPath, "0008_add_student_email.down.sql") {
+		t.Errorf("Unexpected paths %s %s", upPath, downPath)
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) != 2 || migrations[1].Name != "add_student_email" {
+		t.Errorf("Expected the new migration to load, got %v", migrations)
+	}
+}
+
+// TestRepositoryMigrations 检查仓库中每种数据库的迁移文件都能被加载、版本一一对应并且每个版本都可以回滚，
+// SQLite的迁移在临时数据库上实际执行和回滚
+func TestRepositoryMigrations(t *testing.T) {
+	var reference []*Migration
+	for _, name := range dialect.Names() {
+		migrations, err := Load(os.DirFS(filepath.Join("../../scripts/migrations", name)))
+		if err != nil {
+			t.Fatalf("Load(%s) error = %v", name, err)
+		}
+		if len(migrations) == 0 {
+			t.Fatalf("Expected migrations in scripts/migrations/%s", name)
+		}
+		for _, m := range migrations {
+			if len(SplitStatements(m.Up)) == 0 || len(SplitStatements(m.Down)) == 0 {
+				t.Errorf("Migration %s/%s must have both up and down statements", name, m)
+			}
+		}
+
+		if reference == nil {
+			reference = migrations
+			continue
+		}
+		if len(migrations) != len(reference) {
+			t.Errorf("Expected %d migrations in %s like %s, got %d", len(reference), name, dialect.Names()[0], len(migrations))
+			continue
+		}
+		for i, m := range migrations {
+			if m.String() != reference[i].String() {
+				t.Errorf("Migration %d in %s is %s, expected %s", i, name, m, reference[i])
+			}
+		}
+	}
+}
+
+func TestMigratorSQLite(t *testing.T) {
+	migrations, err := Load(os.DirFS("../../scripts/migrations/sqlite"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	db, d, err := dialect.Open(dialect.SQLite, "file:"+filepath.Join(t.TempDir(), "migrate.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	migrator := New(db, d, migrations)
+	migrator.logf = t.Logf
+
+	applied, err := migrator.Up(0)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Expected %d migrations applied, got %d", len(migrations), len(applied))
+	}
+	if applied, err := migrator.Up(0); err != nil || len(applied) != 0 {
+		t.Errorf("Expected nothing to apply the second time, got %v %v", applied, err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil || s.Modified || s.Missing {
+			t.Errorf("Unexpected status %+v", s)
+		}
+	}
+
+	if _, err := migrator.Down(len(migrations)); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Errorf("Expected all tables dropped after rolling back, %d left", tables)
+	}
+
+	if _, err := migrator.Up(0); err != nil {
+		t.Errorf("Up() after Down() error = %v", err)
+	}
+}
+
+func TestMigratorRefusesUnversionedSchema(t *testing.T) {
+	migrations, err := Load(os.DirFS("../../scripts/migrations/sqlite"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	db, d, err := dialect.Open(dialect.SQLite, "file:"+filepath.Join(t.TempDir(), "legacy.db")+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	// 引入迁移之前用建表脚本创建的库：有表但没有schema_migrations
+	if _, err := db.Exec(`CREATE TABLE student (ID VARCHAR(5) PRIMARY KEY, name VARCHAR(20) NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := New(db, d, migrations)
+	migrator.logf = t.Logf
+
+	applied, err := migrator.Up(0)
+	if !errors.Is(err, ErrUnversionedSchema) {
+		t.Fatalf("Up() error = %v, want %v", err, ErrUnversionedSchema)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected nothing applied, got %v", applied)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			t.Errorf("Expected %s not to be recorded as applied", s.Name)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package migrate
+
+import "strings"
+
+// SplitStatements 把SQL脚本按分号拆分为单条语句
+//
+// 引号（'、"、`）内和注释（--、#、/* */）内的分号不作为语句结束，
+// 只包含注释和空白的片段会被丢弃。不支持DELIMITER，迁移中不要定义存储过程和触发器。
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	hasCode := false
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+		current.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == ';':
+			flush()
+			continue
+
+		case c == '\'' || c == '"' || c == '`':
+			end := quoteEnd(script, i)
+			current.WriteString(script[i:end])
+			hasCode = true
+			i = end - 1
+			continue
+
+		case c == '#' || (c == '-' && strings.HasPrefix(script[i:], "--") && (i+2 == len(script) || isSpace(script[i+2]))):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			current.WriteString(script[i : i+end])
+			i += end - 1
+			continue
+
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script)
+			} else {
+				end = i + 2 + end + 2
+			}
+			current.WriteString(script[i:end])
+			i = end - 1
+			continue
+		}
+
+		current.WriteByte(c)
+		if !isSpace(c) {
+			hasCode = true
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// quoteEnd 返回从start开始的引号字符串结束后的位置，支持反斜杠转义和连续两个引号的转义
+func quoteEnd(script string, start int) int {
+	quote := script[start]
+	for i := start + 1; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(script)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,91 @@
+// Package notify 向用户发送通知（找回密码等），具体的投递方式可以替换
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourusername/student-management-system/pkg/config"
+)
+
+// Message 表示一条发给用户的通知
+type Message struct {
+	UserID  string    `json:"user_id"`
+	Role    string    `json:"role"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Notifier 定义通知发送接口
+type Notifier interface {
+	Send(msg *Message) error
+}
+
+// New 根据配置创建通知发送器
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	switch cfg.Type {
+	case "", "log":
+		return NewLogNotifier(log.Default()), nil
+	case "file":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("notifier.path is required for file notifier")
+		}
+		return NewFileNotifier(cfg.Path), nil
+	}
+	return nil, fmt.Errorf("unsupported notifier type %q", cfg.Type)
+}
+
+// LogNotifier 把通知写入日志，适合开发环境
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier 创建日志通知发送器
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+// Send 写入一条日志
+func (n *LogNotifier) Send(msg *Message) error {
+	n.logger.Printf("notify %s %s: %s\n%s", msg.Role, msg.UserID, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileNotifier 把通知以JSON行的形式追加到本地文件，便于测试和对接外部投递程序
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier 创建文件通知发送器
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Send 追加一行JSON
+func (n *FileNotifier) Send(msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open notification file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
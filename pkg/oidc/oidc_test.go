@@ -0,0 +1,140 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/pkg/config"
+	"github.com/yourusername/student-management-system/pkg/oidc/oidctest"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	idp := oidctest.NewServer("sms", "sms-secret")
+	t.Cleanup(idp.Close)
+
+	provider, err := NewProvider(config.OIDCConfig{
+		Issuer:       idp.Issuer(),
+		ClientID:     "sms",
+		ClientSecret: "sms-secret",
+		RedirectURL:  "http://localhost:8080/api/oidc/callback",
+	}, idp.Client())
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return provider, idp
+}
+
+func TestProviderExchange(t *testing.T) {
+	ctx := context.Background()
+	provider, idp := newTestProvider(t)
+	idp.AddUser("alice", map[string]interface{}{"email": "alice@uni.edu", "email_verified": true, "groups": []string{"students"}})
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-verifier-verifier-verifier-verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	if u.Query().Get("code_challenge_method") != "S256" || u.Query().Get("scope") != "openid email profile" {
+		t.Errorf("Unexpected authorization URL %s", authURL)
+	}
+
+	code, state, err := idp.Authorize(authURL, "alice")
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if state != "state-1" {
+		t.Errorf("Expected state to round-trip, got %q", state)
+	}
+
+	// PKCE校验串不匹配时身份提供方拒绝兑换
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier", "nonce-1"); err == nil {
+		t.Error("Expected error for wrong code verifier")
+	}
+
+	code, _, err = idp.Authorize(authURL, "alice")
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	token, err := provider.Exchange(ctx, code, "verifier-verifier-verifier-verifier-verifier", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if token.Subject != "alice" || token.Email != "alice@uni.edu" || !token.EmailVerified {
+		t.Errorf("Unexpected token %+v", token)
+	}
+	if groups := token.ClaimStrings("groups"); len(groups) != 1 || groups[0] != "students" {
+		t.Errorf("Expected groups [students], got %v", groups)
+	}
+}
+
+func TestProviderCanceledContext(t *testing.T) {
+	provider, _ := newTestProvider(t)
+
+	// 请求被取消后不再等待身份提供方
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.Exchange(ctx, "code", "verifier", "nonce"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestProviderVerifyIDToken(t *testing.T) {
+	ctx := context.Background()
+	provider, idp := newTestProvider(t)
+	now := time.Now()
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":   idp.Issuer(),
+			"aud":   []string{"other", "sms"},
+			"sub":   "alice",
+			"exp":   now.Add(time.Minute).Unix(),
+			"iat":   now.Add(30 * time.Second).Unix(), // 时钟偏差之内
+			"nonce": "n",
+		}
+	}
+
+	raw, err := idp.SignIDToken(valid())
+	if err != nil {
+		t.Fatalf("SignIDToken() error = %v", err)
+	}
+	if _, err := provider.VerifyIDToken(ctx, raw, "n"); err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(claims map[string]interface{})
+		nonce  string
+	}{
+		{"wrong issuer", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, "n"},
+		{"wrong audience", func(c map[string]interface{}) { c["aud"] = "other" }, "n"},
+		{"expired", func(c map[string]interface{}) { c["exp"] = now.Add(-2 * time.Minute).Unix() }, "n"},
+		{"issued in the future", func(c map[string]interface{}) { c["iat"] = now.Add(5 * time.Minute).Unix() }, "n"},
+		{"not valid yet", func(c map[string]interface{}) { c["nbf"] = now.Add(5 * time.Minute).Unix() }, "n"},
+		{"nonce mismatch", func(c map[string]interface{}) {}, "other"},
+		{"missing subject", func(c map[string]interface{}) { delete(c, "sub") }, "n"},
+	}
+	for _, tt := range tests {
+		claims := valid()
+		tt.modify(claims)
+		raw, err := idp.SignIDToken(claims)
+		if err != nil {
+			t.Fatalf("SignIDToken() error = %v", err)
+		}
+		if _, err := provider.VerifyIDToken(ctx, raw, tt.nonce); !errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("%s: expected ErrInvalidIDToken, got %v", tt.name, err)
+		}
+	}
+
+	// 篡改载荷后签名校验失败
+	parts := strings.Split(raw, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+	if _, err := provider.VerifyIDToken(ctx, tampered, "n"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Expected ErrInvalidIDToken for tampered token, got %v", err)
+	}
+}
@@ -1,33 +1,307 @@
 package utils
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
+
+	"github.com/yourusername/student-management-system/pkg/config"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
 )
 
+// LegacyKeyID 由 jwt.secret 生成的HS256密钥的kid，没有kid的旧令牌也用它验证
+const LegacyKeyID = "default"
+
 // JWTClaims 定义JWT令牌的声明
 type JWTClaims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"` // 用户角色: student, instructor, admin
-	Exp      int64  `json:"exp"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"` // 用户角色: student, instructor, admin
+	SessionID string `json:"sid,omitempty"`
+	// PasswordChangeRequired 为true时只能访问修改密码和注销接口
+	PasswordChangeRequired bool  `json:"pwd_change,omitempty"`
+	Iat                    int64 `json:"iat,omitempty"`
+	Exp                    int64 `json:"exp"`
+}
+
+// jwtHeader JWT头部
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// jwtKey 一把签名/验证密钥，没有私钥的密钥只用于验证已签发的令牌
+type jwtKey struct {
+	id         string
+	alg        string
+	secret     []byte
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// canSign 判断密钥是否可以用于签名
+func (k *jwtKey) canSign() bool {
+	if k.alg == AlgHS256 {
+		return len(k.secret) > 0
+	}
+	return k.privateKey != nil
+}
+
+// sign 对签名输入计算签名
+func (k *jwtKey) sign(input string) ([]byte, error) {
+	switch k.alg {
+	case AlgHS256:
+		h := hmac.New(sha256.New, k.secret)
+		h.Write([]byte(input))
+		return h.Sum(nil), nil
+	case AlgRS256:
+		digest := sha256.Sum256([]byte(input))
+		return k.privateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgEdDSA:
+		return k.privateKey.Sign(rand.Reader, []byte(input), crypto.Hash(0))
+	}
+	return nil, fmt.Errorf("unsupported algorithm %s", k.alg)
+}
+
+// verify 验证签名
+func (k *jwtKey) verify(input string, signature []byte) bool {
+	switch k.alg {
+	case AlgHS256:
+		h := hmac.New(sha256.New, k.secret)
+		h.Write([]byte(input))
+		return hmac.Equal(signature, h.Sum(nil))
+	case AlgRS256:
+		publicKey, ok := k.publicKey.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256([]byte(input))
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil
+	case AlgEdDSA:
+		publicKey, ok := k.publicKey.(ed25519.PublicKey)
+		if !ok {
+			return false
+		}
+		return ed25519.Verify(publicKey, []byte(input), signature)
+	}
+	return false
+}
+
+// JWTManager 按配置签发和验证JWT令牌，支持通过kid同时持有多把密钥以便轮换
+type JWTManager struct {
+	keys       map[string]*jwtKey
+	activeKey  *jwtKey
+	expiration time.Duration
+	now        func() time.Time // 当前时间，测试中可替换
+}
+
+// NewJWTManager 根据配置创建JWTManager
+//
+// jwt.keys 中的每一把密钥都可以验证令牌，jwt.activeKey 指定签发新令牌使用的密钥。
+// 轮换时先加入新密钥并切换 activeKey，旧密钥保留到它签发的令牌全部过期后再删除。
+// 未配置 keys 时使用 jwt.secret 作为kid为 "default" 的HS256密钥。
+func NewJWTManager(cfg config.JWTConfig) (*JWTManager, error) {
+	m := &JWTManager{
+		keys:       make(map[string]*jwtKey),
+		expiration: time.Duration(cfg.Expiration) * time.Second,
+		now:        time.Now,
+	}
+	if m.expiration <= 0 {
+		m.expiration = 24 * time.Hour
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		key, err := loadJWTKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("error loading jwt key %q: %w", keyCfg.ID, err)
+		}
+		if _, exists := m.keys[key.id]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", key.id)
+		}
+		m.keys[key.id] = key
+	}
+
+	if cfg.Secret != "" {
+		if _, exists := m.keys[LegacyKeyID]; !exists {
+			m.keys[LegacyKeyID] = &jwtKey{id: LegacyKeyID, alg: AlgHS256, secret: []byte(cfg.Secret)}
+		}
+	}
+
+	activeID := cfg.ActiveKey
+	if activeID == "" {
+		if len(cfg.Keys) > 1 {
+			return nil, errors.New("jwt.activeKey is required when more than one key is configured")
+		}
+		activeID = LegacyKeyID
+		if len(cfg.Keys) == 1 {
+			activeID = cfg.Keys[0].ID
+		}
+	}
+
+	active, ok := m.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active jwt key %q is not configured", activeID)
+	}
+	if !active.canSign() {
+		return nil, fmt.Errorf("active jwt key %q has no private key", activeID)
+	}
+	m.activeKey = active
+
+	return m, nil
+}
+
+// loadJWTKey 根据配置加载一把密钥，非对称密钥从本地PEM文件读取
+func loadJWTKey(cfg config.JWTKeyConfig) (*jwtKey, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("id is required")
+	}
+
+	key := &jwtKey{id: cfg.ID, alg: cfg.Algorithm}
+	if key.alg == "" {
+		key.alg = AlgHS256
+	}
+
+	switch key.alg {
+	case AlgHS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("secret is required for HS256")
+		}
+		key.secret = []byte(cfg.Secret)
+		return key, nil
+	case AlgRS256, AlgEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %s", key.alg)
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		privateKey, err := readPrivateKey(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.privateKey = privateKey
+		key.publicKey = privateKey.Public()
+	}
+
+	if cfg.PublicKeyFile != "" {
+		publicKey, err := readPublicKey(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.publicKey = publicKey
+	}
+
+	if key.publicKey == nil {
+		return nil, errors.New("privateKeyFile or publicKeyFile is required")
+	}
+
+	// 密钥类型必须与算法一致
+	switch key.publicKey.(type) {
+	case *rsa.PublicKey:
+		if key.alg != AlgRS256 {
+			return nil, fmt.Errorf("rsa key cannot be used with %s", key.alg)
+		}
+	case ed25519.PublicKey:
+		if key.alg != AlgEdDSA {
+			return nil, fmt.Errorf("ed25519 key cannot be used with %s", key.alg)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key.publicKey)
+	}
+
+	return key, nil
+}
+
+// readPEMBlock 读取PEM文件中的第一个块
+func readPEMBlock(filename string) (*pem.Block, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", filename)
+	}
+
+	return block, nil
+}
+
+// readPrivateKey 读取PKCS#8或PKCS#1格式的私钥
+func readPrivateKey(filename string) (crypto.Signer, error) {
+	block, err := readPEMBlock(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	return signer, nil
+}
+
+// readPublicKey 读取PKIX或PKCS#1格式的公钥
+func readPublicKey(filename string) (crypto.PublicKey, error) {
+	block, err := readPEMBlock(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return publicKey, nil
 }
 
-// GenerateJWT 生成JWT令牌
-func GenerateJWT(userID, username, role string) (string, error) {
-	// 从配置中获取密钥和过期时间
-	secret := "your-secret-key-here" // 应该从配置中读取
-	expirationSeconds := 86400       // 24小时
+// Expiration 返回令牌的有效期
+func (m *JWTManager) Expiration() time.Duration {
+	return m.expiration
+}
+
+// Generate 使用当前活动密钥生成JWT令牌，Iat和Exp由JWTManager填写
+func (m *JWTManager) Generate(claims JWTClaims) (string, error) {
+	key := m.activeKey
 
 	// 创建头部
-	header := map[string]string{
-		"alg": "HS256",
-		"typ": "JWT",
+	header := jwtHeader{
+		Alg: key.alg,
+		Typ: "JWT",
+		Kid: key.id,
 	}
 
 	// 序列化头部
@@ -37,12 +311,9 @@ func GenerateJWT(userID, username, role string) (string, error) {
 	}
 
 	// 创建载荷
-	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
-		Exp:      time.Now().Add(time.Duration(expirationSeconds) * time.Second).Unix(),
-	}
+	now := m.now()
+	claims.Iat = now.Unix()
+	claims.Exp = now.Add(m.expiration).Unix()
 
 	// 序列化载荷
 	payloadJSON, err := json.Marshal(claims)
@@ -56,18 +327,19 @@ func GenerateJWT(userID, username, role string) (string, error) {
 
 	// 创建签名
 	signatureInput := headerBase64 + "." + payloadBase64
-	signature := hmacSha256(signatureInput, secret)
+	signature, err := key.sign(signatureInput)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %v", err)
+	}
 
 	// 组合JWT
-	jwt := signatureInput + "." + signature
+	jwt := signatureInput + "." + base64.RawURLEncoding.EncodeToString(signature)
 
 	return jwt, nil
 }
 
-// ValidateJWT 验证JWT令牌并返回声明
-func ValidateJWT(tokenString string) (*JWTClaims, error) {
-	secret := "your-secret-key-here" // 应该从配置中读取
-
+// Validate 验证JWT令牌并返回声明
+func (m *JWTManager) Validate(tokenString string) (*JWTClaims, error) {
 	// 分割令牌
 	parts := strings.Split(tokenString, ".")
 	if len(parts) != 3 {
@@ -76,13 +348,40 @@ func ValidateJWT(tokenString string) (*JWTClaims, error) {
 
 	headerBase64 := parts[0]
 	payloadBase64 := parts[1]
-	signature := parts[2]
+
+	// 解码头部，按kid选择密钥
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode header: %v", err)
+	}
+
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal header: %v", err)
+	}
+
+	kid := header.Kid
+	if kid == "" {
+		kid = LegacyKeyID
+	}
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	// 头部的算法必须与密钥一致，防止算法替换攻击
+	if header.Alg != key.alg {
+		return nil, fmt.Errorf("unexpected algorithm %s", header.Alg)
+	}
 
 	// 验证签名
-	signatureInput := headerBase64 + "." + payloadBase64
-	expectedSignature := hmacSha256(signatureInput, secret)
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %v", err)
+	}
 
-	if signature != expectedSignature {
+	if !key.verify(headerBase64+"."+payloadBase64, signature) {
 		return nil, fmt.Errorf("invalid signature")
 	}
 
@@ -99,16 +398,9 @@ func ValidateJWT(tokenString string) (*JWTClaims, error) {
 	}
 
 	// 验证过期时间
-	if time.Now().Unix() > claims.Exp {
+	if m.now().Unix() > claims.Exp {
 		return nil, fmt.Errorf("token expired")
 	}
 
 	return &claims, nil
 }
-
-// hmacSha256 计算HMAC SHA-256签名并返回Base64编码的字符串
-func hmacSha256(data, secret string) string {
-	h := hmac.New(sha256.New, []byte(secret))
-	h.Write([]byte(data))
-	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
-}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/student-management-system/pkg/config"
+)
+
+// writePEM 将DER数据写入临时PEM文件
+func writePEM(t *testing.T, name string, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJWTManager_RoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []config.JWTKeyConfig{
+		{ID: "hs", Secret: "hs-secret"},
+		{ID: "rs", Algorithm: AlgRS256, PrivateKeyFile: writePEM(t, "rs.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))},
+		{ID: "ed", Algorithm: AlgEdDSA, PrivateKeyFile: writePEM(t, "ed.pem", "PRIVATE KEY", edDER)},
+	}
+
+	for _, key := range keys {
+		t.Run(key.ID, func(t *testing.T) {
+			m, err := NewJWTManager(config.JWTConfig{Expiration: 60, ActiveKey: key.ID, Keys: keys})
+			if err != nil {
+				t.Fatalf("NewJWTManager() error = %v", err)
+			}
+
+			token, err := m.Generate(JWTClaims{UserID: "S001", Username: "S001", Role: "student", SessionID: "sid-1"})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			claims, err := m.Validate(token)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if claims.UserID != "S001" || claims.Role != "student" || claims.SessionID != "sid-1" {
+				t.Errorf("Unexpected claims %+v", claims)
+			}
+
+			// 篡改载荷后签名失效
+			parts := strings.Split(token, ".")
+			parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"user_id":"S001","role":"admin","exp":9999999999}`))
+			if _, err := m.Validate(strings.Join(parts, ".")); err == nil {
+				t.Error("Expected tampered token to be rejected")
+			}
+
+			m.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+			if _, err := m.Validate(token); err == nil {
+				t.Error("Expected expired token to be rejected")
+			}
+		})
+	}
+}
+
+func TestJWTManager_Rotation(t *testing.T) {
+	// 只配置 secret 时签发没有变化的HS256令牌
+	legacy, err := NewJWTManager(config.JWTConfig{Secret: "old-secret", Expiration: 3600})
+	if err != nil {
+		t.Fatalf("NewJWTManager() error = %v", err)
+	}
+	oldToken, err := legacy.Generate(JWTClaims{UserID: "I001", Role: "instructor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 切换到新密钥后，旧密钥签发的令牌仍然有效
+	rotated, err := NewJWTManager(config.JWTConfig{
+		Secret:     "old-secret",
+		Expiration: 3600,
+		ActiveKey:  "2024-10",
+		Keys:       []config.JWTKeyConfig{{ID: "2024-10", Secret: "new-secret"}},
+	})
+	if err != nil {
+		t.Fatalf("NewJWTManager() error = %v", err)
+	}
+	if _, err := rotated.Validate(oldToken); err != nil {
+		t.Errorf("Expected token signed with the old key to validate, got %v", err)
+	}
+	newToken, err := rotated.Generate(JWTClaims{UserID: "I001", Role: "instructor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 删除旧密钥后，旧令牌失效，新令牌不受影响
+	retired, err := NewJWTManager(config.JWTConfig{
+		Expiration: 3600,
+		Keys:       []config.JWTKeyConfig{{ID: "2024-10", Secret: "new-secret"}},
+	})
+	if err != nil {
+		t.Fatalf("NewJWTManager() error = %v", err)
+	}
+	if _, err := retired.Validate(oldToken); err == nil {
+		t.Error("Expected token signed with a retired key to be rejected")
+	}
+	if _, err := retired.Validate(newToken); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	if _, err := NewJWTManager(config.JWTConfig{ActiveKey: "missing", Secret: "x"}); err == nil {
+		t.Error("Expected error for unknown active key")
+	}
+}
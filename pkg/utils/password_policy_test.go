@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/yourusername/student-management-system/pkg/config"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	strict := NewPasswordPolicy(config.PasswordConfig{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true})
+
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"Aa1!aaaaaa", true},
+		{"Aa1!aaaa", false},   // 太短
+		{"aa1!aaaaaa", false}, // 没有大写字母
+		{"AA1!AAAAAA", false}, // 没有小写字母
+		{"Aaa!aaaaaa", false}, // 没有数字
+		{"Aa1aaaaaaa", false}, // 没有符号
+		{"Ää1!ääääää", true},  // 非ASCII字母按字符计数
+	}
+	for _, tt := range tests {
+		if err := strict.Validate(tt.password); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) error = %v, want valid = %v", tt.password, err, tt.valid)
+		}
+	}
+
+	if err := NewPasswordPolicy(config.PasswordConfig{}).Validate("1234567"); err == nil {
+		t.Error("Expected default minimum length to apply")
+	}
+
+	for i := 0; i < 20; i++ {
+		password, err := GenerateTemporaryPassword(12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := strict.Validate(password + "xx"); err != nil {
+			t.Errorf("Temporary password %q does not satisfy every character class: %v", password, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 附录B的SHA1测试向量，取后6位
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	if _, err := TOTPCode("not base32!", 1); err != ErrInvalidTOTPSecret {
+		t.Errorf("Expected ErrInvalidTOTPSecret, got %v", err)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 9, 1, 8, 0, 10, 0, time.UTC)
+
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+	if step, ok := ValidateTOTP(secret, previous, now, 1); !ok || step != TOTPStep(now)-1 {
+		t.Errorf("Expected previous step to be accepted with skew 1, got %d, %v", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, previous, now, 0); ok {
+		t.Error("Expected previous step to be rejected without skew")
+	}
+
+	old, _ := TOTPCode(secret, TOTPStep(now)-2)
+	if _, ok := ValidateTOTP(secret, old, now, 1); ok {
+		t.Error("Expected code two steps old to be rejected")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now, 1); ok {
+		t.Error("Expected short code to be rejected")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Student Management", "I001", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/Student%20Management:I001?") {
+		t.Fatalf("Unexpected URI %s", uri)
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Student Management" || query.Get("digits") != "6" {
+		t.Errorf("Unexpected URI parameters %v", query)
+	}
+}
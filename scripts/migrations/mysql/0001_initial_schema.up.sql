@@ -0,0 +1,420 @@
+-- 初始数据库结构
+-- 使用 IF NOT EXISTS，已经用旧的 init.sql 建好表的数据库执行本迁移时不会报错，之后即纳入版本管理
+
+-- 创建院系表
+CREATE TABLE IF NOT EXISTS department (
+    dept_name VARCHAR(20) PRIMARY KEY,
+    building VARCHAR(15),
+    budget DECIMAL(12,2)
+);
+
+-- 创建学生表
+CREATE TABLE IF NOT EXISTS student (
+    ID VARCHAR(5) PRIMARY KEY,
+    name VARCHAR(20) NOT NULL,
+    dept_name VARCHAR(20),
+    tot_cred DECIMAL(3,0) DEFAULT 0,
+    password VARCHAR(100),
+    salt VARCHAR(50),
+    must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
+    FOREIGN KEY (dept_name) REFERENCES department(dept_name)
+);
+
+-- 创建教师表
+CREATE TABLE IF NOT EXISTS instructor (
+    ID VARCHAR(5) PRIMARY KEY,
+    name VARCHAR(20) NOT NULL,
+    dept_name VARCHAR(20),
+    salary DECIMAL(8,2),
+    password VARCHAR(100),
+    salt VARCHAR(50),
+    must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
+    FOREIGN KEY (dept_name) REFERENCES department(dept_name)
+);
+
+-- 创建课程表
+CREATE TABLE IF NOT EXISTS course (
+    course_id VARCHAR(8) PRIMARY KEY,
+    title VARCHAR(50),
+    dept_name VARCHAR(20),
+    credits DECIMAL(2,0),
+    FOREIGN KEY (dept_name) REFERENCES department(dept_name)
+);
+
+-- 创建教室表
+CREATE TABLE IF NOT EXISTS classroom (
+    building VARCHAR(15),
+    room_number VARCHAR(7),
+    capacity DECIMAL(4,0),
+    PRIMARY KEY (building, room_number)
+);
+
+-- 创建时间段表
+CREATE TABLE IF NOT EXISTS time_slot (
+    time_slot_id VARCHAR(4) PRIMARY KEY,
+    day VARCHAR(1),
+    start_hr DECIMAL(2),
+    start_min DECIMAL(2),
+    end_hr DECIMAL(2),
+    end_min DECIMAL(2)
+);
+
+-- 创建课程段表
+CREATE TABLE IF NOT EXISTS section (
+    course_id VARCHAR(8),
+    sec_id VARCHAR(8),
+    semester VARCHAR(6),
+    year DECIMAL(4,0),
+    building VARCHAR(15),
+    room_number VARCHAR(7),
+    time_slot_id VARCHAR(4),
+    PRIMARY KEY (course_id, sec_id, semester, year),
+    FOREIGN KEY (course_id) REFERENCES course(course_id),
+    FOREIGN KEY (building, room_number) REFERENCES classroom(building, room_number),
+    FOREIGN KEY (time_slot_id) REFERENCES time_slot(time_slot_id)
+);
+
+-- 创建学生选课表
+CREATE TABLE IF NOT EXISTS takes (
+    ID VARCHAR(5),
+    course_id VARCHAR(8),
+    sec_id VARCHAR(8),
+    semester VARCHAR(6),
+    year DECIMAL(4,0),
+    grade VARCHAR(2),
+    PRIMARY KEY (ID, course_id, sec_id, semester, year),
+    FOREIGN KEY (ID) REFERENCES student(ID),
+    FOREIGN KEY (course_id, sec_id, semester, year) REFERENCES section(course_id, sec_id, semester, year)
+);
+
+-- 创建教师授课表
+CREATE TABLE IF NOT EXISTS teaches (
+    ID VARCHAR(5),
+    course_id VARCHAR(8),
+    sec_id VARCHAR(8),
+    semester VARCHAR(6),
+    year DECIMAL(4,0),
+    PRIMARY KEY (ID, course_id, sec_id, semester, year),
+    FOREIGN KEY (ID) REFERENCES instructor(ID),
+    FOREIGN KEY (course_id, sec_id, semester, year) REFERENCES section(course_id, sec_id, semester, year)
+);
+
+-- 创建导师关系表
+CREATE TABLE IF NOT EXISTS advisor (
+    s_ID VARCHAR(5),
+    i_ID VARCHAR(5),
+    PRIMARY KEY (s_ID),
+    FOREIGN KEY (s_ID) REFERENCES student(ID),
+    FOREIGN KEY (i_ID) REFERENCES instructor(ID)
+);
+
+-- 创建先修课程表
+CREATE TABLE IF NOT EXISTS prereq (
+    course_id VARCHAR(8),
+    prereq_id VARCHAR(8),
+    PRIMARY KEY (course_id, prereq_id),
+    FOREIGN KEY (course_id) REFERENCES course(course_id),
+    FOREIGN KEY (prereq_id) REFERENCES course(course_id)
+);
+
+-- 创建先修规则表，rule 为 JSON 格式的规则树，没有规则树的课程使用 prereq 表
+CREATE TABLE IF NOT EXISTS prereq_rule (
+    course_id VARCHAR(8) PRIMARY KEY,
+    rule TEXT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (course_id) REFERENCES course(course_id)
+);
+
+-- 创建候补队列表
+CREATE TABLE IF NOT EXISTS waitlist (
+    id VARCHAR(32) PRIMARY KEY,
+    student_id VARCHAR(5) NOT NULL,
+    course_id VARCHAR(8) NOT NULL,
+    sec_id VARCHAR(8) NOT NULL,
+    semester VARCHAR(6) NOT NULL,
+    year DECIMAL(4,0) NOT NULL,
+    queue_no INT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'waiting',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    INDEX idx_waitlist_section (course_id, sec_id, semester, year, status, queue_no),
+    UNIQUE (course_id, sec_id, semester, year, queue_no),
+    FOREIGN KEY (student_id) REFERENCES student(ID),
+    FOREIGN KEY (course_id, sec_id, semester, year) REFERENCES section(course_id, sec_id, semester, year)
+);
+
+-- 创建选课特许表，记录教师签发的一次性先修/容量/时间冲突豁免及许可码
+CREATE TABLE IF NOT EXISTS registration_override (
+    id VARCHAR(32) PRIMARY KEY,
+    student_id VARCHAR(5) NOT NULL,
+    course_id VARCHAR(8) NOT NULL,
+    sec_id VARCHAR(8) NOT NULL,
+    semester VARCHAR(6) NOT NULL,
+    year DECIMAL(4,0) NOT NULL,
+    waives VARCHAR(100) NOT NULL,
+    code VARCHAR(16) UNIQUE,
+    granted_by VARCHAR(5) NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'active',
+    created_at DATETIME NOT NULL,
+    used_at DATETIME,
+    INDEX idx_override_student_section (student_id, sec_id, status),
+    FOREIGN KEY (student_id) REFERENCES student(ID),
+    FOREIGN KEY (granted_by) REFERENCES instructor(ID),
+    FOREIGN KEY (course_id, sec_id, semester, year) REFERENCES section(course_id, sec_id, semester, year)
+);
+
+-- 创建选课购物车表
+CREATE TABLE IF NOT EXISTS cart_item (
+    student_id VARCHAR(5) NOT NULL,
+    course_id VARCHAR(8) NOT NULL,
+    sec_id VARCHAR(8) NOT NULL,
+    semester VARCHAR(6) NOT NULL,
+    year DECIMAL(4,0) NOT NULL,
+    added_at DATETIME NOT NULL,
+    PRIMARY KEY (student_id, course_id, sec_id, semester, year),
+    FOREIGN KEY (student_id) REFERENCES student(ID),
+    FOREIGN KEY (course_id, sec_id, semester, year) REFERENCES section(course_id, sec_id, semester, year)
+);
+
+-- 创建校历表
+CREATE TABLE IF NOT EXISTS term_calendar (
+    semester VARCHAR(6),
+    year DECIMAL(4,0),
+    registration_open DATETIME NOT NULL,
+    registration_close DATETIME NOT NULL,
+    add_drop_deadline DATETIME NOT NULL,
+    withdrawal_deadline DATETIME NOT NULL,
+    min_credits DECIMAL(3,0) NOT NULL DEFAULT 0,
+    max_credits DECIMAL(3,0) NOT NULL DEFAULT 0,
+    PRIMARY KEY (semester, year)
+);
+
+-- 创建选课时间票表
+CREATE TABLE IF NOT EXISTS time_ticket (
+    student_id VARCHAR(5),
+    semester VARCHAR(6),
+    year DECIMAL(4,0),
+    tier INT NOT NULL,
+    start_time DATETIME NOT NULL,
+    PRIMARY KEY (student_id, semester, year),
+    FOREIGN KEY (student_id) REFERENCES student(ID)
+);
+
+-- 创建学分超载申请表
+CREATE TABLE IF NOT EXISTS overload_request (
+    id VARCHAR(32) PRIMARY KEY,
+    student_id VARCHAR(5) NOT NULL,
+    semester VARCHAR(6) NOT NULL,
+    year DECIMAL(4,0) NOT NULL,
+    requested_credits DECIMAL(3,0) NOT NULL,
+    reason VARCHAR(500),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    advisor_id VARCHAR(5),
+    decision_note VARCHAR(500),
+    created_at DATETIME NOT NULL,
+    decided_at DATETIME,
+    INDEX idx_overload_student_term (student_id, semester, year),
+    FOREIGN KEY (student_id) REFERENCES student(ID),
+    FOREIGN KEY (advisor_id) REFERENCES instructor(ID)
+);
+
+-- 创建管理员账号表，初始管理员通过 cmd/bootstrap-admin 创建
+CREATE TABLE IF NOT EXISTS admin_account (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    password VARCHAR(100) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'active',
+    must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    last_login_at DATETIME
+);
+
+-- 创建登录会话表，保存刷新令牌的哈希，吊销后该会话的访问令牌也失效
+CREATE TABLE IF NOT EXISTS auth_session (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(32) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    refresh_hash CHAR(64) NOT NULL,
+    password_change_required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    INDEX idx_auth_session_user (user_id, role)
+);
+
+-- 创建已轮换刷新令牌表，旧令牌再次出现时据此判定为重放并吊销会话
+CREATE TABLE IF NOT EXISTS auth_session_rotated (
+    session_id VARCHAR(32) NOT NULL,
+    refresh_hash CHAR(64) NOT NULL,
+    rotated_at DATETIME NOT NULL,
+    PRIMARY KEY (session_id, refresh_hash),
+    FOREIGN KEY (session_id) REFERENCES auth_session(id) ON DELETE CASCADE
+);
+
+-- 创建找回密码令牌表，只保存令牌哈希，令牌只能使用一次
+CREATE TABLE IF NOT EXISTS password_reset_token (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id VARCHAR(32) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    INDEX idx_password_reset_user (user_id, role)
+);
+
+-- 创建登录尝试表，记录每次登录的结果供安全审查
+CREATE TABLE IF NOT EXISTS login_attempt (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(32) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_login_attempt_user (user_id, role),
+    INDEX idx_login_attempt_ip (ip)
+);
+
+-- 创建登录失败计数表，按账号和IP分别统计连续失败次数
+CREATE TABLE IF NOT EXISTS login_throttle (
+    scope VARCHAR(10) NOT NULL,
+    throttle_key VARCHAR(64) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at DATETIME NOT NULL,
+    locked_at DATETIME,
+    PRIMARY KEY (scope, throttle_key)
+);
+
+-- 创建角色表，角色是一组权限的集合；dept_scoped的角色分配时必须指定系部
+CREATE TABLE IF NOT EXISTS auth_role (
+    name VARCHAR(32) PRIMARY KEY,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    dept_scoped BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- 创建角色权限表
+CREATE TABLE IF NOT EXISTS auth_role_permission (
+    role_name VARCHAR(32) NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role_name, permission),
+    FOREIGN KEY (role_name) REFERENCES auth_role(name) ON DELETE CASCADE
+);
+
+-- 创建角色分配表，dept_name为空表示不限系部
+CREATE TABLE IF NOT EXISTS auth_role_assignment (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(32) NOT NULL,
+    user_role VARCHAR(20) NOT NULL,
+    role_name VARCHAR(32) NOT NULL,
+    dept_name VARCHAR(20) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uk_role_assignment (user_id, user_role, role_name, dept_name),
+    FOREIGN KEY (role_name) REFERENCES auth_role(name) ON DELETE CASCADE
+);
+
+-- 创建两步验证表，enabled为FALSE表示已生成密钥但尚未确认绑定
+CREATE TABLE IF NOT EXISTS two_factor (
+    user_id VARCHAR(32) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    enabled_at DATETIME,
+    PRIMARY KEY (user_id, role)
+);
+
+-- 创建两步验证恢复码表，只保存恢复码的SHA-256
+CREATE TABLE IF NOT EXISTS two_factor_recovery_code (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(32) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at DATETIME,
+    INDEX idx_recovery_code_user (user_id, role)
+);
+
+-- 创建两步验证登录挑战表，只保存挑战令牌中随机串的SHA-256
+CREATE TABLE IF NOT EXISTS two_factor_challenge (
+    id VARCHAR(32) PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL,
+    user_id VARCHAR(32) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME
+);
+
+-- 创建两步验证策略表，记录各登录角色是否必须启用两步验证
+CREATE TABLE IF NOT EXISTS two_factor_policy (
+    role VARCHAR(20) PRIMARY KEY,
+    required BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- 创建审计日志表，只追加不修改，before_value和after_value是变更前后实体的JSON快照
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_id VARCHAR(50) NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_key VARCHAR(200) NOT NULL,
+    before_value TEXT,
+    after_value TEXT,
+    created_at DATETIME NOT NULL,
+    INDEX idx_audit_actor (actor_id, created_at),
+    INDEX idx_audit_entity (entity_type, entity_key, created_at),
+    INDEX idx_audit_created (created_at)
+);
+
+-- 创建API密钥表，供外部系统调用接口，只保存密钥随机串的SHA-256
+CREATE TABLE IF NOT EXISTS api_key (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    dept_name VARCHAR(20) NOT NULL DEFAULT '',
+    created_by VARCHAR(50) NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NULL,
+    last_used_at DATETIME NULL,
+    revoked_at DATETIME NULL
+);
+
+-- 创建API密钥权限表
+CREATE TABLE IF NOT EXISTS api_key_permission (
+    key_id VARCHAR(32) NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (key_id, permission),
+    FOREIGN KEY (key_id) REFERENCES api_key(id) ON DELETE CASCADE
+);
+
+-- 创建单点登录状态表，保存跳转到身份提供方时的nonce和PKCE校验串，只保存state随机串的SHA-256
+CREATE TABLE IF NOT EXISTS oidc_login_state (
+    id VARCHAR(32) PRIMARY KEY,
+    state_hash CHAR(64) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL
+);
+
+-- 创建单点登录身份绑定表，subject为空表示只按邮箱预先绑定、尚未登录过
+CREATE TABLE IF NOT EXISTS oidc_identity (
+    role VARCHAR(20) NOT NULL,
+    user_id VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NULL,
+    email VARCHAR(255) NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (role, user_id),
+    UNIQUE KEY uk_oidc_subject (role, subject),
+    UNIQUE KEY uk_oidc_email (role, email)
+);
@@ -0,0 +1,38 @@
+-- 按依赖关系倒序删除 0001 创建的全部表，表中数据一并删除
+
+DROP TABLE IF EXISTS oidc_identity;
+DROP TABLE IF EXISTS oidc_login_state;
+DROP TABLE IF EXISTS api_key_permission;
+DROP TABLE IF EXISTS api_key;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS two_factor_policy;
+DROP TABLE IF EXISTS two_factor_challenge;
+DROP TABLE IF EXISTS two_factor_recovery_code;
+DROP TABLE IF EXISTS two_factor;
+DROP TABLE IF EXISTS auth_role_assignment;
+DROP TABLE IF EXISTS auth_role_permission;
+DROP TABLE IF EXISTS auth_role;
+DROP TABLE IF EXISTS login_throttle;
+DROP TABLE IF EXISTS login_attempt;
+DROP TABLE IF EXISTS password_reset_token;
+DROP TABLE IF EXISTS auth_session_rotated;
+DROP TABLE IF EXISTS auth_session;
+DROP TABLE IF EXISTS admin_account;
+DROP TABLE IF EXISTS overload_request;
+DROP TABLE IF EXISTS time_ticket;
+DROP TABLE IF EXISTS term_calendar;
+DROP TABLE IF EXISTS cart_item;
+DROP TABLE IF EXISTS registration_override;
+DROP TABLE IF EXISTS waitlist;
+DROP TABLE IF EXISTS prereq_rule;
+DROP TABLE IF EXISTS prereq;
+DROP TABLE IF EXISTS advisor;
+DROP TABLE IF EXISTS teaches;
+DROP TABLE IF EXISTS takes;
+DROP TABLE IF EXISTS section;
+DROP TABLE IF EXISTS time_slot;
+DROP TABLE IF EXISTS classroom;
+DROP TABLE IF EXISTS course;
+DROP TABLE IF EXISTS instructor;
+DROP TABLE IF EXISTS student;
+DROP TABLE IF EXISTS department;
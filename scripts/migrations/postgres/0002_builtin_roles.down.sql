@@ -0,0 +1,2 @@
+-- 删除内置角色，角色权限和角色分配随外键级联删除
+DELETE FROM auth_role WHERE name IN ('registrar', 'department_chair', 'teaching_assistant');
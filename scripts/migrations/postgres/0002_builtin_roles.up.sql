@@ -0,0 +1,25 @@
+-- 内置角色：教务员、系主任、助教
+INSERT INTO auth_role VALUES
+('registrar', '教务员：管理学生、教师、课程、课程段、校历和选课时间票', FALSE),
+('department_chair', '系主任：管理本系的课程、课程段、先修课和教学安排，查看本系教师薪水', TRUE),
+('teaching_assistant', '助教：登记本系课程段的成绩', TRUE)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO auth_role_permission VALUES
+('registrar', 'students.manage'),
+('registrar', 'instructors.manage'),
+('registrar', 'courses.manage'),
+('registrar', 'sections.manage'),
+('registrar', 'classrooms.manage'),
+('registrar', 'advisors.manage'),
+('registrar', 'terms.manage'),
+('registrar', 'tickets.manage'),
+('registrar', 'stats.read'),
+('department_chair', 'courses.manage'),
+('department_chair', 'sections.manage'),
+('department_chair', 'prereqs.manage'),
+('department_chair', 'teaches.manage'),
+('department_chair', 'grades.write'),
+('department_chair', 'salary.read'),
+('teaching_assistant', 'grades.write')
+ON CONFLICT DO NOTHING;
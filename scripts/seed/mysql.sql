@@ -0,0 +1,42 @@
+-- 开发和演示用的示例数据，不属于数据库结构，不随迁移执行
+-- 使用 go run ./cmd/migrate seed 写入，INSERT IGNORE 可以重复执行
+
+INSERT IGNORE INTO department VALUES ('计算机科学', '工程楼', 100000.00);
+INSERT IGNORE INTO department VALUES ('数学', '科学楼', 80000.00);
+INSERT IGNORE INTO department VALUES ('物理', '科学楼', 90000.00);
+
+-- 插入学生数据（包含密码和盐值字段，示例密码统一为 '123456'）
+INSERT IGNORE INTO student (ID, name, dept_name, tot_cred, password, salt) VALUES
+('S001', '张三', '计算机科学', 30, '$2a$12$s94zBo0.hs6z5qLIQVTueuP/U8Zm0rDYzGq/n.2Mm2pNRcZNWgJ6u', 's3cr3ts4lt'),
+('S002', '李四', '数学', 25, '$2a$10$xJwL5v5z3V1lO6B9QYqZNuYbU1wYk7Xe7n6jKJc8bLm0v1aG2sD1C', 's4ltv4lu3'),
+('S003', '王五', '计算机科学', 35, '$2a$10$xJwL5v5z3V1lO6B9QYqZNuYbU1wYk7Xe7n6jKJc8bLm0v1aG2sD1C', 's0m3s4lt');
+
+INSERT IGNORE INTO instructor (ID, name, dept_name, salary) VALUES ('I001', '陈教授', '计算机科学', 80000.00);
+INSERT IGNORE INTO instructor (ID, name, dept_name, salary) VALUES ('I002', '刘教授', '数学', 75000.00);
+INSERT IGNORE INTO instructor (ID, name, dept_name, salary) VALUES ('I003', '赵教授', '物理', 85000.00);
+
+INSERT IGNORE INTO course VALUES ('CS101', '计算机科学导论', '计算机科学', 4);
+INSERT IGNORE INTO course VALUES ('CS102', '数据结构', '计算机科学', 4);
+INSERT IGNORE INTO course VALUES ('MATH101', '微积分', '数学', 3);
+
+INSERT IGNORE INTO classroom VALUES ('工程楼', '101', 50);
+INSERT IGNORE INTO classroom VALUES ('工程楼', '102', 40);
+INSERT IGNORE INTO classroom VALUES ('科学楼', '201', 60);
+
+INSERT IGNORE INTO time_slot VALUES ('A', 'M', 8, 0, 8, 50);
+INSERT IGNORE INTO time_slot VALUES ('B', 'M', 9, 0, 9, 50);
+INSERT IGNORE INTO time_slot VALUES ('C', 'T', 10, 0, 10, 50);
+
+INSERT IGNORE INTO section VALUES ('CS101', '1', 'Fall', 2024, '工程楼', '101', 'A');
+INSERT IGNORE INTO section VALUES ('CS102', '1', 'Fall', 2024, '工程楼', '102', 'B');
+INSERT IGNORE INTO section VALUES ('MATH101', '1', 'Fall', 2024, '科学楼', '201', 'C');
+
+INSERT IGNORE INTO teaches VALUES ('I001', 'CS101', '1', 'Fall', 2024);
+INSERT IGNORE INTO teaches VALUES ('I001', 'CS102', '1', 'Fall', 2024);
+INSERT IGNORE INTO teaches VALUES ('I002', 'MATH101', '1', 'Fall', 2024);
+
+INSERT IGNORE INTO advisor VALUES ('S001', 'I001');
+INSERT IGNORE INTO advisor VALUES ('S002', 'I002');
+INSERT IGNORE INTO advisor VALUES ('S003', 'I001');
+
+INSERT IGNORE INTO prereq VALUES ('CS102', 'CS101');